@@ -62,8 +62,14 @@ if [ "$GOOS" = "windows" ]; then
     BINARY_NAME="imf.exe"
 fi
 
+VERSION=$(git -C "$SCRIPT_DIR" describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT=$(git -C "$SCRIPT_DIR" rev-parse HEAD 2>/dev/null || true)
+BUILD_DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+# IMF_RELEASE_KEY: base64 Ed25519 public key that signs release.json, for imf update
+RELEASE_KEY="${IMF_RELEASE_KEY:-}"
+
 GOOS=$GOOS GOARCH=$GOARCH go build \
-    -ldflags="-s -w" \
+    -ldflags="-s -w -X main.version=${VERSION#v} -X main.commit=$COMMIT -X main.buildDate=$BUILD_DATE -X main.releaseKey=$RELEASE_KEY" \
     -o "$SIDECAR_DIR/$BINARY_NAME" \
     "$SCRIPT_DIR/cmd/imf/"
 
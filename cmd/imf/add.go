@@ -9,6 +9,7 @@ import (
 	"os"
 
 	"github.com/immutable-container/imf/pkg/container"
+	"github.com/immutable-container/imf/pkg/manifest"
 )
 
 // runAdd handles the "imf add" command.
@@ -17,11 +18,37 @@ import (
 // integrity verification after sealing. Files cannot be added to a sealed container.
 func runAdd() {
 	fs := flag.NewFlagSet("imf add", flag.ExitOnError)
+	symlinks := fs.String("symlinks", "", "Symlink policy: follow, store, or reject (default the container's, or follow)")
+	maxDownload := fs.Int64("max-download", container.DefaultMaxDownloadSize>>20, "Maximum size in MiB of each file fetched from a URL")
+	timeout := fs.Duration("timeout", container.DefaultDownloadTimeout, "Timeout for each URL download")
+	collisions := fs.String("collisions", "rename", "Name collision policy: rename, error, or overwrite")
+	quiet := fs.Bool("quiet", false, "Do not show a progress bar")
+	dryRun := fs.Bool("dry-run", false, "Read and hash the files and show what would be added, without writing or downloading")
 	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "Usage: imf add <container.imf> <file1> [file2 ...]")
-		fmt.Fprintln(os.Stderr, "\nAdd files to an open container.")
+		fmt.Fprintln(os.Stderr, "Usage: imf add [options] <container.imf> <file-or-url> [...]")
+		fmt.Fprintln(os.Stderr, "\nAdd files to an open container. Arguments starting with http:// or")
+		fmt.Fprintln(os.Stderr, "https:// are downloaded; the source URL and retrieval time are recorded.")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+
+	// Unset, the policy is the container's, or follow for a new one.
+	var policy manifest.SymlinkPolicy
+	if *symlinks != "" {
+		p, err := manifest.ParseSymlinkPolicy(*symlinks)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		policy = p
+	}
+
+	collisionPolicy, err := container.ParseCollisionPolicy(*collisions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
 	}
-	fs.Parse(os.Args[1:])
 
 	if fs.NArg() < 2 {
 		fs.Usage()
@@ -31,9 +58,51 @@ func runAdd() {
 	containerPath := fs.Arg(0)
 	filePaths := fs.Args()[1:]
 
-	if err := container.Add(containerPath, filePaths); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	bar := newProgressBar(*quiet)
+	report, err := container.AddWithReport(containerPath, filePaths, container.AddOptions{
+		SymlinkPolicy:   policy,
+		MaxDownloadSize: *maxDownload << 20,
+		DownloadTimeout: *timeout,
+		Collisions:      collisionPolicy,
+		Progress:        bar.progress(),
+		DryRun:          *dryRun,
+	})
+	bar.clear()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if *dryRun {
+		printAddReport(containerPath, report)
+		return
+	}
+	for _, f := range report.Files {
+		if asked, ok := report.Renamed[f.Name]; ok {
+			fmt.Println(tr("  renamed to avoid collision: %s -> %s", asked, f.Name))
+		}
+	}
+	fmt.Println(tr("Added %d file(s) to %s", len(filePaths), containerPath))
+}
+
+// printAddReport describes what a dry-run add would store.
+func printAddReport(containerPath string, r *container.AddReport) {
+	fmt.Println(tr("Dry run: %s was NOT modified. Adding would:", containerPath))
+	for _, name := range r.Replaced {
+		fmt.Println(tr("  Replace the existing entry %s", name))
+	}
+	for _, f := range r.Files {
+		if f.Download && f.Size < 0 {
+			fmt.Println(tr("  Download %s (GET, not made in a dry run)", f.Source))
+			fmt.Println(tr("    as %s, unless the server names it otherwise", f.Name))
+			continue
+		}
+		fmt.Println(tr("  Add %s (%d bytes, SHA-256 %s)", f.Name, f.Size, f.SHA256))
+		if asked, ok := r.Renamed[f.Name]; ok {
+			fmt.Println(tr("    renamed from %s to avoid a collision", asked))
+		}
+		if f.Name != f.Source {
+			fmt.Println(tr("    from %s", f.Source))
+		}
 	}
-	fmt.Printf("Added %d file(s) to %s\n", len(filePaths), containerPath)
+	fmt.Println(tr("  Rewrite %s with %d new file(s)", containerPath, len(r.Files)))
 }
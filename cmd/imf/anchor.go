@@ -4,9 +4,18 @@
 package main
 
 import (
+	"context"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/immutable-container/imf/pkg/anchor"
 	"github.com/immutable-container/imf/pkg/container"
@@ -21,18 +30,150 @@ import (
 // Usage:
 //   imf anchor archive.imf          # Submit hash and save proof
 //   imf anchor archive.imf -verify  # Verify existing proof matches container
+//   imf anchor archive.imf -upgrade # Fetch the Bitcoin attestation once confirmed
+//   imf anchor archive.imf -embed   # Move the confirmed proof into the container
+//   imf anchor archive.imf -inspect # Show what the proof contains
+//   imf anchor -batch dir/*.imf     # Anchor many containers in one submission
+//   imf anchor -watch dir           # Upgrade pending proofs as they confirm
+//   imf anchor -retry               # Anchor containers queued by seal -anchor
+//   imf anchor log                  # List everything anchored from this machine
+//   imf anchor status               # Pending vs confirmed for everything anchored
+//   imf anchor -backend notary -server URL archive.imf  # Use an HTTP notary
 func runAnchor() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "log":
+			runAnchorLog()
+			return
+		case "status":
+			runAnchorStatus()
+			return
+		}
+	}
+
 	fs := flag.NewFlagSet("imf anchor", flag.ExitOnError)
 	verify := fs.Bool("verify", false, "Verify existing .ots proof instead of creating one")
+	upgrade := fs.Bool("upgrade", false, "Upgrade a pending .ots proof with its Bitcoin attestation")
+	embed := fs.Bool("embed", false, "Upgrade the .ots proof and embed it in the container")
+	inspect := fs.Bool("inspect", false, "Print the parsed proof")
+	var servers stringList
+	fs.Var(&servers, "server", "Calendar server URL to submit to (repeatable)")
+	minCalendars := fs.Int("min-calendars", 1, "Number of calendars that must accept the submission")
+	batch := fs.Bool("batch", false, "Anchor every container given with a single calendar submission")
+	watch := fs.Bool("watch", false, "Keep polling pending proofs and upgrade them as they confirm")
+	interval := fs.Duration("interval", anchor.DefaultWatchInterval, "Time between polls with -watch")
+	webhook := fs.String("webhook", "", "URL to POST a JSON event to when a watched proof confirms")
+	notify := fs.Bool("notify", false, "Show a desktop notification when a watched proof confirms")
+	backend := fs.String("backend", "ots", "Anchoring backend: ots or notary")
+	retry := fs.Bool("retry", false, "Anchor the containers queued when seal -anchor could not")
+	proxy := fs.String("proxy", "", "Proxy for all requests: http://, https:// or socks5:// URL")
+	target := fs.String("target", anchor.TargetFile, "What to anchor: file or manifest")
+	quiet := fs.Bool("quiet", false, "Do not show a progress bar")
+	dryRun := fs.Bool("dry-run", false, "Show what would be submitted and written, without doing it")
 	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "Usage: imf anchor <container.imf> [options]")
+		fmt.Fprintln(os.Stderr, "Usage: imf anchor [options] <container.imf>")
+		fmt.Fprintln(os.Stderr, "       imf anchor -batch [options] <container.imf|dir>...")
+		fmt.Fprintln(os.Stderr, "       imf anchor -watch [options] <container.imf|dir>...")
+		fmt.Fprintln(os.Stderr, "       imf anchor -retry")
+		fmt.Fprintln(os.Stderr, "       imf anchor log | status")
 		fmt.Fprintln(os.Stderr, "\nAnchor a sealed container's hash to the Bitcoin blockchain")
 		fmt.Fprintln(os.Stderr, "via OpenTimestamps. No accounts or fees required.")
 		fmt.Fprintln(os.Stderr, "\nOptions:")
-		fmt.Fprintln(os.Stderr, "  -verify  Verify existing .ots proof matches the container")
+		fmt.Fprintln(os.Stderr, "  -verify            Verify existing .ots proof matches the container")
+		fmt.Fprintln(os.Stderr, "  -upgrade           Fetch the Bitcoin attestation for a pending proof")
+		fmt.Fprintln(os.Stderr, "  -embed             Upgrade the proof and store it inside the container")
+		fmt.Fprintln(os.Stderr, "  -inspect           Print the proof: digest, calendars, operations and")
+		fmt.Fprintln(os.Stderr, "                     attestations, and whether it is pending or complete")
+		fmt.Fprintln(os.Stderr, "  -server URL        Calendar server to submit to; repeatable")
+		fmt.Fprintln(os.Stderr, "  -min-calendars N   Fail unless N calendars accept the submission (default 1)")
+		fmt.Fprintln(os.Stderr, "  -batch             Anchor many containers (or every .imf in a directory) at")
+		fmt.Fprintln(os.Stderr, "                     once: only their Merkle root is submitted, and each gets")
+		fmt.Fprintln(os.Stderr, "                     its own .ots proof")
+		fmt.Fprintln(os.Stderr, "  -watch             Poll pending proofs and upgrade each as it confirms (with")
+		fmt.Fprintln(os.Stderr, "                     -embed, embed it too); directories are rescanned each time")
+		fmt.Fprintln(os.Stderr, "  -interval D        Time between polls with -watch (default 10m)")
+		fmt.Fprintln(os.Stderr, "  -webhook URL       POST a JSON event to URL when a watched proof confirms")
+		fmt.Fprintln(os.Stderr, "  -notify            Show a desktop notification when a watched proof confirms")
+		fmt.Fprintln(os.Stderr, "  -backend NAME      ots (OpenTimestamps, default) or notary, a generic HTTP")
+		fmt.Fprintln(os.Stderr, "                     service that is POSTed the hash and returns a receipt,")
+		fmt.Fprintln(os.Stderr, "                     saved as <container.imf>.receipt; only anchoring and")
+		fmt.Fprintln(os.Stderr, "                     -verify apply to it")
+		fmt.Fprintln(os.Stderr, "  -retry             Anchor the containers 'imf seal -anchor' could not, kept in")
+		fmt.Fprintln(os.Stderr, "                     ~/.imf/anchor-queue.jsonl (or $IMF_ANCHOR_QUEUE)")
+		fmt.Fprintln(os.Stderr, "  -target WHAT       file (default): the container file, or manifest: the signed")
+		fmt.Fprintln(os.Stderr, "                     manifest, whose proof survives re-zipping the same content")
+		fmt.Fprintln(os.Stderr, "  -proxy URL         Send every request through this proxy, e.g. Tor at")
+		fmt.Fprintln(os.Stderr, "                     socks5://127.0.0.1:9050 (default: $HTTPS_PROXY, $HTTP_PROXY)")
+		fmt.Fprintln(os.Stderr, "  -dry-run           Show the hash that would be submitted, the requests, and the")
+		fmt.Fprintln(os.Stderr, "                     files that would be written, without contacting anyone")
+		fmt.Fprintln(os.Stderr, "  -quiet             Do not show a progress bar")
+		fmt.Fprintln(os.Stderr, "\nWithout -server, calendars are read from ~/.imf/calendars (or $IMF_CALENDARS),")
+		fmt.Fprintln(os.Stderr, "one URL per line, falling back to the public OpenTimestamps calendars.")
+		fmt.Fprintln(os.Stderr, "IMF_EXPLORER_URL selects the Esplora API used to look up blocks.")
+		fmt.Fprintln(os.Stderr, "The notary is the -server URL or $IMF_NOTARY_URL; $IMF_NOTARY_TOKEN, if set,")
+		fmt.Fprintln(os.Stderr, "is sent to it as a bearer token.")
+		fmt.Fprintln(os.Stderr, "\nEvery anchor is recorded in ~/.imf/anchors.jsonl (or $IMF_ANCHOR_LOG):")
+		fmt.Fprintln(os.Stderr, "'imf anchor log' lists them and 'imf anchor status' reports which are still")
+		fmt.Fprintln(os.Stderr, "pending and which are confirmed.")
+	}
+	parseFlags(fs)
+
+	// Calendars and explorers see the IP address of whoever anchors, and
+	// when; a proxy keeps that private.
+	if *proxy != "" {
+		if err := anchor.SetProxy(*proxy); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
 	}
-	fs.Parse(os.Args[1:])
 
+	if *dryRun && (*verify || *upgrade || *embed || *inspect || *batch || *watch || *retry) {
+		fmt.Fprintln(os.Stderr, "Error: -dry-run applies only to anchoring a single container")
+		os.Exit(1)
+	}
+
+	if *retry {
+		if fs.NArg() != 0 {
+			fs.Usage()
+			os.Exit(1)
+		}
+		runAnchorRetry()
+		return
+	}
+	if *backend != "ots" {
+		if *upgrade || *embed || *batch || *watch {
+			fmt.Fprintf(os.Stderr, "Error: -upgrade, -embed, -batch and -watch need the ots backend, not %s\n", *backend)
+			os.Exit(1)
+		}
+		if fs.NArg() != 1 {
+			fs.Usage()
+			os.Exit(1)
+		}
+		if *dryRun {
+			mustBeSealed(fs.Arg(0))
+			runAnchorDryRun(fs.Arg(0), mustAnchorer(*backend, servers, *minCalendars, *target))
+			return
+		}
+		runAnchorBackend(fs.Arg(0), mustAnchorer(*backend, servers, *minCalendars, *target), *verify)
+		return
+	}
+
+	if *watch {
+		if *verify || *upgrade || *batch || fs.NArg() == 0 {
+			fs.Usage()
+			os.Exit(1)
+		}
+		runAnchorWatch(fs.Args(), *interval, *embed, *webhook, *notify)
+		return
+	}
+	if *batch {
+		if *verify || *upgrade || *embed || fs.NArg() == 0 {
+			fs.Usage()
+			os.Exit(1)
+		}
+		runAnchorBatch(fs.Args(), anchorOptions(servers, *minCalendars, *target), newProgressBar(*quiet))
+		return
+	}
 	if fs.NArg() != 1 {
 		fs.Usage()
 		os.Exit(1)
@@ -40,50 +181,738 @@ func runAnchor() {
 
 	containerPath := fs.Arg(0)
 
+	if *inspect {
+		if *verify || *upgrade || *embed {
+			fmt.Fprintln(os.Stderr, "Error: -inspect cannot be combined with -verify, -upgrade or -embed")
+			os.Exit(1)
+		}
+		runAnchorInspect(containerPath)
+		return
+	}
+
 	// Verify the container is sealed before anchoring — anchoring an open
 	// container would be pointless since its contents can still change.
-	info, err := container.GetInfo(containerPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	mustBeSealed(containerPath)
+
+	if *dryRun {
+		runAnchorDryRun(containerPath, mustAnchorer(*backend, servers, *minCalendars, *target))
+		return
 	}
-	if info.State != "sealed" {
-		fmt.Fprintln(os.Stderr, "Error: container must be sealed before anchoring")
-		fmt.Fprintln(os.Stderr, "  Run: imf seal <container.imf> -key <private.pem>")
+
+	if *verify && (*upgrade || *embed) {
+		fmt.Fprintln(os.Stderr, "Error: -verify cannot be combined with -upgrade or -embed")
 		os.Exit(1)
 	}
 
-	if *verify {
+	if *embed {
+		// Embed mode: complete the proof, then move it into the container
+		// so a single file carries both content and proof.
+		result, err := anchor.UpgradeWithOptions(containerPath, anchor.UpgradeOptions{
+			Explorer: os.Getenv("IMF_EXPLORER_URL"),
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		if result.Embedded {
+			if jsonOutput {
+				printJSON(newAnchorUpgradeJSON(containerPath, result))
+				return
+			}
+			fmt.Println("Proof is already embedded in the container.")
+			return
+		}
+		if !result.Complete() {
+			fmt.Fprintln(os.Stderr, "Error: proof is still pending — it can be embedded once the calendar has")
+			fmt.Fprintln(os.Stderr, "  committed to Bitcoin, usually within a few hours.")
+			os.Exit(1)
+		}
+		proof, err := anchor.ManifestProof(containerPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		if err := container.EmbedAnchor(containerPath, proof); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		// The loose proof covered the file as it was before embedding, so
+		// it no longer matches.
+		os.Remove(result.ProofPath)
+
+		if jsonOutput {
+			j := newAnchorUpgradeJSON(containerPath, result)
+			j.Proof, j.Embedded = containerPath, true
+			printJSON(j)
+			return
+		}
+		fmt.Printf("Proof embedded in %s\n", containerPath)
+		fmt.Printf("  Bitcoin block:  %s\n", joinHeights(proof.Blocks()))
+		fmt.Printf("  Removed:        %s\n", result.ProofPath)
+		fmt.Println("\n  The container now carries its own blockchain timestamp.")
+		fmt.Println("  Verify anytime: imf anchor <container.imf> -verify")
+		return
+	}
+
+	if *upgrade {
+		// Upgrade mode: ask the calendars for the completed attestation.
+		if !jsonOutput {
+			fmt.Printf("Upgrading proof for %s...\n", containerPath)
+		}
+
+		result, err := anchor.UpgradeWithOptions(containerPath, anchor.UpgradeOptions{
+			Explorer: os.Getenv("IMF_EXPLORER_URL"),
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		if jsonOutput {
+			printJSON(newAnchorUpgradeJSON(containerPath, result))
+			return
+		}
+
+		if !result.Complete() {
+			fmt.Println("Still pending — the calendars have not yet committed to Bitcoin.")
+			for _, c := range result.Pending {
+				fmt.Printf("  Waiting on:     %s\n", c)
+			}
+			fmt.Println("\n  This usually takes a few hours. Try again later.")
+			return
+		}
+
+		fmt.Println("Proof complete!")
+		for _, b := range result.Blocks {
+			if b.Time.IsZero() {
+				fmt.Printf("  Bitcoin block:  %d (time unavailable — explorer unreachable)\n", b.Height)
+			} else {
+				fmt.Printf("  Bitcoin block:  %d (%s)\n", b.Height, b.Time.Format("2006-01-02 15:04:05 MST"))
+			}
+		}
+		if result.Embedded {
+			fmt.Println("  Proof file:     embedded in the container")
+		} else if result.Upgraded {
+			fmt.Printf("  Proof updated:  %s\n", result.ProofPath)
+		} else {
+			fmt.Printf("  Proof file:     %s (already complete)\n", result.ProofPath)
+		}
+		for _, c := range result.Pending {
+			fmt.Printf("  Still pending:  %s\n", c)
+		}
+		if !result.Embedded {
+			fmt.Println("\n  Embed it in the container: imf anchor <container.imf> -embed")
+		}
+	} else if *verify {
 		// Verify mode: check that existing .ots proof matches the container.
-		result, err := anchor.VerifyAnchor(containerPath)
+		result, err := mustAnchorer(*backend, servers, *minCalendars, *target).Verify(containerPath)
+		if jsonOutput {
+			printAnchorVerifyJSON(containerPath, *backend, result, err)
+			return
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "FAILED: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 		fmt.Println("OK — proof matches container")
 		fmt.Printf("  Container hash: %s\n", result.ContainerHash)
-		fmt.Printf("  Proof file:     %s\n", result.ProofPath)
+		fmt.Printf("  Anchored:       %s\n", describeTarget(result.Target))
+		if result.Embedded {
+			fmt.Println("  Proof file:     embedded in the container")
+		} else {
+			fmt.Printf("  Proof file:     %s\n", result.ProofPath)
+		}
 		fmt.Printf("  Proof size:     %d bytes\n", result.ProofSize)
 		fmt.Println("\n  Note: For full Bitcoin verification, use the OpenTimestamps")
 		fmt.Println("  verifier at https://opentimestamps.org or the ots CLI tool.")
 	} else {
 		// Anchor mode: submit hash to OpenTimestamps.
-		fmt.Printf("Anchoring %s to Bitcoin via OpenTimestamps...\n", containerPath)
+		if !jsonOutput {
+			fmt.Printf("Anchoring %s to Bitcoin via OpenTimestamps...\n", containerPath)
+		}
 
-		result, err := anchor.AnchorContainer(containerPath)
+		bar := newProgressBar(*quiet)
+		result, err := bar.anchorer(mustAnchorer(*backend, servers, *minCalendars, *target)).Anchor(containerPath)
+		bar.clear()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		if jsonOutput {
+			printJSON(newAnchorJSON(containerPath, *backend, result))
+			return
 		}
 
 		fmt.Println("Anchored successfully!")
 		fmt.Printf("  Container hash: %s\n", result.ContainerHash)
+		fmt.Printf("  Anchored:       %s (%s)\n", describeTarget(result.Target), result.Digest)
 		fmt.Printf("  Proof saved:    %s\n", result.ProofPath)
-		fmt.Printf("  Server:         %s\n", result.Server)
+		for _, server := range result.Servers {
+			fmt.Printf("  Server:         %s\n", server)
+		}
 		fmt.Printf("  Submitted:      %s\n", result.Timestamp.Format("2006-01-02 15:04:05 MST"))
 		fmt.Println("\n  The proof will be confirmed on the Bitcoin blockchain within")
 		fmt.Println("  a few hours. Keep the .ots file alongside your .imf container.")
+		fmt.Println("  Then complete it: imf anchor <container.imf> -upgrade")
 		fmt.Println("  Verify anytime: imf anchor <container.imf> -verify")
 		fmt.Println("  Full verification: https://opentimestamps.org")
 	}
 }
+
+// runAnchorInspect prints a container's proof. It reads only local files.
+func runAnchorInspect(containerPath string) {
+	proof, where, err := anchor.LoadProof(containerPath)
+	if err == nil && proof == nil {
+		err = fmt.Errorf("no proof found: %s.ots does not exist and none is embedded", containerPath)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	status, err := anchor.Status(containerPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	counts := map[string]int{}
+	proof.Timestamp.Walk(func(n *anchor.Timestamp) {
+		for _, a := range n.Attestations {
+			switch {
+			case a.Tag == anchor.PendingAttestation("").Tag:
+				counts["pending"]++
+			case a.Tag == anchor.BitcoinAttestation(0).Tag:
+				counts["bitcoin"]++
+			default:
+				counts["other"]++
+			}
+		}
+	})
+
+	if jsonOutput {
+		j := anchorInspectJSON{
+			Container:    containerPath,
+			Proof:        where,
+			Embedded:     where == containerPath,
+			Size:         len(proof.Marshal()),
+			Digest:       hex.EncodeToString(proof.Digest),
+			Matches:      status.State != anchor.StateMismatch,
+			Complete:     len(proof.Blocks()) > 0,
+			Blocks:       heightBlocksJSON(proof.Blocks()),
+			Calendars:    nonNil(proof.Calendars()),
+			Attestations: counts,
+			Tree:         newProofNodeJSON(proof.Timestamp),
+		}
+		if j.Matches {
+			j.Target = status.Target
+		}
+		printJSON(j)
+		return
+	}
+
+	fmt.Printf("Proof for %s\n", containerPath)
+	if where == containerPath {
+		fmt.Printf("  Proof:        embedded in the container (%d bytes)\n", len(proof.Marshal()))
+	} else {
+		fmt.Printf("  Proof:        %s (%d bytes)\n", where, len(proof.Marshal()))
+	}
+	fmt.Printf("  Digest:       %s\n", hex.EncodeToString(proof.Digest))
+	switch status.State {
+	case anchor.StateMismatch:
+		fmt.Println("  Matches:      NO — the container has changed since it was anchored")
+	default:
+		fmt.Printf("  Matches:      SHA-256 of the %s\n", describeTarget(status.Target))
+	}
+	if heights := proof.Blocks(); len(heights) > 0 {
+		fmt.Printf("  Status:       complete, Bitcoin block %s\n", joinHeights(heights))
+	} else {
+		fmt.Println("  Status:       pending — no calendar has committed to Bitcoin yet")
+	}
+	for _, c := range proof.Calendars() {
+		fmt.Printf("  Waiting on:   %s\n", c)
+	}
+
+	var kinds []string
+	for _, k := range [][2]string{{"bitcoin", "Bitcoin"}, {"pending", "pending"}, {"other", "other"}} {
+		if counts[k[0]] > 0 {
+			kinds = append(kinds, fmt.Sprintf("%d %s", counts[k[0]], k[1]))
+		}
+	}
+	fmt.Printf("  Attestations: %s\n", strings.Join(kinds, ", "))
+
+	fmt.Println("\nOperations (from the digest):")
+	for _, line := range strings.Split(strings.TrimRight(proof.Timestamp.Format(), "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+// runAnchorBatch anchors every container in args, expanding directories to
+// the .imf files in them, with a single calendar submission, shown on bar as
+// the calendars answer.
+func runAnchorBatch(args []string, opts anchor.AnchorOptions, bar *progressBar) {
+	var paths []string
+	for _, arg := range args {
+		if st, err := os.Stat(arg); err == nil && st.IsDir() {
+			matches, _ := filepath.Glob(filepath.Join(arg, "*.imf"))
+			paths = append(paths, matches...)
+			continue
+		}
+		paths = append(paths, arg)
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no .imf containers found")
+		os.Exit(1)
+	}
+	for _, path := range paths {
+		mustBeSealed(path)
+	}
+
+	if !jsonOutput {
+		fmt.Printf("Anchoring %d container(s) to Bitcoin via OpenTimestamps...\n", len(paths))
+	}
+	opts.Progress = bar.calendars()
+	results, err := anchor.AnchorBatch(paths, opts)
+	bar.clear()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if jsonOutput {
+		out := make([]anchorJSON, len(results))
+		for i, r := range results {
+			out[i] = newAnchorJSON(paths[i], "ots", r)
+		}
+		printJSON(out)
+		return
+	}
+
+	fmt.Println("Anchored successfully!")
+	fmt.Printf("  Merkle root:    %s\n", results[0].MerkleRoot)
+	for _, server := range results[0].Servers {
+		fmt.Printf("  Server:         %s\n", server)
+	}
+	fmt.Printf("  Submitted:      %s\n", results[0].Timestamp.Format("2006-01-02 15:04:05 MST"))
+	fmt.Printf("  Proofs saved:   %d\n", len(results))
+	for _, r := range results {
+		fmt.Printf("    %s\n", r.ProofPath)
+	}
+	fmt.Println("\n  Each proof is independent: upgrade, embed, and verify them")
+	fmt.Println("  one container at a time, as for a single anchor.")
+}
+
+// runAnchorWatch polls the pending proofs of the containers in paths until
+// interrupted, or until they are all complete if paths names no directory.
+func runAnchorWatch(paths []string, interval time.Duration, embed bool, webhook string, notify bool) {
+	// report prints an event as a timestamped line, or with --json as a
+	// line of JSON.
+	report := func(e watchEventJSON, text string) {
+		e.Time = time.Now().UTC()
+		if jsonOutput {
+			printJSONLine(e)
+			return
+		}
+		fmt.Printf("%s  %s: %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Container, text)
+	}
+	failed := func(path string, err error) {
+		report(watchEventJSON{Event: "error", Container: path, Error: err.Error()}, err.Error())
+	}
+	opts := anchor.WatchOptions{
+		Interval: interval,
+		Upgrade:  anchor.UpgradeOptions{Explorer: os.Getenv("IMF_EXPLORER_URL")},
+		OnError:  failed,
+		OnConfirm: func(path string, r *anchor.UpgradeResult) {
+			heights := make([]uint64, len(r.Blocks))
+			for i, b := range r.Blocks {
+				heights[i] = b.Height
+			}
+			report(watchEventJSON{Event: "confirmed", Container: path, Blocks: newBlocksJSON(r.Blocks)},
+				"confirmed in Bitcoin block "+joinHeights(heights))
+			if embed {
+				proof, err := anchor.ManifestProof(path)
+				if err == nil {
+					err = container.EmbedAnchor(path, proof)
+				}
+				if err != nil {
+					failed(path, fmt.Errorf("embedding proof: %w", err))
+				} else {
+					os.Remove(r.ProofPath)
+					report(watchEventJSON{Event: "embedded", Container: path}, "proof embedded in the container")
+				}
+			}
+			if webhook != "" {
+				if err := anchor.PostWebhook(webhook, path, r); err != nil {
+					failed(path, err)
+				}
+			}
+			if notify {
+				notifyDesktop("IMF anchor confirmed", fmt.Sprintf("%s is anchored in Bitcoin block %s", filepath.Base(path), joinHeights(heights)))
+			}
+		},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if jsonOutput {
+		anchor.Watch(ctx, paths, opts)
+		return
+	}
+	fmt.Printf("Watching pending proofs every %s (Ctrl-C to stop)...\n", interval)
+	if err := anchor.Watch(ctx, paths, opts); err == nil {
+		fmt.Println("Nothing left to watch.")
+	}
+}
+
+// notifyDesktop shows a desktop notification where the platform has a
+// standard way to, and does nothing otherwise.
+func notifyDesktop(title, message string) {
+	switch runtime.GOOS {
+	case "darwin":
+		quote := func(s string) string {
+			return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+		}
+		exec.Command("osascript", "-e", "display notification "+quote(message)+" with title "+quote(title)).Run()
+	case "linux":
+		exec.Command("notify-send", title, message).Run()
+	case "windows":
+		quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+		script := "Add-Type -AssemblyName System.Windows.Forms; " +
+			"$n = New-Object System.Windows.Forms.NotifyIcon; " +
+			"$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; " +
+			"$n.ShowBalloonTip(10000, " + quote(title) + ", " + quote(message) + ", 'Info'); " +
+			"Start-Sleep -Seconds 10; $n.Dispose()"
+		exec.Command("powershell", "-NoProfile", "-Command", script).Start()
+	}
+}
+
+// runAnchorLog handles "imf anchor log", listing the anchor log.
+func runAnchorLog() {
+	records := mustReadAnchorLog()
+	if jsonOutput {
+		printJSON(nonNil(records))
+		return
+	}
+	for _, r := range records {
+		fmt.Printf("%s  %-6s  %s\n", r.Time.Local().Format("2006-01-02 15:04:05"), r.Backend, r.Container)
+		fmt.Printf("    hash:    %s\n", r.Hash)
+		fmt.Printf("    proof:   %s\n", r.Proof)
+		fmt.Printf("    servers: %s\n", strings.Join(r.Servers, ", "))
+	}
+	fmt.Printf("%d anchor(s) recorded.\n", len(records))
+}
+
+// runAnchorStatus handles "imf anchor status", reporting the current state
+// of the latest anchor of each container in the anchor log. It reads only
+// local files; "imf anchor -upgrade" or -watch completes pending proofs.
+func runAnchorStatus() {
+	records := mustReadAnchorLog()
+	latest := map[string]anchor.Record{}
+	var order []string
+	for _, r := range records {
+		if _, ok := latest[r.Container]; !ok {
+			order = append(order, r.Container)
+		}
+		latest[r.Container] = r
+	}
+
+	if jsonOutput {
+		out := []anchorStateJSON{}
+		for _, path := range order {
+			state, detail := anchorRecordState(latest[path])
+			out = append(out, anchorStateJSON{Container: path, State: state, Detail: detail})
+		}
+		printJSON(out)
+		return
+	}
+
+	counts := map[string]int{}
+	for _, path := range order {
+		r := latest[path]
+		state, detail := anchorRecordState(r)
+		counts[state]++
+		fmt.Printf("%-10s %s", state, path)
+		if detail != "" {
+			fmt.Printf(" (%s)", detail)
+		}
+		fmt.Println()
+	}
+	var summary []string
+	for _, state := range []string{"confirmed", "notarized", "pending", "mismatch", "missing"} {
+		if counts[state] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[state], state))
+		}
+	}
+	if len(summary) == 0 {
+		summary = []string{"nothing anchored yet"}
+	}
+	fmt.Printf("\n%s.\n", strings.Join(summary, ", "))
+}
+
+// anchorRecordState returns the state of an anchor log record: confirmed,
+// pending, notarized, mismatch, or missing, with a detail for display.
+func anchorRecordState(r anchor.Record) (state, detail string) {
+	if _, err := os.Stat(r.Container); err != nil {
+		return "missing", "container not found"
+	}
+	if r.Backend == "notary" {
+		if _, err := (&anchor.Notary{}).Verify(r.Container); err != nil {
+			return "mismatch", err.Error()
+		}
+		return "notarized", r.Proof
+	}
+	status, err := anchor.Status(r.Container)
+	if err != nil {
+		return "mismatch", err.Error()
+	}
+	if status == nil {
+		return "missing", "proof not found"
+	}
+	switch status.State {
+	case anchor.StateConfirmed:
+		heights := make([]uint64, len(status.Blocks))
+		for i, b := range status.Blocks {
+			heights[i] = b.Height
+		}
+		detail = "Bitcoin block " + joinHeights(heights)
+		if status.Embedded {
+			detail += ", embedded"
+		}
+		return "confirmed", detail
+	case anchor.StatePending:
+		return "pending", "anchored " + r.Time.Local().Format("2006-01-02 15:04")
+	}
+	return "mismatch", "container changed since anchoring"
+}
+
+// mustReadAnchorLog reads the anchor log, exiting on failure.
+func mustReadAnchorLog() []anchor.Record {
+	records, err := anchor.ReadLog()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	return records
+}
+
+// runAnchorRetry anchors the containers in the anchor queue.
+func runAnchorRetry() {
+	results, err := anchor.RetryQueued(func(a anchor.Anchorer) {
+		if n, ok := a.(*anchor.Notary); ok {
+			n.Token = os.Getenv("IMF_NOTARY_TOKEN")
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if jsonOutput {
+		out := []anchorRetryJSON{}
+		failed := false
+		for _, r := range results {
+			j := anchorRetryJSON{Container: r.Entry.Container, Backend: r.Entry.Backend}
+			switch {
+			case r.Dropped:
+				j.Result = "dropped"
+			case r.Err != nil:
+				j.Result, j.Error, failed = "queued", r.Err.Error(), true
+			default:
+				j.Result, j.Proof = "anchored", r.Result.ProofPath
+			}
+			out = append(out, j)
+		}
+		printJSON(out)
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+	if len(results) == 0 {
+		fmt.Println("No containers are waiting to be anchored.")
+		return
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Dropped {
+			fmt.Printf("  dropped       %s: container no longer exists\n", r.Entry.Container)
+			continue
+		}
+		if r.Err != nil {
+			failed++
+			fmt.Printf("  still queued  %s: %v\n", r.Entry.Container, r.Err)
+			continue
+		}
+		fmt.Printf("  anchored      %s -> %s\n", r.Entry.Container, r.Result.ProofPath)
+	}
+	fmt.Printf("\n%d processed, %d still queued.\n", len(results), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runAnchorBackend anchors, or with verify checks the receipt of, a
+// container through a backend other than OpenTimestamps.
+func runAnchorBackend(containerPath string, a anchor.Anchorer, verify bool) {
+	mustBeSealed(containerPath)
+	if verify {
+		result, err := a.Verify(containerPath)
+		if jsonOutput {
+			printAnchorVerifyJSON(containerPath, a.Name(), result, err)
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "FAILED: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Println("OK — receipt matches container")
+		fmt.Printf("  Container hash: %s\n", result.ContainerHash)
+		fmt.Printf("  Anchored:       %s\n", describeTarget(result.Target))
+		fmt.Printf("  Receipt file:   %s\n", result.ProofPath)
+		fmt.Printf("  Receipt size:   %d bytes\n", result.ProofSize)
+		fmt.Println("\n  Note: what the receipt proves depends on the service that issued")
+		fmt.Println("  it; check it with that service.")
+		return
+	}
+
+	if !jsonOutput {
+		fmt.Printf("Anchoring %s via %s...\n", containerPath, a.Name())
+	}
+	result, err := a.Anchor(containerPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if jsonOutput {
+		printJSON(newAnchorJSON(containerPath, a.Name(), result))
+		return
+	}
+	fmt.Println("Anchored successfully!")
+	fmt.Printf("  Container hash: %s\n", result.ContainerHash)
+	fmt.Printf("  Anchored:       %s (%s)\n", describeTarget(result.Target), result.Digest)
+	fmt.Printf("  Receipt saved:  %s\n", result.ProofPath)
+	fmt.Printf("  Server:         %s\n", result.Server)
+	fmt.Printf("  Submitted:      %s\n", result.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	fmt.Printf("\n  Verify anytime: imf anchor -backend %s <container.imf> -verify\n", a.Name())
+}
+
+// printAnchorVerifyJSON prints the outcome of -verify as JSON, exiting
+// non-zero if err is set.
+func printAnchorVerifyJSON(containerPath, backend string, r *anchor.VerifyResult, err error) {
+	j := anchorVerifyJSON{Container: containerPath, Backend: backend}
+	if err != nil {
+		j.Error = err.Error()
+		printJSON(j)
+		os.Exit(1)
+	}
+	j.Verified = true
+	j.ContainerHash, j.Target, j.Proof, j.ProofSize, j.Embedded = r.ContainerHash, r.Target, r.ProofPath, r.ProofSize, r.Embedded
+	printJSON(j)
+}
+
+// describeTarget says what an anchor target is the hash of.
+func describeTarget(target string) string {
+	if target == anchor.TargetManifest {
+		return tr("signed manifest")
+	}
+	return tr("container file")
+}
+
+// mustAnchorer returns the anchoring backend called name, configured from
+// the -server flags or the environment.
+func mustAnchorer(name string, servers []string, minCalendars int, target string) anchor.Anchorer {
+	switch name {
+	case "ots":
+		servers = anchorOptions(servers, minCalendars, target).Calendars
+	case "notary":
+		if len(servers) == 0 && os.Getenv("IMF_NOTARY_URL") != "" {
+			servers = []string{os.Getenv("IMF_NOTARY_URL")}
+		}
+	}
+	a, err := anchor.NewAnchorer(name, servers, minCalendars, target)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if n, ok := a.(*anchor.Notary); ok {
+		n.Token = os.Getenv("IMF_NOTARY_TOKEN")
+	}
+	return a
+}
+
+// anchorOptions returns the calendars to submit to: those given with
+// -server, else those in the calendar list file, else the defaults.
+func anchorOptions(servers []string, minCalendars int, target string) anchor.AnchorOptions {
+	calendars := servers
+	if len(calendars) == 0 {
+		var err error
+		if calendars, err = configuredCalendars(); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+	}
+	return anchor.AnchorOptions{Calendars: calendars, MinCalendars: minCalendars, Target: target}
+}
+
+// runAnchorDryRun describes what anchoring containerPath with a would send
+// and write, without doing either.
+func runAnchorDryRun(containerPath string, a anchor.Anchorer) {
+	plan, err := anchor.Plan(containerPath, a)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if jsonOutput {
+		printJSON(newAnchorPlanJSON(containerPath, plan))
+		return
+	}
+	fmt.Println(tr("Dry run: nothing was submitted for %s. Anchoring would:", containerPath))
+	fmt.Println(tr("  Anchor %s (%s)", describeTarget(plan.Target), plan.Digest))
+	if plan.Backend == "ots" {
+		fmt.Println(tr("  Submit a hash of it with a random nonce, so the calendars do not learn it:"))
+	} else {
+		fmt.Println(tr("  Submit it to the notary:"))
+	}
+	for _, r := range plan.Requests {
+		fmt.Printf("    %s\n", r)
+	}
+	if len(plan.Requests) > 1 {
+		fmt.Println(tr("  Require %d of %d to accept", plan.MinAccepted, len(plan.Requests)))
+	}
+	if plan.Overwrites {
+		fmt.Println(tr("  Overwrite the proof %s", plan.ProofPath))
+	} else {
+		fmt.Println(tr("  Write the proof %s", plan.ProofPath))
+	}
+	if plan.LogPath != "" {
+		fmt.Println(tr("  Record the anchor in %s", plan.LogPath))
+	}
+}
+
+// mustBeSealed exits unless the container at path is sealed.
+func mustBeSealed(path string) {
+	info, err := container.GetInfo(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+	if info.State != "sealed" {
+		fmt.Fprintf(os.Stderr, "Error: %s: container must be sealed before anchoring\n", path)
+		fmt.Fprintln(os.Stderr, "  Run: imf seal <container.imf> -key <private.pem>")
+		os.Exit(1)
+	}
+}
+
+// joinHeights formats block heights as a comma-separated list.
+func joinHeights(heights []uint64) string {
+	s := make([]string, len(heights))
+	for i, h := range heights {
+		s[i] = strconv.FormatUint(h, 10)
+	}
+	return strings.Join(s, ", ")
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/immutable-container/imf/pkg/update"
+)
+
+// imfMIMEType is the MIME type registered for .imf files on Linux.
+const imfMIMEType = "application/x-imf"
+
+// windowsProgID is the registry class .imf files are associated with on
+// Windows.
+const windowsProgID = "IMF.Container"
+
+// runInstallAssociation handles the "imf install-association" command.
+// Registers the .imf extension for the current user so that double-clicking
+// a container runs "imf gui <file>": on Linux, a shared-mime-info type and a
+// desktop entry under ~/.local/share; on Windows, a class under
+// HKEY_CURRENT_USER\Software\Classes. No administrator rights are needed.
+// On macOS the IMF Viewer app declares the association itself. With
+// -uninstall, the registration is removed again.
+func runInstallAssociation() {
+	fs := flag.NewFlagSet("imf install-association", flag.ExitOnError)
+	uninstall := fs.Bool("uninstall", false, "Remove the association instead")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf install-association [-uninstall]")
+		fmt.Fprintln(os.Stderr, "\nOpen .imf files in 'imf gui' when they are double-clicked.")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	exe, err := update.Executable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if *uninstall {
+			err = uninstallXDG()
+		} else {
+			err = installXDG(exe)
+		}
+	case "windows":
+		if *uninstall {
+			err = uninstallWindows()
+		} else {
+			err = installWindows(exe)
+		}
+	case "darwin":
+		err = errors.New("on macOS, install the IMF Viewer app, which registers .imf files itself")
+	default:
+		err = fmt.Errorf("file associations are not supported on %s", runtime.GOOS)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if *uninstall {
+		fmt.Println("Removed the .imf file association")
+		return
+	}
+	fmt.Printf("Registered .imf files to open with %s gui\n", exe)
+}
+
+// xdgDataHome returns $XDG_DATA_HOME, or ~/.local/share.
+func xdgDataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// xdgMIMEPackage declares the .imf MIME type to shared-mime-info. Containers
+// are ZIP files, so the type is a subclass of application/zip.
+const xdgMIMEPackage = `<?xml version="1.0" encoding="UTF-8"?>
+<mime-info xmlns="http://www.freedesktop.org/standards/shared-mime-info">
+  <mime-type type="` + imfMIMEType + `">
+    <comment>Immutable File container</comment>
+    <sub-class-of type="application/zip"/>
+    <glob pattern="*.imf"/>
+  </mime-type>
+</mime-info>
+`
+
+// installXDG registers the MIME type and a desktop entry that opens it with
+// exe, and makes that entry the type's default application. The database
+// updates are best effort: desktops without the tools pick the files up on
+// their next scan.
+func installXDG(exe string) error {
+	data, err := xdgDataHome()
+	if err != nil {
+		return err
+	}
+	mimeDir := filepath.Join(data, "mime")
+	appsDir := filepath.Join(data, "applications")
+	for _, dir := range []string{filepath.Join(mimeDir, "packages"), appsDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(mimeDir, "packages", "imf.xml"), []byte(xdgMIMEPackage), 0644); err != nil {
+		return err
+	}
+	entry := "[Desktop Entry]\n" +
+		"Type=Application\n" +
+		"Name=IMF Viewer\n" +
+		"Comment=Open and verify Immutable File containers\n" +
+		"Exec=" + desktopExecQuote(exe) + " gui %f\n" +
+		"MimeType=" + imfMIMEType + ";\n" +
+		"Terminal=false\n" +
+		"NoDisplay=true\n"
+	if err := os.WriteFile(filepath.Join(appsDir, "imf.desktop"), []byte(entry), 0644); err != nil {
+		return err
+	}
+
+	exec.Command("update-mime-database", mimeDir).Run()
+	exec.Command("update-desktop-database", appsDir).Run()
+	exec.Command("xdg-mime", "default", "imf.desktop", imfMIMEType).Run()
+	return nil
+}
+
+// uninstallXDG removes what installXDG wrote.
+func uninstallXDG() error {
+	data, err := xdgDataHome()
+	if err != nil {
+		return err
+	}
+	mimeDir := filepath.Join(data, "mime")
+	appsDir := filepath.Join(data, "applications")
+	for _, path := range []string{filepath.Join(mimeDir, "packages", "imf.xml"), filepath.Join(appsDir, "imf.desktop")} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	exec.Command("update-mime-database", mimeDir).Run()
+	exec.Command("update-desktop-database", appsDir).Run()
+	return nil
+}
+
+// desktopExecQuote quotes path for the Exec key of a desktop entry, where
+// an argument with spaces or reserved characters must be double-quoted and
+// `"`, "`", "$" and "\" escaped inside the quotes.
+func desktopExecQuote(path string) string {
+	if !strings.ContainsAny(path, " \t\n\"'\\><~|&;$*?#()`") {
+		return path
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	// The desktop entry format unescapes string values before the Exec
+	// rules apply, so each backslash is written twice more.
+	return strings.ReplaceAll(`"`+r.Replace(path)+`"`, `\`, `\\`)
+}
+
+// installWindows registers the .imf extension under the current user's
+// classes with reg.exe, opening it with exe.
+func installWindows(exe string) error {
+	classes := `HKCU\Software\Classes`
+	command := `"` + exe + `" gui "%1"`
+	for _, args := range [][]string{
+		{classes + `\.imf`, "/ve", "/d", windowsProgID},
+		{classes + `\.imf`, "/v", "Content Type", "/d", imfMIMEType},
+		{classes + `\` + windowsProgID, "/ve", "/d", "Immutable File container"},
+		{classes + `\` + windowsProgID + `\DefaultIcon`, "/ve", "/d", `"` + exe + `",0`},
+		{classes + `\` + windowsProgID + `\shell\open\command`, "/ve", "/d", command},
+	} {
+		if err := regCommand(append([]string{"add"}, append(args, "/f")...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uninstallWindows removes what installWindows wrote.
+func uninstallWindows() error {
+	classes := `HKCU\Software\Classes`
+	for _, key := range []string{classes + `\.imf`, classes + `\` + windowsProgID} {
+		if err := regCommand("delete", key, "/f"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// regCommand runs reg.exe with args, returning its output as the error if
+// it fails.
+func regCommand(args ...string) error {
+	out, err := exec.Command("reg", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("reg %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
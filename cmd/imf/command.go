@@ -0,0 +1,150 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// command is an imf command, run as "imf <name> ...". Its run function sees
+// os.Args with the command name folded into os.Args[0], so it can parse
+// os.Args[1:] as its own command line.
+type command struct {
+	name    string
+	summary string
+	run     func()
+	json    bool // prints JSON with --json
+}
+
+// commands are the imf commands, in the order help lists them.
+var commands = []command{
+	{"create", "Create a new empty .imf container", runCreate, false},
+	{"add", "Add files to an open container", runAdd, false},
+	{"seal", "Seal a container (sign, optionally encrypt)", runSeal, true},
+	{"pack", "Create, add a directory, and seal in one step", runPack, false},
+	{"watch", "Seal each item dropped into a directory as it arrives", runWatch, true},
+	{"reseal", "Re-seal a container with a new key and manifest version", runReseal, false},
+	{"cosign", "Add a signature to a container with a signature policy", runCosign, false},
+	{"witness", "Countersign a sealed container as a witness", runWitness, false},
+	{"sign", "Write a detached signature over a sealed container file", runSign, false},
+	{"sign-file", "Write a detached signature over any file", runSignFile, false},
+	{"export", "Export a sealed container to tar.gz with its signed manifest", runExport, false},
+	{"verify", "Verify a sealed container's integrity", runVerify, true},
+	{"verify-file", "Check a file against its detached signature", runVerifyFile, false},
+	{"extract", "Extract files from a container", runExtract, false},
+	{"list", "List files in a container", runList, true},
+	{"grep", "Search the text files in a container", runGrep, false},
+	{"info", "Show container metadata", runInfo, true},
+	{"inspect", "Print the full decoded manifest", runInspect, true},
+	{"receipt", "Write a printable verification certificate (PDF or HTML)", runReceipt, false},
+	{"stats", "Show size, compression, and duplicate statistics", runStats, false},
+	{"keygen", "Generate an Ed25519 key pair", runKeygen, true},
+	{"key", "Manage named keys in the local keyring", runKey, false},
+	{"trust", "Manage the signer keys trusted by verify -trusted", runTrust, false},
+	{"revoke", "Revoke a signing key, or import published revocations", runRevoke, false},
+	{"anchor", "Anchor container hash to Bitcoin via OpenTimestamps", runAnchor, true},
+	{"gui", "Launch the web-based graphical interface", runGUI, false},
+	{"install-association", "Open .imf files in the GUI when double-clicked", runInstallAssociation, false},
+	{"update", "Update imf to the latest signed release", runUpdate, false},
+	{"version", "Show the version, commit, and build date", runVersion, true},
+}
+
+// findCommand returns the command called name, or nil.
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// printUsage writes the top-level help.
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "imf — %s\n\n", tr("Immutable File Container"))
+	fmt.Fprintf(w, "%s\n  imf <command> [options] [arguments]\n\n%s\n", tr("Usage:"), tr("Commands:"))
+	width := 0
+	for _, c := range commands {
+		width = max(width, len(c.name))
+	}
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, c.name, tr(c.summary))
+	}
+	fmt.Fprintf(w, "\n%s\n", tr("Global options:"))
+	fmt.Fprintf(w, "  --json    %s\n", strings.ReplaceAll(tr("Print results as JSON, for scripts (info, inspect, list, verify,\nseal, anchor, keygen, watch, version); see docs/json-output.md\nfor the fields"), "\n", "\n            "))
+	fmt.Fprintf(w, "\n%s\n", tr("Options may come before or after a command's arguments; after \"--\",\neverything is an argument."))
+	fmt.Fprintf(w, "\n%s\n", tr("Run 'imf help <command>' or 'imf <command> -h' for command-specific help."))
+}
+
+// parseFlags parses a command's arguments, os.Args[1:], with fs. Flags may
+// come before, after, or between positional arguments, so
+// "imf verify archive.imf -detail" and "imf verify -detail archive.imf" are
+// the same; everything after "--" is positional. -h prints fs.Usage and
+// exits 0; a bad flag exits with exitError, not the flag package's 2, which
+// means a signature failure here.
+func parseFlags(fs *flag.FlagSet) {
+	fs.Init(fs.Name(), flag.ContinueOnError)
+	switch err := fs.Parse(interspersed(fs, os.Args[1:])); {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(exitOK)
+	case err != nil:
+		os.Exit(exitError)
+	}
+}
+
+// interspersed reorders args so that the flags come first and the
+// positional arguments after them, behind a "--". A flag that fs knows to
+// take a value keeps the argument after it.
+func interspersed(fs *flag.FlagSet, args []string) []string {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positional = append(positional, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := fs.Lookup(name); f != nil && !isBoolFlag(f) && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	if len(positional) == 0 {
+		return flags
+	}
+	return append(append(flags, "--"), positional...)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// parseArgs parses the command line of a command that takes exactly n
+// positional arguments and no options, exiting with its usage otherwise.
+func parseArgs(name, usage string, n int) []string {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: "+usage)
+	}
+	parseFlags(fs)
+	if fs.NArg() != n {
+		fs.Usage()
+		os.Exit(1)
+	}
+	return fs.Args()
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/config"
+)
+
+// loadedSettings caches settings. The GUI's settings page replaces it, so
+// it is guarded by settingsMu.
+var (
+	settingsMu     sync.Mutex
+	loadedSettings *config.Config
+)
+
+// settings returns the defaults from ~/.imf/config and the environment,
+// which flags override; see package config. It exits if the config file
+// cannot be read.
+func settings() *config.Config {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	if loadedSettings == nil {
+		c, err := config.Load()
+		if err != nil {
+			// Not translated: the language may be set in the config file.
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		loadedSettings = c
+	}
+	return loadedSettings
+}
+
+// reloadSettings reads the settings again, after the config file changed.
+func reloadSettings() error {
+	c, err := config.Load()
+	if err != nil {
+		return err
+	}
+	settingsMu.Lock()
+	loadedSettings = c
+	settingsMu.Unlock()
+	return nil
+}
+
+// defaultKey returns keyPath, the -key flag, or if it is empty the
+// configured signing key.
+func defaultKey(keyPath string) string {
+	if keyPath != "" {
+		return keyPath
+	}
+	return settings().Key
+}
+
+// configuredCalendars returns the calendars to anchor with when no -server
+// is given: those in $IMF_CALENDARS if set, then those in the config file,
+// then those in ~/.imf/calendars, or nil for the public calendars.
+func configuredCalendars() ([]string, error) {
+	if os.Getenv("IMF_CALENDARS") == "" && len(settings().Calendars) > 0 {
+		return settings().Calendars, nil
+	}
+	return anchor.ConfiguredCalendars()
+}
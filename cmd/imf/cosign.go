@@ -0,0 +1,51 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// runCosign handles the "imf cosign" command.
+// Adds the holder's signature to a sealed container whose signature policy
+// lists their key (see "imf seal -signer ... -threshold n"). The container
+// verifies once the policy's threshold of listed keys have signed.
+func runCosign() {
+	fs := flag.NewFlagSet("imf cosign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "Path to Ed25519 private key (PEM) or keychain:NAME")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf cosign <container.imf> -key <private.pem>")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	containerPath := fs.Arg(0)
+	*keyPath = defaultKey(*keyPath)
+	if *keyPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -key is required (or set key in ~/.imf/config)")
+		os.Exit(1)
+	}
+
+	privKey := mustReadPrivateKey(*keyPath)
+	defer imfcrypto.Wipe(privKey)
+	if err := container.Cosign(containerPath, privKey); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Signed %s\n", containerPath)
+	info, err := container.GetInfo(containerPath)
+	if err == nil && info.Policy != nil {
+		fmt.Printf("  Signatures: %d of %d required\n", len(info.Policy.Signed), info.Policy.Threshold)
+	}
+}
@@ -16,11 +16,14 @@ import (
 // The container starts in an "open" state, ready to accept files via "imf add".
 func runCreate() {
 	fs := flag.NewFlagSet("imf create", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Check that the container can be created, without creating it")
 	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "Usage: imf create <path.imf>")
+		fmt.Fprintln(os.Stderr, "Usage: imf create [-dry-run] <path.imf>")
 		fmt.Fprintln(os.Stderr, "\nCreate a new empty .imf container.")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
 	}
-	fs.Parse(os.Args[1:])
+	parseFlags(fs)
 
 	if fs.NArg() != 1 {
 		fs.Usage()
@@ -28,9 +31,18 @@ func runCreate() {
 	}
 
 	path := fs.Arg(0)
+	if *dryRun {
+		if err := container.CheckCreate(path); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Dry run: %s was NOT created. Creating would:\n", path)
+		fmt.Println("  Write an empty, open container holding only its manifest")
+		return
+	}
 	if err := container.Create(path); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
 	}
-	fmt.Printf("Created %s\n", path)
+	fmt.Println(tr("Created %s", path))
 }
@@ -0,0 +1,64 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// Exit statuses, so that scripts can tell failures apart without parsing
+// messages. They are documented in docs/exit-codes.md; once published, a
+// status keeps its meaning.
+const (
+	exitOK         = 0 // success
+	exitError      = 1 // any other failure, including bad usage; for grep, no match
+	exitSignature  = 2 // not signed as required: bad signature, untrusted or revoked key, ...
+	exitIntegrity  = 3 // contents do not match the signed manifest
+	exitExpired    = 4 // the container has expired
+	exitPassphrase = 5 // passphrase or decryption key missing or wrong
+	exitIO         = 6 // a file could not be read or written, or a server reached
+)
+
+// exitCode returns the exit status for a command that failed with err.
+func exitCode(err error) int {
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	var netErr net.Error
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, container.ErrIntegrity):
+		return exitIntegrity
+	case errors.Is(err, container.ErrSignature), errors.Is(err, imfcrypto.ErrFileSignature):
+		return exitSignature
+	case errors.Is(err, container.ErrExpired):
+		return exitExpired
+	case errors.Is(err, container.ErrNoKey), errors.Is(err, imfcrypto.ErrDecrypt), errors.Is(err, imfcrypto.ErrKeyProtected):
+		return exitPassphrase
+	case errors.As(err, &pathErr), errors.As(err, &linkErr), errors.As(err, &netErr), errors.As(err, &urlErr):
+		return exitIO
+	}
+	return exitError
+}
+
+// exitSeverity orders the failure statuses from worst to least bad, for
+// commands that sum up several results in one status: tampering is worse
+// than a missing signature, which is worse than expiry, and so on.
+var exitSeverity = []int{exitIntegrity, exitSignature, exitExpired, exitPassphrase, exitIO, exitError}
+
+// worseExit returns whichever of the exit statuses a and b is worse.
+func worseExit(a, b int) int {
+	for _, code := range exitSeverity {
+		if a == code || b == code {
+			return code
+		}
+	}
+	return exitOK
+}
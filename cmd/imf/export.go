@@ -0,0 +1,73 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// runExport handles the "imf export" command.
+// Writes the verified, decrypted contents of a sealed container to a tar.gz
+// archive, with the signed manifest beside it as a detached integrity record
+// for recipients who cannot run imf.
+func runExport() {
+	fs := flag.NewFlagSet("imf export", flag.ExitOnError)
+	format := fs.String("format", container.FormatTarGz, "Archive format (tar.gz)")
+	out := fs.String("out", "", "Archive path (default: container name with the format extension)")
+	keyPath := fs.String("key", "", "Path to Ed25519 public key (PEM). Uses embedded key if omitted.")
+	passphrase := fs.String("passphrase", "", "Decryption passphrase")
+	ignoreExpiry := fs.Bool("ignore-expiry", false, "Export even if container is expired")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf export <container.imf> [options]")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	containerPath := fs.Arg(0)
+
+	outPath := *out
+	if outPath == "" {
+		outPath = strings.TrimSuffix(containerPath, ".imf") + "." + *format
+	}
+
+	opts := container.ExportOptions{
+		Format:     *format,
+		Passphrase: *passphrase,
+		Verify:     container.VerifyOptions{IgnoreExpiry: *ignoreExpiry},
+	}
+	if *keyPath != "" {
+		keyData, err := os.ReadFile(*keyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading key: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		pubKey, err := imfcrypto.ParsePublicKeyPEM(keyData)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing key: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.Verify.PublicKey = pubKey
+	}
+	if info, err := container.GetInfo(containerPath); err == nil && info.Encrypted && info.TimeLock == nil && opts.Passphrase == "" {
+		opts.Passphrase = containerPassphrase("Passphrase: ", false)
+	}
+
+	if err := container.Export(containerPath, outPath, opts); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Exported to %s\n", outPath)
+	fmt.Printf("  Signed manifest: %s\n", container.ExportManifestPath(outPath))
+}
@@ -4,88 +4,147 @@
 package main
 
 import (
+	"crypto/ecdh"
+	"flag"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
 )
 
 // runExtract handles the "imf extract" command.
 // Extracts files from a sealed container. If the container is encrypted,
-// the correct passphrase must be provided (interactively or via -passphrase flag).
+// the correct passphrase must be provided (interactively or via -passphrase flag),
+// or for containers sealed to recipients, the recipient's key via -identity.
 // Expired containers are blocked by default — use -ignore-expiry for forensic access.
+// Files already in the output directory are overwritten by default; with
+// -skip-existing they are left alone, and with -resume they are kept only if
+// they match the manifest's hash, so an interrupted extraction can be re-run
+// without rewriting what is already done.
 func runExtract() {
-	outputDir, passphrase, ignoreExpiry, containerPath := parseExtractArgs()
-
-	if containerPath == "" {
+	fs := flag.NewFlagSet("imf extract", flag.ExitOnError)
+	outputDir := fs.String("out", "", "Output directory")
+	passphrase := fs.String("passphrase", "", "Decryption passphrase")
+	identity := fs.String("identity", "", "X25519 private key (PEM) for containers sealed to recipients")
+	ignoreExpiry := fs.Bool("ignore-expiry", false, "Extract even if expired")
+	symlinks := fs.String("symlinks", "", "Symlink policy: follow/store recreate links, reject refuses them")
+	overwrite := fs.Bool("overwrite", false, "Replace files already in the output directory (default)")
+	skipExisting := fs.Bool("skip-existing", false, "Leave files already in the output directory alone")
+	resume := fs.Bool("resume", false, "Keep existing files that match the manifest's hash, rewrite the rest")
+	quiet := fs.Bool("quiet", false, "Do not show a progress bar")
+	fs.Usage = func() {
 		fmt.Fprintln(os.Stderr, "Usage: imf extract <container.imf> [options]")
 		fmt.Fprintln(os.Stderr, "\nOptions:")
-		fmt.Fprintln(os.Stderr, "  -out string         Output directory (default \".\")")
-		fmt.Fprintln(os.Stderr, "  -passphrase string  Decryption passphrase")
+		fmt.Fprintln(os.Stderr, "  -out string         Output directory (default output_dir in ~/.imf/config, or \".\")")
+		fmt.Fprintln(os.Stderr, "  -passphrase string  Decryption passphrase (default $IMF_PASSPHRASE, else asked)")
+		fmt.Fprintln(os.Stderr, "  -identity file      X25519 private key (PEM) for containers sealed to recipients")
 		fmt.Fprintln(os.Stderr, "  -ignore-expiry      Extract even if expired")
+		fmt.Fprintln(os.Stderr, "  -symlinks string    Symlink policy: follow/store recreate links, reject refuses them")
+		fmt.Fprintln(os.Stderr, "  -overwrite          Replace files already in the output directory (default)")
+		fmt.Fprintln(os.Stderr, "  -skip-existing      Leave files already in the output directory alone")
+		fmt.Fprintln(os.Stderr, "  -resume             Keep existing files that match the manifest's hash, rewrite the rest")
+		fmt.Fprintln(os.Stderr, "  -quiet              Do not show a progress bar")
+	}
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	containerPath := fs.Arg(0)
+	if *outputDir == "" {
+		*outputDir = settings().OutputDir
+	}
+	if *outputDir == "" {
+		*outputDir = "."
+	}
+
+	existing := container.ExistingOverwrite
+	switch {
+	case countTrue(*overwrite, *skipExisting, *resume) > 1:
+		fmt.Fprintln(os.Stderr, "Error: give only one of -overwrite, -skip-existing, and -resume")
 		os.Exit(1)
+	case *skipExisting:
+		existing = container.ExistingSkip
+	case *resume:
+		existing = container.ExistingResume
+	}
+
+	policy, err := manifest.ParseSymlinkPolicy(*symlinks)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+
+	var recipientKey *ecdh.PrivateKey
+	if *identity != "" {
+		recipientKey = mustReadRecipientPrivateKey(*identity)
 	}
 
-	pp := passphrase
-	if pp == "" {
+	pp := *passphrase
+	if pp == "" && recipientKey == nil {
 		info, err := container.GetInfo(containerPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
 		}
-		if info.Encrypted {
-			pp = promptPassphrase("Decryption passphrase: ")
+		// A time-locked container needs no secret, only the drand beacon.
+		if info.Encrypted && info.TimeLock == nil {
+			pp = containerPassphrase("Decryption passphrase: ", false)
 			if pp == "" {
-				fmt.Fprintln(os.Stderr, "Error: container is encrypted, passphrase required")
-				os.Exit(1)
+				fmt.Fprintln(os.Stderr, tr("Error: container is encrypted, passphrase required"))
+				os.Exit(exitPassphrase)
 			}
 		}
 	}
 
-	err := container.Extract(containerPath, container.ExtractOptions{
-		Passphrase:   pp,
-		IgnoreExpiry: ignoreExpiry,
-		OutputDir:    outputDir,
+	bar := newProgressBar(*quiet)
+	kept := 0
+	err = container.Extract(containerPath, container.ExtractOptions{
+		Passphrase:    pp,
+		RecipientKey:  recipientKey,
+		IgnoreExpiry:  *ignoreExpiry,
+		OutputDir:     *outputDir,
+		SymlinkPolicy: policy,
+		Existing:      existing,
+		Kept:          func(string) { kept++ },
+		Progress:      bar.progress(),
 	})
+	bar.clear()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Println(tr("Extracted to %s", *outputDir))
+	if kept > 0 {
+		fmt.Println(tr("  Kept %d existing file(s)", kept))
 	}
-	fmt.Printf("Extracted to %s\n", outputDir)
 }
 
-// parseExtractArgs manually parses extract command arguments.
-// Uses manual parsing because the container path is positional.
-func parseExtractArgs() (outputDir string, passphrase string, ignoreExpiry bool, containerPath string) {
-	outputDir = "."
-	args := os.Args[1:]
-	i := 0
-	for i < len(args) {
-		switch args[i] {
-		case "-out":
-			if i+1 < len(args) {
-				outputDir = args[i+1]
-				i += 2
-			} else {
-				i++
-			}
-		case "-passphrase":
-			if i+1 < len(args) {
-				passphrase = args[i+1]
-				i += 2
-			} else {
-				i++
-			}
-		case "-ignore-expiry":
-			ignoreExpiry = true
-			i++
-		default:
-			if containerPath == "" && !strings.HasPrefix(args[i], "-") {
-				containerPath = args[i]
-			}
-			i++
+// mustReadRecipientPrivateKey loads a PEM X25519 recipient private key,
+// exiting on failure.
+func mustReadRecipientPrivateKey(path string) *ecdh.PrivateKey {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading identity: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	key, err := imfcrypto.ParseRecipientPrivateKeyPEM(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing identity: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	return key
+}
+
+// countTrue returns how many of bs are true, for mutually exclusive flags.
+func countTrue(bs ...bool) int {
+	n := 0
+	for _, b := range bs {
+		if b {
+			n++
 		}
 	}
-	return
+	return n
 }
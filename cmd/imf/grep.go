@@ -0,0 +1,85 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/ecdh"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+// runGrep handles the "imf grep" command.
+// Searches the text files in a container for lines matching a regular
+// expression and prints each as file:line:text, like grep. Encrypted files
+// are decrypted in memory only, so searching never leaves plaintext on disk.
+// -l prints only the names of files with a match. As with grep, the exit
+// status is 1 if nothing matched.
+func runGrep() {
+	fs := flag.NewFlagSet("imf grep", flag.ExitOnError)
+	passphrase := fs.String("passphrase", "", "Decryption passphrase (default $IMF_PASSPHRASE, else asked)")
+	identity := fs.String("identity", "", "X25519 private key (PEM) for containers sealed to recipients")
+	ignoreExpiry := fs.Bool("ignore-expiry", false, "Search even if expired")
+	ignoreCase := fs.Bool("i", false, "Ignore case")
+	fixed := fs.Bool("F", false, "Match the pattern as a fixed string, not a regular expression")
+	filesOnly := fs.Bool("l", false, "Print only the names of files with a match")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf grep [options] <container.imf> <pattern>")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	containerPath, pattern := fs.Arg(0), fs.Arg(1)
+	if *fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid pattern: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	var recipientKey *ecdh.PrivateKey
+	if *identity != "" {
+		recipientKey = mustReadRecipientPrivateKey(*identity)
+	}
+	pp := *passphrase
+	if pp == "" && recipientKey == nil {
+		if info, err := container.GetInfo(containerPath); err == nil && info.Encrypted && info.TimeLock == nil {
+			pp = containerPassphrase("Passphrase: ", false)
+		}
+	}
+
+	matches, err := container.Grep(containerPath, re, container.GrepOptions{
+		Passphrase:   pp,
+		RecipientKey: recipientKey,
+		IgnoreExpiry: *ignoreExpiry,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if len(matches) == 0 {
+		os.Exit(1)
+	}
+	for i, m := range matches {
+		switch {
+		case !*filesOnly:
+			fmt.Printf("%s:%d:%s\n", m.File, m.Line, m.Text)
+		case i == 0 || matches[i-1].File != m.File:
+			fmt.Println(m.File)
+		}
+	}
+}
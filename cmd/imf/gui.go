@@ -8,33 +8,99 @@ package main
 
 import (
 	"archive/zip"
-	"crypto/ed25519"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/immutable-container/imf/pkg/anchor"
 	"github.com/immutable-container/imf/pkg/container"
 	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/i18n"
+	"github.com/immutable-container/imf/pkg/keyring"
+	"github.com/immutable-container/imf/pkg/manifest"
+	"github.com/immutable-container/imf/pkg/preview"
+	"github.com/immutable-container/imf/pkg/qr"
+	"github.com/immutable-container/imf/pkg/templates"
 )
 
-// guiState holds the current working state for the GUI session.
-type guiState struct {
-	WorkDir    string // temporary working directory for this session
-	PrivateKey ed25519.PrivateKey
-	PublicKey  ed25519.PublicKey
-	KeyLoaded  bool
+// guiDir is the directory containers are created in and opened from,
+// shared by every session so the files are easy to find. The settings page
+// can change it while the GUI runs, so handlers read it with workDir.
+var (
+	guiDirMu sync.RWMutex
+	guiDir   string
+)
+
+// workDir returns the working directory of session s: the GUI's, or on a
+// shared server the directory of the session's user within it.
+func workDir(s *guiState) string {
+	guiDirMu.RLock()
+	defer guiDirMu.RUnlock()
+	if s.User != "" {
+		return userDir(guiDir, s.User)
+	}
+	return guiDir
+}
+
+// setWorkDir makes dir the GUI's working directory.
+func setWorkDir(dir string) {
+	guiDirMu.Lock()
+	guiDir = dir
+	guiDirMu.Unlock()
 }
 
-var state guiState
+// defaultWorkDir returns the working directory to use when no container is
+// given: the configured one, created if need be, or else the user's Desktop
+// so .imf files are easy to find, then ~/Downloads, then a new temporary
+// directory.
+func defaultWorkDir() (string, error) {
+	if dir := settings().GUIDir; dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = os.TempDir()
+	}
+	for _, name := range []string{"Desktop", "Downloads"} {
+		dir := filepath.Join(homeDir, name)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return os.MkdirTemp("", "imf-gui-*")
+}
+
+// guiLimits are the resource limits applied while the GUI is running. The GUI
+// opens containers uploaded through the browser, which may come from anyone,
+// so it is stricter than the CLI defaults.
+var guiLimits = container.Limits{
+	MaxEntries:          10000,
+	MaxDecompressedSize: 4 << 30, // 4 GiB
+	MaxManifestSize:     16 << 20,
+}
 
 // apiResponse is the standard JSON response envelope.
 type apiResponse struct {
@@ -45,29 +111,77 @@ type apiResponse struct {
 }
 
 // runGUI starts a local web server that serves the IMF graphical interface.
-// It creates a working directory on the user's Desktop for easy access to
-// created .imf files. Falls back to a temp directory if Desktop is not found.
+// It works in the configured directory or the user's Desktop for easy access
+// to created .imf files; see defaultWorkDir.
 // Registers all REST API routes, finds an available port on localhost, and
-// opens the user's default browser. All operations happen locally — the server
-// only listens on 127.0.0.1 and never exposes data to the network.
+// opens the user's default browser. By default all operations happen
+// locally — the server only listens on 127.0.0.1 and never exposes data to
+// the network; -listen serves other machines instead, see remoteGUI.
 func runGUI() {
-	// Use the user's Desktop as the working directory so .imf files are
-	// easy to find. Fall back to a temp directory if Desktop doesn't exist.
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = os.TempDir()
+	fs := flag.NewFlagSet("imf gui", flag.ExitOnError)
+	listen := fs.String("listen", "", "Address to listen on, such as 0.0.0.0:8443")
+	tlsCert := fs.String("tls-cert", "", "TLS certificate (PEM)")
+	tlsKey := fs.String("tls-key", "", "TLS private key (PEM)")
+	auth := fs.String("auth", "", "How users sign in: basic or oidc")
+	users := fs.String("users", "", "For basic: htpasswd file of bcrypt hashes")
+	oidcIssuer := fs.String("oidc-issuer", "", "For oidc: the provider's issuer URL")
+	oidcClientID := fs.String("oidc-client-id", "", "For oidc: the client ID")
+	audit := fs.String("audit", "", "Append an audit log of requests")
+	app := fs.Bool("app", false, "Serve the desktop app's window: open no browser, and stop when standard input closes")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf gui [options] [container.imf]")
+		fmt.Fprintln(os.Stderr, "\nWith a container, open it; its folder becomes the working directory.")
+		fmt.Fprintln(os.Stderr, "\n  -app                  Serve the desktop app's window: open no browser, and stop,")
+		fmt.Fprintln(os.Stderr, "                        as Quit would, when standard input closes as the app exits")
+		fmt.Fprintln(os.Stderr, "\nOptions, to serve a team from a shared server rather than only this machine:")
+		fmt.Fprintln(os.Stderr, "  -listen addr          Address to listen on, such as 0.0.0.0:8443 (default 127.0.0.1)")
+		fmt.Fprintln(os.Stderr, "  -tls-cert file        TLS certificate (PEM); required with -listen off localhost")
+		fmt.Fprintln(os.Stderr, "  -tls-key file         TLS private key (PEM)")
+		fmt.Fprintln(os.Stderr, "  -auth method          How users sign in: basic or oidc; required with -listen off localhost")
+		fmt.Fprintln(os.Stderr, "  -users file           For basic: htpasswd file of bcrypt hashes (htpasswd -B)")
+		fmt.Fprintln(os.Stderr, "  -oidc-issuer url      For oidc: the provider's issuer URL")
+		fmt.Fprintln(os.Stderr, "  -oidc-client-id id    For oidc: the client ID; the secret is read from $IMF_OIDC_CLIENT_SECRET")
+		fmt.Fprintln(os.Stderr, "  -audit file           Append an audit log of requests (default "+auditFile+" in the working directory with -auth)")
+	}
+	parseFlags(fs)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	var openName string
+	if fs.NArg() == 1 {
+		path, err := filepath.Abs(fs.Arg(0))
+		if err == nil {
+			_, err = os.Stat(path)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		guiDir, openName = filepath.Dir(path), filepath.Base(path)
 	}
 
-	desktopDir := filepath.Join(homeDir, "Desktop")
-	if info, err := os.Stat(desktopDir); err != nil || !info.IsDir() {
-		// No Desktop folder — try ~/Downloads, then fall back to temp.
-		desktopDir = filepath.Join(homeDir, "Downloads")
-		if info, err := os.Stat(desktopDir); err != nil || !info.IsDir() {
-			desktopDir, _ = os.MkdirTemp("", "imf-gui-*")
+	if guiDir == "" {
+		dir, err := defaultWorkDir()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		guiDir = dir
+	}
+	if *listen != "" || *auth != "" {
+		g, err := newRemoteGUI(remoteOptions{
+			dir: guiDir, listen: *listen, tlsCert: *tlsCert, tlsKey: *tlsKey, auth: *auth, users: *users,
+			oidcIssuer: *oidcIssuer, oidcClientID: *oidcClientID, audit: *audit,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
 		}
+		remote = g
 	}
-	state.WorkDir = desktopDir
-	fmt.Printf("IMF working directory: %s\n", state.WorkDir)
+	container.SetLimits(guiLimits)
+	fmt.Printf("IMF working directory: %s\n", guiDir)
 	fmt.Println("Created .imf files will appear here.")
 
 	mux := http.NewServeMux()
@@ -76,45 +190,205 @@ func runGUI() {
 	mux.HandleFunc("/", handleIndex)
 
 	// REST API endpoints for container operations.
+	mux.HandleFunc("/api/messages", handleMessages)
 	mux.HandleFunc("/api/keygen", handleKeygen)
 	mux.HandleFunc("/api/key-status", handleKeyStatus)
 	mux.HandleFunc("/api/load-key", handleLoadKey)
 	mux.HandleFunc("/api/create", handleCreate)
 	mux.HandleFunc("/api/add", handleAddFiles)
+	mux.HandleFunc("/api/upload-start", handleUploadStart)
+	mux.HandleFunc("/api/upload-chunk", handleUploadChunk)
+	mux.HandleFunc("/api/upload-status", handleUploadStatus)
+	mux.HandleFunc("/api/upload-finish", handleUploadFinish)
 	mux.HandleFunc("/api/seal", handleSeal)
+	mux.HandleFunc("/api/passphrase-strength", handlePassphraseStrength)
 	mux.HandleFunc("/api/verify", handleVerify)
+	mux.HandleFunc("/api/check", handleCheck)
 	mux.HandleFunc("/api/extract", handleExtract)
+	mux.HandleFunc("/api/extract-to", handleExtractTo)
 	mux.HandleFunc("/api/info", handleInfo)
 	mux.HandleFunc("/api/list", handleList)
+	mux.HandleFunc("/api/copy", handleCopy)
+	mux.HandleFunc("/api/diff", handleDiff)
+	mux.HandleFunc("/api/remove", handleRemove)
+	mux.HandleFunc("/api/rename", handleRename)
+	mux.HandleFunc("/api/metadata", handleMetadata)
+	mux.HandleFunc("/api/templates", handleTemplates)
 	mux.HandleFunc("/api/download", handleDownload)
 	mux.HandleFunc("/api/download-zip", handleDownloadZip)
 	mux.HandleFunc("/api/browse", handleBrowse)
 	mux.HandleFunc("/api/serve-file", handleServeFile)
+	mux.HandleFunc("/api/preview", handlePreview)
 	mux.HandleFunc("/api/upload-container", handleUploadContainer)
 	mux.HandleFunc("/api/anchor", handleAnchor)
 	mux.HandleFunc("/api/anchor-verify", handleAnchorVerify)
+	mux.HandleFunc("/api/anchor-status", handleAnchorStatus)
+	mux.HandleFunc("/api/hash", handleHash)
+	mux.HandleFunc("/api/qr", handleQR)
 	mux.HandleFunc("/api/workdir", handleWorkDir)
-	mux.HandleFunc("/api/export-key", handleExportKey)
+	mux.HandleFunc("/api/recent", handleRecent)
+	mux.HandleFunc("/api/settings", localOnly(handleSettings))
+	mux.HandleFunc("/api/export-key", localOnly(handleExportKey))
+	mux.HandleFunc("/api/load-pkcs11", localOnly(handleLoadPKCS11))
+	mux.HandleFunc("/api/keys", localOnly(handleListKeys))
+	mux.HandleFunc("/api/use-key", localOnly(handleUseKey))
+	mux.HandleFunc("/api/save-key", localOnly(handleSaveKey))
+	mux.HandleFunc("/api/import-key", localOnly(handleImportKey))
+	mux.HandleFunc("/api/shutdown", localOnly(handleShutdown))
+
+	idleTimeout := defaultIdleTimeout
+	if v := os.Getenv("IMF_GUI_IDLE_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing IMF_GUI_IDLE_TIMEOUT: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		idleTimeout = d
+	}
+
+	token, err := newToken()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating session token: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	apiToken = token
 
-	// Find an available port.
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	// Listen on the configured port, or find an available one.
+	addr := *listen
+	if addr == "" {
+		addr = fmt.Sprintf("127.0.0.1:%d", settings().GUIPort)
+	}
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error finding port: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	port := listener.Addr().(*net.TCPAddr).Port
+	guiPort = port
 	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	if *tlsCert != "" {
+		url = "https://" + addr
+	}
 
 	fmt.Printf("IMF GUI running at %s\n", url)
 	fmt.Println("Press Ctrl+C to stop")
 
-	// Open the browser automatically (unless suppressed by Tauri wrapper).
-	if os.Getenv("IMF_NO_BROWSER") != "1" {
-		go openBrowser(url)
+	// Open the browser automatically (unless the desktop app shows the page,
+	// or serving other machines).
+	if os.Getenv("IMF_NO_BROWSER") != "1" && remote == nil && !*app {
+		openURL := url
+		if openName != "" {
+			openURL += "/?open=" + neturl.QueryEscape(openName)
+		}
+		go openBrowser(openURL)
 	}
 
-	// Start the server.
-	http.Serve(listener, mux)
+	// Start the server. Each browser gets its own session, whose key is
+	// wiped after idleTimeout and which is dropped after a longer idle spell.
+	// Progress sockets stay open indefinitely, so they do not run inside
+	// a session's request tracking.
+	sessions = newSessionManager(idleTimeout)
+	root := http.NewServeMux()
+	root.HandleFunc("/ws", sessions.handleProgressSocket)
+	var h http.Handler = withAPIToken(root, apiToken)
+	if remote != nil {
+		root.Handle("/", sessions.withSessions(remote.audit.withAudit(withUploadLimit(mux))))
+		h = remote.withAuth(h)
+	} else {
+		root.Handle("/", sessions.withSessions(withUploadLimit(mux)))
+	}
+	// Bodies may be large uploads on a slow link, so there is no overall
+	// ReadTimeout; a client still cannot hold a connection open by sending
+	// its headers slowly or by leaving it idle, and each upload chunk has
+	// a deadline of its own.
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: guiReadHeaderTimeout,
+		IdleTimeout:       guiIdleTimeout,
+	}
+	var appClosed chan struct{} // nil, so never ready, unless -app
+	appWindow = *app
+	if *app {
+		appClosed = make(chan struct{})
+		go func() {
+			io.Copy(io.Discard, os.Stdin)
+			close(appClosed)
+		}()
+	}
+	stopped := make(chan int)
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		code := 0
+		select {
+		case <-stop:
+			code = 130
+		case <-quitGUI:
+		case <-appClosed:
+		}
+		signal.Stop(stop) // a second Ctrl+C stops the GUI at once
+		shutdownGUI(srv)
+		stopped <- code
+	}()
+	if *tlsCert != "" {
+		err = srv.ServeTLS(listener, *tlsCert, *tlsKey)
+	} else {
+		err = srv.Serve(listener)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		code := <-stopped
+		fmt.Println("IMF GUI stopped")
+		os.Exit(code)
+	}
+	fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+	os.Exit(exitCode(err))
+}
+
+// appWindow is set when the GUI serves the desktop app's window (-app). The
+// app shows neither a terminal nor the GUI's output, so it cannot show the
+// code that confirms a private key export.
+var appWindow bool
+
+// quitGUI is closed, once, by /api/shutdown to stop the GUI.
+var (
+	quitGUI  = make(chan struct{})
+	quitOnce sync.Once
+)
+
+// shutdownGUI stops srv listening and gives the requests in progress, such
+// as a seal, shutdownGrace to finish. It then wipes every session's key and
+// removes its work directory: extracted files are plaintext and must not be
+// left behind.
+func shutdownGUI(srv *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+	}
+	sessions.closeAll()
+}
+
+// shutdownGrace is how long the GUI waits for requests in progress when it
+// is stopped.
+const shutdownGrace = 10 * time.Second
+
+// guiReadHeaderTimeout is how long a client has to send a request's
+// headers, and guiIdleTimeout how long a kept-alive connection may wait
+// for its next request.
+const (
+	guiReadHeaderTimeout = 10 * time.Second
+	guiIdleTimeout       = 2 * time.Minute
+)
+
+// handleShutdown stops the GUI, for the page's Quit button. It answers
+// first; the server then stops as Ctrl+C would stop it.
+func handleShutdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+	jsonSuccess(w, "Stopping", nil)
+	quitOnce.Do(func() { close(quitGUI) })
 }
 
 // openBrowser opens the default browser on the user's platform.
@@ -138,15 +412,19 @@ func handleKeygen(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s := session(r)
 	kp, err := imfcrypto.GenerateKeyPair()
 	if err != nil {
 		jsonError(w, err.Error(), 500)
 		return
 	}
 
-	state.PrivateKey = kp.PrivateKey
-	state.PublicKey = kp.PublicKey
-	state.KeyLoaded = true
+	signer, err := imfcrypto.NewKeySigner(kp.PrivateKey)
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	s.setKey(kp.PrivateKey, signer, kp.PublicKey, "")
 
 	// Keys stay in memory — no .pem files written to disk.
 	// Users can export explicitly via /api/export-key if needed.
@@ -154,9 +432,36 @@ func handleKeygen(w http.ResponseWriter, r *http.Request) {
 	jsonSuccess(w, "Key pair generated", nil)
 }
 
-// handleKeyStatus returns whether a signing key is currently loaded.
+// handleMessages returns the page's message catalog: for the language set
+// by IMF_LANG or in the config file, or else the browser's, or else the
+// locale's. The message is the language's code.
+func handleMessages(w http.ResponseWriter, r *http.Request) {
+	setting := settings().Lang
+	if setting == "" {
+		setting = r.Header.Get("Accept-Language")
+	}
+	p := i18n.NewPrinter(i18n.Detect(setting))
+	jsonSuccess(w, p.Language(), p.Messages())
+}
+
+// handleKeyStatus returns whether a key is loaded in the session, and if
+// so its fingerprint, keyring name, and what it can be used for.
 func handleKeyStatus(w http.ResponseWriter, r *http.Request) {
-	jsonSuccess(w, "", map[string]bool{"loaded": state.KeyLoaded})
+	s := session(r)
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+	status := map[string]interface{}{
+		"loaded":    s.KeyLoaded,
+		"expired":   s.KeyExpired,
+		"name":      s.KeyName,
+		"signing":   s.Signer != nil,
+		"memory":    s.PrivateKey != nil, // the key can be exported or saved to the keyring
+		"protected": s.keyFile != nil,    // saving the key keeps the passphrase it was loaded with
+	}
+	if s.PublicKey != nil {
+		status["fingerprint"] = imfcrypto.Fingerprint(s.PublicKey)
+	}
+	jsonSuccess(w, "", status)
 }
 
 func handleLoadKey(w http.ResponseWriter, r *http.Request) {
@@ -165,6 +470,7 @@ func handleLoadKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s := session(r)
 	file, _, err := r.FormFile("key")
 	if err != nil {
 		jsonError(w, "No key file provided", 400)
@@ -177,52 +483,241 @@ func handleLoadKey(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, "Error reading key file", 500)
 		return
 	}
-
-	// Try parsing as private key first, then public key.
-	privKey, err := imfcrypto.ParsePrivateKeyPEM(data)
+	defer imfcrypto.Wipe(data)
+
+	// Try parsing as private key first, then public key. A protected key
+	// needs its passphrase; without one the client is asked to prompt.
+	privKey, err := parsePrivateKeyFile(data, r.FormValue("passphrase"))
+	var pe passphraseError
+	if errors.As(err, &pe) {
+		jsonError(w, err.Error(), 401)
+		return
+	}
 	if err == nil {
-		state.PrivateKey = privKey
-		state.PublicKey = privKey.Public().(ed25519.PublicKey)
-		state.KeyLoaded = true
+		signer, err := imfcrypto.NewKeySigner(privKey)
+		if err != nil {
+			jsonError(w, "Invalid private key: "+err.Error(), 400)
+			return
+		}
+		s.setKey(privKey, signer, signer.Public(), "")
+		if imfcrypto.IsEncryptedPrivateKeyPEM(data) {
+			s.keepKeyFile(signer.Public(), data)
+		}
 		jsonSuccess(w, "Private key loaded", nil)
 		return
 	}
 
 	pubKey, err := imfcrypto.ParsePublicKeyPEM(data)
 	if err == nil {
-		state.PublicKey = pubKey
-		state.PrivateKey = nil
-		state.KeyLoaded = true
+		s.setKey(nil, nil, pubKey, "")
 		jsonSuccess(w, "Public key loaded (verify only)", nil)
 		return
 	}
 
-	jsonError(w, "Could not parse key file — must be an IMF PEM key", 400)
+	jsonError(w, "Could not parse key file — must be an Ed25519 PEM or OpenSSH key", 400)
+}
+
+// handleLoadPKCS11 switches signing to an Ed25519 key in a PKCS#11 HSM. The
+// module, slot, and PIN come from the IMF_PKCS11_* environment variables the
+// GUI was started with, so the PIN never passes through the browser; the
+// optional "label" form field picks the key.
+func handleLoadPKCS11(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	s := session(r)
+	cfg, err := imfcrypto.PKCS11ConfigFromEnv(r.FormValue("label"))
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	signer, err := imfcrypto.NewPKCS11Signer(cfg)
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	s.setKey(nil, signer, signer.Public(), "")
+	jsonSuccess(w, "HSM key loaded", nil)
+}
+
+// handleListKeys lists the keys in the local keyring (see "imf key") and
+// which one, if any, is loaded.
+func handleListKeys(w http.ResponseWriter, r *http.Request) {
+	s := session(r)
+	s.keyMu.Lock()
+	active := s.KeyName
+	s.keyMu.Unlock()
+	kr, err := keyring.OpenDefault()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	keys, err := kr.List()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	type keyInfo struct {
+		Name        string `json:"name"`
+		Fingerprint string `json:"fingerprint"`
+		Private     bool   `json:"private"`
+	}
+	list := []keyInfo{}
+	for _, k := range keys {
+		list = append(list, keyInfo{Name: k.Name, Fingerprint: k.Fingerprint, Private: k.HasPrivate})
+	}
+	jsonSuccess(w, "", map[string]interface{}{"keys": list, "active": active})
+}
+
+// handleUseKey loads the keyring key named by the "name" form field. As with
+// /api/load-key, a protected key needs the "passphrase" field, and a key held
+// only as a public key is loaded for verification.
+func handleUseKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	s := session(r)
+	kr, err := keyring.OpenDefault()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	name := r.FormValue("name")
+	key, err := kr.Get(name)
+	if err != nil {
+		jsonError(w, err.Error(), 404)
+		return
+	}
+	privKey, err := keyringPrivateKey(kr, name, r.FormValue("passphrase"))
+	if errors.Is(err, keyring.ErrNoPrivateKey) {
+		s.setKey(nil, nil, key.PublicKey, name)
+		jsonSuccess(w, "Public key "+name+" loaded (verify only)", nil)
+		return
+	}
+	var pe passphraseError
+	if errors.As(err, &pe) {
+		jsonError(w, err.Error(), 401)
+		return
+	}
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	signer, err := imfcrypto.NewKeySigner(privKey)
+	if err != nil {
+		jsonError(w, "Invalid private key: "+err.Error(), 500)
+		return
+	}
+	s.setKey(privKey, signer, signer.Public(), name)
+	if data, err := kr.PrivateKeyFile(name); err == nil && imfcrypto.IsEncryptedPrivateKeyPEM(data) {
+		s.keepKeyFile(signer.Public(), data)
+	}
+	jsonSuccess(w, "Key "+name+" loaded", nil)
+}
+
+// handleSaveKey adds the loaded in-memory key to the keyring under the "name"
+// form field, so a key generated in the GUI outlives the session. With a
+// "passphrase" the key is stored protected by it, as "imf keygen -protect"
+// does; without one, a key loaded from a protected file is stored as that
+// file, still under its own passphrase, and any other key unprotected.
+func handleSaveKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	s := session(r)
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+	if s.PrivateKey == nil {
+		jsonError(w, "No private key loaded", 400)
+		return
+	}
+	kr, err := keyring.OpenDefault()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	name := r.FormValue("name")
+	var pemData []byte
+	switch pp := r.FormValue("passphrase"); {
+	case pp != "":
+		if pemData, err = imfcrypto.MarshalEncryptedPrivateKeyPEM(s.PrivateKey, pp); err != nil {
+			jsonError(w, err.Error(), 500)
+			return
+		}
+	case s.keyFile != nil:
+		pemData = bytes.Clone(s.keyFile)
+	default:
+		pemData = imfcrypto.MarshalPrivateKeyPEM(s.PrivateKey)
+	}
+	defer imfcrypto.Wipe(pemData)
+	key, err := kr.Add(name, s.PublicKey, pemData)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	s.KeyName = name
+	msg := "Key saved to keyring as " + name
+	if imfcrypto.IsEncryptedPrivateKeyPEM(pemData) {
+		msg += ", protected by its passphrase"
+	}
+	jsonSuccess(w, msg, map[string]string{"fingerprint": key.Fingerprint})
 }
 
 // handleCreate creates a new empty .imf container in the session's work directory.
-// Accepts a "name" form field; defaults to "container" if omitted.
+// Accepts a "name" form field; defaults to "container" if omitted. With
+// "template", the container is made from that template, and must have the
+// details it requires.
 func handleCreate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		jsonError(w, "Method not allowed", 405)
 		return
 	}
 
-	name := r.FormValue("name")
-	if name == "" {
+	name := filepath.Base(r.FormValue("name"))
+	if name == "" || name == "." || name == string(filepath.Separator) {
 		name = "container"
 	}
 	if !strings.HasSuffix(name, ".imf") {
 		name += ".imf"
 	}
 
-	containerPath := filepath.Join(state.WorkDir, name)
+	md := metadataForm(r)
+	if id := r.FormValue("template"); id != "" {
+		md.Template = id
+		tpl, err := containerTemplate(&md)
+		if err == nil && tpl == nil {
+			err = fmt.Errorf("%w: %s", templates.ErrNotFound, id)
+		}
+		if err == nil {
+			err = tpl.CheckMetadata(&md)
+		}
+		if err != nil {
+			jsonError(w, err.Error(), 400)
+			return
+		}
+	}
+
+	containerPath := containerFile(r, name)
 	os.Remove(containerPath) // allow recreating
 
 	if err := container.Create(containerPath); err != nil {
 		jsonError(w, err.Error(), 500)
 		return
 	}
+	if err := container.SetMetadata(containerPath, md); err != nil {
+		os.Remove(containerPath)
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	recordRecent(containerPath, func(e *recentEntry) {
+		*e = recentEntry{Name: e.Name, Path: e.Path, State: "open", UsedAt: e.UsedAt}
+	})
 
 	jsonSuccess(w, fmt.Sprintf("Created %s", name), map[string]string{
 		"path": containerPath,
@@ -230,6 +725,51 @@ func handleCreate(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// metadataForm returns the metadata in a request's "title", "description",
+// "case_number", and "tags" fields, the tags separated by commas or lines.
+func metadataForm(r *http.Request) manifest.Metadata {
+	return manifest.Metadata{
+		Title:       r.FormValue("title"),
+		Description: r.FormValue("description"),
+		CaseNumber:  r.FormValue("case_number"),
+		Tags: strings.FieldsFunc(r.FormValue("tags"), func(c rune) bool {
+			return c == ',' || c == '\n'
+		}),
+	}
+}
+
+// containerTemplate returns the template a container with metadata md was
+// made from: nil if it was made from none, or from one since removed.
+func containerTemplate(md *manifest.Metadata) (*templates.Template, error) {
+	if md == nil || md.Template == "" {
+		return nil, nil
+	}
+	dir, err := templates.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := templates.Find(dir, md.Template)
+	if errors.Is(err, templates.ErrNotFound) {
+		return nil, nil
+	}
+	return tpl, err
+}
+
+// handleTemplates lists the templates containers can be made from.
+func handleTemplates(w http.ResponseWriter, r *http.Request) {
+	dir, err := templates.DefaultDir()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	list, err := templates.Load(dir)
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	jsonSuccess(w, "", list)
+}
+
 // handleAddFiles accepts multipart file uploads and adds them to the current container.
 // Files are temporarily written to the work directory, then added to the container
 // via the container.Add() library function, which records SHA-256 hashes in the manifest.
@@ -239,23 +779,33 @@ func handleAddFiles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s := session(r)
 	containerName := r.FormValue("container")
 	if containerName == "" {
 		jsonError(w, "No container specified", 400)
 		return
 	}
-	containerPath := filepath.Join(state.WorkDir, containerName)
-
-	// Parse the multipart form (up to 100MB).
-	r.ParseMultipartForm(100 << 20)
+	containerPath := containerFile(r, containerName)
 
-	files := r.MultipartForm.File["files"]
-	if len(files) == 0 {
+	// withUploadLimit has parsed the form, spooling its files to disk.
+	if r.MultipartForm == nil || len(r.MultipartForm.File["files"]) == 0 {
 		jsonError(w, "No files provided", 400)
 		return
 	}
 
-	// Save uploaded files to temp directory, then add to container.
+	// Save uploaded files to the session's directory, then add to container.
+	uploadDir := filepath.Join(s.WorkDir, "uploads")
+	if err := os.MkdirAll(uploadDir, 0700); err != nil {
+		jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+		return
+	}
+	files := r.MultipartForm.File["files"]
+	for _, fh := range files {
+		if fh.Size > maxUpload() {
+			jsonError(w, errTooLarge(fh.Filename), http.StatusRequestEntityTooLarge)
+			return
+		}
+	}
 	var tempPaths []string
 	for _, fh := range files {
 		src, err := fh.Open()
@@ -264,7 +814,7 @@ func handleAddFiles(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		tmpPath := filepath.Join(state.WorkDir, "upload_"+fh.Filename)
+		tmpPath := filepath.Join(uploadDir, filepath.Base(fh.Filename))
 		dst, err := os.Create(tmpPath)
 		if err != nil {
 			src.Close()
@@ -278,7 +828,8 @@ func handleAddFiles(w http.ResponseWriter, r *http.Request) {
 		tempPaths = append(tempPaths, tmpPath)
 	}
 
-	if err := container.Add(containerPath, tempPaths); err != nil {
+	report, err := container.AddWithReport(containerPath, tempPaths, container.AddOptions{})
+	if err != nil {
 		jsonError(w, err.Error(), 500)
 		return
 	}
@@ -288,18 +839,37 @@ func handleAddFiles(w http.ResponseWriter, r *http.Request) {
 		os.Remove(p)
 	}
 
-	jsonSuccess(w, fmt.Sprintf("Added %d file(s)", len(files)), nil)
+	jsonSuccess(w, fmt.Sprintf("Added %d file(s)", len(files))+renamedNote(report.Renamed),
+		map[string]interface{}{"renamed": report.Renamed})
+}
+
+// renamedNote lists, for a status message, the files stored under a new
+// name to avoid a collision; renamed maps the name stored to the one asked
+// for.
+func renamedNote(renamed map[string]string) string {
+	if len(renamed) == 0 {
+		return ""
+	}
+	notes := make([]string, 0, len(renamed))
+	for stored, asked := range renamed {
+		notes = append(notes, asked+" as "+stored)
+	}
+	sort.Strings(notes)
+	return "; renamed to avoid a collision: " + strings.Join(notes, ", ")
 }
 
 // handleSeal seals the container using the session's loaded private key.
 // Accepts optional passphrase (for AES-256-GCM encryption), expiration date,
 // and embed_key flag. Once sealed, the container becomes permanently immutable.
+// A container made from a template is sealed only with the details the
+// template requires, and encrypted or not as it says.
 func handleSeal(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		jsonError(w, "Method not allowed", 405)
 		return
 	}
 
+	s := session(r)
 	containerName := r.FormValue("container")
 	passphrase := r.FormValue("passphrase")
 	expiresStr := r.FormValue("expires")
@@ -309,17 +879,66 @@ func handleSeal(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, "No container specified", 400)
 		return
 	}
-	if state.PrivateKey == nil {
+	// The "key" field picks a keyring key for this seal alone; otherwise
+	// the session's key signs.
+	signer, done := s.useSigner()
+	defer done()
+	if name := r.FormValue("key"); name != "" {
+		if remote != nil {
+			jsonError(w, errNotLocal.Error(), http.StatusForbidden)
+			return
+		}
+		kr, err := keyring.OpenDefault()
+		if err != nil {
+			jsonError(w, err.Error(), 500)
+			return
+		}
+		privKey, err := keyringPrivateKey(kr, name, r.FormValue("key_passphrase"))
+		var pe passphraseError
+		if errors.As(err, &pe) {
+			jsonError(w, err.Error(), 401)
+			return
+		}
+		if err != nil {
+			jsonError(w, err.Error(), 400)
+			return
+		}
+		defer imfcrypto.Wipe(privKey)
+		if signer, err = imfcrypto.NewKeySigner(privKey); err != nil {
+			jsonError(w, "Invalid private key: "+err.Error(), 400)
+			return
+		}
+	}
+	if signer == nil {
 		jsonError(w, "No private key loaded — generate or load a key first", 400)
 		return
 	}
 
-	containerPath := filepath.Join(state.WorkDir, containerName)
+	containerPath := containerFile(r, containerName)
+	info, err := container.GetInfo(containerPath)
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	tpl, err := containerTemplate(info.Metadata)
+	if err == nil && tpl != nil {
+		if err = tpl.CheckMetadata(info.Metadata); err == nil {
+			err = tpl.CheckEncryption(passphrase != "")
+		}
+	}
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
 
+	progress := s.progress.reporter("seal")
+	defer progress.finish()
 	opts := container.SealOptions{
-		PrivateKey:  state.PrivateKey,
+		Signer:      signer,
 		EmbedPubKey: embedKey,
 		Passphrase:  passphrase,
+		Iterations:  settings().KDFIterations,
+		Progress:    progress.progress(),
 	}
 
 	if expiresStr != "" {
@@ -335,13 +954,35 @@ func handleSeal(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, err.Error(), 500)
 		return
 	}
+	recordRecent(containerPath, func(e *recentEntry) { e.State = "sealed" })
 
 	jsonSuccess(w, "Container sealed", nil)
 }
 
+// handlePassphraseStrength estimates the strength of the "passphrase" form
+// field for the seal dialog's meter. The passphrase is neither kept nor
+// logged.
+func handlePassphraseStrength(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+	s := imfcrypto.EstimateStrength(r.FormValue("passphrase"))
+	jsonSuccess(w, "", map[string]interface{}{
+		"score":      s.Score,
+		"label":      s.Label(),
+		"crack_time": s.CrackTime,
+		"warning":    s.Warning,
+		"suggestion": s.Suggestion,
+	})
+}
+
 // handleVerify verifies a container's cryptographic integrity.
 // Checks the Ed25519 signature and recomputes all file hashes.
 // Accepts the container via multipart upload or by name in the work directory.
+// The result, passed or failed, carries the same report as "imf verify
+// --json", with every file's expected and computed hash, for the page's
+// report view.
 func handleVerify(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		jsonError(w, "Method not allowed", 405)
@@ -358,24 +999,83 @@ func handleVerify(w http.ResponseWriter, r *http.Request) {
 	opts := container.VerifyOptions{
 		IgnoreExpiry: r.FormValue("ignore_expiry") == "true",
 	}
+	// Consult the same local revocation list as "imf verify".
+	if dir, err := keyring.DefaultRevocationDir(); err == nil {
+		if opts.Revocations, err = container.LoadRevocations(dir); err != nil {
+			jsonError(w, "Reading revocation list: "+err.Error(), 500)
+			return
+		}
+	}
 
-	if err := container.Verify(containerPath, opts); err != nil {
-		jsonError(w, err.Error(), 400)
+	name := filepath.Base(containerPath)
+	report, err := container.VerifyWithReport(containerPath, opts)
+	if err != nil {
+		recordVerify(containerPath, err, "")
+		jsonErrorData(w, err.Error(), 400, newFailedVerifyJSON(name, report, err))
+		return
+	}
+	result := newVerifyJSON(name, report, opts)
+	if rev := report.Revocation; rev != nil {
+		msg := "Verified, but the signing key was revoked at " + rev.RevokedAt.Format(time.RFC3339) + ", after this container was sealed"
+		recordVerify(containerPath, nil, msg)
+		jsonSuccess(w, msg, result)
 		return
 	}
 
-	jsonSuccess(w, "Signature and integrity verified", nil)
+	recordVerify(containerPath, nil, "Signature and integrity verified")
+	jsonSuccess(w, "Signature and integrity verified", result)
+}
+
+// extractDir returns the session directory holding the files extracted from
+// the named container. Each container open in the GUI has its own, so
+// extracting one does not replace another's files.
+func extractDir(s *guiState, name string) (string, error) {
+	base := filepath.Base(name)
+	if name == "" || base == "." || base == ".." || base == string(filepath.Separator) {
+		return "", fmt.Errorf("no container specified")
+	}
+	return filepath.Join(s.WorkDir, "extracted", base), nil
+}
+
+// extractedFile returns where file, a path as the container names it, was
+// extracted from the named container.
+func extractedFile(s *guiState, name, file string) (string, error) {
+	dir, err := extractDir(s, name)
+	if err != nil {
+		return "", err
+	}
+	rel, err := container.SanitizePath(file)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
+
+// extractedNames returns the files under dir by their slash-separated
+// paths relative to it.
+func extractedNames(dir string) []string {
+	var names []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return names
 }
 
 // handleExtract extracts files from a sealed container into the work directory.
 // If encrypted, the correct passphrase must be provided. Extracted files are
-// accessible via the /api/browse and /api/download endpoints.
+// accessible via the /api/browse and /api/download endpoints, given the
+// same "container".
 func handleExtract(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		jsonError(w, "Method not allowed", 405)
 		return
 	}
 
+	s := session(r)
 	containerPath, err := resolveContainer(r)
 	if err != nil {
 		jsonError(w, err.Error(), 400)
@@ -383,27 +1083,27 @@ func handleExtract(w http.ResponseWriter, r *http.Request) {
 	}
 
 	passphrase := r.FormValue("passphrase")
-	outputDir := filepath.Join(state.WorkDir, "extracted")
+	outputDir, err := extractDir(s, filepath.Base(containerPath))
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
 	os.RemoveAll(outputDir)
 
+	progress := s.progress.reporter("extract")
+	defer progress.finish()
 	err = container.Extract(containerPath, container.ExtractOptions{
 		Passphrase:   passphrase,
 		IgnoreExpiry: r.FormValue("ignore_expiry") == "true",
 		OutputDir:    outputDir,
+		Progress:     progress.progress(),
 	})
 	if err != nil {
 		jsonError(w, err.Error(), 500)
 		return
 	}
 
-	// List extracted files.
-	var extractedFiles []string
-	filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() {
-			extractedFiles = append(extractedFiles, info.Name())
-		}
-		return nil
-	})
+	extractedFiles := extractedNames(outputDir)
 
 	jsonSuccess(w, fmt.Sprintf("Extracted %d file(s)", len(extractedFiles)), map[string]interface{}{
 		"files":      extractedFiles,
@@ -411,6 +1111,70 @@ func handleExtract(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleExtractTo extracts a sealed container's files into a folder, for
+// a user who means to keep them: the files /api/extract writes are only
+// for previewing, and are removed with the session. The folder is given as
+// "folder", and must be new or empty so that nothing in it is overwritten.
+func handleExtractTo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	s := session(r)
+	containerPath, err := resolveContainer(r)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	outputDir, err := exportDir(s, r.FormValue("folder"))
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	if entries, err := os.ReadDir(outputDir); err == nil && len(entries) > 0 {
+		jsonError(w, fmt.Sprintf("%s is not empty", outputDir), 409)
+		return
+	}
+
+	progress := s.progress.reporter("extract")
+	defer progress.finish()
+	err = container.Extract(containerPath, container.ExtractOptions{
+		Passphrase:   r.FormValue("passphrase"),
+		IgnoreExpiry: r.FormValue("ignore_expiry") == "true",
+		OutputDir:    outputDir,
+		Progress:     progress.progress(),
+	})
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+
+	extractedFiles := extractedNames(outputDir)
+	jsonSuccess(w, fmt.Sprintf("Extracted %d file(s) to %s", len(extractedFiles), outputDir), map[string]interface{}{
+		"files":      extractedFiles,
+		"output_dir": outputDir,
+	})
+}
+
+// exportDir returns the folder named by folder, as given to
+// /api/extract-to. A relative one is within the working directory; on a
+// shared server it must be, so that users write nowhere else.
+func exportDir(s *guiState, folder string) (string, error) {
+	folder = strings.TrimSpace(folder)
+	if folder == "" {
+		return "", errors.New("no folder given")
+	}
+	if remote == nil && filepath.IsAbs(folder) {
+		return filepath.Clean(folder), nil
+	}
+	rel, err := container.SanitizePath(folder)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(workDir(s), filepath.FromSlash(rel)), nil
+}
+
 func handleInfo(w http.ResponseWriter, r *http.Request) {
 	containerPath, err := resolveContainer(r)
 	if err != nil {
@@ -423,6 +1187,7 @@ func handleInfo(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, err.Error(), 500)
 		return
 	}
+	recordRecent(containerPath, func(e *recentEntry) { e.State = string(info.State) })
 
 	jsonSuccess(w, "", info)
 }
@@ -443,7 +1208,169 @@ func handleList(w http.ResponseWriter, r *http.Request) {
 	jsonSuccess(w, "", files)
 }
 
+// handleCopy copies the files named by the "file" fields from the container
+// "source" into the open container "container", keeping their recorded
+// hashes and provenance, so files can be carried between containers open
+// side by side in the GUI. An encrypted source needs its "passphrase"; a
+// sealed one is verified first.
+func handleCopy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	src, dst := r.FormValue("source"), r.FormValue("container")
+	if src == "" || dst == "" {
+		jsonError(w, "No container specified", 400)
+		return
+	}
+	names := r.Form["file"]
+	if len(names) == 0 {
+		jsonError(w, "No files specified", 400)
+		return
+	}
+
+	opts := container.CopyOptions{
+		Passphrase: r.FormValue("passphrase"),
+		Verify:     container.VerifyOptions{IgnoreExpiry: r.FormValue("ignore_expiry") == "true"},
+	}
+	if dir, err := keyring.DefaultRevocationDir(); err == nil {
+		if opts.Verify.Revocations, err = container.LoadRevocations(dir); err != nil {
+			jsonError(w, "Reading revocation list: "+err.Error(), 500)
+			return
+		}
+	}
+	report, err := container.CopyFilesWithReport(containerFile(r, src), containerFile(r, dst), names, opts)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	jsonSuccess(w, fmt.Sprintf("Copied %d file(s) to %s", len(names), filepath.Base(dst))+renamedNote(report.Renamed),
+		map[string]interface{}{"renamed": report.Renamed})
+}
+
+// handleDiff compares the container "container" with "original", the one it
+// revises, for the GUI's diff view: each file added, removed, modified,
+// moved, or unchanged, from the two manifests.
+func handleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	orig, name := r.FormValue("original"), r.FormValue("container")
+	if orig == "" || name == "" {
+		jsonError(w, "No container specified", 400)
+		return
+	}
+	changes, err := container.Diff(containerFile(r, orig), containerFile(r, name))
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	jsonSuccess(w, "", changes)
+}
+
+// handleRemove removes the files named by the "file" fields from the open
+// container "container".
+func handleRemove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	name := r.FormValue("container")
+	if name == "" {
+		jsonError(w, "No container specified", 400)
+		return
+	}
+	names := r.Form["file"]
+	if len(names) == 0 {
+		jsonError(w, "No files specified", 400)
+		return
+	}
+	if err := container.Remove(containerFile(r, name), names); err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	jsonSuccess(w, fmt.Sprintf("Removed %d file(s)", len(names)), nil)
+}
+
+// handleRename renames the file "file" in the open container "container"
+// to "name", which may include folders.
+func handleRename(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	name, file, to := r.FormValue("container"), r.FormValue("file"), r.FormValue("name")
+	if name == "" {
+		jsonError(w, "No container specified", 400)
+		return
+	}
+	if file == "" || to == "" {
+		jsonError(w, "No file specified", 400)
+		return
+	}
+	if err := container.Rename(containerFile(r, name), file, to); err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	jsonSuccess(w, fmt.Sprintf("Renamed %s to %s", file, to), nil)
+}
+
+// handleMetadata returns the metadata of "container", or with a POST
+// replaces that of an open one with the fields metadataForm reads. Once the
+// container is sealed its metadata is signed with it and cannot change.
+func handleMetadata(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("container")
+	if name == "" {
+		jsonError(w, "No container specified", 400)
+		return
+	}
+	containerPath := containerFile(r, name)
+	if r.Method == "POST" {
+		// The template the container was made from is kept, and its
+		// required details must stay filled in.
+		md := metadataForm(r)
+		info, err := container.GetInfo(containerPath)
+		if err != nil {
+			jsonError(w, err.Error(), 500)
+			return
+		}
+		if info.Metadata != nil {
+			md.Template = info.Metadata.Template
+		}
+		tpl, err := containerTemplate(&md)
+		if err == nil && tpl != nil {
+			err = tpl.CheckMetadata(&md)
+		}
+		if err == nil {
+			err = container.SetMetadata(containerPath, md)
+		}
+		if err != nil {
+			jsonError(w, err.Error(), 400)
+			return
+		}
+	}
+	info, err := container.GetInfo(containerPath)
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	msg := ""
+	if r.Method == "POST" {
+		msg = "Details saved"
+	}
+	jsonSuccess(w, msg, map[string]interface{}{
+		"metadata": info.Metadata,
+		"editable": info.State == manifest.StateOpen,
+	})
+}
+
 func handleDownload(w http.ResponseWriter, r *http.Request) {
+	s := session(r)
 	file := r.URL.Query().Get("file")
 	if file == "" {
 		jsonError(w, "No file specified", 400)
@@ -451,57 +1378,91 @@ func handleDownload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Only allow downloads from our work directory.
-	fullPath := filepath.Join(state.WorkDir, file)
-	if !strings.HasPrefix(fullPath, state.WorkDir) {
+	dir := workDir(s)
+	fullPath := filepath.Join(dir, file)
+	if !strings.HasPrefix(fullPath, dir+string(filepath.Separator)) {
 		jsonError(w, "Invalid path", 400)
 		return
 	}
 
-	// Check extracted directory too.
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-		fullPath = filepath.Join(state.WorkDir, "extracted", file)
+	// With "container", a file extracted from that container comes first.
+	if p, err := extractedFile(s, r.URL.Query().Get("container"), file); err == nil {
+		if _, err := os.Stat(p); err == nil {
+			fullPath = p
+		}
 	}
 
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(fullPath)))
 	http.ServeFile(w, r, fullPath)
 }
 
-// handleDownloadZip bundles all extracted files into a single ZIP for download.
-// handleDownloadZip bundles all extracted files into a single ZIP archive for download.
+// handleDownloadZip bundles the files extracted from "container" into a single ZIP archive for download.
 // This provides a convenient way to download all files at once from the GUI.
+// The archive is built in the session's directory, streaming each file into
+// it, and then served with its length, so the browser can show the
+// download's progress and resume it with a range request.
 func handleDownloadZip(w http.ResponseWriter, r *http.Request) {
-	extractedDir := filepath.Join(state.WorkDir, "extracted")
+	s := session(r)
+	name := r.FormValue("container")
+	extractedDir, err := extractDir(s, name)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
 	if _, err := os.Stat(extractedDir); os.IsNotExist(err) {
 		jsonError(w, "No extracted files found", 404)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/zip")
-	w.Header().Set("Content-Disposition", "attachment; filename=\"extracted-files.zip\"")
-
-	zw := zip.NewWriter(w)
-	defer zw.Close()
+	tmp, err := os.CreateTemp(s.WorkDir, "extracted-*.zip")
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
 
-	filepath.Walk(extractedDir, func(path string, info os.FileInfo, err error) error {
+	zw := zip.NewWriter(tmp)
+	err = filepath.Walk(extractedDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
-			return nil
+			return err
 		}
-		data, err := os.ReadFile(path)
+		src, err := os.Open(path)
 		if err != nil {
-			return nil
+			return err
 		}
-		f, err := zw.Create(info.Name())
+		defer src.Close()
+		hdr, err := zip.FileInfoHeader(info)
 		if err != nil {
-			return nil
+			return err
 		}
-		f.Write(data)
-		return nil
+		rel, _ := filepath.Rel(extractedDir, path)
+		hdr.Name = filepath.ToSlash(rel)
+		hdr.Method = zip.Deflate
+		f, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, src)
+		return err
 	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Error building ZIP: %v", err), 500)
+		return
+	}
+
+	zipName := strings.TrimSuffix(filepath.Base(name), ".imf") + "-files.zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", zipName))
+	http.ServeContent(w, r, zipName, time.Now(), tmp)
 }
 
 // fileDetail holds metadata for the file browser.
 type fileDetail struct {
-	Name     string `json:"name"`
+	Name     string `json:"name"` // slash-separated, relative to the extracted directory
 	Size     int64  `json:"size"`
 	Modified string `json:"modified"`
 	Type     string `json:"type"`     // "image", "pdf", "text", "code", "document", "archive", "other"
@@ -510,10 +1471,15 @@ type fileDetail struct {
 }
 
 // handleBrowse returns detailed file listing for the Finder-style browser.
-// handleBrowse returns metadata for all extracted files (name, size, type, modified date).
+// handleBrowse returns metadata for the files extracted from "container" (name, size, type, modified date).
 // Powers the Finder-style file browser in the GUI's Extract panel.
 func handleBrowse(w http.ResponseWriter, r *http.Request) {
-	extractedDir := filepath.Join(state.WorkDir, "extracted")
+	s := session(r)
+	extractedDir, err := extractDir(s, r.FormValue("container"))
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
 	if _, err := os.Stat(extractedDir); os.IsNotExist(err) {
 		jsonSuccess(w, "", []fileDetail{})
 		return
@@ -525,8 +1491,9 @@ func handleBrowse(w http.ResponseWriter, r *http.Request) {
 			return nil
 		}
 		ext := strings.ToLower(filepath.Ext(info.Name()))
+		rel, _ := filepath.Rel(extractedDir, path)
 		files = append(files, fileDetail{
-			Name:     info.Name(),
+			Name:     filepath.ToSlash(rel),
 			Size:     info.Size(),
 			Modified: info.ModTime().Format("Jan 2, 2006 3:04 PM"),
 			Type:     classifyFile(ext),
@@ -540,28 +1507,80 @@ func handleBrowse(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleServeFile serves a file inline for preview (not as download).
+// Range requests are honored, so the preview pane's audio and video players
+// stream a long recording and seek within it rather than fetch it whole, and
+// a long text file is previewed from its first few kilobytes.
+//
+// A container's files are not trusted: one opened in a tab runs sandboxed,
+// in an origin of its own without the GUI's token or cookie, and one of a
+// type that can carry scripts (see activeExts) is downloaded rather than
+// opened. The preview pane reads text with fetch and shows images with
+// <img>, which neither affects.
 func handleServeFile(w http.ResponseWriter, r *http.Request) {
+	s := session(r)
 	file := r.URL.Query().Get("file")
 	if file == "" {
 		http.Error(w, "No file specified", 400)
 		return
 	}
 
-	// Security: only serve from extracted directory.
-	fullPath := filepath.Join(state.WorkDir, "extracted", filepath.Base(file))
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+	// Security: only serve from the container's extracted directory.
+	fullPath, err := extractedFile(s, r.URL.Query().Get("container"), file)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	f, err := os.Open(fullPath)
+	if err != nil {
+		http.Error(w, "File not found", 404)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
 		http.Error(w, "File not found", 404)
 		return
 	}
 
-	// Set content type for inline display.
+	// Set content type for inline display; players go by it, not by the
+	// file's first bytes.
 	ext := strings.ToLower(filepath.Ext(file))
-	mime := mimeForExt(ext)
-	if mime != "" {
-		w.Header().Set("Content-Type", mime)
+	w.Header().Set("Content-Type", mimeForExt(ext))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	disposition := "inline"
+	if activeExts[ext] {
+		disposition = "attachment"
 	}
-	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filepath.Base(file)))
-	http.ServeFile(w, r, fullPath)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, filepath.Base(file)))
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
+}
+
+// handlePreview returns the text of an office document extracted from
+// "container", named by "file", so the GUI can show a Word, Excel, or
+// PowerPoint file without the program that made it.
+func handlePreview(w http.ResponseWriter, r *http.Request) {
+	s := session(r)
+	file := r.FormValue("file")
+	if !preview.Supported(filepath.Ext(file)) {
+		jsonError(w, preview.ErrUnsupported.Error(), 400)
+		return
+	}
+	fullPath, err := extractedFile(s, r.FormValue("container"), file)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	if _, err := os.Stat(fullPath); err != nil {
+		jsonError(w, "File not found", 404)
+		return
+	}
+	doc, err := preview.Office(fullPath)
+	if err != nil {
+		jsonError(w, err.Error(), 422)
+		return
+	}
+	jsonSuccess(w, "", doc)
 }
 
 // classifyFile returns a category based on file extension.
@@ -579,15 +1598,22 @@ func classifyFile(ext string) string {
 		return "document"
 	case ".zip", ".tar", ".gz", ".7z", ".rar", ".imf":
 		return "archive"
-	case ".mp3", ".wav", ".flac", ".aac", ".ogg", ".m4a":
+	case ".mp3", ".wav", ".flac", ".aac", ".ogg", ".oga", ".opus", ".m4a":
 		return "audio"
-	case ".mp4", ".mov", ".avi", ".mkv", ".webm":
+	case ".mp4", ".m4v", ".mov", ".avi", ".mkv", ".webm", ".ogv":
 		return "video"
 	default:
 		return "other"
 	}
 }
 
+// activeExts are the extensions of documents a browser runs scripts in,
+// which /api/serve-file hands over as downloads.
+var activeExts = map[string]bool{
+	".html": true, ".htm": true, ".xhtml": true, ".xht": true,
+	".svg": true, ".svgz": true, ".xml": true, ".xsl": true,
+}
+
 // mimeForExt returns a MIME type for common extensions.
 func mimeForExt(ext string) string {
 	mimes := map[string]string{
@@ -598,7 +1624,12 @@ func mimeForExt(ext string) string {
 		".html": "text/html", ".css": "text/css", ".js": "text/javascript",
 		".go": "text/plain", ".py": "text/plain", ".sh": "text/plain",
 		".log": "text/plain", ".yaml": "text/plain", ".yml": "text/plain",
-		".mp3": "audio/mpeg", ".wav": "audio/wav", ".mp4": "video/mp4",
+		".mp3": "audio/mpeg", ".wav": "audio/wav", ".flac": "audio/flac",
+		".aac": "audio/aac", ".ogg": "audio/ogg", ".oga": "audio/ogg",
+		".opus": "audio/ogg", ".m4a": "audio/mp4",
+		".mp4": "video/mp4", ".m4v": "video/mp4", ".mov": "video/quicktime",
+		".avi": "video/x-msvideo", ".mkv": "video/x-matroska",
+		".webm": "video/webm", ".ogv": "video/ogg",
 	}
 	if m, ok := mimes[ext]; ok {
 		return m
@@ -623,7 +1654,7 @@ func handleUploadContainer(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	dstPath := filepath.Join(state.WorkDir, header.Filename)
+	dstPath := containerFile(r, header.Filename)
 	dst, err := os.Create(dstPath)
 	if err != nil {
 		jsonError(w, fmt.Sprintf("Error saving container: %v", err), 500)
@@ -652,16 +1683,27 @@ func handleAnchor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := anchor.AnchorContainer(containerPath)
+	calendars, err := configuredCalendars()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	progress := session(r).progress.reporter("anchor")
+	defer progress.finish()
+	result, err := anchor.AnchorContainerWithOptions(containerPath, anchor.AnchorOptions{
+		Calendars: calendars,
+		Progress:  progress.calendars(),
+	})
 	if err != nil {
 		jsonError(w, err.Error(), 500)
 		return
 	}
 
-	jsonSuccess(w, "Anchored to Bitcoin", map[string]string{
+	jsonSuccess(w, "Anchored to Bitcoin", map[string]interface{}{
 		"hash":      result.ContainerHash,
 		"proof":     result.ProofPath,
 		"server":    result.Server,
+		"servers":   result.Servers,
 		"timestamp": result.Timestamp.Format(time.RFC3339),
 	})
 }
@@ -694,23 +1736,97 @@ func handleAnchorVerify(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleAnchorStatus asks the calendars whether the container's pending
+// .ots proof has been committed to Bitcoin yet, upgrading the proof if it
+// has, as "imf anchor -upgrade" does, and returns the result in the same
+// form as that command's -json output. The GUI polls it after anchoring
+// until the proof is complete. A container with no proof is answered with
+// 404, and any other failure, such as calendars that cannot be reached,
+// with 502.
+func handleAnchorStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	containerPath, err := resolveContainer(r)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+
+	result, err := anchor.UpgradeWithOptions(containerPath, anchor.UpgradeOptions{
+		Explorer: os.Getenv("IMF_EXPLORER_URL"),
+	})
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		jsonError(w, "Not yet anchored", 404)
+		return
+	case err != nil:
+		jsonError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	msg := "Waiting for Bitcoin confirmation"
+	if result.Complete() {
+		msg = "Confirmed in Bitcoin"
+	}
+	jsonSuccess(w, msg, newAnchorUpgradeJSON(filepath.Base(containerPath), result))
+}
+
+// handleHash returns the container file's SHA-256, for the sidebar to show
+// and copy so a recipient can check it against the sender's, and, if the
+// container has been anchored, the hash its proof commits to. Embedding a
+// proof changes the file, so after "imf anchor -embed" the two differ.
+func handleHash(w http.ResponseWriter, r *http.Request) {
+	containerPath, err := resolveContainer(r)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+
+	f, err := os.Open(containerPath)
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	data := map[string]string{"sha256": hex.EncodeToString(h.Sum(nil))}
+	if proof, _, err := anchor.LoadProof(containerPath); err == nil && proof != nil {
+		data["anchored"] = hex.EncodeToString(proof.Digest)
+	}
+	jsonSuccess(w, "", data)
+}
+
+// handleQR draws the text it is given, a container's hash, as a QR code in
+// SVG, for a recipient to scan from the sender's screen.
+func handleQR(w http.ResponseWriter, r *http.Request) {
+	code, err := qr.Encode([]byte(r.FormValue("text")), qr.M)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+	io.WriteString(w, code.SVG())
+}
+
 // handleWorkDir returns the current working directory path so the GUI can
 // show users where their .imf files are saved.
 func handleWorkDir(w http.ResponseWriter, r *http.Request) {
-	jsonSuccess(w, "", map[string]string{"path": state.WorkDir})
+	jsonSuccess(w, "", map[string]string{"path": workDir(session(r))})
 }
 
-// handleExportKey downloads the private key as a .pem file.
-// This is the only way keys leave memory — the user must explicitly request it.
-func handleExportKey(w http.ResponseWriter, r *http.Request) {
-	if state.PrivateKey == nil {
-		http.Error(w, "No key to export", 400)
-		return
-	}
-	pemData := imfcrypto.MarshalPrivateKeyPEM(state.PrivateKey)
-	w.Header().Set("Content-Type", "application/x-pem-file")
-	w.Header().Set("Content-Disposition", "attachment; filename=\"imf_private.pem\"")
-	w.Write(pemData)
+// containerFile returns the path of the container called name in the
+// working directory of the request's session. Only the last element of name
+// counts, so a request cannot reach outside that directory.
+func containerFile(r *http.Request, name string) string {
+	return filepath.Join(workDir(session(r)), filepath.Base(name))
 }
 
 // resolveContainer determines the container path from a request.
@@ -720,7 +1836,7 @@ func resolveContainer(r *http.Request) (string, error) {
 	// Check for a named container in the work directory.
 	name := r.FormValue("container")
 	if name != "" {
-		path := filepath.Join(state.WorkDir, name)
+		path := containerFile(r, name)
 		if _, err := os.Stat(path); err == nil {
 			return path, nil
 		}
@@ -730,7 +1846,7 @@ func resolveContainer(r *http.Request) (string, error) {
 	file, header, err := r.FormFile("container_file")
 	if err == nil {
 		defer file.Close()
-		tmpPath := filepath.Join(state.WorkDir, header.Filename)
+		tmpPath := containerFile(r, header.Filename)
 		dst, err := os.Create(tmpPath)
 		if err != nil {
 			return "", fmt.Errorf("saving uploaded container: %v", err)
@@ -753,10 +1869,16 @@ func jsonSuccess(w http.ResponseWriter, message string, data interface{}) {
 }
 
 func jsonError(w http.ResponseWriter, message string, code int) {
+	jsonErrorData(w, message, code, nil)
+}
+
+// jsonErrorData is jsonError with data the page needs to explain the error.
+func jsonErrorData(w http.ResponseWriter, message string, code int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(apiResponse{
 		Success: false,
 		Error:   message,
+		Data:    data,
 	})
 }
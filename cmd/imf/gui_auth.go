@@ -0,0 +1,101 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// apiToken is the secret every /api/ request must carry, generated afresh
+// each time the GUI starts. Only the page the GUI serves knows it, so a
+// local process or another web page cannot drive the API — or fetch the
+// private key from /api/export-key — just because the port is reachable.
+var apiToken string
+
+// newToken returns a random 256-bit token, hex-encoded.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// withAPIToken wraps the GUI's handlers so that a request to /api/ or /ws
+// is refused unless it carries token in the X-IMF-Token header. Only a GET
+// or HEAD — a link the browser follows itself (downloads, previews) or the
+// progress socket — may carry it in the "token" query parameter instead, so
+// a request that changes something always comes from the page's own
+// scripts: a plain form posted by another site cannot set the header. Such
+// requests must also come from the GUI's own origin, by their Origin and
+// Sec-Fetch-Site headers. It also refuses any request, the page included,
+// whose Host is not a loopback name, so a DNS-rebound page cannot read the
+// token — unless the GUI serves other machines, where users sign in first.
+func withAPIToken(h http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if remote == nil && !loopbackHost(r.Host) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/ws" {
+			if !sameOrigin(r) {
+				jsonError(w, "Cross-origin request refused", http.StatusForbidden)
+				return
+			}
+			got := r.Header.Get("X-IMF-Token")
+			if got == "" && (r.Method == "GET" || r.Method == "HEAD") {
+				got = r.URL.Query().Get("token")
+			}
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				jsonError(w, "Missing or invalid session token", http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether a request may have come from the GUI's page.
+// A browser says where a request comes from in Sec-Fetch-Site and, for a
+// POST or a WebSocket, in Origin; another page's, even one on another port
+// of this machine, is refused. A request with neither header is not from a
+// browser, or from one too old to send them, and is left to the token.
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return u.Scheme == scheme && strings.EqualFold(u.Host, r.Host)
+}
+
+// loopbackHost reports whether host, a Host header, names this machine.
+func loopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
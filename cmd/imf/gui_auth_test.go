@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setRemote sets the GUI to serve other machines through g, or only this
+// one if g is nil, for the rest of the test.
+func setRemote(t *testing.T, g *remoteGUI) {
+	t.Helper()
+	old := remote
+	remote = g
+	t.Cleanup(func() { remote = old })
+}
+
+// okHandler answers 200 and counts the requests that reach it.
+func okHandler(calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestWithAPIToken(t *testing.T) {
+	setRemote(t, nil)
+	const token = "0123456789abcdef"
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		host    string
+		headers map[string]string
+		want    int
+	}{
+		{"page without token", "GET", "/", "127.0.0.1:8080", nil, 200},
+		{"page on localhost", "GET", "/", "localhost:8080", nil, 200},
+		{"API without token", "POST", "/api/seal", "127.0.0.1:8080", nil, 403},
+		{"API with wrong token", "POST", "/api/seal", "127.0.0.1:8080", map[string]string{"X-IMF-Token": "wrong"}, 403},
+		{"API with token", "POST", "/api/seal", "127.0.0.1:8080", map[string]string{"X-IMF-Token": token}, 200},
+		{"socket without token", "GET", "/ws", "127.0.0.1:8080", nil, 403},
+		{"GET with token in query", "GET", "/api/serve-file?token=" + token, "127.0.0.1:8080", nil, 200},
+		{"POST with token in query", "POST", "/api/seal?token=" + token, "127.0.0.1:8080", nil, 403},
+		{"GET with wrong token in query", "GET", "/api/serve-file?token=wrong", "127.0.0.1:8080", nil, 403},
+		{"cross-site", "POST", "/api/seal", "127.0.0.1:8080",
+			map[string]string{"X-IMF-Token": token, "Sec-Fetch-Site": "cross-site"}, 403},
+		{"same-site, another port", "POST", "/api/seal", "127.0.0.1:8080",
+			map[string]string{"X-IMF-Token": token, "Sec-Fetch-Site": "same-site"}, 403},
+		{"other origin", "POST", "/api/seal", "127.0.0.1:8080",
+			map[string]string{"X-IMF-Token": token, "Origin": "http://127.0.0.1:9999"}, 403},
+		{"same origin", "POST", "/api/seal", "127.0.0.1:8080",
+			map[string]string{"X-IMF-Token": token, "Origin": "http://127.0.0.1:8080", "Sec-Fetch-Site": "same-origin"}, 200},
+		{"rebound host, page", "GET", "/", "evil.example:8080", nil, 403},
+		{"rebound host, API", "POST", "/api/seal", "evil.example:8080", map[string]string{"X-IMF-Token": token}, 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Host = tt.host
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			withAPIToken(okHandler(&calls), token).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if (calls == 1) != (tt.want == 200) {
+				t.Fatalf("handler called %d times", calls)
+			}
+		})
+	}
+}
+
+func TestWithAPITokenRemote(t *testing.T) {
+	setRemote(t, &remoteGUI{auth: "basic"})
+	const token = "0123456789abcdef"
+	var calls int
+	h := withAPIToken(okHandler(&calls), token)
+
+	// A shared server is reached by its own name, but still needs the token.
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Host = "imf.example.com"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("page: status = %d, want 200", rec.Code)
+	}
+	req = httptest.NewRequest("POST", "/api/seal", nil)
+	req.Host = "imf.example.com"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != 403 {
+		t.Fatalf("API without token: status = %d, want 403", rec.Code)
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		site, origin string
+		tls          bool
+		want         bool
+	}{
+		{"", "", false, true},
+		{"none", "", false, true},
+		{"same-origin", "http://127.0.0.1:8080", false, true},
+		{"same-origin", "http://LOCALHOST:8080", false, false},
+		{"cross-site", "", false, false},
+		{"same-site", "", false, false},
+		{"", "http://127.0.0.1:8081", false, false},
+		{"", "https://127.0.0.1:8080", false, false},
+		{"", "http://127.0.0.1:8080", true, false},
+		{"", "null", false, false},
+		{"", "http://evil.example", false, false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/seal", nil)
+		req.Host = "127.0.0.1:8080"
+		if tt.site != "" {
+			req.Header.Set("Sec-Fetch-Site", tt.site)
+		}
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if tt.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		if got := sameOrigin(req); got != tt.want {
+			t.Errorf("Sec-Fetch-Site %q, Origin %q, TLS %v: sameOrigin = %v, want %v", tt.site, tt.origin, tt.tls, got, tt.want)
+		}
+	}
+}
+
+func TestLoopbackHost(t *testing.T) {
+	tests := map[string]bool{
+		"localhost":           true,
+		"LocalHost:8080":      true,
+		"127.0.0.1":           true,
+		"127.0.0.1:8080":      true,
+		"127.9.9.9:80":        true,
+		"[::1]:8080":          true,
+		"::1":                 true,
+		"":                    false,
+		"0.0.0.0:8080":        false,
+		"192.168.1.10:8080":   false,
+		"evil.example":        false,
+		"localhost.evil.com":  false,
+		"127.0.0.1.nip.io:80": false,
+	}
+	for host, want := range tests {
+		if got := loopbackHost(host); got != want {
+			t.Errorf("loopbackHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestExportPrivateKeyApp(t *testing.T) {
+	old := appWindow
+	appWindow = true
+	t.Cleanup(func() { appWindow = old })
+	s := &guiState{}
+	key, signer := newSessionKey(t)
+	s.setKey(key, signer, signer.Public(), "")
+
+	// The desktop app cannot show the confirmation code, so no code is
+	// issued and the export is refused.
+	req := httptest.NewRequest("POST", "/api/export-key", strings.NewReader("private=true"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(context.WithValue(req.Context(), sessionKey{}, s))
+	rec := httptest.NewRecorder()
+	handleExportKey(rec, req)
+	if rec.Code != http.StatusForbidden || s.exportCode != "" {
+		t.Fatalf("status = %d, code issued %v; want 403 and none", rec.Code, s.exportCode != "")
+	}
+}
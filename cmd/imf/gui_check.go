@@ -0,0 +1,153 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/keyring"
+)
+
+// The launch screen's "Verify a Container" page is for someone who has been
+// sent a container and wants to know whether to trust it, without opening
+// it: they drop the .imf, and if they have them the sender's public key and
+// a detached .ots proof, and get a pass or fail with who signed it and how
+// far its anchor has got. Nothing is extracted, and the container is not
+// kept in the working directory or its history.
+
+// checkJSON is what /api/check returns.
+type checkJSON struct {
+	Report      verifyJSON        `json:"report"`
+	Info        *infoJSON         `json:"info,omitempty"`
+	SHA256      string            `json:"sha256"`                // of the container file, to compare with the sender's
+	Fingerprint string            `json:"fingerprint,omitempty"` // the signing key's
+	KeyGiven    bool              `json:"key_given"`             // verified against the public key the user gave, not the embedded one
+	TrustedAs   string            `json:"trusted_as,omitempty"`  // the signing key's name in the trust store
+	Anchor      *anchorStatusJSON `json:"anchor,omitempty"`      // from the proofs given alone; the calendars are not asked
+}
+
+// handleCheck verifies the container posted as "container_file", with the
+// sender's public key as "pubkey" (PEM) and a detached proof as "ots" if
+// given. The files are kept in the session's directory only while they are
+// checked. An expired container is verified all the same, and reported as
+// expired.
+func handleCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	file, header, err := r.FormFile("container_file")
+	if err != nil {
+		jsonError(w, "No container file provided", 400)
+		return
+	}
+	defer file.Close()
+	name := filepath.Base(header.Filename)
+	if name == "." || name == string(filepath.Separator) {
+		name = "container.imf"
+	}
+
+	dir, err := os.MkdirTemp(session(r).WorkDir, "check-")
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+		return
+	}
+	defer os.RemoveAll(dir)
+	containerPath := filepath.Join(dir, name)
+	h := sha256.New()
+	if err := saveFormFile(io.TeeReader(file, h), containerPath); err != nil {
+		jsonError(w, fmt.Sprintf("Error saving container: %v", err), 500)
+		return
+	}
+	if ots, _, err := r.FormFile("ots"); err == nil {
+		err = saveFormFile(ots, containerPath+".ots")
+		ots.Close()
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Error saving proof: %v", err), 500)
+			return
+		}
+	}
+
+	opts := container.VerifyOptions{IgnoreExpiry: true}
+	if f, _, err := r.FormFile("pubkey"); err == nil {
+		data, err := io.ReadAll(io.LimitReader(f, 64<<10))
+		f.Close()
+		if err == nil {
+			opts.PublicKey, err = imfcrypto.ParsePublicKeyPEM(data)
+		}
+		if err != nil {
+			jsonError(w, "Not an Ed25519 public key: "+err.Error(), 400)
+			return
+		}
+	}
+	if revDir, err := keyring.DefaultRevocationDir(); err == nil {
+		if opts.Revocations, err = container.LoadRevocations(revDir); err != nil {
+			jsonError(w, "Reading revocation list: "+err.Error(), 500)
+			return
+		}
+	}
+
+	result := checkJSON{SHA256: hex.EncodeToString(h.Sum(nil)), KeyGiven: opts.PublicKey != nil}
+	if info, err := container.GetInfo(containerPath); err == nil {
+		j := newInfoJSON(name, info)
+		result.Info = &j
+		if info.Signer != nil {
+			result.Fingerprint = info.Signer.Fingerprint
+		}
+	}
+	if opts.PublicKey != nil {
+		result.Fingerprint = imfcrypto.Fingerprint(opts.PublicKey)
+	}
+	if status, err := anchor.Status(containerPath); err != nil && result.Info != nil {
+		result.Anchor = &anchorStatusJSON{State: "unreadable", Error: err.Error(), Blocks: []blockJSON{}, Pending: []string{}}
+	} else if status != nil {
+		result.Anchor = newAnchorStatusJSON(status)
+		result.Anchor.Proof = filepath.Base(status.ProofPath)
+	}
+
+	report, err := container.VerifyWithReport(containerPath, opts)
+	if err != nil {
+		result.Report = newFailedVerifyJSON(name, report, err)
+		jsonErrorData(w, err.Error(), 400, result)
+		return
+	}
+	result.Report = newVerifyJSON(name, report, opts)
+	if report.Signer != nil && opts.PublicKey == nil {
+		result.Fingerprint = report.Signer.Fingerprint
+	}
+	if remote == nil && result.Fingerprint != "" {
+		if ts, err := keyring.OpenTrustStore(); err == nil {
+			keys, _ := ts.List()
+			for _, k := range keys {
+				if k.Fingerprint == result.Fingerprint {
+					result.TrustedAs = k.Name
+				}
+			}
+		}
+	}
+	jsonSuccess(w, "Signature and integrity verified", result)
+}
+
+// saveFormFile writes an uploaded file's contents to path.
+func saveFormFile(src io.Reader, path string) error {
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
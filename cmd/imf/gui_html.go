@@ -3,15 +3,33 @@
 
 package main
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
 
+// handleIndex serves the page with the session's API token filled in, on a
+// shared server the signed-in user, and whether it is the desktop app's
+// window.
 func handleIndex(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
 		return
 	}
+	var account interface{}
+	if remote != nil {
+		account = map[string]interface{}{"user": requestUser(r), "logout": remote.auth == "oidc"}
+	}
+	acct, _ := json.Marshal(account)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(indexHTML))
+	w.Header().Set("Cache-Control", "no-store")
+	// Another site may not frame the page to trick clicks out of its user.
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	app, _ := json.Marshal(appWindow)
+	w.Write([]byte(strings.NewReplacer("{{API_TOKEN}}", apiToken, "{{ACCOUNT}}", string(acct),
+		"{{APP_WINDOW}}", string(app)).Replace(indexHTML)))
 }
 
 const indexHTML = `<!DOCTYPE html>
@@ -23,6 +41,9 @@ const indexHTML = `<!DOCTYPE html>
 <style>
 :root{--bg:#0f1117;--surface:#1a1d27;--surface2:#232735;--surface3:#2a2e3f;--border:#2e3345;--border-light:#3a3f55;--text:#e1e4ed;--text-dim:#8b90a0;--text-faint:#5a5f70;--accent:#4f8ff7;--accent-glow:rgba(79,143,247,.12);--accent-strong:rgba(79,143,247,.25);--success:#34d399;--success-bg:rgba(52,211,153,.1);--error:#f87171;--error-bg:rgba(248,113,113,.1);--warning:#fbbf24;--warning-bg:rgba(251,191,36,.1);--radius:10px;--mono:'SF Mono','Fira Code','Consolas',monospace}
 *{margin:0;padding:0;box-sizing:border-box}
+body.shared .local-only{display:none!important}
+.account{font-size:12px;color:var(--text-dim);margin-left:auto}
+.account a{color:var(--accent);margin-left:8px}
 body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sans-serif;background:var(--bg);color:var(--text);min-height:100vh;line-height:1.6;overflow:hidden}
 #launchScreen{display:flex;flex-direction:column;align-items:center;justify-content:center;height:100vh;gap:48px}
 .launch-logo{text-align:center}
@@ -36,19 +57,109 @@ body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sa
 .launch-card h3{font-size:16px;font-weight:600;margin-bottom:6px}
 .launch-card p{font-size:13px;color:var(--text-dim)}
 .launch-card input[type="file"]{display:none}
+.recent{display:none;width:520px}
+.recent.active{display:block}
+.recent h4{font-size:11px;text-transform:uppercase;letter-spacing:.8px;color:var(--text-faint);margin-bottom:8px}
+.recent-list{max-height:220px;overflow-y:auto;background:var(--surface);border:1px solid var(--border);border-radius:10px}
+.rrow{display:flex;align-items:center;gap:12px;padding:8px 14px;border-bottom:1px solid var(--border);cursor:pointer;transition:background .12s}
+.rrow:last-child{border-bottom:none}
+.rrow:hover{background:var(--accent-glow)}
+.rrow.missing{opacity:.5;cursor:default}
+.rmain{flex:1;min-width:0}
+.rname{font-size:13px;font-weight:600;overflow:hidden;text-overflow:ellipsis;white-space:nowrap}
+.rpath{font-family:var(--mono);font-size:10px;color:var(--text-faint);overflow:hidden;text-overflow:ellipsis;white-space:nowrap}
+.rverify{font-size:11px;white-space:nowrap}
+.rverify.pass{color:var(--success)}
+.rverify.fail{color:var(--error)}
+.rwhen{font-size:11px;color:var(--text-dim);white-space:nowrap}
 .launch-key-section{display:flex;align-items:center;gap:12px;padding:12px 20px;background:var(--surface);border:1px solid var(--border);border-radius:10px}
 .launch-key-section .status{font-size:13px;color:var(--text-dim)}
 .launch-key-section .status.loaded{color:var(--success)}
+#checkScreen{display:none;flex-direction:column;align-items:center;height:100vh;overflow-y:auto;padding:48px 24px;gap:20px}
+#checkScreen.active{display:flex}
+#checkScreen>*{width:560px;max-width:100%}
+.check-head{display:flex;align-items:center;justify-content:space-between}
+.check-head h2{font-size:20px;font-weight:600}
+.check-drop{padding:28px;border:2px dashed var(--border);border-radius:14px;text-align:center;color:var(--text-dim);font-size:13px;cursor:pointer;transition:all .2s}
+.check-drop.over,.check-drop:hover{border-color:var(--accent);background:var(--accent-glow);color:var(--accent)}
+.check-drop input{display:none}
+.check-slots{background:var(--surface);border:1px solid var(--border);border-radius:10px}
+.check-slot{display:flex;align-items:center;gap:12px;padding:8px 14px;border-bottom:1px solid var(--border);font-size:13px}
+.check-slot:last-child{border-bottom:none}
+.check-slot .label{width:150px;color:var(--text-dim)}
+.check-slot .fname{flex:1;min-width:0;overflow:hidden;text-overflow:ellipsis;white-space:nowrap}
+.check-slot .fname.none{color:var(--text-faint)}
+.check-result{background:var(--surface);border:1px solid var(--border);border-radius:10px;padding:20px}
+.check-result:empty{display:none}
+.check-result .verify-status{font-size:18px;padding:16px;margin:0 0 6px}
+.check-result .check-why{font-size:12px;color:var(--text-dim);text-align:center;margin-bottom:16px;word-break:break-word}
+.check-result .meta-row .value{max-width:70%;word-break:break-word}
+.check-result .hash-label{margin-top:16px}
 .lkb{padding:6px 16px;border-radius:6px;font-size:12px;font-weight:500;cursor:pointer;border:1px solid var(--border);background:var(--surface2);color:var(--text);transition:all .2s}
 .lkb:hover{border-color:var(--accent);color:var(--accent)}
+#stopped{display:none;position:fixed;inset:0;z-index:300;background:var(--bg);flex-direction:column;align-items:center;justify-content:center;gap:12px;text-align:center}
+#stopped.active{display:flex}
+#stopped p{color:var(--text-dim);font-size:14px}
 .modal-overlay{display:none;position:fixed;top:0;left:0;right:0;bottom:0;background:rgba(0,0,0,.6);z-index:100;align-items:center;justify-content:center}
 .modal-overlay.active{display:flex}
 .modal{background:var(--surface);border:1px solid var(--border);border-radius:16px;padding:32px;width:420px;box-shadow:0 16px 64px rgba(0,0,0,.5)}
 .modal h2{font-size:18px;margin-bottom:20px}
 .modal label{display:block;font-size:13px;color:var(--text-dim);font-weight:500;margin-bottom:6px}
-.modal input[type="text"],.modal input[type="password"],.modal input[type="date"]{width:100%;padding:10px 14px;background:var(--bg);border:1px solid var(--border);border-radius:8px;color:var(--text);font-size:14px;outline:none;margin-bottom:16px}
-.modal input:focus{border-color:var(--accent)}
+.modal input[type="text"],.modal input[type="password"],.modal input[type="date"],.modal input[type="number"],.modal textarea{width:100%;padding:10px 14px;background:var(--bg);border:1px solid var(--border);border-radius:8px;color:var(--text);font-size:14px;outline:none;margin-bottom:16px}
+.modal select{width:100%;padding:10px 14px;background:var(--bg);border:1px solid var(--border);border-radius:8px;color:var(--text);font-size:14px;outline:none;margin-bottom:16px}
+.modal textarea{font-family:var(--mono);font-size:12px;resize:vertical}
+.modal input:focus,.modal select:focus,.modal textarea:focus{border-color:var(--accent)}
 .modal-btns{display:flex;gap:12px;justify-content:flex-end;margin-top:8px}
+.key-modal{width:560px;max-height:90vh;overflow-y:auto}
+.key-modal h4{font-size:11px;text-transform:uppercase;letter-spacing:.8px;color:var(--text-faint);margin:16px 0 8px}
+.km-list{max-height:240px;overflow-y:auto}
+.km-row{display:flex;align-items:center;justify-content:space-between;gap:12px;padding:10px 12px;border:1px solid var(--border);border-radius:8px;margin-bottom:6px}
+.km-row.active{border-color:var(--accent);background:var(--accent-glow)}
+.km-name{font-size:13px;font-weight:600}
+.km-fp{font-family:var(--mono);font-size:10px;color:var(--text-dim);word-break:break-all}
+.km-tag{font-size:10px;font-weight:500;color:var(--warning)}
+.md-tags{display:flex;flex-wrap:wrap;gap:4px;margin:6px 0}
+.md-tags span{font-size:11px;padding:2px 8px;border-radius:10px;background:var(--accent-glow);color:var(--accent)}
+.md-desc{font-size:12px;color:var(--text-dim);white-space:pre-wrap;word-break:break-word;margin:6px 0}
+.md-none{font-size:12px;color:var(--text-faint)}
+.md-sig{font-size:11px;color:var(--text-dim);margin-top:6px}
+.md-sig.good{color:var(--success)}
+.md-sig.bad{color:var(--error)}
+.modal textarea.md-desc-in{font-family:inherit;font-size:14px}
+.modal .md-req{border-color:var(--warning)}
+.tpl-about{font-size:12px;color:var(--text-dim);margin:-8px 0 16px}
+.tpl-about:empty{display:none}
+.km-btns{display:flex;flex-direction:column;gap:4px;flex-shrink:0}
+.km-empty{font-size:13px;color:var(--text-dim);padding:8px 0}
+.km-actions{display:flex;flex-wrap:wrap;gap:8px;margin:16px 0}
+.settings-modal{width:560px;max-height:90vh;overflow-y:auto}
+.set-note{font-size:11px;color:var(--text-faint);margin:-10px 0 16px;word-break:break-all}
+.set-note .env{color:var(--warning)}
+.report-modal{width:880px;max-width:95vw;max-height:90vh;display:flex;flex-direction:column}
+.rp-wrap{flex:1;overflow:auto;margin:12px 0;border:1px solid var(--border);border-radius:8px}
+.rp-table{width:100%;border-collapse:collapse;font-size:12px}
+.rp-table th{position:sticky;top:0;background:var(--surface2);text-align:left;padding:8px 10px;font-size:11px;font-weight:600;text-transform:uppercase;letter-spacing:.5px;color:var(--text-faint)}
+.rp-table td{padding:8px 10px;border-top:1px solid var(--border);vertical-align:top}
+.rp-table tr.bad td{background:var(--error-bg)}
+.rp-hash{font-family:var(--mono);font-size:10px;word-break:break-all}
+.rp-ok{color:var(--success);font-weight:600;white-space:nowrap}
+.rp-bad{color:var(--error);font-weight:600;white-space:nowrap}
+.rp-link{display:block;margin-top:8px;background:none;border:none;color:var(--accent);font-size:12px;cursor:pointer;width:100%}
+.rp-link:hover{text-decoration:underline}
+.diff-bar{display:flex;align-items:center;gap:12px;flex-wrap:wrap;font-size:13px}
+.diff-bar select{width:auto;margin:0;flex:1;min-width:160px}
+.diff-bar .seal-check{margin:0}
+.diff-sum{font-size:12px;color:var(--text-dim);margin-top:12px}
+.rp-table tr.added td{background:var(--success-bg)}
+.rp-table tr.removed td{background:var(--error-bg)}
+.rp-table tr.modified td,.rp-table tr.moved td{background:var(--warning-bg)}
+.diff-change{font-weight:600;white-space:nowrap}
+.diff-side{color:var(--text-faint)}
+.pw-meter{display:none;margin:-10px 0 16px}
+.pw-meter.active{display:block}
+.pw-bar{height:4px;background:var(--surface3);border-radius:2px;overflow:hidden}
+.pw-bar span{display:block;height:100%;width:0;transition:width .2s,background .2s}
+.pw-text{font-size:12px;color:var(--text-dim);margin-top:4px}
 .seal-check{display:flex;align-items:center;gap:8px;font-size:13px;margin-bottom:12px}
 .seal-check input{accent-color:var(--accent)}
 #workspace{display:none;height:100vh;flex-direction:column}
@@ -62,6 +173,17 @@ body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sa
 .state-badge.open{background:var(--warning-bg);color:var(--warning);border:1px solid var(--warning)}
 .state-badge.sealed{background:var(--success-bg);color:var(--success);border:1px solid var(--success)}
 .titlebar-actions{display:flex;gap:8px}
+.tabbar{display:flex;align-items:flex-end;gap:4px;padding:6px 12px 0;background:var(--bg);border-bottom:1px solid var(--border);overflow-x:auto}
+.tab{display:flex;align-items:center;gap:8px;padding:6px 10px 6px 12px;border:1px solid var(--border);border-bottom:none;border-radius:8px 8px 0 0;font-size:12px;color:var(--text-dim);cursor:pointer;white-space:nowrap;max-width:220px}
+.tab:hover{color:var(--text)}
+.tab.active{background:var(--surface);color:var(--text)}
+.tab .tname{overflow:hidden;text-overflow:ellipsis}
+.tdot{width:7px;height:7px;border-radius:50%;flex-shrink:0;background:var(--warning)}
+.tdot.sealed{background:var(--success)}
+.tclose{border:none;background:transparent;color:var(--text-faint);cursor:pointer;font-size:14px;line-height:1;padding:0 2px}
+.tclose:hover{color:var(--error)}
+.tab-new{padding:4px 10px;margin-bottom:4px;border:1px solid var(--border);border-radius:6px;background:transparent;color:var(--text-dim);cursor:pointer;font-size:13px}
+.tab-new:hover{border-color:var(--accent);color:var(--accent)}
 .workspace-body{display:flex;flex:1;overflow:hidden}
 .sidebar{width:260px;background:var(--surface);border-right:1px solid var(--border);overflow-y:auto}
 .sidebar-section{padding:16px 20px;border-bottom:1px solid var(--border)}
@@ -72,9 +194,18 @@ body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sa
 .meta-row .value.good{color:var(--success)}
 .meta-row .value.warn{color:var(--warning)}
 .meta-row .value.bad{color:var(--error)}
+.hash-box{font-family:'SF Mono',Menlo,Consolas,monospace;font-size:11px;line-height:1.5;word-break:break-all;background:var(--bg);border:1px solid var(--border);border-radius:6px;padding:8px 10px;color:var(--text-dim);user-select:all}
+.hash-label{font-size:12px;color:var(--text-dim);margin:12px 0 6px}
+.hash-btns{display:flex;gap:6px;margin-top:8px}
+.hash-btns .tb{font-size:11px;padding:4px 10px}
+.qr-modal img{display:block;width:240px;height:240px;margin:0 auto 16px;background:#fff;border-radius:8px}
 .verify-status{padding:10px;border-radius:8px;text-align:center;font-size:13px;font-weight:600;margin-top:8px}
 .verify-status.pass{background:var(--success-bg);color:var(--success)}
 .verify-status.fail{background:var(--error-bg);color:var(--error)}
+.exp-banner{display:none;align-items:center;gap:12px;padding:10px 20px;font-size:13px;font-weight:600;border-bottom:1px solid}
+.exp-banner.expired{display:flex;background:var(--error-bg);color:var(--error);border-color:var(--error)}
+.exp-banner.soon{display:flex;background:var(--warning-bg);color:var(--warning);border-color:var(--warning)}
+.exp-banner span{flex:1}
 .verify-status.pending{background:var(--surface2);color:var(--text-dim)}
 .file-area{flex:1;display:flex;flex-direction:column;overflow:hidden;position:relative}
 .file-toolbar{display:flex;align-items:center;justify-content:space-between;padding:10px 20px;border-bottom:1px solid var(--border);background:var(--surface2)}
@@ -84,15 +215,17 @@ body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sa
 .tb.primary{background:var(--accent);color:#fff;border-color:var(--accent)}
 .tb.primary:hover{background:#3d7de5}
 .tb.success{background:var(--success);color:var(--bg);border-color:var(--success)}
-.file-list-header{display:grid;grid-template-columns:32px 1fr 90px 90px 80px;gap:8px;padding:8px 20px;font-size:11px;font-weight:600;color:var(--text-faint);text-transform:uppercase;letter-spacing:.5px;border-bottom:1px solid var(--border);background:var(--surface)}
+.file-list-header{display:grid;grid-template-columns:32px 1fr 90px 90px 150px;gap:8px;padding:8px 20px;font-size:11px;font-weight:600;color:var(--text-faint);text-transform:uppercase;letter-spacing:.5px;border-bottom:1px solid var(--border);background:var(--surface)}
 .file-scroll{flex:1;overflow-y:auto}
-.frow{display:grid;grid-template-columns:32px 1fr 90px 90px 80px;gap:8px;padding:10px 20px;font-size:13px;border-bottom:1px solid var(--border);cursor:default;transition:background .12s;align-items:center}
+.frow{display:grid;grid-template-columns:32px 1fr 90px 90px 150px;gap:8px;padding:10px 20px;font-size:13px;border-bottom:1px solid var(--border);cursor:default;transition:background .12s;align-items:center}
 .frow:hover{background:var(--accent-glow)}
 .frow.selected{background:var(--accent-strong)}
 .frow .icon{font-size:20px;text-align:center}
 .frow .fname{font-weight:500;overflow:hidden;text-overflow:ellipsis;white-space:nowrap}
 .frow .fsize,.frow .ftype{color:var(--text-dim);font-size:12px}
-.frow .factions{display:flex;gap:4px}
+.frow .factions{display:flex;gap:4px;justify-content:flex-end}
+.frow.folder{cursor:pointer}
+.frow .caret{display:inline-block;width:14px;color:var(--text-dim);font-size:10px}
 .fa-btn{padding:3px 8px;border-radius:4px;border:1px solid var(--border);background:transparent;color:var(--text-dim);font-size:11px;cursor:pointer;transition:all .15s}
 .fa-btn:hover{border-color:var(--accent);color:var(--accent)}
 .empty-state{flex:1;display:flex;flex-direction:column;align-items:center;justify-content:center;color:var(--text-dim);gap:16px}
@@ -107,7 +240,16 @@ body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sa
 .preview-thumb img{max-width:100%;max-height:200px}
 .preview-thumb pre{padding:12px;font-size:10px;font-family:var(--mono);max-height:200px;overflow:auto;text-align:left;width:100%;color:var(--text);margin:0}
 .preview-thumb iframe{width:100%;height:200px;border:none}
+.preview-thumb video{width:100%;max-height:240px;background:#000}
+.preview-thumb audio{width:100%;margin:12px}
 .preview-thumb .big-icon{font-size:64px;opacity:.5;padding:32px}
+.preview-thumb .doc-pv{padding:12px;font-size:11px;max-height:320px;overflow:auto;text-align:left;width:100%;color:var(--text)}
+.doc-pv h5{font-size:11px;color:var(--text-dim);margin:10px 0 4px;text-transform:uppercase;letter-spacing:.5px}
+.doc-pv h5:first-child{margin-top:0}
+.doc-pv p{margin:0 0 6px;white-space:pre-wrap;word-break:break-word}
+.doc-pv table{border-collapse:collapse;font-size:10px}
+.doc-pv td{border:1px solid var(--border);padding:2px 5px;white-space:nowrap}
+.doc-pv .note{color:var(--text-dim);font-style:italic}
 .pv-name{font-size:14px;font-weight:600}
 .pv-meta{padding:16px;font-size:12px}
 .pv-meta-row{display:flex;justify-content:space-between;padding:6px 0;border-bottom:1px solid var(--border)}
@@ -122,52 +264,258 @@ body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sa
 .toast.success{background:var(--success-bg);color:var(--success);border:1px solid var(--success)}
 .toast.error{background:var(--error-bg);color:var(--error);border:1px solid var(--error)}
 @keyframes slideIn{from{transform:translateY(20px);opacity:0}}
+.progress{display:none;position:fixed;bottom:24px;left:50%;transform:translateX(-50%);width:360px;padding:12px 16px;background:var(--surface);border:1px solid var(--border);border-radius:var(--radius);box-shadow:0 8px 32px rgba(0,0,0,.4);z-index:150}
+.progress.active{display:block}
+.progress-head{display:flex;justify-content:space-between;font-size:12px;color:var(--text-dim);margin-bottom:6px}
+.progress .pw-bar span{background:var(--accent)}
+.sb-toggle,.pv-close,.sb-scrim{display:none}
+@media (max-width:900px){
+#workspace{height:100dvh}
+.workspace-body{position:relative}
+.sb-toggle{display:inline-block}
+.sidebar{position:absolute;top:0;left:0;bottom:0;width:min(300px,85%);z-index:70;transform:translateX(-100%);transition:transform .2s}
+.sb-open .sidebar{transform:none;box-shadow:8px 0 32px rgba(0,0,0,.4)}
+.sb-open .sb-scrim{display:block;position:absolute;inset:0;z-index:65;background:rgba(0,0,0,.4)}
+.preview-pane{position:absolute;top:0;right:0;bottom:0;width:min(360px,100%);z-index:60;box-shadow:-8px 0 32px rgba(0,0,0,.4)}
+.pv-close{display:block;margin:0 0 12px auto}
+#launchScreen{justify-content:flex-start;height:100dvh;overflow-y:auto;padding:48px 24px}
+.launch-actions{flex-wrap:wrap;justify-content:center}
+}
+@media (max-width:600px){
+#launchScreen{padding:32px 16px;gap:24px}
+.launch-logo h1{font-size:32px}
+.launch-actions{flex-direction:column;width:100%;gap:12px}
+.launch-card{width:100%;padding:18px 16px}
+.launch-card:hover{transform:none}
+.launch-card .icon{font-size:32px;margin-bottom:6px}
+.recent{width:100%}
+.launch-key-section{flex-wrap:wrap;justify-content:center}
+.titlebar{flex-wrap:wrap;gap:8px;padding:8px 12px}
+.titlebar-left{min-width:0;flex:1}
+.container-name{overflow:hidden;text-overflow:ellipsis;white-space:nowrap}
+.titlebar-actions{width:100%;overflow-x:auto}
+.titlebar-actions>*{flex-shrink:0;white-space:nowrap}
+.file-toolbar{flex-wrap:wrap;gap:8px;padding:8px 12px}
+.file-list-header,.frow{grid-template-columns:28px 1fr auto;padding-left:12px;padding-right:12px}
+.file-list-header>:nth-child(3),.file-list-header>:nth-child(4),.frow .fsize,.frow .ftype{display:none}
+.frow .factions{flex-wrap:wrap}
+.modal{max-width:calc(100vw - 24px);max-height:calc(100dvh - 24px);overflow-y:auto;padding:20px}
+.toast{left:12px;right:12px;max-width:none}
+.progress{width:calc(100vw - 24px)}
+.check-slot .label{width:110px}
+}
+@media (pointer:coarse){
+.frow{padding-top:14px;padding-bottom:14px}
+.fa-btn{padding:8px 12px;font-size:12px}
+.tb,.lkb{padding:10px 16px}
+.tab{padding:10px 12px}
+.tclose{font-size:18px;padding:4px 6px}
+.rrow{padding:12px 14px}
+}
 </style>
 </head>
 <body>
+<div class="progress" id="progress">
+  <div class="progress-head"><span id="progLabel"></span><span id="progText"></span></div>
+  <div class="pw-bar"><span id="progBar"></span></div>
+</div>
 <div id="launchScreen">
-  <div class="launch-logo"><h1><span>IMF</span></h1><p>Immutable File Container</p></div>
+  <div class="launch-logo"><h1><span>IMF</span></h1><p data-i18n>Immutable File Container</p></div>
   <div class="launch-actions">
     <div class="launch-card" onclick="document.getElementById('openFile').click()">
-      <div class="icon">&#128194;</div><h3>Open Existing</h3><p>Open and inspect an .imf container</p>
+      <div class="icon">&#128194;</div><h3 data-i18n>Open Existing</h3><p data-i18n>Open and inspect an .imf container</p>
       <input type="file" id="openFile" accept=".imf" onchange="handleOpen(this.files[0])">
     </div>
-    <div class="launch-card" onclick="showModal('createModal')">
-      <div class="icon">&#10010;</div><h3>Create New</h3><p>Create a new container and add files</p>
+    <div class="launch-card" onclick="openCreate()">
+      <div class="icon">&#10010;</div><h3 data-i18n>Create New</h3><p data-i18n>Create a new container and add files</p>
+    </div>
+    <div class="launch-card" onclick="openCheck()">
+      <div class="icon">&#10004;</div><h3 data-i18n>Verify a Container</h3><p data-i18n>Check a container you were sent, without opening it</p>
     </div>
   </div>
+  <div class="recent" id="recent">
+    <h4 data-i18n>Recent Containers</h4>
+    <div class="recent-list" id="recentList"></div>
+  </div>
   <div class="launch-key-section">
-    <span id="keyStatus" class="status">Key auto-generated on seal</span>
-    <button class="lkb" onclick="document.getElementById('keyFile').click()">Import Existing Key</button>
-    <button class="lkb" onclick="exportKey()" id="exportBtn" style="display:none">Export Key</button>
-    <input type="file" id="keyFile" accept=".pem" style="display:none" onchange="doLoadKey(this.files[0])">
+    <span id="keyStatus" class="status" data-i18n>Key auto-generated on seal</span>
+    <button class="lkb" onclick="showKeys()" data-i18n>Manage Keys</button>
+    <button class="lkb local-only" onclick="showSettings()" data-i18n>Settings</button>
+    <button class="lkb local-only" onclick="quitGUI()" data-i18n>Quit</button>
+    <button class="lkb" id="resumeBtn" onclick="showTab(cur)" style="display:none"></button>
+    <span class="account" id="account"></span>
+  </div>
+</div>
+
+<div id="checkScreen">
+  <div class="check-head"><h2 data-i18n>Verify a Container</h2><button class="lkb" onclick="closeCheck()" data-i18n>← Back</button></div>
+  <div class="check-drop" id="checkDrop" onclick="document.getElementById('checkInput').click()">
+    <span data-i18n>Drop the .imf here, with the sender's public key (.pem) and anchor proof (.ots) if you have them, or click to choose</span>
+    <input type="file" id="checkInput" multiple accept=".imf,.pem,.pub,.ots" onchange="checkAdd(this.files);this.value=''">
+  </div>
+  <div class="check-slots" id="checkSlots"></div>
+  <div class="check-result" id="checkResult"></div>
+</div>
+
+<div id="stopped">
+  <h2 data-i18n>IMF has stopped</h2>
+  <p data-i18n>Extracted files were removed and the loaded key was wiped. You can close this tab.</p>
+</div>
+
+<div class="modal-overlay" id="keyModal">
+  <div class="modal key-modal">
+    <h2 data-i18n>Keys</h2>
+    <h4 data-i18n>Loaded Key</h4>
+    <div id="kmActive"></div>
+    <h4 class="local-only" data-i18n>Keyring</h4>
+    <div class="km-list local-only" id="kmList"></div>
+    <div class="km-actions">
+      <button class="lkb" onclick="doKeygen()" data-i18n>Generate Key</button>
+      <button class="lkb" onclick="document.getElementById('keyFile').click()" data-i18n>Import Existing Key</button>
+      <button class="lkb local-only" onclick="document.getElementById('keyringFile').click()" data-i18n>Import to Keyring</button>
+      <button class="lkb local-only" onclick="doLoadHSM()" data-i18n>Use HSM Key</button>
+    </div>
+    <input type="file" id="keyFile" accept=".pem,.pub" style="display:none" onchange="doLoadKey(this.files[0]);this.value=''">
+    <input type="file" id="keyringFile" accept=".pem,.pub" style="display:none" onchange="doImportKey(this.files[0]);this.value=''">
+    <div class="modal-btns">
+      <button class="btn btn-secondary" onclick="hideModal('keyModal')" data-i18n>Close</button>
+    </div>
   </div>
 </div>
 
 <div class="modal-overlay" id="createModal">
   <div class="modal">
-    <h2>Create New Container</h2>
-    <label>Container Name</label>
-    <input type="text" id="createName" placeholder="my-archive">
+    <h2 data-i18n>Create New Container</h2>
+    <label data-i18n>Container Name</label>
+    <input type="text" id="createName" placeholder="my-archive" data-i18n-placeholder>
+    <div id="createTplRow">
+      <label data-i18n>Template</label>
+      <select id="createTpl" onchange="applyTpl()"></select>
+      <div class="tpl-about" id="tplAbout"></div>
+    </div>
+    <div class="md-fields" data-prefix="create"></div>
     <div class="modal-btns">
-      <button class="btn btn-secondary" onclick="hideModal('createModal')">Cancel</button>
-      <button class="btn btn-primary" onclick="doCreate()">Create</button>
+      <button class="btn btn-secondary" onclick="hideModal('createModal')" data-i18n>Cancel</button>
+      <button class="btn btn-primary" onclick="doCreate()" data-i18n>Create</button>
+    </div>
+  </div>
+</div>
+
+<div class="modal-overlay" id="metaModal">
+  <div class="modal">
+    <h2 data-i18n>Container Details</h2>
+    <p style="font-size:13px;color:var(--text-dim);margin-bottom:20px" data-i18n>Details are signed with the files when the container is sealed, and cannot be changed after.</p>
+    <div class="md-fields" data-prefix="md"></div>
+    <div class="modal-btns">
+      <button class="btn btn-secondary" onclick="hideModal('metaModal')" data-i18n>Cancel</button>
+      <button class="btn btn-primary" onclick="saveMD()" data-i18n>Save</button>
     </div>
   </div>
 </div>
 
 <div class="modal-overlay" id="sealModal">
   <div class="modal">
-    <h2>Seal Container</h2>
-    <p style="font-size:13px;color:var(--text-dim);margin-bottom:20px">Once sealed, no files can be added or modified. This is permanent.</p>
-    <div style="font-size:12px;color:var(--text-faint);margin:8px 0">Public key is always embedded for self-verification.</div>
-    <label>Encryption Passphrase (optional)</label>
-    <input type="password" id="sealPass" placeholder="Leave blank to skip encryption">
-    <label>Expiration Date (optional)</label>
+    <h2 data-i18n>Seal Container</h2>
+    <p style="font-size:13px;color:var(--text-dim);margin-bottom:20px" data-i18n>Once sealed, no files can be added or modified. This is permanent.</p>
+    <div style="font-size:12px;color:var(--text-faint);margin:8px 0" data-i18n>Public key is always embedded for self-verification.</div>
+    <div class="tpl-about" id="sealTpl" style="margin:0 0 16px"></div>
+    <label data-i18n>Encryption Passphrase (optional)</label>
+    <input type="password" id="sealPass" placeholder="Leave blank to skip encryption" data-i18n-placeholder oninput="passStrength()">
+    <div class="pw-meter" id="pwMeter"><div class="pw-bar"><span id="pwBar"></span></div><div class="pw-text" id="pwText"></div></div>
+    <label data-i18n>Expiration Date (optional)</label>
     <input type="date" id="sealExp">
+    <label data-i18n>Signing Key</label>
+    <select id="sealKey"></select>
+    <div class="modal-btns">
+      <button class="btn btn-secondary" onclick="hideModal('sealModal')" data-i18n>Cancel</button>
+      <button class="btn btn-primary" onclick="doSeal()" data-i18n>Seal Forever</button>
+    </div>
+  </div>
+</div>
+
+<div class="modal-overlay" id="copyModal">
+  <div class="modal">
+    <h2 data-i18n>Copy to Container</h2>
+    <p style="font-size:13px;color:var(--text-dim);margin-bottom:20px;word-break:break-all" id="copyWhat"></p>
+    <label data-i18n>Destination</label>
+    <select id="copyDst"></select>
+    <div id="copyPassRow">
+      <label data-i18n>Decryption passphrase</label>
+      <input type="password" id="copyPass">
+    </div>
+    <div class="modal-btns">
+      <button class="btn btn-secondary" onclick="hideModal('copyModal')" data-i18n>Cancel</button>
+      <button class="btn btn-primary" onclick="doCopy()" data-i18n>Copy</button>
+    </div>
+  </div>
+</div>
+
+<div class="modal-overlay" id="settingsModal">
+  <div class="modal settings-modal">
+    <h2 data-i18n>Settings</h2>
+    <label data-i18n>Working Directory</label>
+    <input type="text" id="setDir">
+    <div class="set-note" id="setDirNote"></div>
+    <label data-i18n>KDF Iterations</label>
+    <input type="number" id="setKDF" min="0" step="1000">
+    <div class="set-note" id="setKDFNote"></div>
+    <label data-i18n>Calendar Servers</label>
+    <textarea id="setCal" rows="3"></textarea>
+    <div class="set-note" id="setCalNote"></div>
+    <label data-i18n>Port</label>
+    <input type="number" id="setPort" min="0" max="65535">
+    <div class="set-note" id="setPortNote"></div>
+    <label data-i18n>Upload Limit (MiB)</label>
+    <input type="number" id="setMaxUp" min="0" step="1024">
+    <div class="set-note" id="setMaxUpNote"></div>
+    <label data-i18n>Language</label>
+    <select id="setLang"></select>
+    <div class="set-note" id="setLangNote"></div>
+    <label class="seal-check"><input type="checkbox" id="setPreview"> <span data-i18n>Extract sealed containers to preview their files</span></label>
+    <div class="set-note" id="setFile"></div>
     <div class="modal-btns">
-      <button class="btn btn-secondary" onclick="hideModal('sealModal')">Cancel</button>
-      <button class="btn btn-primary" onclick="doSeal()">Seal Forever</button>
+      <button class="btn btn-secondary" onclick="hideModal('settingsModal')" data-i18n>Cancel</button>
+      <button class="btn btn-primary" onclick="saveSettings()" data-i18n>Save</button>
+    </div>
+  </div>
+</div>
+
+<div class="modal-overlay" id="reportModal">
+  <div class="modal report-modal">
+    <h2 data-i18n>Verification Report</h2>
+    <div id="rpBody"></div>
+    <div class="modal-btns">
+      <button class="btn btn-secondary" onclick="exportReport()" data-i18n>Export JSON</button>
+      <button class="btn btn-secondary" onclick="printReport()" data-i18n>Save as PDF</button>
+      <button class="btn btn-primary" onclick="hideModal('reportModal')" data-i18n>Close</button>
+    </div>
+  </div>
+</div>
+
+<div class="modal-overlay" id="diffModal">
+  <div class="modal report-modal">
+    <h2 data-i18n>Compare Containers</h2>
+    <div class="diff-bar">
+      <span data-i18n>Original:</span>
+      <select id="diffOrig" onchange="runDiff()"></select>
+      <label class="seal-check"><input type="checkbox" id="diffAll" onchange="renderDiff()"> <span data-i18n>Show unchanged files</span></label>
+    </div>
+    <div class="diff-sum" id="diffSum"></div>
+    <div class="rp-wrap" id="diffBody"></div>
+    <div class="modal-btns">
+      <button class="btn btn-primary" onclick="hideModal('diffModal')" data-i18n>Close</button>
+    </div>
+  </div>
+</div>
+
+<div class="modal-overlay" id="qrModal">
+  <div class="modal qr-modal">
+    <h2 data-i18n>Container Hash</h2>
+    <img id="qrImg" alt="">
+    <div class="hash-box" id="qrText"></div>
+    <p style="font-size:12px;color:var(--text-dim);margin-top:12px" data-i18n>The recipient can scan this code, or read the hash, and compare it with the SHA-256 of the file they received.</p>
+    <div class="modal-btns">
+      <button class="btn btn-primary" onclick="hideModal('qrModal')" data-i18n>Close</button>
     </div>
   </div>
 </div>
@@ -176,29 +524,35 @@ body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sa
   <div class="titlebar">
     <div class="titlebar-left">
       <button class="back-btn" onclick="goHome()">&#8592;</button>
+      <button class="back-btn sb-toggle" onclick="toggleSB()" data-i18n>Details</button>
       <span class="container-name" id="wsName"></span>
       <span class="state-badge" id="wsBadge"></span>
     </div>
     <div class="titlebar-actions" id="wsActions"></div>
   </div>
+  <div class="tabbar" id="tabBar"></div>
   <div id="locBar" style="padding:4px 20px;background:var(--bg);border-bottom:1px solid var(--border);font-size:11px;color:var(--text-faint);display:none">
-    &#128193; Saved at: <span id="locPath"></span>
+    &#128193; <span data-i18n>Saved at:</span> <span id="locPath"></span>
   </div>
+  <div class="exp-banner" id="expBanner"></div>
   <div class="workspace-body">
+    <div class="sb-scrim" onclick="toggleSB(false)"></div>
     <div class="sidebar">
       <div class="sidebar-section" id="sMeta"></div>
+      <div class="sidebar-section" id="sDetails"></div>
       <div class="sidebar-section" id="sVerify"></div>
+      <div class="sidebar-section" id="sHash"></div>
       <div class="sidebar-section" id="sCrypto"></div>
       <div class="sidebar-section" id="sAnchor"></div>
     </div>
     <div class="file-area" id="fileArea">
       <div class="file-toolbar" id="fileTB"></div>
-      <div class="file-list-header" id="flHead"><div></div><div>Name</div><div>Size</div><div>Type</div><div></div></div>
+      <div class="file-list-header" id="flHead"><div></div><div data-i18n>Name</div><div data-i18n>Size</div><div data-i18n>Type</div><div></div></div>
       <div class="file-scroll" id="fileScroll"></div>
-      <div class="drop-overlay" id="dropOverlay">Drop files to add</div>
+      <div class="drop-overlay" id="dropOverlay" data-i18n>Drop files or folders to add</div>
     </div>
     <div class="preview-pane" id="pvPane">
-      <div class="preview-top"><div class="preview-thumb" id="pvThumb"></div><div class="pv-name" id="pvName"></div></div>
+      <div class="preview-top"><button class="tb pv-close" onclick="closePV()" data-i18n>Close</button><div class="preview-thumb" id="pvThumb"></div><div class="pv-name" id="pvName"></div></div>
       <div class="pv-meta" id="pvMeta"></div>
       <div class="pv-actions" id="pvAct"></div>
     </div>
@@ -207,6 +561,80 @@ body{font-family:'SF Pro Display',-apple-system,BlinkMacSystemFont,'Segoe UI',sa
 
 <script>
 let cName='',cState='',cInfo=null,files=[],selIdx=-1;
+// Open containers: each tab in tabs keeps one container's state, and the
+// variables above are the active tab's, which saveTab() writes back before
+// another tab is shown.
+let tabs=[],cur=-1;
+
+// Session token: the server rejects API calls without it. fetch() sends it
+// as a header; links, downloads and previews carry it in the query (au()).
+const apiToken='{{API_TOKEN}}';
+// account is the signed-in user when the GUI serves a team from a shared
+// server, which keeps its settings and keyring to itself; null otherwise.
+const account={{ACCOUNT}};
+// appWindow is true in the desktop app, which cannot show the code that
+// confirms a private key export: the export is left to imf key export.
+const appWindow={{APP_WINDOW}};
+const _fetch=window.fetch;
+window.fetch=(u,o={})=>{o.headers=Object.assign({'X-IMF-Token':apiToken},o.headers);return _fetch(u,o)};
+function au(u){return u+(u.includes('?')?'&':'?')+'token='+apiToken}
+
+// Messages: the catalog for the user's language, from /api/messages. The
+// English text is the key; t() formats %s and %d (or %[n]s) as Go does.
+let msgs={};
+function t(s,...a){let i=0;return(msgs[s]||s).replace(/%(?:\[(\d+)\])?[sd]/g,(m,n)=>{if(n)i=+n-1;return String(a[i++])})}
+// The English text is kept in the data attributes, so that the page can be
+// translated again when the language is changed in the settings.
+async function loadMessages(){
+  try{
+    const r=await(await fetch('/api/messages')).json();
+    if(!r.success)return;
+    msgs=r.data||{};document.documentElement.lang=r.message;
+  }catch(e){return}
+  document.title='IMF — '+t('Immutable File Container');
+  document.querySelectorAll('[data-i18n]').forEach(e=>{e.dataset.i18n=e.dataset.i18n||e.textContent.trim();e.textContent=t(e.dataset.i18n)});
+  document.querySelectorAll('[data-i18n-placeholder]').forEach(e=>{e.dataset.i18nPlaceholder=e.dataset.i18nPlaceholder||e.placeholder;e.placeholder=t(e.dataset.i18nPlaceholder)});
+}
+mdFields();
+const msgsReady=loadMessages();
+if(account){
+  document.body.classList.add('shared');
+  msgsReady.then(()=>{document.getElementById('account').innerHTML=esc(t('Signed in as %s',account.user))+
+    (account.logout?'<a href="/auth/logout">'+t('Sign out')+'</a>':'')});
+}
+
+// Settings: those in effect, from /api/settings; prefs.preview says whether
+// sealed containers are extracted so their files can be previewed.
+let prefs={preview:true};
+const prefsReady=fetch('/api/settings').then(r=>r.json()).then(r=>{if(r.success)prefs=r.data.current}).catch(()=>{});
+// Templates: a container made from one starts with the details it fills
+// in, and is sealed with its expiry and encryption. They are fetched again
+// each time the create dialog opens, to pick up ones just added.
+let templates=[];
+function loadTemplates(){return fetch('/api/templates').then(r=>r.json()).then(r=>{if(r.success)templates=r.data}).catch(()=>{})}
+loadTemplates();
+function tplFor(id){return id?templates.find(x=>x.id===id):undefined}
+
+// Progress: the server streams the progress of a seal, extract or anchor
+// over /ws; the bar shows while one runs and hides when it finishes.
+function openProgress(){
+  const ws=new WebSocket((location.protocol==='https:'?'wss://':'ws://')+location.host+au('/ws'));
+  ws.onmessage=e=>showProgress(JSON.parse(e.data));
+  ws.onclose=()=>{if(!guiStopped)setTimeout(openProgress,2000)};
+}
+function showProgress(p){
+  const e=document.getElementById('progress');
+  if(p.finished){e.classList.remove('active');return}
+  document.getElementById('progLabel').textContent=t(p.label||p.stage);
+  document.getElementById('progBar').style.width=p.percent.toFixed(1)+'%';
+  document.getElementById('progText').textContent=p.stage==='derive key'?Math.round(p.percent)+'%':
+    (p.stage==='read'||p.stage==='calendars')?p.done+'/'+p.total:fmtS(p.done)+' / '+fmtS(p.total);
+  e.classList.add('active');
+}
+openProgress();
+
+msgsReady.then(refreshKeys);
+msgsReady.then(refreshRecent);
 
 // Launch
 async function handleOpen(file){
@@ -215,207 +643,808 @@ async function handleOpen(file){
   // Upload container to server
   const f2=new FormData();f2.append('container_file',file);
   await fetch('/api/upload-container',{method:'POST',body:f2});
-  // Get info
-  const f3=new FormData();f3.append('container',file.name);
-  const r=await(await fetch('/api/info',{method:'POST',body:f3})).json();
-  if(!r.success){toast(r.error,'error');return}
-  cName=file.name;cInfo=r.data;cState=cInfo.State;
-  // If sealed, extract for preview
-  if(cState==='sealed'){
-    const ef=new FormData();ef.append('container',cName);ef.append('passphrase','');ef.append('ignore_expiry','true');
-    await fetch('/api/extract',{method:'POST',body:ef});
+  await openContainer(file.name);
+}
+// openContainer opens container name from the working directory in a tab,
+// extracting a sealed one for preview unless the settings say not to. An
+// expired one is left unextracted until the user chooses to open it anyway.
+async function openContainer(name){
+  const r=await pf('/api/info',{container:name});
+  if(!r.success){toast(t('Could not open %s: %s',name,r.error),'error');return}
+  let extracted=false;
+  await prefsReady;
+  if(r.data.State==='sealed'&&prefs.preview&&!r.data.Encrypted&&!r.data.Expired){
+    const er=await pf('/api/extract',{container:name,passphrase:''});
+    extracted=er.success;
   }
-  enterWS();
+  await openTab(name,r.data,extracted);
+}
+
+// Recent containers: the working directory's history, newest first, with
+// the state and verification result each had when last seen
+async function refreshRecent(){
+  let list=[];
+  try{const r=await(await fetch('/api/recent')).json();if(r.success)list=r.data}catch(e){}
+  document.getElementById('recent').classList.toggle('active',list.length>0);
+  document.getElementById('recentList').innerHTML=list.map(x=>
+    '<div class="rrow'+(x.missing?' missing':'')+'" data-name="'+esc(x.name)+'" title="'+esc(x.path)+'"'+(x.missing?'':' onclick="openContainer(this.dataset.name)"')+'>'+
+      '<span class="tdot '+esc(x.state)+'"></span>'+
+      '<div class="rmain"><div class="rname">'+esc(x.name)+(x.missing?' <span class="km-tag">'+t('(missing)')+'</span>':'')+'</div>'+
+      '<div class="rpath">'+esc(x.path)+'</div></div>'+
+      (x.verify==='pass'?'<span class="rverify pass">&#10003; '+t('Verified')+'</span>':
+        x.verify==='fail'?'<span class="rverify fail" title="'+esc(x.verify_note)+'">&#10007; '+t('Verification failed')+'</span>':'')+
+      '<span class="rwhen">'+new Date(x.used_at).toLocaleDateString()+'</span>'+
+      '<button class="tclose" title="'+t('Remove from list')+'" onclick="event.stopPropagation();forgetRecent(this.parentNode.dataset.name)">&times;</button>'+
+    '</div>').join('');
+}
+async function forgetRecent(name){
+  const r=await pf('/api/recent',{remove:name});
+  if(!r.success)toast(r.error,'error');
+  refreshRecent();
+}
+
+// Quit stops the GUI's server through /api/shutdown, which wipes the keys
+// and removes the extracted files as Ctrl+C in its terminal would.
+let guiStopped=false;
+async function quitGUI(){
+  if(!confirm(t('Quit IMF? Extracted files will be removed and the loaded key wiped.')))return;
+  const r=await pf('/api/shutdown',{}).catch(()=>({success:false,error:t('The GUI is not responding')}));
+  if(!r.success){toast(r.error,'error');return}
+  guiStopped=true;
+  document.getElementById('stopped').classList.add('active');
+  window.close();
 }
 
 function showModal(id){document.getElementById(id).classList.add('active')}
 function hideModal(id){document.getElementById(id).classList.remove('active')}
 
+async function openCreate(){
+  await loadTemplates();
+  const s=document.getElementById('createTpl'),id=s.value;
+  s.innerHTML='<option value="">'+t('None')+'</option>'+templates.map(x=>'<option value="'+esc(x.id)+'">'+esc(t(x.name))+'</option>').join('');
+  s.value=tplFor(id)?id:'';
+  document.getElementById('createTplRow').style.display=templates.length?'':'none';
+  if(s.value!==id)applyTpl();
+  showModal('createModal');
+}
+// applyTpl fills the create dialog's details from the chosen template and
+// marks those it requires.
+const mdLabels={Title:['Title (optional)','Title (required)'],Case:['Case Number (optional)','Case Number (required)'],
+  Tags:['Tags (optional, separated by commas)','Tags (required, separated by commas)'],Desc:['Description (optional)','Description (required)']};
+function applyTpl(){
+  const x=tplFor(document.getElementById('createTpl').value),req=x&&x.required||[];
+  document.getElementById('tplAbout').textContent=x&&x.description?t(x.description):'';
+  mdFill('create',x?x.metadata:{});
+  for(const[k,id]of Object.entries({title:'Title',case_number:'Case',tags:'Tags',description:'Desc'})){
+    const e=document.getElementById('create'+id),l=e.previousElementSibling,r=req.includes(k);
+    e.classList.toggle('md-req',r);l.dataset.i18n=mdLabels[id][+r];l.textContent=t(l.dataset.i18n);
+  }
+}
 async function doCreate(){
   const name=document.getElementById('createName').value.trim()||'container';
-  const r=await pf('/api/create',{name});
+  const md=mdValues('create');
+  const r=await pf('/api/create',Object.assign({name,template:document.getElementById('createTpl').value},md));
   if(r.success){
-    cName=r.data.name;cState='open';
-    cInfo={State:'open',CreatedAt:new Date().toISOString(),FileCount:0,Encrypted:false,HasPubKey:false};
-    hideModal('createModal');enterWS();
+    hideModal('createModal');
+    document.getElementById('createTpl').value='';applyTpl();
+    await openTab(r.data.name,{State:'open',CreatedAt:new Date().toISOString(),FileCount:0,Encrypted:false,HasPubKey:false});
+    if(Object.values(md).some(v=>v.trim()))refreshTab(r.data.name);
   }else toast(r.error,'error');
 }
 
+// Container details: a title, case number, tags, and description, which
+// can be edited until the container is sealed and are signed with it. The
+// create and edit dialogs share their fields, laid out by mdFields.
+function mdFields(){
+  document.querySelectorAll('.md-fields').forEach(d=>{
+    const p=d.dataset.prefix;
+    d.innerHTML='<label data-i18n>Title (optional)</label><input type="text" id="'+p+'Title">'+
+      '<label data-i18n>Case Number (optional)</label><input type="text" id="'+p+'Case">'+
+      '<label data-i18n>Tags (optional, separated by commas)</label><input type="text" id="'+p+'Tags">'+
+      '<label data-i18n>Description (optional)</label><textarea class="md-desc-in" id="'+p+'Desc" rows="3"></textarea>';
+  });
+}
+function mdValues(p){
+  const v=id=>document.getElementById(p+id).value;
+  return{title:v('Title'),case_number:v('Case'),tags:v('Tags'),description:v('Desc')};
+}
+function mdFill(p,md){
+  document.getElementById(p+'Title').value=md.title||'';
+  document.getElementById(p+'Case').value=md.case_number||'';
+  document.getElementById(p+'Tags').value=(md.tags||[]).join(', ');
+  document.getElementById(p+'Desc').value=md.description||'';
+}
+function renderMD(){
+  const md=cInfo.Metadata||{};
+  const tpl=tplFor(md.template);
+  const rows=(md.title?mr(t('Title'),esc(md.title)):'')+(md.case_number?mr(t('Case Number'),esc(md.case_number)):'')+
+    (md.template?mr(t('Template'),esc(tpl?t(tpl.name):md.template)):'')+
+    (md.tags&&md.tags.length?'<div class="md-tags">'+md.tags.map(x=>'<span>'+esc(x)+'</span>').join('')+'</div>':'')+
+    (md.description?'<div class="md-desc">'+esc(md.description)+'</div>':'');
+  document.getElementById('sDetails').innerHTML='<h4>'+t('Details')+'</h4>'+(rows||'<div class="md-none">'+t(cInfo.Hidden?'Hidden with the file list':'None')+'</div>')+
+    (cState==='open'?'<button class="rp-link" onclick="editMD()">'+t('Edit details')+'</button>':
+      rows?'<div class="md-sig" id="mdSig">'+t('Checking signature...')+'</div>':'');
+}
+function editMD(){mdFill('md',cInfo.Metadata||{});showModal('metaModal')}
+async function saveMD(){
+  const name=cName,r=await pf('/api/metadata',Object.assign({container:name},mdValues('md')));
+  if(!r.success){toast(r.error,'error');return}
+  hideModal('metaModal');toast(t('Details saved'),'success');
+  refreshTab(name);
+}
+
 async function doKeygen(){
   const r=await pf('/api/keygen',{});
-  if(r.success){toast('Key pair generated','success');setKey(true,'Key ready');document.getElementById('exportBtn').style.display='';}
+  if(r.success){toast(t('Key pair generated'),'success');refreshKeys()}
   else toast(r.error,'error');
 }
-async function doLoadKey(file){
+async function doLoadKey(file,pass){
   if(!file)return;
-  const f=new FormData();f.append('key',file);
-  const r=await(await fetch('/api/load-key',{method:'POST',body:f})).json();
-  if(r.success){toast(r.message,'success');setKey(true,r.message);document.getElementById('exportBtn').style.display='';}
+  const f=new FormData();f.append('key',file);if(pass)f.append('passphrase',pass);
+  const res=await fetch('/api/load-key',{method:'POST',body:f});const r=await res.json();
+  if(res.status===401){
+    if(pass)toast(r.error,'error');
+    const p=prompt(t('Passphrase for %s:',file.name));
+    if(p)doLoadKey(file,p);
+    return;
+  }
+  if(r.success){toast(r.message,'success');refreshKeys()}
+  else toast(r.error,'error');
+}
+async function doImportKey(file,name,pass){
+  if(!file)return;
+  if(!name)name=prompt(t('Name for this key in the keyring:'),file.name.replace(/\.[^.]*$/,''));
+  if(!name)return;
+  const f=new FormData();f.append('key',file);f.append('name',name);if(pass)f.append('passphrase',pass);
+  const res=await fetch('/api/import-key',{method:'POST',body:f});const r=await res.json();
+  if(res.status===401){
+    if(pass)toast(r.error,'error');
+    const p=prompt(t('Passphrase for %s:',file.name));
+    if(p)doImportKey(file,name,p);
+    return;
+  }
+  if(r.success){toast(r.message,'success');refreshKeys()}
+  else toast(r.error,'error');
+}
+async function doLoadHSM(){
+  const label=prompt(t('HSM key label (leave empty if the token holds one key):'));
+  if(label===null)return;
+  const r=await pf('/api/load-pkcs11',{label});
+  if(r.success){toast(r.message,'success');refreshKeys()}
+  else toast(r.error,'error');
+}
+async function doUseKey(name,pass){
+  const d={name};if(pass)d.passphrase=pass;
+  const f=new FormData();for(const[k,v]of Object.entries(d))f.append(k,v);
+  const res=await fetch('/api/use-key',{method:'POST',body:f});const r=await res.json();
+  if(res.status===401){
+    if(pass)toast(r.error,'error');
+    const p=prompt(t('Passphrase for %s:',name));
+    if(p)doUseKey(name,p);
+    return;
+  }
+  if(r.success){toast(r.message,'success');refreshKeys()}
+  else toast(r.error,'error');
+}
+// A key loaded from a protected file is saved as that file; any other is
+// protected with a passphrase the user gives, or saved unprotected.
+async function doSaveKey(){
+  const name=prompt(t('Name for this key in the keyring:'));
+  if(!name)return;
+  const d={name};
+  if(!keyState.protected){
+    const pp=prompt(t('Passphrase to protect the key in the keyring (leave empty to store it unprotected):'));
+    if(pp===null)return;
+    if(pp){
+      if(prompt(t('Repeat the passphrase:'))!==pp){toast(t('The passphrases do not match'),'error');return}
+      d.passphrase=pp;
+    }
+  }
+  const r=await pf('/api/save-key',d);
+  if(r.success){toast(r.message,'success');refreshKeys()}
   else toast(r.error,'error');
 }
 function setKey(ok,txt){const e=document.getElementById('keyStatus');e.textContent=txt;e.className='status'+(ok?' loaded':'')}
 
+// Key manager: the session's key and the keyring's keys, kept in keyState
+// and keyList for the manager and the seal dialog's key picker.
+let keyState={},keyList=[];
+function esc(s){return String(s).replace(/&/g,'&amp;').replace(/</g,'&lt;').replace(/>/g,'&gt;').replace(/"/g,'&quot;').replace(/'/g,'&#39;')}
+async function refreshKeys(){
+  try{
+    const ks=await(await fetch('/api/key-status')).json();
+    if(ks.success)keyState=ks.data;
+    const kr=await(await fetch('/api/keys')).json();
+    keyList=kr.success?kr.data.keys:[];
+  }catch(e){return}
+  if(keyState.expired&&!keyState.loaded)setKey(false,t('Key cleared after inactivity'));
+  else if(!keyState.loaded)setKey(false,t('Key auto-generated on seal'));
+  else setKey(true,(keyState.name?t('Key %s',keyState.name):t('Key ready'))+' · '+keyState.fingerprint.slice(0,16));
+  renderKeys();
+}
+function renderKeys(){
+  const k=keyState;
+  document.getElementById('kmActive').innerHTML=!k.loaded?'<div class="km-empty">'+t('No key loaded — one is generated when you seal')+'</div>':
+    '<div class="km-row"><div><div class="km-name">'+esc(k.name||t('Session key'))+(k.signing?'':' <span class="km-tag">'+t('verify only')+'</span>')+'</div>'+
+    '<div class="km-fp">'+k.fingerprint+'</div></div><div class="km-btns">'+
+      (!account?'<button class="fa-btn" onclick="exportKey()">'+t('Export Public Key')+'</button>':'')+
+      (k.memory&&!account?'<button class="fa-btn" onclick="exportPrivateKey()"'+
+        (appWindow?' disabled title="'+esc(t('The desktop app cannot show the code confirming the export. Save the key to the keyring and run imf key export -private.'))+'"':'')+
+        '>'+t('Export Private Key')+'</button>':'')+
+      (k.memory&&!k.name&&!account?'<button class="fa-btn" onclick="doSaveKey()">'+t('Save to Keyring')+'</button>':'')+
+    '</div></div>';
+  document.getElementById('kmList').innerHTML=!keyList.length?'<div class="km-empty">'+t('The keyring is empty')+'</div>':
+    keyList.map(x=>'<div class="km-row'+(x.name===k.name?' active':'')+'"><div><div class="km-name">'+esc(x.name)+
+      (x.private?'':' <span class="km-tag">'+t('(public only)')+'</span>')+'</div><div class="km-fp">'+x.fingerprint+'</div></div>'+
+      '<div class="km-btns"><button class="fa-btn" data-key="'+esc(x.name)+'" onclick="doUseKey(this.dataset.key)">'+t('Use')+'</button>'+
+      '<button class="fa-btn" data-key="'+esc(x.name)+'" onclick="exportKey(this.dataset.key)">'+t('Export Public Key')+'</button></div></div>').join('');
+}
+function showKeys(){refreshKeys();showModal('keyModal')}
+
+// Settings page: the config file's settings, with what is in effect for
+// those left unset and a note on those the environment overrides
+let settingsData=null;
+async function showSettings(){
+  const r=await(await fetch('/api/settings')).json();
+  if(!r.success){toast(r.error,'error');return}
+  fillSettings(r.data);showModal('settingsModal');
+}
+function fillSettings(d){
+  settingsData=d;prefs=d.current;
+  const sv=d.saved,cu=d.current;
+  const note=(id,now,key)=>{document.getElementById(id).innerHTML=(now?esc(now):'')+
+    (d.env[key]?' <span class="env">'+esc(t('Set by %s, which takes precedence',d.env[key]))+'</span>':'')};
+  document.getElementById('setDir').value=sv.dir||'';
+  document.getElementById('setDir').placeholder=cu.dir;
+  note('setDirNote',t('Now: %s',cu.dir),'dir');
+  document.getElementById('setKDF').value=sv.kdf_iterations||'';
+  document.getElementById('setKDF').placeholder=cu.kdf_iterations;
+  note('setKDFNote',t('PBKDF2 iterations for new encrypted containers'),'kdf_iterations');
+  document.getElementById('setCal').value=(sv.calendars||[]).join('\n');
+  document.getElementById('setCal').placeholder=cu.calendars.join('\n');
+  note('setCalNote',t('One URL per line; leave empty for the public calendars'),'calendars');
+  document.getElementById('setPort').value=sv.port||'';
+  document.getElementById('setPort').placeholder=t('Any free port');
+  note('setPortNote',t('Now: %s. A new port applies when the GUI next starts.',cu.port),'port');
+  document.getElementById('setMaxUp').value=sv.max_upload_mb||'';
+  document.getElementById('setMaxUp').placeholder=cu.max_upload_mb;
+  note('setMaxUpNote',t('The largest file that can be added, in MiB'),'max_upload_mb');
+  document.getElementById('setLang').innerHTML='<option value="">'+esc(t('Browser language'))+'</option>'+
+    d.languages.map(l=>'<option value="'+esc(l)+'">'+esc(l)+'</option>').join('');
+  document.getElementById('setLang').value=sv.lang||'';
+  note('setLangNote','','lang');
+  document.getElementById('setPreview').checked=sv.preview;
+  document.getElementById('setFile').textContent=t('Saved in %s',d.file);
+}
+async function saveSettings(){
+  const old=settingsData;
+  const r=await pf('/api/settings',{
+    dir:document.getElementById('setDir').value,
+    kdf_iterations:document.getElementById('setKDF').value,
+    calendars:document.getElementById('setCal').value,
+    port:document.getElementById('setPort').value,
+    max_upload_mb:document.getElementById('setMaxUp').value,
+    lang:document.getElementById('setLang').value,
+    preview:document.getElementById('setPreview').checked?'true':'false'
+  });
+  if(!r.success){toast(r.error,'error');return}
+  hideModal('settingsModal');fillSettings(r.data);
+  if(r.data.saved.lang!==old.saved.lang)await loadMessages();
+  toast(t(r.message),'success');
+  if(r.data.current.dir!==old.current.dir){
+    // The open containers are in the old working directory.
+    wdPath=r.data.current.dir;tabs=[];cur=-1;loadTab(-1);goHome();
+  }else refreshRecent();
+  refreshKeys();
+}
+
 // Workspace
+let wdPath='';
 async function enterWS(){
   document.getElementById('launchScreen').style.display='none';
   document.getElementById('workspace').classList.add('active');
   // Show the file location bar
-  try{
+  if(!wdPath)try{
     const wd=await(await fetch('/api/workdir')).json();
-    if(wd.success){
-      document.getElementById('locPath').textContent=wd.data.path+'/'+cName;
-      document.getElementById('locBar').style.display='';
-    }
+    if(wd.success)wdPath=wd.data.path;
   }catch(e){}
-  renderWS();await refreshFiles();
-  if(cState==='sealed')autoVerify();
+  document.getElementById('pvPane').classList.remove('active');
+  const name=cName,sealed=cState==='sealed';
+  renderWS();await refreshFiles(name);
+  if(sealed)autoVerify(name);
 }
 function goHome(){
+  saveTab();toggleSB(false);
   document.getElementById('workspace').classList.remove('active');
   document.getElementById('launchScreen').style.display='';
-  cName='';cState='';cInfo=null;files=[];selIdx=-1;
   document.getElementById('pvPane').classList.remove('active');
+  const b=document.getElementById('resumeBtn');
+  b.textContent=t(tabs.length===1?'%d open container':'%d open containers',tabs.length)+' →';
+  b.style.display=tabs.length?'':'none';
+  refreshRecent();
+}
+
+// Tabs
+function saveTab(){if(cur>=0)Object.assign(tabs[cur],{name:cName,state:cState,info:cInfo,files,sel:selIdx})}
+function loadTab(i){
+  cur=i;const x=tabs[i]||{};
+  cName=x.name||'';cState=x.state||'';cInfo=x.info||null;files=x.files||[];selIdx=x.sel??-1;
+}
+// openTab shows container name in a tab of its own, or reloads its tab if
+// it is already open.
+function openTab(name,info,extracted){
+  saveTab();
+  const x={name,state:info.State,info,files:[],sel:-1,verify:null,extracted:!!extracted,folded:{}};
+  const i=tabs.findIndex(x=>x.name===name);
+  if(i<0)tabs.push(x);else tabs[i]=x;
+  loadTab(i<0?tabs.length-1:i);
+  return enterWS();
+}
+// showTab switches to tab i as it was left, without asking the server again.
+function showTab(i){
+  if(i<0||i>=tabs.length)return;
+  saveTab();loadTab(i);
+  document.getElementById('launchScreen').style.display='none';
+  document.getElementById('workspace').classList.add('active');
+  renderWS();renderFL();
+  if(files[selIdx])showPV(files[selIdx]);else document.getElementById('pvPane').classList.remove('active');
+}
+function closeTab(i){
+  saveTab();
+  const next=i===cur?Math.min(i,tabs.length-2):cur-(i<cur?1:0);
+  tabs.splice(i,1);cur=-1;
+  if(next<0){loadTab(-1);goHome();return}
+  showTab(next);
+}
+function renderTabs(){
+  document.getElementById('tabBar').innerHTML=tabs.map((x,i)=>
+    '<div class="tab'+(i===cur?' active':'')+'" title="'+esc(x.name)+'" onclick="showTab('+i+')">'+
+      '<span class="tdot '+(i===cur?cState:x.state)+'"></span><span class="tname">'+esc(x.name)+'</span>'+
+      '<button class="tclose" title="'+t('Close')+'" onclick="event.stopPropagation();closeTab('+i+')">&times;</button></div>').join('')+
+    '<button class="tab-new" title="'+t('Open another container')+'" onclick="goHome()">+</button>';
+}
+function isCur(name){return cur>=0&&name===cName}
+// setTab records the answer to a request made for container name's tab.
+// The user may have switched tabs, or closed this one, while it ran, so it
+// reports whether the tab is still the active one and should be redrawn.
+function setTab(name,d){
+  const x=isCur(name)?tabs[cur]:tabs.find(x=>x.name===name);
+  if(!x)return false;
+  Object.assign(x,d);
+  if(!isCur(name)){renderTabs();return false}
+  if('state'in d)cState=d.state;
+  if('info'in d)cInfo=d.info;
+  if('files'in d)files=d.files;
+  return true;
+}
+// refreshTab reloads a tab's container details and file list.
+async function refreshTab(name){
+  const ir=await pf('/api/info',{container:name});
+  if(ir.success&&setTab(name,{info:ir.data,hash:null}))renderSB();
+  await refreshFiles(name);
 }
 
 function renderWS(){
+  renderTabs();
   document.getElementById('wsName').textContent=cName;
+  document.getElementById('locPath').textContent=wdPath+'/'+cName;
+  document.getElementById('locBar').style.display=wdPath?'':'none';
   const b=document.getElementById('wsBadge');b.textContent=cState;b.className='state-badge '+cState;
   const a=document.getElementById('wsActions');
   if(cState==='open'){
-    a.innerHTML='<button class="tb" onclick="document.getElementById(\'addIn\').click()">+ Add Files</button>'+
-      '<button class="tb primary" onclick="showModal(\'sealModal\')">Seal</button>'+
-      '<input type="file" id="addIn" multiple style="display:none" onchange="addF(this.files)">';
+    a.innerHTML='<button class="tb" onclick="document.getElementById(\'addIn\').click()">'+t('+ Add Files')+'</button>'+
+      '<button class="tb" onclick="document.getElementById(\'addDir\').click()">'+t('+ Add Folder')+'</button>'+
+      '<button class="tb" onclick="openDiff()">'+t('Compare…')+'</button>'+
+      '<button class="tb primary" onclick="openSeal()">'+t('Seal')+'</button>'+
+      '<input type="file" id="addIn" multiple style="display:none" onchange="addF(this.files);this.value=\'\'">'+
+      '<input type="file" id="addDir" webkitdirectory multiple style="display:none" onchange="addF(this.files);this.value=\'\'">';
   }else{
-    a.innerHTML='<a href="/api/download?file='+encodeURIComponent(cName)+'" class="tb">Download .imf</a>'+
-      '<button class="tb" onclick="anchorContainer()" style="background:var(--warning-bg);color:var(--warning);border-color:var(--warning)">&#9875; Anchor to Bitcoin</button>'+
-      '<button class="tb success" onclick="extractDL()">Extract All</button>';
+    a.innerHTML='<a href="'+au('/api/download?file='+encodeURIComponent(cName))+'" class="tb">'+t('Download .imf')+'</a>'+
+      '<button class="tb" onclick="anchorContainer()" style="background:var(--warning-bg);color:var(--warning);border-color:var(--warning)">&#9875; '+t('Anchor to Bitcoin')+'</button>'+
+      '<button class="tb" onclick="openDiff()">'+t('Compare…')+'</button>'+
+      '<button class="tb" onclick="extractTo()">'+t('Extract to Folder…')+'</button>'+
+      '<button class="tb success" onclick="extractDL()">'+t('Extract All')+'</button>';
   }
   renderSB();
   document.getElementById('fileTB').innerHTML='<div class="info" id="fCount"></div>'+
-    (cState==='sealed'?'<a href="/api/download-zip" class="tb success" style="font-size:11px;padding:5px 12px">Download All</a>':'');
+    (cState==='sealed'?'<button class="tb success" style="font-size:11px;padding:5px 12px" onclick="downloadAll()">'+t('Download All')+'</button>':'');
   if(cState==='open')setupDrop();
 }
 
 function renderSB(){
   const cr=cInfo.CreatedAt?new Date(cInfo.CreatedAt).toLocaleString():'—';
   const se=cInfo.SealedAt?new Date(cInfo.SealedAt).toLocaleString():'—';
-  let ex='None',ec='';
-  if(cInfo.ExpiresAt){ex=new Date(cInfo.ExpiresAt).toLocaleDateString();ec=cInfo.Expired?'bad':'good';if(cInfo.Expired)ex+=' (EXPIRED)'}
-  document.getElementById('sMeta').innerHTML='<h4>Container</h4>'+
-    mr('State',cState.toUpperCase(),cState==='sealed'?'good':'warn')+
-    mr('Created',cr)+(cState==='sealed'?mr('Sealed',se):'')+
-    mr('Expires',ex,ec)+mr('Files',cInfo.FileCount||0);
-  document.getElementById('sCrypto').innerHTML='<h4>Security</h4>'+
-    mr('Encrypted',cInfo.Encrypted?'Yes':'No',cInfo.Encrypted?'good':'')+
-    mr('Pub Key',cInfo.HasPubKey?'Embedded':'None',cInfo.HasPubKey?'good':'');
-  document.getElementById('sVerify').innerHTML='<h4>Integrity</h4>'+
-    '<div class="verify-status pending" id="vBadge">'+(cState==='sealed'?'Checking...':'Not yet sealed')+'</div>';
+  let ex=t('None'),ec='';
+  if(cInfo.ExpiresAt){
+    const left=new Date(cInfo.ExpiresAt)-Date.now();
+    ex=new Date(cInfo.ExpiresAt).toLocaleDateString();ec=isExpired()?'bad':left<expirySoon?'warn':'good';
+    if(isExpired())ex+=' ('+t('EXPIRED')+')';else if(left<expirySoon)ex+=' ('+t('in %s',untilText(left))+')';
+  }
+  document.getElementById('sMeta').innerHTML='<h4>'+t('Container')+'</h4>'+
+    mr(t('State'),t(cState).toUpperCase(),cState==='sealed'?'good':'warn')+
+    mr(t('Created'),cr)+(cState==='sealed'?mr(t('Sealed'),se):'')+
+    mr(t('Expires'),ex,ec)+mr(t('Files'),cInfo.FileCount||0);
+  renderMD();renderExpiry();
+  document.getElementById('sCrypto').innerHTML='<h4>'+t('Security')+'</h4>'+
+    mr(t('Encrypted'),t(cInfo.Encrypted?'Yes':'No'),cInfo.Encrypted?'good':'')+
+    mr(t('Pub Key'),t(cInfo.HasPubKey?'Embedded':'None'),cInfo.HasPubKey?'good':'')+
+    (cInfo.Signer?mr(t('Signer'),signerLabel(cInfo.Signer)):'');
+  document.getElementById('sVerify').innerHTML='<h4>'+t('Integrity')+'</h4>'+
+    '<div class="verify-status pending" id="vBadge">'+t(cState==='sealed'?'Checking...':'Not yet sealed')+'</div>';
+  showVerify();renderHash();
   // Show blockchain anchor section for sealed containers
   const aDiv=document.getElementById('sAnchor');
   if(cState==='sealed'){
-    aDiv.innerHTML='<h4>Blockchain Anchor</h4>'+
-      '<div style="font-size:12px;color:var(--text-dim)" id="anchorStatus">Checking...</div>';
+    aDiv.innerHTML='<h4>'+t('Blockchain Anchor')+'</h4>'+
+      '<div style="font-size:12px;color:var(--text-dim)" id="anchorStatus">'+t('Checking...')+'</div>';
     // Check if an .ots proof already exists for this container
     checkAnchorStatus();
   }else{aDiv.innerHTML='';}
 }
 
+// The hash section shows a sealed container's SHA-256, for the sender to
+// copy or show as a QR code and the recipient to check against the file they
+// were sent, and the hash its anchor proof commits to if that differs, as it
+// does once the proof is embedded. It is fetched once for each tab.
+async function renderHash(){
+  const d=document.getElementById('sHash'),name=cName;
+  if(cState!=='sealed'){d.innerHTML='';return}
+  let h=tabs[cur].hash;
+  if(!h){
+    d.innerHTML='<h4>'+t('Container Hash')+'</h4><div style="font-size:12px;color:var(--text-dim)">'+t('Checking...')+'</div>';
+    const r=await pf('/api/hash',{container:name});
+    if(!r.success||!setTab(name,{hash:r.data}))return;
+    h=r.data;
+  }
+  d.innerHTML='<h4>'+t('Container Hash')+'</h4><div class="hash-label">SHA-256</div>'+hashBox(h.sha256)+
+    (h.anchored&&h.anchored!==h.sha256?'<div class="hash-label">'+t('Anchored hash')+'</div>'+hashBox(h.anchored):'');
+}
+function hashBox(x){
+  return'<div class="hash-box">'+x+'</div><div class="hash-btns">'+
+    '<button class="tb" onclick="copyHash(\''+x+'\')">'+t('Copy')+'</button>'+
+    '<button class="tb" onclick="showQR(\''+x+'\')">'+t('QR Code')+'</button></div>';
+}
+async function copyHash(x){
+  try{await navigator.clipboard.writeText(x);toast(t('Hash copied'),'success')}
+  catch(e){showQR(x);getSelection().selectAllChildren(document.getElementById('qrText'))}
+}
+function showQR(x){
+  document.getElementById('qrImg').src=au('/api/qr?text='+x);
+  document.getElementById('qrText').textContent=x;
+  showModal('qrModal');
+}
+
+function signerLabel(s){
+  let who=(s.name||'')+(s.email?' <'+s.email+'>':'');
+  who=(who.trim()||t('Key'))+' '+s.fingerprint.slice(0,16);
+  return who.replace(/&/g,'&amp;').replace(/</g,'&lt;').replace(/>/g,'&gt;');
+}
 function mr(l,v,c){return'<div class="meta-row"><span class="label">'+l+'</span><span class="value'+(c?' '+c:'')+'">'+v+'</span></div>'}
 
 // Files
-async function refreshFiles(){
-  const f=new FormData();f.append('container',cName);
+async function refreshFiles(name=cName){
+  const f=new FormData();f.append('container',name);
   const r=await(await fetch('/api/list',{method:'POST',body:f})).json();
-  files=(r.success&&r.data)?r.data:[];
-  renderFL();
+  if(setTab(name,{files:(r.success&&r.data)?r.data:[]}))renderFL();
 }
 
 function renderFL(){
-  document.getElementById('fCount').textContent=files.length+' item'+(files.length!==1?'s':'');
+  document.getElementById('fCount').textContent=t(files.length===1?'%d item':'%d items',files.length);
   const s=document.getElementById('fileScroll');
   if(!files.length){
     document.getElementById('flHead').style.display='none';
     s.innerHTML='<div class="empty-state"><div class="icon">'+(cState==='open'?'&#128194;':'&#128274;')+'</div>'+
-      '<p>'+(cState==='open'?'No files yet':'Empty container')+'</p>'+
-      (cState==='open'?'<div class="hint">Drag and drop files here or click + Add Files</div>':'')+
+      '<p>'+t(cState==='open'?'No files yet':'Empty container')+'</p>'+
+      (cState==='open'?'<div class="hint">'+t('Drag and drop files or folders here, or click + Add Files')+'</div>':'')+
     '</div>';return;
   }
   document.getElementById('flHead').style.display='';
-  s.innerHTML=files.map((f,i)=>{
-    const ext=f.OriginalName.split('.').pop().toLowerCase();
-    const t=cType(ext);
-    return'<div class="frow'+(i===selIdx?' selected':'')+'" onclick="sel('+i+')" ondblclick="openF('+i+')">'+
-      '<div class="icon">'+ico(t)+'</div>'+
-      '<div class="fname">'+f.OriginalName+'</div>'+
+  s.innerHTML=treeRows(fileTree(),0,copyTargets().length,tabs[cur].folded||{});
+}
+// fileTree arranges the files by the folders in their names, such as
+// "photos/2024/a.jpg", keeping each file's index in files.
+function fileTree(){
+  const root={dirs:{},files:[]};
+  files.forEach((f,i)=>{
+    let n=root,p='';
+    for(const d of f.OriginalName.split('/').slice(0,-1)){
+      p+=d+'/';
+      n=n.dirs[d]||(n.dirs[d]={path:p,dirs:{},files:[],count:0,size:0});
+      n.count++;n.size+=f.OriginalSize;
+    }
+    n.files.push(i);
+  });
+  return root;
+}
+// treeRows renders a folder's subfolders, then its files, indented by
+// depth. A folder in folded is shown closed.
+function treeRows(n,depth,cp,folded){
+  const pad=' style="padding-left:'+depth*16+'px"';
+  let h='';
+  for(const d of Object.keys(n.dirs).sort()){
+    const c=n.dirs[d],open=!folded[c.path];
+    h+='<div class="frow folder" data-dir="'+esc(c.path)+'" onclick="foldDir(this.dataset.dir)">'+
+      '<div class="icon">'+(open?'&#128194;':'&#128193;')+'</div>'+
+      '<div class="fname"'+pad+'><span class="caret">'+(open?'&#9662;':'&#9656;')+'</span>'+esc(d)+'</div>'+
+      '<div class="fsize">'+fmtS(c.size)+'</div>'+
+      '<div class="ftype">'+t(c.count===1?'%d item':'%d items',c.count)+'</div>'+
+      '<div class="factions">'+(cState==='open'?'<button class="fa-btn" onclick="event.stopPropagation();removeDir(this.closest(\'.frow\').dataset.dir)">'+t('Delete')+'</button>':'')+'</div></div>';
+    if(open)h+=treeRows(c,depth+1,cp,folded);
+  }
+  for(const i of n.files){
+    const f=files[i],base=f.OriginalName.split('/').pop(),ext=base.includes('.')?base.split('.').pop().toLowerCase():'';
+    h+='<div class="frow'+(i===selIdx?' selected':'')+'" title="'+esc(f.OriginalName)+'" onclick="sel('+i+')" ondblclick="openF('+i+')">'+
+      '<div class="icon">'+ico(cType(ext))+'</div>'+
+      '<div class="fname"'+pad+'>'+(depth?'<span class="caret"></span>':'')+esc(base)+'</div>'+
       '<div class="fsize">'+fmtS(f.OriginalSize)+'</div>'+
-      '<div class="ftype">'+ext.toUpperCase()+'</div>'+
+      '<div class="ftype">'+esc(ext.toUpperCase())+'</div>'+
       '<div class="factions">'+
-        (cState==='sealed'?'<button class="fa-btn" onclick="event.stopPropagation();openF('+i+')">Open</button>'+
-          '<button class="fa-btn" onclick="event.stopPropagation();saveF('+i+')">Save</button>':'')+
+        (cState==='sealed'?'<button class="fa-btn" onclick="event.stopPropagation();openF('+i+')">'+t('Open')+'</button>'+
+          '<button class="fa-btn" onclick="event.stopPropagation();saveF('+i+')">'+t('Save')+'</button>':'')+
+        (cState==='open'?'<button class="fa-btn" onclick="event.stopPropagation();renameF('+i+')">'+t('Rename')+'</button>'+
+          '<button class="fa-btn" onclick="event.stopPropagation();removeF([files['+i+'].OriginalName])">'+t('Delete')+'</button>':'')+
+        (cp?'<button class="fa-btn" onclick="event.stopPropagation();copyF('+i+')">'+t('Copy')+'</button>':'')+
       '</div></div>';
-  }).join('');
+  }
+  return h;
+}
+function foldDir(p){
+  const x=tabs[cur];x.folded=x.folded||{};
+  if(x.folded[p])delete x.folded[p];else x.folded[p]=true;
+  renderFL();
 }
 
 function sel(i){selIdx=i;renderFL();showPV(files[i])}
+function closePV(){document.getElementById('pvPane').classList.remove('active');selIdx=-1;renderFL()}
+// On a narrow screen the sidebar and the preview slide over the file list
+// instead of sitting beside it: the titlebar's Details button toggles the
+// sidebar, and the preview has a Close button.
+function toggleSB(on){document.querySelector('.workspace-body').classList.toggle('sb-open',on)}
 
 function showPV(f){
   document.getElementById('pvPane').classList.add('active');
-  const ext=f.OriginalName.split('.').pop().toLowerCase();
-  const t=cType(ext);
-  const url='/api/serve-file?file='+encodeURIComponent(f.OriginalName);
+  const base=f.OriginalName.split('/').pop(),ext=base.includes('.')?base.split('.').pop().toLowerCase():'';
+  const ty=cType(ext);
+  const url=au('/api/serve-file?file='+encodeURIComponent(f.OriginalName)+'&container='+encodeURIComponent(cName));
   document.getElementById('pvName').textContent=f.OriginalName;
   const th=document.getElementById('pvThumb');
-  if(cState==='sealed'){
+  if(cState==='sealed'&&!tabs[cur].extracted){
+    th.innerHTML='<div class="big-icon">'+ico(ty)+'</div><button class="tb" onclick="previewExtract()">'+t('Extract to preview')+'</button>';
+  }else if(cState==='sealed'){
     if(['jpg','jpeg','png','gif','webp','svg','bmp'].includes(ext))th.innerHTML='<img src="'+url+'">';
     else if(ext==='pdf')th.innerHTML='<iframe src="'+url+'"></iframe>';
+    // The server answers range requests, so the player streams the file and
+    // seeks in it; a format the browser cannot play falls back to the icon.
+    else if(ty==='audio'||ty==='video'){
+      th.innerHTML='<'+ty+' controls preload="metadata" src="'+url+'"></'+ty+'>';
+      th.firstChild.onerror=()=>{th.innerHTML='<div class="big-icon">'+ico(ty)+'</div>'};
+    }
     else if(['txt','md','csv','log','json','xml','yaml','yml','go','py','js','html','css','sh','toml'].includes(ext)){
-      fetch(url).then(r=>r.text()).then(text=>{
+      fetch(url,{headers:{Range:'bytes=0-4999'}}).then(r=>r.text()).then(text=>{
         th.innerHTML='<pre>'+text.replace(/&/g,'&amp;').replace(/</g,'&lt;').replace(/>/g,'&gt;').substring(0,5000)+'</pre>'});
-    }else th.innerHTML='<div class="big-icon">'+ico(t)+'</div>';
-  }else th.innerHTML='<div class="big-icon">'+ico(t)+'</div>';
+    }else if(['docx','xlsx','pptx','odt'].includes(ext)){
+      th.innerHTML='<div class="doc-pv note">'+t('Loading preview...')+'</div>';
+      const name=cName,file=f.OriginalName;
+      pf('/api/preview',{container:name,file}).then(r=>{
+        if(!isCur(name)||files[selIdx]!==f)return;
+        th.innerHTML=r.success?docPV(r.data):'<div class="big-icon">'+ico(ty)+'</div>';
+      });
+    }else th.innerHTML='<div class="big-icon">'+ico(ty)+'</div>';
+  }else th.innerHTML='<div class="big-icon">'+ico(ty)+'</div>';
 
   document.getElementById('pvMeta').innerHTML=
-    pvr('Name',f.OriginalName)+pvr('Size',fmtS(f.OriginalSize))+pvr('Type',ext.toUpperCase())+
+    pvr(t('Name'),esc(f.OriginalName))+pvr(t('Size'),fmtS(f.OriginalSize))+pvr(t('Type'),ext.toUpperCase())+
     pvr('SHA-256','<span style="font-family:var(--mono);font-size:10px;word-break:break-all">'+f.SHA256+'</span>');
 
   const a=document.getElementById('pvAct');
   if(cState==='sealed'){
-    a.innerHTML='<button class="btn btn-primary" style="font-size:13px;padding:8px" onclick="openF('+selIdx+')">Open File</button>'+
-      '<a href="/api/download?file='+encodeURIComponent(f.OriginalName)+'" class="btn btn-secondary" style="font-size:13px;padding:8px;text-decoration:none;text-align:center">Save to Disk</a>';
-  }else a.innerHTML='<div style="font-size:12px;color:var(--text-dim);text-align:center">Seal the container to open or save files</div>';
+    a.innerHTML='<button class="btn btn-primary" style="font-size:13px;padding:8px" onclick="openF('+selIdx+')">'+t('Open File')+'</button>'+
+      '<button class="btn btn-secondary" style="font-size:13px;padding:8px" onclick="saveF('+selIdx+')">'+t('Save to Disk')+'</button>';
+  }else a.innerHTML='<div style="font-size:12px;color:var(--text-dim);text-align:center">'+t('Seal the container to open or save files')+'</div>';
 }
 
+// docPV renders the text /api/preview read from an office document.
+function docPV(d){
+  let h='';
+  for(const s of d.sections){
+    if(s.slide)h+='<h5>'+t('Slide %d',s.slide)+'</h5>';
+    else if(s.title)h+='<h5>'+esc(s.title)+'</h5>';
+    h+=(s.paragraphs||[]).map(p=>'<p>'+esc(p)+'</p>').join('');
+    if(s.rows&&s.rows.length)h+='<table>'+s.rows.map(r=>'<tr>'+r.map(c=>'<td>'+esc(c)+'</td>').join('')+'</tr>').join('')+'</table>';
+  }
+  if(!h)h='<p class="note">'+t('No text in this document')+'</p>';
+  if(d.truncated)h+='<p class="note">'+t('Preview shows the start of the document only')+'</p>';
+  return'<div class="doc-pv">'+h+'</div>';
+}
 function pvr(l,v){return'<div class="pv-meta-row"><span class="label">'+l+'</span><span>'+v+'</span></div>'}
 
 // Actions
-function openF(i){
-  if(cState!=='sealed'){toast('Seal the container first','error');return}
-  window.open('/api/serve-file?file='+encodeURIComponent(files[i].OriginalName),'_blank');
+async function openF(i){
+  if(cState!=='sealed'){toast(t('Seal the container first'),'error');return}
+  if(!await ensureExtracted())return;
+  window.open(au('/api/serve-file?file='+encodeURIComponent(files[i].OriginalName)+'&container='+encodeURIComponent(cName)),'_blank');
+}
+async function saveF(i){
+  if(cState==='sealed'&&!await ensureExtracted())return;
+  window.location.href=au('/api/download?file='+encodeURIComponent(files[i].OriginalName)+'&container='+encodeURIComponent(cName));
+}
+async function downloadAll(){
+  if(await ensureExtracted())window.location.href=au('/api/download-zip?container='+encodeURIComponent(cName));
+}
+// ensureExtracted extracts the active sealed tab's files, if opening it did
+// not, asking for the passphrase of an encrypted one. It reports whether
+// the files are there and the tab is still active.
+async function ensureExtracted(){
+  if(tabs[cur].extracted)return true;
+  if(!expiryOK())return false;
+  const name=cName;let pass='';
+  if(cInfo.Encrypted){pass=prompt(t('Passphrase for %s:',name));if(pass===null)return false}
+  const r=await pf('/api/extract',{container:name,passphrase:pass,ignore_expiry:ignoreExpiry()});
+  if(!r.success){toast(r.error,'error');return false}
+  return setTab(name,{extracted:true});
+}
+async function previewExtract(){if(await ensureExtracted()&&files[selIdx])showPV(files[selIdx])}
+
+// Expiry: the files of an expired container are opened only once the user
+// has confirmed, for its tab, that they want them anyway. The server then
+// is asked to set the expiry aside with ignore_expiry. A container that
+// expires within expirySoon shows a countdown instead.
+const expirySoon=7*24*3600e3;
+function isExpired(){return !!(cInfo&&cInfo.ExpiresAt&&(cInfo.Expired||new Date(cInfo.ExpiresAt)<=Date.now()))}
+function ignoreExpiry(){return tabs[cur].expiryOK?'true':''}
+// untilText says how long ms is, in days and hours or, under a day, hours
+// and minutes.
+function untilText(ms){
+  const m=Math.max(Math.floor(ms/60000),1),d=Math.floor(m/1440),h=Math.floor(m%1440/60);
+  return d?t('%d days %d hours',d,h):h?t('%d hours %d minutes',h,m%60):t('%d minutes',m);
+}
+// expiryOK reports whether the active tab's files may be opened, asking
+// the user first if it has expired.
+function expiryOK(){
+  if(!isExpired()||tabs[cur].expiryOK)return true;
+  if(!confirm(t('%s expired on %s. Whoever sealed it meant it not to be used after then. Open it anyway?',cName,new Date(cInfo.ExpiresAt).toLocaleString())))return false;
+  setTab(cName,{expiryOK:true});renderExpiry();
+  autoVerify();
+  return true;
+}
+async function openExpired(){if(expiryOK()&&await ensureExtracted())toast(t('Opened %s past its expiry',cName),'success')}
+function renderExpiry(){
+  const b=document.getElementById('expBanner');
+  b.className='exp-banner';b.innerHTML='';
+  if(cur<0||!cInfo.ExpiresAt)return;
+  const at=new Date(cInfo.ExpiresAt),left=at-Date.now();
+  if(isExpired()){
+    b.classList.add('expired');
+    b.innerHTML='<span>&#9888; '+esc(t('This container expired on %s.',at.toLocaleString()))+' '+
+      esc(tabs[cur].expiryOK?t('You chose to open it anyway.'):t('Its files stay closed unless you open it anyway.'))+'</span>'+
+      (tabs[cur].expiryOK?'':'<button class="tb" onclick="openExpired()">'+t('Open anyway')+'</button>');
+  }else if(left<expirySoon){
+    b.classList.add('soon');
+    b.innerHTML='<span>&#9201; '+esc(t('This container expires in %s, on %s.',untilText(left),at.toLocaleString()))+'</span>';
+  }
+}
+setInterval(()=>{if(cur>=0&&cInfo.ExpiresAt)renderSB()},60000);
+
+// Remove and rename files in an open container. A new name may move the
+// file into a folder, as in "docs/report.pdf".
+async function removeF(names){
+  if(!confirm(names.length===1?t('Remove %s from the container?',names[0]):t('Remove %d files from the container?',names.length)))return;
+  const name=cName,f=new FormData();f.append('container',name);
+  names.forEach(n=>f.append('file',n));
+  const r=await(await fetch('/api/remove',{method:'POST',body:f})).json();
+  if(!r.success){toast(r.error,'error');return}
+  toast(t('Removed %d file(s)',names.length),'success');
+  if(isCur(name)){selIdx=-1;document.getElementById('pvPane').classList.remove('active')}
+  await refreshTab(name);
+}
+function removeDir(p){removeF(files.filter(f=>f.OriginalName.startsWith(p)).map(f=>f.OriginalName))}
+async function renameF(i){
+  const old=files[i].OriginalName,to=(prompt(t('New name for %s:',old),old)||'').trim();
+  if(!to||to===old)return;
+  const name=cName,r=await pf('/api/rename',{container:name,file:old,name:to});
+  if(!r.success){toast(r.error,'error');return}
+  toast(t('Renamed %s to %s',old,to),'success');
+  await refreshTab(name);
+  if(isCur(name)&&files[selIdx])showPV(files[selIdx]);
+}
+
+// Copy a file into another open container, one not yet sealed
+let copyIdx=-1;
+function copyTargets(){return tabs.filter((x,i)=>i!==cur&&x.state==='open')}
+function copyF(i){
+  if(!expiryOK())return;
+  const dst=copyTargets();
+  if(!dst.length){toast(t('Open or create another container to copy into'),'error');return}
+  copyIdx=i;
+  document.getElementById('copyWhat').textContent=files[i].OriginalName;
+  document.getElementById('copyDst').innerHTML=dst.map(x=>'<option value="'+esc(x.name)+'">'+esc(x.name)+'</option>').join('');
+  document.getElementById('copyPass').value='';
+  document.getElementById('copyPassRow').style.display=cInfo.Encrypted?'':'none';
+  showModal('copyModal');
+}
+async function doCopy(){
+  const dst=document.getElementById('copyDst').value,file=files[copyIdx].OriginalName;
+  const r=await pf('/api/copy',{source:cName,container:dst,file,passphrase:document.getElementById('copyPass').value,ignore_expiry:ignoreExpiry()});
+  if(!r.success){toast(r.error,'error');return}
+  hideModal('copyModal');toast(t('Copied %s to %s',file,dst),'success');
+  refreshTab(dst);
 }
-function saveF(i){window.location.href='/api/download?file='+encodeURIComponent(files[i].OriginalName)}
 
 async function extractDL(){
-  const pass=prompt('Decryption passphrase (blank if unencrypted):');
+  if(!expiryOK())return;
+  const pass=prompt(t('Decryption passphrase (blank if unencrypted):'));
   if(pass===null)return;
-  const f=new FormData();f.append('container',cName);f.append('passphrase',pass||'');
+  const name=cName;
+  const f=new FormData();f.append('container',name);f.append('passphrase',pass||'');f.append('ignore_expiry',ignoreExpiry());
   const r=await(await fetch('/api/extract',{method:'POST',body:f})).json();
-  if(r.success){toast('Downloading files...','success');setTimeout(()=>window.location.href='/api/download-zip',500)}
+  if(r.success){setTab(name,{extracted:true});toast(t('Downloading files...'),'success');setTimeout(()=>window.location.href=au('/api/download-zip?container='+encodeURIComponent(name)),500)}
+  else toast(r.error,'error');
+}
+
+// extractTo extracts the active sealed tab's files into a folder the user
+// names, to keep them; the files extracted for previewing are removed with
+// the session. A relative folder is in the working directory.
+async function extractTo(){
+  if(!expiryOK())return;
+  const name=cName,folder=prompt(t('Folder to extract the files into, in the working directory unless a full path is given:'),name.replace(/\.imf$/i,''));
+  if(!folder)return;
+  let pass='';
+  if(cInfo.Encrypted){pass=prompt(t('Passphrase for %s:',name));if(pass===null)return}
+  const r=await pf('/api/extract-to',{container:name,folder,passphrase:pass,ignore_expiry:ignoreExpiry()});
+  if(r.success)toast(t('Extracted %d files to %s',r.data.files.length,r.data.output_dir),'success');
   else toast(r.error,'error');
 }
 
-// Add files
+// Add files: each is uploaded in chunks that the server writes straight to
+// disk, so size is not bounded by memory. A failed chunk is retried from
+// where the server says the upload got to, and an upload interrupted by a
+// reload resumes from the ID kept in localStorage. A file from a folder is
+// uploaded with its path in the folder, which the container keeps.
+const chunkSize=8<<20;
+async function uploadFile(x,path){
+  const key='imf-upload:'+(path||x.name)+':'+x.size+':'+x.lastModified;
+  let id=localStorage.getItem(key),offset=0;
+  if(id){
+    const r=await(await fetch('/api/upload-status?id='+id)).json();
+    if(r.success)offset=r.data.offset;else id=null;
+  }
+  if(!id){
+    const r=await pf('/api/upload-start',{name:x.name,size:x.size,path:path||''});
+    if(!r.success)throw new Error(r.error);
+    id=r.data.id;localStorage.setItem(key,id);
+  }
+  for(let fails=0;offset<x.size;){
+    showProgress({label:'Uploading',stage:'upload',done:offset,total:x.size,percent:offset*100/x.size});
+    let err;
+    try{
+      const r=await(await fetch('/api/upload-chunk?id='+id+'&offset='+offset,{method:'POST',body:x.slice(offset,offset+chunkSize)})).json();
+      if(r.success){offset=r.data.offset;fails=0;continue}
+      err=r.error;
+    }catch(e){err=e.message}
+    if(++fails>5)throw new Error(err);
+    await new Promise(res=>setTimeout(res,1000*fails));
+    try{const st=await(await fetch('/api/upload-status?id='+id)).json();if(st.success)offset=st.data.offset}catch(e){}
+  }
+  return{id,key};
+}
+// addF adds files, given as a FileList, whose files from a folder input
+// carry their webkitRelativePath, or as {file,path} pairs from a dropped
+// folder.
 async function addF(fl){
   if(!fl.length)return;
-  if(cState!=='open'){toast('Cannot add to sealed container','error');return}
-  const f=new FormData();f.append('container',cName);
-  for(const x of fl)f.append('files',x);
-  const r=await(await fetch('/api/add',{method:'POST',body:f})).json();
+  if(cState!=='open'){toast(t('Cannot add to sealed container'),'error');return}
+  const name=cName;
+  const f=new FormData();f.append('container',name);
+  const up=[];
+  try{for(const x of fl)up.push(x.file?await uploadFile(x.file,x.path):await uploadFile(x,x.webkitRelativePath))}
+  catch(e){showProgress({finished:true});toast(t('Upload failed: %s',e.message),'error');return}
+  showProgress({finished:true});
+  for(const u of up)f.append('id',u.id);
+  const r=await(await fetch('/api/upload-finish',{method:'POST',body:f})).json();
   if(r.success){
-    toast('Added '+fl.length+' file(s)','success');
-    const f2=new FormData();f2.append('container',cName);
-    const ir=await(await fetch('/api/info',{method:'POST',body:f2})).json();
-    if(ir.success)cInfo=ir.data;
-    renderSB();await refreshFiles();
+    up.forEach(u=>localStorage.removeItem(u.key));
+    toast(t('Added %d file(s)',fl.length),'success');
+    await refreshTab(name);
   }else toast(r.error,'error');
 }
 
@@ -425,108 +1454,435 @@ function setupDrop(){
   a.ondragenter=e=>{e.preventDefault();dc++;o.classList.add('active')};
   a.ondragleave=()=>{dc--;if(dc<=0){o.classList.remove('active');dc=0}};
   a.ondragover=e=>e.preventDefault();
-  a.ondrop=e=>{e.preventDefault();o.classList.remove('active');dc=0;if(e.dataTransfer.files.length)addF(e.dataTransfer.files)};
+  a.ondrop=e=>{
+    e.preventDefault();o.classList.remove('active');dc=0;
+    // The entries must be taken before the drop handler returns.
+    const en=[...e.dataTransfer.items].map(i=>i.webkitGetAsEntry&&i.webkitGetAsEntry()).filter(Boolean);
+    if(en.some(x=>x.isDirectory))droppedFiles(en).then(addF).catch(err=>toast(err.message,'error'));
+    else if(e.dataTransfer.files.length)addF(e.dataTransfer.files);
+  };
+}
+// droppedFiles walks dropped files and folders, returning each file with
+// its path from the dropped folder down.
+async function droppedFiles(entries){
+  const out=[];
+  async function walk(x){
+    if(x.isFile){out.push({file:await new Promise((res,rej)=>x.file(res,rej)),path:x.fullPath.replace(/^\//,'')});return}
+    const rd=x.createReader();
+    // readEntries returns a directory's entries a batch at a time.
+    for(;;){
+      const batch=await new Promise((res,rej)=>rd.readEntries(res,rej));
+      if(!batch.length)break;
+      for(const c of batch)await walk(c);
+    }
+  }
+  for(const x of entries)await walk(x);
+  return out;
+}
+
+// Passphrase strength meter, debounced; a stale answer is ignored
+let pwT,pwSeq=0;
+function passStrength(){
+  clearTimeout(pwT);
+  pwT=setTimeout(async()=>{
+    const v=document.getElementById('sealPass').value,m=document.getElementById('pwMeter');
+    const seq=++pwSeq;
+    if(!v){m.classList.remove('active');return}
+    const r=await pf('/api/passphrase-strength',{passphrase:v});
+    if(seq!==pwSeq||!r.success)return;
+    const d=r.data,b=document.getElementById('pwBar');
+    b.style.width=((d.score+1)*20)+'%';b.style.background=['var(--error)','var(--error)','var(--warning)','var(--success)','var(--success)'][d.score];
+    document.getElementById('pwText').textContent=t('%s — guessable in %s offline',d.label[0].toUpperCase()+d.label.slice(1),d.crack_time)+(d.warning?'. '+d.warning:'');
+    m.classList.add('active');
+  },150);
 }
 
-// Seal
-async function doSeal(){
-  if(!files.length){toast('Add files first','error');return}
+// Seal: the key picker offers the session's key and the keyring's private
+// keys; a keyring key signs this seal only
+async function openSeal(){
+  await refreshKeys();
+  const sk=document.getElementById('sealKey');
+  sk.innerHTML='<option value="">'+esc(keyState.loaded&&keyState.signing?t('Session key')+' · '+keyState.fingerprint.slice(0,16):t('New key, generated now'))+'</option>'+
+    keyList.filter(x=>x.private).map(x=>'<option value="'+esc(x.name)+'">'+esc(x.name)+' · '+x.fingerprint.slice(0,16)+'</option>').join('');
+  sk.value=keyState.name&&keyState.signing?keyState.name:'';
+  // A template's policy: its expiry is suggested, its encryption enforced.
+  const x=tplFor((cInfo.Metadata||{}).template),sp=document.getElementById('sealPass'),se=document.getElementById('sealExp');
+  sp.disabled=!!x&&x.encryption==='none';
+  if(sp.disabled)sp.value='';
+  if(x&&x.expiry_days&&!se.value)se.value=new Date(Date.now()+x.expiry_days*864e5).toISOString().slice(0,10);
+  document.getElementById('sealTpl').textContent=x?[t('Made from the %s template.',t(x.name)),
+    x.encryption==='required'?t('It must be encrypted with a passphrase.'):x.encryption==='none'?t('It is sealed without encryption.'):'',
+    x.expiry_days?t('It expires %d days after sealing unless you change the date.',x.expiry_days):''].filter(Boolean).join(' '):'';
+  showModal('sealModal');
+}
+async function doSeal(keyPass){
+  if(!files.length){toast(t('Add files first'),'error');return}
+  const keyName=document.getElementById('sealKey').value;
   // Auto-generate signing key if none loaded — no prompt, just do it
-  try{
+  if(!keyName)try{
     const ks=await(await fetch('/api/key-status')).json();
-    if(!ks.data.loaded){
+    // A key wiped after inactivity must be reloaded, not silently replaced
+    if(ks.data.expired){
+      refreshKeys();
+      toast(t('Signing key was cleared after inactivity — load it again'),'error');return;
+    }
+    if(!ks.data.signing){
       const kr=await fetch('/api/keygen',{method:'POST'});
       const kd=await kr.json();
-      if(!kd.success){toast('Key generation failed: '+kd.error,'error');return;}
-      setKey(true,'Key auto-generated');
+      if(!kd.success){toast(t('Key generation failed: %s',kd.error),'error');return;}
+      await refreshKeys();setKey(true,t('Key auto-generated'));
     }
   }catch(e){console.error('Key check failed',e);}
-  const r=await pf('/api/seal',{
-    container:cName,passphrase:document.getElementById('sealPass').value,
+  const name=cName;
+  const d={
+    container:name,passphrase:document.getElementById('sealPass').value,
     expires:document.getElementById('sealExp').value,
     embed_key:'true'
-  });
+  };
+  if(keyName){d.key=keyName;if(keyPass)d.key_passphrase=keyPass}
+  const f=new FormData();for(const[k,v]of Object.entries(d))f.append(k,v);
+  const res=await fetch('/api/seal',{method:'POST',body:f});const r=await res.json();
+  if(res.status===401){
+    if(keyPass)toast(r.error,'error');
+    const p=prompt(t('Passphrase for %s:',keyName));
+    if(p)doSeal(p);
+    return;
+  }
   if(r.success){
-    cState='sealed';hideModal('sealModal');toast('Container sealed','success');
-    const f=new FormData();f.append('container',cName);
-    const ir=await(await fetch('/api/info',{method:'POST',body:f})).json();
-    if(ir.success)cInfo=ir.data;
+    hideModal('sealModal');toast(t('Container sealed'),'success');
+    const ir=await pf('/api/info',{container:name});
+    setTab(name,ir.success?{state:'sealed',info:ir.data}:{state:'sealed'});
     // Extract for preview
-    const ef=new FormData();ef.append('container',cName);ef.append('passphrase',document.getElementById('sealPass').value);
-    await fetch('/api/extract',{method:'POST',body:ef});
-    renderWS();await refreshFiles();autoVerify();
+    if(prefs.preview){
+      const er=await pf('/api/extract',{container:name,passphrase:d.passphrase});
+      setTab(name,{extracted:er.success});
+    }
+    if(isCur(name))renderWS();
+    await refreshFiles(name);autoVerify(name);
   }else toast(r.error,'error');
 }
 
-// Export signing key as downloadable .pem file
-async function exportKey(){
-  window.location.href='/api/export-key';
+// Export a public key as a downloadable .pem file: the session's, or a
+// keyring key's by name
+function exportKey(name){
+  window.location.href=au('/api/export-key'+(name?'?name='+encodeURIComponent(name):''));
+}
+// Export the session's private key, which the server hands over only once
+// the user types in the code it prints in its terminal
+async function exportPrivateKey(){
+  const res=await fetch('/api/export-key',{method:'POST',body:new URLSearchParams({private:'true'})});
+  const r=await res.json();
+  if(res.status!==428){toast(r.error,'error');return}
+  const code=prompt(t('To export the private key %s, enter the code printed in the terminal running imf gui. Anyone with the file can sign as you.',r.data.fingerprint.slice(0,16)));
+  if(!code)return;
+  const res2=await fetch('/api/export-key',{method:'POST',body:new URLSearchParams({private:'true',confirm:code})});
+  if(!res2.ok){toast((await res2.json()).error,'error');return}
+  const a=document.createElement('a');a.href=URL.createObjectURL(await res2.blob());a.download='imf_private.pem';
+  document.body.appendChild(a);a.click();a.remove();setTimeout(()=>URL.revokeObjectURL(a.href),1000);
 }
 
 // Verify
-async function autoVerify(){
-  const f=new FormData();f.append('container',cName);
-  const r=await(await fetch('/api/verify',{method:'POST',body:f})).json();
-  const e=document.getElementById('vBadge');
-  if(r.success){e.className='verify-status pass';e.innerHTML='&#10003; Verified'}
-  else{e.className='verify-status fail';e.innerHTML='&#10007; '+r.error}
+async function autoVerify(name=cName){
+  const x=tabs.find(x=>x.name===name);
+  const r=await pf('/api/verify',{container:name,ignore_expiry:x&&x.expiryOK?'true':''});
+  if(setTab(name,{verify:r}))showVerify();
+}
+// showVerify shows the active tab's last verification result, if any.
+function showVerify(){
+  const v=cur>=0&&tabs[cur].verify,e=document.getElementById('vBadge');
+  if(!v||!e)return;
+  if(v.success){e.className='verify-status pass';e.innerHTML='&#10003; '+t('Verified')}
+  else{e.className='verify-status fail';e.innerHTML='&#10007; '+esc(v.error)}
+  const s=document.getElementById('mdSig');
+  if(s){s.className='md-sig '+(v.success?'good':'bad');s.innerHTML=v.success?'&#10003; '+t('Signed with the container'):'&#10007; '+t('Signature not verified')}
+  document.getElementById('rpLink')?.remove();
+  if(v.data&&v.data.files)e.insertAdjacentHTML('afterend','<button class="rp-link" id="rpLink" onclick="showReport()">'+t('View report')+'</button>');
+}
+
+// Verification report: the active tab's last verification, with each
+// file's recorded and computed hash, exportable as JSON or, through the
+// browser's print dialog, as PDF
+function reportData(){
+  if(document.getElementById('checkScreen').classList.contains('active'))return checkResult&&checkResult.report;
+  const v=cur>=0&&tabs[cur].verify;return v&&v.data;
+}
+function reportHTML(d){
+  const fl=d.files||[],bad=fl.filter(f=>f.status!=='ok').length;
+  const row=(l,v)=>'<div class="meta-row"><span class="label">'+l+'</span><span class="value">'+v+'</span></div>';
+  return row(t('Container'),esc(d.container))+
+    row(t('Result'),d.verified?'<span class="rp-ok">&#10003; '+t('Verified')+'</span>':'<span class="rp-bad">&#10007; '+esc(d.error)+'</span>')+
+    (d.signer?row(t('Signer'),signerLabel(d.signer)):'')+
+    (d.sealed_at?row(t('Sealed'),new Date(d.sealed_at).toLocaleString()):'')+
+    row(t('Files'),t('%d checked, %d failed',fl.length,bad))+
+    '<div class="rp-wrap"><table class="rp-table"><thead><tr><th>'+t('Status')+'</th><th>'+t('File')+'</th><th>'+t('Expected SHA-256')+'</th><th>'+t('Actual SHA-256')+'</th></tr></thead><tbody>'+
+    fl.map(f=>'<tr'+(f.status==='ok'?'':' class="bad"')+'><td class="'+(f.status==='ok'?'rp-ok':'rp-bad')+'">'+
+      t({ok:'OK',mismatch:'Mismatch',missing:'Missing'}[f.status]||f.status)+'</td>'+
+      '<td>'+esc(f.name)+(f.encrypted?'<br><span class="km-tag">'+t('hash of encrypted data')+'</span>':'')+(f.error?'<br><span class="rp-bad">'+esc(f.error)+'</span>':'')+'</td>'+
+      '<td class="rp-hash">'+esc(f.expected_sha256)+'</td><td class="rp-hash">'+esc(f.computed_sha256||'—')+'</td></tr>').join('')+
+    '</tbody></table></div>';
+}
+function showReport(){
+  const d=reportData();if(!d)return;
+  document.getElementById('rpBody').innerHTML=reportHTML(d);
+  showModal('reportModal');
+}
+function exportReport(){
+  const d=reportData();if(!d)return;
+  const a=document.createElement('a');
+  a.href=URL.createObjectURL(new Blob([JSON.stringify(d,null,2)],{type:'application/json'}));
+  a.download=d.container.replace(/\.imf$/,'')+'-verify.json';
+  document.body.appendChild(a);a.click();a.remove();setTimeout(()=>URL.revokeObjectURL(a.href),1000);
+}
+// printReport opens the report on a plain page and the print dialog, where
+// "Save as PDF" writes it out
+function printReport(){
+  const d=reportData();if(!d)return;
+  const w=window.open('','_blank');
+  if(!w){toast(t('Allow pop-ups to save the report'),'error');return}
+  w.document.write('<!DOCTYPE html><html><head><meta charset="UTF-8"><title>'+esc(d.container)+' — '+t('Verification Report')+'</title><style>'+
+    'body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;color:#111;margin:32px;font-size:12px}h1{font-size:18px;margin-bottom:4px}'+
+    '.gen{color:#666;margin-bottom:16px}.meta-row{display:flex;gap:12px;margin-bottom:4px}.meta-row .label{color:#666;width:100px}'+
+    'table{width:100%;border-collapse:collapse;margin-top:16px}th,td{border:1px solid #ccc;padding:6px;text-align:left;vertical-align:top}th{background:#eee}'+
+    '.rp-hash{font-family:monospace;font-size:9px;word-break:break-all}.rp-ok{color:#067a46;font-weight:600}.rp-bad{color:#b91c1c;font-weight:600}'+
+    'tr.bad td{background:#fde8e8}.km-tag{color:#92400e;font-size:10px}</style></head><body>'+
+    '<h1>'+t('Verification Report')+'</h1><div class="gen">'+t('Generated %s',new Date().toLocaleString())+'</div>'+reportHTML(d)+'</body></html>');
+  w.document.close();w.focus();w.print();
+}
+
+// Verify a Container: for someone who has been sent a container. The .imf,
+// and the sender's public key and a detached .ots proof if they have them,
+// go to /api/check, which verifies them without opening the container or
+// keeping it, and the result is shown as a pass or fail with the signer's
+// fingerprint, whose key it was checked against, and the anchor's status.
+// The files are checked again each time one is added or removed.
+const checkFiles={imf:null,pem:null,ots:null};
+let checkResult=null,checkSeq=0;
+function openCheck(){
+  document.getElementById('launchScreen').style.display='none';
+  document.getElementById('checkScreen').classList.add('active');
+  renderCheckSlots();
+}
+function closeCheck(){
+  document.getElementById('checkScreen').classList.remove('active');
+  document.getElementById('launchScreen').style.display='';
+}
+function setupCheckDrop(){
+  const d=document.getElementById('checkDrop');
+  d.ondragover=e=>{e.preventDefault();d.classList.add('over')};
+  d.ondragleave=()=>d.classList.remove('over');
+  d.ondrop=e=>{e.preventDefault();d.classList.remove('over');checkAdd(e.dataTransfer.files)};
+}
+function checkAdd(list){
+  for(const f of list){
+    const n=f.name.toLowerCase();
+    if(n.endsWith('.imf'))checkFiles.imf=f;
+    else if(n.endsWith('.pem')||n.endsWith('.pub'))checkFiles.pem=f;
+    else if(n.endsWith('.ots'))checkFiles.ots=f;
+    else toast(t('Not an .imf, .pem, or .ots file: %s',f.name),'error');
+  }
+  renderCheckSlots();runCheck();
+}
+function checkRemove(k){checkFiles[k]=null;renderCheckSlots();runCheck()}
+function renderCheckSlots(){
+  const slot=(k,l)=>'<div class="check-slot"><span class="label">'+l+'</span>'+
+    (checkFiles[k]?'<span class="fname">'+esc(checkFiles[k].name)+'</span><button class="tb" onclick="checkRemove(\''+k+'\')">'+t('Remove')+'</button>':
+      '<span class="fname none">'+t(k==='imf'?'Required':'Optional')+'</span>')+'</div>';
+  document.getElementById('checkSlots').innerHTML=slot('imf',t('Container'))+slot('pem',t("Sender's public key"))+slot('ots',t('Anchor proof'));
+}
+async function runCheck(){
+  const seq=++checkSeq,el=document.getElementById('checkResult');
+  checkResult=null;
+  if(!checkFiles.imf){el.innerHTML='';return}
+  el.innerHTML='<div class="verify-status pending">'+t('Checking...')+'</div>';
+  const f=new FormData();f.append('container_file',checkFiles.imf);
+  if(checkFiles.pem)f.append('pubkey',checkFiles.pem);
+  if(checkFiles.ots)f.append('ots',checkFiles.ots);
+  let r;
+  try{r=await(await fetch('/api/check',{method:'POST',body:f})).json()}catch(e){r={success:false,error:String(e)}}
+  if(seq!==checkSeq)return;
+  if(!r.data){el.innerHTML='<div class="verify-status fail">&#10007; '+t('Could not check the container')+'</div><div class="check-why">'+esc(r.error||'')+'</div>';return}
+  checkResult=r.data;
+  el.innerHTML=checkHTML(r.data,r.success,r.error);
+}
+function checkHTML(d,ok,err){
+  const rep=d.report,info=d.info||{},s=rep.signer||info.signer,fl=rep.files||[];
+  let key;
+  if(d.key_given)key=mr(t('Key'),ok?t('Matches the key you provided'):t('Checked against the key you provided'),ok?'good':'bad');
+  else if(d.trusted_as)key=mr(t('Key'),t('In your trust store as %s',esc(d.trusted_as)),'good');
+  else if(d.fingerprint)key=mr(t('Key'),t('Embedded in the container; compare its fingerprint with the sender\'s'),'warn');
+  else key='';
+  const a=d.anchor;let anc;
+  if(!a)anc=mr(t('Anchor'),t('No proof'));
+  else if(a.state==='confirmed')anc=mr(t('Anchor'),t('Confirmed in Bitcoin block %s',a.blocks.map(b=>b.height).join(', ')),'good');
+  else if(a.state==='pending')anc=mr(t('Anchor'),t('Waiting for Bitcoin confirmation'),'warn');
+  else if(a.state==='mismatch')anc=mr(t('Anchor'),t('The proof is for other contents'),'bad');
+  else anc=mr(t('Anchor'),t('Could not read the proof')+(a.error?': '+esc(a.error):''),'bad');
+  return(ok?'<div class="verify-status pass">&#10003; '+t('Verified')+'</div><div class="check-why">'+t('The signature is valid and nothing has changed since the container was sealed.')+'</div>':
+      '<div class="verify-status fail">&#10007; '+t('Verification failed')+'</div><div class="check-why">'+esc(err||rep.error||'')+'</div>')+
+    mr(t('Container'),esc(rep.container))+
+    (s?mr(t('Signer'),signerLabel(s)):'')+key+
+    (rep.sealed_at||info.sealed_at?mr(t('Sealed'),new Date(rep.sealed_at||info.sealed_at).toLocaleString()):'')+
+    (info.expires_at?mr(t('Expires'),new Date(info.expires_at).toLocaleString()+(info.expired?' — '+t('expired'):''),info.expired?'warn':''):'')+
+    anc+
+    (fl.length?mr(t('Files'),t('%d checked, %d failed',fl.length,fl.filter(f=>f.status!=='ok').length)+' <button class="tb" onclick="showReport()" style="font-size:11px;padding:2px 8px;margin-left:6px">'+t('View report')+'</button>'):'')+
+    (d.fingerprint?'<div class="hash-label">'+t('Key fingerprint')+'</div>'+hashBox(d.fingerprint):'')+
+    '<div class="hash-label">'+t('Container SHA-256')+'</div>'+hashBox(d.sha256);
+}
+
+// Compare: the diff view sets the active tab's container, as revised,
+// beside an original picked from the other tabs and the recent containers,
+// and lists each file added, removed, modified, moved, or, if asked for,
+// unchanged, from /api/diff.
+let diffData=null;
+async function openDiff(){
+  const s=document.getElementById('diffOrig'),prev=s.value;
+  let names=tabs.map(x=>x.name);
+  try{const r=await(await fetch('/api/recent')).json();if(r.success)names=names.concat(r.data.filter(x=>!x.missing).map(x=>x.name))}catch(e){}
+  names=[...new Set(names)].filter(n=>n!==cName);
+  if(!names.length){toast(t('Open another container to compare this one with'),'error');return}
+  s.innerHTML=names.map(n=>'<option value="'+esc(n)+'">'+esc(n)+'</option>').join('');
+  if(names.includes(prev))s.value=prev;
+  showModal('diffModal');
+  runDiff();
+}
+async function runDiff(){
+  const orig=document.getElementById('diffOrig').value,name=cName;
+  diffData=null;
+  document.getElementById('diffSum').textContent=t('Comparing...');
+  document.getElementById('diffBody').innerHTML='';
+  const r=await pf('/api/diff',{original:orig,container:name});
+  if(orig!==document.getElementById('diffOrig').value||name!==cName)return;
+  if(!r.success){document.getElementById('diffSum').textContent=r.error;return}
+  diffData=r.data||[];
+  renderDiff();
+}
+function renderDiff(){
+  if(!diffData)return;
+  const n={added:0,removed:0,modified:0,moved:0,unchanged:0};
+  diffData.forEach(c=>n[c.Change]++);
+  document.getElementById('diffSum').textContent=
+    t('%d added, %d removed, %d modified, %d moved, %d unchanged',n.added,n.removed,n.modified,n.moved,n.unchanged);
+  const all=document.getElementById('diffAll').checked;
+  const list=diffData.filter(c=>all||c.Change!=='unchanged');
+  const side=f=>f?esc(f.OriginalName)+' <span class="diff-side">'+fmtS(f.OriginalSize)+'</span><div class="rp-hash">'+esc(f.SHA256)+'</div>':'<span class="diff-side">—</span>';
+  const label={added:'Added',removed:'Removed',modified:'Modified',moved:'Moved',unchanged:'Unchanged'};
+  document.getElementById('diffBody').innerHTML=list.length?
+    '<table class="rp-table"><thead><tr><th>'+t('Change')+'</th><th>'+esc(document.getElementById('diffOrig').value)+'</th><th>'+esc(cName)+'</th></tr></thead><tbody>'+
+    list.map(c=>'<tr class="'+c.Change+'"><td class="diff-change">'+t(label[c.Change])+'</td><td>'+side(c.Old)+'</td><td>'+side(c.New)+'</td></tr>').join('')+
+    '</tbody></table>':
+    '<div class="km-empty" style="padding:16px">'+t(diffData.length?'The two containers hold the same files.':'Neither container holds any files.')+'</div>';
 }
 
 // Anchor to Bitcoin via OpenTimestamps
 async function anchorContainer(){
-  toast('Anchoring to Bitcoin via OpenTimestamps...','info');
-  const f=new FormData();f.append('container',cName);
+  toast(t('Anchoring to Bitcoin via OpenTimestamps...'),'info');
+  const name=cName;
+  const f=new FormData();f.append('container',name);
   const r=await(await fetch('/api/anchor',{method:'POST',body:f})).json();
   if(r.success){
-    toast('Anchored to Bitcoin!','success');
-    showAnchorResult(r.data);
+    toast(t('Anchored to Bitcoin!'),'success');
+    if(setTab(name,{hash:null})){showAnchorResult(r.data);pollAnchor(name);renderHash()}
   }else{
-    toast('Anchor failed: '+r.error,'error');
+    toast(t('Anchor failed: %s',r.error),'error');
   }
 }
 
-// Check if .ots proof exists and verify it
+// Anchor status: the calendars commit to Bitcoin every few hours, so while
+// the active tab's proof is pending, /api/anchor-status is asked again every
+// few minutes, upgrading the proof once it is confirmed. Polling stops when
+// the tab is left; coming back to it checks again.
+const anchorPollInterval=5*60*1000;
+let anchorTimer=null;
+function pollAnchor(name){
+  clearTimeout(anchorTimer);
+  anchorTimer=setTimeout(()=>{
+    if(isCur(name)&&document.getElementById('workspace').classList.contains('active'))checkAnchorStatus();
+  },anchorPollInterval);
+}
 async function checkAnchorStatus(){
-  const f=new FormData();f.append('container',cName);
+  const name=cName;
+  clearTimeout(anchorTimer);
+  const st=document.getElementById('anchorCheck');if(st)st.textContent=t('Checking...');
+  let res,r;
   try{
-    const r=await(await fetch('/api/anchor-verify',{method:'POST',body:f})).json();
-    if(r.success){
-      showAnchorVerified(r.data);
-    }else{
-      showAnchorNotFound();
-    }
-  }catch(e){showAnchorNotFound();}
+    res=await pfr('/api/anchor-status',{container:name});r=await res.json();
+  }catch(e){r={success:false,error:String(e)}}
+  if(!isCur(name))return;
+  if(r.success&&r.data.complete){showAnchorConfirmed(r.data);return}
+  if(res&&res.status===404){showAnchorNotFound();return}
+  if(r.success)showAnchorPending(r.data);else showAnchorFailed(r.error);
+  pollAnchor(name);
 }
 
 // Show anchor result after submitting
 function showAnchorResult(data){
   const aDiv=document.getElementById('sAnchor');
   if(!aDiv)return;
-  aDiv.innerHTML='<h4>Blockchain Anchor</h4>'+
-    mr('Status','Submitted','good')+
-    mr('Hash',data.hash.substring(0,16)+'...')+
-    mr('Server',data.server.replace('https://',''))+
-    mr('Submitted',new Date(data.timestamp).toLocaleString())+
+  aDiv.innerHTML='<h4>'+t('Blockchain Anchor')+'</h4>'+
+    mr(t('Status'),t('Submitted'),'good')+
+    mr(t('Hash'),data.hash.substring(0,16)+'...')+
+    mr(t('Server'),data.server.replace('https://',''))+
+    mr(t('Submitted'),new Date(data.timestamp).toLocaleString())+
     '<div style="margin-top:10px;display:flex;flex-direction:column;gap:6px">'+
-      '<a href="/api/download?file='+encodeURIComponent(cName+'.ots')+'" class="tb success" style="font-size:11px;padding:4px 10px;text-decoration:none;text-align:center">Download .ots proof</a>'+
-      '<button class="tb" onclick="verifyAnchor()" style="font-size:11px;padding:4px 10px">Verify Anchor</button>'+
+      '<a href="'+au('/api/download?file='+encodeURIComponent(cName+'.ots'))+'" class="tb success" style="font-size:11px;padding:4px 10px;text-decoration:none;text-align:center">'+t('Download .ots proof')+'</a>'+
+      '<button class="tb" onclick="verifyAnchor()" style="font-size:11px;padding:4px 10px">'+t('Verify Anchor')+'</button>'+
     '</div>';
 }
 
+// Show a proof still waiting for its calendars to commit to Bitcoin
+function showAnchorPending(data){
+  const aDiv=document.getElementById('sAnchor');
+  if(!aDiv)return;
+  aDiv.innerHTML='<h4>'+t('Blockchain Anchor')+'</h4>'+
+    mr(t('Status'),t('Waiting for Bitcoin confirmation'),'warn')+
+    mr(t('Hash'),data.container_hash.substring(0,16)+'...')+
+    mr(t('Pending calendars'),String(data.pending.length))+
+    '<div style="margin-top:6px;font-size:11px;color:var(--text-faint)" id="anchorCheck">'+
+      t('Last checked %s; checking again every few minutes.',new Date().toLocaleTimeString())+'</div>'+
+    anchorButtons();
+}
+// Show a proof confirmed in one or more Bitcoin blocks
+function showAnchorConfirmed(data){
+  const aDiv=document.getElementById('sAnchor');
+  if(!aDiv)return;
+  aDiv.innerHTML='<h4>'+t('Blockchain Anchor')+'</h4>'+
+    '<div class="verify-status pass" style="margin-bottom:10px">&#10003; '+t('Confirmed in Bitcoin')+'</div>'+
+    mr(t('Hash'),data.container_hash.substring(0,16)+'...')+
+    data.blocks.map(b=>mr(t('Block'),String(b.height))+
+      mr(t('Block time'),b.time?new Date(b.time).toLocaleString():t('unavailable'))).join('')+
+    (data.embedded?mr(t('Proof'),t('Embedded')):'')+
+    anchorButtons(data.embedded);
+}
+// Show a status check that failed, with a retry
+function showAnchorFailed(err){
+  const aDiv=document.getElementById('sAnchor');
+  if(!aDiv)return;
+  aDiv.innerHTML='<h4>'+t('Blockchain Anchor')+'</h4>'+
+    '<div class="verify-status fail" style="margin-bottom:10px">&#10007; '+t('Could not check the anchor')+'</div>'+
+    '<div style="font-size:11px;color:var(--text-dim);word-break:break-word" id="anchorCheck">'+esc(err||'')+'</div>'+
+    anchorButtons(false,t('Retry'));
+}
+function anchorButtons(embedded,check){
+  return'<div style="margin-top:10px;display:flex;flex-direction:column;gap:6px">'+
+    (embedded?'':'<a href="'+au('/api/download?file='+encodeURIComponent(cName+'.ots'))+'" class="tb success" style="font-size:11px;padding:4px 10px;text-decoration:none;text-align:center">'+t('Download .ots proof')+'</a>')+
+    '<button class="tb" onclick="checkAnchorStatus()" style="font-size:11px;padding:4px 10px">'+(check||t('Check now'))+'</button>'+
+  '</div>';
+}
+
 // Show verified anchor status
 function showAnchorVerified(data){
   const aDiv=document.getElementById('sAnchor');
   if(!aDiv)return;
-  aDiv.innerHTML='<h4>Blockchain Anchor</h4>'+
-    '<div class="verify-status pass" style="margin-bottom:10px">&#10003; Proof matches container</div>'+
-    mr('Hash',data.hash.substring(0,16)+'...')+
-    mr('Proof size',data.proof_size+' bytes')+
+  aDiv.innerHTML='<h4>'+t('Blockchain Anchor')+'</h4>'+
+    '<div class="verify-status pass" style="margin-bottom:10px">&#10003; '+t('Proof matches container')+'</div>'+
+    mr(t('Hash'),data.hash.substring(0,16)+'...')+
+    mr(t('Proof size'),t('%d bytes',data.proof_size))+
     '<div style="margin-top:10px;display:flex;flex-direction:column;gap:6px">'+
-      '<a href="/api/download?file='+encodeURIComponent(cName+'.ots')+'" class="tb success" style="font-size:11px;padding:4px 10px;text-decoration:none;text-align:center">Download .ots proof</a>'+
-      '<a href="https://opentimestamps.org" target="_blank" class="tb" style="font-size:11px;padding:4px 10px;text-decoration:none;text-align:center">Verify on Bitcoin &#8599;</a>'+
+      '<a href="'+au('/api/download?file='+encodeURIComponent(cName+'.ots'))+'" class="tb success" style="font-size:11px;padding:4px 10px;text-decoration:none;text-align:center">'+t('Download .ots proof')+'</a>'+
+      '<a href="https://opentimestamps.org" target="_blank" class="tb" style="font-size:11px;padding:4px 10px;text-decoration:none;text-align:center">'+t('Verify on Bitcoin')+' &#8599;</a>'+
     '</div>'+
     '<div style="margin-top:8px;font-size:10px;color:var(--text-faint)">'+
-      'Drop your .ots file at opentimestamps.org for full Bitcoin block verification.'+
+      t('Drop your .ots file at opentimestamps.org for full Bitcoin block verification.')+
     '</div>';
 }
 
@@ -534,28 +1890,30 @@ function showAnchorVerified(data){
 function showAnchorNotFound(){
   const aDiv=document.getElementById('sAnchor');
   if(!aDiv)return;
-  aDiv.innerHTML='<h4>Blockchain Anchor</h4>'+
-    '<div style="font-size:12px;color:var(--text-dim)">Not yet anchored</div>'+
+  aDiv.innerHTML='<h4>'+t('Blockchain Anchor')+'</h4>'+
+    '<div style="font-size:12px;color:var(--text-dim)">'+t('Not yet anchored')+'</div>'+
     '<div style="margin-top:6px;font-size:11px;color:var(--text-faint)">'+
-      'Click "&#9875; Anchor to Bitcoin" above to timestamp this container on the blockchain.'+
+      t('Click "%s" above to timestamp this container on the blockchain.','&#9875; '+t('Anchor to Bitcoin'))+
     '</div>';
 }
 
 // Verify existing anchor
 async function verifyAnchor(){
-  toast('Verifying anchor proof...','info');
-  const f=new FormData();f.append('container',cName);
+  toast(t('Verifying anchor proof...'),'info');
+  const name=cName;
+  const f=new FormData();f.append('container',name);
   const r=await(await fetch('/api/anchor-verify',{method:'POST',body:f})).json();
   if(r.success){
-    toast('Anchor verified — proof matches container','success');
-    showAnchorVerified(r.data);
+    toast(t('Anchor verified — proof matches container'),'success');
+    if(isCur(name))showAnchorVerified(r.data);
   }else{
-    toast('Anchor verification failed: '+r.error,'error');
+    toast(t('Anchor verification failed: %s',r.error),'error');
   }
 }
 
 // Helpers
-async function pf(url,d){const f=new FormData();for(const[k,v]of Object.entries(d))f.append(k,v);return(await fetch(url,{method:'POST',body:f})).json()}
+async function pf(url,d){return(await pfr(url,d)).json()}
+function pfr(url,d){const f=new FormData();for(const[k,v]of Object.entries(d))f.append(k,v);return fetch(url,{method:'POST',body:f})}
 function toast(m,t){const e=document.createElement('div');e.className='toast '+t;e.textContent=m;document.body.appendChild(e);setTimeout(()=>e.remove(),4000)}
 function fmtS(b){if(b<1024)return b+' B';if(b<1048576)return(b/1024).toFixed(1)+' KB';return(b/1048576).toFixed(1)+' MB'}
 function ico(t){return{image:'&#128444;',pdf:'&#128196;',text:'&#128196;',code:'&#128187;',document:'&#128203;',archive:'&#128230;',audio:'&#127925;',video:'&#127909;',other:'&#128196;'}[t]||'&#128196;'}
@@ -564,8 +1922,10 @@ function cType(e){
   if(e==='pdf')return'pdf';
   if(['txt','md','csv','log','json','xml','yaml','yml','toml'].includes(e))return'text';
   if(['go','py','js','ts','java','c','cpp','h','rs','rb','sh','html','css'].includes(e))return'code';
-  if(['doc','docx','xls','xlsx','ppt','pptx'].includes(e))return'document';
+  if(['doc','docx','xls','xlsx','ppt','pptx','odt','rtf'].includes(e))return'document';
   if(['zip','tar','gz','7z','rar','imf'].includes(e))return'archive';
+  if(['mp3','wav','flac','aac','ogg','oga','opus','m4a'].includes(e))return'audio';
+  if(['mp4','m4v','mov','avi','mkv','webm','ogv'].includes(e))return'video';
   return'other';
 }
 
@@ -575,9 +1935,10 @@ document.addEventListener('keydown',e=>{
   if(e.key==='ArrowDown'){e.preventDefault();sel(Math.min(selIdx+1,files.length-1))}
   if(e.key==='ArrowUp'){e.preventDefault();sel(Math.max(selIdx-1,0))}
   if(e.key==='Enter'&&selIdx>=0){e.preventDefault();openF(selIdx)}
-  if(e.key==='Escape'){document.getElementById('pvPane').classList.remove('active');selIdx=-1;renderFL()}
+  if(e.key==='Escape')closePV();
 });
 document.getElementById('createName').addEventListener('keydown',e=>{if(e.key==='Enter')doCreate()});
+setupCheckDrop();
 
 // Auto-open: if launched with ?open=filename.imf, load that container automatically.
 // This is used by the Tauri wrapper when the app is launched by double-clicking an .imf file.
@@ -585,19 +1946,11 @@ document.getElementById('createName').addEventListener('keydown',e=>{if(e.key===
   const p=new URLSearchParams(window.location.search);
   const autoOpen=p.get('open');
   if(!autoOpen)return;
+  await msgsReady;
   // The file has already been uploaded to the workdir by the Tauri wrapper.
   // Just load it by name as if the user selected it.
-  try{
-    const f=new FormData();f.append('container',autoOpen);
-    const r=await(await fetch('/api/info',{method:'POST',body:f})).json();
-    if(!r.success){toast('Could not open '+autoOpen+': '+r.error,'error');return}
-    cName=autoOpen;cInfo=r.data;cState=cInfo.State;
-    if(cState==='sealed'){
-      const ef=new FormData();ef.append('container',cName);ef.append('passphrase','');ef.append('ignore_expiry','true');
-      await fetch('/api/extract',{method:'POST',body:ef});
-    }
-    enterWS();
-  }catch(e){console.error('Auto-open failed:',e)}
+  try{await openContainer(autoOpen)}
+  catch(e){console.error('Auto-open failed:',e)}
 })();
 </script>
 </body>
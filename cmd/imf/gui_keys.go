@@ -0,0 +1,215 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/keyring"
+)
+
+// passphraseError is a protected key's missing or wrong passphrase. The GUI
+// answers it with 401 so that the browser asks for the passphrase.
+type passphraseError struct{ err error }
+
+func (e passphraseError) Error() string { return e.err.Error() }
+func (e passphraseError) Unwrap() error { return e.err }
+
+// keyringPrivateKey returns the private key stored in kr under name,
+// decrypting it with passphrase if it is protected. A key held only as a
+// public key yields keyring.ErrNoPrivateKey.
+func keyringPrivateKey(kr *keyring.Keyring, name, passphrase string) (ed25519.PrivateKey, error) {
+	data, err := kr.PrivateKeyFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer imfcrypto.Wipe(data)
+	return parsePrivateKeyFile(data, passphrase)
+}
+
+// parsePrivateKeyFile parses a private key file, decrypting it with
+// passphrase if it is protected.
+func parsePrivateKeyFile(data []byte, passphrase string) (ed25519.PrivateKey, error) {
+	key, err := imfcrypto.ParsePrivateKeyPEM(data)
+	if !errors.Is(err, imfcrypto.ErrKeyProtected) {
+		return key, err
+	}
+	if passphrase == "" {
+		return nil, passphraseError{errors.New("Key is passphrase-protected")}
+	}
+	key, err = imfcrypto.ParseEncryptedPrivateKeyPEM(data, passphrase)
+	if err != nil {
+		return nil, passphraseError{err}
+	}
+	return key, nil
+}
+
+// handleImportKey adds the uploaded "key" file to the keyring under the
+// "name" form field, as "imf key add" does. A private key file is stored as
+// given, so a protected one stays protected; its "passphrase" is needed only
+// to work out the public key. A public key file is stored on its own.
+func handleImportKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	file, _, err := r.FormFile("key")
+	if err != nil {
+		jsonError(w, "No key file provided", 400)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		jsonError(w, "Error reading key file", 500)
+		return
+	}
+	defer imfcrypto.Wipe(data)
+
+	var pub ed25519.PublicKey
+	var private []byte
+	priv, err := parsePrivateKeyFile(data, r.FormValue("passphrase"))
+	var pe passphraseError
+	switch {
+	case errors.As(err, &pe):
+		jsonError(w, err.Error(), 401)
+		return
+	case err == nil:
+		pub, private = priv.Public().(ed25519.PublicKey), data
+		defer imfcrypto.Wipe(priv)
+	default:
+		if pub, err = imfcrypto.ParsePublicKeyPEM(data); err != nil {
+			jsonError(w, "Could not parse key file — must be an Ed25519 PEM or OpenSSH key", 400)
+			return
+		}
+	}
+
+	kr, err := keyring.OpenDefault()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	name := r.FormValue("name")
+	key, err := kr.Add(name, pub, private)
+	if err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	jsonSuccess(w, "Key imported to keyring as "+name, map[string]string{"fingerprint": key.Fingerprint})
+}
+
+// exportConfirmTimeout is how long the code that confirms a private key
+// export stays valid.
+const exportConfirmTimeout = 2 * time.Minute
+
+// newExportCode returns a random eight-digit code confirming a private key
+// export. A wrong guess discards it, so it need not be longer.
+func newExportCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(100000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08d", n), nil
+}
+
+// handleExportKey downloads a public key as a .pem file: the session's key,
+// or with the "name" field the keyring key of that name.
+//
+// With "private" set to "true" it downloads the session's private key
+// instead. That takes two POSTs: the first prints a single-use code on the
+// terminal running the GUI and is answered with 428, and the second sends
+// the code the user types in as "confirm". The code never passes through
+// the browser, so a script that has the page's token still cannot export
+// the key. This is the only way keys leave memory. In the desktop app, which
+// has no terminal to show the code, it is refused.
+func handleExportKey(w http.ResponseWriter, r *http.Request) {
+	s := session(r)
+	if r.FormValue("private") != "true" {
+		s.keyMu.Lock()
+		pub := s.PublicKey
+		s.keyMu.Unlock()
+		filename := "imf_public.pem"
+		if name := r.FormValue("name"); name != "" {
+			kr, err := keyring.OpenDefault()
+			if err != nil {
+				jsonError(w, err.Error(), 500)
+				return
+			}
+			key, err := kr.Get(name)
+			if err != nil {
+				jsonError(w, err.Error(), 404)
+				return
+			}
+			pub, filename = key.PublicKey, name+".pub.pem"
+		}
+		if pub == nil {
+			http.Error(w, "No key to export", 400)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-pem-file")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+		w.Write(imfcrypto.MarshalPublicKeyPEM(pub))
+		return
+	}
+
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+	if appWindow {
+		jsonError(w, "The desktop app cannot show the code confirming a private key export; "+
+			"save the key to the keyring and run imf key export -private", http.StatusForbidden)
+		return
+	}
+	s.keyMu.Lock()
+	if s.PrivateKey == nil {
+		s.keyMu.Unlock()
+		jsonError(w, "No key to export", 400)
+		return
+	}
+	fingerprint := imfcrypto.Fingerprint(s.PublicKey)
+	confirm := strings.TrimSpace(r.FormValue("confirm"))
+	if confirm == "" {
+		code, err := newExportCode()
+		if err != nil {
+			s.keyMu.Unlock()
+			jsonError(w, err.Error(), 500)
+			return
+		}
+		s.exportCode, s.exportCodeAt = code, time.Now()
+		s.keyMu.Unlock()
+		fmt.Fprintf(os.Stderr, "Code to confirm exporting the private key %s: %s\n", fingerprint[:16], code)
+		jsonErrorData(w, "Enter the code printed in the terminal running imf gui", http.StatusPreconditionRequired,
+			map[string]string{"fingerprint": fingerprint})
+		return
+	}
+	ok := s.exportCode != "" && time.Since(s.exportCodeAt) <= exportConfirmTimeout &&
+		subtle.ConstantTimeCompare([]byte(confirm), []byte(s.exportCode)) == 1
+	s.exportCode = ""
+	var pemData []byte
+	if ok {
+		pemData = imfcrypto.MarshalPrivateKeyPEM(s.PrivateKey)
+	}
+	s.keyMu.Unlock()
+	if !ok {
+		jsonError(w, "Wrong or expired code; export the key again for a new one", http.StatusForbidden)
+		return
+	}
+	defer imfcrypto.Wipe(pemData)
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"imf_private.pem\"")
+	w.Write(pemData)
+}
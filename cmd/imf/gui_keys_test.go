@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/keyring"
+)
+
+// inSession returns req to run in session s.
+func inSession(req *http.Request, s *guiState) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), sessionKey{}, s))
+}
+
+// saveKey asks handleSaveKey, in session s, to save the key as name, with
+// passphrase if it is not empty.
+func saveKey(t *testing.T, s *guiState, name, passphrase string) {
+	t.Helper()
+	form := url.Values{"name": {name}}
+	if passphrase != "" {
+		form.Set("passphrase", passphrase)
+	}
+	req := httptest.NewRequest("POST", "/api/save-key", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handleSaveKey(rec, inSession(req, s))
+	if rec.Code != 200 {
+		t.Fatalf("save %s: status = %d: %s", name, rec.Code, rec.Body)
+	}
+}
+
+func TestSaveKeyProtection(t *testing.T) {
+	t.Setenv("IMF_KEYRING", t.TempDir())
+	kr, err := keyring.OpenDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A key loaded from a protected file is saved as that file.
+	kp, _ := imfcrypto.GenerateKeyPair()
+	file, err := imfcrypto.MarshalEncryptedPrivateKeyPEM(kp.PrivateKey, "file-pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("key", "imf_private.pem")
+	fw.Write(file)
+	mw.WriteField("passphrase", "file-pw")
+	mw.Close()
+	req := httptest.NewRequest("POST", "/api/load-key", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	s := &guiState{}
+	rec := httptest.NewRecorder()
+	handleLoadKey(rec, inSession(req, s))
+	if rec.Code != 200 {
+		t.Fatalf("load: status = %d: %s", rec.Code, rec.Body)
+	}
+	saveKey(t, s, "loaded", "")
+	if stored, _ := kr.PrivateKeyFile("loaded"); !bytes.Equal(stored, file) {
+		t.Fatal("a protected key was not saved as its protected file")
+	}
+
+	// Any other key is protected by the passphrase given, if any.
+	key, signer := newSessionKey(t)
+	s.setKey(key, signer, signer.Public(), "")
+	saveKey(t, s, "protected", "new-pw")
+	stored, _ := kr.PrivateKeyFile("protected")
+	if !imfcrypto.IsEncryptedPrivateKeyPEM(stored) {
+		t.Fatal("key saved with a passphrase is not protected")
+	}
+	if got, err := imfcrypto.ParseEncryptedPrivateKeyPEM(stored, "new-pw"); err != nil || !got.Equal(key) {
+		t.Fatalf("saved key does not open with its passphrase: %v", err)
+	}
+	saveKey(t, s, "plain", "")
+	if stored, _ := kr.PrivateKeyFile("plain"); imfcrypto.IsEncryptedPrivateKeyPEM(stored) {
+		t.Fatal("key saved without a passphrase is protected")
+	}
+}
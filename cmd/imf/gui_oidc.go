@@ -0,0 +1,296 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oidcClient signs users in with an OpenID Connect provider, using the
+// authorization code flow:
+//
+//	GET /auth/login?next=PATH   redirects to the provider
+//	GET /auth/callback          the provider redirects back here with a code
+//	GET /auth/logout            forgets the sign-in
+//
+// The ID token comes straight from the provider's token endpoint over TLS,
+// so, as OpenID Connect Core 3.1.3.7 allows, its claims are checked but not
+// its signature.
+type oidcClient struct {
+	issuer, clientID, secret string
+	authURL, tokenURL        string
+	client                   *http.Client
+
+	mu      sync.Mutex
+	pending map[string]oidcPending // sign-ins sent to the provider, by state
+}
+
+// oidcPending is a sign-in waiting for the provider to send the browser back.
+type oidcPending struct {
+	nonce, next string
+	at          time.Time
+}
+
+// oidcLogin is a signed-in browser.
+type oidcLogin struct {
+	user    string
+	expires time.Time
+}
+
+// oidcLoginTimeout is how long the provider may take to send a browser back.
+const oidcLoginTimeout = 10 * time.Minute
+
+// newOIDCClient looks up issuer's endpoints in its discovery document.
+func newOIDCClient(issuer, clientID, secret string) (*oidcClient, error) {
+	if clientID == "" {
+		return nil, errors.New("-oidc-client-id is required with -auth oidc")
+	}
+	c := &oidcClient{
+		issuer:   strings.TrimSuffix(issuer, "/"),
+		clientID: clientID,
+		secret:   secret,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		pending:  make(map[string]oidcPending),
+	}
+	resp, err := c.client.Get(c.issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, fmt.Errorf("fetching OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching OIDC discovery document: %s", resp.Status)
+	}
+	var doc struct {
+		Issuer   string `json:"issuer"`
+		AuthURL  string `json:"authorization_endpoint"`
+		TokenURL string `json:"token_endpoint"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing OIDC discovery document: %w", err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != c.issuer || doc.AuthURL == "" || doc.TokenURL == "" {
+		return nil, fmt.Errorf("OIDC discovery document does not match issuer %s", issuer)
+	}
+	c.authURL, c.tokenURL = doc.AuthURL, doc.TokenURL
+	return c, nil
+}
+
+// redirectURL is where the provider sends the browser back to.
+func redirectURL(r *http.Request) string {
+	return "https://" + r.Host + "/auth/callback"
+}
+
+func (c *oidcClient) serveHTTP(g *remoteGUI, w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/auth/login":
+		c.login(w, r)
+	case "/auth/callback":
+		c.callback(g, w, r)
+	case "/auth/logout":
+		if ck, err := r.Cookie(loginCookie); err == nil {
+			g.mu.Lock()
+			if l, ok := g.logins[ck.Value]; ok {
+				g.audit.log(r, l.user, "logout", http.StatusOK)
+				delete(g.logins, ck.Value)
+			}
+			g.mu.Unlock()
+		}
+		http.SetCookie(w, &http.Cookie{Name: loginCookie, Path: "/", MaxAge: -1, Secure: true, HttpOnly: true})
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "Signed out.")
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// login sends the browser to the provider to sign in.
+func (c *oidcClient) login(w http.ResponseWriter, r *http.Request) {
+	state, err := newToken()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	nonce, err := newToken()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	next := r.URL.Query().Get("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		next = "/"
+	}
+	c.mu.Lock()
+	for s, p := range c.pending {
+		if time.Since(p.at) > oidcLoginTimeout {
+			delete(c.pending, s)
+		}
+	}
+	c.pending[state] = oidcPending{nonce: nonce, next: next, at: time.Now()}
+	c.mu.Unlock()
+
+	q := url.Values{
+		"response_type": {"code"},
+		"client_id":     {c.clientID},
+		"redirect_uri":  {redirectURL(r)},
+		"scope":         {"openid profile email"},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	sep := "?"
+	if strings.Contains(c.authURL, "?") {
+		sep = "&"
+	}
+	http.Redirect(w, r, c.authURL+sep+q.Encode(), http.StatusFound)
+}
+
+// callback finishes a sign-in: it trades the code for an ID token, checks
+// it, and sets the login cookie.
+func (c *oidcClient) callback(g *remoteGUI, w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	state := q.Get("state")
+	c.mu.Lock()
+	p, ok := c.pending[state]
+	delete(c.pending, state)
+	c.mu.Unlock()
+	if !ok || time.Since(p.at) > oidcLoginTimeout {
+		http.Error(w, "Sign-in expired; try again", http.StatusBadRequest)
+		return
+	}
+	if e := q.Get("error"); e != "" {
+		g.audit.log(r, "", "login failed: "+e, http.StatusUnauthorized)
+		http.Error(w, "Sign-in failed: "+e, http.StatusUnauthorized)
+		return
+	}
+	user, err := c.exchange(r, q.Get("code"), p.nonce)
+	if err != nil {
+		g.audit.log(r, "", "login failed: "+err.Error(), http.StatusUnauthorized)
+		http.Error(w, "Sign-in failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	token, err := newToken()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	g.mu.Lock()
+	for t, l := range g.logins {
+		if time.Now().After(l.expires) {
+			delete(g.logins, t)
+		}
+	}
+	g.logins[token] = oidcLogin{user: user, expires: time.Now().Add(sessionTimeout)}
+	g.mu.Unlock()
+	g.audit.log(r, user, "login", http.StatusOK)
+
+	// Lax rather than Strict, so that the cookie comes with the redirect
+	// back from the provider.
+	http.SetCookie(w, &http.Cookie{
+		Name:     loginCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(sessionTimeout / time.Second),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, p.next, http.StatusFound)
+}
+
+// exchange trades code for an ID token at the token endpoint and returns
+// the user it names: their preferred user name, else their email address,
+// else their subject identifier.
+func (c *oidcClient) exchange(r *http.Request, code, nonce string) (string, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURL(r)},
+	}
+	req, err := http.NewRequestWithContext(r.Context(), "POST", c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.secret))
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint: %s", resp.Status)
+	}
+	var tok struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tok); err != nil {
+		return "", fmt.Errorf("token endpoint: %w", err)
+	}
+
+	parts := strings.Split(tok.IDToken, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed ID token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.New("malformed ID token")
+	}
+	var claims struct {
+		Issuer   string          `json:"iss"`
+		Audience json.RawMessage `json:"aud"`
+		Expires  int64           `json:"exp"`
+		Nonce    string          `json:"nonce"`
+		Subject  string          `json:"sub"`
+		Email    string          `json:"email"`
+		Username string          `json:"preferred_username"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", errors.New("malformed ID token")
+	}
+	var aud []string
+	if json.Unmarshal(claims.Audience, &aud) != nil {
+		aud = []string{""}
+		json.Unmarshal(claims.Audience, &aud[0])
+	}
+	switch {
+	case strings.TrimSuffix(claims.Issuer, "/") != c.issuer:
+		return "", errors.New("ID token is from another issuer")
+	case !slices.Contains(aud, c.clientID):
+		return "", errors.New("ID token is for another client")
+	case time.Now().After(time.Unix(claims.Expires, 0)):
+		return "", errors.New("ID token has expired")
+	case claims.Nonce != nonce:
+		return "", errors.New("ID token nonce does not match")
+	}
+	for _, user := range []string{claims.Username, claims.Email, claims.Subject} {
+		if user != "" {
+			return user, nil
+		}
+	}
+	return "", errors.New("ID token names no user")
+}
+
+// loginUser returns the user the request's login cookie signs in, or "".
+func (g *remoteGUI) loginUser(r *http.Request) string {
+	ck, err := r.Cookie(loginCookie)
+	if err != nil {
+		return ""
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	l, ok := g.logins[ck.Value]
+	if !ok || time.Now().After(l.expires) {
+		return ""
+	}
+	return l.user
+}
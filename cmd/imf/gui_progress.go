@@ -0,0 +1,288 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+// progressEvent is one progress report sent to the browser over /ws.
+type progressEvent struct {
+	Op       string  `json:"op"`              // "seal", "extract", or "anchor"
+	Stage    string  `json:"stage,omitempty"` // a container.Stage* or stageCalendars
+	Label    string  `json:"label,omitempty"` // the stage's name for people, in English
+	Done     int64   `json:"done"`
+	Total    int64   `json:"total"`
+	Percent  float64 `json:"percent"`
+	Finished bool    `json:"finished,omitempty"` // the operation is over, however it ended
+}
+
+// progressHub passes a session's progress events to each of its open
+// sockets. The zero value is ready to use.
+type progressHub struct {
+	mu   sync.Mutex
+	subs map[chan progressEvent]struct{}
+}
+
+// subscribe returns a channel receiving the hub's events from now on.
+func (h *progressHub) subscribe() chan progressEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs == nil {
+		h.subs = make(map[chan progressEvent]struct{})
+	}
+	ch := make(chan progressEvent, 64)
+	h.subs[ch] = struct{}{}
+	return ch
+}
+
+func (h *progressHub) unsubscribe(ch chan progressEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs, ch)
+}
+
+// publish sends ev to every subscriber, dropping it for one whose socket
+// has fallen behind rather than holding up the operation.
+func (h *progressHub) publish(ev progressEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// progressReporter publishes one operation's progress to a hub, no more
+// often than progressInterval within a stage, as progressBar draws it.
+type progressReporter struct {
+	hub *progressHub
+	op  string
+
+	mu    sync.Mutex
+	stage string
+	sent  time.Time
+}
+
+// reporter starts reporting the progress of op.
+func (h *progressHub) reporter(op string) *progressReporter {
+	return &progressReporter{hub: h, op: op}
+}
+
+// progress returns the reporter's update function for the container package.
+func (p *progressReporter) progress() container.Progress {
+	return p.update
+}
+
+// calendars returns a function reporting the calendars answering an anchor
+// submission.
+func (p *progressReporter) calendars() func(answered, total int) {
+	return func(answered, total int) {
+		p.update(stageCalendars, int64(answered), int64(total))
+	}
+}
+
+func (p *progressReporter) update(stage string, done, total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if stage == p.stage && done < total && now.Sub(p.sent) < progressInterval {
+		return
+	}
+	p.stage, p.sent = stage, now
+	frac := 1.0
+	if total > 0 {
+		frac = min(float64(done)/float64(total), 1)
+	}
+	p.hub.publish(progressEvent{Op: p.op, Stage: stage, Label: stageLabels[stage], Done: done, Total: total, Percent: frac * 100})
+}
+
+// finish tells the browser the operation is over.
+func (p *progressReporter) finish() {
+	p.hub.publish(progressEvent{Op: p.op, Finished: true})
+}
+
+// wsGUID is the key suffix RFC 6455 hashes into Sec-WebSocket-Accept.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used here.
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xA
+)
+
+// handleProgressSocket upgrades /ws to a WebSocket and streams the
+// session's progress events to it as JSON text messages until either side
+// closes it. It runs outside withSessions, which would count an open
+// socket as activity and keep the session's key from ever being wiped.
+func (m *sessionManager) handleProgressSocket(w http.ResponseWriter, r *http.Request) {
+	var s *guiState
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		s = m.get(c.Value)
+	}
+	if s == nil {
+		http.Error(w, "No session", http.StatusForbidden)
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Expected a WebSocket handshake", http.StatusBadRequest)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sum := sha1.Sum([]byte(key + wsGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if rw.Flush() != nil {
+		return
+	}
+
+	events := s.progress.subscribe()
+	defer s.progress.unsubscribe(events)
+
+	// The browser sends nothing but pings and a close; read them so both
+	// are answered and a dropped connection is noticed.
+	var wmu sync.Mutex
+	send := func(op byte, payload []byte) error {
+		wmu.Lock()
+		defer wmu.Unlock()
+		return writeWSFrame(conn, op, payload)
+	}
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			op, payload, err := readWSFrame(rw.Reader)
+			if err != nil {
+				return
+			}
+			switch op {
+			case wsClose:
+				send(wsClose, nil)
+				return
+			case wsPing:
+				send(wsPong, payload)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case ev := <-events:
+			data, _ := json.Marshal(ev)
+			if send(wsText, data) != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// headerHasToken reports whether the comma-separated header name lists
+// token, ignoring case.
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeWSFrame writes payload as one unmasked frame, as a server must.
+func writeWSFrame(conn net.Conn, op byte, payload []byte) error {
+	hdr := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, byte(n))
+	case n <= 0xFFFF:
+		hdr = append(hdr, 126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := conn.Write(append(hdr, payload...))
+	return err
+}
+
+// maxWSFrame bounds a frame read from the browser, which has no reason to
+// send more than a close or a ping.
+const maxWSFrame = 4096
+
+// readWSFrame reads one frame from the browser, which must mask it.
+// Fragmented messages are not reassembled; only control frames matter here.
+func readWSFrame(r *bufio.Reader) (op byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	op = hdr[0] & 0x0F
+	if hdr[1]&0x80 == 0 {
+		return 0, nil, errors.New("unmasked client frame")
+	}
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if n > maxWSFrame {
+		return 0, nil, errors.New("frame too large")
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
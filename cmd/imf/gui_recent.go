@@ -0,0 +1,136 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// recentFile names the file in the GUI's working directory that remembers
+// the containers recently created, opened, or verified there, so the launch
+// screen can show where they are and reopen them.
+const recentFile = ".imf-recent.json"
+
+// maxRecent bounds the history; older entries are forgotten.
+const maxRecent = 20
+
+// recentEntry is one container in the history.
+type recentEntry struct {
+	Name       string     `json:"name"`
+	Path       string     `json:"path"`
+	State      string     `json:"state"`                 // "open" or "sealed", as last seen
+	Verify     string     `json:"verify,omitempty"`      // "pass" or "fail"; empty if never verified
+	VerifyNote string     `json:"verify_note,omitempty"` // the last verification's message or error
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	UsedAt     time.Time  `json:"used_at"`
+	Missing    bool       `json:"missing,omitempty"` // set when listing: the file is gone
+}
+
+// recentMu serializes updates to the history file.
+var recentMu sync.Mutex
+
+// readRecent returns the history kept in dir.
+func readRecent(dir string) []recentEntry {
+	var list []recentEntry
+	if data, err := os.ReadFile(filepath.Join(dir, recentFile)); err == nil {
+		json.Unmarshal(data, &list)
+	}
+	return list
+}
+
+// writeRecent replaces the history file in dir, through a temporary file so
+// that a crash cannot leave it half written.
+func writeRecent(dir string, list []recentEntry) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, recentFile)
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// recordRecent moves the container at path to the top of the history,
+// adding it if need be, and lets update record what just happened to it.
+// The history is a convenience: failing to write it does not fail the
+// request that triggered it.
+func recordRecent(path string, update func(e *recentEntry)) {
+	recentMu.Lock()
+	defer recentMu.Unlock()
+
+	e := recentEntry{Name: filepath.Base(path), Path: path}
+	dir := filepath.Dir(path)
+	list := readRecent(dir)
+	for i, o := range list {
+		if o.Path == path {
+			e = o
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	e.UsedAt = time.Now()
+	update(&e)
+	list = append([]recentEntry{e}, list...)
+	if len(list) > maxRecent {
+		list = list[:maxRecent]
+	}
+	writeRecent(dir, list)
+}
+
+// recordVerify records the outcome of verifying the container at path.
+func recordVerify(path string, err error, message string) {
+	recordRecent(path, func(e *recentEntry) {
+		now := time.Now()
+		e.Verify, e.VerifyNote, e.VerifiedAt = "pass", message, &now
+		if err != nil {
+			e.Verify, e.VerifyNote = "fail", err.Error()
+		}
+	})
+}
+
+// handleRecent lists the history, newest first, marking containers that are
+// no longer where they were. A POST with "remove" drops that container's
+// entry instead.
+func handleRecent(w http.ResponseWriter, r *http.Request) {
+	dir := workDir(session(r))
+	if r.Method == "POST" {
+		name := r.FormValue("remove")
+		recentMu.Lock()
+		list := readRecent(dir)
+		for i, e := range list {
+			if e.Name == name {
+				list = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		err := writeRecent(dir, list)
+		recentMu.Unlock()
+		if err != nil {
+			jsonError(w, err.Error(), 500)
+			return
+		}
+		jsonSuccess(w, "", nil)
+		return
+	}
+
+	recentMu.Lock()
+	list := readRecent(dir)
+	recentMu.Unlock()
+	for i := range list {
+		if _, err := os.Stat(list[i].Path); err != nil {
+			list[i].Missing = true
+		}
+	}
+	if list == nil {
+		list = []recentEntry{}
+	}
+	jsonSuccess(w, "", list)
+}
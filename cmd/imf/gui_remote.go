@@ -0,0 +1,352 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// By default the GUI listens only on localhost, for the browser of the
+// person who started it. "imf gui -listen ADDR" serves it to other machines
+// instead, for a team sharing one server. That takes TLS and a way for users
+// to sign in: "basic" checks HTTP basic auth against an htpasswd file of
+// bcrypt hashes, and "oidc" signs users in with an OpenID Connect provider.
+// Each user then works in a directory of their own, under users/ in the
+// working directory, and every request that changes or fetches a container
+// is written to an audit log. Settings, the server's keyring and its HSM are
+// not offered to remote users; they bring or generate their own keys.
+
+// remoteGUI is how the GUI serves other machines. It is nil when the GUI
+// listens only on localhost.
+type remoteGUI struct {
+	auth  string            // "basic" or "oidc"
+	users map[string][]byte // for basic: each user's bcrypt password hash
+	oidc  *oidcClient       // for oidc
+	audit *auditLog
+
+	mu       sync.Mutex
+	accepted map[string][sha256.Size]byte // for basic: the password last accepted for each user, hashed, so bcrypt runs once
+	logins   map[string]oidcLogin         // for oidc: signed-in browsers, by login cookie
+}
+
+// remote is set by runGUI when the GUI is started with -listen.
+var remote *remoteGUI
+
+// errNotLocal refuses a remote user what only the server's owner may use.
+var errNotLocal = errors.New("Not available on a shared server")
+
+// loginCookie names the cookie holding an OIDC sign-in.
+const loginCookie = "imf_login"
+
+// loadUsers reads an htpasswd file: one "name:hash" per line, with blank
+// lines and lines starting with '#' ignored. Only bcrypt hashes, as made by
+// "htpasswd -B", are accepted.
+func loadUsers(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	users := make(map[string][]byte)
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, hash, ok := strings.Cut(line, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%s:%d: expected name:hash", path, n)
+		}
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("%s:%d: not a bcrypt hash; create it with htpasswd -B", path, n)
+		}
+		users[name] = []byte(hash)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%s: no users", path)
+	}
+	return users, nil
+}
+
+// remoteOptions are the "imf gui" flags that set up a remoteGUI.
+type remoteOptions struct {
+	dir                             string // the working directory
+	listen, tlsCert, tlsKey         string
+	auth, users                     string
+	oidcIssuer, oidcClientID, audit string
+}
+
+// newRemoteGUI checks opts and sets up signing in and the audit log. It
+// returns nil if no -auth is given, for the GUI to serve this machine
+// alone: off localhost, it refuses to run without TLS and -auth, so that a
+// team's containers are never served in the clear or to anyone who asks.
+func newRemoteGUI(opts remoteOptions) (*remoteGUI, error) {
+	if opts.listen != "" && !loopbackListen(opts.listen) && (opts.tlsCert == "" || opts.auth == "") {
+		return nil, errors.New("-listen off localhost requires -tls-cert, -tls-key, and -auth")
+	}
+	if (opts.tlsCert == "") != (opts.tlsKey == "") {
+		return nil, errors.New("-tls-cert and -tls-key must be given together")
+	}
+	g := &remoteGUI{
+		auth:     opts.auth,
+		accepted: make(map[string][sha256.Size]byte),
+		logins:   make(map[string]oidcLogin),
+	}
+	var err error
+	switch opts.auth {
+	case "":
+		return nil, nil
+	case "basic":
+		if opts.users == "" {
+			return nil, errors.New("-auth basic requires -users")
+		}
+		if g.users, err = loadUsers(opts.users); err != nil {
+			return nil, err
+		}
+	case "oidc":
+		if opts.oidcIssuer == "" || opts.tlsCert == "" {
+			return nil, errors.New("-auth oidc requires -oidc-issuer and TLS")
+		}
+		if g.oidc, err = newOIDCClient(opts.oidcIssuer, opts.oidcClientID, os.Getenv("IMF_OIDC_CLIENT_SECRET")); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown -auth %q: use basic or oidc", opts.auth)
+	}
+
+	path := opts.audit
+	if path == "" {
+		path = filepath.Join(opts.dir, auditFile)
+	}
+	if g.audit, err = openAuditLog(path); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// checkPassword reports whether password is user's.
+func (g *remoteGUI) checkPassword(user, password string) bool {
+	hash, ok := g.users[user]
+	if !ok {
+		return false
+	}
+	sum := sha256.Sum256([]byte(password))
+	g.mu.Lock()
+	last, seen := g.accepted[user]
+	g.mu.Unlock()
+	if seen && subtle.ConstantTimeCompare(sum[:], last[:]) == 1 {
+		return true
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return false
+	}
+	g.mu.Lock()
+	g.accepted[user] = sum
+	g.mu.Unlock()
+	return true
+}
+
+type userKey struct{}
+
+// requestUser returns the user a request was made by, or "" when the GUI
+// serves only localhost.
+func requestUser(r *http.Request) string {
+	user, _ := r.Context().Value(userKey{}).(string)
+	return user
+}
+
+// withAuth wraps the GUI's handlers so that every request must come from a
+// signed-in user, whose name the handlers get from requestUser. With oidc,
+// a browser that is not signed in is sent to the provider, except for API
+// requests, which are refused.
+func (g *remoteGUI) withAuth(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user string
+		switch g.auth {
+		case "basic":
+			name, password, ok := r.BasicAuth()
+			if !ok || !g.checkPassword(name, password) {
+				if ok {
+					g.audit.log(r, name, "login failed", http.StatusUnauthorized)
+				}
+				w.Header().Set("WWW-Authenticate", `Basic realm="IMF", charset="UTF-8"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			user = name
+		case "oidc":
+			if strings.HasPrefix(r.URL.Path, "/auth/") {
+				g.oidc.serveHTTP(g, w, r)
+				return
+			}
+			if user = g.loginUser(r); user == "" {
+				if strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/ws" {
+					jsonError(w, "Not signed in", http.StatusUnauthorized)
+					return
+				}
+				http.Redirect(w, r, "/auth/login?next="+r.URL.EscapedPath(), http.StatusFound)
+				return
+			}
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+// localOnly wraps a handler that works with the server's own settings or
+// keys, so that it is refused when the GUI serves other machines.
+func localOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if remote != nil {
+			jsonError(w, errNotLocal.Error(), http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// userDir returns user's directory within the working directory base. A
+// name that is not safe as a file name is changed, and a hash of it added
+// so that two names cannot end up in the same directory.
+func userDir(base, user string) string {
+	safe := strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.ContainsRune("._@-", c):
+			return c
+		}
+		return '_'
+	}, user)
+	if safe != user || strings.HasPrefix(safe, ".") {
+		sum := sha256.Sum256([]byte(user))
+		safe = strings.TrimLeft(safe, ".") + "-" + hex.EncodeToString(sum[:4])
+	}
+	return filepath.Join(base, "users", safe)
+}
+
+// loopbackListen reports whether addr, a -listen address, is on this
+// machine only.
+func loopbackListen(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	return loopbackHost(host)
+}
+
+// auditFile names the default audit log, in the working directory.
+const auditFile = ".imf-audit.log"
+
+// auditLog appends one JSON object per line for each request it is given.
+type auditLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// auditEntry is one line of the audit log.
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user,omitempty"`
+	Addr      string    `json:"addr"`
+	Action    string    `json:"action"` // the request's method and path, or a sign-in event
+	Container string    `json:"container,omitempty"`
+	Files     []string  `json:"files,omitempty"`
+	Status    int       `json:"status"`
+}
+
+func openAuditLog(path string) (*auditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	return &auditLog{f: f}, nil
+}
+
+// log records action by user. A nil log records nothing.
+func (a *auditLog) log(r *http.Request, user, action string, status int) {
+	if a == nil {
+		return
+	}
+	e := auditEntry{Time: time.Now().UTC(), User: user, Addr: r.RemoteAddr, Action: action, Status: status}
+	form := r.Form
+	if form == nil {
+		form = r.URL.Query()
+	}
+	// /api/create names its container "name".
+	if e.Container = form.Get("container"); e.Container == "" && r.URL.Path == "/api/create" {
+		e.Container = form.Get("name")
+	}
+	e.Files = form["file"]
+	data, _ := json.Marshal(e)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.f.Write(append(data, '\n'))
+}
+
+// audited reports whether a request is written to the audit log: every
+// one that changes something, and those that fetch a container, its files,
+// or a key. Uploaded chunks are left to the upload's finish.
+func audited(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/api/upload-chunk":
+		return false
+	case "/api/download", "/api/download-zip", "/api/serve-file", "/api/preview", "/api/export-key":
+		return true
+	}
+	return strings.HasPrefix(r.URL.Path, "/api/") && r.Method != "GET" && r.Method != "HEAD"
+}
+
+// statusWriter remembers the status a handler answered with.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// withAudit wraps the GUI's handlers so that the requests audited picks
+// are written to a once they have been answered.
+func (a *auditLog) withAudit(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a == nil || !audited(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		sw := &statusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, r)
+		a.log(r, requestUser(r), r.Method+" "+r.URL.Path, sw.status)
+	})
+}
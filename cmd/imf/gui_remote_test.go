@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newBasicRemote returns a remoteGUI signing in alice and bob, whose
+// passwords are their names reversed, with its audit log in a temporary
+// directory.
+func newBasicRemote(t *testing.T) (*remoteGUI, string) {
+	t.Helper()
+	users := make(map[string][]byte)
+	for name, password := range map[string]string{"alice": "ecila", "bob": "bob-secret"} {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		users[name] = hash
+	}
+	auditPath := filepath.Join(t.TempDir(), auditFile)
+	audit, err := openAuditLog(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { audit.f.Close() })
+	return &remoteGUI{auth: "basic", users: users, audit: audit, accepted: make(map[string][32]byte)}, auditPath
+}
+
+// userHandler answers with the user a request was made by.
+var userHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(requestUser(r)))
+})
+
+func TestCheckPassword(t *testing.T) {
+	g, _ := newBasicRemote(t)
+	if !g.checkPassword("alice", "ecila") {
+		t.Fatal("right password refused")
+	}
+	// Once a password is accepted and remembered, another is still refused.
+	if g.checkPassword("alice", "wrong") {
+		t.Fatal("wrong password accepted after a right one")
+	}
+	if !g.checkPassword("alice", "ecila") {
+		t.Fatal("right password refused the second time")
+	}
+	if g.checkPassword("bob", "ecila") {
+		t.Fatal("alice's password accepted for bob")
+	}
+	if g.checkPassword("carol", "") || g.checkPassword("", "") {
+		t.Fatal("unknown user accepted")
+	}
+}
+
+func TestWithAuthBasic(t *testing.T) {
+	g, auditPath := newBasicRemote(t)
+	h := g.withAuth(userHandler)
+
+	req := httptest.NewRequest("GET", "/api/list", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("no credentials: status = %d, WWW-Authenticate %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+
+	req = httptest.NewRequest("GET", "/api/list", nil)
+	req.SetBasicAuth("alice", "wrong")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad password: status = %d, want 401", rec.Code)
+	}
+	data, _ := os.ReadFile(auditPath)
+	if !strings.Contains(string(data), `"action":"login failed"`) || !strings.Contains(string(data), `"user":"alice"`) {
+		t.Fatalf("failed login not audited: %s", data)
+	}
+
+	req = httptest.NewRequest("GET", "/api/list", nil)
+	req.SetBasicAuth("alice", "ecila")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != 200 || rec.Body.String() != "alice" {
+		t.Fatalf("good password: status = %d, user %q", rec.Code, rec.Body.String())
+	}
+}
+
+// newOIDCProvider serves a discovery document and a token endpoint whose
+// ID tokens name user and carry whatever nonce claims returns.
+func newOIDCProvider(t *testing.T, user string, nonce func() string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		claims, _ := json.Marshal(map[string]any{
+			"iss": srv.URL, "aud": "imf", "exp": time.Now().Add(time.Hour).Unix(),
+			"nonce": nonce(), "sub": "1234", "preferred_username": user,
+		})
+		idToken := "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".c2ln"
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// startLogin starts a sign-in through h and returns its state and nonce,
+// from the redirect to the provider.
+func startLogin(t *testing.T, h http.Handler, next string) (state, nonce string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/auth/login?next="+url.QueryEscape(next), nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login: status = %d, want 302", rec.Code)
+	}
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc.Query().Get("state"), loc.Query().Get("nonce")
+}
+
+func TestWithAuthOIDC(t *testing.T) {
+	var nonce string
+	provider := newOIDCProvider(t, "alice", func() string { return nonce })
+	client, err := newOIDCClient(provider.URL, "imf", "secret")
+	if err != nil {
+		t.Fatalf("newOIDCClient: %v", err)
+	}
+	g := &remoteGUI{auth: "oidc", oidc: client, logins: make(map[string]oidcLogin)}
+	h := g.withAuth(userHandler)
+
+	// Not signed in: the page is sent to sign in, the API refused.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusFound || !strings.HasPrefix(rec.Header().Get("Location"), "/auth/login") {
+		t.Fatalf("page: status = %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/api/seal", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("API: status = %d, want 401", rec.Code)
+	}
+
+	// A callback for a sign-in that was never started is refused.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/auth/callback?state=forged&code=good-code", nil))
+	if rec.Code != http.StatusBadRequest || len(rec.Result().Cookies()) != 0 {
+		t.Fatalf("forged state: status = %d, cookies %v", rec.Code, rec.Result().Cookies())
+	}
+
+	// An ID token with another sign-in's nonce is refused, and so is a
+	// code the provider does not accept.
+	state, _ := startLogin(t, h, "/")
+	nonce = "another"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/auth/callback?state="+state+"&code=good-code", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong nonce: status = %d, want 401", rec.Code)
+	}
+	state, nonce = startLogin(t, h, "/")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/auth/callback?state="+state+"&code=bad-code", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad code: status = %d, want 401", rec.Code)
+	}
+
+	// A good sign-in sets the login cookie and goes back to the page; a
+	// next pointing off the server is dropped.
+	state, nonce = startLogin(t, h, "//evil.example/")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/auth/callback?state="+state+"&code=good-code", nil))
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/" {
+		t.Fatalf("callback: status = %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	var login *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == loginCookie {
+			login = c
+		}
+	}
+	if login == nil || !login.HttpOnly || !login.Secure {
+		t.Fatalf("login cookie = %+v", login)
+	}
+
+	// The state is single-use.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/auth/callback?state="+state+"&code=good-code", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("replayed state: status = %d, want 400", rec.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/api/seal", nil)
+	req.AddCookie(login)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != 200 || rec.Body.String() != "alice" {
+		t.Fatalf("signed in: status = %d, user %q", rec.Code, rec.Body.String())
+	}
+	req = httptest.NewRequest("POST", "/api/seal", nil)
+	req.AddCookie(&http.Cookie{Name: loginCookie, Value: login.Value + "0"})
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("forged login cookie: status = %d, want 401", rec.Code)
+	}
+
+	// Signing out forgets the login.
+	req = httptest.NewRequest("GET", "/auth/logout", nil)
+	req.AddCookie(login)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	req = httptest.NewRequest("POST", "/api/seal", nil)
+	req.AddCookie(login)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("after logout: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestSessionsPerUser(t *testing.T) {
+	g, _ := newBasicRemote(t)
+	setRemote(t, g)
+	t.Setenv("TMPDIR", t.TempDir())
+	oldDir := guiDir
+	setWorkDir(t.TempDir())
+	t.Cleanup(func() { setWorkDir(oldDir) })
+
+	m := &sessionManager{sessions: make(map[string]*guiState)}
+	t.Cleanup(m.closeAll)
+	var got *guiState
+	h := g.withAuth(m.withSessions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = session(r)
+	})))
+	do := func(user, password string, cookie *http.Cookie) *http.Response {
+		req := httptest.NewRequest("GET", "/api/list", nil)
+		req.SetBasicAuth(user, password)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	resp := do("alice", "ecila", nil)
+	if len(resp.Cookies()) != 1 {
+		t.Fatalf("alice got cookies %v", resp.Cookies())
+	}
+	aliceCookie, alice := resp.Cookies()[0], got
+	if alice.User != "alice" {
+		t.Fatalf("session user = %q", alice.User)
+	}
+
+	// alice's cookie brings her back to her session, and bob, sending it,
+	// gets a session and a directory of his own.
+	do("alice", "ecila", aliceCookie)
+	if got != alice {
+		t.Fatal("alice's cookie did not find her session")
+	}
+	resp = do("bob", "bob-secret", aliceCookie)
+	if got == alice || got.User != "bob" {
+		t.Fatalf("bob got alice's session (user %q)", got.User)
+	}
+	if len(resp.Cookies()) != 1 || resp.Cookies()[0].Value == aliceCookie.Value {
+		t.Fatalf("bob was not given a session cookie of his own: %v", resp.Cookies())
+	}
+	if got.WorkDir == alice.WorkDir || workDir(got) == workDir(alice) {
+		t.Fatal("bob shares alice's directory")
+	}
+	if !strings.HasPrefix(workDir(got), guiDir) {
+		t.Fatalf("bob's directory %s is outside the working directory", workDir(got))
+	}
+
+	// alice's password does not get bob in as alice.
+	if resp := do("alice", "bob-secret", aliceCookie); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong password with alice's cookie: status = %d", resp.StatusCode)
+	}
+}
+
+func TestUserDir(t *testing.T) {
+	base := filepath.Join("srv", "imf")
+	if got := userDir(base, "alice"); got != filepath.Join(base, "users", "alice") {
+		t.Fatalf("userDir(alice) = %s", got)
+	}
+	seen := make(map[string]string)
+	for _, user := range []string{"alice", "a/b", "a_b", "a\\b", "..", ".alice", "../alice", "alice@example.com", "ALICE", "a b"} {
+		dir := userDir(base, user)
+		if filepath.Dir(dir) != filepath.Join(base, "users") {
+			t.Errorf("userDir(%q) = %s, not directly under users/", user, dir)
+		}
+		if strings.HasPrefix(filepath.Base(dir), ".") {
+			t.Errorf("userDir(%q) = %s is hidden", user, dir)
+		}
+		if other, ok := seen[dir]; ok {
+			t.Errorf("users %q and %q share %s", user, other, dir)
+		}
+		seen[dir] = user
+	}
+}
+
+func TestLocalOnly(t *testing.T) {
+	var calls int
+	h := localOnly(func(w http.ResponseWriter, r *http.Request) { calls++ })
+
+	setRemote(t, nil)
+	h(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/save-key", nil))
+	if calls != 1 {
+		t.Fatal("local request refused")
+	}
+
+	g, _ := newBasicRemote(t)
+	setRemote(t, g)
+	req := httptest.NewRequest("POST", "/api/save-key", nil)
+	req.SetBasicAuth("alice", "ecila")
+	rec := httptest.NewRecorder()
+	g.withAuth(h).ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || calls != 1 {
+		t.Fatalf("remote user: status = %d, handler called %d times", rec.Code, calls)
+	}
+	if !strings.Contains(rec.Body.String(), errNotLocal.Error()) {
+		t.Fatalf("body = %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,408 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// guiState holds one browser session's state. Each session, identified by
+// the imf_session cookie, has its own key and its own work directory, so two
+// browsers or two people on a shared machine do not see or replace each
+// other's key or extracted files.
+type guiState struct {
+	ID      string
+	User    string // the signed-in user on a shared server; see remoteGUI
+	WorkDir string // private directory for uploads and extracted files; removed with the session
+
+	// The key, guarded by keyMu. Requests in a session run concurrently, so
+	// each takes keyMu to read or change the key, and uses useSigner to sign.
+	keyMu      sync.Mutex
+	PrivateKey ed25519.PrivateKey // nil when the key lives in an HSM or only a public key is loaded
+	Signer     imfcrypto.Signer   // signs seals; nil if only a public key is loaded
+	PublicKey  ed25519.PublicKey
+	KeyName    string // keyring name of the loaded key; empty if it is not in the keyring
+	KeyLoaded  bool
+	KeyExpired bool   // the key was wiped after the session sat idle
+	keyFile    []byte // the passphrase-protected file the key was loaded from, if any; see keepKeyFile
+
+	exportCode   string    // single-use code confirming a private key export; see handleExportKey
+	exportCodeAt time.Time // when exportCode was issued
+	signing      int       // requests signing with Signer; see useSigner
+	retired      []func()  // releases of keys replaced while a request was signing
+
+	progress progressHub // progress of the session's operations, for its /ws sockets
+
+	uploadMu   sync.Mutex          // guards the upload fields below; not held while an upload's bytes are copied
+	uploads    map[string]int64    // the size of each upload in progress, by ID; see gui_upload.go
+	uploading  map[string]struct{} // uploads a request is writing to or adding, by ID; see claimUploads
+	uploadedAt time.Time           // when an upload was last started or added to
+
+	mu       sync.RWMutex // read-held by every request; write-held to close the session
+	closed   bool         // the session was dropped; set under mu
+	lastUsed atomic.Int64 // when the latest request started or ended, in Unix nanoseconds
+	active   atomic.Int32 // requests running in the session
+}
+
+// setKey replaces the session's key with one named name in the keyring, or
+// "" if it is not there, wiping the previous in-memory key or releasing the
+// HSM session held by the previous signer.
+func (s *guiState) setKey(priv ed25519.PrivateKey, signer imfcrypto.Signer, pub ed25519.PublicKey, name string) {
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+	s.dropKey()
+	s.PrivateKey = priv
+	s.Signer = signer
+	s.PublicKey = pub
+	s.KeyName = name
+	s.KeyLoaded = true
+	s.KeyExpired = false
+	s.keyFile = nil
+}
+
+// keepKeyFile records file, the passphrase-protected key file the session's
+// key pub was loaded from, so that saving the key to the keyring keeps it
+// protected. It does nothing if another request has since loaded a
+// different key.
+func (s *guiState) keepKeyFile(pub ed25519.PublicKey, file []byte) {
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+	if s.PublicKey.Equal(pub) {
+		s.keyFile = bytes.Clone(file)
+	}
+}
+
+// clearKey wipes the session's key and forgets it.
+func (s *guiState) clearKey() {
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+	s.forgetKey()
+}
+
+// forgetKey is clearKey with keyMu already held.
+func (s *guiState) forgetKey() {
+	s.dropKey()
+	s.PrivateKey, s.Signer, s.PublicKey = nil, nil, nil
+	s.KeyName = ""
+	s.KeyLoaded = false
+	s.keyFile = nil
+}
+
+// dropKey wipes the session's in-memory key and releases its signer, or, if
+// a request is signing with them, leaves that to the last such request to
+// finish. keyMu must be held.
+func (s *guiState) dropKey() {
+	priv, signer := s.PrivateKey, s.Signer
+	release := func() {
+		releaseSigner(signer)
+		imfcrypto.Wipe(priv)
+	}
+	if s.signing > 0 {
+		s.retired = append(s.retired, release)
+		return
+	}
+	release()
+}
+
+// useSigner returns the session's signer, or nil if it has none, and a
+// function to call once done signing. Until then, a key replaced or cleared
+// by another request is kept rather than wiped under the signer.
+func (s *guiState) useSigner() (imfcrypto.Signer, func()) {
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+	if s.Signer == nil {
+		return nil, func() {}
+	}
+	s.signing++
+	return s.Signer, func() {
+		s.keyMu.Lock()
+		defer s.keyMu.Unlock()
+		if s.signing--; s.signing == 0 {
+			for _, release := range s.retired {
+				release()
+			}
+			s.retired = nil
+		}
+	}
+}
+
+// idle returns how long it has been since the session's last request, or
+// 0 while one is running.
+func (s *guiState) idle() time.Duration {
+	if s.active.Load() > 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, s.lastUsed.Load()))
+}
+
+// touch records activity on the session.
+func (s *guiState) touch() {
+	s.lastUsed.Store(time.Now().UnixNano())
+}
+
+// defaultIdleTimeout is how long the GUI keeps a key loaded with no request
+// from the browser before wiping it. IMF_GUI_IDLE_TIMEOUT overrides it with
+// a duration such as "5m", or "0" to keep the key for the whole session.
+const defaultIdleTimeout = 15 * time.Minute
+
+// sessionTimeout is how long a session may sit idle before it is dropped:
+// its key wiped and its work directory removed. A browser that comes back
+// later simply starts a new session.
+const sessionTimeout = 12 * time.Hour
+
+// maxSessions bounds how many sessions are kept. Past it, the session idle
+// longest is dropped to make room if it has been idle for sessionReclaimAge
+// and has no request running; otherwise new sessions are refused until one
+// expires.
+const maxSessions = 64
+
+// sessionReclaimAge is how long a session must sit idle before it may be
+// dropped to make room for a new one.
+const sessionReclaimAge = time.Hour
+
+// errTooManySessions is returned by create when every session is in use.
+var errTooManySessions = errors.New("too many GUI sessions in use; try again later")
+
+// sessionCookie names the cookie holding the session ID.
+const sessionCookie = "imf_session"
+
+// sessionManager keeps the GUI's sessions and expires idle ones.
+type sessionManager struct {
+	keyTimeout time.Duration // wipe a session's key after this long idle; 0 keeps it
+
+	mu       sync.Mutex
+	sessions map[string]*guiState
+}
+
+// sessions is the GUI's session manager, set by runGUI.
+var sessions *sessionManager
+
+// newSessionManager returns a manager that wipes idle keys after keyTimeout
+// and drops sessions idle for sessionTimeout, checking in the background.
+func newSessionManager(keyTimeout time.Duration) *sessionManager {
+	m := &sessionManager{keyTimeout: keyTimeout, sessions: make(map[string]*guiState)}
+	interval := time.Minute
+	if keyTimeout > 0 && keyTimeout/2 < interval {
+		interval = keyTimeout / 2
+	}
+	go func() {
+		for range time.Tick(interval) {
+			m.sweep()
+		}
+	}()
+	return m
+}
+
+// get returns the session with the given ID, or nil.
+func (m *sessionManager) get(id string) *guiState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sessions[id]
+}
+
+// create starts a session for user with a new ID and work directory. On a
+// shared server it also makes sure the user's directory exists.
+func (m *sessionManager) create(user string) (*guiState, error) {
+	id, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "imf-session-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating session directory: %w", err)
+	}
+	s := &guiState{ID: id, User: user, WorkDir: dir}
+	if user != "" {
+		if err := os.MkdirAll(workDir(s), 0700); err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+	}
+	s.touch()
+
+	m.mu.Lock()
+	var oldest *guiState
+	if len(m.sessions) >= maxSessions {
+		for _, o := range m.sessions {
+			if oldest == nil || o.idle() > oldest.idle() {
+				oldest = o
+			}
+		}
+		// TryLock fails while a request runs in the session; on success the
+		// lock is held, and released by closeLocked below.
+		if oldest.idle() < sessionReclaimAge || !oldest.mu.TryLock() {
+			m.mu.Unlock()
+			os.RemoveAll(dir)
+			return nil, errTooManySessions
+		}
+		delete(m.sessions, oldest.ID)
+	}
+	m.sessions[id] = s
+	m.mu.Unlock()
+
+	if oldest != nil {
+		oldest.closeLocked()
+		oldest.mu.Unlock()
+	}
+	return s, nil
+}
+
+// sweep wipes the keys of sessions idle past keyTimeout and drops sessions
+// idle past sessionTimeout. A request in progress counts as activity.
+func (m *sessionManager) sweep() {
+	m.mu.Lock()
+	var expired []*guiState
+	for id, s := range m.sessions {
+		if s.idle() >= sessionTimeout {
+			delete(m.sessions, id)
+			expired = append(expired, s)
+		}
+	}
+	live := make([]*guiState, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		live = append(live, s)
+	}
+	m.mu.Unlock()
+
+	for _, s := range expired {
+		s.close()
+	}
+	// A session busy starting or checking an upload is swept next time.
+	for _, s := range live {
+		if s.uploadMu.TryLock() {
+			s.dropStaleUploads()
+			s.uploadMu.Unlock()
+		}
+	}
+	if m.keyTimeout <= 0 {
+		return
+	}
+	// Only keyMu is taken: a request that starts meanwhile and signs keeps
+	// the key until it is done, as with any other change of key.
+	for _, s := range live {
+		if s.idle() < m.keyTimeout {
+			continue
+		}
+		s.keyMu.Lock()
+		if s.KeyLoaded && s.idle() >= m.keyTimeout {
+			s.forgetKey()
+			s.KeyExpired = true
+			fmt.Printf("Signing key cleared after %s idle\n", m.keyTimeout)
+		}
+		s.keyMu.Unlock()
+	}
+}
+
+// close wipes the session's key and removes its work directory, once any
+// request still using it has finished.
+func (s *guiState) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+}
+
+// closeLocked is close with mu already held. A request that found the
+// session before it was dropped sees closed and starts a new one.
+func (s *guiState) closeLocked() {
+	s.closed = true
+	s.clearKey()
+	os.RemoveAll(s.WorkDir)
+}
+
+// closeAll drops every session as the GUI stops, wiping its key and
+// removing its work directory. A request still running past the shutdown's
+// grace period loses both: a seal it is signing fails rather than keep the
+// key.
+func (m *sessionManager) closeAll() {
+	m.mu.Lock()
+	all := m.sessions
+	m.sessions = make(map[string]*guiState)
+	m.mu.Unlock()
+	for _, s := range all {
+		s.wipeKey()
+		os.RemoveAll(s.WorkDir)
+	}
+}
+
+// wipeKey is clearKey for the GUI's shutdown: it wipes the session's key,
+// and any it replaced, at once, even while a request is signing with them.
+func (s *guiState) wipeKey() {
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+	for _, release := range s.retired {
+		release()
+	}
+	s.retired = nil
+	releaseSigner(s.Signer)
+	imfcrypto.Wipe(s.PrivateKey)
+	s.PrivateKey, s.Signer, s.PublicKey = nil, nil, nil
+	s.KeyName = ""
+	s.KeyLoaded = false
+	s.keyFile = nil
+}
+
+type sessionKey struct{}
+
+// withSessions wraps the GUI's handlers so that each request runs in its
+// browser's session, starting one (and setting its cookie) if the request
+// has none, one that has expired, or one another user signed in to.
+func (m *sessionManager) withSessions(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s *guiState
+		user := requestUser(r)
+		if c, err := r.Cookie(sessionCookie); err == nil {
+			if s = m.get(c.Value); s != nil && s.User != user {
+				s = nil
+			}
+		}
+		if s != nil {
+			s.mu.RLock()
+			if s.closed {
+				s.mu.RUnlock()
+				s = nil
+			}
+		}
+		if s == nil {
+			var err error
+			if s, err = m.create(user); errors.Is(err, errTooManySessions) {
+				jsonError(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			} else if err != nil {
+				jsonError(w, err.Error(), 500)
+				return
+			}
+			s.mu.RLock()
+			http.SetCookie(w, &http.Cookie{
+				Name:     sessionCookie,
+				Value:    s.ID,
+				Path:     "/",
+				Secure:   r.TLS != nil,
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+		defer s.mu.RUnlock()
+		s.active.Add(1)
+		defer s.active.Add(-1)
+		s.touch()
+		defer s.touch()
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionKey{}, s)))
+	})
+}
+
+// session returns the session a request runs in.
+func session(r *http.Request) *guiState {
+	return r.Context().Value(sessionKey{}).(*guiState)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// newSessionKey returns a fresh key pair and a signer for it.
+func newSessionKey(t *testing.T) (ed25519.PrivateKey, imfcrypto.Signer) {
+	t.Helper()
+	kp, err := imfcrypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := imfcrypto.NewKeySigner(kp.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kp.PrivateKey, signer
+}
+
+func wiped(key []byte) bool {
+	return bytes.Equal(key, make([]byte, len(key)))
+}
+
+func TestSetKeyWhileSigning(t *testing.T) {
+	s := &guiState{}
+	first, signer := newSessionKey(t)
+	s.setKey(first, signer, signer.Public(), "")
+
+	// A seal is signing with the first key when another request loads a
+	// second: the first is kept until the seal is done.
+	inUse, done := s.useSigner()
+	second, signer2 := newSessionKey(t)
+	s.setKey(second, signer2, signer2.Public(), "second")
+	if wiped(first) {
+		t.Fatal("key wiped while a request was signing with it")
+	}
+	if _, err := inUse.Sign([]byte("manifest")); err != nil {
+		t.Fatalf("Sign with the replaced key: %v", err)
+	}
+	done()
+	if !wiped(first) {
+		t.Fatal("replaced key not wiped once the request was done")
+	}
+	if wiped(second) || s.KeyName != "second" {
+		t.Fatal("the new key was lost")
+	}
+
+	// With no request signing, a replaced key is wiped at once.
+	third, signer3 := newSessionKey(t)
+	s.setKey(third, signer3, signer3.Public(), "")
+	if !wiped(second) {
+		t.Fatal("replaced key not wiped")
+	}
+}
+
+func TestCloseAllWipesBusySession(t *testing.T) {
+	s := &guiState{ID: "busy", WorkDir: t.TempDir()}
+	key, signer := newSessionKey(t)
+	s.setKey(key, signer, signer.Public(), "")
+	m := &sessionManager{sessions: map[string]*guiState{s.ID: s}}
+
+	// A request is still running, and signing, when the GUI stops.
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	inUse, done := s.useSigner()
+	defer done()
+
+	m.closeAll()
+	if !wiped(key) || s.KeyLoaded || s.PrivateKey != nil {
+		t.Fatal("closeAll left a busy session's key")
+	}
+	if _, err := inUse.Sign([]byte("manifest")); !errors.Is(err, imfcrypto.ErrSignerClosed) {
+		t.Fatalf("Sign after closeAll: got %v, want ErrSignerClosed", err)
+	}
+	if _, err := os.Stat(s.WorkDir); !os.IsNotExist(err) {
+		t.Fatal("closeAll left a busy session's directory")
+	}
+}
+
+func TestSweepBusySession(t *testing.T) {
+	setRemote(t, nil)
+	s := &guiState{ID: "busy", WorkDir: t.TempDir()}
+	key, signer := newSessionKey(t)
+	s.setKey(key, signer, signer.Public(), "")
+	m := &sessionManager{keyTimeout: time.Minute, sessions: map[string]*guiState{s.ID: s}}
+
+	// A request that has been running for longer than keyTimeout.
+	started, finish, finished := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	h := m.withSessions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-finish
+	}))
+	go func() {
+		req := httptest.NewRequest("GET", "/api/status", nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: s.ID})
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		close(finished)
+	}()
+	<-started
+	s.lastUsed.Store(time.Now().Add(-time.Hour).UnixNano())
+
+	// The sweep neither waits for it nor counts the session as idle.
+	swept := make(chan struct{})
+	go func() {
+		m.sweep()
+		close(swept)
+	}()
+	select {
+	case <-swept:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sweep waited for a running request")
+	}
+	if !s.KeyLoaded || wiped(key) {
+		t.Fatal("key wiped while a request was running")
+	}
+
+	close(finish)
+	<-finished
+	s.lastUsed.Store(time.Now().Add(-time.Hour).UnixNano())
+	m.sweep()
+	if s.KeyLoaded || !s.KeyExpired || !wiped(key) {
+		t.Fatal("key kept once the session was idle")
+	}
+}
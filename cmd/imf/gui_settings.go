@@ -0,0 +1,230 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/config"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/i18n"
+)
+
+// guiPort is the port the GUI is listening on, set by runGUI.
+var guiPort int
+
+// guiSettings are the settings the GUI's settings page shows and edits.
+// They are kept in the config file, so the CLI shares them.
+type guiSettings struct {
+	Dir           string   `json:"dir"`
+	KDFIterations int      `json:"kdf_iterations"`
+	Calendars     []string `json:"calendars"`
+	Port          int      `json:"port"`
+	Lang          string   `json:"lang"`
+	Preview       bool     `json:"preview"`
+	MaxUpload     int      `json:"max_upload_mb"`
+}
+
+// settingsEnv names the environment variable that overrides each setting.
+var settingsEnv = map[string]string{
+	"dir":            "IMF_GUI_DIR",
+	"kdf_iterations": "IMF_KDF_ITERATIONS",
+	"calendars":      "IMF_CALENDARS",
+	"port":           "IMF_GUI_PORT",
+	"lang":           "IMF_LANG",
+	"max_upload_mb":  "IMF_GUI_MAX_UPLOAD_MB",
+}
+
+// previewEnabled reports whether the GUI extracts sealed containers so
+// their files can be previewed.
+func previewEnabled() bool {
+	p := settings().GUIPreview
+	return p == nil || *p
+}
+
+// savedSettings returns the settings as the config file has them.
+func savedSettings(c *config.Config) guiSettings {
+	return guiSettings{
+		Dir:           c.GUIDir,
+		KDFIterations: c.KDFIterations,
+		Calendars:     c.Calendars,
+		Port:          c.GUIPort,
+		Lang:          c.Lang,
+		Preview:       c.GUIPreview == nil || *c.GUIPreview,
+		MaxUpload:     c.GUIMaxUpload,
+	}
+}
+
+// currentSettings returns the settings in effect, with imf's defaults in
+// place of those not set.
+func currentSettings(s *guiState) guiSettings {
+	c := settings()
+	cur := guiSettings{
+		Dir:           workDir(s),
+		KDFIterations: c.KDFIterations,
+		Port:          guiPort,
+		Lang:          i18n.Detect(c.Lang),
+		Preview:       previewEnabled(),
+		MaxUpload:     int(maxUpload() >> 20),
+	}
+	if cur.KDFIterations == 0 {
+		cur.KDFIterations = imfcrypto.PBKDF2Iterations
+	}
+	cur.Calendars, _ = configuredCalendars()
+	if len(cur.Calendars) == 0 {
+		cur.Calendars = anchor.DefaultCalendars
+	}
+	return cur
+}
+
+// settingsData is what /api/settings returns: the config file's path and
+// settings, the settings in effect, which are set in the environment, and
+// the languages to choose from.
+func settingsData(s *guiState, path string, c *config.Config) map[string]interface{} {
+	env := map[string]string{}
+	for key, name := range settingsEnv {
+		if os.Getenv(name) != "" {
+			env[key] = name
+		}
+	}
+	return map[string]interface{}{
+		"file":      path,
+		"saved":     savedSettings(c),
+		"current":   currentSettings(s),
+		"env":       env,
+		"languages": i18n.Languages(),
+	}
+}
+
+// handleSettings returns the GUI's settings, or with a POST saves them to
+// the config file. The form fields are "dir", "kdf_iterations",
+// "calendars" (one URL per line), "port", "lang", "preview", and
+// "max_upload_mb"; an empty field removes the setting. A new working
+// directory and upload limit apply at once, unless the environment
+// overrides them; a new port applies when the GUI next starts.
+func handleSettings(w http.ResponseWriter, r *http.Request) {
+	path, err := config.DefaultFile()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	c, err := config.LoadFile(path)
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	if r.Method != "POST" {
+		jsonSuccess(w, "", settingsData(session(r), path, c))
+		return
+	}
+
+	oldDir, oldPort := c.GUIDir, c.GUIPort
+	if err := parseSettingsForm(r, c); err != nil {
+		jsonError(w, err.Error(), 400)
+		return
+	}
+	if err := config.SaveFile(path, c); err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	if err := reloadSettings(); err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	if c.GUIDir != oldDir && os.Getenv("IMF_GUI_DIR") == "" {
+		dir, err := defaultWorkDir()
+		if err != nil {
+			jsonError(w, err.Error(), 500)
+			return
+		}
+		setWorkDir(dir)
+	}
+
+	msg := "Settings saved"
+	if c.GUIPort != oldPort {
+		msg = "Settings saved. The new port applies when the GUI next starts."
+	}
+	jsonSuccess(w, msg, settingsData(session(r), path, c))
+}
+
+// parseSettingsForm checks the settings posted from the settings page and
+// sets them in c.
+func parseSettingsForm(r *http.Request, c *config.Config) error {
+	dir := strings.TrimSpace(r.FormValue("dir"))
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	if dir != "" {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("Working directory must be an absolute path: %s", dir)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("Not a directory: %s", dir)
+		}
+	}
+
+	iterations, err := settingsInt(r.FormValue("kdf_iterations"))
+	if err != nil || (iterations != 0 && iterations < imfcrypto.MinSealIterations) {
+		return fmt.Errorf("KDF iterations must be at least %d", imfcrypto.MinSealIterations)
+	}
+
+	var calendars []string
+	for _, line := range strings.Split(r.FormValue("calendars"), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if err := anchor.CheckCalendarURL(line); err != nil {
+			return err
+		}
+		calendars = append(calendars, line)
+	}
+
+	port, err := settingsInt(r.FormValue("port"))
+	if err != nil || port < 0 || port > 65535 {
+		return fmt.Errorf("Invalid port: %s", r.FormValue("port"))
+	}
+
+	lang := r.FormValue("lang")
+	if lang != "" && !slices.Contains(i18n.Languages(), lang) {
+		return fmt.Errorf("Unsupported language: %s", lang)
+	}
+
+	var preview *bool
+	switch v := r.FormValue("preview"); v {
+	case "", "true":
+		// Previewing is the default, so it need not be written down.
+	case "false":
+		preview = new(bool)
+	default:
+		return fmt.Errorf("Invalid preview setting: %s", v)
+	}
+
+	maxUpload, err := settingsInt(r.FormValue("max_upload_mb"))
+	if err != nil || maxUpload < 0 {
+		return fmt.Errorf("Invalid upload limit: %s", r.FormValue("max_upload_mb"))
+	}
+
+	c.GUIDir, c.KDFIterations, c.Calendars = dir, iterations, calendars
+	c.GUIPort, c.Lang, c.GUIPreview, c.GUIMaxUpload = port, lang, preview, maxUpload
+	return nil
+}
+
+// settingsInt parses an integer field, empty meaning 0.
+func settingsInt(v string) (int, error) {
+	if v = strings.TrimSpace(v); v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,453 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+// Files are added through the GUI in chunks, so that a multi-gigabyte file
+// is streamed to disk rather than held in a multipart form, and an upload
+// cut off part way can carry on from where it stopped:
+//
+//	POST /api/upload-start   name, size, path → id
+//	POST /api/upload-chunk?id=ID&offset=N     body: the bytes from N on
+//	GET  /api/upload-status?id=ID             → offset, the bytes received
+//	POST /api/upload-finish  container, id…   adds the finished uploads
+//
+// An upload lives in the session's uploads directory as ID.part, with its
+// name and size in ID.json, until it is added or the session is dropped.
+// A session may have maxUploads uploads in progress, of maxUploadTotal
+// bytes in all; once none of them has been added to for uploadTimeout, they
+// are taken for abandoned and removed.
+// A file dropped as part of a folder gives its "path" within the folder,
+// such as "photos/2024/a.jpg", and is added under that path, so the
+// folder's structure survives in the container.
+
+// maxUploadChunk bounds the body of one /api/upload-chunk request.
+const maxUploadChunk = 64 << 20
+
+// defaultMaxUpload is the largest file, in MiB, the GUI accepts unless
+// gui_max_upload_mb says otherwise: as much as guiLimits lets it extract.
+const defaultMaxUpload = 4096
+
+// uploadMemory bounds how much of a multipart form is held in memory; the
+// rest of its files are spooled to temporary files on disk.
+const uploadMemory = 8 << 20
+
+// formOverhead is the room a request body is given past maxUpload, for a
+// multipart form's other fields and boundaries.
+const formOverhead = 1 << 20
+
+// maxUploads bounds how many uploads a session may have in progress. The
+// page uploads all of a dropped folder's files before adding any, so this
+// is a folder's worth of files.
+const maxUploads = 10000
+
+// uploadChunkTimeout is how long the body of one /api/upload-chunk request
+// may take to arrive. What arrived by then is kept, and the page resumes
+// from there.
+const uploadChunkTimeout = 5 * time.Minute
+
+// uploadTimeout is how long a session's uploads in progress are kept with
+// none of them started or added to.
+const uploadTimeout = time.Hour
+
+// maxUpload returns the largest file the GUI accepts, in bytes.
+func maxUpload() int64 {
+	if mb := settings().GUIMaxUpload; mb > 0 {
+		return int64(mb) << 20
+	}
+	return defaultMaxUpload << 20
+}
+
+// maxUploadTotal returns how many bytes a session's uploads in progress
+// may come to together.
+func maxUploadTotal() int64 {
+	return 4 * maxUpload()
+}
+
+// errTooLarge refuses an upload larger than maxUpload.
+func errTooLarge(name string) string {
+	return fmt.Sprintf("%s is larger than the %s upload limit", name, formatBytes(maxUpload()))
+}
+
+// withUploadLimit wraps the GUI's handlers so that no request body runs
+// past maxUpload, and parses a multipart form up front, holding no more
+// than uploadMemory of it in memory: its files are spooled to disk, and
+// removed once the request has been answered. Chunked uploads are bounded
+// by the size given to /api/upload-start instead.
+func withUploadLimit(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxUpload()+formOverhead)
+		if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+			if err := r.ParseMultipartForm(uploadMemory); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					jsonError(w, errTooLarge("The upload"), http.StatusRequestEntityTooLarge)
+					return
+				}
+				jsonError(w, "Invalid form: "+err.Error(), 400)
+				return
+			}
+			defer r.MultipartForm.RemoveAll()
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// uploadInfo is what ID.json records about an upload.
+type uploadInfo struct {
+	Name string `json:"name"` // a slash-separated relative path for a file from a folder
+	Size int64  `json:"size"`
+}
+
+// uploadPaths returns the data and metadata files of upload id.
+func uploadPaths(s *guiState, id string) (part, meta string, err error) {
+	if b, err := hex.DecodeString(id); err != nil || len(b) != 32 {
+		return "", "", errors.New("invalid upload ID")
+	}
+	dir := filepath.Join(s.WorkDir, "uploads")
+	return filepath.Join(dir, id+".part"), filepath.Join(dir, id+".json"), nil
+}
+
+// readUpload returns upload id's metadata and the bytes received so far.
+func readUpload(s *guiState, id string) (info uploadInfo, received int64, err error) {
+	part, meta, err := uploadPaths(s, id)
+	if err != nil {
+		return info, 0, err
+	}
+	data, err := os.ReadFile(meta)
+	if err != nil {
+		return info, 0, errors.New("no such upload")
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, 0, err
+	}
+	fi, err := os.Stat(part)
+	if err != nil {
+		return info, 0, errors.New("no such upload")
+	}
+	return info, fi.Size(), nil
+}
+
+// dropStaleUploads removes the session's uploads in progress if none of
+// them has been started or added to for uploadTimeout, and no request is
+// using one. uploadMu must be held.
+func (s *guiState) dropStaleUploads() {
+	if len(s.uploads) == 0 || len(s.uploading) > 0 || time.Since(s.uploadedAt) < uploadTimeout {
+		return
+	}
+	for id := range s.uploads {
+		part, meta, _ := uploadPaths(s, id)
+		os.Remove(part)
+		os.Remove(meta)
+	}
+	s.uploads = nil
+}
+
+// claimUploads marks the uploads ids as in use by a request, which may then
+// read or write their files without uploadMu, and returns a function that
+// releases them. It fails if another request is using one of them.
+// uploadMu must be held, and is needed to call the release function too.
+func (s *guiState) claimUploads(ids []string) (func(), error) {
+	for _, id := range ids {
+		if _, busy := s.uploading[id]; busy {
+			return nil, errors.New("upload is in use by another request")
+		}
+	}
+	if s.uploading == nil {
+		s.uploading = make(map[string]struct{})
+	}
+	for _, id := range ids {
+		s.uploading[id] = struct{}{}
+	}
+	return func() {
+		for _, id := range ids {
+			delete(s.uploading, id)
+		}
+	}, nil
+}
+
+// handleUploadStart begins an upload of the file named by the "name" form
+// field, of "size" bytes, and returns its ID. A "path" field, if given,
+// names the file by its path within a folder instead.
+func handleUploadStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	s := session(r)
+	name := filepath.Base(r.FormValue("name"))
+	if path := r.FormValue("path"); path != "" {
+		var err error
+		if name, err = container.SanitizePath(path); err != nil {
+			jsonError(w, "Invalid file path: "+path, 400)
+			return
+		}
+	} else if name == "." || name == string(filepath.Separator) {
+		jsonError(w, "No file name provided", 400)
+		return
+	}
+	size, err := strconv.ParseInt(r.FormValue("size"), 10, 64)
+	if err != nil || size < 0 {
+		jsonError(w, "Invalid file size", 400)
+		return
+	}
+	if size > maxUpload() {
+		jsonError(w, errTooLarge(name), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+	s.dropStaleUploads()
+	if len(s.uploads) >= maxUploads {
+		jsonError(w, fmt.Sprintf("More than %d uploads in progress", maxUploads), http.StatusTooManyRequests)
+		return
+	}
+	total := size
+	for _, n := range s.uploads {
+		total += n
+	}
+	if total > maxUploadTotal() {
+		jsonError(w, fmt.Sprintf("Uploads in progress would come to more than %s", formatBytes(maxUploadTotal())),
+			http.StatusRequestEntityTooLarge)
+		return
+	}
+	id, err := newToken()
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	part, meta, _ := uploadPaths(s, id)
+	if err := os.MkdirAll(filepath.Dir(part), 0700); err != nil {
+		jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+		return
+	}
+	data, _ := json.Marshal(uploadInfo{Name: name, Size: size})
+	if err := os.WriteFile(meta, data, 0600); err != nil {
+		jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+		return
+	}
+	if err := os.WriteFile(part, nil, 0600); err != nil {
+		os.Remove(meta)
+		jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+		return
+	}
+	if s.uploads == nil {
+		s.uploads = make(map[string]int64)
+	}
+	s.uploads[id] = size
+	s.uploadedAt = time.Now()
+	jsonSuccess(w, "", map[string]interface{}{"id": id, "offset": 0})
+}
+
+// handleUploadStatus reports how much of an upload has arrived, so the
+// browser knows where to resume it.
+func handleUploadStatus(w http.ResponseWriter, r *http.Request) {
+	s := session(r)
+	id := r.FormValue("id")
+	s.uploadMu.Lock()
+	info, received, err := readUpload(s, id)
+	s.uploadMu.Unlock()
+	if err != nil {
+		jsonError(w, err.Error(), 404)
+		return
+	}
+	jsonSuccess(w, "", map[string]interface{}{"id": id, "name": info.Name, "size": info.Size, "offset": received})
+}
+
+// handleUploadChunk appends the request body to an upload. The "offset"
+// query parameter must equal the bytes received so far; if it does not, the
+// browser asks /api/upload-status where to go on from. Bytes that arrive
+// before the connection drops are kept.
+func handleUploadChunk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	s := session(r)
+	q := r.URL.Query()
+	id := q.Get("id")
+	offset, err := strconv.ParseInt(q.Get("offset"), 10, 64)
+	if err != nil {
+		jsonError(w, "Invalid offset", 400)
+		return
+	}
+
+	// Claim the upload, then copy the body with uploadMu released, so a
+	// slow chunk holds up no other request in the session.
+	s.uploadMu.Lock()
+	info, received, err := readUpload(s, id)
+	if err != nil {
+		s.uploadMu.Unlock()
+		jsonError(w, err.Error(), 404)
+		return
+	}
+	if offset != received {
+		s.uploadMu.Unlock()
+		jsonError(w, fmt.Sprintf("Upload is at offset %d, not %d", received, offset), 409)
+		return
+	}
+	release, err := s.claimUploads([]string{id})
+	if err != nil {
+		s.uploadMu.Unlock()
+		jsonError(w, err.Error(), 409)
+		return
+	}
+	s.uploadedAt = time.Now()
+	s.uploadMu.Unlock()
+	defer func() {
+		s.uploadMu.Lock()
+		release()
+		s.uploadedAt = time.Now()
+		s.uploadMu.Unlock()
+	}()
+
+	part, _, _ := uploadPaths(s, id)
+	f, err := os.OpenFile(part, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	defer f.Close()
+
+	// Read one byte past what the file still needs, to catch a chunk that
+	// would run past its end.
+	http.NewResponseController(w).SetReadDeadline(time.Now().Add(uploadChunkTimeout))
+	limit := min(info.Size-received, maxUploadChunk)
+	n, err := io.Copy(f, io.LimitReader(r.Body, limit+1))
+	if n > limit {
+		f.Truncate(received + limit)
+		jsonError(w, "Chunk runs past the end of the file", 400)
+		return
+	}
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Upload interrupted at offset %d: %v", received+n, err), 400)
+		return
+	}
+	jsonSuccess(w, "", map[string]interface{}{"offset": received + n})
+}
+
+// handleUploadFinish adds the completed uploads named by the "id" form
+// fields to the open container named by "container", as /api/add would.
+func handleUploadFinish(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		jsonError(w, "Method not allowed", 405)
+		return
+	}
+
+	s := session(r)
+	containerName := r.FormValue("container")
+	if containerName == "" {
+		jsonError(w, "No container specified", 400)
+		return
+	}
+	containerPath := containerFile(r, containerName)
+	ids := r.Form["id"]
+	if len(ids) == 0 {
+		jsonError(w, "No files provided", 400)
+		return
+	}
+
+	// Claim the uploads, then stage and add them with uploadMu released.
+	s.uploadMu.Lock()
+	release, err := s.claimUploads(ids)
+	s.uploadMu.Unlock()
+	if err != nil {
+		jsonError(w, err.Error(), 409)
+		return
+	}
+	var added bool
+	defer func() {
+		s.uploadMu.Lock()
+		release()
+		if added {
+			for _, id := range ids {
+				delete(s.uploads, id)
+			}
+		}
+		s.uploadMu.Unlock()
+	}()
+
+	// Lay the finished uploads out as a tree under a directory of their own,
+	// each at its path, so that they are added under those paths.
+	dir := filepath.Join(s.WorkDir, "uploads")
+	os.MkdirAll(dir, 0700)
+	root, err := os.MkdirTemp(dir, "add-")
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+		return
+	}
+	type staged struct{ part, meta, path string }
+	var done []staged
+	restore := func() {
+		for _, st := range done {
+			os.Rename(st.path, st.part)
+		}
+		os.RemoveAll(root)
+	}
+	var paths []string
+	for _, id := range ids {
+		info, received, err := readUpload(s, id)
+		if err != nil {
+			restore()
+			jsonError(w, err.Error(), 404)
+			return
+		}
+		if received != info.Size {
+			restore()
+			jsonError(w, fmt.Sprintf("Upload of %s is incomplete: %d of %d bytes", info.Name, received, info.Size), 409)
+			return
+		}
+		part, meta, _ := uploadPaths(s, id)
+		st := staged{part: part, meta: meta, path: filepath.Join(root, filepath.FromSlash(info.Name))}
+		if _, err := os.Lstat(st.path); err == nil {
+			restore()
+			jsonError(w, fmt.Sprintf("%s is uploaded twice", info.Name), 409)
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(st.path), 0700); err != nil {
+			restore()
+			jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+			return
+		}
+		if err := os.Rename(part, st.path); err != nil {
+			restore()
+			jsonError(w, fmt.Sprintf("Error creating temp file: %v", err), 500)
+			return
+		}
+		done = append(done, st)
+		paths = append(paths, st.path)
+	}
+
+	report, err := container.AddWithReport(containerPath, paths, container.AddOptions{BaseDir: root})
+	if err != nil {
+		restore()
+		jsonError(w, err.Error(), 500)
+		return
+	}
+	os.RemoveAll(root)
+	for _, st := range done {
+		os.Remove(st.meta)
+	}
+	added = true
+	jsonSuccess(w, fmt.Sprintf("Added %d file(s)", len(paths))+renamedNote(report.Renamed),
+		map[string]interface{}{"renamed": report.Renamed})
+}
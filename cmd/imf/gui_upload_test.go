@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/config"
+)
+
+// startUpload asks handleUploadStart, in session s, for an upload of size
+// bytes, and returns the status and the upload's ID.
+func startUpload(t *testing.T, s *guiState, size int64) (int, string) {
+	t.Helper()
+	form := url.Values{"name": {"a.bin"}, "size": {strconv.FormatInt(size, 10)}}
+	req := httptest.NewRequest("POST", "/api/upload-start", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(context.WithValue(req.Context(), sessionKey{}, s))
+	rec := httptest.NewRecorder()
+	handleUploadStart(rec, req)
+	var resp struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp.Data.ID
+}
+
+func TestUploadLimits(t *testing.T) {
+	settingsMu.Lock()
+	old := loadedSettings
+	loadedSettings = &config.Config{GUIMaxUpload: 1}
+	settingsMu.Unlock()
+	t.Cleanup(func() {
+		settingsMu.Lock()
+		loadedSettings = old
+		settingsMu.Unlock()
+	})
+	s := &guiState{WorkDir: t.TempDir()}
+
+	// Four 1 MiB uploads fill the session's 4 MiB; a fifth is refused.
+	var ids []string
+	for i := 0; i < 4; i++ {
+		code, id := startUpload(t, s, 1<<20)
+		if code != 200 {
+			t.Fatalf("upload %d: status = %d", i, code)
+		}
+		ids = append(ids, id)
+	}
+	if code, _ := startUpload(t, s, 1); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("past the total: status = %d, want 413", code)
+	}
+
+	// Left alone past uploadTimeout, the uploads are removed, and make room.
+	s.uploadedAt = time.Now().Add(-uploadTimeout)
+	if code, _ := startUpload(t, s, 1); code != 200 {
+		t.Fatalf("after the timeout: status = %d", code)
+	}
+	for _, id := range ids {
+		part, meta, _ := uploadPaths(s, id)
+		if _, err := os.Stat(part); !os.IsNotExist(err) {
+			t.Fatalf("abandoned %s not removed", filepath.Base(part))
+		}
+		if _, err := os.Stat(meta); !os.IsNotExist(err) {
+			t.Fatalf("abandoned %s not removed", filepath.Base(meta))
+		}
+	}
+	if len(s.uploads) != 1 {
+		t.Fatalf("%d uploads in progress, want 1", len(s.uploads))
+	}
+
+	s.uploads = make(map[string]int64)
+	for i := 0; i < maxUploads; i++ {
+		s.uploads[strconv.Itoa(i)] = 0
+	}
+	if code, _ := startUpload(t, s, 1); code != http.StatusTooManyRequests {
+		t.Fatalf("past maxUploads: status = %d, want 429", code)
+	}
+}
+
+// sendChunk posts body to handleUploadChunk as upload id's bytes from
+// offset on, in session s.
+func sendChunk(s *guiState, id string, offset int64, body io.Reader) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/upload-chunk?id="+id+"&offset="+strconv.FormatInt(offset, 10), body)
+	req = req.WithContext(context.WithValue(req.Context(), sessionKey{}, s))
+	rec := httptest.NewRecorder()
+	handleUploadChunk(rec, req)
+	return rec
+}
+
+func TestUploadChunkStalled(t *testing.T) {
+	s := &guiState{WorkDir: t.TempDir()}
+	s.touch()
+	_, id := startUpload(t, s, 10)
+
+	// A chunk whose body stalls part way.
+	body, send := io.Pipe()
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- sendChunk(s, id, 0, body) }()
+	send.Write([]byte("hello"))
+
+	// The session's other requests, and the sweeper, are not held up by it.
+	if !s.uploadMu.TryLock() {
+		t.Fatal("uploadMu held while a chunk is copied")
+	}
+	s.uploadMu.Unlock()
+	m := &sessionManager{sessions: map[string]*guiState{"s": s}}
+	m.sweep()
+	if _, _, err := readUpload(s, id); err != nil {
+		t.Fatalf("status during the chunk: %v", err)
+	}
+
+	// but no other chunk may write to the same upload meanwhile.
+	if rec := sendChunk(s, id, 5, strings.NewReader("world")); rec.Code != 409 {
+		t.Fatalf("second chunk: status = %d, want 409", rec.Code)
+	}
+
+	send.Write([]byte("world"))
+	send.Close()
+	if rec := <-done; rec.Code != 200 {
+		t.Fatalf("chunk: status = %d: %s", rec.Code, rec.Body)
+	}
+	part, _, _ := uploadPaths(s, id)
+	if data, _ := os.ReadFile(part); string(data) != "helloworld" {
+		t.Fatalf("upload holds %q", data)
+	}
+	if len(s.uploading) != 0 {
+		t.Fatal("upload still claimed after the chunk")
+	}
+}
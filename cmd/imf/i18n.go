@@ -0,0 +1,24 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import "github.com/immutable-container/imf/pkg/i18n"
+
+// messages translates what imf prints for people; see package i18n.
+var messages *i18n.Printer
+
+// printer returns the Printer for the language chosen by IMF_LANG, lang in
+// ~/.imf/config, or the locale.
+func printer() *i18n.Printer {
+	if messages == nil {
+		messages = i18n.NewPrinter(i18n.Detect(settings().Lang))
+	}
+	return messages
+}
+
+// tr translates format and formats it with args, like fmt.Sprintf. Only
+// text meant for people goes through it; JSON output stays in English.
+func tr(format string, args ...any) string {
+	return printer().Sprintf(format, args...)
+}
@@ -7,33 +7,74 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/immutable-container/imf/pkg/anchor"
 	"github.com/immutable-container/imf/pkg/container"
+	"github.com/immutable-container/imf/pkg/sigstore"
 )
 
 // runInfo handles the "imf info" command.
 // Displays metadata about a container: state (open/sealed), creation and seal
 // timestamps, expiration status, encryption status, embedded key presence,
-// and file count. Does not require decryption or key access.
+// signer identity, file count, and the status of an OpenTimestamps anchor,
+// whether in an adjacent .ots file or embedded. Does not require decryption or key access, except that a
+// hidden manifest needs -passphrase or -identity to show its timestamps. With -online, the
+// Bitcoin blocks of a confirmed anchor are looked up to show their times.
+// With --json, the same is printed as an infoJSON.
 func runInfo() {
 	fs := flag.NewFlagSet("imf info", flag.ExitOnError)
-	fs.Parse(os.Args[1:])
+	passphrase := fs.String("passphrase", "", "Passphrase, to read a hidden manifest")
+	identity := fs.String("identity", "", "X25519 private key (PEM), to read a hidden manifest")
+	online := fs.Bool("online", false, "Look up the Bitcoin blocks of a confirmed anchor")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf info [options] <container.imf>")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
 
 	if fs.NArg() != 1 {
-		fmt.Fprintln(os.Stderr, "Usage: imf info <container.imf>")
+		fs.Usage()
 		os.Exit(1)
 	}
 
-	info, err := container.GetInfo(fs.Arg(0))
+	opts := container.InfoOptions{Passphrase: *passphrase}
+	if *identity != "" {
+		opts.RecipientKey = mustReadRecipientPrivateKey(*identity)
+	}
+	info, err := container.GetInfoWithOptions(fs.Arg(0), opts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+
+	var statusOpts anchor.StatusOptions
+	if *online {
+		statusOpts.Explorer = os.Getenv("IMF_EXPLORER_URL")
+		if statusOpts.Explorer == "" {
+			statusOpts.Explorer = anchor.DefaultExplorer
+		}
+	}
+	status, statusErr := anchor.StatusWithOptions(fs.Arg(0), statusOpts)
+
+	if jsonOutput {
+		j := newInfoJSON(fs.Arg(0), info)
+		if statusErr != nil {
+			j.Anchor = &anchorStatusJSON{State: "unreadable", Blocks: []blockJSON{}, Pending: []string{}, Error: statusErr.Error()}
+		} else if status != nil {
+			j.Anchor = newAnchorStatusJSON(status)
+		}
+		printJSON(j)
+		return
 	}
 
 	fmt.Printf("Container: %s\n", fs.Arg(0))
 	fmt.Printf("  State:     %s\n", info.State)
-	fmt.Printf("  Created:   %s\n", info.CreatedAt.Format(time.RFC3339))
+	if !info.CreatedAt.IsZero() {
+		fmt.Printf("  Created:   %s\n", info.CreatedAt.Format(time.RFC3339))
+	}
 
 	if info.SealedAt != nil {
 		fmt.Printf("  Sealed:    %s\n", info.SealedAt.Format(time.RFC3339))
@@ -47,6 +88,82 @@ func runInfo() {
 	}
 
 	fmt.Printf("  Encrypted: %v\n", info.Encrypted)
+	if info.TimeLock != nil {
+		lockStr := info.TimeLock.Format(time.RFC3339)
+		if time.Now().Before(*info.TimeLock) {
+			lockStr += " (LOCKED)"
+		}
+		fmt.Printf("  Unlocks:   %s\n", lockStr)
+	}
+	if info.Hidden {
+		fmt.Println("  Manifest:  hidden")
+	}
 	fmt.Printf("  Pub Key:   %v\n", info.HasPubKey)
+	if info.Signer != nil {
+		fmt.Printf("  Signer:    %s\n", formatSigner(info.Signer))
+	}
+	if info.PQ != "" {
+		fmt.Printf("  Hybrid:    %s\n", info.PQ)
+	}
+	if info.Keyless && len(info.CertChain) > 0 {
+		id, iss := sigstore.Identity(info.CertChain[0])
+		fmt.Printf("  Keyless:   %s (%s)\n", id, iss)
+	} else if len(info.CertChain) > 0 {
+		fmt.Printf("  Cert:      %s (issued by %s)\n", info.CertChain[0].Subject, info.CertChain[0].Issuer)
+	}
+	if info.Policy != nil {
+		fmt.Printf("  Policy:    %d of %d keys, %d signed\n", info.Policy.Threshold, len(info.Policy.Keys), len(info.Policy.Signed))
+	}
+	if md := info.Metadata; md != nil {
+		if md.Title != "" {
+			fmt.Printf("  Title:     %s\n", md.Title)
+		}
+		if md.CaseNumber != "" {
+			fmt.Printf("  Case:      %s\n", md.CaseNumber)
+		}
+		if len(md.Tags) > 0 {
+			fmt.Printf("  Tags:      %s\n", strings.Join(md.Tags, ", "))
+		}
+		if md.Template != "" {
+			fmt.Printf("  Template:  %s\n", md.Template)
+		}
+		if md.Description != "" {
+			fmt.Printf("  About:     %s\n", strings.ReplaceAll(md.Description, "\n", "\n             "))
+		}
+	}
 	fmt.Printf("  Files:     %d\n", info.FileCount)
+
+	if statusErr != nil {
+		fmt.Printf("  Anchor:    unreadable proof (%v)\n", statusErr)
+	} else if status != nil {
+		printAnchorStatus(status)
+	}
+}
+
+// printAnchorStatus prints the anchor lines of imf info.
+func printAnchorStatus(s *anchor.StatusResult) {
+	where := "adjacent .ots"
+	if s.Embedded {
+		where = "embedded"
+	}
+	switch s.State {
+	case anchor.StateMismatch:
+		fmt.Printf("  Anchor:    MISMATCH (%s) — container changed since anchoring\n", where)
+	case anchor.StatePending:
+		fmt.Printf("  Anchor:    pending (%s), waiting on %s\n", where, strings.Join(s.Pending, ", "))
+	default:
+		fmt.Printf("  Anchor:    confirmed (%s)\n", where)
+	}
+	if s.Target != "" {
+		fmt.Printf("  Anchored:  %s (SHA-256 of the %s)\n", s.Hash, describeTarget(s.Target))
+	} else if s.Hash != "" {
+		fmt.Printf("  Anchored:  %s\n", s.Hash)
+	}
+	for _, b := range s.Blocks {
+		line := fmt.Sprintf("Bitcoin block %d", b.Height)
+		if !b.Time.IsZero() {
+			line += ", " + b.Time.UTC().Format(time.RFC3339)
+		}
+		fmt.Printf("  Block:     %s\n", line)
+	}
 }
@@ -0,0 +1,224 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/keyring"
+)
+
+const keyUsage = `Usage: imf key <subcommand> [arguments]
+
+Subcommands:
+  list                     List keys in the keyring
+  add <name> <file>        Add a private or public key file under name
+  rm <name>                Remove a key
+  export [-private] <name> Print a key's public (or private) key file
+  fingerprint <name|file>  Print a key's SHA-256 fingerprint
+  recover [-protect] <name>
+                           Re-create a key from its recovery phrase (see
+                           "imf keygen -mnemonic")
+
+Keys are kept in ~/.imf/keys (or $IMF_KEYRING). A keyring key name can be
+given wherever -key takes a key file, e.g. "imf seal a.imf -key release".
+`
+
+// runKey handles the "imf key" command, which manages the named keys in the
+// local keyring (see package keyring).
+func runKey() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, keyUsage)
+		os.Exit(1)
+	}
+	sub := os.Args[1]
+	os.Args = append([]string{os.Args[0] + " " + sub}, os.Args[2:]...)
+
+	switch sub {
+	case "list":
+		runKeyList()
+	case "add":
+		runKeyAdd()
+	case "rm":
+		runKeyRemove()
+	case "export":
+		runKeyExport()
+	case "fingerprint":
+		runKeyFingerprint()
+	case "recover":
+		runKeyRecover()
+	case "help", "-h", "--help":
+		fmt.Print(keyUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown key subcommand: %s\n\n", sub)
+		fmt.Fprint(os.Stderr, keyUsage)
+		os.Exit(1)
+	}
+}
+
+// mustOpenKeyring opens the default keyring, exiting on failure.
+func mustOpenKeyring() *keyring.Keyring {
+	kr, err := keyring.OpenDefault()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	return kr
+}
+
+func runKeyList() {
+	parseArgs("imf key list", "imf key list", 0)
+	kr := mustOpenKeyring()
+	keys, err := kr.List()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if len(keys) == 0 {
+		fmt.Printf("No keys in %s\n", kr.Dir())
+		return
+	}
+	for _, k := range keys {
+		kind := "public"
+		if k.HasPrivate {
+			kind = "private"
+		}
+		fmt.Printf("%-20s %-7s  %s\n", k.Name, kind, k.Fingerprint)
+	}
+}
+
+// runKeyAdd stores a key file in the keyring. A private key file is kept as
+// is (a protected key stays protected); its passphrase is asked for only to
+// derive the public key.
+func runKeyAdd() {
+	args := parseArgs("imf key add", "imf key add <name> <file>", 2)
+	name, path := args[0], args[1]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	var private []byte
+	pub, err := imfcrypto.ParsePublicKeyPEM(data)
+	if err != nil {
+		priv, privErr := imfcrypto.ParsePrivateKeyPEM(data)
+		if errors.Is(privErr, imfcrypto.ErrKeyProtected) {
+			pp := promptPassphrase(tr("Passphrase for %s: ", path))
+			priv, privErr = imfcrypto.ParseEncryptedPrivateKeyPEM(data, pp)
+		}
+		if privErr != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing key %s: %v\n", path, privErr)
+			os.Exit(exitCode(privErr))
+		}
+		pub, private = priv.Public().(ed25519.PublicKey), data
+	}
+
+	key, err := mustOpenKeyring().Add(name, pub, private)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Added %s (%s)\n", key.Name, key.Fingerprint)
+}
+
+func runKeyRemove() {
+	name := parseArgs("imf key rm", "imf key rm <name>", 1)[0]
+	if err := mustOpenKeyring().Remove(name); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Removed %s\n", name)
+}
+
+func runKeyExport() {
+	fs := flag.NewFlagSet("imf key export", flag.ExitOnError)
+	private := fs.Bool("private", false, "Export the private key file instead of the public key")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf key export [-private] <name>")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	kr := mustOpenKeyring()
+	if *private {
+		data, err := kr.PrivateKeyFile(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		os.Stdout.Write(data)
+		return
+	}
+	key, err := kr.Get(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	os.Stdout.Write(imfcrypto.MarshalPublicKeyPEM(key.PublicKey))
+}
+
+// runKeyFingerprint prints the fingerprint of a keyring key, or of a public
+// key file.
+func runKeyFingerprint() {
+	key := parseArgs("imf key fingerprint", "imf key fingerprint <name|file>", 1)[0]
+	fmt.Println(imfcrypto.Fingerprint(mustReadPublicKey(key)))
+}
+
+// runKeyRecover re-derives a key from the recovery phrase printed by
+// "imf keygen -mnemonic" and adds it to the keyring under name, optionally
+// protected by a passphrase.
+func runKeyRecover() {
+	fs := flag.NewFlagSet("imf key recover", flag.ExitOnError)
+	protect := fs.Bool("protect", false, "Encrypt the recovered private key with a passphrase")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf key recover [-protect] <name>")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	kp, err := imfcrypto.KeyPairFromMnemonic(promptPassphrase("Recovery phrase: "))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	privPEM := imfcrypto.MarshalPrivateKeyPEM(kp.PrivateKey)
+	if *protect {
+		pp := promptPassphrase("Key passphrase: ")
+		if pp == "" {
+			fmt.Fprintln(os.Stderr, "Error: passphrase must not be empty")
+			os.Exit(1)
+		}
+		if promptPassphrase("Repeat passphrase: ") != pp {
+			fmt.Fprintln(os.Stderr, "Error: passphrases do not match")
+			os.Exit(1)
+		}
+		if privPEM, err = imfcrypto.MarshalEncryptedPrivateKeyPEM(kp.PrivateKey, pp); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+	}
+
+	key, err := mustOpenKeyring().Add(fs.Arg(0), kp.PublicKey, privPEM)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Recovered %s (%s)\n", key.Name, key.Fingerprint)
+}
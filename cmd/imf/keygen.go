@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/keyring"
 )
 
 // runKeygen handles the "imf keygen" command.
@@ -17,38 +19,284 @@ import (
 //   - imf_private.pem (mode 0600) — used for signing during seal
 //   - imf_public.pem  (mode 0644) — used for verification
 // The private key should be kept secret; the public key can be shared freely.
+// With -pkcs8 the files use the standard PEM encodings that openssl reads and
+// writes; imf accepts keys in either form. With -protect the private key is
+// encrypted under a passphrase, which imf asks for whenever the key is used.
+// With -store keychain the private key goes into the OS keychain under -name
+// instead of a file, and is used as "-key keychain:NAME"; only the public key
+// is written out. With -store keyring both halves go into the local keyring
+// under -name (see "imf key"), and the key is used as "-key NAME".
+// With -pq an ML-DSA-65 key pair is generated instead, for hybrid
+// post-quantum signatures alongside an Ed25519 key (seal -pq-key).
+// With -mnemonic the Ed25519 key is derived from a new 24-word recovery
+// phrase, which is printed once for a paper backup; "imf key recover"
+// re-creates the key from it. With --json the key's location is printed as a
+// keygenJSON, which carries the recovery phrase too.
 func runKeygen() {
 	fs := flag.NewFlagSet("imf keygen", flag.ExitOnError)
 	outDir := fs.String("out", ".", "Output directory for key files")
-	fs.Parse(os.Args[1:])
+	x25519 := fs.Bool("x25519", false, "Generate an X25519 recipient key pair for encryption instead")
+	pq := fs.Bool("pq", false, "Generate an ML-DSA-65 post-quantum key pair for hybrid signing instead")
+	standard := fs.Bool("pkcs8", false, "Write standard PKCS#8 / SubjectPublicKeyInfo PEM, readable by openssl")
+	protect := fs.Bool("protect", false, "Encrypt the private key file with a passphrase (Argon2id + AES-256-GCM)")
+	store := fs.String("store", "file", "Where to keep the key: file, keyring, or keychain")
+	name := fs.String("name", "imf", "Key name in the keyring or keychain")
+	mnemonic := fs.Bool("mnemonic", false, "Derive the key from a new 24-word recovery phrase, for a paper backup")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf keygen [options]")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
 
-	kp, err := imfcrypto.GenerateKeyPair()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if *protect && *standard {
+		fmt.Fprintln(os.Stderr, "Error: -protect and -pkcs8 cannot be combined")
+		os.Exit(1)
+	}
+	if *store != "file" && *store != "keyring" && *store != "keychain" {
+		fmt.Fprintf(os.Stderr, "Error: unknown -store %q (want file, keyring, or keychain)\n", *store)
+		os.Exit(1)
+	}
+	if *store == "keychain" && (*x25519 || *pq || *protect || *standard) {
+		fmt.Fprintln(os.Stderr, "Error: -store keychain cannot be combined with -x25519, -pq, -protect, or -pkcs8")
+		os.Exit(1)
+	}
+	if *mnemonic && (*x25519 || *pq) {
+		fmt.Fprintln(os.Stderr, "Error: -mnemonic cannot be combined with -x25519 or -pq")
 		os.Exit(1)
 	}
+	if *store == "keyring" && (*x25519 || *pq) {
+		fmt.Fprintln(os.Stderr, "Error: -store keyring cannot be combined with -x25519 or -pq")
+		os.Exit(1)
+	}
+
+	if *x25519 {
+		keygenRecipient(*outDir)
+		return
+	}
+	if *pq {
+		keygenPQ(*outDir)
+		return
+	}
+
+	var kp *imfcrypto.KeyPair
+	var phrase string
+	var err error
+	if *mnemonic {
+		if phrase, err = imfcrypto.GenerateMnemonic(); err == nil {
+			kp, err = imfcrypto.KeyPairFromMnemonic(phrase)
+		}
+	} else {
+		kp, err = imfcrypto.GenerateKeyPair()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if phrase != "" && !jsonOutput {
+		printMnemonic(phrase)
+	}
+	result := keygenJSON{
+		Type:           "Ed25519",
+		Store:          *store,
+		Fingerprint:    imfcrypto.Fingerprint(kp.PublicKey),
+		RecoveryPhrase: phrase,
+	}
 
 	if err := os.MkdirAll(*outDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	privPath := filepath.Join(*outDir, "imf_private.pem")
 	pubPath := filepath.Join(*outDir, "imf_public.pem")
 
-	if _, err := os.Stat(privPath); err == nil {
+	if *store == "keychain" {
+		if err := imfcrypto.StoreKeychainKey(*name, kp.PrivateKey); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		if err := os.WriteFile(pubPath, imfcrypto.MarshalPublicKeyPEM(kp.PublicKey), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing public key: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if jsonOutput {
+			result.PrivateKey, result.PublicKey = "keychain:"+*name, pubPath
+			printJSON(result)
+			return
+		}
+		fmt.Printf("Generated key pair:\n  Private: keychain:%s\n  Public:  %s\n", *name, pubPath)
+		return
+	}
+
+	if _, err := os.Stat(privPath); err == nil && *store == "file" {
 		fmt.Fprintf(os.Stderr, "Error: %s already exists\n", privPath)
 		os.Exit(1)
 	}
+	if *store == "keyring" {
+		if _, err := mustOpenKeyring().Get(*name); err == nil {
+			fmt.Fprintf(os.Stderr, "Error: %v: %s\n", keyring.ErrExists, *name)
+			os.Exit(1)
+		}
+	}
+
+	privPEM := imfcrypto.MarshalPrivateKeyPEM(kp.PrivateKey)
+	pubPEM := imfcrypto.MarshalPublicKeyPEM(kp.PublicKey)
+	if *standard {
+		if privPEM, err = imfcrypto.MarshalPrivateKeyPKCS8PEM(kp.PrivateKey); err == nil {
+			pubPEM, err = imfcrypto.MarshalPublicKeyPKIXPEM(kp.PublicKey)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+	}
+	if *protect {
+		pp := promptPassphrase("Key passphrase: ")
+		if pp == "" {
+			fmt.Fprintln(os.Stderr, "Error: passphrase must not be empty")
+			os.Exit(1)
+		}
+		if promptPassphrase("Repeat passphrase: ") != pp {
+			fmt.Fprintln(os.Stderr, "Error: passphrases do not match")
+			os.Exit(1)
+		}
+		if privPEM, err = imfcrypto.MarshalEncryptedPrivateKeyPEM(kp.PrivateKey, pp); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+	}
 
-	if err := os.WriteFile(privPath, imfcrypto.MarshalPrivateKeyPEM(kp.PrivateKey), 0600); err != nil {
+	if *store == "keyring" {
+		key, err := mustOpenKeyring().Add(*name, kp.PublicKey, privPEM)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		if jsonOutput {
+			result.PrivateKey = key.Name
+			printJSON(result)
+			return
+		}
+		fmt.Printf("Generated key %s in the keyring\n  Fingerprint: %s\n", key.Name, key.Fingerprint)
+		return
+	}
+
+	if err := os.WriteFile(privPath, privPEM, 0600); err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing private key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if err := os.WriteFile(pubPath, pubPEM, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing public key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if jsonOutput {
+		result.PrivateKey, result.PublicKey = privPath, pubPath
+		printJSON(result)
+		return
+	}
+	fmt.Printf("Generated key pair:\n  Private: %s (keep secret!)\n  Public:  %s\n", privPath, pubPath)
+}
+
+// keygenRecipient generates an X25519 recipient key pair:
+//   - imf_x25519_private.pem (mode 0600) — decrypts containers sealed to it
+//   - imf_x25519_public.pem  (mode 0644) — given to senders for seal -recipient
+func keygenRecipient(outDir string) {
+	key, err := imfcrypto.GenerateRecipientKey()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	privPath := filepath.Join(outDir, "imf_x25519_private.pem")
+	pubPath := filepath.Join(outDir, "imf_x25519_public.pem")
+
+	if _, err := os.Stat(privPath); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists\n", privPath)
 		os.Exit(1)
 	}
-	if err := os.WriteFile(pubPath, imfcrypto.MarshalPublicKeyPEM(kp.PublicKey), 0644); err != nil {
+
+	if err := os.WriteFile(privPath, imfcrypto.MarshalRecipientPrivateKeyPEM(key), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing private key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if err := os.WriteFile(pubPath, imfcrypto.MarshalRecipientPublicKeyPEM(key.PublicKey()), 0644); err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing public key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if jsonOutput {
+		printJSON(keygenJSON{Type: "X25519", Store: "file", PrivateKey: privPath, PublicKey: pubPath})
+		return
+	}
+	fmt.Printf("Generated recipient key pair:\n  Private: %s (keep secret!)\n  Public:  %s\n", privPath, pubPath)
+}
+
+// keygenPQ generates an ML-DSA-65 key pair for hybrid signatures:
+//   - imf_pq_private.pem (mode 0600) — passed to seal -pq-key
+//   - imf_pq_public.pem  (mode 0644) — passed to verify -pq-key
+func keygenPQ(outDir string) {
+	seed, err := imfcrypto.GeneratePQKey()
+	var pub []byte
+	if err == nil {
+		pub, err = imfcrypto.PQPublicKey(seed)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	privPath := filepath.Join(outDir, "imf_pq_private.pem")
+	pubPath := filepath.Join(outDir, "imf_pq_public.pem")
+
+	if _, err := os.Stat(privPath); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists\n", privPath)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Generated key pair:\n  Private: %s (keep secret!)\n  Public:  %s\n", privPath, pubPath)
+	if err := os.WriteFile(privPath, imfcrypto.MarshalPQPrivateKeyPEM(seed), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing private key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if err := os.WriteFile(pubPath, imfcrypto.MarshalPQPublicKeyPEM(pub), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing public key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if jsonOutput {
+		printJSON(keygenJSON{Type: imfcrypto.PQAlgorithm, Store: "file", PrivateKey: privPath, PublicKey: pubPath})
+		return
+	}
+	fmt.Printf("Generated %s key pair:\n  Private: %s (keep secret!)\n  Public:  %s\n", imfcrypto.PQAlgorithm, privPath, pubPath)
+}
+
+// printMnemonic shows a recovery phrase as numbered words, ready to copy
+// onto paper.
+func printMnemonic(phrase string) {
+	fmt.Println("Recovery phrase (write it down and keep it safe; anyone who has it can sign as you):")
+	fmt.Println()
+	words := strings.Fields(phrase)
+	for i := 0; i < len(words); i += 6 {
+		var row string
+		for j := i; j < i+6 && j < len(words); j++ {
+			row += fmt.Sprintf("  %2d. %-10s", j+1, words[j])
+		}
+		fmt.Println(strings.TrimRight(row, " "))
+	}
+	fmt.Println()
 }
@@ -7,6 +7,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path"
+	"sort"
 
 	"github.com/immutable-container/imf/pkg/container"
 )
@@ -14,19 +16,68 @@ import (
 // runList handles the "imf list" command.
 // Lists all files stored in a container with their names, sizes, and
 // truncated SHA-256 hashes. Works on both open and sealed containers.
+// The -l and --json modes add the ZIP path, encrypted hash, MIME type, and a
+// per-file hash check; -sort and -match shape the listing for scripts.
 func runList() {
 	fs := flag.NewFlagSet("imf list", flag.ExitOnError)
-	fs.Parse(os.Args[1:])
+	long := fs.Bool("l", false, "Long format: zip path, MIME type, encrypted hash, and hash check status")
+	sortBy := fs.String("sort", "", "Sort by: name or size (default: manifest order)")
+	match := fs.String("match", "", "Only list files whose name matches this glob")
+	passphrase := fs.String("passphrase", "", "Passphrase, to read a hidden manifest")
+	identity := fs.String("identity", "", "X25519 private key (PEM), to read a hidden manifest")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf list [options] <container.imf>")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+		fmt.Fprintln(os.Stderr, "  -json\n    \tOutput the listing as JSON (implies the -l fields)")
+	}
+	parseFlags(fs)
 
 	if fs.NArg() != 1 {
-		fmt.Fprintln(os.Stderr, "Usage: imf list <container.imf>")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *sortBy != "" && *sortBy != "name" && *sortBy != "size" {
+		fmt.Fprintf(os.Stderr, "Error: unknown sort key %q (want name or size)\n", *sortBy)
 		os.Exit(1)
 	}
+	if *match != "" {
+		if _, err := path.Match(*match, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -match pattern: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+	}
 
-	files, err := container.ListFiles(fs.Arg(0))
+	opts := container.ListOptions{
+		CheckHashes: *long || jsonOutput,
+		Passphrase:  *passphrase,
+	}
+	if *identity != "" {
+		opts.RecipientKey = mustReadRecipientPrivateKey(*identity)
+	}
+	// File names of a hidden manifest are encrypted; ask for the passphrase.
+	if opts.Passphrase == "" && opts.RecipientKey == nil {
+		if info, err := container.GetInfo(fs.Arg(0)); err == nil && info.Hidden {
+			opts.Passphrase = containerPassphrase("Passphrase: ", false)
+		}
+	}
+	files, err := container.ListFilesWithOptions(fs.Arg(0), opts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+
+	files = filterFiles(files, *match)
+	switch *sortBy {
+	case "name":
+		sort.SliceStable(files, func(i, j int) bool { return files[i].OriginalName < files[j].OriginalName })
+	case "size":
+		sort.SliceStable(files, func(i, j int) bool { return files[i].OriginalSize > files[j].OriginalSize })
+	}
+
+	if jsonOutput {
+		printJSON(newFilesJSON(files))
+		return
 	}
 
 	if len(files) == 0 {
@@ -34,6 +85,19 @@ func runList() {
 		return
 	}
 
+	if *long {
+		fmt.Printf("%-30s %10s  %-24s %-8s  %-40s %s\n", "NAME", "SIZE", "TYPE", "STATUS", "PATH", "SHA256")
+		fmt.Printf("%-30s %10s  %-24s %-8s  %-40s %s\n", "----", "----", "----", "------", "----", "------")
+		for _, f := range files {
+			fmt.Printf("%-30s %10d  %-24s %-8s  %-40s %s\n", f.OriginalName, f.OriginalSize, f.MimeType, f.Status, f.Path, f.SHA256)
+			if f.EncryptedSHA256 != "" {
+				fmt.Printf("%-30s %10s  %-24s %-8s  %-40s %s\n", "", "", "", "", "  (encrypted)", f.EncryptedSHA256)
+			}
+		}
+		fmt.Printf("\n%d file(s)\n", len(files))
+		return
+	}
+
 	fmt.Printf("%-30s %10s  %s\n", "NAME", "SIZE", "SHA256")
 	fmt.Printf("%-30s %10s  %s\n", "----", "----", "------")
 	for _, f := range files {
@@ -41,3 +105,18 @@ func runList() {
 	}
 	fmt.Printf("\n%d file(s)\n", len(files))
 }
+
+// filterFiles keeps only files whose name matches the glob pattern.
+// An empty pattern keeps everything.
+func filterFiles(files []container.FileInfo, pattern string) []container.FileInfo {
+	if pattern == "" {
+		return files
+	}
+	var out []container.FileInfo
+	for _, f := range files {
+		if ok, _ := path.Match(pattern, f.OriginalName); ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -20,61 +20,36 @@ import (
 	"os"
 )
 
-const usage = `imf — Immutable File Container
-
-Usage:
-  imf <command> [options]
-
-Commands:
-  create    Create a new empty .imf container
-  add       Add files to an open container
-  seal      Seal a container (sign, optionally encrypt)
-  verify    Verify a sealed container's integrity
-  extract   Extract files from a container
-  list      List files in a container
-  info      Show container metadata
-  keygen    Generate an Ed25519 key pair
-  anchor    Anchor container hash to Bitcoin via OpenTimestamps
-  gui       Launch the web-based graphical interface
-
-Run 'imf <command> -h' for command-specific help.
-`
-
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Print(usage)
+	args, asJSON := extractJSONFlag(os.Args[1:])
+	if len(args) == 0 {
+		printUsage(os.Stdout)
 		os.Exit(1)
 	}
 
-	cmd := os.Args[1]
-	os.Args = append([]string{os.Args[0] + " " + cmd}, os.Args[2:]...)
-
-	switch cmd {
-	case "create":
-		runCreate()
-	case "add":
-		runAdd()
-	case "seal":
-		runSeal()
-	case "verify":
-		runVerify()
-	case "extract":
-		runExtract()
-	case "list":
-		runList()
-	case "info":
-		runInfo()
-	case "keygen":
-		runKeygen()
-	case "anchor":
-		runAnchor()
-	case "gui":
-		runGUI()
+	name := args[0]
+	switch name {
 	case "help", "-h", "--help":
-		fmt.Print(usage)
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", cmd)
-		fmt.Print(usage)
+		// "imf help <command>" is "imf <command> -h".
+		if len(args) < 2 {
+			printUsage(os.Stdout)
+			return
+		}
+		name, args = args[1], []string{args[1], "-h"}
+	case "-version", "--version":
+		name, args = "version", []string{"version"}
+	}
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", name)
+		printUsage(os.Stderr)
+		os.Exit(1)
+	}
+	if asJSON && !cmd.json {
+		fmt.Fprintf(os.Stderr, "Error: imf %s has no JSON output\n", name)
 		os.Exit(1)
 	}
+	jsonOutput = asJSON
+	os.Args = append([]string{os.Args[0] + " " + name}, args[1:]...)
+	cmd.run()
 }
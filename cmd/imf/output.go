@@ -0,0 +1,687 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/container"
+	"github.com/immutable-container/imf/pkg/manifest"
+	"github.com/immutable-container/imf/pkg/sigstore"
+)
+
+// With --json, the commands that report results print a single JSON value
+// on stdout instead of text, for CI systems and other tools. The types
+// below are that output; their field names are documented in
+// docs/json-output.md and only ever gain new fields. Prompts, warnings and
+// errors still go to stderr, and a failure still exits non-zero.
+
+// jsonOutput is set by --json (or -json), anywhere on the command line.
+var jsonOutput bool
+
+// extractJSONFlag removes --json and -json from args, up to any "--", and
+// reports whether there was one.
+func extractJSONFlag(args []string) ([]string, bool) {
+	out := make([]string, 0, len(args))
+	found := false
+	for i, arg := range args {
+		if arg == "--" {
+			out = append(out, args[i:]...)
+			break
+		}
+		if arg == "--json" || arg == "-json" {
+			found = true
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out, found
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+}
+
+// printJSONLine writes v to stdout as one line of JSON, for commands that
+// report events as they happen.
+func printJSONLine(v any) {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+}
+
+type certJSON struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+}
+
+func newCertJSON(chain []*x509.Certificate) *certJSON {
+	if len(chain) == 0 {
+		return nil
+	}
+	return &certJSON{Subject: chain[0].Subject.String(), Issuer: chain[0].Issuer.String()}
+}
+
+// keylessJSON is the OIDC identity a keyless signing certificate was issued to.
+type keylessJSON struct {
+	Identity string `json:"identity"`
+	Issuer   string `json:"issuer"`
+}
+
+func newKeylessJSON(chain []*x509.Certificate) *keylessJSON {
+	if len(chain) == 0 {
+		return nil
+	}
+	id, iss := sigstore.Identity(chain[0])
+	return &keylessJSON{Identity: id, Issuer: iss}
+}
+
+type policyJSON struct {
+	Threshold int      `json:"threshold"`
+	Keys      []string `json:"keys"`
+	Signed    []string `json:"signed"`
+	Satisfied bool     `json:"satisfied"`
+}
+
+func newPolicyJSON(p *container.PolicyStatus) *policyJSON {
+	if p == nil {
+		return nil
+	}
+	return &policyJSON{
+		Threshold: p.Threshold,
+		Keys:      nonNil(p.Keys),
+		Signed:    nonNil(p.Signed),
+		Satisfied: p.Satisfied(),
+	}
+}
+
+type blockJSON struct {
+	Height uint64     `json:"height"`
+	Hash   string     `json:"hash,omitempty"`
+	Time   *time.Time `json:"time,omitempty"`
+}
+
+func newBlocksJSON(blocks []anchor.Block) []blockJSON {
+	out := []blockJSON{}
+	for _, b := range blocks {
+		j := blockJSON{Height: b.Height, Hash: b.Hash}
+		if !b.Time.IsZero() {
+			t := b.Time.UTC()
+			j.Time = &t
+		}
+		out = append(out, j)
+	}
+	return out
+}
+
+func heightBlocksJSON(heights []uint64) []blockJSON {
+	out := []blockJSON{}
+	for _, h := range heights {
+		out = append(out, blockJSON{Height: h})
+	}
+	return out
+}
+
+// infoJSON is the output of imf info.
+type infoJSON struct {
+	Container         string                   `json:"container"`
+	State             string                   `json:"state"`
+	CreatedAt         *time.Time               `json:"created_at,omitempty"`
+	SealedAt          *time.Time               `json:"sealed_at,omitempty"`
+	ExpiresAt         *time.Time               `json:"expires_at,omitempty"`
+	Expired           bool                     `json:"expired"`
+	Encrypted         bool                     `json:"encrypted"`
+	TimeLock          *time.Time               `json:"time_lock,omitempty"`
+	HiddenManifest    bool                     `json:"hidden_manifest"`
+	EmbeddedPublicKey bool                     `json:"embedded_public_key"`
+	Signer            *manifest.SignerIdentity `json:"signer,omitempty"`
+	PostQuantum       string                   `json:"post_quantum,omitempty"`
+	Keyless           *keylessJSON             `json:"keyless,omitempty"`
+	Certificate       *certJSON                `json:"certificate,omitempty"`
+	Policy            *policyJSON              `json:"policy,omitempty"`
+	Metadata          *manifest.Metadata       `json:"metadata,omitempty"`
+	FileCount         int                      `json:"file_count"`
+	Anchor            *anchorStatusJSON        `json:"anchor,omitempty"`
+}
+
+func newInfoJSON(path string, info *container.Info) infoJSON {
+	j := infoJSON{
+		Container:         path,
+		State:             string(info.State),
+		SealedAt:          info.SealedAt,
+		ExpiresAt:         info.ExpiresAt,
+		Expired:           info.Expired,
+		Encrypted:         info.Encrypted,
+		TimeLock:          info.TimeLock,
+		HiddenManifest:    info.Hidden,
+		EmbeddedPublicKey: info.HasPubKey,
+		Signer:            info.Signer,
+		PostQuantum:       info.PQ,
+		Policy:            newPolicyJSON(info.Policy),
+		Metadata:          info.Metadata,
+		FileCount:         info.FileCount,
+	}
+	if !info.CreatedAt.IsZero() {
+		j.CreatedAt = &info.CreatedAt
+	}
+	if info.Keyless {
+		j.Keyless = newKeylessJSON(info.CertChain)
+	} else {
+		j.Certificate = newCertJSON(info.CertChain)
+	}
+	return j
+}
+
+// anchorStatusJSON is the anchor of a container, as imf info reports it.
+type anchorStatusJSON struct {
+	State    string      `json:"state"` // pending, confirmed, mismatch, or unreadable
+	Hash     string      `json:"hash,omitempty"`
+	Target   string      `json:"target,omitempty"`
+	Proof    string      `json:"proof,omitempty"`
+	Embedded bool        `json:"embedded"`
+	Blocks   []blockJSON `json:"blocks"`
+	Pending  []string    `json:"pending"`
+	Error    string      `json:"error,omitempty"`
+}
+
+func newAnchorStatusJSON(s *anchor.StatusResult) *anchorStatusJSON {
+	return &anchorStatusJSON{
+		State:    s.State,
+		Hash:     s.Hash,
+		Target:   s.Target,
+		Proof:    s.ProofPath,
+		Embedded: s.Embedded,
+		Blocks:   newBlocksJSON(s.Blocks),
+		Pending:  nonNil(s.Pending),
+	}
+}
+
+// fileJSON is one file in the output of imf list.
+type fileJSON struct {
+	Name            string `json:"name"`
+	Size            int64  `json:"size"`
+	SHA256          string `json:"sha256"`
+	Path            string `json:"path"`
+	EncryptedSHA256 string `json:"encrypted_sha256,omitempty"`
+	MimeType        string `json:"mime_type"`
+	Status          string `json:"status"`
+}
+
+func newFilesJSON(files []container.FileInfo) []fileJSON {
+	out := []fileJSON{}
+	for _, f := range files {
+		out = append(out, fileJSON{
+			Name:            f.OriginalName,
+			Size:            f.OriginalSize,
+			SHA256:          f.SHA256,
+			Path:            f.Path,
+			EncryptedSHA256: f.EncryptedSHA256,
+			MimeType:        f.MimeType,
+			Status:          f.Status,
+		})
+	}
+	return out
+}
+
+type timestampJSON struct {
+	Time    time.Time `json:"time"`
+	TSA     string    `json:"tsa"`
+	Trusted bool      `json:"trusted"`
+}
+
+type transparencyLogJSON struct {
+	LogIndex int64     `json:"log_index"`
+	Time     time.Time `json:"time"`
+	URL      string    `json:"url,omitempty"`
+	Proven   bool      `json:"inclusion_proven"`
+}
+
+type witnessJSON struct {
+	Name      string    `json:"name,omitempty"`
+	PublicKey string    `json:"public_key"`
+	Time      time.Time `json:"time"`
+}
+
+type revocationJSON struct {
+	RevokedAt time.Time `json:"revoked_at"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// verifyJSON is the output of imf verify. Verified is false, with Error
+// set, when the container fails verification.
+type verifyJSON struct {
+	Container         string                   `json:"container"`
+	Verified          bool                     `json:"verified"`
+	Error             string                   `json:"error,omitempty"`
+	Signer            *manifest.SignerIdentity `json:"signer,omitempty"`
+	Keyless           *keylessJSON             `json:"keyless,omitempty"`
+	Certificate       *certJSON                `json:"certificate,omitempty"`
+	SealedAt          *time.Time               `json:"sealed_at,omitempty"`
+	Timestamp         *timestampJSON           `json:"timestamp,omitempty"`
+	TransparencyLog   *transparencyLogJSON     `json:"transparency_log,omitempty"`
+	AnchorBlocks      []blockJSON              `json:"anchor_blocks,omitempty"`
+	PostQuantum       string                   `json:"post_quantum,omitempty"`
+	DetachedSignature *time.Time               `json:"detached_signature_signed_at,omitempty"`
+	Policy            *policyJSON              `json:"policy,omitempty"`
+	Witnesses         []witnessJSON            `json:"witnesses"`
+	Revoked           *revocationJSON          `json:"revoked,omitempty"`
+	Files             []fileCheckJSON          `json:"files,omitempty"`
+	UnsignedEntries   []string                 `json:"unsigned_entries,omitempty"`
+}
+
+// fileCheckJSON is the integrity check of one file by imf verify.
+type fileCheckJSON struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	Encrypted bool   `json:"encrypted"`
+	Expected  string `json:"expected_sha256"`
+	Computed  string `json:"computed_sha256,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func newFileChecksJSON(files []container.FileCheck) []fileCheckJSON {
+	out := make([]fileCheckJSON, len(files))
+	for i, f := range files {
+		out[i] = fileCheckJSON{Name: f.Name, Path: f.Path, Status: f.Status, Encrypted: f.Encrypted, Expected: f.Expected, Computed: f.Computed}
+		if f.Err != nil {
+			out[i].Error = f.Err.Error()
+		}
+	}
+	return out
+}
+
+// newFailedVerifyJSON is the verifyJSON of a container that failed to
+// verify, with the check of each file if verification got that far.
+func newFailedVerifyJSON(path string, r *container.VerifyReport, err error) verifyJSON {
+	j := verifyJSON{Container: path, Error: err.Error(), Witnesses: []witnessJSON{}}
+	if r != nil {
+		j.Files = newFileChecksJSON(r.Files)
+	}
+	return j
+}
+
+func newVerifyJSON(path string, r *container.VerifyReport, opts container.VerifyOptions) verifyJSON {
+	j := verifyJSON{
+		Container:       path,
+		Verified:        true,
+		Signer:          r.Signer,
+		SealedAt:        r.SealedAt,
+		PostQuantum:     r.PQ,
+		Policy:          newPolicyJSON(r.Policy),
+		Witnesses:       []witnessJSON{},
+		Files:           newFileChecksJSON(r.Files),
+		UnsignedEntries: r.Unsigned,
+	}
+	if r.Rekor != nil {
+		j.Keyless = newKeylessJSON(r.Chain)
+		j.TransparencyLog = &transparencyLogJSON{LogIndex: r.Rekor.LogIndex, Time: r.Rekor.Time().UTC(), Proven: opts.RekorKey != nil}
+	} else {
+		j.Certificate = newCertJSON(r.Chain)
+	}
+	if ts := r.Timestamp; ts != nil {
+		j.Timestamp = &timestampJSON{Time: ts.Time.UTC(), TSA: ts.Signer.Subject.String(), Trusted: opts.TSARoots != nil}
+	}
+	if r.Anchor != nil {
+		j.AnchorBlocks = heightBlocksJSON(r.Anchor.Blocks())
+	}
+	if opts.Detached != nil {
+		j.DetachedSignature = &opts.Detached.SignedAt
+	}
+	for _, w := range r.Witnesses {
+		j.Witnesses = append(j.Witnesses, witnessJSON{Name: w.Name, PublicKey: w.PublicKey, Time: w.Timestamp})
+	}
+	if rev := r.Revocation; rev != nil {
+		j.Revoked = &revocationJSON{RevokedAt: rev.RevokedAt, Reason: rev.Reason}
+	}
+	return j
+}
+
+type sealFileJSON struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	Path      string `json:"path"`
+	Encrypted bool   `json:"encrypted"`
+}
+
+// sealAnchorJSON is the anchor submitted, or queued, once sealed.
+type sealAnchorJSON struct {
+	Backend string `json:"backend"`
+	Proof   string `json:"proof,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+	Queued  bool   `json:"queued"`
+	Error   string `json:"error,omitempty"`
+}
+
+// sealJSON is the output of imf seal, and of imf seal -dry-run with DryRun
+// set and nothing written.
+type sealJSON struct {
+	Container       string                   `json:"container"`
+	DryRun          bool                     `json:"dry_run"`
+	Encrypted       bool                     `json:"encrypted"`
+	Algorithm       string                   `json:"algorithm,omitempty"`
+	KDF             string                   `json:"kdf,omitempty"`
+	Iterations      int                      `json:"kdf_iterations,omitempty"`
+	Recipients      int                      `json:"recipients,omitempty"`
+	TimeLock        *time.Time               `json:"time_lock,omitempty"`
+	HiddenManifest  bool                     `json:"hidden_manifest"`
+	PublicKey       string                   `json:"embedded_public_key,omitempty"`
+	Signer          *manifest.SignerIdentity `json:"signer,omitempty"`
+	Keyless         *keylessJSON             `json:"keyless,omitempty"`
+	Certificate     *certJSON                `json:"certificate,omitempty"`
+	PostQuantum     string                   `json:"post_quantum,omitempty"`
+	Timestamp       *timestampJSON           `json:"timestamp,omitempty"`
+	TransparencyLog *transparencyLogJSON     `json:"transparency_log,omitempty"`
+	Policy          *policyJSON              `json:"policy,omitempty"`
+	ExpiresAt       *time.Time               `json:"expires_at,omitempty"`
+	ManifestSHA256  string                   `json:"manifest_sha256"`
+	Anchor          *sealAnchorJSON          `json:"anchor,omitempty"`
+	Files           []sealFileJSON           `json:"files"`
+}
+
+func newSealJSON(path string, r *container.SealReport, recipients int) sealJSON {
+	j := sealJSON{
+		Container:      path,
+		DryRun:         r.DryRun,
+		Encrypted:      r.Encrypted,
+		Algorithm:      r.Algorithm,
+		KDF:            r.KDF,
+		Iterations:     r.Iterations,
+		Recipients:     recipients,
+		TimeLock:       r.TimeLock,
+		HiddenManifest: r.HiddenManifest,
+		PublicKey:      r.PublicKey,
+		Signer:         r.Signer,
+		PostQuantum:    r.PQ,
+		Policy:         newPolicyJSON(r.Policy),
+		ExpiresAt:      r.ExpiresAt,
+		ManifestSHA256: r.SignedSHA256,
+		Files:          []sealFileJSON{},
+	}
+	if r.Rekor != "" {
+		j.Keyless = newKeylessJSON(r.CertChain)
+	} else {
+		j.Certificate = newCertJSON(r.CertChain)
+	}
+	if r.Timestamp != nil {
+		j.Timestamp = &timestampJSON{Time: r.Timestamp.UTC(), TSA: r.TSA}
+	}
+	if r.RekorEntry != nil {
+		j.TransparencyLog = &transparencyLogJSON{LogIndex: r.RekorEntry.LogIndex, Time: r.RekorEntry.Time().UTC(), URL: r.Rekor}
+	}
+	if r.AnchorBackend != "" && !r.DryRun {
+		j.Anchor = &sealAnchorJSON{Backend: r.AnchorBackend}
+		if r.Anchor != nil {
+			j.Anchor.Proof, j.Anchor.Digest = r.Anchor.ProofPath, r.Anchor.Digest
+		} else if r.AnchorErr != nil {
+			j.Anchor.Queued, j.Anchor.Error = true, r.AnchorErr.Error()
+		}
+	}
+	for _, f := range r.Files {
+		j.Files = append(j.Files, sealFileJSON{Name: f.Name, Size: f.Size, SHA256: f.SHA256, Path: f.Path, Encrypted: f.Encrypted})
+	}
+	return j
+}
+
+// keygenJSON is the output of imf keygen. PrivateKey is what to pass as
+// -key: a file path, keychain:NAME, or the name of a key in the keyring.
+type keygenJSON struct {
+	Type           string `json:"type"`  // Ed25519, X25519 or ML-DSA-65
+	Store          string `json:"store"` // file, keyring, or keychain
+	PrivateKey     string `json:"private_key"`
+	PublicKey      string `json:"public_key,omitempty"`
+	Fingerprint    string `json:"fingerprint,omitempty"`
+	RecoveryPhrase string `json:"recovery_phrase,omitempty"`
+}
+
+// versionJSON is the output of imf version. Commit and BuildDate are empty
+// when they are not known.
+type versionJSON struct {
+	Version          string `json:"version"`
+	Commit           string `json:"commit"`
+	BuildDate        string `json:"build_date"`
+	GoVersion        string `json:"go_version"`
+	Platform         string `json:"platform"` // GOOS/GOARCH
+	ManifestVersions []int  `json:"manifest_versions"`
+}
+
+// anchorJSON is the output of imf anchor for a new anchor, and one element
+// of the output of imf anchor -batch.
+type anchorJSON struct {
+	Container     string    `json:"container"`
+	Backend       string    `json:"backend"`
+	ContainerHash string    `json:"container_hash"`
+	Target        string    `json:"target"`
+	Digest        string    `json:"digest"`
+	Proof         string    `json:"proof"`
+	Servers       []string  `json:"servers"`
+	MerkleRoot    string    `json:"merkle_root,omitempty"`
+	Submitted     time.Time `json:"submitted"`
+}
+
+// anchorPlanJSON is the output of imf anchor -dry-run.
+type anchorPlanJSON struct {
+	Container     string   `json:"container"`
+	DryRun        bool     `json:"dry_run"`
+	Backend       string   `json:"backend"`
+	ContainerHash string   `json:"container_hash"`
+	Target        string   `json:"target"`
+	Digest        string   `json:"digest"`
+	Requests      []string `json:"requests"`
+	MinAccepted   int      `json:"min_accepted"`
+	Proof         string   `json:"proof"`
+	Overwrites    bool     `json:"overwrites"`
+	Log           string   `json:"log,omitempty"`
+}
+
+func newAnchorPlanJSON(path string, p *anchor.AnchorPlan) anchorPlanJSON {
+	return anchorPlanJSON{
+		Container:     path,
+		DryRun:        true,
+		Backend:       p.Backend,
+		ContainerHash: p.ContainerHash,
+		Target:        p.Target,
+		Digest:        p.Digest,
+		Requests:      p.Requests,
+		MinAccepted:   p.MinAccepted,
+		Proof:         p.ProofPath,
+		Overwrites:    p.Overwrites,
+		Log:           p.LogPath,
+	}
+}
+
+func newAnchorJSON(path, backend string, r *anchor.AnchorResult) anchorJSON {
+	j := anchorJSON{
+		Container:     path,
+		Backend:       backend,
+		ContainerHash: r.ContainerHash,
+		Target:        r.Target,
+		Digest:        r.Digest,
+		Proof:         r.ProofPath,
+		Servers:       nonNil(r.Servers),
+		MerkleRoot:    r.MerkleRoot,
+		Submitted:     r.Timestamp.UTC(),
+	}
+	if len(j.Servers) == 0 && r.Server != "" {
+		j.Servers = []string{r.Server}
+	}
+	return j
+}
+
+// anchorVerifyJSON is the output of imf anchor -verify.
+type anchorVerifyJSON struct {
+	Container     string `json:"container"`
+	Backend       string `json:"backend"`
+	Verified      bool   `json:"verified"`
+	Error         string `json:"error,omitempty"`
+	ContainerHash string `json:"container_hash,omitempty"`
+	Target        string `json:"target,omitempty"`
+	Proof         string `json:"proof,omitempty"`
+	ProofSize     int    `json:"proof_size,omitempty"`
+	Embedded      bool   `json:"embedded"`
+}
+
+// anchorUpgradeJSON is the output of imf anchor -upgrade and -embed.
+type anchorUpgradeJSON struct {
+	Container     string      `json:"container"`
+	ContainerHash string      `json:"container_hash"`
+	Complete      bool        `json:"complete"`
+	Blocks        []blockJSON `json:"blocks"`
+	Pending       []string    `json:"pending"`
+	Proof         string      `json:"proof"`
+	Upgraded      bool        `json:"upgraded"`
+	Embedded      bool        `json:"embedded"`
+}
+
+func newAnchorUpgradeJSON(path string, r *anchor.UpgradeResult) anchorUpgradeJSON {
+	return anchorUpgradeJSON{
+		Container:     path,
+		ContainerHash: r.ContainerHash,
+		Complete:      r.Complete(),
+		Blocks:        newBlocksJSON(r.Blocks),
+		Pending:       nonNil(r.Pending),
+		Proof:         r.ProofPath,
+		Upgraded:      r.Upgraded,
+		Embedded:      r.Embedded,
+	}
+}
+
+// proofNodeJSON is a node of an OpenTimestamps proof: the message at that
+// point, the attestations to it, and the operations that lead on from it.
+type proofNodeJSON struct {
+	Message      string            `json:"message"`
+	Attestations []string          `json:"attestations,omitempty"`
+	Operations   []proofBranchJSON `json:"operations,omitempty"`
+}
+
+type proofBranchJSON struct {
+	Op   string        `json:"op"`
+	Next proofNodeJSON `json:"next"`
+}
+
+func newProofNodeJSON(t *anchor.Timestamp) proofNodeJSON {
+	j := proofNodeJSON{Message: hex.EncodeToString(t.Msg)}
+	for _, a := range t.Attestations {
+		j.Attestations = append(j.Attestations, a.String())
+	}
+	for _, br := range t.Ops {
+		j.Operations = append(j.Operations, proofBranchJSON{Op: br.Op.String(), Next: newProofNodeJSON(br.Timestamp)})
+	}
+	return j
+}
+
+// anchorInspectJSON is the output of imf anchor -inspect.
+type anchorInspectJSON struct {
+	Container    string         `json:"container"`
+	Proof        string         `json:"proof"`
+	Embedded     bool           `json:"embedded"`
+	Size         int            `json:"size"`
+	Digest       string         `json:"digest"`
+	Matches      bool           `json:"matches"`
+	Target       string         `json:"target,omitempty"`
+	Complete     bool           `json:"complete"`
+	Blocks       []blockJSON    `json:"blocks"`
+	Calendars    []string       `json:"calendars"`
+	Attestations map[string]int `json:"attestations"` // count by kind: bitcoin, pending, other
+	Tree         proofNodeJSON  `json:"tree"`
+}
+
+// anchorRetryJSON is one element of the output of imf anchor -retry.
+type anchorRetryJSON struct {
+	Container string `json:"container"`
+	Backend   string `json:"backend"`
+	Result    string `json:"result"` // anchored, queued, or dropped
+	Proof     string `json:"proof,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// anchorStateJSON is one element of the output of imf anchor status.
+type anchorStateJSON struct {
+	Container string `json:"container"`
+	State     string `json:"state"` // confirmed, notarized, pending, mismatch, or missing
+	Detail    string `json:"detail,omitempty"`
+}
+
+// watchEventJSON is one line of the output of imf anchor -watch.
+type watchEventJSON struct {
+	Time      time.Time   `json:"time"`
+	Event     string      `json:"event"` // confirmed, embedded, or error
+	Container string      `json:"container"`
+	Blocks    []blockJSON `json:"blocks,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// inboxEventJSON is one line of the output of imf watch.
+type inboxEventJSON struct {
+	Time        time.Time `json:"time"`
+	Event       string    `json:"event"` // sealed or error
+	Item        string    `json:"item"`
+	Container   string    `json:"container,omitempty"`
+	Files       int       `json:"files,omitempty"`
+	Anchor      string    `json:"anchor,omitempty"`
+	AnchorError string    `json:"anchor_error,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// inspectJSON is the output of imf inspect.
+type inspectJSON struct {
+	Container    string              `json:"container"`
+	ManifestSize int                 `json:"manifest_size"` // bytes of manifest.json as stored
+	SignedSize   int                 `json:"signed_size"`
+	SignedSHA256 string              `json:"signed_sha256"` // of the bytes the signature is over
+	Manifest     *manifest.Manifest  `json:"manifest"`
+	Hidden       *hiddenManifestJSON `json:"hidden,omitempty"`
+}
+
+// hiddenManifestJSON is the decrypted manifest of a hidden container in
+// imf inspect.
+type hiddenManifestJSON struct {
+	SignedSize   int                `json:"signed_size"`
+	SignedSHA256 string             `json:"signed_sha256"`
+	Manifest     *manifest.Manifest `json:"manifest"`
+}
+
+func newInspectJSON(path string, in *container.Inspection) inspectJSON {
+	signed := sha256.Sum256(in.Signable)
+	j := inspectJSON{
+		Container:    path,
+		ManifestSize: len(in.Stored),
+		SignedSize:   len(in.Signable),
+		SignedSHA256: hex.EncodeToString(signed[:]),
+		Manifest:     in.Manifest,
+	}
+	if in.Hidden != nil {
+		hidden := sha256.Sum256(in.HiddenSignable)
+		j.Hidden = &hiddenManifestJSON{
+			SignedSize:   len(in.HiddenSignable),
+			SignedSHA256: hex.EncodeToString(hidden[:]),
+			Manifest:     in.Hidden,
+		}
+	}
+	return j
+}
+
+// nonNil returns s, or an empty slice for nil, so that it is encoded as
+// [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
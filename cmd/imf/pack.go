@@ -0,0 +1,111 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// runPack handles the "imf pack" command.
+// Creates a container, adds a directory tree with its relative paths, and
+// seals it in one step. The container only appears at the -out path once it
+// is fully sealed, so scripts never observe a half-built archive.
+func runPack() {
+	fs := flag.NewFlagSet("imf pack", flag.ExitOnError)
+	out := fs.String("out", "", "Path of the container to create (.imf)")
+	keyPath := fs.String("key", "", "Path to Ed25519 private key (PEM), keychain:NAME, hw:[NAME], or pkcs11:[LABEL]")
+	embedPub := fs.Bool("embed-pubkey", false, "Embed public key in container")
+	passphrase := fs.String("passphrase", "", "Encryption passphrase ('none' to skip)")
+	iterations := fs.Int("kdf-iterations", 0, "PBKDF2 iterations for the passphrase (default 600000)")
+	expiresStr := fs.String("expires", "", "Expiration time (RFC3339)")
+	symlinks := fs.String("symlinks", "follow", "Symlink policy: follow, store, or reject")
+	dryRun := fs.Bool("dry-run", false, "Validate and show what would be sealed, without writing")
+	signerName := fs.String("name", "", "Your name, recorded with the signature")
+	signerEmail := fs.String("email", "", "Your email, recorded with the signature")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf pack <directory> -out <container.imf> -key <private.pem> [options]")
+		fmt.Fprintln(os.Stderr, "\nCreate, fill, and seal a container from a directory in one step.")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 || *out == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	dir := fs.Arg(0)
+	*keyPath = defaultKey(*keyPath)
+	if *keyPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -key is required (or set key in ~/.imf/config)")
+		os.Exit(1)
+	}
+	if *iterations == 0 {
+		*iterations = settings().KDFIterations
+	}
+
+	policy, err := manifest.ParseSymlinkPolicy(*symlinks)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+
+	pp := *passphrase
+	if pp == "" {
+		pp = containerPassphrase("Encryption passphrase (enter to skip): ", true)
+	}
+	if pp == "none" {
+		pp = ""
+	}
+
+	signer := mustLoadSigner(*keyPath)
+	defer releaseSigner(signer)
+	opts := container.PackOptions{
+		Add: container.AddOptions{SymlinkPolicy: policy},
+		Seal: container.SealOptions{
+			Signer:      signer,
+			EmbedPubKey: *embedPub,
+			Passphrase:  pp,
+			Iterations:  *iterations,
+			SignerName:  *signerName,
+			SignerEmail: *signerEmail,
+			DryRun:      *dryRun,
+		},
+	}
+	if *expiresStr != "" {
+		t, err := time.Parse(time.RFC3339, *expiresStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing expiry: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.Seal.ExpiresAt = &t
+	}
+
+	report, err := container.Pack(dir, *out, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if *dryRun {
+		printSealReport(*out, report)
+		return
+	}
+
+	fmt.Printf("Packed %d file(s) from %s into %s\n", len(report.Files), dir, *out)
+	if report.Encrypted {
+		fmt.Println("  Encrypted: yes")
+	}
+	if report.EmbedPublicKey {
+		fmt.Println("  Public key: embedded")
+	}
+	if report.ExpiresAt != nil {
+		fmt.Printf("  Expires: %s\n", report.ExpiresAt.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/container"
+	"golang.org/x/term"
+)
+
+// progressBar draws the progress of a long operation on stderr, as one line
+// redrawn in place, so that a slow key derivation or a multi-gigabyte
+// encryption does not look like a hang. A nil progressBar draws nothing.
+type progressBar struct {
+	mu    sync.Mutex
+	stage string
+	drawn time.Time // when the line was last drawn
+	width int       // length of the line drawn, to blank it
+}
+
+// Stages other than the container package's, counted in items.
+const (
+	stageCalendars  = "calendars"  // anchor: calendars that have answered
+	stageContainers = "containers" // verify -dir: containers verified
+	stageDownload   = "download"   // update: bytes of the new binary
+)
+
+// stageLabels name each stage on its bar.
+var stageLabels = map[string]string{
+	container.StageRead:      "Reading",
+	container.StageDeriveKey: "Deriving key",
+	container.StageEncrypt:   "Encrypting",
+	container.StageDecrypt:   "Decrypting",
+	container.StageHash:      "Verifying",
+	container.StageWrite:     "Writing",
+	stageCalendars:           "Anchoring",
+	stageContainers:          "Verifying",
+	stageDownload:            "Downloading",
+}
+
+// progressInterval is the least time between redraws, except that the
+// first and last of each stage are always drawn.
+const progressInterval = 100 * time.Millisecond
+
+// progressWidth is the number of cells in a bar.
+const progressWidth = 30
+
+// newProgressBar returns a bar for a command run without -quiet, or nil if
+// quiet is set or stderr is not a terminal, where redrawing a line would
+// only fill a log with carriage returns.
+func newProgressBar(quiet bool) *progressBar {
+	if quiet || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return &progressBar{}
+}
+
+// progress returns the bar's update function for the container package, or
+// nil if there is no bar.
+func (b *progressBar) progress() container.Progress {
+	if b == nil {
+		return nil
+	}
+	return b.update
+}
+
+// calendars returns a function reporting the calendars answering an anchor
+// submission, or nil if there is no bar.
+func (b *progressBar) calendars() func(answered, total int) {
+	if b == nil {
+		return nil
+	}
+	return func(answered, total int) {
+		b.update(stageCalendars, int64(answered), int64(total))
+	}
+}
+
+// anchorer returns a, reporting to the bar as calendars answer if it is the
+// OpenTimestamps backend.
+func (b *progressBar) anchorer(a anchor.Anchorer) anchor.Anchorer {
+	if o, ok := a.(anchor.OpenTimestamps); ok && b != nil {
+		o.Options.Progress = b.calendars()
+		return o
+	}
+	return a
+}
+
+// update draws done of total units of stage.
+func (b *progressBar) update(stage string, done, total int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if stage == b.stage && done < total && now.Sub(b.drawn) < progressInterval {
+		return
+	}
+	b.stage, b.drawn = stage, now
+
+	frac := 1.0
+	if total > 0 {
+		frac = min(float64(done)/float64(total), 1)
+	}
+	filled := int(frac * progressWidth)
+	label, ok := stageLabels[stage]
+	if !ok {
+		label = stage
+	}
+	line := fmt.Sprintf("%-13s[%s%s] %3.0f%%", label, strings.Repeat("#", filled), strings.Repeat("-", progressWidth-filled), frac*100)
+	switch stage {
+	case container.StageDeriveKey:
+	case container.StageRead, stageCalendars, stageContainers:
+		line += fmt.Sprintf("  %d/%d", done, total)
+	default:
+		line += fmt.Sprintf("  %s / %s", formatBytes(done), formatBytes(total))
+	}
+	pad := max(b.width-len(line), 0)
+	fmt.Fprintf(os.Stderr, "\r%s%s", line, strings.Repeat(" ", pad))
+	b.width = len(line)
+}
+
+// clear blanks the bar's line, ready for the command's own output.
+func (b *progressBar) clear() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.width > 0 {
+		fmt.Fprintf(os.Stderr, "\r%s\r", strings.Repeat(" ", b.width))
+		b.width = 0
+	}
+	b.stage = ""
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// stdin is shared by all prompts so that buffered input meant for a later
+// prompt (e.g. piped answers) is not lost.
+var stdin = bufio.NewReader(os.Stdin)
+
+// promptPassphrase reads a passphrase from stdin after printing prompt to
+// stderr. On a terminal the passphrase is not echoed; piped input is read a
+// line at a time.
+func promptPassphrase(prompt string) string {
+	fmt.Fprint(os.Stderr, printer().Translate(prompt))
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, _ := stdin.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+
+	// Turn echo back on if interrupted, or the shell is left without it.
+	state, err := term.GetState(fd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "\n"+tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	done := make(chan struct{})
+	defer func() {
+		signal.Stop(interrupt)
+		close(done)
+	}()
+	go func() {
+		select {
+		case <-interrupt:
+			term.Restore(fd, state)
+			fmt.Fprintln(os.Stderr)
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// containerPassphrase returns the passphrase that encrypts or decrypts a
+// container when none was given with -passphrase: $IMF_PASSPHRASE if set,
+// for scripts, or else one read with prompt. A new passphrase (confirm) is
+// asked for twice on a terminal, so that a typo does not lock the container.
+func containerPassphrase(prompt string, confirm bool) string {
+	if pp, ok := os.LookupEnv("IMF_PASSPHRASE"); ok {
+		return pp
+	}
+	pp := promptPassphrase(prompt)
+	if confirm && pp != "" && pp != "none" && term.IsTerminal(int(os.Stdin.Fd())) {
+		if promptPassphrase("Repeat passphrase: ") != pp {
+			fmt.Fprintln(os.Stderr, tr("Error: passphrases do not match"))
+			os.Exit(1)
+		}
+	}
+	return pp
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/container"
+	"github.com/immutable-container/imf/pkg/report"
+)
+
+// runReceipt handles the "imf receipt" command.
+// Verifies a local container, as "imf verify" does, and writes the result
+// as a verification certificate: the container's SHA-256, the signer and
+// key fingerprint, the seal time, the status of its Bitcoin anchor, and the
+// hash check of every file, formatted to be printed and filed. The format
+// is PDF or HTML, by -format or the extension of -out. A container that
+// fails verification still gets a certificate, recording the failure, and
+// the command then exits with the status verify would. With -online, the
+// blocks of a confirmed anchor are looked up to show their times.
+func runReceipt() {
+	fs := flag.NewFlagSet("imf receipt", flag.ExitOnError)
+	out := fs.String("out", "", "File to write the certificate to (.pdf or .html)")
+	format := fs.String("format", "", "Certificate format: pdf or html (default from the -out extension)")
+	keyPath := fs.String("key", "", "Path to Ed25519 public key (PEM) or keyring key name. Uses embedded key if omitted.")
+	trusted := fs.Bool("trusted", false, "Accept an embedded key only if it is in the trust store (see 'imf trust')")
+	ignoreExpiry := fs.Bool("ignore-expiry", false, "Verify even if container is expired")
+	online := fs.Bool("online", false, "Look up the Bitcoin blocks of a confirmed anchor")
+	quiet := fs.Bool("quiet", false, "Do not show a progress bar")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf receipt <container.imf> -out <certificate.pdf|certificate.html> [options]")
+		fmt.Fprintln(os.Stderr, "\nVerify a container and write a printable verification certificate.")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+
+	if fs.NArg() != 1 || *out == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*out)) {
+		case ".pdf":
+			*format = "pdf"
+		case ".html", ".htm":
+			*format = "html"
+		default:
+			fmt.Fprintln(os.Stderr, "Error: cannot tell the format from -out; name it .pdf or .html, or give -format")
+			os.Exit(1)
+		}
+	}
+	write := report.WritePDF
+	switch *format {
+	case "pdf":
+	case "html":
+		write = report.WriteHTML
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (want pdf or html)\n", *format)
+		os.Exit(1)
+	}
+
+	opts := report.Options{Verify: container.VerifyOptions{IgnoreExpiry: *ignoreExpiry}}
+	if *keyPath != "" {
+		opts.Verify.PublicKey = mustReadPublicKey(*keyPath)
+	}
+	if *trusted {
+		keys, err := mustOpenTrustStore().PublicKeys()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading trust store: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.Verify.RequireTrusted = true
+		opts.Verify.TrustedKeys = keys
+	}
+	if *online {
+		opts.Anchor.Explorer = os.Getenv("IMF_EXPLORER_URL")
+		if opts.Anchor.Explorer == "" {
+			opts.Anchor.Explorer = anchor.DefaultExplorer
+		}
+	}
+
+	bar := newProgressBar(*quiet)
+	opts.Verify.Progress = bar.progress()
+	c, err := report.New(fs.Arg(0), opts)
+	bar.clear()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	var buf bytes.Buffer
+	if err := write(&buf, c); err == nil {
+		err = os.WriteFile(*out, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing certificate: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if c.Err != nil {
+		fmt.Fprintf(os.Stderr, "FAILED: %v\n", c.Err)
+		fmt.Printf("Certificate of the failed verification written to %s\n", *out)
+		os.Exit(exitCode(c.Err))
+	}
+	fmt.Printf("OK — verified; certificate written to %s\n", *out)
+}
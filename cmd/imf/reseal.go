@@ -0,0 +1,122 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// runReseal handles the "imf reseal" command.
+// Migrates an existing container to a new one sealed with a new key and the
+// current manifest version, for key rotation and schema upgrades:
+//  1. Verifies the old container (old public key, or its embedded key)
+//  2. Decrypts it with the old passphrase and checks every file hash
+//  3. Seals the same files into a new container with the new key
+//
+// Open containers from older tools are accepted and simply sealed.
+func runReseal() {
+	fs := flag.NewFlagSet("imf reseal", flag.ExitOnError)
+	out := fs.String("out", "", "Path of the new container (.imf)")
+	keyPath := fs.String("key", "", "Path to the new Ed25519 private key (PEM), keychain:NAME, hw:[NAME], or pkcs11:[LABEL]")
+	oldKeyPath := fs.String("old-key", "", "Path to the old Ed25519 public key (PEM). Uses embedded key if omitted.")
+	oldPassphrase := fs.String("old-passphrase", "", "Passphrase of the old container, if encrypted")
+	embedPub := fs.Bool("embed-pubkey", false, "Embed the new public key in the new container")
+	passphrase := fs.String("passphrase", "", "Encryption passphrase for the new container ('none' to skip)")
+	expiresStr := fs.String("expires", "", "Expiration time for the new container (RFC3339)")
+	ignoreExpiry := fs.Bool("ignore-expiry", false, "Accept an expired old container")
+	dryRun := fs.Bool("dry-run", false, "Validate and show what would be sealed, without writing")
+	signerName := fs.String("name", "", "Your name, recorded with the new signature")
+	signerEmail := fs.String("email", "", "Your email, recorded with the new signature")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf reseal <old.imf> -out <new.imf> -key <new-private.pem> [options]")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 || *out == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	oldPath := fs.Arg(0)
+	*keyPath = defaultKey(*keyPath)
+	if *keyPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -key is required (or set key in ~/.imf/config)")
+		os.Exit(1)
+	}
+
+	opts := container.ResealOptions{
+		Verify:     container.VerifyOptions{IgnoreExpiry: *ignoreExpiry},
+		Passphrase: *oldPassphrase,
+	}
+	if *oldKeyPath != "" {
+		keyData, err := os.ReadFile(*oldKeyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading old key: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		pubKey, err := imfcrypto.ParsePublicKeyPEM(keyData)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing old key: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.Verify.PublicKey = pubKey
+	}
+	if info, err := container.GetInfo(oldPath); err == nil && info.Encrypted && info.TimeLock == nil && opts.Passphrase == "" {
+		opts.Passphrase = containerPassphrase("Old container passphrase: ", false)
+	}
+
+	pp := *passphrase
+	if pp == "" {
+		pp = containerPassphrase("New encryption passphrase (enter to skip): ", true)
+	}
+	if pp == "none" {
+		pp = ""
+	}
+	signer := mustLoadSigner(*keyPath)
+	defer releaseSigner(signer)
+	opts.Seal = container.SealOptions{
+		Signer:      signer,
+		EmbedPubKey: *embedPub,
+		Passphrase:  pp,
+		SignerName:  *signerName,
+		SignerEmail: *signerEmail,
+		DryRun:      *dryRun,
+	}
+	if *expiresStr != "" {
+		t, err := time.Parse(time.RFC3339, *expiresStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing expiry: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.Seal.ExpiresAt = &t
+	}
+
+	report, err := container.Reseal(oldPath, *out, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if *dryRun {
+		printSealReport(*out, report)
+		return
+	}
+
+	fmt.Printf("Resealed %d file(s) from %s into %s\n", len(report.Files), oldPath, *out)
+	if report.Encrypted {
+		fmt.Println("  Encrypted: yes")
+	}
+	if report.EmbedPublicKey {
+		fmt.Println("  Public key: embedded")
+	}
+	if report.ExpiresAt != nil {
+		fmt.Printf("  Expires: %s\n", report.ExpiresAt.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/keyring"
+)
+
+// runRevoke handles the "imf revoke" command.
+// With -key, the key owner writes a revocation statement signed by the key
+// being revoked, to publish wherever their containers' recipients look (a
+// file or a URL). With -import, a statement or list someone else published,
+// from a file or an http(s) URL, is checked and added to the local
+// revocation list that "imf verify" consults.
+func runRevoke() {
+	fs := flag.NewFlagSet("imf revoke", flag.ExitOnError)
+	keyPath := fs.String("key", "", "Private key to revoke (PEM, keychain:NAME, or keyring name)")
+	reason := fs.String("reason", "", "Reason recorded in the statement, e.g. \"key compromise\"")
+	atStr := fs.String("at", "", "Revocation time (RFC3339); containers sealed from then on fail to verify (default now)")
+	out := fs.String("out", "", "Write the statement to this file instead of stdout")
+	importFrom := fs.String("import", "", "Add a published statement or list (file or URL) to the local revocation list")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf revoke -key <private.pem> [-reason text] [-at time] [-out file]")
+		fmt.Fprintln(os.Stderr, "       imf revoke -import <file|url>")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+
+	if (*keyPath == "") == (*importFrom == "") || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *importFrom != "" {
+		importRevocations(*importFrom)
+		return
+	}
+
+	at := time.Now()
+	if *atStr != "" {
+		t, err := time.Parse(time.RFC3339, *atStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing -at: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		at = t
+	}
+	privKey := mustReadPrivateKey(*keyPath)
+	defer imfcrypto.Wipe(privKey)
+	r, err := container.Revoke(privKey, at, *reason)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	data, _ := json.MarshalIndent(r, "", "  ")
+	data = append(data, '\n')
+	if *out == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Wrote revocation of %s effective %s to %s\n", revokedFingerprint(*r), r.RevokedAt.Format(time.RFC3339), *out)
+}
+
+// importRevocations checks the statements at src and saves them in the local
+// revocation list, one file per revoked key.
+func importRevocations(src string) {
+	var revs []container.Revocation
+	var err error
+	if container.IsURL(src) {
+		revs, err = container.FetchRevocations(src)
+	} else {
+		var data []byte
+		if data, err = os.ReadFile(src); err == nil {
+			revs, err = container.ParseRevocations(data)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+
+	dir, err := keyring.DefaultRevocationDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0700)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	for _, r := range revs {
+		fingerprint := revokedFingerprint(r)
+		data, _ := json.MarshalIndent(r, "", "  ")
+		if err := os.WriteFile(filepath.Join(dir, fingerprint+".json"), append(data, '\n'), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Revoked %s as of %s\n", fingerprint, r.RevokedAt.Format(time.RFC3339))
+	}
+}
+
+// revokedFingerprint returns the fingerprint of the key r revokes. r must
+// already have passed Check.
+func revokedFingerprint(r container.Revocation) string {
+	key, _ := base64.StdEncoding.DecodeString(r.PublicKey)
+	return imfcrypto.Fingerprint(ed25519.PublicKey(key))
+}
+
+// mustLoadRevocations returns the local revocation list plus, if url is set,
+// the list published there. It exits on failure.
+func mustLoadRevocations(url string) []container.Revocation {
+	dir, err := keyring.DefaultRevocationDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	revs, err := container.LoadRevocations(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading revocation list: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if url != "" {
+		fetched, err := container.FetchRevocations(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		revs = append(revs, fetched...)
+	}
+	return revs
+}
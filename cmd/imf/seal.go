@@ -4,70 +4,213 @@
 package main
 
 import (
-	"bufio"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/x509"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/immutable-container/imf/pkg/container"
 	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/keyring"
+	"github.com/immutable-container/imf/pkg/manifest"
+	"github.com/immutable-container/imf/pkg/sigstore"
 )
 
 // runSeal handles the "imf seal" command.
 // Sealing is the core operation that makes a container immutable:
-//   1. Reads the Ed25519 private key from a PEM file
+//   1. Reads the Ed25519 private key from a PEM file (or uses a hardware token)
 //   2. Optionally encrypts all files with AES-256-GCM (if passphrase provided)
 //   3. Computes SHA-256 hashes for every file and records them in the manifest
 //   4. Signs the manifest with the private key (Ed25519)
 //   5. Optionally embeds the public key for self-verification
 //   6. Writes a .sealed marker — after this, no modifications are possible
+//   7. Optionally anchors the sealed container to Bitcoin (-anchor)
 func runSeal() {
-	// Parse command-line flags for key path, encryption, expiry, etc.
-	keyPath, embedPub, passphrase, expiresStr, containerPath := parseSealArgs()
-
-	if containerPath == "" {
+	var (
+		keyPath, passphrase, iterationsStr, thresholdStr     string
+		signerName, signerEmail, certPath, tsaURL, pqKeyPath string
+		expiresStr, timelockStr                              string
+		recipients, signers                                  stringList
+		embedPub, hideManifest, keyless, dryRun, strict      bool
+		anchorSeal, quiet                                    bool
+	)
+	fs := flag.NewFlagSet("imf seal", flag.ExitOnError)
+	fs.StringVar(&keyPath, "key", "", "Path to Ed25519 private key (PEM), keychain:NAME, hw:[NAME], or pkcs11:[LABEL]")
+	fs.BoolVar(&keyless, "keyless", false, "Sign with a one-time key certified by Fulcio for your OIDC identity")
+	fs.BoolVar(&embedPub, "embed-pubkey", false, "Embed public key in container")
+	fs.StringVar(&passphrase, "passphrase", "", "Encryption passphrase ('none' to skip)")
+	fs.StringVar(&iterationsStr, "kdf-iterations", "", "PBKDF2 iterations for the passphrase (default 600000)")
+	fs.BoolVar(&strict, "strict", false, "Refuse a weak passphrase instead of warning")
+	fs.Var(&recipients, "recipient", "Encrypt to this X25519 public key (PEM) instead; repeatable")
+	fs.BoolVar(&hideManifest, "hide-manifest", false, "Also encrypt the manifest, hiding file names and sizes (padded to a size bucket)")
+	fs.StringVar(&timelockStr, "timelock", "", "Encrypt so no one can decrypt before this time (RFC3339) instead")
+	fs.Var(&signers, "signer", "Ed25519 public key (PEM) allowed to sign under the policy; repeatable")
+	fs.StringVar(&thresholdStr, "threshold", "", "Require n of the -signer keys to sign (see 'imf cosign')")
+	fs.StringVar(&signerName, "name", "", "Your name, recorded with the signature")
+	fs.StringVar(&signerEmail, "email", "", "Your email, recorded with the signature")
+	fs.StringVar(&certPath, "cert", "", "X.509 certificate chain (PEM) for the key, leaf first")
+	fs.StringVar(&tsaURL, "tsa", "", "Get an RFC 3161 timestamp from this time-stamping authority")
+	fs.StringVar(&pqKeyPath, "pq-key", "", "Also sign with this ML-DSA-65 private key (hybrid post-quantum)")
+	fs.StringVar(&expiresStr, "expires", "", "Expiration time (RFC3339)")
+	fs.BoolVar(&anchorSeal, "anchor", false, "Anchor to Bitcoin via OpenTimestamps once sealed")
+	fs.BoolVar(&dryRun, "dry-run", false, "Validate and show what would be sealed, without writing")
+	fs.BoolVar(&quiet, "quiet", false, "Do not show a progress bar")
+	fs.Usage = func() {
 		fmt.Fprintln(os.Stderr, "Usage: imf seal <container.imf> [options]")
 		fmt.Fprintln(os.Stderr, "\nOptions:")
-		fmt.Fprintln(os.Stderr, "  -key string         Path to Ed25519 private key (PEM)")
+		fmt.Fprintln(os.Stderr, "  -key string         Path to Ed25519 private key (PEM), keychain:NAME, hw:[NAME], or pkcs11:[LABEL]")
+		fmt.Fprintln(os.Stderr, "  -keyless            Sign with a one-time key certified by Fulcio for your OIDC identity")
 		fmt.Fprintln(os.Stderr, "  -embed-pubkey       Embed public key in container")
-		fmt.Fprintln(os.Stderr, "  -passphrase string  Encryption passphrase ('none' to skip)")
+		fmt.Fprintln(os.Stderr, "  -passphrase string  Encryption passphrase ('none' to skip; default $IMF_PASSPHRASE, else asked)")
+		fmt.Fprintln(os.Stderr, "  -kdf-iterations n   PBKDF2 iterations for the passphrase (default 600000)")
+		fmt.Fprintln(os.Stderr, "  -strict             Refuse a weak passphrase instead of warning")
+		fmt.Fprintln(os.Stderr, "  -recipient file     Encrypt to this X25519 public key (PEM) instead; repeatable")
+		fmt.Fprintln(os.Stderr, "  -hide-manifest      Also encrypt the manifest, hiding file names and sizes (padded to a size bucket)")
+		fmt.Fprintln(os.Stderr, "  -timelock string    Encrypt so no one can decrypt before this time (RFC3339) instead")
+		fmt.Fprintln(os.Stderr, "  -signer file        Ed25519 public key (PEM) allowed to sign under the policy; repeatable")
+		fmt.Fprintln(os.Stderr, "  -threshold n        Require n of the -signer keys to sign (see 'imf cosign')")
+		fmt.Fprintln(os.Stderr, "  -name string        Your name, recorded with the signature")
+		fmt.Fprintln(os.Stderr, "  -email string       Your email, recorded with the signature")
+		fmt.Fprintln(os.Stderr, "  -cert file          X.509 certificate chain (PEM) for the key, leaf first")
+		fmt.Fprintln(os.Stderr, "  -tsa url            Get an RFC 3161 timestamp from this time-stamping authority")
+		fmt.Fprintln(os.Stderr, "  -pq-key file        Also sign with this ML-DSA-65 private key (hybrid post-quantum)")
 		fmt.Fprintln(os.Stderr, "  -expires string     Expiration time (RFC3339)")
+		fmt.Fprintln(os.Stderr, "  -anchor             Anchor to Bitcoin via OpenTimestamps once sealed; queued for")
+		fmt.Fprintln(os.Stderr, "                      'imf anchor -retry' if the calendars cannot be reached")
+		fmt.Fprintln(os.Stderr, "  -dry-run            Validate and show what would be sealed, without writing")
+		fmt.Fprintln(os.Stderr, "  -quiet              Do not show a progress bar")
+		fmt.Fprintln(os.Stderr, "  -json               Print the result as JSON")
+	}
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
 		os.Exit(1)
 	}
+	containerPath := fs.Arg(0)
 
 	// A signing key is always required — it proves authorship and enables
-	// tamper detection via the Ed25519 signature on the manifest.
-	if keyPath == "" {
-		fmt.Fprintln(os.Stderr, "Error: -key is required")
-		os.Exit(1)
+	// tamper detection via the Ed25519 signature on the manifest. With
+	// -keyless the key is a one-time one, vouched for by an OIDC identity.
+	if !keyless {
+		keyPath = defaultKey(keyPath)
 	}
-	keyData, err := os.ReadFile(keyPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading key: %v\n", err)
+	if keyPath == "" && !keyless {
+		fmt.Fprintln(os.Stderr, tr("Error: -key or -keyless is required (or set key in ~/.imf/config)"))
 		os.Exit(1)
 	}
-	privKey, err := imfcrypto.ParsePrivateKeyPEM(keyData)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing key: %v\n", err)
+	if keyPath != "" && keyless {
+		fmt.Fprintln(os.Stderr, "Error: -key and -keyless cannot be combined")
 		os.Exit(1)
 	}
+	var signer imfcrypto.Signer
+	if !keyless {
+		signer = mustLoadSigner(keyPath)
+		defer releaseSigner(signer)
+	}
+
+	// Recipients replace the passphrase: each gets the content key wrapped
+	// for their own X25519 key, so nothing secret needs to be sent.
+	var recipientKeys []*ecdh.PublicKey
+	for _, path := range recipients {
+		recipientKeys = append(recipientKeys, mustReadRecipientPublicKey(path))
+	}
 
 	// Prompt for passphrase interactively if not provided via flag.
 	// Use "none" to explicitly skip encryption.
 	pp := passphrase
-	if pp == "" {
-		pp = promptPassphrase("Encryption passphrase (enter to skip): ")
+	if pp == "" && len(recipientKeys) == 0 && timelockStr == "" {
+		pp = containerPassphrase("Encryption passphrase (enter to skip): ", true)
 	}
 	if pp == "none" {
 		pp = ""
 	}
+	if pp != "" {
+		checkPassphraseStrength(pp, strict)
+	}
 
 	// Build seal options and execute the seal operation.
+	bar := newProgressBar(quiet)
 	opts := container.SealOptions{
-		PrivateKey:  privKey,
-		EmbedPubKey: embedPub,
-		Passphrase:  pp,
+		Signer:       signer,
+		EmbedPubKey:  embedPub,
+		Passphrase:   pp,
+		Recipients:   recipientKeys,
+		HideManifest: hideManifest,
+		SignerName:   signerName,
+		SignerEmail:  signerEmail,
+		TSAURL:       tsaURL,
+		DryRun:       dryRun,
+		Progress:     bar.progress(),
+	}
+	if anchorSeal {
+		opts.Anchor = bar.anchorer(mustAnchorer("ots", nil, 1, ""))
+	}
+
+	if iterationsStr != "" {
+		n, err := strconv.Atoi(iterationsStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing -kdf-iterations: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.Iterations = n
+	} else {
+		opts.Iterations = settings().KDFIterations
+	}
+
+	// A signature policy lists who may sign and how many must; the sealer
+	// signs now if listed, the others later with "imf cosign".
+	if len(signers) > 0 || thresholdStr != "" {
+		policy := &container.SignaturePolicy{Threshold: len(signers)}
+		if thresholdStr != "" {
+			n, err := strconv.Atoi(thresholdStr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing -threshold: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			policy.Threshold = n
+		}
+		for _, path := range signers {
+			policy.Keys = append(policy.Keys, mustReadPublicKey(path))
+		}
+		opts.Policy = policy
+	}
+
+	// A certificate chain binds the key to an organization's PKI, so
+	// recipients can verify against their CA bundle with "imf verify -ca".
+	if certPath != "" {
+		opts.CertChain = mustReadCertChain(certPath)
+	}
+
+	// Keyless signing suits CI pipelines: the job's OIDC token gets a
+	// short-lived certificate from Fulcio and the signature is logged in
+	// Rekor, so there is no long-lived key to store. IMF_FULCIO_URL and
+	// IMF_REKOR_URL select private Sigstore instances.
+	if keyless {
+		opts.Keyless = &container.KeylessOptions{
+			FulcioURL: os.Getenv("IMF_FULCIO_URL"),
+			RekorURL:  os.Getenv("IMF_REKOR_URL"),
+		}
+		if !dryRun {
+			token, err := sigstore.IdentityToken()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+				os.Exit(exitCode(err))
+			}
+			opts.Keyless.IdentityToken = token
+		}
+	}
+
+	// In hybrid mode an ML-DSA key signs alongside Ed25519, so the
+	// container survives a future break of Ed25519 by quantum computers.
+	if pqKeyPath != "" {
+		opts.PQKey = mustReadPQPrivateKey(pqKeyPath)
 	}
 
 	// Parse optional expiration date (RFC3339 format, e.g. "2026-12-31T23:59:59Z").
@@ -76,77 +219,324 @@ func runSeal() {
 		t, err := time.Parse(time.RFC3339, expiresStr)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing expiry: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 		opts.ExpiresAt = &t
 	}
 
-	if err := container.Seal(containerPath, opts); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	// A time lock is the reverse of an expiry: the files are encrypted to a
+	// future round of the drand beacon and cannot be decrypted, by anyone,
+	// until the beacon publishes it. IMF_DRAND_URL and IMF_DRAND_CHAIN
+	// select another drand network.
+	if timelockStr != "" {
+		t, err := time.Parse(time.RFC3339, timelockStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing -timelock: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.TimeLock = &container.TimeLockOptions{
+			Until: t,
+			URL:   os.Getenv("IMF_DRAND_URL"),
+			Chain: os.Getenv("IMF_DRAND_CHAIN"),
+		}
+	}
+
+	report, err := container.SealWithReport(containerPath, opts)
+	bar.clear()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if jsonOutput {
+		printJSON(newSealJSON(containerPath, report, len(recipientKeys)))
+		if report.AnchorErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not anchor the container: %v\n", report.AnchorErr)
+		}
+		return
+	}
+	if dryRun {
+		printSealReport(containerPath, report)
+		return
 	}
 
 	// Print summary of what was sealed and how.
-	fmt.Printf("Sealed %s\n", containerPath)
+	fmt.Println(tr("Sealed %s", containerPath))
 	if pp != "" {
-		fmt.Println("  Encrypted: yes")
+		fmt.Println(tr("  Encrypted: yes"))
+	}
+	if len(recipientKeys) > 0 {
+		fmt.Println(tr("  Encrypted to: %d recipient(s)", len(recipientKeys)))
+	}
+	if report.TimeLock != nil {
+		fmt.Println(tr("  Time-locked until: %s", report.TimeLock.Format(time.RFC3339)))
+	}
+	if report.HiddenManifest {
+		fmt.Println(tr("  Manifest: hidden"))
 	}
 	if embedPub {
-		fmt.Println("  Public key: embedded")
+		fmt.Println(tr("  Public key: embedded"))
+	}
+	fmt.Println(tr("  Signer: %s", formatSigner(report.Signer)))
+	if report.RekorEntry != nil {
+		identity, issuer := sigstore.Identity(report.CertChain[0])
+		fmt.Printf("  Identity: %s (%s)\n", identity, issuer)
+	} else if len(report.CertChain) > 0 {
+		fmt.Printf("  Certificate: %s\n", report.CertChain[0].Subject)
+	}
+	if report.PQ != "" {
+		fmt.Printf("  Post-quantum: %s\n", report.PQ)
+	}
+	if report.Timestamp != nil {
+		fmt.Printf("  Timestamp: %s (%s)\n", report.Timestamp.Format(time.RFC3339), report.TSA)
+	}
+	if report.RekorEntry != nil {
+		fmt.Printf("  Transparency log: entry %d (%s)\n", report.RekorEntry.LogIndex, report.Rekor)
+	}
+	if report.Policy != nil {
+		fmt.Printf("  Signatures: %d of %d required (%d keys)\n", len(report.Policy.Signed), report.Policy.Threshold, len(report.Policy.Keys))
 	}
 	if opts.ExpiresAt != nil {
-		fmt.Printf("  Expires: %s\n", opts.ExpiresAt.Format(time.RFC3339))
+		fmt.Println(tr("  Expires: %s", opts.ExpiresAt.Format(time.RFC3339)))
+	}
+	if report.Anchor != nil {
+		fmt.Printf("  Anchor: %s (pending; see 'imf anchor -upgrade')\n", report.Anchor.ProofPath)
+	} else if report.AnchorErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not anchor the container: %v\n", report.AnchorErr)
+		fmt.Fprintln(os.Stderr, "  It is sealed, and queued to be anchored by 'imf anchor -retry'.")
 	}
 }
 
-// promptPassphrase reads a passphrase from stdin with a visible prompt.
-func promptPassphrase(prompt string) string {
-	fmt.Fprint(os.Stderr, prompt)
-	reader := bufio.NewReader(os.Stdin)
-	line, _ := reader.ReadString('\n')
-	return strings.TrimSpace(line)
+// mustReadPrivateKey loads a PEM private key from disk, from the OS keychain
+// for a "keychain:NAME" reference, or from the keyring when no file of that
+// name exists, exiting on failure. A passphrase-protected key is decrypted
+// after prompting for its passphrase.
+func mustReadPrivateKey(keyPath string) ed25519.PrivateKey {
+	if name, ok := imfcrypto.KeychainKeyName(keyPath); ok {
+		privKey, err := imfcrypto.LoadKeychainKey(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		return privKey
+	}
+	keyData, err := os.ReadFile(keyPath)
+	if errors.Is(err, os.ErrNotExist) && keyring.ValidName(keyPath) {
+		if kr, krErr := keyring.OpenDefault(); krErr == nil {
+			if _, krErr = kr.Get(keyPath); krErr == nil {
+				keyData, err = kr.PrivateKeyFile(keyPath)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer imfcrypto.Wipe(keyData)
+	privKey, err := imfcrypto.ParsePrivateKeyPEM(keyData)
+	if errors.Is(err, imfcrypto.ErrKeyProtected) {
+		pp := promptPassphrase(tr("Passphrase for %s: ", keyPath))
+		privKey, err = imfcrypto.ParseEncryptedPrivateKeyPEM(keyData, pp)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	return privKey
 }
 
-// parseSealArgs manually parses seal command arguments.
-// We use manual parsing instead of flag.FlagSet because the container path
-// is a positional argument mixed with flags.
-func parseSealArgs() (keyPath string, embedPub bool, passphrase string, expiresStr string, containerPath string) {
-	args := os.Args[1:]
-	i := 0
-	for i < len(args) {
-		switch args[i] {
-		case "-key":
-			if i+1 < len(args) {
-				keyPath = args[i+1]
-				i += 2
-			} else {
-				i++
-			}
-		case "-embed-pubkey":
-			embedPub = true
-			i++
-		case "-passphrase":
-			if i+1 < len(args) {
-				passphrase = args[i+1]
-				i += 2
-			} else {
-				i++
-			}
-		case "-expires":
-			if i+1 < len(args) {
-				expiresStr = args[i+1]
-				i += 2
-			} else {
-				i++
+// mustReadPublicKey loads a PEM Ed25519 public key from disk, or from the
+// keyring when no file of that name exists, exiting on failure.
+func mustReadPublicKey(keyPath string) ed25519.PublicKey {
+	keyData, err := os.ReadFile(keyPath)
+	if errors.Is(err, os.ErrNotExist) && keyring.ValidName(keyPath) {
+		if kr, krErr := keyring.OpenDefault(); krErr == nil {
+			if key, krErr := kr.Get(keyPath); krErr == nil {
+				return key.PublicKey
 			}
-		case "-h", "-help":
-			return
-		default:
-			if containerPath == "" && !strings.HasPrefix(args[i], "-") {
-				containerPath = args[i]
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	pubKey, err := imfcrypto.ParsePublicKeyPEM(keyData)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing key %s: %v\n", keyPath, err)
+		os.Exit(exitCode(err))
+	}
+	return pubKey
+}
+
+// mustLoadSigner returns the signer for a -key argument: a hardware token
+// reached through its helper program for "hw:" or "hw:NAME" (see
+// imfcrypto.ExternalSigner), an HSM key for "pkcs11:" or "pkcs11:LABEL"
+// (configured by the IMF_PKCS11_* environment variables), otherwise a
+// private key file or keyring key name. It exits on failure.
+func mustLoadSigner(keyRef string) imfcrypto.Signer {
+	if label, ok := imfcrypto.PKCS11Label(keyRef); ok {
+		cfg, err := imfcrypto.PKCS11ConfigFromEnv(label)
+		if err == nil {
+			var signer *imfcrypto.PKCS11Signer
+			if signer, err = imfcrypto.NewPKCS11Signer(cfg); err == nil {
+				return signer
 			}
-			i++
 		}
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	if program, ok := imfcrypto.HardwareSignerProgram(keyRef); ok {
+		signer, err := imfcrypto.NewExternalSigner(program)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		return signer
+	}
+	signer, err := imfcrypto.NewKeySigner(mustReadPrivateKey(keyRef))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid signing key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	return signer
+}
+
+// releaseSigner wipes an in-memory signing key, or ends an HSM session, once
+// a command is done signing. It is safe to call at any time, even twice or
+// while another goroutine signs, whose signature then fails.
+func releaseSigner(s imfcrypto.Signer) {
+	if c, ok := s.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// mustReadCertChain loads a PEM X.509 certificate chain, exiting on failure.
+func mustReadCertChain(path string) []*x509.Certificate {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading certificate chain: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	chain, err := imfcrypto.ParseCertificateChainPEM(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing certificate chain %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+	return chain
+}
+
+// mustReadPQPrivateKey loads a PEM ML-DSA-65 private key, exiting on failure.
+func mustReadPQPrivateKey(path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	seed, err := imfcrypto.ParsePQPrivateKeyPEM(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing key %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+	return seed
+}
+
+// mustReadRecipientPublicKey loads a PEM X25519 recipient public key,
+// exiting on failure.
+func mustReadRecipientPublicKey(path string) *ecdh.PublicKey {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading recipient key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	key, err := imfcrypto.ParseRecipientPublicKeyPEM(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing recipient key %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+	return key
+}
+
+// printSealReport describes what a dry-run seal would sign and encrypt.
+func printSealReport(containerPath string, r *container.SealReport) {
+	fmt.Println(tr("Dry run: %s was NOT modified. Sealing would:", containerPath))
+	if r.Encrypted {
+		if r.Iterations > 0 {
+			fmt.Printf("  Encrypt %d file(s) with %s (key via %s, %d iterations)\n", len(r.Files), r.Algorithm, r.KDF, r.Iterations)
+		} else {
+			fmt.Printf("  Encrypt %d file(s) with %s (key via %s)\n", len(r.Files), r.Algorithm, r.KDF)
+		}
+	} else {
+		fmt.Println("  Store files unencrypted")
+	}
+	if r.TimeLock != nil {
+		fmt.Printf("  Lock the key until %s\n", r.TimeLock.Format(time.RFC3339))
+	}
+	if r.HiddenManifest {
+		fmt.Println("  Encrypt the manifest, leaving only a signed outer header")
+	}
+	if r.EmbedPublicKey {
+		fmt.Printf("  Embed public key %s\n", r.PublicKey)
+	}
+	if r.ExpiresAt != nil {
+		fmt.Printf("  Expire at %s\n", r.ExpiresAt.Format(time.RFC3339))
+	}
+	if r.Policy != nil {
+		fmt.Printf("  Require %d of %d listed keys to sign (%d so far)\n", r.Policy.Threshold, len(r.Policy.Keys), len(r.Policy.Signed))
+	}
+	fmt.Printf("  Record signer %s\n", formatSigner(r.Signer))
+	if len(r.CertChain) > 0 {
+		fmt.Printf("  Embed %d certificate(s) for %s\n", len(r.CertChain), r.CertChain[0].Subject)
+	}
+	if r.PQ != "" {
+		fmt.Printf("  Also sign with %s (hybrid post-quantum)\n", r.PQ)
+	}
+	if r.TSA != "" {
+		fmt.Printf("  Timestamp the signed manifest at %s\n", r.TSA)
+	}
+	if r.Rekor != "" {
+		fmt.Printf("  Sign with a one-time key certified by Fulcio, logged at %s\n", r.Rekor)
+	}
+	if r.AnchorBackend != "" {
+		fmt.Printf("  Anchor the sealed container via %s\n", r.AnchorBackend)
+	}
+	fmt.Printf("  Sign a %d-byte manifest (SHA-256 %s)\n", r.SignedBytes, r.SignedSHA256)
+	fmt.Println("\n" + tr("Files:"))
+	for _, f := range r.Files {
+		fmt.Printf("  %-30s %10d  -> %s\n", f.Name, f.Size, f.Path)
+		fmt.Printf("    SHA-256 %s\n", f.SHA256)
+	}
+}
+
+// formatSigner describes a recorded signer identity as "Name <email>
+// (fingerprint ...)", or just the fingerprint if no name or email was given.
+func formatSigner(s *manifest.SignerIdentity) string {
+	if s == nil {
+		return "(not recorded)"
+	}
+	who := strings.TrimSpace(s.Name)
+	if s.Email != "" {
+		who = strings.TrimSpace(who + " <" + s.Email + ">")
+	}
+	if who == "" {
+		return "fingerprint " + s.Fingerprint
+	}
+	return who + " (fingerprint " + s.Fingerprint + ")"
+}
+
+// checkPassphraseStrength warns about a passphrase an offline attacker
+// could guess (see imfcrypto.EstimateStrength); with strict it exits
+// instead, for scripts that must not seal under a weak one.
+func checkPassphraseStrength(pp string, strict bool) {
+	s := imfcrypto.EstimateStrength(pp)
+	if s.Score >= imfcrypto.MinPassphraseScore {
+		return
+	}
+	msg := fmt.Sprintf("passphrase is %s — guessable in %s offline", s.Label(), s.CrackTime)
+	if s.Warning != "" {
+		msg += ". " + s.Warning
+	}
+	if strict {
+		fmt.Fprintf(os.Stderr, "Error: %s\n  %s\n", msg, s.Suggestion)
+		os.Exit(1)
 	}
-	return
+	fmt.Fprintf(os.Stderr, "Warning: %s\n  %s (-strict refuses weak passphrases)\n", msg, s.Suggestion)
 }
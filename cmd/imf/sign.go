@@ -0,0 +1,58 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+// runSign handles the "imf sign" command.
+// Writes a detached signature over the sealed container file's exact bytes,
+// so the whole artifact, ZIP structure included, can be attested and the
+// signature distributed separately. "imf verify -sig" checks it. Sign last:
+// a later cosignature or witness changes the file and invalidates it.
+func runSign() {
+	fs := flag.NewFlagSet("imf sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "Private key that sealed the container (PEM), keychain:NAME, hw:[NAME], or pkcs11:[LABEL]")
+	out := fs.String("out", "", "Signature file (default <container>.sig)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf sign <container.imf> -key <private.pem> [-out file]")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	containerPath := fs.Arg(0)
+	*keyPath = defaultKey(*keyPath)
+	if *keyPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -key is required (or set key in ~/.imf/config)")
+		os.Exit(1)
+	}
+	if *out == "" {
+		*out = containerPath + ".sig"
+	}
+
+	signer := mustLoadSigner(*keyPath)
+	defer releaseSigner(signer)
+	d, err := container.SignFile(containerPath, signer)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	data, _ := json.MarshalIndent(d, "", "  ")
+	if err := os.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Signed %s (SHA-256 %s)\n  Signature: %s\n", containerPath, d.SHA256, *out)
+}
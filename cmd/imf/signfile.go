@@ -0,0 +1,151 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// runSignFile handles the "imf sign-file" command.
+// Writes a detached signature over any file's exact bytes with the same
+// keys that seal containers, so a loose document needs no other toolchain.
+// "imf verify-file" checks it.
+func runSignFile() {
+	fs := flag.NewFlagSet("imf sign-file", flag.ExitOnError)
+	keyPath := fs.String("key", "", "Private key (PEM), keyring key name, keychain:NAME, hw:[NAME], or pkcs11:[LABEL]")
+	out := fs.String("out", "", "Signature file (default <file>.sig)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf sign-file <file> -key <private.pem> [-out file]")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	path := fs.Arg(0)
+	*keyPath = defaultKey(*keyPath)
+	if *keyPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -key is required (or set key in ~/.imf/config)")
+		os.Exit(1)
+	}
+	if *out == "" {
+		*out = path + ".sig"
+	}
+
+	signer := mustLoadSigner(*keyPath)
+	defer releaseSigner(signer)
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	s, err := imfcrypto.SignFile(f, signer)
+	f.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	data, _ := json.MarshalIndent(s, "", "  ")
+	if err := os.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Signed %s (SHA-256 %s)\n  Signature: %s\n", path, s.SHA256, *out)
+}
+
+// runVerifyFile handles the "imf verify-file" command.
+// Checks a file against its detached signature from "imf sign-file" (or
+// "imf sign"). The signer must be given with -key, or be in the trust
+// store with -trusted: the key recorded in the signature is not trusted by
+// itself.
+func runVerifyFile() {
+	fs := flag.NewFlagSet("imf verify-file", flag.ExitOnError)
+	keyPath := fs.String("key", "", "Path to Ed25519 public key (PEM) or keyring key name")
+	trusted := fs.Bool("trusted", false, "Accept any signer in the trust store (see 'imf trust')")
+	sigPath := fs.String("sig", "", "Signature file (default <file>.sig)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf verify-file <file> (-key <public.pem> | -trusted) [-sig file]")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	path := fs.Arg(0)
+	if (*keyPath == "") == !*trusted {
+		fmt.Fprintln(os.Stderr, "Error: give exactly one of -key and -trusted")
+		os.Exit(1)
+	}
+	if *sigPath == "" {
+		*sigPath = path + ".sig"
+	}
+
+	data, err := os.ReadFile(*sigPath)
+	var s *imfcrypto.FileSignature
+	if err == nil {
+		s, err = imfcrypto.ParseFileSignature(data)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading signature %s: %v\n", *sigPath, err)
+		os.Exit(exitCode(err))
+	}
+
+	var pubKey ed25519.PublicKey
+	if *keyPath != "" {
+		pubKey = mustReadPublicKey(*keyPath)
+	} else {
+		pubKey = mustTrustedSigner(s)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	defer f.Close()
+	if err := s.Verify(f, pubKey); err != nil {
+		fmt.Fprintf(os.Stderr, "FAILED: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Println("OK — file signature verified")
+	fmt.Printf("  Signer:    %s\n", imfcrypto.Fingerprint(pubKey))
+	fmt.Printf("  Signed at: %s\n", s.SignedAt.Format(time.RFC3339))
+}
+
+// mustTrustedSigner returns the trust store key that s names as its
+// signer, exiting with exitSignature if there is none.
+func mustTrustedSigner(s *imfcrypto.FileSignature) ed25519.PublicKey {
+	claimed, err := s.SignerKey()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAILED: %v\n", err)
+		os.Exit(exitSignature)
+	}
+	keys, err := mustOpenTrustStore().PublicKeys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading trust store: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	for _, k := range keys {
+		if bytes.Equal(k, claimed) {
+			return k
+		}
+	}
+	fmt.Fprintf(os.Stderr, "FAILED: signer %s is not in the trust store\n", imfcrypto.Fingerprint(claimed))
+	os.Exit(exitSignature)
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+// runStats handles the "imf stats" command.
+// Reports total plaintext and stored sizes, the compression ratio, a
+// breakdown by file type, the largest files, and how much content is
+// duplicated — useful when deciding how to split or compress an archive.
+func runStats() {
+	fs := flag.NewFlagSet("imf stats", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf stats <container.imf>")
+		fmt.Fprintln(os.Stderr, "\nShow size, compression, type, and duplicate statistics.")
+	}
+	parseFlags(fs)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	st, err := container.GetStats(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Container: %s\n", fs.Arg(0))
+	fmt.Printf("  Files:          %d\n", st.FileCount)
+	fmt.Printf("  Plaintext size: %s\n", formatBytes(st.PlaintextSize))
+	fmt.Printf("  Stored size:    %s\n", formatBytes(st.StoredSize))
+	fmt.Printf("  Container size: %s\n", formatBytes(st.ContainerSize))
+	if st.PlaintextSize > 0 {
+		fmt.Printf("  Compression:    %.1f%% of original\n", st.CompressionRatio*100)
+	}
+	fmt.Printf("  Duplicates:     %d file(s) in %d group(s), %s redundant\n",
+		st.DuplicateFiles, st.DuplicateGroups, formatBytes(st.DuplicateBytes))
+
+	if len(st.ByType) > 0 {
+		types := make([]string, 0, len(st.ByType))
+		for t := range st.ByType {
+			types = append(types, t)
+		}
+		sort.Slice(types, func(i, j int) bool { return st.ByType[types[i]].Size > st.ByType[types[j]].Size })
+
+		fmt.Println("\nBy type:")
+		for _, t := range types {
+			ts := st.ByType[t]
+			fmt.Printf("  %-32s %6d file(s)  %10s\n", t, ts.Count, formatBytes(ts.Size))
+		}
+	}
+
+	if len(st.Largest) > 0 {
+		fmt.Println("\nLargest files:")
+		for _, f := range st.Largest {
+			fmt.Printf("  %-40s %10s\n", f.OriginalName, formatBytes(f.OriginalSize))
+		}
+	}
+}
+
+// formatBytes renders a byte count with a binary unit suffix.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/immutable-container/imf/pkg/keyring"
+)
+
+const trustUsage = `Usage: imf trust <subcommand> [arguments]
+
+Subcommands:
+  list                  List trusted signer keys
+  add <name> <file>     Trust a public key file (or keyring key) under name
+  rm <name>             Stop trusting a key
+
+Trusted keys are kept in ~/.imf/trusted_keys (or $IMF_TRUSTED_KEYS).
+"imf verify -trusted" accepts a container's embedded public key only if it
+is one of them.
+`
+
+// runTrust handles the "imf trust" command, which manages the trust store
+// consulted by "imf verify -trusted".
+func runTrust() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, trustUsage)
+		os.Exit(1)
+	}
+	sub := os.Args[1]
+	os.Args = append([]string{os.Args[0] + " " + sub}, os.Args[2:]...)
+
+	switch sub {
+	case "list":
+		parseArgs("imf trust list", "imf trust list", 0)
+		ts := mustOpenTrustStore()
+		keys, err := ts.List()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		if len(keys) == 0 {
+			fmt.Printf("No trusted keys in %s\n", ts.Dir())
+			return
+		}
+		for _, k := range keys {
+			fmt.Printf("%-20s %s\n", k.Name, k.Fingerprint)
+		}
+	case "add":
+		args := parseArgs("imf trust add", "imf trust add <name> <file>", 2)
+		key, err := mustOpenTrustStore().Add(args[0], mustReadPublicKey(args[1]), nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Trusted %s (%s)\n", key.Name, key.Fingerprint)
+	case "rm":
+		args := parseArgs("imf trust rm", "imf trust rm <name>", 1)
+		if err := mustOpenTrustStore().Remove(args[0]); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("No longer trusting %s\n", args[0])
+	case "help", "-h", "--help":
+		fmt.Print(trustUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown trust subcommand: %s\n\n", sub)
+		fmt.Fprint(os.Stderr, trustUsage)
+		os.Exit(1)
+	}
+}
+
+// mustOpenTrustStore opens the default trust store, exiting on failure.
+func mustOpenTrustStore() *keyring.Keyring {
+	ts, err := keyring.OpenTrustStore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	return ts
+}
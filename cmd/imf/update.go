@@ -0,0 +1,125 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/immutable-container/imf/pkg/update"
+)
+
+// releaseKey is the base64 Ed25519 public key release indexes are signed
+// with, set at build time with -ldflags "-X main.releaseKey=...". A build
+// without one cannot update itself unless given -key.
+var releaseKey = ""
+
+// runUpdate handles the "imf update" command.
+// Fetches the signed release index (see package update), and if it names a
+// newer version than this one, downloads this platform's binary, checks it
+// against the index, and replaces the running executable with it. The
+// index must be signed by the release key built into imf, or by the key
+// given with -key for a private mirror. With -check, only reports whether
+// an update is available.
+func runUpdate() {
+	fs := flag.NewFlagSet("imf update", flag.ExitOnError)
+	check := fs.Bool("check", false, "Only report whether a newer version is available")
+	indexURL := fs.String("url", "", "Release index URL (default $IMF_UPDATE_URL, then "+update.DefaultURL+")")
+	keyPath := fs.String("key", "", "Public key (PEM) or keyring key name the release index must be signed with, instead of the built-in release key")
+	force := fs.Bool("force", false, "Install the release even if it is not newer than this version")
+	quiet := fs.Bool("quiet", false, "Do not show a progress bar")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf update [-check] [-url URL] [-key public.pem]")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *indexURL == "" {
+		*indexURL = os.Getenv("IMF_UPDATE_URL")
+	}
+	if *indexURL == "" {
+		*indexURL = update.DefaultURL
+	}
+	var key ed25519.PublicKey
+	if *keyPath != "" {
+		key = mustReadPublicKey(*keyPath)
+	} else {
+		key = mustReleaseKey()
+	}
+
+	current, _, _ := buildInfo()
+	r, err := update.Check(*indexURL, key)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	newer := update.Newer(r.Version, current)
+	if !newer && !*force {
+		fmt.Printf("imf %s is up to date (latest release %s)\n", current, r.Version)
+		return
+	}
+	if *check {
+		fmt.Printf("imf %s is available (this is %s)\n", r.Version, current)
+		if r.Notes != "" {
+			fmt.Printf("  Notes: %s\n", r.Notes)
+		}
+		return
+	}
+
+	asset, err := r.Asset(update.Platform())
+	if err == nil {
+		err = install(asset, *quiet)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Updated imf %s to %s\n", current, r.Version)
+	if r.Notes != "" {
+		fmt.Printf("  Notes: %s\n", r.Notes)
+	}
+}
+
+// install downloads asset beside the running executable and swaps it in.
+func install(asset update.Asset, quiet bool) error {
+	exe, err := update.Executable()
+	if err != nil {
+		return err
+	}
+	bar := newProgressBar(quiet)
+	var progress func(done, total int64)
+	if bar != nil {
+		progress = func(done, total int64) { bar.update(stageDownload, done, total) }
+	}
+	path, err := update.Download(asset, filepath.Dir(exe), progress)
+	bar.clear()
+	if err != nil {
+		return err
+	}
+	if err := update.Install(path, exe); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// mustReleaseKey returns the built-in release key, exiting if this build
+// has none.
+func mustReleaseKey() ed25519.PublicKey {
+	key, err := base64.StdEncoding.DecodeString(releaseKey)
+	if releaseKey == "" || err != nil || len(key) != ed25519.PublicKeySize {
+		fmt.Fprintln(os.Stderr, "Error: this build of imf has no release key; give -key with the key releases are signed with")
+		os.Exit(1)
+	}
+	return key
+}
@@ -4,12 +4,19 @@
 package main
 
 import (
+	"crypto"
+	"crypto/x509"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/immutable-container/imf/pkg/container"
 	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/sigstore"
 )
 
 // runVerify handles the "imf verify" command.
@@ -17,39 +24,390 @@ import (
 //   1. Checking the Ed25519 signature on the manifest
 //   2. Recomputing SHA-256 hashes for every file and comparing to manifest
 //   3. Checking expiration date (unless -ignore-expiry is set)
-// If -key is omitted and the container has an embedded public key, that key is used.
+// Any signature policy status and witness countersignatures are listed too,
+// and with -detail the signer's recorded identity, the seal time, and the
+// hash check of each file; every file is checked, even after one fails.
+// If -key is omitted and the container has an embedded public key, that key is
+// used; with -trusted it must also be in the trust store. With -ca, the
+// container's embedded certificate chain must lead to one of the CAs in the
+// given PEM bundle, as of the seal time, and certify the signing key. With
+// -tsa-ca, the container must carry an RFC 3161 timestamp from a TSA under
+// one of the bundle's CAs, and that time is used as the seal time. With
+// -rekor or -rekor-key, the container must be signed keylessly (see "imf
+// seal -keyless") and its entry proven to be in the Rekor log; the time it
+// was logged is then used as the seal time. -identity and -issuer pin who
+// the signing certificate was issued to, and are meaningful with -ca. With
+// -pq-key, a hybrid ML-DSA signature by that key is required; one present
+// without it is checked too. With -sig, the container file must also match
+// that detached signature (see "imf sign") by the same key.
+// The signing key is checked against the local revocation list (see "imf
+// revoke") and any list given with -revocations: a container sealed after
+// the key was revoked fails, one sealed before it verifies with a warning.
+// Archive entries the signed manifest does not cover, which anyone could add
+// after sealing, are reported with a warning; with -strict they fail.
+// The container may be a local path or an https://, s3://, or gs:// URL.
+// With --json the result, including everything -detail shows, is printed as
+// a verifyJSON, with verified false if the container fails.
+// Given several containers, or -dir, each is verified in turn, or -jobs at a
+// time, and the results are printed as a table (a JSON array with --json);
+// one failing does not stop the rest, and the exit status is that of the
+// worst failure.
 func runVerify() {
 	fs := flag.NewFlagSet("imf verify", flag.ExitOnError)
-	keyPath := fs.String("key", "", "Path to Ed25519 public key (PEM). Uses embedded key if omitted.")
+	keyPath := fs.String("key", "", "Path to Ed25519 public key (PEM) or keyring key name. Uses embedded key if omitted.")
 	ignoreExpiry := fs.Bool("ignore-expiry", false, "Verify even if container is expired")
-	fs.Parse(os.Args[1:])
+	detail := fs.Bool("detail", false, "Also show who sealed the container, when, and each file's hash check")
+	trusted := fs.Bool("trusted", false, "Accept an embedded key only if it is in the trust store (see 'imf trust')")
+	caPath := fs.String("ca", "", "Require the embedded certificate chain to lead to a CA in this PEM bundle")
+	sigPath := fs.String("sig", "", "Also check this detached signature over the container file (see 'imf sign')")
+	pqKeyPath := fs.String("pq-key", "", "Require a hybrid post-quantum signature by this ML-DSA-65 public key (PEM)")
+	tsaCAPath := fs.String("tsa-ca", "", "Require an RFC 3161 timestamp from a TSA under a CA in this PEM bundle")
+	rekor := fs.Bool("rekor", false, "Require a keyless signature proven to be in the Rekor log at IMF_REKOR_URL (default rekor.sigstore.dev)")
+	rekorKeyPath := fs.String("rekor-key", "", "Like -rekor, with the log's public key (PEM) pinned instead of fetched")
+	identity := fs.String("identity", "", "Require the signing certificate to be issued to this email or URI")
+	issuer := fs.String("issuer", "", "Require the signing certificate to name this OIDC issuer")
+	strict := fs.Bool("strict", false, "Fail if the container holds any entry the signed manifest does not cover")
+	revocationURL := fs.String("revocations", "", "Also check the signing key against the revocation list at this URL")
+	dir := fs.String("dir", "", "Also verify every .imf container in this directory")
+	jobs := fs.Int("jobs", 1, "Verify this many containers at a time")
+	quiet := fs.Bool("quiet", false, "Do not show a progress bar")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf verify [options] <container.imf|url>...")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs)
 
-	if fs.NArg() != 1 {
-		fmt.Fprintln(os.Stderr, "Usage: imf verify <container.imf> [options]")
+	paths := fs.Args()
+	if *dir != "" {
+		matches, err := filepath.Glob(filepath.Join(*dir, "*.imf"))
+		if err == nil && len(matches) == 0 {
+			err = fmt.Errorf("no .imf containers in %s", *dir)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	batch := len(paths) > 1 || *dir != ""
+	if batch && *sigPath != "" {
+		fmt.Fprintln(os.Stderr, "Error: -sig checks a single container")
+		os.Exit(1)
+	}
+	if *jobs < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -jobs must be at least 1")
 		os.Exit(1)
 	}
 
 	opts := container.VerifyOptions{
 		IgnoreExpiry: *ignoreExpiry,
+		Revocations:  mustLoadRevocations(*revocationURL),
+		Identity:     *identity,
+		Issuer:       *issuer,
+		Strict:       *strict,
 	}
 
 	if *keyPath != "" {
-		keyData, err := os.ReadFile(*keyPath)
+		opts.PublicKey = mustReadPublicKey(*keyPath)
+	}
+	if *trusted {
+		keys, err := mustOpenTrustStore().PublicKeys()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading key: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error reading trust store: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.RequireTrusted = true
+		opts.TrustedKeys = keys
+	}
+
+	if *caPath != "" {
+		opts.Roots = mustReadCertPool(*caPath)
+	}
+	if *tsaCAPath != "" {
+		opts.TSARoots = mustReadCertPool(*tsaCAPath)
+	}
+	if *rekorKeyPath != "" || *rekor {
+		opts.RekorKey = mustLoadRekorKey(*rekorKeyPath)
+	}
+	if *sigPath != "" {
+		data, err := os.ReadFile(*sigPath)
+		if err == nil {
+			opts.Detached, err = container.ParseDetachedSignature(data)
 		}
-		pubKey, err := imfcrypto.ParsePublicKeyPEM(keyData)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing key: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error reading signature %s: %v\n", *sigPath, err)
+			os.Exit(exitCode(err))
 		}
-		opts.PublicKey = pubKey
+	}
+	if *pqKeyPath != "" {
+		data, err := os.ReadFile(*pqKeyPath)
+		if err == nil {
+			opts.PQPublicKey, err = imfcrypto.ParsePQPublicKeyPEM(data)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading key %s: %v\n", *pqKeyPath, err)
+			os.Exit(exitCode(err))
+		}
+	}
+
+	bar := newProgressBar(*quiet)
+	if batch {
+		runVerifyBatch(paths, opts, *jobs, *detail, bar)
+		return
 	}
 
-	if err := container.Verify(fs.Arg(0), opts); err != nil {
-		fmt.Fprintf(os.Stderr, "FAILED: %v\n", err)
+	opts.Progress = bar.progress()
+	report, err := container.VerifyWithReport(fs.Arg(0), opts)
+	bar.clear()
+	if err != nil {
+		if jsonOutput {
+			printJSON(newFailedVerifyJSON(fs.Arg(0), report, err))
+		} else {
+			fmt.Fprintln(os.Stderr, tr("FAILED: %v", err))
+			if *detail && report != nil {
+				printFileChecks(report.Files)
+			}
+		}
+		os.Exit(exitCode(err))
+	}
+	if !jsonOutput {
+		fmt.Println(tr("OK — signature and integrity verified"))
+	}
+	if r := report.Revocation; r != nil {
+		fmt.Fprint(os.Stderr, tr("WARNING: the signing key was revoked at %s, after this container's recorded seal time", r.RevokedAt.Format(time.RFC3339)))
+		if r.Reason != "" {
+			fmt.Fprintf(os.Stderr, " (%s)", r.Reason)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+	if n := len(report.Unsigned); n > 0 {
+		fmt.Fprintln(os.Stderr, tr("WARNING: %d entry(ies) not covered by the signature, such as %s; -strict rejects them", n, report.Unsigned[0]))
+	}
+	if jsonOutput {
+		printJSON(newVerifyJSON(fs.Arg(0), report, opts))
+		return
+	}
+	if *detail {
+		fmt.Println(tr("  Signer: %s", formatSigner(report.Signer)))
+		if report.Rekor != nil {
+			id, iss := sigstore.Identity(report.Chain[0])
+			fmt.Printf("  Identity: %s (%s, issued by %s)\n", id, iss, report.Chain[0].Issuer)
+		} else if len(report.Chain) > 0 {
+			fmt.Printf("  Certificate: %s (issued by %s)\n", report.Chain[0].Subject, report.Chain[0].Issuer)
+		}
+		if report.SealedAt != nil {
+			fmt.Println(tr("  Sealed: %s", report.SealedAt.Format(time.RFC3339)))
+		}
+		if ts := report.Timestamp; ts != nil {
+			trust := "TSA not checked, see -tsa-ca"
+			if opts.TSARoots != nil {
+				trust = "trusted TSA"
+			}
+			fmt.Printf("  Timestamp: %s by %s (%s)\n", ts.Time.Format(time.RFC3339), ts.Signer.Subject, trust)
+		}
+		if e := report.Rekor; e != nil {
+			trust := "inclusion not checked, see -rekor"
+			if opts.RekorKey != nil {
+				trust = "inclusion proven"
+			}
+			fmt.Printf("  Transparency log: entry %d at %s (%s)\n", e.LogIndex, e.Time().Format(time.RFC3339), trust)
+		}
+		if p := report.Anchor; p != nil {
+			fmt.Printf("  Bitcoin anchor: block %s (blocks not checked, see imf anchor -upgrade)\n", joinHeights(p.Blocks()))
+		}
+		printFileChecks(report.Files)
+	}
+	if report.PQ != "" {
+		fmt.Printf("  Post-quantum: %s signature verified\n", report.PQ)
+	}
+	if opts.Detached != nil {
+		fmt.Printf("  Detached signature: verified (signed %s)\n", opts.Detached.SignedAt.Format(time.RFC3339))
+	}
+	if report.Policy != nil {
+		fmt.Printf("  Signatures: %d of %d required\n", len(report.Policy.Signed), report.Policy.Threshold)
+	}
+	if len(report.Witnesses) > 0 {
+		fmt.Println("  Witnesses:")
+		for i, w := range report.Witnesses {
+			name := w.Name
+			if name == "" {
+				name = "(unnamed)"
+			}
+			fmt.Printf("    %d. %s  %s  key %s\n", i+1, w.Timestamp.Format(time.RFC3339), name, w.PublicKey)
+		}
+	}
+}
+
+// printFileChecks lists the check of each file, for -detail: its status
+// and name, then the hash the manifest records and, if it differs, the hash
+// of what is stored.
+func printFileChecks(files []container.FileCheck) {
+	failed := 0
+	for _, f := range files {
+		if f.Status != container.FileStatusOK {
+			failed++
+		}
+	}
+	fmt.Println(tr("  Files: %d checked, %d failed", len(files), failed))
+	for _, f := range files {
+		fmt.Printf("    %-9s %s\n", strings.ToUpper(f.Status), f.Name)
+		what := "sha256"
+		if f.Encrypted {
+			what = "encrypted sha256"
+		}
+		switch {
+		case f.Status == container.FileStatusOK:
+			fmt.Printf("              %s %s\n", what, f.Expected)
+		case f.Err != nil:
+			fmt.Printf("              expected %s %s\n", what, f.Expected)
+			fmt.Printf("              unreadable: %v\n", f.Err)
+		case f.Computed != "":
+			fmt.Printf("              expected %s %s\n", what, f.Expected)
+			fmt.Printf("              computed %s %s\n", what, f.Computed)
+		default:
+			fmt.Printf("              expected %s %s\n", what, f.Expected)
+		}
+	}
+}
+
+// verifyResult is the outcome of verifying one container of a batch.
+type verifyResult struct {
+	path   string
+	report *container.VerifyReport
+	err    error
+}
+
+// runVerifyBatch verifies each of paths, jobs at a time, carrying on past
+// failures, then prints a table of the results in the order given and a
+// summary line. If any container failed, it exits with the status of the
+// worst failure. bar counts the containers verified.
+func runVerifyBatch(paths []string, opts container.VerifyOptions, jobs int, detail bool, bar *progressBar) {
+	results := make([]verifyResult, len(paths))
+	sem := make(chan struct{}, jobs)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int64
+	)
+	bar.update(stageContainers, 0, int64(len(paths)))
+	for i, path := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			report, err := container.VerifyWithReport(path, opts)
+			results[i] = verifyResult{path: path, report: report, err: err}
+			mu.Lock()
+			done++
+			bar.update(stageContainers, done, int64(len(paths)))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	bar.clear()
+
+	failed, warned, code := 0, 0, exitOK
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			code = worseExit(code, exitCode(r.err))
+		} else if r.report.Revocation != nil || len(r.report.Unsigned) > 0 {
+			warned++
+		}
+	}
+
+	if jsonOutput {
+		out := make([]verifyJSON, 0, len(results))
+		for _, r := range results {
+			if r.err != nil {
+				out = append(out, newFailedVerifyJSON(r.path, r.report, r.err))
+			} else {
+				out = append(out, newVerifyJSON(r.path, r.report, opts))
+			}
+		}
+		printJSON(out)
+	} else {
+		width := len("CONTAINER")
+		for _, r := range results {
+			width = max(width, len(r.path))
+		}
+		fmt.Printf("%-8s  %-*s  %s\n", "RESULT", width, "CONTAINER", "DETAIL")
+		fmt.Printf("%-8s  %-*s  %s\n", "------", width, "---------", "------")
+		for _, r := range results {
+			result, what := "OK", ""
+			switch {
+			case r.err != nil:
+				result, what = "FAILED", r.err.Error()
+			case r.report.Revocation != nil:
+				result = "WARNING"
+				what = "signing key revoked at " + r.report.Revocation.RevokedAt.Format(time.RFC3339) + ", after the seal"
+			case len(r.report.Unsigned) > 0:
+				result = "WARNING"
+				what = fmt.Sprintf("%d entry(ies) not covered by the signature", len(r.report.Unsigned))
+			case detail:
+				what = formatSigner(r.report.Signer)
+			}
+			fmt.Println(strings.TrimRight(fmt.Sprintf("%-8s  %-*s  %s", result, width, r.path, what), " "))
+		}
+		fmt.Printf("\n%d container(s): %d verified, %d failed", len(results), len(results)-failed, failed)
+		if warned > 0 {
+			fmt.Printf(", %d with warnings", warned)
+		}
+		fmt.Println()
+	}
+	if code != exitOK {
+		os.Exit(code)
+	}
+}
+
+// mustReadCertPool loads a PEM bundle of CA certificates, exiting on failure.
+func mustReadCertPool(path string) *x509.CertPool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading CA bundle: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		fmt.Fprintf(os.Stderr, "Error: no certificates found in %s\n", path)
 		os.Exit(1)
 	}
-	fmt.Println("OK — signature and integrity verified")
+	return pool
+}
+
+// mustLoadRekorKey reads a pinned Rekor public key, or fetches the key of
+// the log at IMF_REKOR_URL (default rekor.sigstore.dev) if path is empty,
+// exiting on failure.
+func mustLoadRekorKey(path string) crypto.PublicKey {
+	if path == "" {
+		url := os.Getenv("IMF_REKOR_URL")
+		if url == "" {
+			url = sigstore.DefaultRekorURL
+		}
+		key, err := sigstore.PublicKey(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+			os.Exit(exitCode(err))
+		}
+		return key
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading Rekor key: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	key, err := sigstore.ParsePublicKeyPEM(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing Rekor key %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+	return key
 }
@@ -0,0 +1,101 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// Build information, set at build time with
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// as build-app.sh does. Left unset, they fall back to what the Go toolchain
+// records when building from a checkout: the module's pseudo-version, the
+// commit, and the commit's time.
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// buildInfo returns the version, commit, and build date of this binary,
+// filling in what was not set at build time from the Go build info. The
+// commit gains a "-dirty" suffix if the checkout had local changes.
+func buildInfo() (ver, rev, date string) {
+	ver, rev, date = version, commit, buildDate
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ver, rev, date
+	}
+	if ver == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		ver = strings.TrimPrefix(info.Main.Version, "v")
+	}
+	dirty := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if rev == "" {
+				rev = s.Value
+			}
+		case "vcs.time":
+			if date == "" {
+				date = s.Value
+			}
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	if dirty && commit == "" && rev != "" {
+		rev += "-dirty"
+	}
+	return ver, rev, date
+}
+
+// runVersion handles the "imf version" command.
+// Prints the version, commit, and build date of this binary and the
+// manifest versions it reads, for support requests and for recording which
+// build produced or checked a container. With --json, the same is printed
+// as a versionJSON.
+func runVersion() {
+	parseArgs("imf version", "imf version", 0)
+
+	ver, rev, date := buildInfo()
+	manifests := make([]int, 0, manifest.Version)
+	for v := 1; v <= manifest.Version; v++ {
+		manifests = append(manifests, v)
+	}
+	if jsonOutput {
+		printJSON(versionJSON{
+			Version:          ver,
+			Commit:           rev,
+			BuildDate:        date,
+			GoVersion:        runtime.Version(),
+			Platform:         runtime.GOOS + "/" + runtime.GOARCH,
+			ManifestVersions: manifests,
+		})
+		return
+	}
+
+	fmt.Printf("imf %s\n", ver)
+	if rev == "" {
+		rev = "unknown"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+	fmt.Printf("  Commit:     %s\n", rev)
+	fmt.Printf("  Built:      %s\n", date)
+	fmt.Printf("  Go:         %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Printf("  Manifests:  version %d", manifests[0])
+	if len(manifests) > 1 {
+		fmt.Printf(" to %d", manifests[len(manifests)-1])
+	}
+	fmt.Println()
+}
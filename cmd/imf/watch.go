@@ -0,0 +1,126 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+// runWatch handles the "imf watch" command.
+// Watches a drop directory and packs and seals each file or subdirectory
+// that appears in it into a container of its own in the -out directory, as
+// "imf pack" would, once it has stopped changing. Sealed items are moved
+// aside to -done; with -anchor each container is also anchored, or queued
+// for "imf anchor -retry". It runs until interrupted, printing a line per
+// container sealed or item that failed (a line of JSON with --json).
+func runWatch() {
+	fs := flag.NewFlagSet("imf watch", flag.ExitOnError)
+	out := fs.String("out", "", "Directory to write the sealed containers to")
+	keyPath := fs.String("key", "", "Path to Ed25519 private key (PEM), keychain:NAME, hw:[NAME], or pkcs11:[LABEL]")
+	embedPub := fs.Bool("embed-pubkey", false, "Embed public key in each container")
+	passphrase := fs.String("passphrase", "", "Encryption passphrase ('none' to skip)")
+	iterations := fs.Int("kdf-iterations", 0, "PBKDF2 iterations for the passphrase (default 600000)")
+	interval := fs.Duration("interval", container.DefaultInboxInterval, "Time between scans of the directory")
+	done := fs.String("done", "", "Directory to move sealed items to (default <directory>/.done)")
+	anchorSeal := fs.Bool("anchor", false, "Anchor each container to Bitcoin via OpenTimestamps once sealed")
+	signerName := fs.String("name", "", "Your name, recorded with the signature")
+	signerEmail := fs.String("email", "", "Your email, recorded with the signature")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf watch <directory> -out <directory> -key <private.pem> [options]")
+		fmt.Fprintln(os.Stderr, "\nSeal each file or subdirectory dropped into a directory into a container of")
+		fmt.Fprintln(os.Stderr, "its own: inbox/report.pdf becomes report.pdf.imf, inbox/case-7/ case-7.imf.")
+		fmt.Fprintln(os.Stderr, "An item is sealed once two scans in a row find it unchanged, then moved to")
+		fmt.Fprintln(os.Stderr, "-done; names starting with \".\" are left alone, so upload to a hidden name")
+		fmt.Fprintln(os.Stderr, "and rename when done. Runs until interrupted.")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 || *out == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	inbox := fs.Arg(0)
+	*keyPath = defaultKey(*keyPath)
+	if *keyPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -key is required (or set key in ~/.imf/config)")
+		os.Exit(1)
+	}
+	if *iterations == 0 {
+		*iterations = settings().KDFIterations
+	}
+
+	pp := *passphrase
+	if pp == "" {
+		pp = containerPassphrase("Encryption passphrase (enter to skip): ", true)
+	}
+	if pp == "none" {
+		pp = ""
+	}
+
+	signer := mustLoadSigner(*keyPath)
+	defer releaseSigner(signer)
+	opts := container.InboxOptions{
+		Interval: *interval,
+		DoneDir:  *done,
+		Pack: container.PackOptions{
+			Seal: container.SealOptions{
+				Signer:      signer,
+				EmbedPubKey: *embedPub,
+				Passphrase:  pp,
+				Iterations:  *iterations,
+				SignerName:  *signerName,
+				SignerEmail: *signerEmail,
+			},
+		},
+	}
+	if *anchorSeal {
+		opts.Pack.Seal.Anchor = mustAnchorer("ots", nil, 1, "")
+	}
+
+	// report prints an event as a timestamped line, or with --json as a
+	// line of JSON.
+	report := func(e inboxEventJSON, text string) {
+		e.Time = time.Now().UTC()
+		if jsonOutput {
+			printJSONLine(e)
+			return
+		}
+		fmt.Printf("%s  %s: %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Item, text)
+	}
+	opts.OnError = func(item string, err error) {
+		report(inboxEventJSON{Event: "error", Item: item, Error: err.Error()}, err.Error())
+	}
+	opts.OnSealed = func(item, containerPath string, r *container.SealReport) {
+		e := inboxEventJSON{Event: "sealed", Item: item, Container: containerPath, Files: len(r.Files)}
+		text := fmt.Sprintf("sealed %d file(s) into %s", len(r.Files), containerPath)
+		switch {
+		case r.Anchor != nil:
+			e.Anchor = r.Anchor.ProofPath
+			text += ", anchor pending in " + r.Anchor.ProofPath
+		case r.AnchorErr != nil:
+			e.AnchorError = r.AnchorErr.Error()
+			text += fmt.Sprintf(", anchoring queued (%v)", r.AnchorErr)
+		}
+		report(e, text)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if !jsonOutput {
+		fmt.Printf("Watching %s every %s, sealing into %s (Ctrl-C to stop)...\n", inbox, *interval, *out)
+	}
+	if err := container.WatchInbox(ctx, inbox, *out, opts); err != nil && ctx.Err() == nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+}
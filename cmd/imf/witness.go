@@ -0,0 +1,59 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// runWitness handles the "imf witness" command.
+// A third party countersigns a sealed container: after verifying it, they
+// sign the manifest hash and the current time with their own Ed25519 key.
+// Each witness also signs the previous one, so "imf verify" can list the
+// witnesses as a chain in the order they signed.
+func runWitness() {
+	fs := flag.NewFlagSet("imf witness", flag.ExitOnError)
+	keyPath := fs.String("key", "", "Path to the witness's Ed25519 private key (PEM) or keychain:NAME")
+	name := fs.String("name", "", "Name to record with the witness signature")
+	verifyKeyPath := fs.String("verify-key", "", "Sealer's Ed25519 public key (PEM). Uses embedded key if omitted.")
+	ignoreExpiry := fs.Bool("ignore-expiry", false, "Witness even if container is expired")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: imf witness <container.imf> -key <private.pem> [options]")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
+		fs.PrintDefaults()
+	}
+
+	parseFlags(fs)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	containerPath := fs.Arg(0)
+	if *keyPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -key is required")
+		os.Exit(1)
+	}
+
+	opts := container.WitnessOptions{
+		Name:   *name,
+		Verify: container.VerifyOptions{IgnoreExpiry: *ignoreExpiry},
+	}
+	if *verifyKeyPath != "" {
+		opts.Verify.PublicKey = mustReadPublicKey(*verifyKeyPath)
+	}
+	privKey := mustReadPrivateKey(*keyPath)
+	defer imfcrypto.Wipe(privKey)
+	w, err := container.Witness(containerPath, privKey, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Error: %v", err))
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Witnessed %s at %s\n", containerPath, w.Timestamp.Format(time.RFC3339))
+}
@@ -1,3 +1,18 @@
 module github.com/immutable-container/imf
 
 go 1.22.2
+
+require (
+	github.com/cloudflare/circl v1.6.1
+	github.com/miekg/pkcs11 v1.1.1
+	github.com/zalando/go-keyring v0.2.8
+	golang.org/x/crypto v0.31.0
+	golang.org/x/term v0.27.0
+	golang.org/x/text v0.21.0
+)
+
+require (
+	github.com/danieljoos/wincred v1.2.3 // indirect
+	github.com/godbus/dbus/v5 v5.2.2 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+)
@@ -12,9 +12,12 @@
 //
 // The proof is initially "pending" — it takes a few hours for the calendar server
 // to batch multiple timestamps into a single Bitcoin transaction. After confirmation,
-// the proof upgrades to a full Bitcoin attestation.
+// Upgrade fetches the full Bitcoin attestation from the calendar.
 //
 // No accounts, API keys, wallets, or tokens are required.
+//
+// OpenTimestamps is one Anchorer among others: Notary submits the hash to
+// any HTTP service that returns a receipt for it.
 package anchor
 
 import (
@@ -25,23 +28,59 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
-// Default OpenTimestamps calendar servers.
-// Multiple servers are tried in order for redundancy.
-var calendarServers = []string{
+// DefaultCalendars are the OpenTimestamps calendar servers used when no
+// others are configured.
+var DefaultCalendars = []string{
 	"https://a.pool.opentimestamps.org",
 	"https://b.pool.opentimestamps.org",
 	"https://a.pool.eternitywall.com",
 }
 
+// AnchorOptions configures AnchorContainerWithOptions.
+type AnchorOptions struct {
+	Calendars []string // calendar servers to submit to; defaults to DefaultCalendars
+	// MinCalendars is how many calendars must accept the submission for
+	// it to succeed; defaults to 1. Each one that does can later complete
+	// the proof, so requiring more protects against a calendar that
+	// disappears before committing to Bitcoin.
+	MinCalendars int
+	// Target is what is anchored: TargetFile (the default) or
+	// TargetManifest.
+	Target string
+	// Progress, if set, is called as each calendar answers, with the
+	// number that have so far, accepted or not, of those submitted to.
+	Progress func(answered, total int)
+}
+
+// Anchor targets: what a proof's digest is the SHA-256 of.
+const (
+	// TargetFile anchors the container file. The proof also commits to
+	// the manifest, so it can later be embedded (see ManifestProof), but
+	// the .ots file itself stops matching if the container is rewritten.
+	TargetFile = "file"
+	// TargetManifest anchors the manifest's signable bytes alone, which
+	// stay the same when a sealed container is re-zipped, witnessed or
+	// cosigned, so the proof survives re-packaging of the same content.
+	TargetManifest = "manifest"
+)
+
 // AnchorResult contains the result of a timestamping operation.
 type AnchorResult struct {
 	ContainerHash string    // SHA-256 hex digest of the .imf file
+	Target        string    // TargetFile or TargetManifest
+	Digest        string    // Hex digest the proof is over: the file or manifest hash
 	ProofPath     string    // Path where the .ots proof file was saved
-	Server        string    // Calendar server that accepted the submission
+	Server        string    // First calendar server that accepted the submission
+	Servers       []string  // Every calendar server that accepted the submission
+	MerkleRoot    string    // Hex digest submitted to the calendars; shared by a batch
 	Timestamp     time.Time // When the submission was made
 }
 
@@ -51,51 +90,149 @@ type AnchorResult struct {
 //
 // Returns an AnchorResult with the hash, proof path, and server used.
 func AnchorContainer(containerPath string) (*AnchorResult, error) {
-	// Read the entire container and compute its SHA-256 hash.
-	data, err := os.ReadFile(containerPath)
+	return AnchorContainerWithOptions(containerPath, AnchorOptions{})
+}
+
+// AnchorContainerWithOptions is AnchorContainer with a choice of calendar
+// servers. The hash is submitted to all of them at once, and the proof
+// records a pending attestation from each one that accepted it.
+func AnchorContainerWithOptions(containerPath string, opts AnchorOptions) (*AnchorResult, error) {
+	results, err := AnchorBatch([]string{containerPath}, opts)
 	if err != nil {
-		return nil, fmt.Errorf("reading container: %w", err)
+		return nil, err
 	}
+	return results[0], nil
+}
 
-	hash := sha256.Sum256(data)
-	hashHex := hex.EncodeToString(hash[:])
+// submit sends digest to the calendars in opts and returns the merged
+// pending timestamps of those that accepted it.
+func submit(digest []byte, opts AnchorOptions) (*Timestamp, []string, error) {
+	calendars := opts.Calendars
+	if len(calendars) == 0 {
+		calendars = DefaultCalendars
+	}
+	need := opts.MinCalendars
+	if need <= 0 {
+		need = 1
+	}
+	if need > len(calendars) {
+		return nil, nil, fmt.Errorf("need %d calendars to accept the submission, but only %d are configured", need, len(calendars))
+	}
+
+	stamps := make([]*Timestamp, len(calendars))
+	errs := make([]error, len(calendars))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		answered int
+	)
+	if opts.Progress != nil {
+		opts.Progress(0, len(calendars))
+	}
+	for i, server := range calendars {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := submitDigest(strings.TrimSuffix(server, "/")+"/digest", digest)
+			if err == nil {
+				if stamps[i], err = ParseTimestamp(resp, digest); err != nil {
+					err = fmt.Errorf("calendar %s: %w", server, err)
+				}
+			}
+			errs[i] = err
+			if opts.Progress != nil {
+				mu.Lock()
+				answered++
+				opts.Progress(answered, len(calendars))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
 
-	// Submit the raw 32-byte digest to an OpenTimestamps calendar server.
-	// The server returns an OTS proof file (binary format).
-	var proof []byte
-	var usedServer string
-
-	for _, server := range calendarServers {
-		url := server + "/digest"
-		proof, err = submitDigest(url, hash[:])
-		if err == nil {
-			usedServer = server
-			break
+	stamp := &Timestamp{Msg: digest}
+	var used []string
+	var failures []error
+	for i, server := range calendars {
+		if errs[i] != nil {
+			failures = append(failures, errs[i])
+			continue
 		}
+		stamp.merge(stamps[i])
+		used = append(used, server)
 	}
 
-	if proof == nil {
-		return nil, errors.New("all OpenTimestamps servers failed — check your internet connection")
+	if len(used) == 0 {
+		return nil, nil, fmt.Errorf("all OpenTimestamps servers failed — check your internet connection: %w", errors.Join(failures...))
+	}
+	if len(used) < need {
+		return nil, nil, fmt.Errorf("only %d of %d calendars accepted the submission, %d required: %w", len(used), len(calendars), need, errors.Join(failures...))
 	}
+	return stamp, used, nil
+}
 
-	// Save the proof receipt alongside the container.
-	// e.g., "archive.imf" → "archive.imf.ots"
-	proofPath := containerPath + ".ots"
-	if err := os.WriteFile(proofPath, proof, 0644); err != nil {
-		return nil, fmt.Errorf("saving proof: %w", err)
+// DefaultCalendarsFile returns the calendar list file: $IMF_CALENDARS if
+// set, otherwise ~/.imf/calendars.
+func DefaultCalendarsFile() (string, error) {
+	if path := os.Getenv("IMF_CALENDARS"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
 	}
+	return filepath.Join(home, ".imf", "calendars"), nil
+}
 
-	return &AnchorResult{
-		ContainerHash: hashHex,
-		ProofPath:     proofPath,
-		Server:        usedServer,
-		Timestamp:     time.Now(),
-	}, nil
+// ConfiguredCalendars returns the calendars listed in DefaultCalendarsFile,
+// or nil (meaning DefaultCalendars) if there is no such file.
+func ConfiguredCalendars() ([]string, error) {
+	path, err := DefaultCalendarsFile()
+	if err != nil {
+		return nil, err
+	}
+	return LoadCalendars(path)
 }
 
-// VerifyAnchor checks that a .ots proof file matches the container's hash.
-// This is a local check only — it confirms the proof was generated for this
-// specific container. Full Bitcoin verification requires an OTS verifier.
+// LoadCalendars reads a calendar list: one server URL per line, with blank
+// lines and lines starting with '#' ignored. A missing file is an empty
+// list.
+func LoadCalendars(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading calendar list: %w", err)
+	}
+	var calendars []string
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := CheckCalendarURL(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n+1, err)
+		}
+		calendars = append(calendars, line)
+	}
+	return calendars, nil
+}
+
+// CheckCalendarURL rejects anything but an absolute http(s) URL.
+func CheckCalendarURL(calendar string) error {
+	u, err := url.Parse(calendar)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid calendar URL %q", calendar)
+	}
+	return nil
+}
+
+// VerifyAnchor checks that a .ots proof file matches the container's hash,
+// or, without one, that the container has an embedded proof matching its
+// manifest. This is a local check only — it confirms the proof was generated
+// for this specific container. Full Bitcoin verification requires an OTS
+// verifier.
 func VerifyAnchor(containerPath string) (*VerifyResult, error) {
 	// Read container and compute hash.
 	data, err := os.ReadFile(containerPath)
@@ -108,18 +245,45 @@ func VerifyAnchor(containerPath string) (*VerifyResult, error) {
 	// Read the proof file.
 	proofPath := containerPath + ".ots"
 	proof, err := os.ReadFile(proofPath)
+	if errors.Is(err, os.ErrNotExist) {
+		p, perr := embeddedProof(data)
+		if perr != nil {
+			return nil, perr
+		}
+		if p != nil {
+			return &VerifyResult{
+				ContainerHash: hashHex,
+				Target:        TargetManifest,
+				ProofPath:     containerPath,
+				ProofSize:     len(p.Marshal()),
+				HashMatches:   true,
+				Embedded:      true,
+			}, nil
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("reading proof file: %w", err)
 	}
 
-	// Check that the proof contains the expected hash.
-	// OTS proof files embed the original digest — verify it matches.
-	if !bytes.Contains(proof, hash[:]) {
-		return nil, errors.New("proof does not match container — container may have been modified after anchoring")
+	// Check that the proof was made for the expected hash. Proofs from
+	// earlier versions have no header, only the calendar's timestamp, so
+	// the best that can be done is to look for the digest in them.
+	target := TargetFile
+	if bytes.HasPrefix(proof, otsMagic) {
+		p, err := ParseProof(proof)
+		if err != nil {
+			return nil, err
+		}
+		if target, err = matchDigest(data, p.Digest); err != nil {
+			return nil, err
+		}
+	} else if !bytes.Contains(proof, hash[:]) {
+		return nil, errMismatch
 	}
 
 	return &VerifyResult{
 		ContainerHash: hashHex,
+		Target:        target,
 		ProofPath:     proofPath,
 		ProofSize:     len(proof),
 		HashMatches:   true,
@@ -129,15 +293,17 @@ func VerifyAnchor(containerPath string) (*VerifyResult, error) {
 // VerifyResult contains the result of a local anchor verification.
 type VerifyResult struct {
 	ContainerHash string // SHA-256 hex digest of the .imf file
+	Target        string // What the proof is over: TargetFile or TargetManifest
 	ProofPath     string // Path to the .ots proof file
 	ProofSize     int    // Size of the proof in bytes
 	HashMatches   bool   // Whether the proof matches the container hash
+	Embedded      bool   // Whether the proof is embedded in the container
 }
 
 // submitDigest POSTs a raw 32-byte SHA-256 digest to an OTS calendar server.
 // Returns the binary OTS proof on success.
 func submitDigest(url string, digest []byte) ([]byte, error) {
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := httpClient(15 * time.Second)
 
 	req, err := http.NewRequest("POST", url, bytes.NewReader(digest))
 	if err != nil {
@@ -0,0 +1,663 @@
+package anchor_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// step applies op to t's message and hangs the result off t.
+func step(t *testing.T, ts *anchor.Timestamp, op anchor.Op) *anchor.Timestamp {
+	t.Helper()
+	msg, err := op.Apply(ts.Msg)
+	if err != nil {
+		t.Fatalf("%s: %v", op, err)
+	}
+	next := &anchor.Timestamp{Msg: msg}
+	ts.Ops = append(ts.Ops, anchor.Branch{Op: op, Timestamp: next})
+	return next
+}
+
+func TestUpgrade(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "archive.imf")
+	os.WriteFile(imfPath, []byte("a sealed container"), 0644)
+	digest := sha256.Sum256([]byte("a sealed container"))
+
+	// The calendar's commitment: the digest with a nonce, hashed. Once
+	// "confirmed", it returns the path from there to a block's merkle root.
+	commit := &anchor.Timestamp{Msg: digest[:]}
+	leaf := step(t, step(t, commit, anchor.Op{Tag: anchor.OpAppend, Arg: []byte("nonce")}), anchor.Op{Tag: anchor.OpSHA256})
+	upgraded := &anchor.Timestamp{Msg: leaf.Msg}
+	root := step(t, step(t, upgraded, anchor.Op{Tag: anchor.OpPrepend, Arg: bytes.Repeat([]byte{7}, 32)}), anchor.Op{Tag: anchor.OpSHA256})
+	root.Attestations = append(root.Attestations, anchor.BitcoinAttestation(800000))
+	blockTime := time.Date(2023, 7, 24, 1, 2, 3, 0, time.UTC)
+
+	confirmed := false
+	calendar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/timestamp/"+hex.EncodeToString(leaf.Msg) || !confirmed {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(upgraded.Marshal())
+	}))
+	defer calendar.Close()
+	leaf.Attestations = append(leaf.Attestations, anchor.PendingAttestation(calendar.URL))
+
+	merkleRoot, _ := anchor.Op{Tag: anchor.OpReverse}.Apply(root.Msg)
+	explorer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/block-height/800000":
+			w.Write([]byte("00000000000000000002a7c4"))
+		case "/block/00000000000000000002a7c4":
+			json.NewEncoder(w).Encode(map[string]any{
+				"id":          "00000000000000000002a7c4",
+				"timestamp":   blockTime.Unix(),
+				"merkle_root": hex.EncodeToString(merkleRoot),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer explorer.Close()
+
+	proofPath := imfPath + ".ots"
+	pending := (&anchor.Proof{Digest: digest[:], Timestamp: commit}).Marshal()
+	os.WriteFile(proofPath, pending, 0644)
+	parsed, err := anchor.ParseProof(pending)
+	if err != nil || !bytes.Equal(parsed.Marshal(), pending) {
+		t.Fatalf("proof does not round-trip: %v", err)
+	}
+	if _, err := anchor.VerifyAnchor(imfPath); err != nil {
+		t.Fatalf("VerifyAnchor: %v", err)
+	}
+
+	opts := anchor.UpgradeOptions{Explorer: explorer.URL}
+	result, err := anchor.UpgradeWithOptions(imfPath, opts)
+	if err != nil {
+		t.Fatalf("Upgrade while pending: %v", err)
+	}
+	if result.Complete() || result.Upgraded || len(result.Pending) != 1 || result.Pending[0] != calendar.URL {
+		t.Fatalf("pending upgrade result %+v", result)
+	}
+	if got, _ := os.ReadFile(proofPath); !bytes.Equal(got, pending) {
+		t.Fatal("pending proof was rewritten")
+	}
+
+	confirmed = true
+	result, err = anchor.UpgradeWithOptions(imfPath, opts)
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	if !result.Complete() || !result.Upgraded || len(result.Pending) != 0 {
+		t.Fatalf("upgrade result %+v", result)
+	}
+	if b := result.Blocks[0]; b.Height != 800000 || !b.Time.Equal(blockTime) {
+		t.Fatalf("block %+v, want height 800000 at %s", b, blockTime)
+	}
+	data, _ := os.ReadFile(proofPath)
+	complete, err := anchor.ParseProof(data)
+	if err != nil {
+		t.Fatalf("ParseProof after upgrade: %v", err)
+	}
+	var atts []string
+	complete.Timestamp.Walk(func(n *anchor.Timestamp) {
+		for _, a := range n.Attestations {
+			atts = append(atts, a.String())
+		}
+	})
+	if len(atts) != 1 || atts[0] != "Bitcoin block 800000 attestation" {
+		t.Fatalf("attestations after upgrade: %v", atts)
+	}
+	if _, err := anchor.VerifyAnchor(imfPath); err != nil {
+		t.Fatalf("VerifyAnchor after upgrade: %v", err)
+	}
+
+	// An attestation to a block with another merkle root is rejected.
+	merkleRoot[0] ^= 1
+	if _, err := anchor.UpgradeWithOptions(imfPath, opts); err == nil || !strings.Contains(err.Error(), "merkle root") {
+		t.Fatalf("expected a merkle root mismatch, got %v", err)
+	}
+
+	// A modified container no longer matches its proof.
+	os.WriteFile(imfPath, []byte("a tampered container"), 0644)
+	if _, err := anchor.Upgrade(imfPath); err == nil {
+		t.Fatal("expected a modified container to be rejected")
+	}
+	t.Logf("✓ Pending proof upgraded to Bitcoin block %d", result.Blocks[0].Height)
+}
+
+// newCalendar starts a fake calendar that returns a pending timestamp for
+// each digest submitted and, once confirmed is set, the path from it to a
+// Bitcoin block at height.
+func newCalendar(t *testing.T, height uint64, confirmed *atomic.Bool) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	leaves := map[string]*anchor.Timestamp{}
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == "/digest" {
+			digest, _ := io.ReadAll(r.Body)
+			ts := &anchor.Timestamp{Msg: digest}
+			leaf := step(t, step(t, ts, anchor.Op{Tag: anchor.OpAppend, Arg: []byte(srv.URL)}), anchor.Op{Tag: anchor.OpSHA256})
+			leaf.Attestations = append(leaf.Attestations, anchor.PendingAttestation(srv.URL))
+			leaves[hex.EncodeToString(leaf.Msg)] = &anchor.Timestamp{Msg: leaf.Msg}
+			w.Write(ts.Marshal())
+			return
+		}
+		done, ok := leaves[strings.TrimPrefix(r.URL.Path, "/timestamp/")]
+		if !ok || !confirmed.Load() {
+			http.NotFound(w, r)
+			return
+		}
+		if len(done.Ops) == 0 {
+			step(t, done, anchor.Op{Tag: anchor.OpSHA256}).Attestations = []anchor.Attestation{anchor.BitcoinAttestation(height)}
+		}
+		w.Write(done.Marshal())
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// sealedContainer creates a sealed container holding one file with the
+// given contents.
+func sealedContainer(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	// Keep the anchor log out of the home directory.
+	t.Setenv("IMF_ANCHOR_LOG", filepath.Join(dir, "anchors.jsonl"))
+	imfPath := filepath.Join(dir, name+".imf")
+	container.Create(imfPath)
+	src := filepath.Join(dir, name+".txt")
+	os.WriteFile(src, []byte(contents), 0644)
+	container.Add(imfPath, []string{src})
+	kp, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	return imfPath
+}
+
+func TestAnchorCalendars(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := sealedContainer(t, tmpDir, "nightly", "1,2,3")
+
+	var confirmed atomic.Bool
+	a, b := newCalendar(t, 860000, &confirmed), newCalendar(t, 860000, &confirmed)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	calendars := []string{a.URL, down.URL, b.URL}
+
+	if _, err := anchor.AnchorContainerWithOptions(imfPath, anchor.AnchorOptions{Calendars: calendars, MinCalendars: 3}); err == nil {
+		t.Fatal("expected 3 required submissions with one calendar down to fail")
+	}
+	var answered []int
+	result, err := anchor.AnchorContainerWithOptions(imfPath, anchor.AnchorOptions{
+		Calendars:    calendars,
+		MinCalendars: 2,
+		Progress:     func(n, total int) { answered = append(answered, n) },
+	})
+	if err != nil {
+		t.Fatalf("AnchorContainerWithOptions: %v", err)
+	}
+	if !reflect.DeepEqual(answered, []int{0, 1, 2, 3}) {
+		t.Fatalf("progress %v, want every calendar to answer, down or not", answered)
+	}
+	if !reflect.DeepEqual(result.Servers, []string{a.URL, b.URL}) || result.Server != a.URL {
+		t.Fatalf("servers %v, first %s", result.Servers, result.Server)
+	}
+
+	// Both calendars can complete the proof; the one that does first is
+	// enough to embed it.
+	opts := anchor.UpgradeOptions{Explorer: down.URL}
+	if up, err := anchor.UpgradeWithOptions(imfPath, opts); err != nil || up.Complete() || len(up.Pending) != 2 {
+		t.Fatalf("pending upgrade: %+v, %v", up, err)
+	}
+	confirmed.Store(true)
+	up, err := anchor.UpgradeWithOptions(imfPath, opts)
+	if err != nil || !up.Complete() || len(up.Pending) != 0 {
+		t.Fatalf("upgrade: %+v, %v", up, err)
+	}
+	if b := up.Blocks[0]; b.Height != 860000 || !b.Time.IsZero() {
+		t.Fatalf("block %+v with the explorer down", b)
+	}
+	proof, err := anchor.ManifestProof(imfPath)
+	if err != nil {
+		t.Fatalf("ManifestProof: %v", err)
+	}
+	if err := container.EmbedAnchor(imfPath, proof); err != nil {
+		t.Fatalf("EmbedAnchor: %v", err)
+	}
+
+	list := filepath.Join(tmpDir, "calendars")
+	os.WriteFile(list, []byte("# private calendars\n"+a.URL+"\n\n  "+b.URL+"\n"), 0644)
+	got, err := anchor.LoadCalendars(list)
+	if err != nil || !reflect.DeepEqual(got, []string{a.URL, b.URL}) {
+		t.Fatalf("LoadCalendars: %v, %v", got, err)
+	}
+	os.WriteFile(list, []byte("ftp://calendar.example\n"), 0644)
+	if _, err := anchor.LoadCalendars(list); err == nil {
+		t.Fatal("expected a non-HTTP calendar to be rejected")
+	}
+	t.Logf("✓ Anchored with %d of %d calendars and embedded after confirmation", len(result.Servers), len(calendars))
+}
+
+func TestAnchorBatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"mon", "tue", "wed", "thu", "fri"} {
+		paths = append(paths, sealedContainer(t, tmpDir, name, "archive of "+name))
+	}
+	var confirmed atomic.Bool
+	cal := newCalendar(t, 870000, &confirmed)
+	opts := anchor.AnchorOptions{Calendars: []string{cal.URL}}
+
+	if _, err := anchor.AnchorBatch(append(paths, paths[0]), opts); err == nil {
+		t.Fatal("expected a container listed twice to be rejected")
+	}
+	if _, err := anchor.AnchorBatch(append(paths, filepath.Join(tmpDir, "missing.imf")), opts); err == nil {
+		t.Fatal("expected a missing container to fail the batch")
+	}
+	results, err := anchor.AnchorBatch(paths, opts)
+	if err != nil {
+		t.Fatalf("AnchorBatch: %v", err)
+	}
+
+	confirmed.Store(true)
+	for i, r := range results {
+		if r.MerkleRoot != results[0].MerkleRoot {
+			t.Fatalf("%s has Merkle root %s, want %s", paths[i], r.MerkleRoot, results[0].MerkleRoot)
+		}
+		// A proof reveals nothing about the other containers in the batch.
+		proof, _ := os.ReadFile(r.ProofPath)
+		for j, other := range results {
+			h, _ := hex.DecodeString(other.ContainerHash)
+			if j != i && bytes.Contains(proof, h) {
+				t.Fatalf("proof for %s contains the hash of %s", paths[i], paths[j])
+			}
+		}
+		up, err := anchor.UpgradeWithOptions(paths[i], anchor.UpgradeOptions{Explorer: cal.URL})
+		if err != nil || !up.Complete() || up.Blocks[0].Height != 870000 {
+			t.Fatalf("upgrading %s: %+v, %v", paths[i], up, err)
+		}
+		if _, err := anchor.ManifestProof(paths[i]); err != nil {
+			t.Fatalf("ManifestProof(%s): %v", paths[i], err)
+		}
+	}
+	records, err := anchor.ReadLog()
+	if err != nil || len(records) != len(paths) {
+		t.Fatalf("anchor log: %+v, %v", records, err)
+	}
+	for i, r := range records {
+		if r.Container != paths[i] || r.Hash != results[i].ContainerHash || r.Backend != "ots" || r.MerkleRoot != results[0].MerkleRoot {
+			t.Fatalf("record %d: %+v", i, r)
+		}
+	}
+	t.Logf("✓ %d containers anchored under Merkle root %s…", len(results), results[0].MerkleRoot[:16])
+}
+
+func TestWatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	paths := []string{sealedContainer(t, tmpDir, "q1", "first quarter"), sealedContainer(t, tmpDir, "q2", "second quarter")}
+	var confirmed atomic.Bool
+	cal := newCalendar(t, 880000, &confirmed)
+	if _, err := anchor.AnchorBatch(paths, anchor.AnchorOptions{Calendars: []string{cal.URL}}); err != nil {
+		t.Fatalf("AnchorBatch: %v", err)
+	}
+
+	events := make(chan map[string]any, len(paths))
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]any
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	defer hook.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	confirmations := make(chan string, len(paths))
+	opts := anchor.WatchOptions{
+		Interval: 10 * time.Millisecond,
+		Upgrade:  anchor.UpgradeOptions{Explorer: cal.URL},
+		OnConfirm: func(path string, r *anchor.UpgradeResult) {
+			if err := anchor.PostWebhook(hook.URL, path, r); err != nil {
+				t.Errorf("PostWebhook: %v", err)
+			}
+			confirmations <- path
+		},
+		OnError: func(path string, err error) { t.Errorf("%s: %v", path, err) },
+	}
+	stopped := make(chan error, 1)
+	go func() { stopped <- anchor.Watch(ctx, []string{tmpDir}, opts) }()
+
+	time.Sleep(50 * time.Millisecond)
+	if len(confirmations) != 0 {
+		t.Fatal("proof confirmed before the calendar committed it")
+	}
+	confirmed.Store(true)
+	seen := map[string]bool{}
+	for range paths {
+		select {
+		case path := <-confirmations:
+			seen[path] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for confirmations")
+		}
+	}
+	if !seen[paths[0]] || !seen[paths[1]] {
+		t.Fatalf("confirmed %v, want %v", seen, paths)
+	}
+	for range paths {
+		event := <-events
+		if event["event"] != "anchor.confirmed" || !seen[event["container"].(string)] {
+			t.Fatalf("webhook event %v", event)
+		}
+		if blocks := event["blocks"].([]any); blocks[0].(map[string]any)["height"] != float64(880000) {
+			t.Fatalf("webhook blocks %v", blocks)
+		}
+	}
+
+	// A directory is watched until cancelled; named containers only until
+	// their proofs are complete.
+	cancel()
+	if err := <-stopped; err != context.Canceled {
+		t.Fatalf("Watch on a directory returned %v", err)
+	}
+	if err := anchor.Watch(context.Background(), paths, opts); err != nil {
+		t.Fatalf("Watch on complete proofs: %v", err)
+	}
+	t.Logf("✓ %d proofs upgraded by the watcher and reported to the webhook", len(seen))
+}
+
+func TestStatus(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := sealedContainer(t, tmpDir, "ledger", "debits and credits")
+	if s, err := anchor.Status(imfPath); s != nil || err != nil {
+		t.Fatalf("status without a proof: %+v, %v", s, err)
+	}
+
+	var confirmed atomic.Bool
+	cal := newCalendar(t, 890000, &confirmed)
+	result, err := anchor.AnchorContainerWithOptions(imfPath, anchor.AnchorOptions{Calendars: []string{cal.URL}})
+	if err != nil {
+		t.Fatalf("AnchorContainerWithOptions: %v", err)
+	}
+	s, err := anchor.Status(imfPath)
+	if err != nil || s.State != anchor.StatePending || s.Hash != result.ContainerHash || !reflect.DeepEqual(s.Pending, []string{cal.URL}) {
+		t.Fatalf("pending status: %+v, %v", s, err)
+	}
+
+	confirmed.Store(true)
+	if _, err := anchor.UpgradeWithOptions(imfPath, anchor.UpgradeOptions{Explorer: cal.URL}); err != nil {
+		t.Fatalf("UpgradeWithOptions: %v", err)
+	}
+	s, err = anchor.Status(imfPath)
+	if err != nil || s.State != anchor.StateConfirmed || len(s.Pending) != 0 || s.Blocks[0].Height != 890000 || !s.Blocks[0].Time.IsZero() {
+		t.Fatalf("confirmed status: %+v, %v", s, err)
+	}
+
+	proof, _ := anchor.ManifestProof(imfPath)
+	if err := container.EmbedAnchor(imfPath, proof); err != nil {
+		t.Fatalf("EmbedAnchor: %v", err)
+	}
+	if s, err = anchor.Status(imfPath); err != nil || s.State != anchor.StateMismatch || s.Embedded {
+		t.Fatalf("status of a rewritten container: %+v, %v", s, err)
+	}
+	os.Remove(imfPath + ".ots")
+	s, err = anchor.Status(imfPath)
+	if err != nil || s.State != anchor.StateConfirmed || !s.Embedded || s.Hash != hex.EncodeToString(proof.Digest) {
+		t.Fatalf("embedded status: %+v, %v", s, err)
+	}
+	t.Logf("✓ Anchor status followed from pending to confirmed in block %d and embedded", s.Blocks[0].Height)
+}
+
+func TestInspect(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := sealedContainer(t, tmpDir, "minutes", "board minutes")
+	if p, _, err := anchor.LoadProof(imfPath); p != nil || err != nil {
+		t.Fatalf("LoadProof without a proof: %v, %v", p, err)
+	}
+
+	var confirmed atomic.Bool
+	cal := newCalendar(t, 891000, &confirmed)
+	result, err := anchor.AnchorContainerWithOptions(imfPath, anchor.AnchorOptions{Calendars: []string{cal.URL}})
+	if err != nil {
+		t.Fatalf("AnchorContainerWithOptions: %v", err)
+	}
+	p, where, err := anchor.LoadProof(imfPath)
+	if err != nil || where != imfPath+".ots" || hex.EncodeToString(p.Digest) != result.ContainerHash {
+		t.Fatalf("LoadProof: %v, %q, %v", p, where, err)
+	}
+	if !reflect.DeepEqual(p.Calendars(), []string{cal.URL}) {
+		t.Errorf("Calendars = %v, want [%s]", p.Calendars(), cal.URL)
+	}
+	if out := p.Timestamp.Format(); !strings.Contains(out, "verify pending attestation at "+cal.URL) || !strings.Contains(out, "sha256\n") {
+		t.Errorf("pending proof formatted as:\n%s", out)
+	}
+
+	confirmed.Store(true)
+	if _, err := anchor.UpgradeWithOptions(imfPath, anchor.UpgradeOptions{Explorer: cal.URL}); err != nil {
+		t.Fatalf("UpgradeWithOptions: %v", err)
+	}
+	p, _, err = anchor.LoadProof(imfPath)
+	if err != nil || len(p.Calendars()) != 0 {
+		t.Fatalf("LoadProof after upgrade: %v, %v", p, err)
+	}
+	out := p.Timestamp.Format()
+	if !strings.Contains(out, "verify Bitcoin block 891000 attestation") || strings.Contains(out, "pending") {
+		t.Errorf("confirmed proof formatted as:\n%s", out)
+	}
+	t.Logf("✓ Proof inspected while pending and after confirmation in block 891000")
+}
+
+func TestNotary(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := sealedContainer(t, tmpDir, "contract", "signed contract")
+
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"serial": 7}`))
+	}))
+	defer srv.Close()
+
+	a, err := anchor.NewAnchorer("notary", []string{srv.URL}, 1, "")
+	if err != nil {
+		t.Fatalf("NewAnchorer: %v", err)
+	}
+	if _, err := a.Anchor(imfPath); err == nil {
+		t.Fatal("expected the notary to refuse a submission without its token")
+	}
+	a.(*anchor.Notary).Token = "s3cret"
+	result, err := a.Anchor(imfPath)
+	if err != nil {
+		t.Fatalf("Anchor: %v", err)
+	}
+	if got["hash"] != result.ContainerHash || got["algorithm"] != "sha256" {
+		t.Fatalf("notary received %v, want hash %s", got, result.ContainerHash)
+	}
+	var receipt anchor.NotaryReceipt
+	raw, _ := os.ReadFile(result.ProofPath)
+	if err := json.Unmarshal(raw, &receipt); err != nil || string(receipt.Receipt) != `{"serial": 7}` {
+		t.Fatalf("receipt %s, %v", raw, err)
+	}
+
+	// Verifying needs only the receipt, not the notary.
+	verifier, _ := anchor.NewAnchorer("notary", nil, 1, "")
+	if v, err := verifier.Verify(imfPath); err != nil || !v.HashMatches {
+		t.Fatalf("Verify: %+v, %v", v, err)
+	}
+	f, _ := os.OpenFile(imfPath, os.O_APPEND|os.O_WRONLY, 0)
+	f.Write([]byte("x"))
+	f.Close()
+	if _, err := verifier.Verify(imfPath); err == nil {
+		t.Fatal("expected a modified container to fail verification")
+	}
+	if _, err := anchor.NewAnchorer("ethereum", nil, 1, ""); err == nil {
+		t.Fatal("expected an unknown backend to be rejected")
+	}
+	t.Logf("✓ Container notarized by %s and receipt verified", result.Server)
+}
+
+func TestProxy(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := sealedContainer(t, tmpDir, "whistle", "for the record")
+
+	// The proxy answers for the calendar itself, whose name does not
+	// resolve: the request can only succeed if it went through the proxy.
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		digest, _ := io.ReadAll(r.Body)
+		w.Write((&anchor.Timestamp{Msg: digest, Attestations: []anchor.Attestation{anchor.PendingAttestation("http://calendar.invalid")}}).Marshal())
+	}))
+	defer proxy.Close()
+
+	if err := anchor.SetProxy("ftp://" + proxy.Listener.Addr().String()); err == nil {
+		t.Fatal("expected an unsupported proxy scheme to be rejected")
+	}
+	if err := anchor.SetProxy(proxy.URL); err != nil {
+		t.Fatalf("SetProxy: %v", err)
+	}
+	defer anchor.SetProxy("")
+	if _, err := anchor.AnchorContainerWithOptions(imfPath, anchor.AnchorOptions{Calendars: []string{"http://calendar.invalid"}}); err != nil {
+		t.Fatalf("AnchorContainerWithOptions: %v", err)
+	}
+	if !reflect.DeepEqual(proxied, []string{"http://calendar.invalid/digest"}) {
+		t.Fatalf("proxy saw %v", proxied)
+	}
+	t.Logf("✓ Calendar reached only through the proxy at %s", proxy.URL)
+}
+
+// rezip rewrites a container with its entries in reverse order, as another
+// ZIP tool might: the same content in a different file.
+func rezip(t *testing.T, imfPath string) {
+	t.Helper()
+	zr, err := zip.OpenReader(imfPath)
+	if err != nil {
+		t.Fatalf("opening %s: %v", imfPath, err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := len(zr.File) - 1; i >= 0; i-- {
+		rc, _ := zr.File[i].Open()
+		w, _ := zw.Create(zr.File[i].Name)
+		io.Copy(w, rc)
+		rc.Close()
+	}
+	zr.Close()
+	zw.Close()
+	os.WriteFile(imfPath, buf.Bytes(), 0644)
+}
+
+func TestAnchorManifestTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	paths := []string{sealedContainer(t, tmpDir, "thesis", "chapter one"), sealedContainer(t, tmpDir, "data", "1,2,3")}
+	var confirmed atomic.Bool
+	cal := newCalendar(t, 900000, &confirmed)
+	opts := anchor.AnchorOptions{Calendars: []string{cal.URL}, Target: "header"}
+	if _, err := anchor.AnchorBatch(paths, opts); err == nil {
+		t.Fatal("expected an unknown target to be rejected")
+	}
+	opts.Target = anchor.TargetManifest
+	results, err := anchor.AnchorBatch(paths[:1], opts)
+	if err != nil {
+		t.Fatalf("AnchorBatch: %v", err)
+	}
+	opts.Target = anchor.TargetFile
+	if _, err := anchor.AnchorBatch(paths[1:], opts); err != nil {
+		t.Fatalf("AnchorBatch: %v", err)
+	}
+	if r := results[0]; r.Target != anchor.TargetManifest || r.Digest == r.ContainerHash {
+		t.Fatalf("result %+v", r)
+	}
+
+	// Re-zipping changes the file but not the signed manifest: only the
+	// manifest proof still matches.
+	for _, p := range paths {
+		rezip(t, p)
+	}
+	v, err := anchor.VerifyAnchor(paths[0])
+	if err != nil || v.Target != anchor.TargetManifest {
+		t.Fatalf("VerifyAnchor after re-zip: %+v, %v", v, err)
+	}
+	if _, err := anchor.VerifyAnchor(paths[1]); err == nil {
+		t.Fatal("expected the file proof to stop matching after re-zip")
+	}
+	if err := container.Verify(paths[0], container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify after re-zip: %v", err)
+	}
+
+	confirmed.Store(true)
+	if up, err := anchor.UpgradeWithOptions(paths[0], anchor.UpgradeOptions{Explorer: cal.URL}); err != nil || !up.Complete() {
+		t.Fatalf("UpgradeWithOptions: %+v, %v", up, err)
+	}
+	s, err := anchor.Status(paths[0])
+	if err != nil || s.State != anchor.StateConfirmed || s.Target != anchor.TargetManifest || s.Hash != results[0].Digest {
+		t.Fatalf("status: %+v, %v", s, err)
+	}
+	proof, err := anchor.ManifestProof(paths[0])
+	if err != nil {
+		t.Fatalf("ManifestProof: %v", err)
+	}
+	if err := container.EmbedAnchor(paths[0], proof); err != nil {
+		t.Fatalf("EmbedAnchor: %v", err)
+	}
+	if records, _ := anchor.ReadLog(); records[0].Target != anchor.TargetManifest || records[0].Digest != results[0].Digest {
+		t.Fatalf("log records %+v", records)
+	}
+	t.Logf("✓ Manifest anchor survived re-zipping and embedded in block %d", proof.Blocks()[0])
+}
+
+func TestPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := sealedContainer(t, tmpDir, "plan", "dry run")
+	data, _ := os.ReadFile(imfPath)
+	hash := sha256.Sum256(data)
+
+	a, _ := anchor.NewAnchorer("ots", []string{"https://cal.example/", "https://cal2.example"}, 2, "")
+	plan, err := anchor.Plan(imfPath, a)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	want := []string{"POST https://cal.example/digest", "POST https://cal2.example/digest"}
+	if plan.Digest != hex.EncodeToString(hash[:]) || plan.Target != anchor.TargetFile || !reflect.DeepEqual(plan.Requests, want) || plan.MinAccepted != 2 {
+		t.Fatalf("plan %+v", plan)
+	}
+	if plan.ProofPath != imfPath+".ots" || plan.Overwrites {
+		t.Fatalf("proof %s, overwrites %v", plan.ProofPath, plan.Overwrites)
+	}
+	if _, err := os.Stat(imfPath + ".ots"); err == nil {
+		t.Fatal("Plan wrote a proof")
+	}
+
+	n, _ := anchor.NewAnchorer("notary", []string{"https://notary.example/anchor"}, 1, anchor.TargetManifest)
+	plan, err = anchor.Plan(imfPath, n)
+	if err != nil {
+		t.Fatalf("Plan notary: %v", err)
+	}
+	if plan.Target != anchor.TargetManifest || plan.Digest == plan.ContainerHash || plan.ProofPath != imfPath+".receipt" {
+		t.Fatalf("notary plan %+v", plan)
+	}
+	t.Log("✓ Planned anchors without contacting any server")
+}
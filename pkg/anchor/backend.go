@@ -0,0 +1,68 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import "fmt"
+
+// Anchorer timestamps a container's hash with an external service and
+// checks the receipt it leaves next to the container. OpenTimestamps is the
+// default; Notary is an alternative for those who would rather rely on a
+// service they run or trust than on Bitcoin.
+type Anchorer interface {
+	// Name identifies the backend, as given to imf anchor -backend.
+	Name() string
+	// Anchor submits the container's SHA-256 hash and saves the receipt.
+	Anchor(containerPath string) (*AnchorResult, error)
+	// Verify checks that the saved receipt matches the container.
+	Verify(containerPath string) (*VerifyResult, error)
+}
+
+// Backends lists the names NewAnchorer accepts, default first.
+var Backends = []string{"ots", "notary"}
+
+// NewAnchorer returns the backend called name: "ots" submits to the
+// OpenTimestamps calendars in servers (DefaultCalendars if none), at least
+// minServers of which must accept; "notary" submits to the notary URL in
+// servers, which is only needed to anchor, not to verify. target is
+// TargetFile or TargetManifest; empty means TargetFile.
+func NewAnchorer(name string, servers []string, minServers int, target string) (Anchorer, error) {
+	switch target {
+	case "", TargetFile, TargetManifest:
+	default:
+		return nil, fmt.Errorf("unknown anchor target %q (want %s or %s)", target, TargetFile, TargetManifest)
+	}
+	switch name {
+	case "", "ots":
+		return OpenTimestamps{Options: AnchorOptions{Calendars: servers, MinCalendars: minServers, Target: target}}, nil
+	case "notary":
+		n := &Notary{Target: target}
+		if len(servers) > 1 {
+			return nil, fmt.Errorf("the notary backend takes one server URL, got %d", len(servers))
+		}
+		if len(servers) == 1 {
+			if err := CheckCalendarURL(servers[0]); err != nil {
+				return nil, err
+			}
+			n.URL = servers[0]
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("unknown anchor backend %q (want one of %v)", name, Backends)
+}
+
+// OpenTimestamps anchors containers in Bitcoin through OpenTimestamps
+// calendars, saving a .ots proof beside each.
+type OpenTimestamps struct {
+	Options AnchorOptions
+}
+
+func (OpenTimestamps) Name() string { return "ots" }
+
+func (o OpenTimestamps) Anchor(containerPath string) (*AnchorResult, error) {
+	return AnchorContainerWithOptions(containerPath, o.Options)
+}
+
+func (OpenTimestamps) Verify(containerPath string) (*VerifyResult, error) {
+	return VerifyAnchor(containerPath)
+}
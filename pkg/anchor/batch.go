@@ -0,0 +1,154 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// nonceSize is the length of the random value mixed into each container's
+// commitment, so that a proof does not reveal the hashes of the containers
+// it was batched with.
+const nonceSize = 16
+
+// AnchorBatch anchors many containers with a single calendar submission.
+// Their commitments are hashed pairwise into a Merkle tree and only the
+// root is submitted; each container's .ots proof holds the path from its
+// own hash to the root, followed by the calendars' timestamps of the root.
+// The proofs are ordinary OpenTimestamps proofs, upgraded and embedded one
+// by one like any other.
+//
+// Nothing is submitted unless every container can be read. Each container
+// anchored is recorded in the anchor log (see ReadLog).
+func AnchorBatch(containerPaths []string, opts AnchorOptions) ([]*AnchorResult, error) {
+	if len(containerPaths) == 0 {
+		return nil, fmt.Errorf("no containers to anchor")
+	}
+	target := opts.Target
+	if target == "" {
+		target = TargetFile
+	}
+	if target != TargetFile && target != TargetManifest {
+		return nil, fmt.Errorf("unknown anchor target %q (want %s or %s)", target, TargetFile, TargetManifest)
+	}
+	seen := map[string]bool{}
+	roots := make([]*Timestamp, len(containerPaths))
+	leaves := make([]*Timestamp, len(containerPaths))
+	hashes := make([][]byte, len(containerPaths))
+	for i, path := range containerPaths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		if seen[abs] {
+			return nil, fmt.Errorf("%s is listed twice", path)
+		}
+		seen[abs] = true
+
+		if roots[i], leaves[i], hashes[i], err = commit(path, target); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	top := merkleTree(leaves)
+	stamp, used, err := submit(top.Msg, opts)
+	if err != nil {
+		return nil, err
+	}
+	top.merge(stamp)
+
+	now := time.Now()
+	results := make([]*AnchorResult, len(containerPaths))
+	for i, path := range containerPaths {
+		// Save the proof receipt alongside the container.
+		// e.g., "archive.imf" → "archive.imf.ots"
+		proof := &Proof{Digest: roots[i].Msg, Timestamp: roots[i]}
+		proofPath := path + ".ots"
+		if err := os.WriteFile(proofPath, proof.Marshal(), 0644); err != nil {
+			return nil, fmt.Errorf("saving proof: %w", err)
+		}
+		results[i] = &AnchorResult{
+			ContainerHash: hex.EncodeToString(hashes[i]),
+			Target:        target,
+			Digest:        hex.EncodeToString(roots[i].Msg),
+			ProofPath:     proofPath,
+			Server:        used[0],
+			Servers:       used,
+			MerkleRoot:    hex.EncodeToString(top.Msg),
+			Timestamp:     now,
+		}
+		logAnchor(path, "ots", results[i])
+	}
+	return results, nil
+}
+
+// commit returns the timestamp of what target names in a container, the
+// container's file hash, and, at the end of the timestamp's single chain
+// of operations, the leaf to be timestamped. For TargetFile the chain joins
+// the file hash with the manifest hash (see ManifestProof) and hashes it;
+// for TargetManifest it starts from the manifest hash. Either way it ends
+// by hashing in a random nonce.
+func commit(containerPath, target string) (root, leaf *Timestamp, fileHash []byte, err error) {
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("reading container: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	mHash, _, err := manifestDigest(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, nil, err
+	}
+
+	root = &Timestamp{Msg: hash[:]}
+	ops := []Op{{Tag: OpAppend, Arg: mHash}, {Tag: OpSHA256}, {Tag: OpAppend, Arg: nonce}, {Tag: OpSHA256}}
+	if target == TargetManifest {
+		root = &Timestamp{Msg: mHash}
+		ops = ops[2:]
+	}
+	leaf = root
+	for _, op := range ops {
+		msg, err := op.Apply(leaf.Msg)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		next := &Timestamp{Msg: msg}
+		leaf.Ops = []Branch{{Op: op, Timestamp: next}}
+		leaf = next
+	}
+	return root, leaf, hash[:], nil
+}
+
+// merkleTree joins leaves pairwise with SHA-256 until one remains, hanging
+// the path to the root off every leaf, and returns the root. An odd leaf
+// out is carried up to the next level unchanged.
+func merkleTree(leaves []*Timestamp) *Timestamp {
+	for len(leaves) > 1 {
+		var next []*Timestamp
+		for i := 0; i+1 < len(leaves); i += 2 {
+			l, r := leaves[i], leaves[i+1]
+			joined := append(append([]byte{}, l.Msg...), r.Msg...)
+			sum := sha256.Sum256(joined)
+			parent := &Timestamp{Msg: sum[:]}
+			hash := Branch{Op: Op{Tag: OpSHA256}, Timestamp: parent}
+			l.Ops = append(l.Ops, Branch{Op: Op{Tag: OpAppend, Arg: r.Msg}, Timestamp: &Timestamp{Msg: joined, Ops: []Branch{hash}}})
+			r.Ops = append(r.Ops, Branch{Op: Op{Tag: OpPrepend, Arg: l.Msg}, Timestamp: &Timestamp{Msg: joined, Ops: []Branch{hash}}})
+			next = append(next, parent)
+		}
+		if len(leaves)%2 == 1 {
+			next = append(next, leaves[len(leaves)-1])
+		}
+		leaves = next
+	}
+	return leaves[0]
+}
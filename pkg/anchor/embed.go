@@ -0,0 +1,175 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// A proof over the container file stops matching as soon as the file is
+// rewritten, so it has to travel as a loose .ots file. To let it move into
+// the container, AnchorContainer timestamps the hashes of both the file and
+// the manifest's signable bytes together:
+//
+//	commitment = SHA-256(file hash || manifest hash)
+//
+// The loose proof starts from the file hash; ManifestProof turns it into
+// the same proof starting from the manifest hash, which stays valid when
+// the proof is stored in the manifest's (unsigned) anchor field. A proof
+// made with TargetManifest starts from the manifest hash already.
+
+var errMismatch = errors.New("proof does not match container — container may have been modified after anchoring")
+
+// ManifestProof converts the confirmed .ots proof of a container into a
+// proof over the SHA-256 of its manifest's signable bytes, ready to be
+// embedded in the container with container.EmbedAnchor. It fails if the
+// proof is still pending or was made before proofs committed to the
+// manifest. A proof of TargetManifest is returned as it is.
+func ManifestProof(containerPath string) (*Proof, error) {
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	fileHash := sha256.Sum256(data)
+	mHash, _, err := manifestDigest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(containerPath + ".ots")
+	if err != nil {
+		return nil, fmt.Errorf("reading proof file: %w", err)
+	}
+	proof, err := readProof(raw, fileHash[:])
+	if err != nil {
+		return nil, err
+	}
+	target, err := matchDigest(data, proof.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if !proof.Timestamp.complete() {
+		return nil, errors.New("proof is still pending — run imf anchor -upgrade once the calendar has committed to Bitcoin")
+	}
+	if target == TargetManifest {
+		return proof, nil
+	}
+
+	// Find the commitment: the file hash with the manifest hash appended,
+	// then hashed.
+	var commitment *Timestamp
+	for _, b := range proof.Timestamp.Ops {
+		if b.Op.Tag != OpAppend || !bytes.Equal(b.Op.Arg, mHash) {
+			continue
+		}
+		for _, b2 := range b.Timestamp.Ops {
+			if b2.Op.Tag == OpSHA256 && b2.Timestamp.complete() {
+				commitment = b2.Timestamp
+			}
+		}
+	}
+	if commitment == nil {
+		return nil, errors.New("proof does not commit to the manifest — it was made by an older version of imf; anchor the container again to embed its proof")
+	}
+
+	joined := &Timestamp{Msg: append(append([]byte{}, fileHash[:]...), mHash...)}
+	joined.Ops = []Branch{{Op: Op{Tag: OpSHA256}, Timestamp: commitment}}
+	root := &Timestamp{Msg: mHash}
+	root.Ops = []Branch{{Op: Op{Tag: OpPrepend, Arg: fileHash[:]}, Timestamp: joined}}
+	return &Proof{Digest: mHash, Timestamp: root}, nil
+}
+
+// Blocks returns the heights of the Bitcoin blocks the proof attests to.
+// It does not check them against the blockchain.
+func (p *Proof) Blocks() []uint64 {
+	var heights []uint64
+	seen := map[uint64]bool{}
+	p.Timestamp.Walk(func(n *Timestamp) {
+		for _, a := range n.Attestations {
+			if h, ok := a.BitcoinHeight(); ok && !seen[h] {
+				seen[h] = true
+				heights = append(heights, h)
+			}
+		}
+	})
+	return heights
+}
+
+// matchDigest reports what digest is the SHA-256 of in container data:
+// the file itself (TargetFile) or the manifest's signable bytes
+// (TargetManifest).
+func matchDigest(data, digest []byte) (string, error) {
+	fileHash := sha256.Sum256(data)
+	if bytes.Equal(digest, fileHash[:]) {
+		return TargetFile, nil
+	}
+	if mHash, _, err := manifestDigest(data); err == nil && bytes.Equal(digest, mHash) {
+		return TargetManifest, nil
+	}
+	return "", errMismatch
+}
+
+// manifestDigest returns the SHA-256 of the signable bytes of the manifest
+// in container data, and the base64 proof embedded in it, if any.
+func manifestDigest(data []byte) ([]byte, string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, "", fmt.Errorf("opening zip: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "manifest.json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		mData, err := io.ReadAll(io.LimitReader(rc, 64<<20))
+		rc.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("reading manifest: %w", err)
+		}
+		m, err := manifest.Unmarshal(mData)
+		if err != nil {
+			return nil, "", err
+		}
+		signable, err := m.SignableBytes()
+		if err != nil {
+			return nil, "", fmt.Errorf("computing signable bytes: %w", err)
+		}
+		sum := sha256.Sum256(signable)
+		return sum[:], m.Anchor, nil
+	}
+	return nil, "", errors.New("container has no manifest")
+}
+
+// embeddedProof decodes the proof embedded in container data, if any, and
+// checks that it is over the manifest's signable bytes.
+func embeddedProof(data []byte) (*Proof, error) {
+	digest, enc, err := manifestDigest(data)
+	if err != nil || enc == "" {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, fmt.Errorf("decoding embedded proof: %w", err)
+	}
+	p, err := ParseProof(raw)
+	if err != nil {
+		return nil, fmt.Errorf("embedded proof: %w", err)
+	}
+	if !bytes.Equal(p.Digest, digest) {
+		return nil, errors.New("embedded proof does not match the manifest")
+	}
+	return p, nil
+}
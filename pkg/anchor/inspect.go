@@ -0,0 +1,81 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadProof reads a container's proof from its .ots file or, without one,
+// from its manifest, returning it with where it was found. It returns a nil
+// proof if the container has none. Unlike VerifyAnchor it does not check
+// that the proof matches the container, so a stale proof can be inspected.
+func LoadProof(containerPath string) (*Proof, string, error) {
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading container: %w", err)
+	}
+	proofPath := containerPath + ".ots"
+	raw, err := os.ReadFile(proofPath)
+	if errors.Is(err, os.ErrNotExist) {
+		p, err := embeddedProof(data)
+		if err != nil || p == nil {
+			return nil, "", err
+		}
+		return p, containerPath, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("reading proof file: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	p, err := readProof(raw, hash[:])
+	if err != nil {
+		return nil, "", err
+	}
+	return p, proofPath, nil
+}
+
+// Format renders the timestamp as an indented tree, in the manner of the
+// OpenTimestamps client's "ots info": each operation on its own line, a
+// fork as one "->" line per branch with the branch indented below it, and
+// each attestation as "verify ..." followed by the message it attests.
+func (t *Timestamp) Format() string {
+	var b strings.Builder
+	t.format(&b, "")
+	return b.String()
+}
+
+func (t *Timestamp) format(b *strings.Builder, indent string) {
+	for _, a := range t.Attestations {
+		fmt.Fprintf(b, "%sverify %s\n", indent, a)
+		fmt.Fprintf(b, "%s  on %s\n", indent, hex.EncodeToString(t.Msg))
+	}
+	for _, br := range t.Ops {
+		if len(t.Ops) == 1 && len(t.Attestations) == 0 {
+			fmt.Fprintf(b, "%s%s\n", indent, br.Op)
+			br.Timestamp.format(b, indent)
+			continue
+		}
+		fmt.Fprintf(b, "%s -> %s\n", indent, br.Op)
+		br.Timestamp.format(b, indent+"    ")
+	}
+}
+
+// Calendars returns the URLs of the calendars the proof is still waiting on.
+func (p *Proof) Calendars() []string {
+	var uris []string
+	p.Timestamp.Walk(func(n *Timestamp) {
+		for _, a := range n.Attestations {
+			if uri, ok := a.URI(); ok {
+				uris = append(uris, uri)
+			}
+		}
+	})
+	return uris
+}
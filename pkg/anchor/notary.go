@@ -0,0 +1,161 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Notary anchors containers with a generic HTTP notary: a service that
+// accepts a hash and returns a receipt for it, such as a company's internal
+// timestamping service or an API in front of a smart contract. The hash is
+// POSTed to URL as
+//
+//	{"hash": "<hex SHA-256 of the container>", "algorithm": "sha256"}
+//
+// (with TargetManifest, the SHA-256 of the manifest's signable bytes)
+// and any 200 or 201 response body is kept, as returned, in a receipt file
+// <container>.receipt. imf checks only that the receipt is for the
+// container; what the receipt proves is up to the notary.
+type Notary struct {
+	URL    string
+	Token  string // sent as a bearer token, if set
+	Target string // TargetFile (the default) or TargetManifest
+}
+
+// NotaryReceipt is the content of a .receipt file.
+type NotaryReceipt struct {
+	Backend     string    `json:"backend"` // always "notary"
+	URL         string    `json:"url"`
+	Hash        string    `json:"hash"`             // hex digest submitted
+	Target      string    `json:"target,omitempty"` // what Hash is of; empty means TargetFile
+	Algorithm   string    `json:"algorithm"`
+	Submitted   time.Time `json:"submitted"`
+	ContentType string    `json:"content_type,omitempty"`
+	Receipt     []byte    `json:"receipt"` // the notary's response body
+}
+
+func (*Notary) Name() string { return "notary" }
+
+// Anchor submits the container's hash to the notary and saves its receipt.
+func (n *Notary) Anchor(containerPath string) (*AnchorResult, error) {
+	if n.URL == "" {
+		return nil, errors.New("no notary URL configured")
+	}
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	target, digest := TargetFile, hash[:]
+	if n.Target == TargetManifest {
+		target = TargetManifest
+		if digest, _, err = manifestDigest(data); err != nil {
+			return nil, err
+		}
+	}
+	digestHex := hex.EncodeToString(digest)
+
+	body, _ := json.Marshal(map[string]string{"hash": digestHex, "algorithm": "sha256"})
+	req, err := http.NewRequest("POST", n.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+	client := httpClient(15 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", n.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("notary %s returned status %d", n.URL, resp.StatusCode)
+	}
+	receipt, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if len(receipt) == 0 {
+		return nil, errors.New("empty receipt received")
+	}
+
+	now := time.Now()
+	out, err := json.MarshalIndent(NotaryReceipt{
+		Backend:     "notary",
+		URL:         n.URL,
+		Hash:        digestHex,
+		Target:      target,
+		Algorithm:   "sha256",
+		Submitted:   now.UTC(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Receipt:     receipt,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	receiptPath := containerPath + ".receipt"
+	if err := os.WriteFile(receiptPath, out, 0644); err != nil {
+		return nil, fmt.Errorf("saving receipt: %w", err)
+	}
+	result := &AnchorResult{
+		ContainerHash: hex.EncodeToString(hash[:]),
+		Target:        target,
+		Digest:        digestHex,
+		ProofPath:     receiptPath,
+		Server:        n.URL,
+		Servers:       []string{n.URL},
+		MerkleRoot:    digestHex,
+		Timestamp:     now,
+	}
+	logAnchor(containerPath, n.Name(), result)
+	return result, nil
+}
+
+// Verify checks that the container's .receipt file was issued for its
+// current hash, or its manifest's for a receipt of TargetManifest. It does
+// not contact the notary.
+func (*Notary) Verify(containerPath string) (*VerifyResult, error) {
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	hash := sha256.Sum256(data)
+
+	receiptPath := containerPath + ".receipt"
+	raw, err := os.ReadFile(receiptPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading receipt file: %w", err)
+	}
+	var r NotaryReceipt
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, fmt.Errorf("parsing receipt file: %w", err)
+	}
+	if r.Backend != "notary" || r.Algorithm != "sha256" {
+		return nil, fmt.Errorf("unsupported receipt (backend %q, algorithm %q)", r.Backend, r.Algorithm)
+	}
+	digest, _ := hex.DecodeString(r.Hash)
+	target, err := matchDigest(data, digest)
+	if err != nil || (target != r.Target && !(target == TargetFile && r.Target == "")) {
+		return nil, errors.New("receipt does not match container — container may have been modified after anchoring")
+	}
+	return &VerifyResult{
+		ContainerHash: hex.EncodeToString(hash[:]),
+		Target:        target,
+		ProofPath:     receiptPath,
+		ProofSize:     len(r.Receipt),
+		HashMatches:   true,
+	}, nil
+}
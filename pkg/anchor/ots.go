@@ -0,0 +1,480 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+// This file implements the OpenTimestamps proof format, as written by the
+// reference client (python-opentimestamps) and the ots CLI.
+//
+// A detached proof is a header, the hash of the timestamped file, and a
+// Timestamp: a tree of operations (append, prepend, hash, ...) that turns
+// the file hash into the messages that calendars and blockchains attest to.
+
+// otsMagic opens every detached .ots proof.
+var otsMagic = []byte("\x00OpenTimestamps\x00\x00Proof\x00\xbf\x89\xe2\xe8\x84\xe8\x92\x94")
+
+const (
+	otsVersion   = 1
+	maxMsgLength = 4096 // longest message or operand an operation may produce
+	maxDepth     = 256  // deepest timestamp tree accepted
+)
+
+// Operation tags.
+const (
+	OpSHA1      byte = 0x02
+	OpRIPEMD160 byte = 0x03
+	OpSHA256    byte = 0x08
+	OpKeccak256 byte = 0x67
+	OpAppend    byte = 0xf0
+	OpPrepend   byte = 0xf1
+	OpReverse   byte = 0xf2
+	OpHexlify   byte = 0xf3
+)
+
+// Attestation tags.
+var (
+	tagPending  = [8]byte{0x83, 0xdf, 0xe3, 0x0d, 0x2e, 0xf9, 0x0c, 0x8e}
+	tagBitcoin  = [8]byte{0x05, 0x88, 0x96, 0x0d, 0x73, 0xd7, 0x19, 0x01}
+	tagLitecoin = [8]byte{0x06, 0x86, 0x9a, 0x0d, 0x73, 0xd7, 0x1b, 0x45}
+)
+
+// Op is one step from a message to the next: a hash, or an append or
+// prepend of Arg.
+type Op struct {
+	Tag byte
+	Arg []byte // operand of OpAppend and OpPrepend
+}
+
+// Apply returns the result of the operation on msg.
+func (o Op) Apply(msg []byte) ([]byte, error) {
+	var out []byte
+	switch o.Tag {
+	case OpAppend:
+		out = append(append([]byte{}, msg...), o.Arg...)
+	case OpPrepend:
+		out = append(append([]byte{}, o.Arg...), msg...)
+	case OpReverse:
+		out = make([]byte, len(msg))
+		for i, b := range msg {
+			out[len(msg)-1-i] = b
+		}
+	case OpHexlify:
+		out = []byte(hex.EncodeToString(msg))
+	case OpSHA1:
+		h := sha1.Sum(msg)
+		out = h[:]
+	case OpRIPEMD160:
+		h := ripemd160.New()
+		h.Write(msg)
+		out = h.Sum(nil)
+	case OpSHA256:
+		h := sha256.Sum256(msg)
+		out = h[:]
+	case OpKeccak256:
+		h := sha3.NewLegacyKeccak256()
+		h.Write(msg)
+		out = h.Sum(nil)
+	default:
+		return nil, fmt.Errorf("unknown operation 0x%02x", o.Tag)
+	}
+	if len(out) > maxMsgLength {
+		return nil, errors.New("operation result too long")
+	}
+	return out, nil
+}
+
+// String returns the operation as the ots CLI prints it.
+func (o Op) String() string {
+	switch o.Tag {
+	case OpAppend:
+		return "append " + hex.EncodeToString(o.Arg)
+	case OpPrepend:
+		return "prepend " + hex.EncodeToString(o.Arg)
+	case OpReverse:
+		return "reverse"
+	case OpHexlify:
+		return "hexlify"
+	case OpSHA1:
+		return "sha1"
+	case OpRIPEMD160:
+		return "ripemd160"
+	case OpSHA256:
+		return "sha256"
+	case OpKeccak256:
+		return "keccak256"
+	}
+	return fmt.Sprintf("op 0x%02x", o.Tag)
+}
+
+// Attestation is a claim that a message existed at some time: a calendar's
+// promise to commit it (pending) or its inclusion in a block.
+type Attestation struct {
+	Tag     [8]byte
+	Payload []byte // serialized body, kept verbatim for unknown kinds
+}
+
+// PendingAttestation returns a calendar's promise to commit a message to
+// Bitcoin; the completed timestamp can later be fetched from uri.
+func PendingAttestation(uri string) Attestation {
+	return Attestation{Tag: tagPending, Payload: appendVarBytes(nil, []byte(uri))}
+}
+
+// BitcoinAttestation returns an attestation that a message is the merkle
+// root of the Bitcoin block at height.
+func BitcoinAttestation(height uint64) Attestation {
+	return Attestation{Tag: tagBitcoin, Payload: appendVarUint(nil, height)}
+}
+
+// URI returns the calendar of a pending attestation.
+func (a Attestation) URI() (string, bool) {
+	if a.Tag != tagPending {
+		return "", false
+	}
+	r := &otsReader{b: a.Payload}
+	uri, err := r.varBytes(1000)
+	if err != nil {
+		return "", false
+	}
+	return string(uri), true
+}
+
+// BitcoinHeight returns the block height of a Bitcoin attestation.
+func (a Attestation) BitcoinHeight() (uint64, bool) {
+	if a.Tag != tagBitcoin {
+		return 0, false
+	}
+	r := &otsReader{b: a.Payload}
+	h, err := r.varUint()
+	if err != nil {
+		return 0, false
+	}
+	return h, true
+}
+
+// String describes the attestation as the ots CLI does.
+func (a Attestation) String() string {
+	if uri, ok := a.URI(); ok {
+		return "pending attestation at " + uri
+	}
+	if h, ok := a.BitcoinHeight(); ok {
+		return fmt.Sprintf("Bitcoin block %d attestation", h)
+	}
+	if a.Tag == tagLitecoin {
+		r := &otsReader{b: a.Payload}
+		if h, err := r.varUint(); err == nil {
+			return fmt.Sprintf("Litecoin block %d attestation", h)
+		}
+	}
+	return "unknown attestation " + hex.EncodeToString(a.Tag[:])
+}
+
+// Branch is an operation applied to a timestamp's message and the
+// timestamp of the result.
+type Branch struct {
+	Op        Op
+	Timestamp *Timestamp
+}
+
+// Timestamp is a message with the attestations made directly on it and
+// the operations leading on to further messages.
+type Timestamp struct {
+	Msg          []byte
+	Attestations []Attestation
+	Ops          []Branch
+}
+
+// Walk calls fn for every timestamp in the tree, parents first.
+func (t *Timestamp) Walk(fn func(*Timestamp)) {
+	fn(t)
+	for _, b := range t.Ops {
+		b.Timestamp.Walk(fn)
+	}
+}
+
+// merge adds other's attestations and operations, which must start from
+// the same message, to t.
+func (t *Timestamp) merge(other *Timestamp) {
+	for _, a := range other.Attestations {
+		if !t.hasAttestation(a) {
+			t.Attestations = append(t.Attestations, a)
+		}
+	}
+	for _, ob := range other.Ops {
+		found := false
+		for _, b := range t.Ops {
+			if b.Op.Tag == ob.Op.Tag && bytes.Equal(b.Op.Arg, ob.Op.Arg) {
+				b.Timestamp.merge(ob.Timestamp)
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Ops = append(t.Ops, ob)
+		}
+	}
+}
+
+func (t *Timestamp) hasAttestation(a Attestation) bool {
+	for _, x := range t.Attestations {
+		if x.Tag == a.Tag && bytes.Equal(x.Payload, a.Payload) {
+			return true
+		}
+	}
+	return false
+}
+
+// complete reports whether the tree holds a Bitcoin attestation.
+func (t *Timestamp) complete() bool {
+	found := false
+	t.Walk(func(n *Timestamp) {
+		for _, a := range n.Attestations {
+			if _, ok := a.BitcoinHeight(); ok {
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// ParseTimestamp decodes a serialized timestamp, such as a calendar's
+// response, that starts from msg.
+func ParseTimestamp(data, msg []byte) (*Timestamp, error) {
+	r := &otsReader{b: data}
+	t, err := r.timestamp(msg, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.b) != 0 {
+		return nil, errors.New("trailing data after timestamp")
+	}
+	return t, nil
+}
+
+// Marshal serializes the timestamp.
+func (t *Timestamp) Marshal() []byte {
+	return t.appendTo(nil)
+}
+
+func (t *Timestamp) appendTo(b []byte) []byte {
+	n := len(t.Attestations) + len(t.Ops)
+	i := 0
+	// Every item but the last is preceded by 0xff.
+	for _, a := range t.Attestations {
+		if i++; i < n {
+			b = append(b, 0xff)
+		}
+		b = append(b, 0x00)
+		b = append(b, a.Tag[:]...)
+		b = appendVarBytes(b, a.Payload)
+	}
+	for _, br := range t.Ops {
+		if i++; i < n {
+			b = append(b, 0xff)
+		}
+		b = append(b, br.Op.Tag)
+		if br.Op.Tag == OpAppend || br.Op.Tag == OpPrepend {
+			b = appendVarBytes(b, br.Op.Arg)
+		}
+		b = br.Timestamp.appendTo(b)
+	}
+	return b
+}
+
+// Proof is a detached .ots proof for a file.
+type Proof struct {
+	Digest    []byte // SHA-256 hash of the file
+	Timestamp *Timestamp
+}
+
+// ParseProof decodes a detached .ots proof.
+func ParseProof(data []byte) (*Proof, error) {
+	if !bytes.HasPrefix(data, otsMagic) {
+		return nil, errors.New("not an OpenTimestamps proof")
+	}
+	r := &otsReader{b: data[len(otsMagic):]}
+	v, err := r.varUint()
+	if err != nil {
+		return nil, err
+	}
+	if v != otsVersion {
+		return nil, fmt.Errorf("unsupported OpenTimestamps proof version %d", v)
+	}
+	op, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if op != OpSHA256 {
+		return nil, fmt.Errorf("unsupported file hash 0x%02x, want SHA-256", op)
+	}
+	digest, err := r.bytes(sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	t, err := r.timestamp(digest, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.b) != 0 {
+		return nil, errors.New("trailing data after timestamp")
+	}
+	return &Proof{Digest: digest, Timestamp: t}, nil
+}
+
+// Marshal serializes the proof as a detached .ots file.
+func (p *Proof) Marshal() []byte {
+	b := append([]byte{}, otsMagic...)
+	b = appendVarUint(b, otsVersion)
+	b = append(b, OpSHA256)
+	b = append(b, p.Digest...)
+	return p.Timestamp.appendTo(b)
+}
+
+// readProof decodes the proof for a file with the given digest. Proofs
+// saved by earlier versions of imf hold just the calendar's timestamp,
+// without the header; they are accepted if they parse from digest.
+func readProof(data, digest []byte) (*Proof, error) {
+	if bytes.HasPrefix(data, otsMagic) {
+		return ParseProof(data)
+	}
+	t, err := ParseTimestamp(data, digest)
+	if err != nil {
+		return nil, fmt.Errorf("not an OpenTimestamps proof: %w", err)
+	}
+	return &Proof{Digest: digest, Timestamp: t}, nil
+}
+
+// otsReader consumes the serialized form.
+type otsReader struct {
+	b []byte
+}
+
+var errTruncated = errors.New("truncated OpenTimestamps data")
+
+func (r *otsReader) byte() (byte, error) {
+	if len(r.b) == 0 {
+		return 0, errTruncated
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c, nil
+}
+
+func (r *otsReader) bytes(n int) ([]byte, error) {
+	if len(r.b) < n {
+		return nil, errTruncated
+	}
+	out := r.b[:n:n]
+	r.b = r.b[n:]
+	return out, nil
+}
+
+// varUint reads a little-endian base-128 integer.
+func (r *otsReader) varUint() (uint64, error) {
+	var v uint64
+	for shift := 0; shift < 64; shift += 7 {
+		c, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, errors.New("varuint overflows 64 bits")
+}
+
+func (r *otsReader) varBytes(max int) ([]byte, error) {
+	n, err := r.varUint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(max) {
+		return nil, fmt.Errorf("length %d exceeds limit %d", n, max)
+	}
+	return r.bytes(int(n))
+}
+
+func (r *otsReader) timestamp(msg []byte, depth int) (*Timestamp, error) {
+	if depth > maxDepth {
+		return nil, errors.New("timestamp tree too deep")
+	}
+	t := &Timestamp{Msg: msg}
+	for {
+		tag, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		last := tag != 0xff
+		if !last {
+			if tag, err = r.byte(); err != nil {
+				return nil, err
+			}
+		}
+		if err := r.item(t, tag, depth); err != nil {
+			return nil, err
+		}
+		if last {
+			return t, nil
+		}
+	}
+}
+
+// item reads one attestation or operation of t, whose tag has been read.
+func (r *otsReader) item(t *Timestamp, tag byte, depth int) error {
+	if tag == 0x00 {
+		var a Attestation
+		b, err := r.bytes(len(a.Tag))
+		if err != nil {
+			return err
+		}
+		copy(a.Tag[:], b)
+		if a.Payload, err = r.varBytes(8192); err != nil {
+			return err
+		}
+		t.Attestations = append(t.Attestations, a)
+		return nil
+	}
+	op := Op{Tag: tag}
+	if tag == OpAppend || tag == OpPrepend {
+		arg, err := r.varBytes(maxMsgLength)
+		if err != nil {
+			return err
+		}
+		op.Arg = arg
+	}
+	next, err := op.Apply(t.Msg)
+	if err != nil {
+		return err
+	}
+	sub, err := r.timestamp(next, depth+1)
+	if err != nil {
+		return err
+	}
+	t.Ops = append(t.Ops, Branch{Op: op, Timestamp: sub})
+	return nil
+}
+
+func appendVarUint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendVarBytes(b, p []byte) []byte {
+	return append(appendVarUint(b, uint64(len(p))), p...)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AnchorPlan describes what anchoring a container would do, for a dry run:
+// the hash that would leave the machine, where to, and the files that
+// would be written.
+type AnchorPlan struct {
+	Backend       string
+	ContainerHash string   // SHA-256 hex digest of the .imf file
+	Target        string   // TargetFile or TargetManifest
+	Digest        string   // hex digest the proof would be over
+	Requests      []string // each network request, as "POST <url>"
+	MinAccepted   int      // how many of Requests must succeed
+	ProofPath     string   // the proof or receipt that would be written
+	Overwrites    bool     // whether ProofPath exists and would be replaced
+	LogPath       string   // the anchor log a record would be appended to, if any
+}
+
+// Plan returns what a.Anchor(containerPath) would do, without contacting
+// any server or writing anything. For OpenTimestamps, the calendars are
+// sent a hash of Digest and a random nonce rather than Digest itself.
+func Plan(containerPath string, a Anchorer) (*AnchorPlan, error) {
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	p := &AnchorPlan{Backend: a.Name(), ContainerHash: hex.EncodeToString(hash[:]), Target: TargetFile, Digest: hex.EncodeToString(hash[:])}
+
+	// An OpenTimestamps proof always commits to the manifest too.
+	var target string
+	commitsManifest := false
+	switch a := a.(type) {
+	case OpenTimestamps:
+		target = a.Options.Target
+		calendars := a.Options.Calendars
+		if len(calendars) == 0 {
+			calendars = DefaultCalendars
+		}
+		for _, c := range calendars {
+			p.Requests = append(p.Requests, "POST "+strings.TrimSuffix(c, "/")+"/digest")
+		}
+		p.MinAccepted = max(a.Options.MinCalendars, 1)
+		if p.MinAccepted > len(calendars) {
+			return nil, fmt.Errorf("need %d calendars to accept the submission, but only %d are configured", p.MinAccepted, len(calendars))
+		}
+		p.ProofPath = containerPath + ".ots"
+		commitsManifest = true
+	case *Notary:
+		if a.URL == "" {
+			return nil, errors.New("no notary URL configured")
+		}
+		target = a.Target
+		p.Requests = []string{"POST " + a.URL}
+		p.MinAccepted = 1
+		p.ProofPath = containerPath + ".receipt"
+	default:
+		return nil, fmt.Errorf("cannot plan anchoring with the %s backend", a.Name())
+	}
+
+	if commitsManifest || target == TargetManifest {
+		mHash, _, err := manifestDigest(data)
+		if err != nil {
+			return nil, err
+		}
+		if target == TargetManifest {
+			p.Target, p.Digest = TargetManifest, hex.EncodeToString(mHash)
+		}
+	}
+	if _, err := os.Stat(p.ProofPath); err == nil {
+		p.Overwrites = true
+	}
+	if path, err := DefaultLogFile(); err == nil {
+		p.LogPath = path
+	}
+	return p, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Every request the package makes, to calendars, block explorers, notaries
+// and webhooks, reveals the user's IP address and when they anchor. By
+// default requests follow HTTPS_PROXY, HTTP_PROXY and NO_PROXY like any Go
+// program, which accept socks5:// URLs too; SetProxy overrides them.
+
+var (
+	transportMu sync.RWMutex
+	transport   = newTransport(nil)
+)
+
+// SetProxy sends every request the package makes through the proxy at
+// rawURL: an http://, https:// or socks5:// URL, such as Tor's
+// socks5://127.0.0.1:9050. Through a SOCKS5 proxy host names are resolved
+// by the proxy, so DNS lookups do not leak either. An empty rawURL restores
+// the proxy settings of the environment.
+func SetProxy(rawURL string) error {
+	var proxy *url.URL
+	if rawURL != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q", rawURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q (want http, https or socks5)", u.Scheme)
+		}
+		proxy = u
+	}
+	t := newTransport(proxy)
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	transport.CloseIdleConnections()
+	transport = t
+	return nil
+}
+
+func newTransport(proxy *url.URL) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != nil {
+		t.Proxy = http.ProxyURL(proxy)
+	}
+	return t
+}
+
+// httpClient returns a client that goes through the configured proxy.
+func httpClient(timeout time.Duration) *http.Client {
+	transportMu.RLock()
+	defer transportMu.RUnlock()
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
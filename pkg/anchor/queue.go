@@ -0,0 +1,176 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// QueueEntry is a container waiting to be anchored, because the service
+// could not be reached when it was sealed.
+type QueueEntry struct {
+	Container  string    `json:"container"` // absolute path
+	Backend    string    `json:"backend"`
+	Servers    []string  `json:"servers,omitempty"` // empty for the backend's default
+	MinServers int       `json:"min_servers,omitempty"`
+	Target     string    `json:"target,omitempty"`
+	Queued     time.Time `json:"queued"`
+	Error      string    `json:"error"` // why the last attempt failed
+}
+
+// RetryResult is the outcome of retrying one queued container.
+type RetryResult struct {
+	Entry  QueueEntry
+	Result *AnchorResult // set once anchored
+	Err    error         // set if the attempt failed
+	// Dropped is set if the container no longer exists, and so has left
+	// the queue without being anchored.
+	Dropped bool
+}
+
+// DefaultQueueFile returns the anchor queue: $IMF_ANCHOR_QUEUE if set,
+// otherwise ~/.imf/anchor-queue.jsonl.
+func DefaultQueueFile() (string, error) {
+	if path := os.Getenv("IMF_ANCHOR_QUEUE"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
+	}
+	return filepath.Join(home, ".imf", "anchor-queue.jsonl"), nil
+}
+
+// Enqueue records that a container is to be anchored with a later
+// RetryQueued, after a failure to anchor it with a. A container already in
+// the queue is replaced.
+func Enqueue(containerPath string, a Anchorer, cause error) error {
+	abs, err := filepath.Abs(containerPath)
+	if err != nil {
+		return err
+	}
+	e := QueueEntry{Container: abs, Backend: a.Name(), Queued: time.Now().UTC()}
+	switch b := a.(type) {
+	case OpenTimestamps:
+		e.Servers, e.MinServers, e.Target = b.Options.Calendars, b.Options.MinCalendars, b.Options.Target
+	case *Notary:
+		e.Target = b.Target
+		if b.URL != "" {
+			e.Servers = []string{b.URL}
+		}
+	}
+	if cause != nil {
+		e.Error = cause.Error()
+	}
+
+	entries, err := Queued()
+	if err != nil {
+		return err
+	}
+	kept := entries[:0]
+	for _, q := range entries {
+		if q.Container != abs {
+			kept = append(kept, q)
+		}
+	}
+	return saveQueue(append(kept, e))
+}
+
+// Queued returns the containers waiting to be anchored, oldest first.
+func Queued() ([]QueueEntry, error) {
+	path, err := DefaultQueueFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading anchor queue: %w", err)
+	}
+	var entries []QueueEntry
+	for n, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e QueueEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// RetryQueued tries again to anchor every queued container. Those that are
+// anchored leave the queue, as do those that no longer exist; the others
+// stay, with the new error recorded. configure, if not nil, is called with
+// each backend before use, for settings not kept in the queue such as a
+// notary's token.
+func RetryQueued(configure func(Anchorer)) ([]RetryResult, error) {
+	entries, err := Queued()
+	if err != nil {
+		return nil, err
+	}
+	var results []RetryResult
+	var kept []QueueEntry
+	for _, e := range entries {
+		r := RetryResult{Entry: e}
+		a, err := NewAnchorer(e.Backend, e.Servers, e.MinServers, e.Target)
+		if err == nil {
+			if configure != nil {
+				configure(a)
+			}
+			r.Result, err = a.Anchor(e.Container)
+		}
+		r.Err = err
+		if err != nil {
+			if _, serr := os.Stat(e.Container); errors.Is(serr, os.ErrNotExist) {
+				r.Dropped = true
+			} else {
+				e.Error = err.Error()
+				kept = append(kept, e)
+			}
+		}
+		results = append(results, r)
+	}
+	return results, saveQueue(kept)
+}
+
+// saveQueue replaces the queue with entries, removing it if there are none.
+func saveQueue(entries []QueueEntry) error {
+	path, err := DefaultQueueFile()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing anchor queue: %w", err)
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating anchor queue directory: %w", err)
+	}
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("saving anchor queue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Record is one entry in the anchor log: a container anchored at a given
+// time. The log is append-only; a container anchored twice has two records.
+type Record struct {
+	Time       time.Time `json:"time"`
+	Container  string    `json:"container"` // absolute path
+	Hash       string    `json:"hash"`      // SHA-256 hex digest of the .imf file
+	Target     string    `json:"target"`    // TargetFile or TargetManifest
+	Digest     string    `json:"digest"`    // hex digest the proof is over
+	Backend    string    `json:"backend"`
+	Servers    []string  `json:"servers"`
+	Proof      string    `json:"proof"` // absolute path of the proof or receipt
+	MerkleRoot string    `json:"merkle_root,omitempty"`
+}
+
+// DefaultLogFile returns the anchor log: $IMF_ANCHOR_LOG if set, otherwise
+// ~/.imf/anchors.jsonl.
+func DefaultLogFile() (string, error) {
+	if path := os.Getenv("IMF_ANCHOR_LOG"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
+	}
+	return filepath.Join(home, ".imf", "anchors.jsonl"), nil
+}
+
+// ReadLog returns the records in the anchor log, oldest first.
+func ReadLog() ([]Record, error) {
+	path, err := DefaultLogFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading anchor log: %w", err)
+	}
+	var records []Record
+	for n, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n+1, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// logAnchor appends a record of result to the anchor log. The log is a
+// convenience, so a failure to write it does not fail the anchor.
+func logAnchor(containerPath, backend string, result *AnchorResult) {
+	path, err := DefaultLogFile()
+	if err != nil {
+		return
+	}
+	r := Record{
+		Time:       result.Timestamp.UTC(),
+		Container:  containerPath,
+		Hash:       result.ContainerHash,
+		Target:     result.Target,
+		Digest:     result.Digest,
+		Backend:    backend,
+		Servers:    result.Servers,
+		Proof:      result.ProofPath,
+		MerkleRoot: result.MerkleRoot,
+	}
+	if abs, err := filepath.Abs(containerPath); err == nil {
+		r.Container = abs
+	}
+	if abs, err := filepath.Abs(result.ProofPath); err == nil {
+		r.Proof = abs
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Anchor states reported by Status.
+const (
+	StatePending   = "pending"   // no calendar has committed to Bitcoin yet
+	StateConfirmed = "confirmed" // anchored in at least one Bitcoin block
+	StateMismatch  = "mismatch"  // the proof is for other contents
+)
+
+// StatusOptions configures StatusWithOptions.
+type StatusOptions struct {
+	// Explorer is the Esplora API used to look up the blocks a confirmed
+	// proof is anchored in. If empty, no lookup is made and only the block
+	// heights recorded in the proof are reported.
+	Explorer string
+}
+
+// StatusResult describes a container's anchor proof.
+type StatusResult struct {
+	State     string   // StatePending, StateConfirmed or StateMismatch
+	Hash      string   // SHA-256 hex digest the proof is over
+	Target    string   // What Hash is of: TargetFile or TargetManifest
+	ProofPath string   // Path to the .ots proof file, or the container if embedded
+	Embedded  bool     // Whether the proof is embedded in the container
+	Blocks    []Block  // Bitcoin blocks the container is anchored in
+	Pending   []string // Calendars that have not yet committed to Bitcoin
+}
+
+// Status reports the anchor proof of a container, from its .ots file or,
+// without one, from the proof embedded in its manifest. It reads only local
+// files, and returns nil if the container has no proof.
+func Status(containerPath string) (*StatusResult, error) {
+	return StatusWithOptions(containerPath, StatusOptions{})
+}
+
+// StatusWithOptions is Status, optionally looking up the blocks of a
+// confirmed proof.
+func StatusWithOptions(containerPath string, opts StatusOptions) (*StatusResult, error) {
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	hash := sha256.Sum256(data)
+
+	var proof *Proof
+	result := &StatusResult{ProofPath: containerPath + ".ots"}
+	raw, err := os.ReadFile(result.ProofPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		digest, enc, err := manifestDigest(data)
+		if err != nil || enc == "" {
+			return nil, err
+		}
+		result.ProofPath, result.Embedded, result.Target = containerPath, true, TargetManifest
+		if proof, err = embeddedProof(data); err != nil {
+			// Report the manifest it should have matched.
+			result.State, result.Hash = StateMismatch, hex.EncodeToString(digest)
+			return result, nil
+		}
+	case err != nil:
+		return nil, fmt.Errorf("reading proof file: %w", err)
+	case !bytes.HasPrefix(raw, otsMagic) && !bytes.Contains(raw, hash[:]):
+		// A proof from an earlier version, for other contents; its digest
+		// cannot be recovered.
+		result.State = StateMismatch
+		return result, nil
+	default:
+		if proof, err = readProof(raw, hash[:]); err != nil {
+			return nil, err
+		}
+		if result.Target, err = matchDigest(data, proof.Digest); err != nil {
+			result.State, result.Hash = StateMismatch, hex.EncodeToString(proof.Digest)
+			return result, nil
+		}
+	}
+	result.Hash = hex.EncodeToString(proof.Digest)
+
+	result.Pending = proof.Calendars()
+	if opts.Explorer != "" {
+		if result.Blocks, err = lookupBlocks(opts.Explorer, proof.Timestamp); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, h := range proof.Blocks() {
+			result.Blocks = append(result.Blocks, Block{Height: h})
+		}
+	}
+	result.State = StatePending
+	if len(result.Blocks) > 0 {
+		result.State = StateConfirmed
+	}
+	return result, nil
+}
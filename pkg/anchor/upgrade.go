@@ -0,0 +1,298 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultExplorer is the Esplora API used to look up the Bitcoin blocks a
+// proof is anchored in.
+const DefaultExplorer = "https://blockstream.info/api"
+
+// UpgradeOptions configures Upgrade.
+type UpgradeOptions struct {
+	Explorer string // Esplora API for block times; defaults to DefaultExplorer
+}
+
+// UpgradeResult reports the state of a proof after an upgrade.
+type UpgradeResult struct {
+	ContainerHash string   // SHA-256 hex digest of the .imf file
+	ProofPath     string   // Path to the .ots proof file
+	Upgraded      bool     // Whether the proof file was rewritten
+	Blocks        []Block  // Bitcoin blocks the container is anchored in
+	Pending       []string // Calendars that have not yet committed to Bitcoin
+	Embedded      bool     // Whether the proof is embedded in the container
+}
+
+// Complete reports whether the proof is anchored in at least one block.
+func (r *UpgradeResult) Complete() bool {
+	return len(r.Blocks) > 0
+}
+
+// Block is a Bitcoin block whose merkle root commits to the container.
+type Block struct {
+	Height uint64
+	Hash   string    // block hash; empty if the explorer was unreachable
+	Time   time.Time // block header time; zero if the explorer was unreachable
+}
+
+// Upgrade completes the pending attestations in a container's .ots proof.
+// Calendars commit the digests they receive to Bitcoin every few hours;
+// once they have, each calendar named in the proof returns the path from
+// the container's hash to the block's merkle root, and the proof file is
+// rewritten with it. Proofs that are still pending are left unchanged.
+func Upgrade(containerPath string) (*UpgradeResult, error) {
+	return UpgradeWithOptions(containerPath, UpgradeOptions{})
+}
+
+// UpgradeWithOptions is Upgrade with a choice of block explorer.
+func UpgradeWithOptions(containerPath string, opts UpgradeOptions) (*UpgradeResult, error) {
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	hash := sha256.Sum256(data)
+
+	explorer := opts.Explorer
+	if explorer == "" {
+		explorer = DefaultExplorer
+	}
+
+	proofPath := containerPath + ".ots"
+	raw, err := os.ReadFile(proofPath)
+	if errors.Is(err, os.ErrNotExist) {
+		// An embedded proof was complete when it was embedded; just
+		// report its blocks.
+		p, perr := embeddedProof(data)
+		if perr != nil {
+			return nil, perr
+		}
+		if p != nil {
+			result := &UpgradeResult{ContainerHash: hex.EncodeToString(hash[:]), ProofPath: containerPath, Embedded: true}
+			if result.Blocks, err = lookupBlocks(explorer, p.Timestamp); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading proof file: %w", err)
+	}
+	proof, err := readProof(raw, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := matchDigest(data, proof.Digest); err != nil {
+		return nil, err
+	}
+
+	result := &UpgradeResult{ContainerHash: hex.EncodeToString(hash[:]), ProofPath: proofPath}
+	// Proofs from earlier versions lack the header and are rewritten.
+	changed := !bytes.HasPrefix(raw, otsMagic)
+
+	type pending struct {
+		node *Timestamp
+		uri  string
+	}
+	var todo []pending
+	proof.Timestamp.Walk(func(n *Timestamp) {
+		for _, a := range n.Attestations {
+			if uri, ok := a.URI(); ok {
+				todo = append(todo, pending{n, uri})
+			}
+		}
+	})
+
+	var failures []error
+	for _, p := range todo {
+		upgraded, err := fetchTimestamp(p.uri, p.node.Msg)
+		if err != nil {
+			failures = append(failures, err)
+		}
+		if upgraded == nil {
+			result.Pending = append(result.Pending, p.uri)
+			continue
+		}
+		p.node.merge(upgraded)
+		if p.node.complete() {
+			p.node.dropAttestation(PendingAttestation(p.uri))
+		}
+		changed = true
+	}
+	if len(failures) > 0 && len(failures) == len(todo) {
+		return nil, fmt.Errorf("contacting calendars: %w", errors.Join(failures...))
+	}
+
+	if changed {
+		if err := writeFileAtomic(proofPath, proof.Marshal()); err != nil {
+			return nil, fmt.Errorf("saving proof: %w", err)
+		}
+		result.Upgraded = true
+	}
+
+	if result.Blocks, err = lookupBlocks(explorer, proof.Timestamp); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lookupBlocks looks up every Bitcoin block t attests to. A block the
+// explorer cannot be reached for is returned without its time.
+func lookupBlocks(explorer string, t *Timestamp) ([]Block, error) {
+	var blocks []Block
+	seen := map[uint64]bool{}
+	var blockErr error
+	t.Walk(func(n *Timestamp) {
+		for _, a := range n.Attestations {
+			height, ok := a.BitcoinHeight()
+			if !ok || seen[height] {
+				continue
+			}
+			seen[height] = true
+			b, err := lookupBlock(explorer, height, n.Msg)
+			if err != nil && blockErr == nil && !isUnreachable(err) {
+				blockErr = err
+			}
+			blocks = append(blocks, b)
+		}
+	})
+	return blocks, blockErr
+}
+
+func (t *Timestamp) dropAttestation(a Attestation) {
+	kept := t.Attestations[:0]
+	for _, x := range t.Attestations {
+		if x.Tag != a.Tag || !bytes.Equal(x.Payload, a.Payload) {
+			kept = append(kept, x)
+		}
+	}
+	t.Attestations = kept
+}
+
+// fetchTimestamp asks a calendar for its timestamp of msg. It returns nil
+// and no error while the commitment is still pending.
+func fetchTimestamp(calendar string, msg []byte) (*Timestamp, error) {
+	if err := CheckCalendarURL(calendar); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", strings.TrimSuffix(calendar, "/")+"/timestamp/"+hex.EncodeToString(msg), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.opentimestamps.v1")
+
+	client := httpClient(15 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", calendar, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar %s returned status %d", calendar, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	t, err := ParseTimestamp(body, msg)
+	if err != nil {
+		return nil, fmt.Errorf("calendar %s: %w", calendar, err)
+	}
+	return t, nil
+}
+
+// unreachableError marks a failure to reach the block explorer, which is
+// reported as a block with unknown time rather than an error.
+type unreachableError struct{ err error }
+
+func (e *unreachableError) Error() string { return e.err.Error() }
+
+func isUnreachable(err error) bool {
+	var u *unreachableError
+	return errors.As(err, &u)
+}
+
+// lookupBlock fetches the Bitcoin block at height from an Esplora API and
+// checks that its merkle root is root, the message the attestation was
+// made on.
+func lookupBlock(explorer string, height uint64, root []byte) (Block, error) {
+	b := Block{Height: height}
+	client := httpClient(15 * time.Second)
+	get := func(path string) ([]byte, error) {
+		resp, err := client.Get(strings.TrimSuffix(explorer, "/") + path)
+		if err != nil {
+			return nil, &unreachableError{err}
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, &unreachableError{fmt.Errorf("explorer returned status %d", resp.StatusCode)}
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	}
+
+	hash, err := get("/block-height/" + strconv.FormatUint(height, 10))
+	if err != nil {
+		return b, err
+	}
+	body, err := get("/block/" + strings.TrimSpace(string(hash)))
+	if err != nil {
+		return b, err
+	}
+	var header struct {
+		ID         string `json:"id"`
+		Timestamp  int64  `json:"timestamp"`
+		MerkleRoot string `json:"merkle_root"`
+	}
+	if err := json.Unmarshal(body, &header); err != nil {
+		return b, &unreachableError{fmt.Errorf("decoding block %d: %w", height, err)}
+	}
+	// Explorers show the merkle root byte-reversed, as Bitcoin hashes are.
+	want, _ := Op{Tag: OpReverse}.Apply(root)
+	if header.MerkleRoot != hex.EncodeToString(want) {
+		return b, fmt.Errorf("proof does not match Bitcoin block %d: merkle root %s", height, header.MerkleRoot)
+	}
+	b.Hash = header.ID
+	b.Time = time.Unix(header.Timestamp, 0).UTC()
+	return b, nil
+}
+
+// writeFileAtomic replaces path with data so that an interrupted write
+// never leaves a truncated proof.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
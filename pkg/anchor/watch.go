@@ -0,0 +1,191 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultWatchInterval is how often Watch polls pending proofs. Calendars
+// commit to Bitcoin every few hours, so polling more often gains little.
+const DefaultWatchInterval = 10 * time.Minute
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	Interval time.Duration // time between polls; defaults to DefaultWatchInterval
+	Upgrade  UpgradeOptions
+
+	// OnConfirm is called once for each proof that becomes complete.
+	OnConfirm func(containerPath string, r *UpgradeResult)
+	// OnError is called when a proof cannot be checked. It is tried again
+	// at the next poll.
+	OnError func(containerPath string, err error)
+}
+
+// Watch polls the pending .ots proofs of the containers in paths and
+// upgrades each one as soon as its calendar has committed to Bitcoin.
+// A directory in paths stands for the anchored .imf containers in it and
+// is rescanned at every poll, so containers anchored later are picked up.
+//
+// Watch runs until ctx is cancelled or, when paths names only containers,
+// until all their proofs are complete.
+func Watch(ctx context.Context, paths []string, opts WatchOptions) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	onError := opts.OnError
+	if onError == nil {
+		onError = func(string, error) {}
+	}
+
+	done := map[string]bool{}
+	for {
+		scanned, hasDirs := watchTargets(paths)
+		remaining := 0
+		for _, path := range scanned {
+			if done[path] {
+				continue
+			}
+			pending, err := proofPending(path)
+			if errors.Is(err, os.ErrNotExist) {
+				// A container named explicitly was never anchored.
+				onError(path, err)
+				done[path] = true
+				continue
+			}
+			if err != nil {
+				onError(path, err)
+				remaining++
+				continue
+			}
+			if !pending {
+				// Complete before the watch began; nothing to report.
+				done[path] = true
+				continue
+			}
+			r, err := UpgradeWithOptions(path, opts.Upgrade)
+			if err != nil {
+				onError(path, err)
+				remaining++
+				continue
+			}
+			if !r.Complete() {
+				remaining++
+				continue
+			}
+			done[path] = true
+			if opts.OnConfirm != nil {
+				opts.OnConfirm(path, r)
+			}
+		}
+		if !hasDirs && remaining == 0 {
+			return nil
+		}
+
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// watchTargets expands paths to the containers to poll: each container
+// named, and each .imf file with a .ots proof in each directory named.
+func watchTargets(paths []string) (targets []string, hasDirs bool) {
+	for _, path := range paths {
+		st, err := os.Stat(path)
+		if err != nil || !st.IsDir() {
+			targets = append(targets, path)
+			continue
+		}
+		hasDirs = true
+		matches, _ := filepath.Glob(filepath.Join(path, "*.imf"))
+		for _, m := range matches {
+			if _, err := os.Stat(m + ".ots"); err == nil {
+				targets = append(targets, m)
+			}
+		}
+	}
+	return targets, hasDirs
+}
+
+// proofPending reports whether the container's .ots proof has yet to
+// reach a Bitcoin block. It reads only local files. An embedded proof was
+// complete when it was embedded.
+func proofPending(containerPath string) (bool, error) {
+	data, err := os.ReadFile(containerPath)
+	if err != nil {
+		return false, fmt.Errorf("reading container: %w", err)
+	}
+	raw, err := os.ReadFile(containerPath + ".ots")
+	if errors.Is(err, os.ErrNotExist) {
+		if p, perr := embeddedProof(data); perr == nil && p != nil {
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading proof file: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	proof, err := readProof(raw, hash[:])
+	if err != nil {
+		return false, err
+	}
+	return !proof.Timestamp.complete(), nil
+}
+
+// PostWebhook tells url that a container's proof has been confirmed, by
+// POSTing a JSON event:
+//
+//	{"event": "anchor.confirmed", "container": "...", "container_hash": "...",
+//	 "proof": "...", "blocks": [{"height": 850000, "hash": "...", "time": "..."}]}
+func PostWebhook(url, containerPath string, r *UpgradeResult) error {
+	type block struct {
+		Height uint64     `json:"height"`
+		Hash   string     `json:"hash,omitempty"`
+		Time   *time.Time `json:"time,omitempty"`
+	}
+	event := struct {
+		Event         string  `json:"event"`
+		Container     string  `json:"container"`
+		ContainerHash string  `json:"container_hash"`
+		Proof         string  `json:"proof"`
+		Blocks        []block `json:"blocks"`
+	}{Event: "anchor.confirmed", Container: containerPath, ContainerHash: r.ContainerHash, Proof: r.ProofPath}
+	for _, b := range r.Blocks {
+		eb := block{Height: b.Height, Hash: b.Hash}
+		if !b.Time.IsZero() {
+			t := b.Time
+			eb.Time = &t
+		}
+		event.Blocks = append(event.Blocks, eb)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	client := httpClient(15 * time.Second)
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("calling webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %s returned status %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package config reads imf's defaults: the config file, ~/.imf/config, and
+// the environment variables that override it. Command-line flags in turn
+// override both, so the order of precedence is flag, then environment, then
+// config file, then imf's built-in default.
+//
+// The config file is TOML, one "key = value" per line:
+//
+//	key = "~/.imf/imf_private.pem"
+//	output_dir = "~/Extracted"
+//	calendars = ["https://a.pool.opentimestamps.org", "https://b.pool.opentimestamps.org"]
+//	kdf_iterations = 1_000_000
+//	gui_port = 8765
+//	gui_dir = "~/Documents/IMF"
+//	gui_preview = false
+//	gui_max_upload_mb = 16_384
+//	lang = "de"
+//
+// Only this subset of TOML is read: strings, integers, booleans, and arrays
+// of strings, without tables. A key imf does not know is an error, so that a
+// typo is not silently ignored.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Config holds the defaults. A zero field means none was set.
+type Config struct {
+	Key           string   // signing key: a path, keychain:NAME, hw:[NAME], pkcs11:[LABEL], or keyring name ($IMF_KEY)
+	OutputDir     string   // directory imf extract writes to ($IMF_OUTPUT_DIR)
+	Calendars     []string // OpenTimestamps calendars to anchor with
+	KDFIterations int      // PBKDF2 iterations for new passphrase-encrypted containers ($IMF_KDF_ITERATIONS)
+	GUIPort       int      // port the GUI listens on ($IMF_GUI_PORT)
+	GUIDir        string   // directory the GUI creates and opens containers in ($IMF_GUI_DIR)
+	GUIPreview    *bool    // whether the GUI extracts a sealed container to preview its files; nil means it does
+	GUIMaxUpload  int      // largest file the GUI accepts, in MiB ($IMF_GUI_MAX_UPLOAD_MB)
+	Lang          string   // language of messages, such as "de"; empty follows the locale ($IMF_LANG)
+}
+
+// keys lists the settings in the order SaveFile writes new ones.
+var keys = []string{"key", "output_dir", "calendars", "kdf_iterations", "gui_port", "gui_dir", "gui_preview", "gui_max_upload_mb", "lang"}
+
+// DefaultFile returns the config file: $IMF_CONFIG if set, otherwise
+// ~/.imf/config.
+func DefaultFile() (string, error) {
+	if path := os.Getenv("IMF_CONFIG"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
+	}
+	return filepath.Join(home, ".imf", "config"), nil
+}
+
+// Load returns the defaults from DefaultFile, overridden by any set in the
+// environment. A missing config file is not an error.
+func Load() (*Config, error) {
+	path, err := DefaultFile()
+	if err != nil {
+		return nil, err
+	}
+	c, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFile reads the config file at path, returning an empty Config if
+// there is none.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse parses a config file. A leading "~/" in a path is expanded to the
+// home directory.
+func Parse(data []byte) (*Config, error) {
+	c := &Config{}
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		n := i + 1
+		line := strings.TrimSpace(stripComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", n)
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		// An array may continue over several lines.
+		for strings.HasPrefix(value, "[") && !strings.HasSuffix(value, "]") && i+1 < len(lines) {
+			i++
+			value += " " + strings.TrimSpace(stripComment(lines[i]))
+		}
+		if err := c.set(key, value); err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n, key, err)
+		}
+	}
+	return c, nil
+}
+
+func (c *Config) set(key, value string) error {
+	var err error
+	switch key {
+	case "key":
+		if c.Key, err = parseString(value); err == nil {
+			c.Key, err = expandHome(c.Key)
+		}
+	case "output_dir":
+		if c.OutputDir, err = parseString(value); err == nil {
+			c.OutputDir, err = expandHome(c.OutputDir)
+		}
+	case "calendars":
+		c.Calendars, err = parseStrings(value)
+	case "kdf_iterations":
+		c.KDFIterations, err = parseInt(value)
+	case "gui_port":
+		c.GUIPort, err = parseInt(value)
+	case "gui_dir":
+		if c.GUIDir, err = parseString(value); err == nil {
+			c.GUIDir, err = expandHome(c.GUIDir)
+		}
+	case "gui_preview":
+		var b bool
+		b, err = parseBool(value)
+		c.GUIPreview = &b
+	case "gui_max_upload_mb":
+		c.GUIMaxUpload, err = parseInt(value)
+	case "lang":
+		c.Lang, err = parseString(value)
+	default:
+		return errors.New("unknown setting")
+	}
+	return err
+}
+
+// applyEnv overrides c with the settings given in the environment.
+func (c *Config) applyEnv() error {
+	if v := os.Getenv("IMF_KEY"); v != "" {
+		c.Key = v
+	}
+	if v := os.Getenv("IMF_OUTPUT_DIR"); v != "" {
+		c.OutputDir = v
+	}
+	if v := os.Getenv("IMF_GUI_DIR"); v != "" {
+		c.GUIDir = v
+	}
+	if v := os.Getenv("IMF_LANG"); v != "" {
+		c.Lang = v
+	}
+	for env, field := range map[string]*int{"IMF_KDF_ITERATIONS": &c.KDFIterations, "IMF_GUI_PORT": &c.GUIPort, "IMF_GUI_MAX_UPLOAD_MB": &c.GUIMaxUpload} {
+		if v := os.Getenv(env); v != "" {
+			n, err := parseInt(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+			*field = n
+		}
+	}
+	return nil
+}
+
+// stripComment removes a '#' comment that is not inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == '"' && c == '\\':
+			i++ // an escaped character cannot end the string
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseString(value string) (string, error) {
+	switch {
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		return strconv.Unquote(value)
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1], nil
+	}
+	return "", fmt.Errorf("expected a quoted string, got %s", value)
+}
+
+func parseStrings(value string) ([]string, error) {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("expected an array of strings, got %s", value)
+	}
+	var out []string
+	for _, item := range strings.Split(value[1:len(value)-1], ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue // a trailing comma
+		}
+		s, err := parseString(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected true or false, got %s", value)
+}
+
+func parseInt(value string) (int, error) {
+	n, err := strconv.Atoi(strings.ReplaceAll(value, "_", ""))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a non-negative integer, got %s", value)
+	}
+	return n, nil
+}
+
+// expandHome expands a leading "~/" in path to the home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
+	}
+	return filepath.Join(home, path[2:]), nil
+}
+
+// value formats the setting key of c as a config file value, or returns ""
+// if c leaves it unset.
+func (c *Config) value(key string) string {
+	str := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return strconv.Quote(s)
+	}
+	num := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	switch key {
+	case "key":
+		return str(c.Key)
+	case "output_dir":
+		return str(c.OutputDir)
+	case "calendars":
+		if len(c.Calendars) == 0 {
+			return ""
+		}
+		quoted := make([]string, len(c.Calendars))
+		for i, cal := range c.Calendars {
+			quoted[i] = strconv.Quote(cal)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case "kdf_iterations":
+		return num(c.KDFIterations)
+	case "gui_port":
+		return num(c.GUIPort)
+	case "gui_dir":
+		return str(c.GUIDir)
+	case "gui_preview":
+		if c.GUIPreview == nil {
+			return ""
+		}
+		return strconv.FormatBool(*c.GUIPreview)
+	case "gui_max_upload_mb":
+		return num(c.GUIMaxUpload)
+	case "lang":
+		return str(c.Lang)
+	}
+	return ""
+}
+
+// SaveFile writes c to the config file at path. A setting already in the
+// file is rewritten on its own line, so comments and the order of the file
+// are kept; a new one is added at the end, and one c leaves unset is
+// removed. The file is replaced in one step, so a reader never sees it half
+// written.
+func SaveFile(path string, c *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	var out []string
+	written := make(map[string]bool)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripComment(lines[i]))
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || strings.HasPrefix(line, "[") || slices.Index(keys, key) < 0 {
+			out = append(out, lines[i])
+			continue
+		}
+		// Skip the rest of an array spread over several lines.
+		for strings.HasPrefix(value, "[") && !strings.HasSuffix(value, "]") && i+1 < len(lines) {
+			i++
+			value += " " + strings.TrimSpace(stripComment(lines[i]))
+		}
+		if v := c.value(key); v != "" && !written[key] {
+			out = append(out, key+" = "+v)
+			written[key] = true
+		}
+	}
+	for _, key := range keys {
+		if v := c.value(key); v != "" && !written[key] {
+			out = append(out, key+" = "+v)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*")
+	if err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	text := strings.Join(out, "\n")
+	if text != "" {
+		text += "\n"
+	}
+	if _, err := tmp.WriteString(text); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/config"
+)
+
+func TestParse(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	c, err := config.Parse([]byte(`
+# Defaults for imf.
+key = "~/keys/imf_private.pem"  # signing key
+output_dir = '/tmp/out#1'
+calendars = [
+  "https://a.example",
+  "https://b.example",  # second
+]
+kdf_iterations = 1_000_000
+gui_port = 8765
+gui_max_upload_mb = 16_384
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := &config.Config{
+		Key:           filepath.Join(home, "keys", "imf_private.pem"),
+		OutputDir:     "/tmp/out#1",
+		Calendars:     []string{"https://a.example", "https://b.example"},
+		KDFIterations: 1000000,
+		GUIPort:       8765,
+		GUIMaxUpload:  16384,
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("got %+v, want %+v", c, want)
+	}
+
+	for _, bad := range []string{
+		`kye = "typo"`,
+		`key = unquoted`,
+		`gui_port = "8765"`,
+		`gui_preview = yes`,
+		`[anchor]`,
+		`calendars = "https://a.example"`,
+	} {
+		if _, err := config.Parse([]byte(bad)); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+	t.Log("✓ Config file parsed, and typos and bad values rejected")
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(path, []byte("key = \"from-config\"\noutput_dir = \"config-out\"\ngui_port = 1\n"), 0600)
+	t.Setenv("IMF_CONFIG", path)
+	t.Setenv("IMF_KEY", "from-env")
+	t.Setenv("IMF_OUTPUT_DIR", "")
+	t.Setenv("IMF_KDF_ITERATIONS", "")
+	t.Setenv("IMF_GUI_PORT", "2")
+
+	c, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.Key != "from-env" || c.GUIPort != 2 {
+		t.Fatalf("environment did not override config: %+v", c)
+	}
+	if c.OutputDir != "config-out" {
+		t.Fatalf("config value lost: %+v", c)
+	}
+
+	t.Setenv("IMF_CONFIG", filepath.Join(t.TempDir(), "missing"))
+	if c, err = config.Load(); err != nil || c.OutputDir != "" {
+		t.Fatalf("missing config file: %+v, %v", c, err)
+	}
+	t.Log("✓ Environment overrides the config file, and a missing file is empty")
+}
+
+func TestSaveFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(path, []byte(`# Defaults for imf.
+key = "my-key"
+calendars = [
+  "https://a.example",  # first
+  "https://b.example",
+]
+# Port for the GUI.
+gui_port = 8765
+`), 0600)
+
+	c, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	preview := false
+	c.Key, c.GUIPort = "", 9000
+	c.Calendars = []string{"https://c.example"}
+	c.GUIDir, c.GUIPreview = "/data/imf", &preview
+	if err := config.SaveFile(path, c); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := `# Defaults for imf.
+calendars = ["https://c.example"]
+# Port for the GUI.
+gui_port = 9000
+gui_dir = "/data/imf"
+gui_preview = false
+`
+	if string(data) != want {
+		t.Fatalf("saved:\n%s\nwant:\n%s", data, want)
+	}
+	if got, err := config.LoadFile(path); err != nil || !reflect.DeepEqual(got, c) {
+		t.Fatalf("reloaded %+v, %v; want %+v", got, err, c)
+	}
+	t.Log("✓ Settings saved in place, with comments kept and unset ones removed")
+}
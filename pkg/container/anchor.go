@@ -0,0 +1,72 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// EmbedAnchor stores a confirmed OpenTimestamps proof over the SHA-256 of
+// the manifest's signable bytes (see anchor.ManifestProof) in the
+// container, so the container carries its own blockchain timestamp. Like an
+// RFC 3161 timestamp, the proof is kept outside the signed bytes; it needs
+// no signature, since it is checked against the hash it commits to.
+func EmbedAnchor(containerPath string, proof *anchor.Proof) error {
+	if len(proof.Blocks()) == 0 {
+		return errors.New("proof is still pending; only a proof confirmed in Bitcoin can be embedded")
+	}
+	unlock, err := lockContainer(containerPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, zipData, err := readContainer(containerPath)
+	if err != nil {
+		return err
+	}
+	if !m.IsSealed() {
+		return errors.New("container is not sealed")
+	}
+	signable, err := m.SignableBytes()
+	if err != nil {
+		return fmt.Errorf("computing signable bytes: %w", err)
+	}
+	digest := imfcrypto.HashSHA256(signable)
+	if !bytes.Equal(proof.Digest, digest[:]) {
+		return errors.New("proof is not over this container's manifest")
+	}
+	m.Anchor = base64.StdEncoding.EncodeToString(proof.Marshal())
+
+	entries, err := readZipEntries(zipData, manifestPath)
+	if err != nil {
+		return err
+	}
+	return rewriteContainer(containerPath, m, entries, nil)
+}
+
+// checkAnchor decodes a manifest's embedded proof and checks that it
+// commits to signable. Whether the blocks it names exist is left to an
+// OpenTimestamps verifier or imf anchor -upgrade.
+func checkAnchor(enc string, signable []byte) (*anchor.Proof, error) {
+	raw, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, fmt.Errorf("ANCHOR VERIFICATION FAILED: decoding proof: %w", err)
+	}
+	proof, err := anchor.ParseProof(raw)
+	if err != nil {
+		return nil, fmt.Errorf("ANCHOR VERIFICATION FAILED: %w", err)
+	}
+	digest := imfcrypto.HashSHA256(signable)
+	if !bytes.Equal(proof.Digest, digest[:]) {
+		return nil, errors.New("ANCHOR VERIFICATION FAILED: proof is for a different manifest")
+	}
+	return proof, nil
+}
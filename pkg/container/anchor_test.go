@@ -0,0 +1,175 @@
+package container_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// writeConfirmedProof writes the .ots proof imf anchor would have ended up
+// with once upgraded: the file hash joined with the manifest hash, hashed,
+// and attested in Bitcoin block height.
+func writeConfirmedProof(t *testing.T, imfPath string, height uint64) {
+	t.Helper()
+	data, _ := os.ReadFile(imfPath)
+	fileHash := sha256.Sum256(data)
+	signable, _ := readManifest(t, imfPath).SignableBytes()
+	mHash := sha256.Sum256(signable)
+
+	root := &anchor.Timestamp{Msg: fileHash[:]}
+	joined := &anchor.Timestamp{Msg: append(fileHash[:], mHash[:]...)}
+	commitment := sha256.Sum256(joined.Msg)
+	block := &anchor.Timestamp{Msg: commitment[:], Attestations: []anchor.Attestation{anchor.BitcoinAttestation(height)}}
+	joined.Ops = []anchor.Branch{{Op: anchor.Op{Tag: anchor.OpSHA256}, Timestamp: block}}
+	root.Ops = []anchor.Branch{{Op: anchor.Op{Tag: anchor.OpAppend, Arg: mHash[:]}, Timestamp: joined}}
+	os.WriteFile(imfPath+".ots", (&anchor.Proof{Digest: fileHash[:], Timestamp: root}).Marshal(), 0644)
+}
+
+func TestEmbedAnchor(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "anchored.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "minutes.txt")
+	os.WriteFile(src, []byte("board minutes"), 0644)
+	container.Add(imfPath, []string{src})
+	sealer, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: sealer.PrivateKey, EmbedPubKey: true})
+
+	writeConfirmedProof(t, imfPath, 850000)
+	proof, err := anchor.ManifestProof(imfPath)
+	if err != nil {
+		t.Fatalf("ManifestProof: %v", err)
+	}
+	pending := &anchor.Proof{Digest: proof.Digest, Timestamp: &anchor.Timestamp{
+		Msg: proof.Digest, Attestations: []anchor.Attestation{anchor.PendingAttestation("https://calendar.example")},
+	}}
+	if err := container.EmbedAnchor(imfPath, pending); err == nil {
+		t.Fatal("expected a pending proof to be rejected")
+	}
+	if err := container.EmbedAnchor(imfPath, proof); err != nil {
+		t.Fatalf("EmbedAnchor: %v", err)
+	}
+	os.Remove(imfPath + ".ots")
+
+	report, err := container.VerifyWithReport(imfPath, container.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyWithReport: %v", err)
+	}
+	if report.Anchor == nil || !reflect.DeepEqual(report.Anchor.Blocks(), []uint64{850000}) {
+		t.Fatalf("report anchor %+v", report.Anchor)
+	}
+	if r, err := anchor.VerifyAnchor(imfPath); err != nil || !r.Embedded {
+		t.Fatalf("VerifyAnchor: %+v, %v", r, err)
+	}
+
+	// The proof is over the signed manifest, so rewriting the container
+	// for a witness leaves it valid.
+	notary, _ := imfcrypto.GenerateKeyPair()
+	if _, err := container.Witness(imfPath, notary.PrivateKey, container.WitnessOptions{}); err != nil {
+		t.Fatalf("Witness: %v", err)
+	}
+	if report, err = container.VerifyWithReport(imfPath, container.VerifyOptions{}); err != nil || report.Anchor == nil {
+		t.Fatalf("verify after witness: %v", err)
+	}
+
+	// A proof for another manifest fails verification.
+	other := filepath.Join(tmpDir, "other.imf")
+	container.Create(other)
+	container.Add(other, []string{src})
+	container.Seal(other, container.SealOptions{PrivateKey: sealer.PrivateKey, EmbedPubKey: true})
+	if err := container.EmbedAnchor(other, proof); err == nil {
+		t.Fatal("expected a proof for another manifest to be rejected")
+	}
+	m := readManifest(t, other)
+	m.Anchor = base64.StdEncoding.EncodeToString(proof.Marshal())
+	data, _ := m.Marshal()
+	tampered := filepath.Join(tmpDir, "tampered.imf")
+	replaceEntry(t, other, tampered, "manifest.json", data)
+	if err := container.Verify(tampered, container.VerifyOptions{}); err == nil || !strings.Contains(err.Error(), "ANCHOR") {
+		t.Fatalf("expected an anchor mismatch, got %v", err)
+	}
+
+	// A proof made before proofs committed to the manifest cannot be
+	// embedded.
+	data, _ = os.ReadFile(other)
+	digest := sha256.Sum256(data)
+	old := &anchor.Proof{Digest: digest[:], Timestamp: &anchor.Timestamp{
+		Msg: digest[:], Attestations: []anchor.Attestation{anchor.BitcoinAttestation(850000)},
+	}}
+	os.WriteFile(other+".ots", old.Marshal(), 0644)
+	if _, err := anchor.ManifestProof(other); err == nil || !strings.Contains(err.Error(), "older version") {
+		t.Fatalf("expected an old proof to be refused, got %v", err)
+	}
+	t.Logf("✓ Proof embedded, anchored in block %d, and survives a witness", report.Anchor.Blocks()[0])
+}
+
+func TestSealAnchor(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("IMF_ANCHOR_QUEUE", filepath.Join(tmpDir, "queue.jsonl"))
+	t.Setenv("IMF_ANCHOR_LOG", filepath.Join(tmpDir, "anchors.jsonl"))
+	var up atomic.Bool
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		digest, _ := io.ReadAll(r.Body)
+		w.Write((&anchor.Timestamp{Msg: digest, Attestations: []anchor.Attestation{anchor.PendingAttestation(srv.URL)}}).Marshal())
+	}))
+	defer srv.Close()
+	calendar, _ := anchor.NewAnchorer("ots", []string{srv.URL}, 1, "")
+
+	imfPath := filepath.Join(tmpDir, "report.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "report.txt")
+	os.WriteFile(src, []byte("annual report"), 0644)
+	container.Add(imfPath, []string{src})
+	sealer, _ := imfcrypto.GenerateKeyPair()
+
+	// The calendar is down: the container is sealed all the same, and
+	// queued.
+	report, err := container.SealWithReport(imfPath, container.SealOptions{PrivateKey: sealer.PrivateKey, EmbedPubKey: true, Anchor: calendar})
+	if err != nil {
+		t.Fatalf("SealWithReport: %v", err)
+	}
+	if report.Anchor != nil || report.AnchorErr == nil {
+		t.Fatalf("anchor %+v, error %v with the calendar down", report.Anchor, report.AnchorErr)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	queued, err := anchor.Queued()
+	if err != nil || len(queued) != 1 || queued[0].Container != imfPath || queued[0].Error == "" {
+		t.Fatalf("queue %+v, %v", queued, err)
+	}
+
+	results, err := anchor.RetryQueued(nil)
+	if err != nil || len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("retry with the calendar down: %+v, %v", results, err)
+	}
+	up.Store(true)
+	results, err = anchor.RetryQueued(nil)
+	if err != nil || len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("retry: %+v, %v", results, err)
+	}
+	if _, err := anchor.VerifyAnchor(imfPath); err != nil {
+		t.Fatalf("VerifyAnchor: %v", err)
+	}
+	if queued, _ := anchor.Queued(); len(queued) != 0 {
+		t.Fatalf("queue still holds %+v", queued)
+	}
+	t.Logf("✓ Sealed while the calendar was down and anchored on retry: %s", results[0].Result.ProofPath)
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// certChainPath holds the signer's X.509 certificate chain, leaf first, as
+// PEM for people and tools that look inside the ZIP. The manifest carries
+// the same certificates, so they are covered by the signature.
+const certChainPath = "keyring/chain.pem"
+
+// ErrNoCertChain is returned when VerifyOptions.Roots is set but the
+// container was sealed without a certificate chain.
+var ErrNoCertChain = errors.New("container has no certificate chain")
+
+// encodeCertChain validates that chain's leaf certifies key and returns the
+// chain as base64 DER for the manifest.
+func encodeCertChain(chain []*x509.Certificate, key ed25519.PublicKey) ([]string, error) {
+	if err := checkLeafKey(chain[0], key); err != nil {
+		return nil, err
+	}
+	enc := make([]string, len(chain))
+	for i, c := range chain {
+		enc[i] = base64.StdEncoding.EncodeToString(c.Raw)
+	}
+	return enc, nil
+}
+
+// marshalCertChainPEM encodes chain as consecutive CERTIFICATE blocks.
+func marshalCertChainPEM(chain []*x509.Certificate) []byte {
+	var out []byte
+	for _, c := range chain {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})...)
+	}
+	return out
+}
+
+// parseCertChain decodes a manifest certificate chain.
+func parseCertChain(enc []string) ([]*x509.Certificate, error) {
+	chain := make([]*x509.Certificate, len(enc))
+	for i, s := range enc {
+		der, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("decoding certificate %d: %w", i+1, err)
+		}
+		if chain[i], err = x509.ParseCertificate(der); err != nil {
+			return nil, fmt.Errorf("parsing certificate %d: %w", i+1, err)
+		}
+	}
+	return chain, nil
+}
+
+// checkLeafKey checks that leaf certifies the Ed25519 key key.
+func checkLeafKey(leaf *x509.Certificate, key ed25519.PublicKey) error {
+	pub, ok := leaf.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("certificate %q is for a %s key, not Ed25519", leaf.Subject.CommonName, leaf.PublicKeyAlgorithm)
+	}
+	if !pub.Equal(key) {
+		return fmt.Errorf("certificate %q does not match the signing key", leaf.Subject.CommonName)
+	}
+	return nil
+}
+
+// verifyCertChain checks chain against roots as of at, the time the
+// container was sealed, so a container stays valid after its signing
+// certificate expires.
+func verifyCertChain(chain []*x509.Certificate, roots *x509.CertPool, at time.Time) error {
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   at,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("CERTIFICATE VERIFICATION FAILED: %w", err)
+	}
+	return nil
+}
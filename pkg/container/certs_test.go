@@ -0,0 +1,115 @@
+package container_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// issueCert creates a certificate for pub signed by parent's key (self-signed
+// if parent is nil).
+func issueCert(t *testing.T, name string, pub ed25519.PublicKey, parent *x509.Certificate, parentKey ed25519.PrivateKey, ca bool) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  ca,
+	}
+	if ca {
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent = tmpl
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	return cert
+}
+
+func TestCertificateChain(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "report.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "report.txt")
+	os.WriteFile(src, []byte("quarterly figures"), 0644)
+	container.Add(imfPath, []string{src})
+
+	caKey, _ := imfcrypto.GenerateKeyPair()
+	caCert := issueCert(t, "Example Root CA", caKey.PublicKey, nil, caKey.PrivateKey, true)
+	kp, _ := imfcrypto.GenerateKeyPair()
+	leaf := issueCert(t, "Release Signing", kp.PublicKey, caCert, caKey.PrivateKey, false)
+
+	// The leaf must certify the sealing key.
+	other, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: other.PrivateKey, CertChain: []*x509.Certificate{leaf}, DryRun: true}); err == nil {
+		t.Fatal("expected a chain for another key to be rejected")
+	}
+
+	// No embedded public key: the leaf certificate supplies it.
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, CertChain: []*x509.Certificate{leaf}}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	report, err := container.VerifyWithReport(imfPath, container.VerifyOptions{Roots: roots})
+	if err != nil {
+		t.Fatalf("VerifyWithReport: %v", err)
+	}
+	if len(report.Chain) != 1 || report.Chain[0].Subject.CommonName != "Release Signing" {
+		t.Fatalf("unexpected chain in report: %v", report.Chain)
+	}
+
+	// A CA bundle also satisfies RequireTrusted in place of the trust store.
+	if err := container.Verify(imfPath, container.VerifyOptions{Roots: roots, RequireTrusted: true}); err != nil {
+		t.Fatalf("Verify with roots and RequireTrusted: %v", err)
+	}
+
+	// Another organization's CA does not vouch for the key.
+	otherCAKey, _ := imfcrypto.GenerateKeyPair()
+	otherCA := issueCert(t, "Other Root CA", otherCAKey.PublicKey, nil, otherCAKey.PrivateKey, true)
+	wrong := x509.NewCertPool()
+	wrong.AddCert(otherCA)
+	if err := container.Verify(imfPath, container.VerifyOptions{Roots: wrong}); err == nil || !strings.Contains(err.Error(), "CERTIFICATE VERIFICATION FAILED") {
+		t.Fatalf("expected certificate verification failure, got %v", err)
+	}
+
+	// Tampering with the PEM copy is detected.
+	tampered := filepath.Join(tmpDir, "tampered.imf")
+	replaceEntry(t, imfPath, tampered, "keyring/chain.pem", imfcrypto.MarshalPublicKeyPEM(kp.PublicKey))
+	if err := container.Verify(tampered, container.VerifyOptions{}); err == nil {
+		t.Fatal("expected tampered chain file to fail verification")
+	}
+
+	// A container without a chain cannot satisfy a CA requirement.
+	plain := filepath.Join(tmpDir, "plain.imf")
+	container.Create(plain)
+	container.Add(plain, []string{src})
+	container.Seal(plain, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true})
+	if err := container.Verify(plain, container.VerifyOptions{Roots: roots}); !errors.Is(err, container.ErrNoCertChain) {
+		t.Fatalf("expected ErrNoCertChain, got %v", err)
+	}
+
+	t.Logf("✓ Certificate chain embedded, verified against CA bundle, wrong CA and tampering rejected")
+}
@@ -19,19 +19,29 @@ package container
 import (
 	"archive/zip"
 	"bytes"
+	"crypto"
+	"crypto/ecdh"
 	"crypto/ed25519"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/immutable-container/imf/pkg/anchor"
 	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
 	"github.com/immutable-container/imf/pkg/manifest"
+	"github.com/immutable-container/imf/pkg/sigstore"
+	"github.com/immutable-container/imf/pkg/tsa"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Well-known paths within the ZIP archive structure.
@@ -45,23 +55,236 @@ const (
 
 // SealOptions configures the seal operation.
 type SealOptions struct {
-	PrivateKey  ed25519.PrivateKey // required: signing key
-	EmbedPubKey bool               // embed public key in container
-	Passphrase  string             // if non-empty, encrypt files
-	ExpiresAt   *time.Time         // optional expiration
+	PrivateKey   ed25519.PrivateKey  // signing key; required unless Signer is set
+	Signer       imfcrypto.Signer    // signs instead of PrivateKey, e.g. a hardware token
+	EmbedPubKey  bool                // embed public key in container
+	Passphrase   string              // if non-empty, encrypt files
+	Recipients   []*ecdh.PublicKey   // if non-empty, encrypt files to these X25519 keys instead
+	Iterations   int                 // PBKDF2 iterations for Passphrase; 0 means imfcrypto.PBKDF2Iterations
+	TimeLock     *TimeLockOptions    // if set, encrypt so no one can decrypt before a given time
+	HideManifest bool                // also encrypt the manifest and pad every file, hiding file names and sizes
+	Policy       *SignaturePolicy    // optional k-of-n signature requirement
+	CertChain    []*x509.Certificate // optional X.509 chain for the signing key, leaf first
+	TSAURL       string              // optional RFC 3161 time-stamping authority to timestamp the manifest
+	Keyless      *KeylessOptions     // sign with a one-time key certified by Fulcio instead of PrivateKey
+	PQKey        []byte              // optional ML-DSA-65 seed; also signs the manifest (hybrid mode)
+	SignerName   string              // optional name recorded with the signature
+	SignerEmail  string              // optional email recorded with the signature
+	ExpiresAt    *time.Time          // optional expiration
+	DryRun       bool                // validate and report only; do not modify the container
+	Progress     Progress            // if set, told how far key derivation, encryption, and writing have got
+	// Anchor, if set, anchors the container once it is sealed. The seal
+	// does not depend on it: if the service cannot be reached, the
+	// container is queued for anchor.RetryQueued instead.
+	Anchor anchor.Anchorer
+}
+
+// SealReport describes what a seal signed and encrypted (or, for a dry run,
+// would have).
+type SealReport struct {
+	DryRun         bool
+	Files          []SealReportFile
+	Encrypted      bool
+	Algorithm      string     // encryption algorithm, if encrypted
+	KDF            string     // key derivation function, if encrypted
+	HiddenManifest bool       // the manifest itself is encrypted
+	Iterations     int        // KDF iterations, for passphrase encryption
+	TimeLock       *time.Time // when time-locked files can first be decrypted
+	ExpiresAt      *time.Time
+	EmbedPublicKey bool
+	PublicKey      string                   // base64 Ed25519 public key, if embedded
+	SignedBytes    int                      // length of the signed manifest bytes
+	SignedSHA256   string                   // SHA-256 of the signed manifest bytes
+	Signer         *manifest.SignerIdentity // identity recorded with the signature
+	CertChain      []*x509.Certificate      // embedded certificate chain, leaf first, if any
+	TSA            string                   // time-stamping authority asked for a timestamp, if any
+	PQ             string                   // post-quantum algorithm that also signs, if any
+	Timestamp      *time.Time               // the TSA's time, once obtained
+	Rekor          string                   // transparency log recording a keyless signature, if any
+	RekorEntry     *sigstore.Entry          // the log entry, once obtained
+	Policy         *PolicyStatus            // signature policy, if any, and the keys signed so far
+	AnchorBackend  string                   // anchoring backend the sealed container goes to, if any
+	Anchor         *anchor.AnchorResult     // the anchor, once submitted
+	AnchorErr      error                    // why anchoring failed and the container was queued instead
+}
+
+// SealReportFile is one file in a SealReport.
+type SealReportFile struct {
+	Name      string
+	Size      int64
+	SHA256    string // hex, of the plaintext
+	Path      string // path inside the ZIP after sealing
+	Encrypted bool
+}
+
+// AddOptions configures the add operation.
+type AddOptions struct {
+	SymlinkPolicy   manifest.SymlinkPolicy // how to treat symlinks; defaults to the container's, or follow
+	MaxDownloadSize int64                  // cap on bytes fetched per URL; defaults to DefaultMaxDownloadSize
+	DownloadTimeout time.Duration          // per-URL timeout; defaults to DefaultDownloadTimeout
+	Collisions      CollisionPolicy        // what to do when a name is taken; defaults to rename
+	BaseDir         string                 // if set, local files are named by their path relative to it
+	Progress        Progress               // if set, told how far reading the files and writing the container have got
+	DryRun          bool                   // validate and report only; do not download URLs or modify the container
+}
+
+// AddReport describes what AddWithReport added, or with DryRun set would
+// add.
+type AddReport struct {
+	DryRun   bool
+	Files    []AddReportFile
+	Replaced []string          // names of entries a new file took the place of, under CollisionOverwrite
+	Renamed  map[string]string // under CollisionRename, the name each renamed file was given as, by the name stored
+}
+
+// AddReportFile is one file in an AddReport.
+type AddReportFile struct {
+	Source   string // the path or URL given
+	Name     string // name stored in the container, after collisions are resolved
+	Path     string // path inside the ZIP
+	Size     int64  // bytes; -1 for a URL not downloaded in a dry run
+	SHA256   string // hex; empty for a URL not downloaded in a dry run
+	Download bool   // whether Source is fetched over the network
+}
+
+// CollisionPolicy controls what Add does when a file's (normalized) name is
+// already used by another entry.
+type CollisionPolicy string
+
+const (
+	CollisionRename    CollisionPolicy = "rename"    // store as name_1.ext, name_2.ext, ... (default)
+	CollisionError     CollisionPolicy = "error"     // fail the add operation
+	CollisionOverwrite CollisionPolicy = "overwrite" // replace the existing entry
+)
+
+// ParseCollisionPolicy validates a policy name. An empty string yields CollisionRename.
+func ParseCollisionPolicy(s string) (CollisionPolicy, error) {
+	switch p := CollisionPolicy(s); p {
+	case "":
+		return CollisionRename, nil
+	case CollisionRename, CollisionError, CollisionOverwrite:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown collision policy %q (want rename, error, or overwrite)", s)
 }
 
 // ExtractOptions configures extraction.
 type ExtractOptions struct {
-	Passphrase   string // required if container is encrypted
-	IgnoreExpiry bool   // extract even if expired
-	OutputDir    string // where to write extracted files
+	Passphrase    string                 // required if container is encrypted with a passphrase
+	RecipientKey  *ecdh.PrivateKey       // required if container is encrypted to recipients
+	IgnoreExpiry  bool                   // extract even if expired
+	OutputDir     string                 // where to write extracted files
+	SymlinkPolicy manifest.SymlinkPolicy // set to reject to refuse stored links; otherwise they are recreated
+	Existing      ExistingPolicy         // what to do about files already in OutputDir; "" means ExistingOverwrite
+	Kept          func(name string)      // if set, told of each existing file left in place under Existing
+	Progress      Progress               // if set, told how far key derivation, decryption, and writing have got
 }
 
 // VerifyOptions configures verification.
 type VerifyOptions struct {
-	PublicKey    ed25519.PublicKey // if nil, uses embedded key
-	IgnoreExpiry bool
+	PublicKey      ed25519.PublicKey // if nil, uses embedded key
+	IgnoreExpiry   bool
+	RequireTrusted bool                // without PublicKey, the embedded key must be one of TrustedKeys
+	Roots          *x509.CertPool      // if set, the certificate chain must lead to one of these CAs
+	TSARoots       *x509.CertPool      // if set, an RFC 3161 timestamp from a TSA under these CAs is required
+	TrustedKeys    []ed25519.PublicKey // keys trusted to have sealed the container
+	Revocations    []Revocation        // revocation statements to check the signing key against
+	PQPublicKey    []byte              // if set, a hybrid ML-DSA signature by this key is required
+	Detached       *DetachedSignature  // if set, must be the verifying key's signature over the whole file
+	RekorKey       crypto.PublicKey    // if set, a keyless signature logged by the Rekor log with this key is required
+	Identity       string              // if set, the leaf certificate must be issued to this email or URI
+	Issuer         string              // if set, the leaf certificate must name this OIDC issuer
+	Strict         bool                // fail if the archive holds an entry the manifest does not account for
+	Progress       Progress            // if set, told how far hashing the entries has got
+}
+
+// ErrUntrustedKey is returned when RequireTrusted is set and the embedded
+// public key is not in the trust store. Anyone can seal a container with
+// their own key embedded; the trust store is what ties it to a known signer.
+var ErrUntrustedKey = errors.New("UNTRUSTED KEY: the embedded public key is not in the trust store")
+
+// Kinds of failure that callers, such as scripts driving the imf command,
+// may need to tell apart without parsing messages. Errors of these kinds
+// keep their own messages and wrap the kind, so errors.Is reports it.
+var (
+	// ErrSignature: the container is not signed as required — a bad or
+	// missing signature, an untrusted or revoked key, or an unmet
+	// certificate, timestamp, transparency log, or policy requirement.
+	ErrSignature = errors.New("signature verification failed")
+	// ErrIntegrity: the contents do not match the signed manifest.
+	ErrIntegrity = errors.New("integrity check failed")
+	// ErrExpired: the container is past its expiry time.
+	ErrExpired = errors.New("container expired")
+	// ErrNoKey: the container is encrypted and no passphrase or recipient
+	// key was given, or a recipient key it is not encrypted to. A wrong
+	// passphrase fails to decrypt instead, with crypto.ErrDecrypt.
+	ErrNoKey = errors.New("no passphrase or key to decrypt the container")
+)
+
+// failure is an error of one of the kinds above. Its message is err's
+// alone, so classifying an error does not change what users see.
+type failure struct{ err, kind error }
+
+func (f *failure) Error() string   { return f.err.Error() }
+func (f *failure) Unwrap() []error { return []error{f.err, f.kind} }
+
+// classify marks err as a failure of kind.
+func classify(kind, err error) error {
+	return &failure{err: err, kind: kind}
+}
+
+// VerifyReport describes what VerifyWithReport found besides the pass/fail
+// result.
+type VerifyReport struct {
+	Signer    *manifest.SignerIdentity // who sealed the container, if recorded
+	SealedAt  *time.Time               // when it was sealed; nil for a hidden manifest
+	Policy    *PolicyStatus            // signature policy status, if the container has one
+	Witnesses []WitnessInfo            // witnesses in the order they signed
+	PQ        string                   // post-quantum algorithm that also signed, if any
+
+	// Chain is the signer's certificate chain, verified against
+	// VerifyOptions.Roots when those are given.
+	Chain []*x509.Certificate
+
+	// Timestamp is the container's RFC 3161 timestamp, if it has one. Its
+	// TSA was checked against VerifyOptions.TSARoots when those are given.
+	Timestamp *tsa.Token
+
+	// Rekor is the transparency log entry of a keyless signature, if the
+	// container has one. It was proven to be in the log when
+	// VerifyOptions.RekorKey is given.
+	Rekor *sigstore.Entry
+
+	// Anchor is the container's embedded OpenTimestamps proof, if it has
+	// one. It was checked to commit to the signed manifest, not against
+	// the blockchain.
+	Anchor *anchor.Proof
+
+	// Revocation is set when the signing key has been revoked, but only
+	// after the container's recorded seal time; it should be shown as a
+	// warning.
+	Revocation *Revocation
+
+	// Files is the integrity check of every file the manifest lists, in
+	// manifest order; for a hidden manifest, of every stored entry.
+	Files []FileCheck
+
+	// Unsigned names the archive entries that neither the manifest nor the
+	// container format accounts for. Nothing signs them; they may have been
+	// added after sealing, and VerifyOptions.Strict rejects them.
+	Unsigned []string
+}
+
+// FileCheck is the result of checking one file's stored bytes against the
+// manifest.
+type FileCheck struct {
+	Name      string // original name, or the stored path for a hidden manifest
+	Path      string // path inside the ZIP
+	Encrypted bool   // the hashes are of the stored ciphertext
+	Expected  string // SHA-256 hex digest recorded in the manifest
+	Computed  string // SHA-256 hex digest of the stored bytes; empty if missing or unreadable
+	Status    string // FileStatusOK, FileStatusMismatch, or FileStatusMissing
+	Err       error  // why the entry could not be read, if it could not
 }
 
 // Info holds container metadata for display.
@@ -72,29 +295,55 @@ type Info struct {
 	ExpiresAt *time.Time
 	Expired   bool
 	Encrypted bool
+	TimeLock  *time.Time // files cannot be decrypted before this time, if time-locked
 	HasPubKey bool
+	Hidden    bool // the manifest is encrypted
 	FileCount int
+	Signer    *manifest.SignerIdentity // who sealed the container, if recorded
+	CertChain []*x509.Certificate      // signer's certificate chain, leaf first, if embedded
+	PQ        string                   // post-quantum algorithm that also signed, if any
+	Keyless   bool                     // signed keylessly, with the signature in a transparency log
+	Policy    *PolicyStatus            // signature policy and who has signed, if any
+	Metadata  *manifest.Metadata       // title, description, case number, and tags, if set
+}
+
+// InfoOptions configures GetInfoWithOptions.
+type InfoOptions struct {
+	Passphrase   string           // reads a hidden manifest encrypted with a passphrase
+	RecipientKey *ecdh.PrivateKey // reads a hidden manifest encrypted to recipients
 }
 
 // FileInfo holds per-file metadata for listing.
 type FileInfo struct {
-	OriginalName string
-	OriginalSize int64
-	SHA256       string
+	OriginalName    string
+	OriginalSize    int64
+	SHA256          string
+	Path            string // path inside the ZIP
+	EncryptedSHA256 string // hash of the stored ciphertext, if encrypted
+	MimeType        string // guessed from the file extension
+	Status          string // per-file hash check result, when requested
+}
+
+// Per-file verification results reported in FileInfo.Status.
+const (
+	FileStatusOK       = "ok"       // stored bytes match the manifest hash
+	FileStatusMismatch = "mismatch" // stored bytes do not match
+	FileStatusMissing  = "missing"  // manifest entry has no ZIP entry
+)
+
+// ListOptions configures ListFilesWithOptions.
+type ListOptions struct {
+	CheckHashes  bool             // re-hash each stored entry and fill FileInfo.Status
+	Passphrase   string           // reads a hidden manifest encrypted with a passphrase
+	RecipientKey *ecdh.PrivateKey // reads a hidden manifest encrypted to recipients
 }
 
 // Create creates a new empty .imf container at the given path.
 // The container starts in the "open" state with an empty manifest and no files.
 // This is the entry point of the IMF lifecycle: Create -> Add -> Seal.
 func Create(path string) error {
-	// Enforce the .imf extension so containers are easily identifiable.
-	if !strings.HasSuffix(path, ".imf") {
-		return errors.New("container path must have .imf extension")
-	}
-
-	// Safety check: never silently overwrite an existing container.
-	if _, err := os.Stat(path); err == nil {
-		return fmt.Errorf("file already exists: %s", path)
+	if err := CheckCreate(path); err != nil {
+		return err
 	}
 
 	// Initialize a fresh manifest in the "open" state with creation timestamp.
@@ -126,54 +375,162 @@ func Create(path string) error {
 	return nil
 }
 
+// CheckCreate reports whether Create could make a container at path,
+// without making it: the path must end in .imf, not exist, and be in an
+// existing directory.
+func CheckCreate(path string) error {
+	// Enforce the .imf extension so containers are easily identifiable.
+	if !strings.HasSuffix(path, ".imf") {
+		return errors.New("container path must have .imf extension")
+	}
+
+	// Safety check: never silently overwrite an existing container.
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("file already exists: %s", path)
+	}
+	if fi, err := os.Stat(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	} else if !fi.IsDir() {
+		return fmt.Errorf("creating file: %s is not a directory", filepath.Dir(path))
+	}
+	return nil
+}
+
 // Add adds one or more files to an open container.
 // Each file is read from disk, SHA-256 hashed for integrity tracking, and stored
-// inside the ZIP under the files/ directory. Name collisions are resolved by
-// appending a numeric suffix. This operation is only allowed on open (unsealed) containers.
+// inside the ZIP under the files/ directory. Names are normalized to Unicode
+// NFC and collisions are resolved by appending a numeric suffix. This operation is only allowed on open (unsealed) containers.
+// If another process is modifying the container, ErrBusy is returned.
 func Add(containerPath string, filePaths []string) error {
+	return AddWithOptions(containerPath, filePaths, AddOptions{})
+}
+
+// AddWithOptions is like Add but allows the symlink policy and download
+// limits to be chosen. Any path beginning with http:// or https:// is fetched
+// rather than read from disk.
+// A container records the policy used to populate it, and a later add with
+// no policy given uses it; adding with a different policy is rejected so the
+// manifest stays truthful.
+func AddWithOptions(containerPath string, filePaths []string, opts AddOptions) error {
+	_, err := AddWithReport(containerPath, filePaths, opts)
+	return err
+}
+
+// AddWithReport is AddWithOptions, returning what was added. With
+// opts.DryRun set, local files are read and hashed and collisions resolved
+// as for a real add, but nothing is written and URLs are not downloaded:
+// their entries are named from the URL, which a server's
+// Content-Disposition could change, and have no size or hash.
+func AddWithReport(containerPath string, filePaths []string, opts AddOptions) (*AddReport, error) {
+	policy, err := manifest.ParseSymlinkPolicy(string(opts.SymlinkPolicy))
+	if err != nil {
+		return nil, err
+	}
+	collisions, err := ParseCollisionPolicy(string(opts.Collisions))
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the container lock across the whole read-modify-write so a
+	// concurrent add or seal cannot interleave with ours.
+	unlock, err := lockContainer(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Read the current container state (manifest + raw ZIP bytes).
 	m, zipData, err := readContainer(containerPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Enforce immutability: sealed containers reject all modifications.
 	if m.IsSealed() {
-		return errors.New("cannot add files to a sealed container")
+		return nil, errors.New("cannot add files to a sealed container")
+	}
+
+	if opts.SymlinkPolicy == "" && m.SymlinkPolicy != "" {
+		policy = m.SymlinkPolicy
+	} else if m.SymlinkPolicy != "" && m.SymlinkPolicy != policy {
+		return nil, fmt.Errorf("container was populated with symlink policy %q, cannot add with %q (leave the policy unset to use the container's)", m.SymlinkPolicy, policy)
 	}
+	m.SymlinkPolicy = policy
 
 	// Read all existing ZIP entries except the manifest (which we'll regenerate).
 	// We need these to rewrite the container with both old and new entries.
 	existingEntries, err := readZipEntries(zipData, manifestPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Process each file: read from disk, compute hash, add to manifest.
+	report := &AddReport{DryRun: opts.DryRun}
 	newEntries := make(map[string][]byte)
+	read := opts.Progress.start(StageRead, int64(len(filePaths)))
 	for _, fp := range filePaths {
-		// Read the entire file into memory for hashing and storage.
-		data, err := os.ReadFile(fp)
-		if err != nil {
-			return fmt.Errorf("reading %s: %w", fp, err)
+		var (
+			data        []byte
+			baseName    string
+			linkTarget  string
+			retrievedAt *time.Time
+		)
+		if IsURL(fp) && opts.DryRun {
+			u, err := url.Parse(fp)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", fp, err)
+			}
+			data, baseName = []byte{}, downloadName(u, "")
+		} else if IsURL(fp) {
+			// Download remote sources with size and time limits; the URL and
+			// retrieval time are recorded in the manifest for provenance.
+			var fetched time.Time
+			data, baseName, fetched, err = fetchURL(fp, opts)
+			if err != nil {
+				return nil, err
+			}
+			retrievedAt = &fetched
+		} else {
+			// Read the entire file into memory for hashing and storage. Under the
+			// store policy a symlink's "content" is its target path.
+			data, linkTarget, err = readAddSource(fp, policy)
+			if err != nil {
+				return nil, err
+			}
+			baseName = filepath.Base(fp)
+			if opts.BaseDir != "" {
+				// Keep the path relative to BaseDir so directory structure
+				// survives the round trip through the container.
+				baseName, err = relativeName(opts.BaseDir, fp)
+				if err != nil {
+					return nil, err
+				}
+			}
 		}
 
+		// Normalize to NFC so "é" typed on Linux and "e\u0301" from a macOS
+		// filesystem name the same entry. The as-given form is kept in the
+		// manifest when it differs from the stored name.
+		rawName := baseName
+		baseName = norm.NFC.String(baseName)
+
 		// Store files under files/<basename> inside the ZIP.
-		baseName := filepath.Base(fp)
 		zipPath := filesDir + baseName
 
-		// Handle name collisions: if "files/doc.pdf" already exists,
-		// try "files/doc_1.pdf", "files/doc_2.pdf", etc.
-		origZipPath := zipPath
-		suffix := 1
-		for entryExists(m, zipPath) || newEntries[zipPath] != nil {
-			ext := filepath.Ext(baseName)
-			name := strings.TrimSuffix(baseName, ext)
-			zipPath = fmt.Sprintf("%s%s_%d%s", filesDir, name, suffix, ext)
-			suffix++
+		// Handle name collisions according to the chosen policy.
+		if collisions == CollisionOverwrite && (entryExists(m, zipPath) || newEntries[zipPath] != nil) {
+			report.Replaced = append(report.Replaced, baseName)
+		}
+		asked := baseName
+		zipPath, baseName, err = placeEntry(m, existingEntries, newEntries, zipPath, collisions)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fp, err)
 		}
-		if zipPath != origZipPath {
-			fmt.Printf("  renamed to avoid collision: %s -> %s\n", baseName, filepath.Base(zipPath))
+		if baseName != asked {
+			if report.Renamed == nil {
+				report.Renamed = make(map[string]string)
+			}
+			report.Renamed[baseName] = asked
 		}
 
 		// Compute SHA-256 hash of the original plaintext content.
@@ -188,16 +545,38 @@ func Add(containerPath string, filePaths []string) error {
 			OriginalName: baseName,
 			OriginalSize: int64(len(data)),
 			SHA256:       hex.EncodeToString(hash[:]),
+			LinkTarget:   linkTarget,
+			RetrievedAt:  retrievedAt,
+		}
+		// Only normalization is recorded: a name changed to avoid a
+		// collision is reported, not kept as the file's original form.
+		if rawName != norm.NFC.String(rawName) {
+			entry.OriginalForm = rawName
+		}
+		if retrievedAt != nil {
+			entry.SourceURL = fp
 		}
 		if err := m.AddFile(entry); err != nil {
-			return fmt.Errorf("adding %s to manifest: %w", baseName, err)
+			return nil, fmt.Errorf("adding %s to manifest: %w", baseName, err)
 		}
+		rf := AddReportFile{Source: fp, Name: baseName, Path: zipPath, Size: entry.OriginalSize, SHA256: entry.SHA256, Download: IsURL(fp)}
+		if rf.Download && opts.DryRun {
+			rf.Size, rf.SHA256 = -1, ""
+		}
+		report.Files = append(report.Files, rf)
 
 		newEntries[zipPath] = data
+		read.add(1)
+	}
+	if opts.DryRun {
+		return report, nil
 	}
 
 	// Rewrite the container.
-	return rewriteContainer(containerPath, m, existingEntries, newEntries)
+	if err := writeContainer(containerPath, m, existingEntries, newEntries, opts.Progress); err != nil {
+		return nil, err
+	}
+	return report, nil
 }
 
 // Seal seals the container, making it permanently immutable.
@@ -213,75 +592,194 @@ func Add(containerPath string, filePaths []string) error {
 //
 // After sealing, no further modifications are possible. The container is either
 // fully sealed or unchanged — there is no partially-sealed state.
+// If another process is modifying the container, ErrBusy is returned.
 func Seal(containerPath string, opts SealOptions) error {
+	_, err := SealWithReport(containerPath, opts)
+	return err
+}
+
+// SealWithReport seals the container like Seal and returns a report of what
+// was signed and encrypted. With opts.DryRun set, every step runs in memory —
+// key checks, key derivation, encryption, and signing — but the container
+// file is left untouched.
+func SealWithReport(containerPath string, opts SealOptions) (*SealReport, error) {
+	unlock, err := lockContainer(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	m, zipData, err := readContainer(containerPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Sealed containers cannot be re-sealed.
 	if m.IsSealed() {
-		return errors.New("container is already sealed")
+		return nil, errors.New("container is already sealed")
+	}
+
+	// Check the signing key up front so a bad key fails before any work.
+	// Keyless signing uses a one-time key in place of the caller's.
+	signer := opts.Signer
+	if opts.Keyless != nil {
+		if signer != nil || opts.PrivateKey != nil || len(opts.CertChain) > 0 {
+			return nil, errors.New("keyless signing replaces the signing key and certificate chain")
+		}
+		kp, err := imfcrypto.GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+		defer imfcrypto.Wipe(kp.PrivateKey)
+		opts.PrivateKey = kp.PrivateKey
+	}
+	if signer == nil {
+		if signer, err = imfcrypto.NewKeySigner(opts.PrivateKey); err != nil {
+			return nil, fmt.Errorf("invalid signing key: %w", err)
+		}
+	}
+	if len(m.Files) == 0 {
+		return nil, errors.New("cannot seal an empty container")
 	}
 
 	// Load all file entries from the current ZIP.
 	existingEntries, err := readZipEntries(zipData, manifestPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// --- Step 1: Encryption (optional) ---
-	// If a passphrase is provided, derive an AES-256 key and encrypt each file
-	// individually. Each encrypted file gets a unique nonce for security.
+	// With a passphrase, derive an AES-256 key from it; with recipients,
+	// generate a random content key and wrap it for each recipient's X25519
+	// key; with a time lock, encrypt the random key to a future drand round.
+	// Either way each file is then encrypted individually with a unique
+	// nonce.
+	if opts.Passphrase != "" && len(opts.Recipients) > 0 {
+		return nil, errors.New("encrypt with a passphrase or to recipients, not both")
+	}
+	if opts.TimeLock != nil && (opts.Passphrase != "" || len(opts.Recipients) > 0) {
+		return nil, errors.New("a time lock replaces the passphrase or recipients")
+	}
+	if opts.HideManifest && opts.Passphrase == "" && len(opts.Recipients) == 0 {
+		return nil, errors.New("hiding the manifest requires a passphrase or recipients")
+	}
 	var encKey []byte
-	var salt []byte
 	processedEntries := make(map[string][]byte)
 
-	if opts.Passphrase != "" {
+	switch {
+	case opts.Passphrase != "":
 		// Generate a random 32-byte salt for key derivation.
-		salt, err = imfcrypto.GenerateSalt()
+		salt, err := imfcrypto.GenerateSalt()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// Derive a 256-bit encryption key from the passphrase using PBKDF2
-		// with 600,000 iterations (OWASP 2023 recommendation).
-		encKey, err = imfcrypto.DeriveKey(opts.Passphrase, salt)
+		// Derive a 256-bit encryption key from the passphrase using PBKDF2,
+		// by default with 600,000 iterations (OWASP 2023 recommendation).
+		iterations := opts.Iterations
+		if iterations == 0 {
+			iterations = imfcrypto.PBKDF2Iterations
+		}
+		if iterations < imfcrypto.MinSealIterations {
+			return nil, fmt.Errorf("PBKDF2 iterations %d below the minimum of %d", iterations, imfcrypto.MinSealIterations)
+		}
+		encKey, err = opts.Progress.deriveKey(opts.Passphrase, salt, iterations)
 		if err != nil {
-			return fmt.Errorf("deriving encryption key: %w", err)
+			return nil, fmt.Errorf("deriving encryption key: %w", err)
 		}
 
 		// Store encryption metadata in the manifest so the recipient knows
 		// which algorithm and KDF parameters to use for decryption.
 		m.Encryption = &manifest.EncryptionInfo{
 			Algorithm:  "AES-256-GCM",
-			KDF:        "PBKDF2-HMAC-SHA256",
+			KDF:        kdfPBKDF2,
 			Salt:       base64.StdEncoding.EncodeToString(salt),
-			Iterations: imfcrypto.PBKDF2Iterations,
+			Iterations: iterations,
 		}
 
+	case len(opts.Recipients) > 0:
+		encKey, err = imfcrypto.GenerateContentKey()
+		if err != nil {
+			return nil, err
+		}
+		m.Encryption = &manifest.EncryptionInfo{
+			Algorithm: "AES-256-GCM",
+			KDF:       kdfX25519,
+		}
+		for _, r := range opts.Recipients {
+			eph, wrapped, err := imfcrypto.WrapKey(r, encKey)
+			if err != nil {
+				return nil, fmt.Errorf("wrapping key for recipient: %w", err)
+			}
+			m.Encryption.Recipients = append(m.Encryption.Recipients, manifest.Recipient{
+				PublicKey:    base64.StdEncoding.EncodeToString(r.Bytes()),
+				EphemeralKey: base64.StdEncoding.EncodeToString(eph),
+				WrappedKey:   base64.StdEncoding.EncodeToString(wrapped),
+			})
+		}
+
+	case opts.TimeLock != nil:
+		encKey, err = imfcrypto.GenerateContentKey()
+		if err != nil {
+			return nil, err
+		}
+		tl, err := lockContentKey(opts.TimeLock, encKey)
+		if err != nil {
+			return nil, err
+		}
+		m.Encryption = &manifest.EncryptionInfo{
+			Algorithm: "AES-256-GCM",
+			KDF:       kdfTimeLock,
+			TimeLock:  tl,
+		}
+	}
+
+	// The content key is only needed until the seal is written.
+	defer imfcrypto.Wipe(encKey)
+	if opts.HideManifest {
+		m.Encryption.Padding = paddingPow2
+	}
+
+	if encKey != nil {
 		// Encrypt each file individually with AES-256-GCM.
 		// We also hash the ciphertext and store it in the manifest, providing
 		// a second integrity check layer (encrypted hash verified before decryption).
+		var size int64
+		for _, fe := range m.Files {
+			size += int64(len(existingEntries[fe.Path]))
+		}
+		encrypting := opts.Progress.start(StageEncrypt, size)
 		for i, fe := range m.Files {
 			plaintext, ok := existingEntries[fe.Path]
 			if !ok {
-				return fmt.Errorf("file not found in container: %s", fe.Path)
+				return nil, fmt.Errorf("file not found in container: %s", fe.Path)
 			}
 
-			ciphertext, err := imfcrypto.Encrypt(encKey, plaintext)
+			// A hidden manifest hides the sizes too, so the stored
+			// entry must not give them away.
+			stored := plaintext
+			if opts.HideManifest {
+				stored = padPlaintext(plaintext)
+			}
+			ciphertext, err := imfcrypto.Encrypt(encKey, stored)
 			if err != nil {
-				return fmt.Errorf("encrypting %s: %w", fe.OriginalName, err)
+				return nil, fmt.Errorf("encrypting %s: %w", fe.OriginalName, err)
 			}
 
 			// Rename the file path with .enc suffix to indicate encryption,
 			// and record the ciphertext hash for pre-decryption integrity check.
+			// With a hidden manifest the stored name must not leak the
+			// original, so entries are simply numbered.
 			encPath := fe.Path + ".enc"
+			if opts.HideManifest {
+				encPath = fmt.Sprintf("%s%06d.enc", filesDir, i+1)
+			}
 			encHash := imfcrypto.HashSHA256(ciphertext)
 			m.Files[i].EncryptedSHA256 = hex.EncodeToString(encHash[:])
 			m.Files[i].Path = encPath
 
 			processedEntries[encPath] = ciphertext
+			encrypting.add(int64(len(plaintext)))
 		}
 	} else {
 		// No encryption — copy entries as-is.
@@ -298,22 +796,68 @@ func Seal(containerPath string, opts SealOptions) error {
 		m.ExpiresAt = &t
 	}
 
+	// --- Step 2b: Record the signature policy (optional) ---
+	// Like the expiry, the policy is signed, so the threshold and key list
+	// cannot be lowered or swapped after sealing.
+	if opts.Policy != nil {
+		p, err := newManifestPolicy(opts.Policy)
+		if err != nil {
+			return nil, err
+		}
+		m.Policy = p
+	}
+
 	// --- Step 3: Embed public key (optional) ---
 	// Embedding the public key makes the container self-verifying: the recipient
 	// can verify the signature without any prior key exchange or key server.
 	// The key is stored both in the manifest (base64) and as a PEM file in keyring/.
 	if opts.EmbedPubKey {
-		pubKey := opts.PrivateKey.Public().(ed25519.PublicKey)
+		pubKey := signer.Public()
 		m.PublicKey = base64.StdEncoding.EncodeToString(pubKey)
 
 		pubKeyPEM := imfcrypto.MarshalPublicKeyPEM(pubKey)
 		processedEntries[pubKeyPath] = pubKeyPEM
 	}
 
+	// --- Step 3b: Record the signer's identity ---
+	// The fingerprint ties the optional name and email to the signing key;
+	// both are covered by the signature.
+	m.Signer = &manifest.SignerIdentity{
+		Name:        opts.SignerName,
+		Email:       opts.SignerEmail,
+		Fingerprint: imfcrypto.Fingerprint(signer.Public()),
+	}
+
+	// --- Step 3c: Embed the certificate chain (optional) ---
+	// The chain ties the signing key to an organization's PKI; verifiers
+	// then need only their CA bundle rather than this particular key.
+	// For keyless signing Fulcio issues the chain, certifying the one-time
+	// key for the sealer's identity; a dry run does not ask for one.
+	if opts.Keyless != nil && !opts.DryRun {
+		if opts.CertChain, err = requestKeylessCert(opts.Keyless, opts.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.CertChain) > 0 {
+		if m.CertChain, err = encodeCertChain(opts.CertChain, signer.Public()); err != nil {
+			return nil, err
+		}
+		processedEntries[certChainPath] = marshalCertChainPEM(opts.CertChain)
+	}
+
+	// --- Step 3d: Record the post-quantum key (optional) ---
+	// In hybrid mode an ML-DSA key signs the same bytes as Ed25519. Its
+	// public key is covered by both signatures.
+	if opts.PQKey != nil {
+		if m.PQKey, err = newPQKey(opts.PQKey); err != nil {
+			return nil, err
+		}
+	}
+
 	// --- Step 4: Transition to sealed state ---
 	// This is irreversible — the manifest state becomes "sealed" with a timestamp.
 	if err := m.Seal(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// --- Step 5: Sign the manifest with Ed25519 ---
@@ -322,9 +866,12 @@ func Seal(containerPath string, opts SealOptions) error {
 	// file hashes, timestamps, expiry, and the embedded public key.
 	signable, err := m.SignableBytes()
 	if err != nil {
-		return fmt.Errorf("computing signable bytes: %w", err)
+		return nil, fmt.Errorf("computing signable bytes: %w", err)
+	}
+	sig, err := signer.Sign(signable)
+	if err != nil {
+		return nil, fmt.Errorf("signing manifest: %w", err)
 	}
-	sig := imfcrypto.Sign(opts.PrivateKey, signable)
 	m.Signature = base64.StdEncoding.EncodeToString(sig)
 
 	// --- Step 6: Add the sealed marker file ---
@@ -332,46 +879,233 @@ func Seal(containerPath string, opts SealOptions) error {
 	// signals that the container is immutable without needing to parse the manifest.
 	processedEntries[sealedMarker] = []byte("sealed")
 
+	// --- Step 6b: Hide the manifest (optional) ---
+	// The signed manifest is itself encrypted and replaced by an outer
+	// header that is signed in turn; see hideManifest.
+	report := newSealReport(m, signable, opts.DryRun)
+	if opts.HideManifest {
+		outer, blob, err := hideManifest(m, encKey, signer)
+		if err != nil {
+			return nil, err
+		}
+		if signable, err = outer.SignableBytes(); err != nil {
+			return nil, fmt.Errorf("computing signable bytes: %w", err)
+		}
+		report = newSealReport(m, signable, opts.DryRun)
+		report.HiddenManifest = true
+		m = outer
+		processedEntries[hiddenManifestPath] = blob
+	}
+
+	// --- Step 6c: Add the post-quantum signature (optional) ---
+	// It covers the same bytes as the final Ed25519 signature: the outer
+	// header's, if the manifest was hidden.
+	if m.PQKey != nil {
+		if err := addPQSignature(m, opts.PQKey, signable); err != nil {
+			return nil, err
+		}
+		report.PQ = m.PQKey.Algorithm
+	}
+
+	// --- Step 6d: Cosign under the policy ---
+	// If the sealer is one of the policy keys, their signature counts
+	// toward the threshold straight away.
+	if m.Policy != nil {
+		pub := base64.StdEncoding.EncodeToString(signer.Public())
+		if policyLists(m.Policy, pub) {
+			if err := addCosignature(m, signer); err != nil {
+				return nil, err
+			}
+		}
+		report.Policy = policyStatus(m, signable)
+	}
+	report.TSA = opts.TSAURL
+	if opts.Keyless != nil {
+		report.Rekor = opts.Keyless.rekorURL()
+	}
+	if opts.Anchor != nil {
+		report.AnchorBackend = opts.Anchor.Name()
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	// --- Step 6e: Obtain a trusted timestamp (optional) ---
+	// A TSA countersigns the hash of the signed manifest bytes, giving
+	// independent proof of when the container was sealed.
+	if opts.TSAURL != "" {
+		token, tok, err := requestTimestamp(opts.TSAURL, signable)
+		if err != nil {
+			return nil, err
+		}
+		m.Timestamp = token
+		report.Timestamp = &tok.Time
+	}
+
+	// --- Step 6f: Log the keyless signature (optional) ---
+	// Rekor records the one-time key's signature over the hash of the signed
+	// manifest bytes. Its inclusion proof shows the signature was made while
+	// the short-lived certificate was valid.
+	if opts.Keyless != nil {
+		raw, entry, err := logSignature(opts.Keyless, signer, opts.CertChain[0], signable)
+		if err != nil {
+			return nil, err
+		}
+		m.Rekor = raw
+		report.RekorEntry = entry
+	}
+
 	// --- Step 7: Rewrite the container atomically ---
 	// The entire ZIP is rewritten with the signed manifest, processed (possibly
 	// encrypted) files, embedded key, and sealed marker.
-	return rewriteContainer(containerPath, m, nil, processedEntries)
+	if err := writeContainer(containerPath, m, nil, processedEntries, opts.Progress); err != nil {
+		return nil, err
+	}
+
+	// --- Step 8: Anchor the sealed container (optional) ---
+	// The seal stands whatever happens here: a container that cannot be
+	// anchored now is queued, to be anchored by anchor.RetryQueued.
+	if opts.Anchor != nil {
+		if report.Anchor, report.AnchorErr = opts.Anchor.Anchor(containerPath); report.AnchorErr != nil {
+			if err := anchor.Enqueue(containerPath, opts.Anchor, report.AnchorErr); err != nil {
+				report.AnchorErr = fmt.Errorf("%w (and queueing it failed: %v)", report.AnchorErr, err)
+			}
+		}
+	}
+	return report, nil
+}
+
+// newSealReport summarizes a signed manifest for SealWithReport.
+func newSealReport(m *manifest.Manifest, signable []byte, dryRun bool) *SealReport {
+	digest := imfcrypto.HashSHA256(signable)
+	r := &SealReport{
+		DryRun:         dryRun,
+		ExpiresAt:      m.ExpiresAt,
+		PublicKey:      m.PublicKey,
+		SignedBytes:    len(signable),
+		SignedSHA256:   hex.EncodeToString(digest[:]),
+		Signer:         m.Signer,
+		EmbedPublicKey: m.PublicKey != "",
+	}
+	if len(m.CertChain) > 0 {
+		r.CertChain, _ = parseCertChain(m.CertChain)
+	}
+	if m.Encryption != nil {
+		r.Encrypted = true
+		r.Algorithm = m.Encryption.Algorithm
+		r.KDF = m.Encryption.KDF
+		r.Iterations = m.Encryption.Iterations
+		if tl := m.Encryption.TimeLock; tl != nil {
+			r.TimeLock = &tl.Unlocks
+		}
+	}
+	for _, fe := range m.Files {
+		r.Files = append(r.Files, SealReportFile{
+			Name:      fe.OriginalName,
+			Size:      fe.OriginalSize,
+			SHA256:    fe.SHA256,
+			Path:      fe.Path,
+			Encrypted: fe.EncryptedSHA256 != "",
+		})
+	}
+	return r
 }
 
 // Verify checks the cryptographic integrity of a sealed container.
-// Verification performs three checks:
+// Verification performs four checks:
 //   1. Expiration: rejects expired containers (unless IgnoreExpiry is set)
-//   2. Signature: verifies the Ed25519 signature over the manifest
-//   3. File hashes: confirms each file's hash matches the manifest record
+//   2. Signature: verifies the Ed25519 signature over the manifest, that
+//      the recorded signer fingerprint matches the verifying key, that any
+//      post-quantum signature verifies too (hybrid mode), that any
+//      RFC 3161 timestamp covers the manifest, that any keyless signature
+//      is recorded in the transparency log, that the key was not revoked
+//      before the seal (see VerifyOptions.Revocations),
+//      that any signature policy's threshold of cosignatures is met, and
+//      that every witness countersignature is valid
+//   3. Structure: confirms the ZIP layout is exactly as written at seal time
+//   4. File hashes: confirms each file's hash matches the manifest record
 //
+// With VerifyOptions.Detached, the container file as a whole must also match
+// a detached signature by the verifying key (see SignFile).
+//
+// A container with a hidden manifest is verified without a key: its signed
+// envelope records the hash of every stored entry and of the encrypted manifest.
+//
+// Entries are streamed from disk (or fetched by range from a remote backend,
+// see IsRemote), so memory use does not grow with the size of the container.
 // If the container has an embedded public key, it will be used automatically.
 // An explicit public key can be provided to override the embedded one.
 func Verify(containerPath string, opts VerifyOptions) error {
-	m, zipData, err := readContainer(containerPath)
+	_, err := VerifyWithReport(containerPath, opts)
+	return err
+}
+
+// VerifyWithReport verifies the container like Verify and reports what else
+// it found: the signature policy status, the chain of witnesses, and the
+// check of each file. When files fail that check, the report is returned
+// along with the error, listing every file, good or bad.
+func VerifyWithReport(containerPath string, opts VerifyOptions) (*VerifyReport, error) {
+	// Open the archive in place rather than loading it into memory: entries
+	// are streamed through the hash one at a time, so memory use is bounded
+	// regardless of container size.
+	cf, err := openObject(containerPath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer cf.Close()
+	zr, err := zip.NewReader(cf, cf.Size())
+	if err != nil {
+		return nil, fmt.Errorf("opening zip: %w", err)
+	}
+
+	limits := CurrentLimits()
+	if err := limits.checkArchive(zr); err != nil {
+		return nil, err
+	}
+	m, err := loadManifest(zr, limits)
+	if err != nil {
+		return nil, err
 	}
 	if !m.IsSealed() {
-		return errors.New("container is not sealed")
+		return nil, errors.New("container is not sealed")
 	}
 
 	// Check expiry.
 	if m.IsExpired() && !opts.IgnoreExpiry {
-		return fmt.Errorf("container expired at %s (use --ignore-expiry to override)", m.ExpiresAt.Format(time.RFC3339))
+		return nil, classify(ErrExpired, fmt.Errorf("container expired at %s (use --ignore-expiry to override)", m.ExpiresAt.Format(time.RFC3339)))
+	}
+
+	var chain []*x509.Certificate
+	if len(m.CertChain) > 0 {
+		if chain, err = parseCertChain(m.CertChain); err != nil {
+			return nil, err
+		}
 	}
 
 	// Determine which public key to use for signature verification.
-	// Priority: explicit key from options > embedded key in manifest.
+	// Priority: explicit key from options > embedded key in manifest >
+	// the leaf certificate's key. A key taken from the container must be
+	// in the trust store if one is required, unless a CA vouches for it.
 	pubKey := opts.PublicKey
 	if pubKey == nil {
-		if m.PublicKey == "" {
-			return errors.New("no public key provided and none embedded in container")
+		switch {
+		case m.PublicKey != "":
+			keyBytes, err := base64.StdEncoding.DecodeString(m.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("decoding embedded public key: %w", err)
+			}
+			pubKey = ed25519.PublicKey(keyBytes)
+		case chain != nil:
+			var ok bool
+			if pubKey, ok = chain[0].PublicKey.(ed25519.PublicKey); !ok {
+				return nil, errors.New("leaf certificate is not for an Ed25519 key")
+			}
+		default:
+			return nil, classify(ErrSignature, errors.New("no public key provided and none embedded in container"))
 		}
-		keyBytes, err := base64.StdEncoding.DecodeString(m.PublicKey)
-		if err != nil {
-			return fmt.Errorf("decoding embedded public key: %w", err)
+		if opts.RequireTrusted && opts.Roots == nil && !keyListed(opts.TrustedKeys, pubKey) {
+			return nil, classify(ErrSignature, fmt.Errorf("%w (fingerprint %s)", ErrUntrustedKey, imfcrypto.Fingerprint(pubKey)))
 		}
-		pubKey = ed25519.PublicKey(keyBytes)
 	}
 
 	// Verify the Ed25519 signature over the manifest.
@@ -379,40 +1113,249 @@ func Verify(containerPath string, opts VerifyOptions) error {
 	// expiry, and the embedded public key — any modification is detected.
 	sigBytes, err := base64.StdEncoding.DecodeString(m.Signature)
 	if err != nil {
-		return fmt.Errorf("decoding signature: %w", err)
+		return nil, fmt.Errorf("decoding signature: %w", err)
 	}
 	signable, err := m.SignableBytes()
 	if err != nil {
-		return fmt.Errorf("computing signable bytes: %w", err)
+		return nil, fmt.Errorf("computing signable bytes: %w", err)
 	}
 	if !imfcrypto.Verify(pubKey, signable, sigBytes) {
-		return errors.New("SIGNATURE VERIFICATION FAILED — container may be tampered")
+		return nil, classify(ErrSignature, errors.New("SIGNATURE VERIFICATION FAILED — container may be tampered"))
+	}
+	if m.Signer != nil && m.Signer.Fingerprint != imfcrypto.Fingerprint(pubKey) {
+		return nil, classify(ErrSignature, errors.New("SIGNER MISMATCH: recorded fingerprint does not match the verifying key"))
 	}
 
-	// Verify per-file integrity by checking hashes against manifest records.
-	// For encrypted containers, we verify the ciphertext hash (the plaintext
-	// hash is verified during extraction after decryption).
-	entries, err := readZipEntries(zipData, manifestPath, sealedMarker, pubKeyPath)
-	if err != nil {
-		return err
+	// In hybrid mode the ML-DSA signature must verify as well, so forging
+	// the container takes breaking both schemes.
+	if err := checkPQSignature(m, signable, opts.PQPublicKey); err != nil {
+		return nil, classify(ErrSignature, err)
 	}
 
-	for _, fe := range m.Files {
-		data, ok := entries[fe.Path]
-		if !ok {
-			return fmt.Errorf("INTEGRITY FAILURE: file missing from container: %s", fe.Path)
+	// An RFC 3161 timestamp must cover the signed bytes. Once its TSA is
+	// checked against the caller's roots, its time replaces the sealer's own
+	// claim for the certificate and revocation checks below.
+	sealedAt := m.SealedAt
+	var stamp *tsa.Token
+	if m.Timestamp != "" {
+		if stamp, err = checkTimestamp(m.Timestamp, signable, opts.TSARoots); err != nil {
+			return nil, classify(ErrSignature, err)
+		}
+		if opts.TSARoots != nil {
+			sealedAt = &stamp.Time
+		}
+	} else if opts.TSARoots != nil {
+		return nil, classify(ErrSignature, ErrNoTimestamp)
+	}
+
+	// A keyless signature's Rekor entry must record a signature by the leaf
+	// certificate's key over the same bytes. Proven to be in the log, the
+	// time it was logged is trusted in the same way.
+	var logEntry *sigstore.Entry
+	if len(m.Rekor) > 0 {
+		if logEntry, err = checkRekor(m.Rekor, chain, signable, opts.RekorKey); err != nil {
+			return nil, classify(ErrSignature, err)
+		}
+		if opts.RekorKey != nil {
+			t := logEntry.Time()
+			sealedAt = &t
+		}
+	} else if opts.RekorKey != nil {
+		return nil, classify(ErrSignature, ErrNoTransparencyLog)
+	}
+
+	// An embedded blockchain anchor must also commit to the signed bytes.
+	var proof *anchor.Proof
+	if m.Anchor != "" {
+		if proof, err = checkAnchor(m.Anchor, signable); err != nil {
+			return nil, classify(ErrSignature, err)
 		}
+	}
 
-		// If encrypted, verify encrypted hash.
+	// A certificate chain must certify the key that signed. Checked against
+	// the caller's CAs, it is evaluated as of the seal time.
+	if chain != nil {
+		if err := checkLeafKey(chain[0], pubKey); err != nil {
+			return nil, classify(ErrSignature, fmt.Errorf("CERTIFICATE VERIFICATION FAILED: %w", err))
+		}
+	}
+	if opts.Roots != nil {
+		if chain == nil {
+			return nil, classify(ErrSignature, ErrNoCertChain)
+		}
+		at := time.Now()
+		if sealedAt != nil {
+			at = *sealedAt
+		}
+		if err := verifyCertChain(chain, opts.Roots, at); err != nil {
+			return nil, classify(ErrSignature, err)
+		}
+	}
+	if opts.Identity != "" || opts.Issuer != "" {
+		if chain == nil {
+			return nil, classify(ErrSignature, ErrNoCertChain)
+		}
+		if err := checkIdentity(chain[0], opts.Identity, opts.Issuer); err != nil {
+			return nil, classify(ErrSignature, err)
+		}
+	}
+
+	// A signature policy additionally requires enough of its listed keys to
+	// have signed the same bytes.
+	report := &VerifyReport{Signer: m.Signer, SealedAt: m.SealedAt, Chain: chain, Timestamp: stamp, Rekor: logEntry, Anchor: proof, PQ: pqAlgorithm(m)}
+	if report.Revocation, err = checkRevocation(opts.Revocations, pubKey, sealedAt); err != nil {
+		return nil, classify(ErrSignature, err)
+	}
+	if m.Policy != nil {
+		report.Policy = policyStatus(m, signable)
+		if s := report.Policy; !s.Satisfied() {
+			return nil, classify(ErrSignature, fmt.Errorf("SIGNATURE POLICY NOT MET: %d of %d required signatures", len(s.Signed), s.Threshold))
+		}
+	}
+
+	// Witness countersignatures must each be valid and form an unbroken
+	// chain over this manifest.
+	if report.Witnesses, err = checkWitnesses(m, signable); err != nil {
+		return nil, classify(ErrSignature, err)
+	}
+
+	// Index the archive. Duplicate names are rejected outright: a reader that
+	// picks the other copy would see different content than we verified.
+	// The archive structure itself is checked too: each local header must
+	// agree with its central directory record, and no byte may sit outside
+	// the entries and directory.
+	if err := checkArchiveLayout(cf, cf.Size(), zr.File); err != nil {
+		return nil, classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: %w", err))
+	}
+	index := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		if _, dup := index[f.Name]; dup {
+			return nil, classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: duplicate entry in container: %s", f.Name))
+		}
+		if err := checkLocalHeader(cf, f); err != nil {
+			return nil, classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: %w", err))
+		}
+		index[f.Name] = f
+	}
+
+	// Verify per-file integrity by streaming each entry through SHA-256 and
+	// checking it against the manifest record. For encrypted containers, we
+	// verify the ciphertext hash (the plaintext hash is verified during
+	// extraction after decryption).
+	// A hidden container's file list is encrypted; its envelope lists the
+	// stored entries and their hashes instead.
+	records := m.Files
+	if m.IsHidden() {
+		records = envelopeRecords(m.Envelope)
+	}
+	// Every file is checked, rather than stopping at the first bad one, so
+	// the report shows the extent of any damage.
+	b := limits.newBudget()
+	checked := map[string]bool{manifestPath: true}
+	var size int64
+	for _, f := range zr.File {
+		if f.Name != manifestPath {
+			size += int64(f.UncompressedSize64)
+		}
+	}
+	hashing := opts.Progress.start(StageHash, size)
+	var failures []error
+	for _, fe := range records {
+		check := FileCheck{Name: fe.OriginalName, Path: fe.Path, Expected: fe.SHA256, Status: FileStatusOK}
 		if fe.EncryptedSHA256 != "" {
-			hash := imfcrypto.HashSHA256(data)
-			if hex.EncodeToString(hash[:]) != fe.EncryptedSHA256 {
-				return fmt.Errorf("INTEGRITY FAILURE: encrypted hash mismatch for %s", fe.OriginalName)
+			check.Encrypted, check.Expected = true, fe.EncryptedSHA256
+		}
+		f, ok := index[fe.Path]
+		switch {
+		case !ok:
+			check.Status = FileStatusMissing
+			failures = append(failures, fmt.Errorf("INTEGRITY FAILURE: file missing from container: %s", fe.Path))
+		default:
+			checked[fe.Path] = true
+			if check.Computed, check.Err = hashEntry(f, b, hashing); check.Err != nil {
+				check.Status = FileStatusMismatch
+				failures = append(failures, fmt.Errorf("INTEGRITY FAILURE: reading %s: %w", fe.Path, check.Err))
+			} else if check.Computed != check.Expected {
+				check.Status = FileStatusMismatch
+				if check.Encrypted {
+					failures = append(failures, fmt.Errorf("INTEGRITY FAILURE: encrypted hash mismatch for %s", fe.OriginalName))
+				} else {
+					failures = append(failures, fmt.Errorf("INTEGRITY FAILURE: hash mismatch for %s", fe.OriginalName))
+				}
 			}
 		}
+		report.Files = append(report.Files, check)
+	}
+	if len(failures) == 1 {
+		return report, classify(ErrIntegrity, failures[0])
+	}
+	if len(failures) > 1 {
+		return report, classify(ErrIntegrity, fmt.Errorf("%w (%d of %d files failed)", failures[0], len(failures), len(records)))
 	}
 
-	return nil
+	// The sealed marker, embedded key and certificate chain are not covered
+	// by manifest hashes, so check their content directly.
+	marker, ok := index[sealedMarker]
+	if !ok {
+		return nil, classify(ErrIntegrity, errors.New("INTEGRITY FAILURE: sealed marker missing from container"))
+	}
+	checked[sealedMarker] = true
+	if data, err := readEntry(marker, b); err != nil || string(data) != "sealed" {
+		return nil, classify(ErrIntegrity, errors.New("INTEGRITY FAILURE: sealed marker is corrupt"))
+	}
+	if cf, ok := index[certChainPath]; ok {
+		checked[certChainPath] = true
+		data, err := readEntry(cf, b)
+		if err != nil {
+			return nil, classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: reading certificate chain: %w", err))
+		}
+		if chain == nil || string(data) != string(marshalCertChainPEM(chain)) {
+			return nil, classify(ErrIntegrity, errors.New("INTEGRITY FAILURE: certificate chain file does not match the signed manifest"))
+		}
+	}
+	if kf, ok := index[pubKeyPath]; ok {
+		checked[pubKeyPath] = true
+		data, err := readEntry(kf, b)
+		if err != nil {
+			return nil, classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: reading embedded key: %w", err))
+		}
+		embedded, err := imfcrypto.ParsePublicKeyPEM(data)
+		if err != nil || base64.StdEncoding.EncodeToString(embedded) != m.PublicKey {
+			return nil, classify(ErrIntegrity, errors.New("INTEGRITY FAILURE: embedded key file does not match the signed manifest"))
+		}
+	}
+
+	// Any remaining entries must still decompress cleanly; this runs the
+	// ZIP CRC-32 check so corruption anywhere in the archive is reported.
+	// Nothing signs them, so they are listed in the report, and in strict
+	// mode they fail verification: anyone can add one after sealing.
+	for _, f := range zr.File {
+		if checked[f.Name] {
+			continue
+		}
+		if _, err := hashEntry(f, b, hashing); err != nil {
+			return nil, classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: reading %s: %w", f.Name, err))
+		}
+		report.Unsigned = append(report.Unsigned, f.Name)
+	}
+	hashing.finish()
+	if opts.Strict && len(report.Unsigned) > 0 {
+		err := fmt.Errorf("INTEGRITY FAILURE: entry not covered by the signed manifest: %s", report.Unsigned[0])
+		if n := len(report.Unsigned); n > 1 {
+			err = fmt.Errorf("%w (and %d more)", err, n-1)
+		}
+		return nil, classify(ErrIntegrity, err)
+	}
+
+	// A detached signature covers the file byte for byte, so it is checked
+	// last, once the contents are known to be sound.
+	if opts.Detached != nil {
+		if err := checkDetached(opts.Detached, cf, pubKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
 }
 
 // Extract extracts files from a container to the specified output directory.
@@ -424,41 +1367,41 @@ func Verify(containerPath string, opts VerifyOptions) error {
 //
 // The plaintext hash verification during extraction is the final integrity check:
 // it ensures the decrypted content matches what was originally added before sealing.
+// Files already in the output directory are replaced, unless opts.Existing
+// says to keep them (see ExistingPolicy).
 // For unsealed containers, files are extracted directly without decryption.
 func Extract(containerPath string, opts ExtractOptions) error {
 	m, zipData, err := readContainer(containerPath)
 	if err != nil {
 		return err
 	}
+	if m, err = revealManifest(m, zipData, opts.Passphrase, opts.RecipientKey); err != nil {
+		return err
+	}
 	if !m.IsSealed() {
 		// For unsealed containers, extract plaintext files directly.
-		return extractUnsealed(m, zipData, opts.OutputDir)
+		return extractUnsealed(m, zipData, opts)
 	}
 
 	// Check expiry.
 	if m.IsExpired() && !opts.IgnoreExpiry {
-		return fmt.Errorf("container expired at %s (use --ignore-expiry to override)", m.ExpiresAt.Format(time.RFC3339))
+		return classify(ErrExpired, fmt.Errorf("container expired at %s (use --ignore-expiry to override)", m.ExpiresAt.Format(time.RFC3339)))
 	}
 
-	entries, err := readZipEntries(zipData, manifestPath, sealedMarker, pubKeyPath)
+	entries, err := readZipEntries(zipData, manifestPath, sealedMarker, pubKeyPath, certChainPath)
 	if err != nil {
 		return err
 	}
 
-	// Derive decryption key if encrypted.
-	var decKey []byte
-	if m.Encryption != nil {
-		if opts.Passphrase == "" {
-			return errors.New("container is encrypted but no passphrase provided")
-		}
-		salt, err := base64.StdEncoding.DecodeString(m.Encryption.Salt)
-		if err != nil {
-			return fmt.Errorf("decoding salt: %w", err)
-		}
-		decKey, err = imfcrypto.DeriveKey(opts.Passphrase, salt)
-		if err != nil {
-			return fmt.Errorf("deriving decryption key: %w", err)
-		}
+	// Files left in place are neither decrypted nor written.
+	todo := *m
+	if todo.Files, err = filesToExtract(m.Files, opts); err != nil {
+		return err
+	}
+	m = &todo
+	plaintexts, err := openEntries(m, entries, opts.Passphrase, opts.RecipientKey, opts.Progress)
+	if err != nil {
+		return err
 	}
 
 	// Create output directory.
@@ -466,61 +1409,271 @@ func Extract(containerPath string, opts ExtractOptions) error {
 		return fmt.Errorf("creating output directory: %w", err)
 	}
 
+	var size int64
+	for _, plaintext := range plaintexts {
+		size += int64(len(plaintext))
+	}
+	writing := opts.Progress.start(StageWrite, size)
+	for _, fe := range extractOrder(m.Files) {
+		plaintext := plaintexts[fe.Path]
+		if fe.LinkTarget != "" {
+			if err := writeExtractedLink(opts.OutputDir, fe, plaintext, opts.SymlinkPolicy); err != nil {
+				return err
+			}
+		} else if err := writeExtracted(opts.OutputDir, fe.OriginalName, plaintext); err != nil {
+			return err
+		}
+		writing.add(int64(len(plaintext)))
+	}
+
+	return nil
+}
+
+// extractOrder returns files with the regular files first and the stored
+// links after them, so that no file is written through a link the same
+// container has just created.
+func extractOrder(files []manifest.FileEntry) []manifest.FileEntry {
+	out := make([]manifest.FileEntry, 0, len(files))
+	for _, fe := range files {
+		if fe.LinkTarget == "" {
+			out = append(out, fe)
+		}
+	}
+	for _, fe := range files {
+		if fe.LinkTarget != "" {
+			out = append(out, fe)
+		}
+	}
+	return out
+}
+
+// Key derivation schemes recorded in EncryptionInfo.KDF.
+const (
+	kdfPBKDF2 = "PBKDF2-HMAC-SHA256"
+	kdfX25519 = "X25519-HKDF-SHA256"
+)
+
+// contentKey recovers the file encryption key: derived from the passphrase,
+// unwrapped from the manifest entry for recipientKey's public half, or
+// unlocked with the drand beacon for a time-locked container. Deriving the
+// key from a passphrase is reported to progress.
+func contentKey(enc *manifest.EncryptionInfo, passphrase string, recipientKey *ecdh.PrivateKey, progress Progress) ([]byte, error) {
+	if enc.KDF == kdfTimeLock {
+		return unlockContentKey(enc.TimeLock)
+	}
+	if enc.KDF == kdfX25519 {
+		if recipientKey == nil {
+			return nil, classify(ErrNoKey, errors.New("container is encrypted to recipients but no recipient key provided"))
+		}
+		pub := base64.StdEncoding.EncodeToString(recipientKey.PublicKey().Bytes())
+		for _, r := range enc.Recipients {
+			if r.PublicKey != pub {
+				continue
+			}
+			eph, err := base64.StdEncoding.DecodeString(r.EphemeralKey)
+			if err != nil {
+				return nil, fmt.Errorf("decoding ephemeral key: %w", err)
+			}
+			wrapped, err := base64.StdEncoding.DecodeString(r.WrappedKey)
+			if err != nil {
+				return nil, fmt.Errorf("decoding wrapped key: %w", err)
+			}
+			return imfcrypto.UnwrapKey(recipientKey, eph, wrapped)
+		}
+		return nil, classify(ErrNoKey, errors.New("container is not encrypted to this recipient key"))
+	}
+
+	// Derive decryption key from the passphrase.
+	if passphrase == "" {
+		return nil, classify(ErrNoKey, errors.New("container is encrypted but no passphrase provided"))
+	}
+	salt, err := base64.StdEncoding.DecodeString(enc.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("decoding salt: %w", err)
+	}
+	// Containers written before the count was recorded used the default.
+	iterations := enc.Iterations
+	if iterations == 0 {
+		iterations = imfcrypto.PBKDF2Iterations
+	}
+	key, err := progress.deriveKey(passphrase, salt, iterations)
+	if err != nil {
+		return nil, fmt.Errorf("deriving decryption key: %w", err)
+	}
+	return key, nil
+}
+
+// openEntries decrypts the file entries of a sealed container (if it is
+// encrypted) and checks each plaintext against its manifest hash. The result
+// maps each manifest path to its plaintext. Key derivation and decryption
+// are reported to progress.
+func openEntries(m *manifest.Manifest, entries map[string][]byte, passphrase string, recipientKey *ecdh.PrivateKey, progress Progress) (map[string][]byte, error) {
+	var decKey []byte
+	if m.Encryption != nil {
+		var err error
+		decKey, err = contentKey(m.Encryption, passphrase, recipientKey, progress)
+		if err != nil {
+			return nil, err
+		}
+		defer imfcrypto.Wipe(decKey)
+	}
+
+	var size int64
+	for _, fe := range m.Files {
+		size += int64(len(entries[fe.Path]))
+	}
+	decrypting := progress.start(StageDecrypt, size)
+	out := make(map[string][]byte, len(m.Files))
 	for _, fe := range m.Files {
 		data, ok := entries[fe.Path]
 		if !ok {
-			return fmt.Errorf("file missing from container: %s", fe.Path)
+			return nil, fmt.Errorf("file missing from container: %s", fe.Path)
 		}
 
-		var plaintext []byte
+		plaintext := data
 		if m.Encryption != nil {
+			var err error
 			plaintext, err = imfcrypto.Decrypt(decKey, data)
 			if err != nil {
-				return fmt.Errorf("decrypting %s: %w", fe.OriginalName, err)
+				return nil, fmt.Errorf("decrypting %s: %w", fe.OriginalName, err)
+			}
+			if m.Encryption.Padding != "" {
+				if plaintext, err = unpadPlaintext(plaintext, fe.OriginalSize, m.Encryption.Padding); err != nil {
+					return nil, classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: %s: %w", fe.OriginalName, err))
+				}
 			}
-		} else {
-			plaintext = data
 		}
 
 		// Verify plaintext hash.
 		hash := imfcrypto.HashSHA256(plaintext)
 		if hex.EncodeToString(hash[:]) != fe.SHA256 {
-			return fmt.Errorf("INTEGRITY FAILURE: hash mismatch for %s", fe.OriginalName)
-		}
-
-		outPath := filepath.Join(opts.OutputDir, fe.OriginalName)
-		if err := os.WriteFile(outPath, plaintext, 0644); err != nil {
-			return fmt.Errorf("writing %s: %w", fe.OriginalName, err)
+			return nil, classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: hash mismatch for %s", fe.OriginalName))
 		}
+		out[fe.Path] = plaintext
+		decrypting.add(int64(len(data)))
 	}
-
-	return nil
+	return out, nil
 }
 
 // ListFiles returns metadata for all files in the container.
 func ListFiles(containerPath string) ([]FileInfo, error) {
-	m, _, err := readContainer(containerPath)
+	return ListFilesWithOptions(containerPath, ListOptions{})
+}
+
+// ListFilesWithOptions is like ListFiles but can also re-hash every stored
+// entry against the manifest. This checks stored bytes only; it does not
+// verify the signature (use Verify for that).
+func ListFilesWithOptions(containerPath string, opts ListOptions) ([]FileInfo, error) {
+	m, zipData, err := readContainer(containerPath)
 	if err != nil {
 		return nil, err
 	}
+	if m, err = revealManifest(m, zipData, opts.Passphrase, opts.RecipientKey); err != nil {
+		return nil, err
+	}
+
+	var index map[string]*zip.File
+	var b *budget
+	if opts.CheckHashes {
+		zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+		if err != nil {
+			return nil, fmt.Errorf("opening zip: %w", err)
+		}
+		index = make(map[string]*zip.File, len(zr.File))
+		for _, f := range zr.File {
+			index[f.Name] = f
+		}
+		b = CurrentLimits().newBudget()
+	}
 
 	var files []FileInfo
 	for _, fe := range m.Files {
-		files = append(files, FileInfo{
-			OriginalName: fe.OriginalName,
-			OriginalSize: fe.OriginalSize,
-			SHA256:       fe.SHA256,
-		})
+		fi := FileInfo{
+			OriginalName:    fe.OriginalName,
+			OriginalSize:    fe.OriginalSize,
+			SHA256:          fe.SHA256,
+			Path:            fe.Path,
+			EncryptedSHA256: fe.EncryptedSHA256,
+			MimeType:        mimeType(fe.OriginalName),
+		}
+		if opts.CheckHashes {
+			fi.Status = entryStatus(index[fe.Path], fe, b)
+		}
+		files = append(files, fi)
 	}
 	return files, nil
 }
 
+// entryStatus hashes a stored entry and compares it with the manifest record:
+// the ciphertext hash for encrypted entries, the plaintext hash otherwise.
+func entryStatus(f *zip.File, fe manifest.FileEntry, b *budget) string {
+	if f == nil {
+		return FileStatusMissing
+	}
+	want := fe.SHA256
+	if fe.EncryptedSHA256 != "" {
+		want = fe.EncryptedSHA256
+	}
+	if got, err := hashEntry(f, b, nil); err != nil || got != want {
+		return FileStatusMismatch
+	}
+	return FileStatusOK
+}
+
+// mimeType guesses a MIME type from a file name's extension, without
+// parameters such as charset.
+func mimeType(name string) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); t != "" {
+		if mt, _, err := mime.ParseMediaType(t); err == nil {
+			return mt
+		}
+		return t
+	}
+	return "application/octet-stream"
+}
+
 // GetInfo returns container metadata.
 func GetInfo(containerPath string) (*Info, error) {
-	m, _, err := readContainer(containerPath)
+	return GetInfoWithOptions(containerPath, InfoOptions{})
+}
+
+// GetInfoWithOptions is like GetInfo, but can read a hidden manifest. Without
+// a key, a hidden container reports only what its outer header records:
+// timestamps are zero and FileCount is the number of stored entries.
+func GetInfoWithOptions(containerPath string, opts InfoOptions) (*Info, error) {
+	m, zipData, err := readContainer(containerPath)
 	if err != nil {
 		return nil, err
 	}
+	hidden := m.IsHidden()
+	fileCount := len(m.Files)
+	var policy *PolicyStatus
+	if m.Policy != nil {
+		signable, err := m.SignableBytes()
+		if err != nil {
+			return nil, fmt.Errorf("computing signable bytes: %w", err)
+		}
+		policy = policyStatus(m, signable)
+	}
+	if hidden {
+		fileCount = len(m.Envelope.Entries)
+		if opts.Passphrase != "" || opts.RecipientKey != nil {
+			if m, err = revealManifest(m, zipData, opts.Passphrase, opts.RecipientKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var chain []*x509.Certificate
+	if len(m.CertChain) > 0 {
+		if chain, err = parseCertChain(m.CertChain); err != nil {
+			return nil, err
+		}
+	}
+	var timeLock *time.Time
+	if m.Encryption != nil && m.Encryption.TimeLock != nil {
+		timeLock = &m.Encryption.TimeLock.Unlocks
+	}
 
 	return &Info{
 		State:     m.State,
@@ -529,16 +1682,39 @@ func GetInfo(containerPath string) (*Info, error) {
 		ExpiresAt: m.ExpiresAt,
 		Expired:   m.IsExpired(),
 		Encrypted: m.Encryption != nil,
+		TimeLock:  timeLock,
 		HasPubKey: m.PublicKey != "",
-		FileCount: len(m.Files),
+		Hidden:    hidden,
+		FileCount: fileCount,
+		Signer:    m.Signer,
+		CertChain: chain,
+		PQ:        pqAlgorithm(m),
+		Keyless:   len(m.Rekor) > 0,
+		Policy:    policy,
+		Metadata:  m.Metadata,
 	}, nil
 }
 
 // --- Internal helpers ---
 
+// keyListed reports whether key is one of keys.
+func keyListed(keys []ed25519.PublicKey, key ed25519.PublicKey) bool {
+	for _, k := range keys {
+		if k.Equal(key) {
+			return true
+		}
+	}
+	return false
+}
+
 // readContainer reads the manifest and raw zip bytes from a container.
 func readContainer(path string) (*manifest.Manifest, []byte, error) {
-	data, err := os.ReadFile(path)
+	obj, err := openObject(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(io.NewSectionReader(obj, 0, obj.Size()))
 	if err != nil {
 		return nil, nil, fmt.Errorf("reading container: %w", err)
 	}
@@ -548,29 +1724,80 @@ func readContainer(path string) (*manifest.Manifest, []byte, error) {
 		return nil, nil, fmt.Errorf("opening zip: %w", err)
 	}
 
+	// Reject zip bombs before decompressing anything.
+	limits := CurrentLimits()
+	if err := limits.checkArchive(zr); err != nil {
+		return nil, nil, err
+	}
+
+	m, err := loadManifest(zr, limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, data, nil
+}
+
+// loadManifest finds, reads, and parses manifest.json in an opened archive.
+func loadManifest(zr *zip.Reader, limits Limits) (*manifest.Manifest, error) {
 	for _, f := range zr.File {
 		if f.Name == manifestPath {
 			rc, err := f.Open()
 			if err != nil {
-				return nil, nil, fmt.Errorf("opening manifest: %w", err)
+				return nil, fmt.Errorf("opening manifest: %w", err)
 			}
 			defer rc.Close()
 
-			mData, err := io.ReadAll(rc)
+			mData, err := readManifestData(rc, limits)
 			if err != nil {
-				return nil, nil, fmt.Errorf("reading manifest: %w", err)
+				return nil, err
 			}
+			return manifest.Unmarshal(mData)
+		}
+	}
 
-			m, err := manifest.Unmarshal(mData)
-			if err != nil {
-				return nil, nil, err
-			}
+	return nil, errors.New("manifest.json not found in container")
+}
 
-			return m, data, nil
-		}
+// hashEntry streams a ZIP entry through SHA-256 and returns the hex digest,
+// counting the bytes read on m. Reading to EOF also makes archive/zip check
+// the entry's CRC-32.
+func hashEntry(f *zip.File, b *budget, m *meter) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	hash, err := imfcrypto.HashReaderSHA256(m.reader(b.reader(rc, f.Name)))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// readEntry reads a small ZIP entry fully into memory.
+func readEntry(f *zip.File, b *budget) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
 	}
+	defer rc.Close()
+	return b.readAll(rc, f.Name)
+}
 
-	return nil, nil, errors.New("manifest.json not found in container")
+// readManifestData reads the manifest entry, enforcing MaxManifestSize.
+func readManifestData(r io.Reader, limits Limits) ([]byte, error) {
+	if limits.MaxManifestSize > 0 {
+		r = io.LimitReader(r, limits.MaxManifestSize+1)
+	}
+	mData, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if limits.MaxManifestSize > 0 && int64(len(mData)) > limits.MaxManifestSize {
+		return nil, fmt.Errorf("%w: manifest larger than %d bytes", ErrLimitExceeded, limits.MaxManifestSize)
+	}
+	return mData, nil
 }
 
 // readZipEntries reads all entries from zip data, excluding the given paths.
@@ -580,6 +1807,12 @@ func readZipEntries(data []byte, excludePaths ...string) (map[string][]byte, err
 		return nil, fmt.Errorf("opening zip: %w", err)
 	}
 
+	limits := CurrentLimits()
+	if err := limits.checkArchive(zr); err != nil {
+		return nil, err
+	}
+	b := limits.newBudget()
+
 	excludeSet := make(map[string]bool)
 	for _, p := range excludePaths {
 		excludeSet[p] = true
@@ -594,7 +1827,7 @@ func readZipEntries(data []byte, excludePaths ...string) (map[string][]byte, err
 		if err != nil {
 			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
 		}
-		d, err := io.ReadAll(rc)
+		d, err := b.readAll(rc, f.Name)
 		rc.Close()
 		if err != nil {
 			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
@@ -606,6 +1839,12 @@ func readZipEntries(data []byte, excludePaths ...string) (map[string][]byte, err
 
 // rewriteContainer rewrites the container with updated manifest and entries.
 func rewriteContainer(path string, m *manifest.Manifest, existing map[string][]byte, newEntries map[string][]byte) error {
+	return writeContainer(path, m, existing, newEntries, nil)
+}
+
+// writeContainer is rewriteContainer, reporting the bytes of the entries
+// written to progress.
+func writeContainer(path string, m *manifest.Manifest, existing map[string][]byte, newEntries map[string][]byte, progress Progress) error {
 	mData, err := m.Marshal()
 	if err != nil {
 		return fmt.Errorf("marshaling manifest: %w", err)
@@ -617,6 +1856,15 @@ func rewriteContainer(path string, m *manifest.Manifest, existing map[string][]b
 	}
 	defer f.Close()
 
+	var size int64
+	for _, data := range existing {
+		size += int64(len(data))
+	}
+	for _, data := range newEntries {
+		size += int64(len(data))
+	}
+	writing := progress.start(StageWrite, size)
+
 	zw := zip.NewWriter(f)
 
 	// Write manifest first.
@@ -634,7 +1882,7 @@ func rewriteContainer(path string, m *manifest.Manifest, existing map[string][]b
 		if err != nil {
 			return err
 		}
-		if _, err := w.Write(data); err != nil {
+		if err := writing.write(w, data); err != nil {
 			return err
 		}
 	}
@@ -645,7 +1893,7 @@ func rewriteContainer(path string, m *manifest.Manifest, existing map[string][]b
 		if err != nil {
 			return err
 		}
-		if _, err := w.Write(data); err != nil {
+		if err := writing.write(w, data); err != nil {
 			return err
 		}
 	}
@@ -653,18 +1901,68 @@ func rewriteContainer(path string, m *manifest.Manifest, existing map[string][]b
 	return zw.Close()
 }
 
+// placeEntry resolves a name collision for a file about to be stored at
+// zipPath, returning the zip path and original name to use; a rename is left
+// to the caller to report. existing and added are the entries already in the
+// container and those added so far in this operation; under
+// CollisionOverwrite the clashing entry is dropped from the manifest and both
+// maps.
+func placeEntry(m *manifest.Manifest, existing, added map[string][]byte, zipPath string, policy CollisionPolicy) (string, string, error) {
+	baseName := strings.TrimPrefix(zipPath, filesDir)
+	prior, ok := findEntry(m, zipPath)
+	if !ok && added[zipPath] == nil {
+		return zipPath, baseName, nil
+	}
+
+	switch policy {
+	case CollisionError:
+		return "", "", fmt.Errorf("an entry named %s already exists", baseName)
+	case CollisionOverwrite:
+		// Drop the earlier entry (from this call or a previous one)
+		// so the new content takes its place.
+		if ok {
+			m.RemoveFile(prior)
+			delete(existing, prior)
+		}
+		delete(added, zipPath)
+		return zipPath, baseName, nil
+	}
+
+	// Rename: if "files/doc.pdf" already exists, try
+	// "files/doc_1.pdf", "files/doc_2.pdf", etc.
+	ext := filepath.Ext(baseName)
+	stem := strings.TrimSuffix(baseName, ext)
+	renamed := zipPath
+	for suffix := 1; entryExists(m, renamed) || added[renamed] != nil; suffix++ {
+		renamed = fmt.Sprintf("%s%s_%d%s", filesDir, stem, suffix, ext)
+	}
+	// Extract by the new name too, or the two files would
+	// overwrite each other on the way out.
+	return renamed, strings.TrimPrefix(renamed, filesDir), nil
+}
+
 // entryExists checks if a path already exists in the manifest.
 func entryExists(m *manifest.Manifest, path string) bool {
+	_, ok := findEntry(m, path)
+	return ok
+}
+
+// findEntry returns the stored path of the manifest entry matching path.
+// Paths are compared after NFC normalization so containers written before
+// names were normalized still collide correctly.
+func findEntry(m *manifest.Manifest, path string) (string, bool) {
+	want := norm.NFC.String(path)
 	for _, f := range m.Files {
-		if f.Path == path {
-			return true
+		if norm.NFC.String(f.Path) == want {
+			return f.Path, true
 		}
 	}
-	return false
+	return "", false
 }
 
 // extractUnsealed extracts files from an unsealed container (no decryption).
-func extractUnsealed(m *manifest.Manifest, zipData []byte, outputDir string) error {
+func extractUnsealed(m *manifest.Manifest, zipData []byte, opts ExtractOptions) error {
+	outputDir := opts.OutputDir
 	entries, err := readZipEntries(zipData, manifestPath)
 	if err != nil {
 		return err
@@ -674,15 +1972,174 @@ func extractUnsealed(m *manifest.Manifest, zipData []byte, outputDir string) err
 		return fmt.Errorf("creating output directory: %w", err)
 	}
 
-	for _, fe := range m.Files {
+	files, err := filesToExtract(m.Files, opts)
+	if err != nil {
+		return err
+	}
+	for _, fe := range extractOrder(files) {
 		data, ok := entries[fe.Path]
 		if !ok {
 			return fmt.Errorf("file missing from container: %s", fe.Path)
 		}
-		outPath := filepath.Join(outputDir, fe.OriginalName)
-		if err := os.WriteFile(outPath, data, 0644); err != nil {
-			return fmt.Errorf("writing %s: %w", fe.OriginalName, err)
+		if fe.LinkTarget != "" {
+			if err := writeExtractedLink(outputDir, fe, data, opts.SymlinkPolicy); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeExtracted(outputDir, fe.OriginalName, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExtracted writes one extracted file beneath outputDir. The name comes
+// from the manifest, which an attacker controls for unsealed or tampered
+// containers, so it is sanitized before touching the filesystem.
+func writeExtracted(outputDir, name string, data []byte) error {
+	outPath, err := SafeJoin(outputDir, name)
+	if err != nil {
+		return fmt.Errorf("extracting %s: %w", name, err)
+	}
+	if err := checkParents(outputDir, outPath, name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", name, err)
+	}
+	// A link already at outPath is replaced, not written through.
+	if fi, err := os.Lstat(outPath); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(outPath); err != nil {
+			return fmt.Errorf("replacing link %s: %w", name, err)
+		}
+	}
+	if err := os.WriteFile(outPath, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// checkParents refuses to write path, beneath root, through a symlink. A
+// name that is safe as a string can still lead outside root on disk if a
+// directory on the way is a link, left by an earlier extraction or created
+// by this one, so each directory between root and path that already exists
+// must be a real one.
+func checkParents(root, path, name string) error {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("extracting %s: %w", name, err)
+	}
+	if rel == "." {
+		return nil
+	}
+	dir := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, part)
+		fi, err := os.Lstat(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // the rest is created by MkdirAll, as real directories
 		}
+		if err != nil {
+			return fmt.Errorf("extracting %s: %w", name, err)
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("extracting %s: %w: %s is a symlink", name, ErrUnsafePath, filepath.ToSlash(strings.TrimPrefix(dir, root+string(filepath.Separator))))
+		}
+	}
+	return nil
+}
+
+// relativeName returns fp's slash-separated path relative to baseDir,
+// refusing anything that is not inside it.
+func relativeName(baseDir, fp string) (string, error) {
+	rel, err := filepath.Rel(baseDir, fp)
+	if err != nil {
+		return "", fmt.Errorf("%s is not under %s: %w", fp, baseDir, err)
+	}
+	name, err := SanitizePath(filepath.ToSlash(rel))
+	if err != nil {
+		return "", fmt.Errorf("%s is not under %s: %w", fp, baseDir, err)
+	}
+	return name, nil
+}
+
+// readAddSource reads a file to be added, applying the symlink policy.
+// For a stored link the returned data is the link target and linkTarget is set.
+func readAddSource(fp string, policy manifest.SymlinkPolicy) (data []byte, linkTarget string, err error) {
+	fi, err := os.Lstat(fp)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", fp, err)
+	}
+
+	if fi.Mode()&os.ModeSymlink != 0 {
+		switch policy {
+		case manifest.SymlinkReject:
+			return nil, "", fmt.Errorf("%s is a symlink and the symlink policy is reject", fp)
+		case manifest.SymlinkStore:
+			target, err := os.Readlink(fp)
+			if err != nil {
+				return nil, "", fmt.Errorf("reading link %s: %w", fp, err)
+			}
+			return []byte(target), target, nil
+		}
+	}
+
+	data, err = os.ReadFile(fp)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", fp, err)
+	}
+	return data, "", nil
+}
+
+// writeExtractedLink recreates a stored symlink beneath outputDir. The stored
+// content must match the manifest's link target, and the target must resolve
+// inside outputDir so a link cannot be used to write or read outside it.
+// Since the link's directory is a real one (see checkParents), that holds on
+// disk as well as in the string as long as ".." comes only at the start of
+// the target: "d/../x" would leave through d if d were itself a link to ".",
+// so such targets are refused.
+func writeExtractedLink(outputDir string, fe manifest.FileEntry, data []byte, policy manifest.SymlinkPolicy) error {
+	if policy == manifest.SymlinkReject {
+		return fmt.Errorf("%s is a symlink and the symlink policy is reject", fe.OriginalName)
+	}
+	if string(data) != fe.LinkTarget {
+		return classify(ErrIntegrity, fmt.Errorf("INTEGRITY FAILURE: link target mismatch for %s", fe.OriginalName))
+	}
+
+	linkPath, err := SafeJoin(outputDir, fe.OriginalName)
+	if err != nil {
+		return fmt.Errorf("extracting %s: %w", fe.OriginalName, err)
+	}
+	if filepath.IsAbs(fe.LinkTarget) || strings.HasPrefix(strings.ReplaceAll(fe.LinkTarget, `\`, "/"), "/") {
+		return fmt.Errorf("extracting %s: %w: absolute link target %q", fe.OriginalName, ErrUnsafePath, fe.LinkTarget)
+	}
+	named := false
+	for _, part := range strings.Split(strings.ReplaceAll(fe.LinkTarget, `\`, "/"), "/") {
+		switch part {
+		case "", ".":
+		case "..":
+			if named {
+				return fmt.Errorf("extracting %s: %w: link target %q has \"..\" after a name", fe.OriginalName, ErrUnsafePath, fe.LinkTarget)
+			}
+		default:
+			named = true
+		}
+	}
+	if err := checkParents(outputDir, linkPath, fe.OriginalName); err != nil {
+		return err
+	}
+	resolved := filepath.Join(filepath.Dir(linkPath), filepath.FromSlash(fe.LinkTarget))
+	if rel, err := filepath.Rel(outputDir, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("extracting %s: %w: link target %q escapes the output directory", fe.OriginalName, ErrUnsafePath, fe.LinkTarget)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", fe.OriginalName, err)
+	}
+	os.Remove(linkPath)
+	if err := os.Symlink(fe.LinkTarget, linkPath); err != nil {
+		return fmt.Errorf("creating link %s: %w", fe.OriginalName, err)
 	}
 	return nil
 }
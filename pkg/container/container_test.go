@@ -1,14 +1,27 @@
 package container_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/immutable-container/imf/pkg/container"
 	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
 )
 
 func TestFullLifecycle(t *testing.T) {
@@ -187,6 +200,9 @@ func TestExpiredContainer(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected expiry error on verify")
 	}
+	if !errors.Is(err, container.ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
 	t.Logf("✓ Expired verify rejected: %v", err)
 
 	// Verify with ignore-expiry should pass.
@@ -264,6 +280,45 @@ func TestCreateDuplicateRejected(t *testing.T) {
 	t.Log("✓ Duplicate creation rejected")
 }
 
+func TestAddDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "dry.imf")
+	if err := container.CheckCreate(filepath.Join(tmpDir, "missing", "x.imf")); err == nil {
+		t.Fatal("expected CheckCreate to reject a missing directory")
+	}
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "notes.txt")
+	os.WriteFile(src, []byte("dry run"), 0644)
+	container.Add(imfPath, []string{src})
+	before, _ := os.ReadFile(imfPath)
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests.Add(1) }))
+	defer srv.Close()
+
+	report, err := container.AddWithReport(imfPath, []string{src, srv.URL + "/report.pdf"}, container.AddOptions{
+		Collisions: container.CollisionOverwrite,
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("AddWithReport: %v", err)
+	}
+	sum := sha256.Sum256([]byte("dry run"))
+	if len(report.Files) != 2 || report.Files[0].SHA256 != hex.EncodeToString(sum[:]) || report.Files[0].Size != 7 {
+		t.Fatalf("report %+v", report.Files)
+	}
+	if f := report.Files[1]; !f.Download || f.Name != "report.pdf" || f.Size != -1 {
+		t.Fatalf("URL entry %+v", f)
+	}
+	if len(report.Replaced) != 1 || report.Replaced[0] != "notes.txt" {
+		t.Fatalf("replaced %v", report.Replaced)
+	}
+	if after, _ := os.ReadFile(imfPath); !bytes.Equal(before, after) || requests.Load() != 0 {
+		t.Fatalf("dry run modified the container or made %d request(s)", requests.Load())
+	}
+	t.Log("✓ Dry-run add reported without writing or downloading")
+}
+
 func TestEmptySealRejected(t *testing.T) {
 	tmpDir := t.TempDir()
 	imfPath := filepath.Join(tmpDir, "empty.imf")
@@ -411,3 +466,779 @@ func TestTamperDetectionByteOverwrite(t *testing.T) {
 	}
 	t.Logf("✓ 16-byte overwrite detected: %v", err)
 }
+
+func TestSymlinkPolicies(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "target.txt")
+	os.WriteFile(target, []byte("link target contents"), 0644)
+	link := filepath.Join(tmpDir, "link.txt")
+	if err := os.Symlink("target.txt", link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	// Reject refuses the link outright.
+	rejectPath := filepath.Join(tmpDir, "reject.imf")
+	container.Create(rejectPath)
+	err := container.AddWithOptions(rejectPath, []string{link}, container.AddOptions{SymlinkPolicy: manifest.SymlinkReject})
+	if err == nil {
+		t.Fatal("expected error adding symlink under reject policy")
+	}
+	t.Log("✓ Reject policy refused symlink")
+
+	// Follow (the default) copies the target's contents.
+	followPath := filepath.Join(tmpDir, "follow.imf")
+	container.Create(followPath)
+	if err := container.Add(followPath, []string{link}); err != nil {
+		t.Fatalf("Add (follow): %v", err)
+	}
+	files, _ := container.ListFiles(followPath)
+	if len(files) != 1 || files[0].OriginalSize != int64(len("link target contents")) {
+		t.Fatalf("follow policy did not copy target contents: %+v", files)
+	}
+	t.Log("✓ Follow policy copied target contents")
+
+	// Store keeps the link and recreates it on extraction, even when encrypted.
+	storePath := filepath.Join(tmpDir, "store.imf")
+	container.Create(storePath)
+	opts := container.AddOptions{SymlinkPolicy: manifest.SymlinkStore}
+	if err := container.AddWithOptions(storePath, []string{target, link}, opts); err != nil {
+		t.Fatalf("Add (store): %v", err)
+	}
+	follow := container.AddOptions{SymlinkPolicy: manifest.SymlinkFollow}
+	if err := container.AddWithOptions(storePath, []string{target}, follow); err == nil {
+		t.Fatal("expected error mixing symlink policies")
+	}
+	// With no policy given, an add uses the container's.
+	if err := container.Add(storePath, []string{target}); err != nil {
+		t.Fatalf("Add with the container's policy: %v", err)
+	}
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(storePath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, Passphrase: "links"}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	if err := container.Extract(storePath, container.ExtractOptions{Passphrase: "links", OutputDir: outDir}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	got, err := os.Readlink(filepath.Join(outDir, "link.txt"))
+	if err != nil || got != "target.txt" {
+		t.Fatalf("expected recreated link to target.txt, got %q (%v)", got, err)
+	}
+	t.Log("✓ Store policy recreated symlink on extraction")
+
+	err = container.Extract(storePath, container.ExtractOptions{
+		Passphrase:    "links",
+		OutputDir:     filepath.Join(tmpDir, "out-reject"),
+		SymlinkPolicy: manifest.SymlinkReject,
+	})
+	if err == nil {
+		t.Fatal("expected error extracting stored link under reject policy")
+	}
+	t.Log("✓ Reject policy refused stored link on extraction")
+}
+
+func TestSymlinkEscapeRejected(t *testing.T) {
+	tmpDir := t.TempDir()
+	link := filepath.Join(tmpDir, "escape")
+	if err := os.Symlink("../../etc/passwd", link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	imfPath := filepath.Join(tmpDir, "escape.imf")
+	container.Create(imfPath)
+	if err := container.AddWithOptions(imfPath, []string{link}, container.AddOptions{SymlinkPolicy: manifest.SymlinkStore}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	err := container.Extract(imfPath, container.ExtractOptions{OutputDir: filepath.Join(tmpDir, "out")})
+	if !errors.Is(err, container.ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath for escaping link, got %v", err)
+	}
+	t.Log("✓ Link escaping the output directory rejected")
+}
+
+// TestSymlinkChainEscapeRejected extracts a container whose names are each
+// safe as strings, but which would write outside the output directory
+// through links it creates itself: "a" links to ".", "a/b" (that is, "b")
+// to "..", and "a/b/x" would then land beside the output directory.
+func TestSymlinkChainEscapeRejected(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	os.Mkdir(src, 0755)
+	if err := os.Symlink(".", filepath.Join(src, "a")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	os.Symlink("..", filepath.Join(src, "b"))
+	os.WriteFile(filepath.Join(tmpDir, "x"), []byte("outside"), 0644)
+
+	imfPath := filepath.Join(tmpDir, "chain.imf")
+	container.Create(imfPath)
+	paths := []string{filepath.Join(src, "a"), filepath.Join(src, "a", "b"), filepath.Join(src, "a", "b", "x")}
+	if err := container.AddWithOptions(imfPath, paths, container.AddOptions{SymlinkPolicy: manifest.SymlinkStore, BaseDir: src}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	var names []string
+	for _, f := range readManifest(t, imfPath).Files {
+		names = append(names, f.OriginalName)
+	}
+	if strings.Join(names, " ") != "a a/b a/b/x" {
+		t.Fatalf("unexpected names %q", names)
+	}
+
+	parent := filepath.Join(tmpDir, "dest")
+	os.Mkdir(parent, 0755)
+	err := container.Extract(imfPath, container.ExtractOptions{OutputDir: filepath.Join(parent, "out")})
+	if err == nil {
+		t.Fatal("expected the chain of links to be refused")
+	}
+	if _, serr := os.Lstat(filepath.Join(parent, "x")); serr == nil {
+		t.Fatal("SECURITY FAILURE: file written outside the output directory")
+	}
+	t.Logf("✓ Chain of links refused: %v", err)
+
+	// A link left in the output directory is not written through either.
+	out := filepath.Join(parent, "out2")
+	os.Mkdir(out, 0755)
+	os.Symlink("..", filepath.Join(out, "a"))
+	files := filepath.Join(tmpDir, "files")
+	os.MkdirAll(filepath.Join(files, "a"), 0755)
+	os.WriteFile(filepath.Join(files, "a", "y"), []byte("y"), 0644)
+	plain := filepath.Join(tmpDir, "plain.imf")
+	container.Create(plain)
+	if err := container.AddWithOptions(plain, []string{filepath.Join(files, "a", "y")}, container.AddOptions{BaseDir: files}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	err = container.Extract(plain, container.ExtractOptions{OutputDir: out})
+	if !errors.Is(err, container.ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath writing through a link, got %v", err)
+	}
+	if _, serr := os.Lstat(filepath.Join(parent, "y")); serr == nil {
+		t.Fatal("SECURITY FAILURE: file written through a link in the output directory")
+	}
+}
+
+func TestAddFromURL(t *testing.T) {
+	body := "remote report contents"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/report.pdf":
+			w.Write([]byte(body))
+		case "/big":
+			w.Write(make([]byte, 2048))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "remote.imf")
+	container.Create(imfPath)
+
+	before := time.Now().UTC().Add(-time.Second)
+	if err := container.Add(imfPath, []string{srv.URL + "/report.pdf"}); err != nil {
+		t.Fatalf("Add URL: %v", err)
+	}
+
+	m := readManifest(t, imfPath)
+	if len(m.Files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(m.Files))
+	}
+	fe := m.Files[0]
+	if fe.OriginalName != "report.pdf" || fe.OriginalSize != int64(len(body)) {
+		t.Fatalf("unexpected entry: %+v", fe)
+	}
+	if fe.SourceURL != srv.URL+"/report.pdf" {
+		t.Fatalf("source URL not recorded: %q", fe.SourceURL)
+	}
+	if fe.RetrievedAt == nil || fe.RetrievedAt.Before(before) {
+		t.Fatalf("retrieval time not recorded: %v", fe.RetrievedAt)
+	}
+	t.Log("✓ URL downloaded with provenance recorded")
+
+	err := container.AddWithOptions(imfPath, []string{srv.URL + "/big"}, container.AddOptions{MaxDownloadSize: 1024})
+	if err == nil {
+		t.Fatal("expected error for download over size limit")
+	}
+	t.Logf("✓ Oversized download rejected: %v", err)
+
+	if err := container.Add(imfPath, []string{srv.URL + "/missing"}); err == nil {
+		t.Fatal("expected error for 404 download")
+	}
+	t.Log("✓ Failed download rejected")
+}
+
+// readManifest returns the raw manifest of a container for assertions on
+// fields not surfaced through the container API.
+func readManifest(t *testing.T, imfPath string) *manifest.Manifest {
+	t.Helper()
+	zr, err := zip.OpenReader(imfPath)
+	if err != nil {
+		t.Fatalf("opening container: %v", err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.Name != "manifest.json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening manifest: %v", err)
+		}
+		defer rc.Close()
+		data, _ := io.ReadAll(rc)
+		m, err := manifest.Unmarshal(data)
+		if err != nil {
+			t.Fatalf("parsing manifest: %v", err)
+		}
+		return m
+	}
+	t.Fatal("manifest.json not found")
+	return nil
+}
+
+func TestUnicodeNormalizationAndCollisions(t *testing.T) {
+	tmpDir := t.TempDir()
+	nfc := "café.txt"  // é as a single code point
+	nfd := "café.txt" // e + combining acute, as macOS reports it
+
+	nfcDir := filepath.Join(tmpDir, "a")
+	nfdDir := filepath.Join(tmpDir, "b")
+	os.MkdirAll(nfcDir, 0755)
+	os.MkdirAll(nfdDir, 0755)
+	os.WriteFile(filepath.Join(nfcDir, nfc), []byte("first"), 0644)
+	os.WriteFile(filepath.Join(nfdDir, nfd), []byte("second"), 0644)
+
+	// Rename (default): the NFD name collides with the NFC one.
+	renamePath := filepath.Join(tmpDir, "rename.imf")
+	container.Create(renamePath)
+	report, err := container.AddWithReport(renamePath, []string{filepath.Join(nfcDir, nfc), filepath.Join(nfdDir, nfd)}, container.AddOptions{})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if len(report.Renamed) != 1 || report.Renamed["café_1.txt"] != nfc {
+		t.Fatalf("Renamed = %v, want café_1.txt from %s", report.Renamed, nfc)
+	}
+	m := readManifest(t, renamePath)
+	if len(m.Files) != 2 || m.Files[1].OriginalName != "café_1.txt" {
+		t.Fatalf("expected NFD name renamed to café_1.txt, got %+v", m.Files)
+	}
+	if m.Files[1].OriginalForm != nfd {
+		t.Fatalf("expected original NFD form recorded, got %q", m.Files[1].OriginalForm)
+	}
+	t.Log("✓ NFD name normalized, collision renamed, original form recorded")
+
+	// A name changed only to avoid a collision has no original form.
+	if err := container.Add(renamePath, []string{filepath.Join(nfcDir, nfc)}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if f := readManifest(t, renamePath).Files[2]; f.OriginalName != "café_2.txt" || f.OriginalForm != "" {
+		t.Fatalf("expected café_2.txt with no original form, got %+v", f)
+	}
+
+	// Error: the second add fails and leaves the container untouched.
+	errorPath := filepath.Join(tmpDir, "error.imf")
+	container.Create(errorPath)
+	container.Add(errorPath, []string{filepath.Join(nfcDir, nfc)})
+	err = container.AddWithOptions(errorPath, []string{filepath.Join(nfdDir, nfd)}, container.AddOptions{Collisions: container.CollisionError})
+	if err == nil {
+		t.Fatal("expected collision error")
+	}
+	if files, _ := container.ListFiles(errorPath); len(files) != 1 {
+		t.Fatalf("expected 1 file after failed add, got %d", len(files))
+	}
+	t.Log("✓ Error policy rejected collision")
+
+	// Overwrite: the second file replaces the first.
+	overwritePath := filepath.Join(tmpDir, "overwrite.imf")
+	container.Create(overwritePath)
+	container.Add(overwritePath, []string{filepath.Join(nfcDir, nfc)})
+	if err := container.AddWithOptions(overwritePath, []string{filepath.Join(nfdDir, nfd)}, container.AddOptions{Collisions: container.CollisionOverwrite}); err != nil {
+		t.Fatalf("Add overwrite: %v", err)
+	}
+	outDir := filepath.Join(tmpDir, "out")
+	if err := container.Extract(overwritePath, container.ExtractOptions{OutputDir: outDir}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(outDir, nfc))
+	if string(data) != "second" {
+		t.Fatalf("expected overwritten content, got %q", data)
+	}
+	t.Log("✓ Overwrite policy replaced existing entry")
+}
+
+// TestVerifyDetectsPlaintextSwap rebuilds an unencrypted sealed container with
+// one file's content replaced but the signed manifest intact.
+func TestVerifyDetectsPlaintextSwap(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "swap.imf")
+
+	container.Create(imfPath)
+	testFile := filepath.Join(tmpDir, "plain.txt")
+	os.WriteFile(testFile, []byte("original plaintext"), 0644)
+	container.Add(imfPath, []string{testFile})
+	kp, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true})
+
+	zr, err := zip.OpenReader(imfPath)
+	if err != nil {
+		t.Fatalf("opening container: %v", err)
+	}
+	swapped := filepath.Join(tmpDir, "swapped.imf")
+	out, _ := os.Create(swapped)
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == "files/plain.txt" {
+			data = []byte("forged plaintext!!")
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(data)
+	}
+	zw.Close()
+	out.Close()
+	zr.Close()
+
+	err = container.Verify(swapped, container.VerifyOptions{})
+	if err == nil {
+		t.Fatal("SECURITY FAILURE: Verification passed with swapped plaintext")
+	}
+	if !errors.Is(err, container.ErrIntegrity) {
+		t.Fatalf("expected an integrity failure, got %v", err)
+	}
+	t.Logf("✓ Plaintext swap detected: %v", err)
+}
+
+func TestVerifyReportsEveryFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "report.imf")
+
+	container.Create(imfPath)
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		p := filepath.Join(tmpDir, name)
+		os.WriteFile(p, []byte("contents of "+name), 0644)
+		paths = append(paths, p)
+	}
+	container.Add(imfPath, paths)
+	kp, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, Passphrase: "pw"})
+
+	report, err := container.VerifyWithReport(imfPath, container.VerifyOptions{})
+	if err != nil || len(report.Files) != 3 {
+		t.Fatalf("VerifyWithReport: %v, %+v", err, report)
+	}
+	for _, f := range report.Files {
+		if f.Status != container.FileStatusOK || !f.Encrypted || f.Computed != f.Expected {
+			t.Fatalf("sound file reported as %+v", f)
+		}
+	}
+
+	// Corrupt one entry and drop another: both are reported, and the
+	// file between them still checks out.
+	zr, err := zip.OpenReader(imfPath)
+	if err != nil {
+		t.Fatalf("opening container: %v", err)
+	}
+	damaged := filepath.Join(tmpDir, "damaged.imf")
+	out, _ := os.Create(damaged)
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		switch f.Name {
+		case "files/a.txt.enc":
+			data[len(data)-1] ^= 1
+		case "files/c.txt.enc":
+			continue
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(data)
+	}
+	zw.Close()
+	out.Close()
+	zr.Close()
+
+	report, err = container.VerifyWithReport(damaged, container.VerifyOptions{})
+	if !errors.Is(err, container.ErrIntegrity) || !strings.Contains(err.Error(), "2 of 3 files failed") {
+		t.Fatalf("expected an integrity failure for 2 files, got %v", err)
+	}
+	if report == nil || len(report.Files) != 3 {
+		t.Fatalf("expected all 3 files reported, got %+v", report)
+	}
+	want := []string{container.FileStatusMismatch, container.FileStatusOK, container.FileStatusMissing}
+	for i, f := range report.Files {
+		if f.Status != want[i] {
+			t.Fatalf("%s: status %s, want %s", f.Name, f.Status, want[i])
+		}
+	}
+	if a := report.Files[0]; a.Computed == "" || a.Computed == a.Expected {
+		t.Fatalf("mismatch reported without the computed hash: %+v", a)
+	}
+	if c := report.Files[2]; c.Computed != "" || c.Expected == "" {
+		t.Fatalf("missing file reported as %+v", c)
+	}
+	t.Logf("✓ Every file reported: %v", err)
+}
+
+func TestFailureKinds(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "kinds.imf")
+	container.Create(imfPath)
+	testFile := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(testFile, []byte("classified"), 0644)
+	container.Add(imfPath, []string{testFile})
+	kp, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, Passphrase: "pw"}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	other, _ := imfcrypto.GenerateKeyPair()
+	err := container.Verify(imfPath, container.VerifyOptions{PublicKey: other.PublicKey})
+	if !errors.Is(err, container.ErrSignature) || errors.Is(err, container.ErrIntegrity) {
+		t.Fatalf("wrong key: expected only ErrSignature, got %v", err)
+	}
+	if err.Error() != "SIGNATURE VERIFICATION FAILED — container may be tampered" {
+		t.Fatalf("classifying changed the message: %q", err)
+	}
+
+	out := filepath.Join(tmpDir, "out")
+	err = container.Extract(imfPath, container.ExtractOptions{OutputDir: out})
+	if !errors.Is(err, container.ErrNoKey) {
+		t.Fatalf("no passphrase: expected ErrNoKey, got %v", err)
+	}
+	err = container.Extract(imfPath, container.ExtractOptions{OutputDir: out, Passphrase: "wrong"})
+	if !errors.Is(err, imfcrypto.ErrDecrypt) {
+		t.Fatalf("wrong passphrase: expected ErrDecrypt, got %v", err)
+	}
+	t.Log("✓ Signature, missing-key, and wrong-passphrase failures are told apart")
+}
+
+func TestListFilesWithHashCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "list.imf")
+
+	container.Create(imfPath)
+	testFile := filepath.Join(tmpDir, "notes.txt")
+	os.WriteFile(testFile, []byte("listing test"), 0644)
+	container.Add(imfPath, []string{testFile})
+	kp, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, Passphrase: "list"})
+
+	files, err := container.ListFilesWithOptions(imfPath, container.ListOptions{CheckHashes: true})
+	if err != nil {
+		t.Fatalf("ListFilesWithOptions: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+	f := files[0]
+	if f.Path != "files/notes.txt.enc" || f.EncryptedSHA256 == "" {
+		t.Fatalf("expected encrypted zip path and hash, got %+v", f)
+	}
+	if f.MimeType != "text/plain" {
+		t.Fatalf("unexpected MIME type %q", f.MimeType)
+	}
+	if f.Status != container.FileStatusOK {
+		t.Fatalf("expected status ok, got %q", f.Status)
+	}
+	t.Log("✓ Long listing fields and hash status populated")
+}
+
+func TestSealDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "dryrun.imf")
+
+	container.Create(imfPath)
+	kp, _ := imfcrypto.GenerateKeyPair()
+
+	// Validation failures are reported even in a dry run.
+	if _, err := container.SealWithReport(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, DryRun: true}); err == nil {
+		t.Fatal("expected empty-container error in dry run")
+	}
+	testFile := filepath.Join(tmpDir, "doc.txt")
+	os.WriteFile(testFile, []byte("dry run"), 0644)
+	container.Add(imfPath, []string{testFile})
+	if _, err := container.SealWithReport(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey[:10], DryRun: true}); err == nil {
+		t.Fatal("expected invalid-key error in dry run")
+	}
+
+	before, _ := os.ReadFile(imfPath)
+	report, err := container.SealWithReport(imfPath, container.SealOptions{
+		PrivateKey:  kp.PrivateKey,
+		EmbedPubKey: true,
+		Passphrase:  "dry",
+		DryRun:      true,
+	})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	after, _ := os.ReadFile(imfPath)
+	if string(before) != string(after) {
+		t.Fatal("dry run modified the container")
+	}
+	if !report.DryRun || !report.Encrypted || len(report.Files) != 1 || report.Files[0].Path != "files/doc.txt.enc" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	t.Log("✓ Dry run validated and reported without modifying the container")
+}
+
+func TestSealKDFIterations(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "kdf.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("tuned"), 0644)
+	container.Add(imfPath, []string{src})
+	kp, _ := imfcrypto.GenerateKeyPair()
+
+	weak := container.SealOptions{PrivateKey: kp.PrivateKey, Passphrase: "pw", Iterations: 5000}
+	if err := container.Seal(imfPath, weak); err == nil {
+		t.Fatal("expected sealing with too few iterations to fail")
+	}
+
+	opts := container.SealOptions{PrivateKey: kp.PrivateKey, Passphrase: "pw", Iterations: 150000}
+	if err := container.Seal(imfPath, opts); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if m := readManifest(t, imfPath); m.Encryption.Iterations != 150000 {
+		t.Fatalf("manifest records %d iterations, want 150000", m.Encryption.Iterations)
+	}
+	outDir := filepath.Join(tmpDir, "out")
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: outDir, Passphrase: "pw"}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	t.Logf("✓ KDF iterations recorded and honored on extract")
+}
+
+// tokenSigner stands in for a hardware token: it signs without exposing
+// its key and counts how often it was asked to.
+type tokenSigner struct {
+	kp    *imfcrypto.KeyPair
+	calls int
+}
+
+func (s *tokenSigner) Public() ed25519.PublicKey { return s.kp.PublicKey }
+
+func (s *tokenSigner) Sign(message []byte) ([]byte, error) {
+	s.calls++
+	return imfcrypto.Sign(s.kp.PrivateKey, message), nil
+}
+
+func TestSealWithSigner(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "token.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("signed on a token"), 0644)
+	container.Add(imfPath, []string{src})
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	token := &tokenSigner{kp: kp}
+	if err := container.Seal(imfPath, container.SealOptions{Signer: token, EmbedPubKey: true}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if token.calls != 1 {
+		t.Fatalf("token asked to sign %d times, want 1", token.calls)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{PublicKey: kp.PublicKey}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	t.Logf("✓ Seal signed through a Signer without a private key")
+}
+
+func TestSignerIdentity(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "signed.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("who sealed this?"), 0644)
+	container.Add(imfPath, []string{src})
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	err := container.Seal(imfPath, container.SealOptions{
+		PrivateKey:  kp.PrivateKey,
+		EmbedPubKey: true,
+		SignerName:  "Ada Lovelace",
+		SignerEmail: "ada@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	fingerprint := imfcrypto.Fingerprint(kp.PublicKey)
+	report, err := container.VerifyWithReport(imfPath, container.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyWithReport: %v", err)
+	}
+	if s := report.Signer; s == nil || s.Name != "Ada Lovelace" || s.Email != "ada@example.com" || s.Fingerprint != fingerprint {
+		t.Fatalf("unexpected signer: %+v", report.Signer)
+	}
+	info, err := container.GetInfo(imfPath)
+	if err != nil || info.Signer == nil || info.Signer.Fingerprint != fingerprint {
+		t.Fatalf("GetInfo signer: %+v, %v", info, err)
+	}
+
+	// A fingerprint naming another key is rejected even when the manifest
+	// is validly signed.
+	other, _ := imfcrypto.GenerateKeyPair()
+	m := readManifest(t, imfPath)
+	m.Signer.Fingerprint = imfcrypto.Fingerprint(other.PublicKey)
+	signable, _ := m.SignableBytes()
+	m.Signature = base64.StdEncoding.EncodeToString(imfcrypto.Sign(kp.PrivateKey, signable))
+	data, _ := m.Marshal()
+	forged := filepath.Join(tmpDir, "forged.imf")
+	replaceEntry(t, imfPath, forged, "manifest.json", data)
+	if err := container.Verify(forged, container.VerifyOptions{}); err == nil {
+		t.Fatal("expected a mismatched signer fingerprint to fail verification")
+	}
+
+	t.Logf("✓ Signer identity recorded, reported, and bound to the signing key")
+}
+
+func TestVerifyRequireTrusted(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "resealed.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("from a known signer?"), 0644)
+	container.Add(imfPath, []string{src})
+
+	// An attacker seals with their own key and embeds it.
+	attacker, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: attacker.PrivateKey, EmbedPubKey: true})
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("embedded-key verification: %v", err)
+	}
+
+	publisher, _ := imfcrypto.GenerateKeyPair()
+	opts := container.VerifyOptions{RequireTrusted: true, TrustedKeys: []ed25519.PublicKey{publisher.PublicKey}}
+	if err := container.Verify(imfPath, opts); !errors.Is(err, container.ErrUntrustedKey) {
+		t.Fatalf("expected ErrUntrustedKey, got %v", err)
+	}
+
+	opts.TrustedKeys = append(opts.TrustedKeys, attacker.PublicKey)
+	if err := container.Verify(imfPath, opts); err != nil {
+		t.Fatalf("trusted key rejected: %v", err)
+	}
+	t.Logf("✓ Embedded key accepted only when it is in the trust store")
+}
+
+func TestVerifyStrictRejectsUnsignedEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "strict.imf")
+
+	container.Create(imfPath)
+	testFile := filepath.Join(tmpDir, "doc.txt")
+	os.WriteFile(testFile, []byte("signed contents"), 0644)
+	container.Add(imfPath, []string{testFile})
+	kp, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true})
+
+	report, err := container.VerifyWithReport(imfPath, container.VerifyOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("strict verify of an untouched container: %v", err)
+	}
+	if len(report.Unsigned) != 0 {
+		t.Fatalf("untouched container has unsigned entries %v", report.Unsigned)
+	}
+
+	// Smuggle an extra entry in after sealing, keeping the rest intact.
+	zr, err := zip.OpenReader(imfPath)
+	if err != nil {
+		t.Fatalf("opening container: %v", err)
+	}
+	smuggled := filepath.Join(tmpDir, "smuggled.imf")
+	out, _ := os.Create(smuggled)
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		if err := zw.Copy(f); err != nil {
+			t.Fatalf("copying %s: %v", f.Name, err)
+		}
+	}
+	w, _ := zw.Create("files/payload.exe")
+	w.Write([]byte("not signed by anyone"))
+	zw.Close()
+	out.Close()
+	zr.Close()
+
+	report, err = container.VerifyWithReport(smuggled, container.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("lenient verify: %v", err)
+	}
+	if len(report.Unsigned) != 1 || report.Unsigned[0] != "files/payload.exe" {
+		t.Fatalf("unsigned entries = %v, want [files/payload.exe]", report.Unsigned)
+	}
+	err = container.Verify(smuggled, container.VerifyOptions{Strict: true})
+	if !errors.Is(err, container.ErrIntegrity) || !strings.Contains(err.Error(), "files/payload.exe") {
+		t.Fatalf("strict verify: got %v, want an integrity failure naming the entry", err)
+	}
+	t.Logf("✓ Unsigned entry reported, and rejected in strict mode: %v", err)
+}
+
+func TestExtractExistingPolicies(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "resume.imf")
+	container.Create(imfPath)
+	var srcs []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		p := filepath.Join(tmpDir, name)
+		os.WriteFile(p, []byte("contents of "+name), 0644)
+		srcs = append(srcs, p)
+	}
+	container.Add(imfPath, srcs)
+	kp, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, Passphrase: "correct horse battery"})
+
+	out := filepath.Join(tmpDir, "out")
+	extract := func(policy container.ExistingPolicy) []string {
+		t.Helper()
+		var kept []string
+		err := container.Extract(imfPath, container.ExtractOptions{
+			Passphrase: "correct horse battery",
+			OutputDir:  out,
+			Existing:   policy,
+			Kept:       func(name string) { kept = append(kept, name) },
+		})
+		if err != nil {
+			t.Fatalf("Extract (%s): %v", policy, err)
+		}
+		return kept
+	}
+	extract("")
+
+	// Simulate an interrupted run: one file cut short, one never written,
+	// one complete.
+	os.WriteFile(filepath.Join(out, "a.txt"), []byte("contents"), 0644)
+	os.Remove(filepath.Join(out, "b.txt"))
+
+	if kept := extract(container.ExistingResume); len(kept) != 1 || kept[0] != "c.txt" {
+		t.Fatalf("resume kept %v, want [c.txt]", kept)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if got, _ := os.ReadFile(filepath.Join(out, name)); string(got) != "contents of "+name {
+			t.Fatalf("%s after resume = %q", name, got)
+		}
+	}
+
+	// Skip leaves even a modified file alone; overwrite replaces it.
+	os.WriteFile(filepath.Join(out, "a.txt"), []byte("edited"), 0644)
+	if kept := extract(container.ExistingSkip); len(kept) != 3 {
+		t.Fatalf("skip kept %v, want all three", kept)
+	}
+	if got, _ := os.ReadFile(filepath.Join(out, "a.txt")); string(got) != "edited" {
+		t.Fatalf("skip replaced a.txt with %q", got)
+	}
+	if kept := extract(container.ExistingOverwrite); len(kept) != 0 {
+		t.Fatalf("overwrite kept %v", kept)
+	}
+	if got, _ := os.ReadFile(filepath.Join(out, "a.txt")); string(got) != "contents of a.txt" {
+		t.Fatalf("overwrite left a.txt as %q", got)
+	}
+	t.Log("✓ Extract resumes, skips, and overwrites existing files as asked")
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// CopyOptions configures CopyFiles.
+type CopyOptions struct {
+	Passphrase   string           // decrypts the source, if it is encrypted with a passphrase
+	RecipientKey *ecdh.PrivateKey // decrypts the source, if it is encrypted to recipients
+	Verify       VerifyOptions    // public key and expiry handling for a sealed source
+	Collisions   CollisionPolicy  // what to do when a name already exists in the destination
+}
+
+// CopyReport says what CopyFiles stored.
+type CopyReport struct {
+	Names   []string          // the names the copied files are stored under, in the order given
+	Renamed map[string]string // under CollisionRename, the source name of each renamed file, by the name stored
+}
+
+// CopyFiles copies the named files from srcContainer into the open container
+// dstContainer. Names are the original names shown by ListFiles. A sealed
+// source is verified first; every copied entry's plaintext is checked against
+// its recorded hash before the destination is touched.
+//
+// The recorded metadata (original name and form, size, hash, link target,
+// source URL, retrieval time) is carried over, so a subset curated from a
+// large archive keeps its provenance.
+func CopyFiles(srcContainer, dstContainer string, names []string, opts CopyOptions) error {
+	_, err := CopyFilesWithReport(srcContainer, dstContainer, names, opts)
+	return err
+}
+
+// CopyFilesWithReport is CopyFiles, returning what was copied.
+func CopyFilesWithReport(srcContainer, dstContainer string, names []string, opts CopyOptions) (*CopyReport, error) {
+	if len(names) == 0 {
+		return nil, errors.New("no files to copy")
+	}
+	collisions, err := ParseCollisionPolicy(string(opts.Collisions))
+	if err != nil {
+		return nil, err
+	}
+
+	src, srcData, err := readContainer(srcContainer)
+	if err != nil {
+		return nil, err
+	}
+	if src.IsSealed() {
+		if err := Verify(srcContainer, opts.Verify); err != nil {
+			return nil, fmt.Errorf("verifying %s: %w", srcContainer, err)
+		}
+	}
+	if src, err = revealManifest(src, srcData, opts.Passphrase, opts.RecipientKey); err != nil {
+		return nil, err
+	}
+
+	// Select the requested entries, in the order given.
+	byName := make(map[string]manifest.FileEntry, len(src.Files))
+	for _, fe := range src.Files {
+		byName[norm.NFC.String(fe.OriginalName)] = fe
+	}
+	selected := &manifest.Manifest{Encryption: src.Encryption}
+	for _, name := range names {
+		fe, ok := byName[norm.NFC.String(name)]
+		if !ok {
+			return nil, fmt.Errorf("file not found in %s: %s", srcContainer, name)
+		}
+		selected.Files = append(selected.Files, fe)
+	}
+
+	srcEntries, err := readZipEntries(srcData, manifestPath, sealedMarker, pubKeyPath, certChainPath)
+	if err != nil {
+		return nil, err
+	}
+	plaintexts, err := openEntries(selected, srcEntries, opts.Passphrase, opts.RecipientKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockContainer(dstContainer)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	m, zipData, err := readContainer(dstContainer)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsSealed() {
+		return nil, errors.New("cannot add files to a sealed container")
+	}
+	existingEntries, err := readZipEntries(zipData, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CopyReport{}
+	newEntries := make(map[string][]byte)
+	for _, fe := range selected.Files {
+		// Stored links only make sense in a container populated under the
+		// store policy; record it if the destination has none yet.
+		if fe.LinkTarget != "" {
+			if m.SymlinkPolicy == "" {
+				m.SymlinkPolicy = manifest.SymlinkStore
+			} else if m.SymlinkPolicy != manifest.SymlinkStore {
+				return nil, fmt.Errorf("%s is a stored symlink but the destination symlink policy is %q", fe.OriginalName, m.SymlinkPolicy)
+			}
+		}
+
+		data := plaintexts[fe.Path]
+		zipPath, name, err := placeEntry(m, existingEntries, newEntries, filesDir+norm.NFC.String(fe.OriginalName), collisions)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fe.OriginalName, err)
+		}
+		if fe.OriginalForm == "" && norm.NFC.String(fe.OriginalName) != fe.OriginalName {
+			fe.OriginalForm = fe.OriginalName
+		}
+		if asked := norm.NFC.String(fe.OriginalName); name != asked {
+			if report.Renamed == nil {
+				report.Renamed = make(map[string]string)
+			}
+			report.Renamed[name] = asked
+		}
+		report.Names = append(report.Names, name)
+		fe.Path = zipPath
+		fe.OriginalName = name
+		fe.EncryptedSHA256 = ""
+		if err := m.AddFile(fe); err != nil {
+			return nil, fmt.Errorf("adding %s to manifest: %w", name, err)
+		}
+		newEntries[zipPath] = data
+	}
+
+	if err := rewriteContainer(dstContainer, m, existingEntries, newEntries); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// DetachedSignature is a signature over the exact bytes of a container file,
+// kept beside it (conventionally as NAME.imf.sig). The manifest signature
+// covers only the manifest and, through its hashes, the stored files; a
+// detached signature also covers the ZIP structure and everything added
+// after sealing, such as cosignatures and witnesses, so the whole artifact
+// can be attested. It must be made by the key the container verifies under.
+type DetachedSignature struct {
+	PublicKey string    `json:"public_key"` // base64-encoded Ed25519 public key
+	SHA256    string    `json:"sha256"`     // hex SHA-256 of the container file
+	Size      int64     `json:"size"`       // size of the container file in bytes
+	SignedAt  time.Time `json:"signed_at"`  // when it was signed, by the signer's clock
+	Signature string    `json:"signature"`  // base64-encoded Ed25519 signature
+}
+
+// SignFile signs the container file at containerPath with signer. The
+// container must be sealed and verify under the signer's key. Any later
+// change to the file, including a cosignature or witness, invalidates the
+// detached signature.
+func SignFile(containerPath string, signer imfcrypto.Signer) (*DetachedSignature, error) {
+	unlock, err := lockContainer(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := Verify(containerPath, VerifyOptions{PublicKey: signer.Public(), IgnoreExpiry: true}); err != nil {
+		return nil, fmt.Errorf("container does not verify under the signing key: %w", err)
+	}
+	obj, err := openObject(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	digest, err := hashObject(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DetachedSignature{
+		PublicKey: base64.StdEncoding.EncodeToString(signer.Public()),
+		SHA256:    digest,
+		Size:      obj.Size(),
+		SignedAt:  time.Now().UTC(),
+	}
+	db, err := d.SignableBytes()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(db)
+	if err != nil {
+		return nil, fmt.Errorf("signing container: %w", err)
+	}
+	d.Signature = base64.StdEncoding.EncodeToString(sig)
+	return d, nil
+}
+
+// SignableBytes returns the bytes the signer signs: the JSON form of d with
+// the signature field zeroed out.
+func (d *DetachedSignature) SignableBytes() ([]byte, error) {
+	cp := *d
+	cp.Signature = ""
+	return json.Marshal(cp)
+}
+
+// ParseDetachedSignature decodes a detached signature file.
+func ParseDetachedSignature(data []byte) (*DetachedSignature, error) {
+	var d DetachedSignature
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("parsing detached signature: %w", err)
+	}
+	return &d, nil
+}
+
+// checkDetached verifies that d is pubKey's signature over the container
+// file obj.
+func checkDetached(d *DetachedSignature, obj Object, pubKey ed25519.PublicKey) error {
+	key, err := base64.StdEncoding.DecodeString(d.PublicKey)
+	if err != nil || !bytes.Equal(key, pubKey) {
+		return classify(ErrSignature, errors.New("DETACHED SIGNATURE VERIFICATION FAILED: not made by the container's signing key"))
+	}
+	sig, err := base64.StdEncoding.DecodeString(d.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding detached signature: %w", err)
+	}
+	db, err := d.SignableBytes()
+	if err != nil {
+		return err
+	}
+	if !imfcrypto.Verify(pubKey, db, sig) {
+		return classify(ErrSignature, errors.New("DETACHED SIGNATURE VERIFICATION FAILED: bad signature"))
+	}
+	if obj.Size() != d.Size {
+		return classify(ErrSignature, errors.New("DETACHED SIGNATURE VERIFICATION FAILED: container file size differs from the signed size"))
+	}
+	digest, err := hashObject(obj)
+	if err != nil {
+		return err
+	}
+	if digest != d.SHA256 {
+		return classify(ErrSignature, errors.New("DETACHED SIGNATURE VERIFICATION FAILED: container file differs from the signed bytes"))
+	}
+	return nil
+}
+
+// hashObject returns the hex SHA-256 of obj's full contents.
+func hashObject(obj Object) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(obj, 0, obj.Size())); err != nil {
+		return "", fmt.Errorf("reading container: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,54 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestDetachedSignature(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "release.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "app.tar")
+	os.WriteFile(src, []byte("release artifact"), 0644)
+	container.Add(imfPath, []string{src})
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	signer, _ := imfcrypto.NewKeySigner(kp.PrivateKey)
+	container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true})
+
+	// Only the key the container verifies under may sign it.
+	other, _ := imfcrypto.GenerateKeyPair()
+	otherSigner, _ := imfcrypto.NewKeySigner(other.PrivateKey)
+	if _, err := container.SignFile(imfPath, otherSigner); err == nil {
+		t.Fatal("expected signing with another key to fail")
+	}
+
+	d, err := container.SignFile(imfPath, signer)
+	if err != nil {
+		t.Fatalf("SignFile: %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{Detached: d}); err != nil {
+		t.Fatalf("Verify with detached signature: %v", err)
+	}
+
+	// A witness added afterwards leaves the manifest valid but changes the
+	// file, so the detached signature no longer matches.
+	witness, _ := imfcrypto.GenerateKeyPair()
+	if _, err := container.Witness(imfPath, witness.PrivateKey, container.WitnessOptions{}); err != nil {
+		t.Fatalf("Witness: %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify after witness: %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{Detached: d}); err == nil || !strings.Contains(err.Error(), "DETACHED SIGNATURE") {
+		t.Fatalf("expected detached signature failure, got %v", err)
+	}
+
+	t.Logf("✓ Detached signature covers the whole file; later changes detected")
+}
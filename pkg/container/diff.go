@@ -0,0 +1,93 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"sort"
+)
+
+// How a file differs between two containers, in FileChange.Change.
+const (
+	ChangeAdded     = "added"     // only in the new container
+	ChangeRemoved   = "removed"   // only in the old container
+	ChangeModified  = "modified"  // in both, with different content
+	ChangeMoved     = "moved"     // the same content under another name
+	ChangeUnchanged = "unchanged" // in both, with the same content
+)
+
+// FileChange is one file of two containers compared by Diff. Old is the
+// file in the old container and New the file in the new one; one of them
+// is nil for a file that was added or removed.
+type FileChange struct {
+	Name   string // the file's name in the new container, or in the old one if removed
+	Change string
+	Old    *FileInfo
+	New    *FileInfo
+}
+
+// Diff compares the files of two containers, as recorded in their
+// manifests, so that a revised container can be reviewed against the one it
+// replaces. Files are matched by name and compared by their plaintext
+// SHA-256, so encrypted containers are compared without their passphrases.
+// A file removed under one name and added under another with the same
+// content is reported once, as moved. The changes are sorted by name.
+func Diff(oldPath, newPath string) ([]FileChange, error) {
+	oldFiles, err := ListFiles(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := ListFiles(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*FileInfo, len(oldFiles))
+	for i := range oldFiles {
+		byName[oldFiles[i].OriginalName] = &oldFiles[i]
+	}
+	var changes []FileChange
+	var added []*FileInfo
+	for i := range newFiles {
+		nf := &newFiles[i]
+		of, ok := byName[nf.OriginalName]
+		if !ok {
+			added = append(added, nf)
+			continue
+		}
+		delete(byName, nf.OriginalName)
+		change := ChangeUnchanged
+		if of.SHA256 != nf.SHA256 {
+			change = ChangeModified
+		}
+		changes = append(changes, FileChange{Name: nf.OriginalName, Change: change, Old: of, New: nf})
+	}
+
+	// What is left in byName was removed, unless an added file has its
+	// content. Each removed file pairs with one added file at most, taken
+	// in name order so that the pairing does not depend on map order.
+	removed := make(map[string][]*FileInfo)
+	for _, of := range byName {
+		removed[of.SHA256] = append(removed[of.SHA256], of)
+	}
+	for _, list := range removed {
+		sort.Slice(list, func(i, j int) bool { return list[i].OriginalName < list[j].OriginalName })
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].OriginalName < added[j].OriginalName })
+	for _, nf := range added {
+		if list := removed[nf.SHA256]; len(list) > 0 {
+			removed[nf.SHA256] = list[1:]
+			changes = append(changes, FileChange{Name: nf.OriginalName, Change: ChangeMoved, Old: list[0], New: nf})
+			continue
+		}
+		changes = append(changes, FileChange{Name: nf.OriginalName, Change: ChangeAdded, New: nf})
+	}
+	for _, list := range removed {
+		for _, of := range list {
+			changes = append(changes, FileChange{Name: of.OriginalName, Change: ChangeRemoved, Old: of})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes, nil
+}
@@ -0,0 +1,69 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(dir string, files map[string]string) string {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		var paths []string
+		for name, body := range files {
+			p := filepath.Join(tmpDir, dir, name)
+			os.WriteFile(p, []byte(body), 0644)
+			paths = append(paths, p)
+		}
+		imfPath := filepath.Join(tmpDir, dir+".imf")
+		container.Create(imfPath)
+		if err := container.Add(imfPath, paths); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		return imfPath
+	}
+	oldPath := write("v1", map[string]string{"same.txt": "same", "edit.txt": "first draft", "gone.txt": "bye", "exhibit.pdf": "moved"})
+	newPath := write("v2", map[string]string{"same.txt": "same", "edit.txt": "second draft", "new.txt": "hello", "exhibit-a.pdf": "moved"})
+
+	// The revised container is sealed encrypted: its manifest still records
+	// every file's plaintext hash.
+	kp, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(newPath, container.SealOptions{PrivateKey: kp.PrivateKey, Passphrase: "pw"}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	changes, err := container.Diff(oldPath, newPath)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	want := []struct{ name, change string }{
+		{"edit.txt", container.ChangeModified},
+		{"exhibit-a.pdf", container.ChangeMoved},
+		{"gone.txt", container.ChangeRemoved},
+		{"new.txt", container.ChangeAdded},
+		{"same.txt", container.ChangeUnchanged},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Name != w.name || c.Change != w.change {
+			t.Errorf("change %d: got %s %s, want %s %s", i, c.Name, c.Change, w.name, w.change)
+		}
+	}
+	if moved := changes[1]; moved.Old == nil || moved.Old.OriginalName != "exhibit.pdf" {
+		t.Errorf("expected exhibit-a.pdf to have moved from exhibit.pdf, got %+v", moved.Old)
+	}
+	if changes[2].New != nil || changes[3].Old != nil {
+		t.Error("a removed file should have no new side, and an added one no old side")
+	}
+
+	if _, err := container.Diff(oldPath, filepath.Join(tmpDir, "missing.imf")); err == nil {
+		t.Fatal("expected an error for a missing container")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// ExistingPolicy controls what Extract does when a file it would write is
+// already in the output directory.
+type ExistingPolicy string
+
+const (
+	ExistingOverwrite ExistingPolicy = "overwrite" // replace it (default)
+	ExistingSkip      ExistingPolicy = "skip"      // leave it alone, whatever it holds
+	ExistingResume    ExistingPolicy = "resume"    // leave it if it matches the manifest, replace it otherwise
+)
+
+// keepExisting reports whether Extract should leave the file already at
+// fe's output path instead of writing fe. Under ExistingResume a file is
+// kept only if its SHA-256 is the one the manifest records, and a link
+// only if it points where the stored one does, so re-running an
+// interrupted extraction rewrites just what is missing or incomplete
+// without decrypting the rest again.
+func keepExisting(outputDir string, fe manifest.FileEntry, policy ExistingPolicy) (bool, error) {
+	if policy == "" || policy == ExistingOverwrite {
+		return false, nil
+	}
+	path, err := SafeJoin(outputDir, fe.OriginalName)
+	if err != nil {
+		return false, fmt.Errorf("extracting %s: %w", fe.OriginalName, err)
+	}
+	fi, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if policy == ExistingSkip {
+		return true, nil
+	}
+
+	if fe.LinkTarget != "" {
+		target, err := os.Readlink(path)
+		return err == nil && target == fe.LinkTarget, nil
+	}
+	if !fi.Mode().IsRegular() || fi.Size() != fe.OriginalSize {
+		return false, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	sum, err := imfcrypto.HashReaderSHA256(f)
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return hex.EncodeToString(sum[:]) == fe.SHA256, nil
+}
+
+// filesToExtract returns the entries of files that Extract must write
+// under opts.Existing, telling opts.Kept of each one it leaves as it is.
+func filesToExtract(files []manifest.FileEntry, opts ExtractOptions) ([]manifest.FileEntry, error) {
+	switch opts.Existing {
+	case "", ExistingOverwrite:
+		return files, nil
+	case ExistingSkip, ExistingResume:
+	default:
+		return nil, fmt.Errorf("unknown policy for existing files %q (want overwrite, skip, or resume)", opts.Existing)
+	}
+	out := make([]manifest.FileEntry, 0, len(files))
+	for _, fe := range files {
+		keep, err := keepExisting(opts.OutputDir, fe, opts.Existing)
+		if err != nil {
+			return nil, err
+		}
+		if !keep {
+			out = append(out, fe)
+		} else if opts.Kept != nil {
+			opts.Kept(fe.OriginalName)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// FormatTarGz is the only export format currently supported.
+const FormatTarGz = "tar.gz"
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	Format       string           // archive format; "" means tar.gz
+	Passphrase   string           // required if container is encrypted with a passphrase
+	RecipientKey *ecdh.PrivateKey // required if container is encrypted to recipients
+	Verify       VerifyOptions    // public key and expiry handling for the pre-export check
+}
+
+// ExportManifestPath returns where Export writes the detached manifest for
+// an archive written to outPath: "x.tar.gz" pairs with "x.manifest.json".
+func ExportManifestPath(outPath string) string {
+	return strings.TrimSuffix(outPath, "."+FormatTarGz) + ".manifest.json"
+}
+
+// Export writes the decrypted contents of a sealed container to a tar.gz
+// archive at outPath, and the container's signed manifest, byte for byte,
+// beside it (see ExportManifestPath). Recipients without imf can unpack the
+// archive with standard tools and compare each file against the SHA-256
+// recorded in the manifest; anyone with imf or an Ed25519 library can check
+// the manifest signature.
+//
+// The container is fully verified and decrypted before anything is written.
+func Export(containerPath, outPath string, opts ExportOptions) error {
+	if opts.Format != "" && opts.Format != FormatTarGz {
+		return fmt.Errorf("unsupported export format %q (supported: %s)", opts.Format, FormatTarGz)
+	}
+	manifestOut := ExportManifestPath(outPath)
+	for _, p := range []string{outPath, manifestOut} {
+		if _, err := os.Stat(p); err == nil {
+			return fmt.Errorf("file already exists: %s", p)
+		}
+	}
+
+	m, zipData, err := readContainer(containerPath)
+	if err != nil {
+		return err
+	}
+	if !m.IsSealed() {
+		return errors.New("only sealed containers can be exported")
+	}
+	if err := Verify(containerPath, opts.Verify); err != nil {
+		return err
+	}
+	signed, err := revealManifest(m, zipData, opts.Passphrase, opts.RecipientKey)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readZipEntries(zipData, sealedMarker, pubKeyPath, certChainPath)
+	if err != nil {
+		return err
+	}
+	plaintexts, err := openEntries(signed, entries, opts.Passphrase, opts.RecipientKey, nil)
+	if err != nil {
+		return err
+	}
+
+	// A hidden container's outer header names no files, so the decrypted
+	// inner manifest, which carries its own signature, is exported instead.
+	manifestData := entries[manifestPath]
+	if m.IsHidden() {
+		if manifestData, err = signed.Marshal(); err != nil {
+			return fmt.Errorf("marshaling manifest: %w", err)
+		}
+	}
+
+	if err := writeTarGz(outPath, signed.Files, plaintexts, *signed.SealedAt); err != nil {
+		os.Remove(outPath)
+		return err
+	}
+	if err := os.WriteFile(manifestOut, manifestData, 0644); err != nil {
+		os.Remove(outPath)
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	return nil
+}
+
+// writeTarGz writes each manifest entry to a gzipped tarball, in manifest
+// order, stamped with the seal time so repeated exports are identical.
+func writeTarGz(outPath string, files []manifest.FileEntry, plaintexts map[string][]byte, modTime time.Time) error {
+	f, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, fe := range files {
+		// Names come from a signed manifest, but a tarball is unpacked by
+		// tools that may not guard against traversal, so sanitize anyway.
+		name, err := SanitizePath(fe.OriginalName)
+		if err != nil {
+			return fmt.Errorf("exporting %s: %w", fe.OriginalName, err)
+		}
+		data := plaintexts[fe.Path]
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			ModTime: modTime,
+			Format:  tar.FormatPAX,
+		}
+		if fe.LinkTarget != "" {
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = fe.LinkTarget
+			hdr.Mode = 0777
+			data = nil
+		} else {
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(data))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,93 @@
+package container_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+func TestExportTarGz(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.MkdirAll(filepath.Join(srcDir, "sub"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "a.txt"), []byte("alpha"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "sub", "b.txt"), []byte("beta"), 0644)
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	imfPath := filepath.Join(tmpDir, "case.imf")
+	_, err := container.Pack(srcDir, imfPath, container.PackOptions{
+		Seal: container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, Passphrase: "pw"},
+	})
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	outPath := filepath.Join(tmpDir, "case.tar.gz")
+	if err := container.Export(imfPath, outPath, container.ExportOptions{}); err == nil {
+		t.Fatal("expected export without a passphrase to fail")
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Fatal("failed export left an archive behind")
+	}
+	if err := container.Export(imfPath, outPath, container.ExportOptions{Passphrase: "pw"}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	// The detached manifest must carry a signature that checks out without imf.
+	mData, err := os.ReadFile(container.ExportManifestPath(outPath))
+	if err != nil {
+		t.Fatalf("reading detached manifest: %v", err)
+	}
+	m, err := manifest.Unmarshal(mData)
+	if err != nil {
+		t.Fatalf("parsing detached manifest: %v", err)
+	}
+	signable, _ := m.SignableBytes()
+	sig, _ := base64.StdEncoding.DecodeString(m.Signature)
+	if !ed25519.Verify(kp.PublicKey, signable, sig) {
+		t.Fatal("detached manifest signature does not verify")
+	}
+	want := map[string]string{}
+	for _, fe := range m.Files {
+		want[fe.OriginalName] = fe.SHA256
+	}
+
+	f, _ := os.Open(outPath)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	seen := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		sum := sha256.Sum256(data)
+		if want[hdr.Name] != hex.EncodeToString(sum[:]) {
+			t.Fatalf("%s: hash does not match manifest", hdr.Name)
+		}
+		seen++
+	}
+	if seen != 2 {
+		t.Fatalf("expected 2 files in archive, got %d", seen)
+	}
+	t.Logf("✓ Exported tar.gz matches the signed manifest")
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Download limits applied when AddOptions leaves them unset.
+const (
+	DefaultMaxDownloadSize = 512 << 20 // 512 MiB
+	DefaultDownloadTimeout = 5 * time.Minute
+)
+
+// IsURL reports whether an add source should be downloaded rather than read
+// from the local filesystem.
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// fetchURL downloads rawURL into memory, enforcing the size and time limits
+// from opts. It returns the body, a file name for the entry, and the time the
+// response was received.
+func fetchURL(rawURL string, opts AddOptions) ([]byte, string, time.Time, error) {
+	maxSize := opts.MaxDownloadSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDownloadSize
+	}
+	timeout := opts.DownloadTimeout
+	if timeout <= 0 {
+		timeout = DefaultDownloadTimeout
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("parsing %s: %w", rawURL, err)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("downloading %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	retrievedAt := time.Now().UTC()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", time.Time{}, fmt.Errorf("downloading %s: server returned status %d", rawURL, resp.StatusCode)
+	}
+	if resp.ContentLength > maxSize {
+		return nil, "", time.Time{}, fmt.Errorf("downloading %s: size %d exceeds limit of %d bytes", rawURL, resp.ContentLength, maxSize)
+	}
+
+	// Read one byte past the limit so an over-long body without a
+	// Content-Length header is still detected.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("downloading %s: %w", rawURL, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", time.Time{}, fmt.Errorf("downloading %s: exceeds limit of %d bytes", rawURL, maxSize)
+	}
+
+	return data, downloadName(u, resp.Header.Get("Content-Disposition")), retrievedAt, nil
+}
+
+// downloadName picks an entry name for a download: the Content-Disposition
+// filename if the server sent one, else the last URL path segment.
+func downloadName(u *url.URL, disposition string) string {
+	if disposition != "" {
+		if _, params, err := mime.ParseMediaType(disposition); err == nil {
+			if name := path.Base(strings.ReplaceAll(params["filename"], `\`, "/")); name != "" && name != "." && name != "/" && name != ".." {
+				return name
+			}
+		}
+	}
+	if name := path.Base(u.Path); name != "" && name != "." && name != "/" {
+		return name
+	}
+	return "download"
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"fmt"
+	"regexp"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// GrepOptions configures Grep.
+type GrepOptions struct {
+	Passphrase   string           // required if container is encrypted with a passphrase
+	RecipientKey *ecdh.PrivateKey // required if container is encrypted to recipients
+	IgnoreExpiry bool             // search even if expired
+}
+
+// GrepMatch is a line of a file in a container that matches a pattern.
+type GrepMatch struct {
+	File string // the file's original name
+	Line int    // 1-based line number
+	Text string // the line, without its line ending
+}
+
+// Grep searches the text files in a container for lines matching re, in
+// manifest order. Files are decrypted, and checked against their manifest
+// hashes, in memory only; nothing is written to disk, and the plaintext is
+// wiped once searched. Files containing a NUL byte are taken to be binary
+// and skipped, as are stored symbolic links.
+func Grep(containerPath string, re *regexp.Regexp, opts GrepOptions) ([]GrepMatch, error) {
+	m, zipData, err := readContainer(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	if m, err = revealManifest(m, zipData, opts.Passphrase, opts.RecipientKey); err != nil {
+		return nil, err
+	}
+
+	var plaintexts map[string][]byte
+	if m.IsSealed() {
+		if m.IsExpired() && !opts.IgnoreExpiry {
+			return nil, classify(ErrExpired, fmt.Errorf("container expired at %s (use --ignore-expiry to override)", m.ExpiresAt.Format(time.RFC3339)))
+		}
+		entries, err := readZipEntries(zipData, manifestPath, sealedMarker, pubKeyPath, certChainPath)
+		if err != nil {
+			return nil, err
+		}
+		if plaintexts, err = openEntries(m, entries, opts.Passphrase, opts.RecipientKey, nil); err != nil {
+			return nil, err
+		}
+	} else if plaintexts, err = readZipEntries(zipData, manifestPath); err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, data := range plaintexts {
+			imfcrypto.Wipe(data)
+		}
+	}()
+
+	var matches []GrepMatch
+	for _, fe := range m.Files {
+		data, ok := plaintexts[fe.Path]
+		if !ok {
+			return nil, fmt.Errorf("file missing from container: %s", fe.Path)
+		}
+		if fe.LinkTarget != "" || bytes.IndexByte(data, 0) >= 0 {
+			continue
+		}
+		for n := 1; len(data) > 0; n++ {
+			line := data
+			if i := bytes.IndexByte(data, '\n'); i >= 0 {
+				line, data = data[:i], data[i+1:]
+			} else {
+				data = nil
+			}
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			if re.Match(line) {
+				matches = append(matches, GrepMatch{File: fe.OriginalName, Line: n, Text: string(line)})
+			}
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,67 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestGrep(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.MkdirAll(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "a.txt"), []byte("header\r\ninvoice 4211 paid\nfooter\n"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "b.txt"), []byte("no match here\nINVOICE 4211 due"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "c.bin"), []byte("invoice 4211\x00\x01"), 0644)
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	imfPath := filepath.Join(tmpDir, "case.imf")
+	_, err := container.Pack(srcDir, imfPath, container.PackOptions{
+		Seal: container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, Passphrase: "pw"},
+	})
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	re := regexp.MustCompile(`(?i)invoice 4211`)
+	if _, err := container.Grep(imfPath, re, container.GrepOptions{}); err == nil {
+		t.Fatal("expected grep without a passphrase to fail")
+	}
+	if _, err := container.Grep(imfPath, re, container.GrepOptions{Passphrase: "wrong"}); err == nil {
+		t.Fatal("expected grep with the wrong passphrase to fail")
+	}
+	matches, err := container.Grep(imfPath, re, container.GrepOptions{Passphrase: "pw"})
+	if err != nil {
+		t.Fatalf("Grep: %v", err)
+	}
+	want := []container.GrepMatch{
+		{File: "a.txt", Line: 2, Text: "invoice 4211 paid"},
+		{File: "b.txt", Line: 2, Text: "INVOICE 4211 due"},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("got %d matches, want %d: %+v", len(matches), len(want), matches)
+	}
+	for i := range want {
+		if matches[i] != want[i] {
+			t.Fatalf("match %d: got %+v, want %+v", i, matches[i], want[i])
+		}
+	}
+
+	// An open container is searched as stored.
+	openPath := filepath.Join(tmpDir, "open.imf")
+	container.Create(openPath)
+	container.Add(openPath, []string{filepath.Join(srcDir, "a.txt")})
+	matches, err = container.Grep(openPath, regexp.MustCompile("footer"), container.GrepOptions{})
+	if err != nil {
+		t.Fatalf("Grep open container: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Line != 3 {
+		t.Fatalf("unexpected matches in open container: %+v", matches)
+	}
+
+	t.Logf("✓ Grep found %d matching lines, skipping the binary file", len(want))
+}
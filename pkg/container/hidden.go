@@ -0,0 +1,197 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// hiddenManifestPath holds the encrypted manifest of a hidden container.
+const hiddenManifestPath = "manifest.enc"
+
+// paddingPow2 pads each plaintext of a hidden container with zeros to the
+// next power of two, and at least minPaddedSize, before it is encrypted, so
+// the stored entries show only a size bucket. The real length is the file's
+// original_size, in the encrypted manifest.
+const (
+	paddingPow2   = "pow2"
+	minPaddedSize = 4096
+)
+
+// ErrManifestHidden is returned when a container's manifest is encrypted and
+// no passphrase or recipient key was supplied to read it.
+var ErrManifestHidden = errors.New("manifest is encrypted")
+
+// hideManifest encrypts the sealed, signed manifest inner with encKey and
+// builds the outer header stored in its place. The header keeps only what is
+// needed to decrypt and to verify without a key: version, encryption
+// parameters, expiry, the public key, certificate chain, and post-quantum key
+// if any, the signer's identity, any signature policy, and the hashes of the encrypted manifest and of every
+// stored entry, all under a fresh signature.
+func hideManifest(inner *manifest.Manifest, encKey []byte, signer imfcrypto.Signer) (*manifest.Manifest, []byte, error) {
+	data, err := inner.Marshal()
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling manifest: %w", err)
+	}
+	blob, err := imfcrypto.Encrypt(encKey, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encrypting manifest: %w", err)
+	}
+	blobHash := imfcrypto.HashSHA256(blob)
+
+	env := &manifest.Envelope{
+		ManifestSHA256: hex.EncodeToString(blobHash[:]),
+		Entries:        make(map[string]string, len(inner.Files)),
+	}
+	for _, fe := range inner.Files {
+		env.Entries[fe.Path] = fe.EncryptedSHA256
+	}
+
+	outer := &manifest.Manifest{
+		Version:    inner.Version,
+		State:      manifest.StateSealed,
+		ExpiresAt:  inner.ExpiresAt,
+		PublicKey:  inner.PublicKey,
+		CertChain:  inner.CertChain,
+		PQKey:      inner.PQKey,
+		Encryption: inner.Encryption,
+		Files:      []manifest.FileEntry{},
+		Envelope:   env,
+		Policy:     inner.Policy,
+		Signer:     inner.Signer,
+	}
+	signable, err := outer.SignableBytes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("computing signable bytes: %w", err)
+	}
+	sig, err := signer.Sign(signable)
+	if err != nil {
+		return nil, nil, fmt.Errorf("signing manifest: %w", err)
+	}
+	outer.Signature = base64.StdEncoding.EncodeToString(sig)
+	return outer, blob, nil
+}
+
+// revealManifest returns the real manifest of a container: m itself, or for
+// a hidden container the decrypted inner manifest. The inner manifest must
+// match the signed envelope entry for entry, so it cannot be swapped for
+// another without breaking the outer signature.
+func revealManifest(m *manifest.Manifest, zipData []byte, passphrase string, recipientKey *ecdh.PrivateKey) (*manifest.Manifest, error) {
+	if !m.IsHidden() {
+		return m, nil
+	}
+	if passphrase == "" && recipientKey == nil {
+		return nil, classify(ErrNoKey, fmt.Errorf("%w: a passphrase or recipient key is required", ErrManifestHidden))
+	}
+	if m.Encryption == nil {
+		return nil, errors.New("hidden manifest has no encryption parameters")
+	}
+	key, err := contentKey(m.Encryption, passphrase, recipientKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer imfcrypto.Wipe(key)
+
+	zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		return nil, fmt.Errorf("opening zip: %w", err)
+	}
+	limits := CurrentLimits()
+	var blob []byte
+	for _, f := range zr.File {
+		if f.Name == hiddenManifestPath {
+			blob, err = readEntry(f, &budget{limit: limits.MaxManifestSize, remaining: limits.MaxManifestSize})
+			if err != nil {
+				return nil, fmt.Errorf("reading encrypted manifest: %w", err)
+			}
+			break
+		}
+	}
+	if blob == nil {
+		return nil, errors.New("encrypted manifest missing from container")
+	}
+	blobHash := imfcrypto.HashSHA256(blob)
+	if hex.EncodeToString(blobHash[:]) != m.Envelope.ManifestSHA256 {
+		return nil, classify(ErrIntegrity, errors.New("INTEGRITY FAILURE: encrypted manifest hash mismatch"))
+	}
+
+	data, err := imfcrypto.Decrypt(key, blob)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting manifest: %w", err)
+	}
+	inner, err := manifest.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if !inner.IsSealed() || inner.IsHidden() {
+		return nil, classify(ErrIntegrity, errors.New("INTEGRITY FAILURE: encrypted manifest is malformed"))
+	}
+	if len(inner.Files) != len(m.Envelope.Entries) {
+		return nil, classify(ErrIntegrity, errors.New("INTEGRITY FAILURE: encrypted manifest does not match envelope"))
+	}
+	for _, fe := range inner.Files {
+		if m.Envelope.Entries[fe.Path] != fe.EncryptedSHA256 {
+			return nil, classify(ErrIntegrity, errors.New("INTEGRITY FAILURE: encrypted manifest does not match envelope"))
+		}
+	}
+	return inner, nil
+}
+
+// padPlaintext returns p padded as paddingPow2 says.
+func padPlaintext(p []byte) []byte {
+	size := minPaddedSize
+	for size < len(p) {
+		size <<= 1
+	}
+	padded := make([]byte, size)
+	copy(padded, p)
+	return padded
+}
+
+// unpadPlaintext strips the padding named by padding from p, the plaintext
+// of a file of size bytes.
+func unpadPlaintext(p []byte, size int64, padding string) ([]byte, error) {
+	if padding != paddingPow2 {
+		return nil, fmt.Errorf("unknown padding %q", padding)
+	}
+	if size < 0 || size > int64(len(p)) {
+		return nil, errors.New("padded entry is shorter than the file")
+	}
+	for _, b := range p[size:] {
+		if b != 0 {
+			return nil, errors.New("padding is not zeros")
+		}
+	}
+	return p[:size], nil
+}
+
+// envelopeRecords lists what Verify checks for a hidden container: every
+// stored entry, in path order, plus the encrypted manifest, each against its
+// envelope hash.
+func envelopeRecords(env *manifest.Envelope) []manifest.FileEntry {
+	paths := make([]string, 0, len(env.Entries))
+	for path := range env.Entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	records := []manifest.FileEntry{{
+		Path:            hiddenManifestPath,
+		OriginalName:    hiddenManifestPath,
+		EncryptedSHA256: env.ManifestSHA256,
+	}}
+	for _, path := range paths {
+		records = append(records, manifest.FileEntry{Path: path, OriginalName: path, EncryptedSHA256: env.Entries[path]})
+	}
+	return records
+}
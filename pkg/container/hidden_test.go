@@ -0,0 +1,186 @@
+package container_test
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestHiddenManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "hidden.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "informant-names.txt")
+	os.WriteFile(src, []byte("sensitive"), 0644)
+	container.Add(imfPath, []string{src})
+	kp, _ := imfcrypto.GenerateKeyPair()
+
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, HideManifest: true}); err == nil {
+		t.Fatal("expected hiding the manifest without encryption to fail")
+	}
+	err := container.Seal(imfPath, container.SealOptions{
+		PrivateKey:   kp.PrivateKey,
+		EmbedPubKey:  true,
+		Passphrase:   "pw",
+		HideManifest: true,
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	// Nothing readable without the passphrase may mention the file.
+	zr, err := zip.OpenReader(imfPath)
+	if err != nil {
+		t.Fatalf("opening container: %v", err)
+	}
+	for _, f := range zr.File {
+		if strings.Contains(f.Name, "informant") {
+			t.Fatalf("entry name leaks the file name: %s", f.Name)
+		}
+		if f.Name == "manifest.json" {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			if strings.Contains(string(data), "informant") {
+				t.Fatal("outer header leaks the file name")
+			}
+		}
+	}
+	zr.Close()
+
+	// The signature and every stored byte still verify without a key.
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if _, err := container.ListFiles(imfPath); !errors.Is(err, container.ErrManifestHidden) {
+		t.Fatalf("ListFiles without passphrase: expected ErrManifestHidden, got %v", err)
+	}
+	info, err := container.GetInfo(imfPath)
+	if err != nil || !info.Hidden || info.FileCount != 1 {
+		t.Fatalf("GetInfo: %+v, %v", info, err)
+	}
+	if _, err := container.ListFilesWithOptions(imfPath, container.ListOptions{Passphrase: "wrong"}); err == nil {
+		t.Fatal("expected a wrong passphrase to fail")
+	}
+	files, err := container.ListFilesWithOptions(imfPath, container.ListOptions{Passphrase: "pw", CheckHashes: true})
+	if err != nil || len(files) != 1 || files[0].OriginalName != "informant-names.txt" || files[0].Status != container.FileStatusOK {
+		t.Fatalf("ListFilesWithOptions: %+v, %v", files, err)
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: outDir, Passphrase: "pw"}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(outDir, "informant-names.txt"))
+	if string(got) != "sensitive" {
+		t.Fatalf("extracted %q", got)
+	}
+	t.Logf("✓ Hidden manifest verifies without a key and opens with one")
+}
+
+func TestHiddenManifestTamper(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "hidden.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("data"), 0644)
+	container.Add(imfPath, []string{src})
+	kp, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, Passphrase: "pw", HideManifest: true})
+
+	// Rewrite the container with the encrypted manifest replaced.
+	zr, _ := zip.OpenReader(imfPath)
+	tampered := filepath.Join(tmpDir, "tampered.imf")
+	out, _ := os.Create(tampered)
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == "manifest.enc" {
+			data[len(data)-1] ^= 0xFF
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(data)
+	}
+	zw.Close()
+	out.Close()
+	zr.Close()
+
+	if err := container.Verify(tampered, container.VerifyOptions{}); err == nil {
+		t.Fatal("SECURITY FAILURE: tampered hidden manifest verified")
+	}
+	if _, err := container.ListFilesWithOptions(tampered, container.ListOptions{Passphrase: "pw"}); err == nil {
+		t.Fatal("SECURITY FAILURE: tampered hidden manifest opened")
+	}
+	t.Logf("✓ Tampered hidden manifest rejected")
+}
+
+func TestHiddenManifestPadsSizes(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "hidden.imf")
+	container.Create(imfPath)
+	contents := map[string]string{
+		"empty.txt": "",
+		"short.txt": "yes",
+		"long.txt":  strings.Repeat("informant ", 300),
+	}
+	var srcs []string
+	for name, data := range contents {
+		src := filepath.Join(tmpDir, name)
+		os.WriteFile(src, []byte(data), 0644)
+		srcs = append(srcs, src)
+	}
+	container.Add(imfPath, srcs)
+	kp, _ := imfcrypto.GenerateKeyPair()
+	err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, Passphrase: "pw", HideManifest: true})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	// Files of different sizes in the same bucket are stored alike.
+	zr, err := zip.OpenReader(imfPath)
+	if err != nil {
+		t.Fatalf("opening container: %v", err)
+	}
+	sizes := map[uint64]int{}
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "files/") {
+			sizes[f.UncompressedSize64]++
+		}
+	}
+	zr.Close()
+	if len(sizes) != 1 || sizes[4096+28] != len(contents) {
+		t.Fatalf("stored entry sizes %v reveal the file sizes", sizes)
+	}
+
+	// The real sizes are in the encrypted manifest, and the padding is gone
+	// on the way out.
+	files, err := container.ListFilesWithOptions(imfPath, container.ListOptions{Passphrase: "pw"})
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	for _, f := range files {
+		if f.OriginalSize != int64(len(contents[f.OriginalName])) {
+			t.Fatalf("%s: size %d, want %d", f.OriginalName, f.OriginalSize, len(contents[f.OriginalName]))
+		}
+	}
+	outDir := filepath.Join(tmpDir, "out")
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: outDir, Passphrase: "pw"}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	for name, want := range contents {
+		if got, _ := os.ReadFile(filepath.Join(outDir, name)); string(got) != want {
+			t.Fatalf("%s: extracted %d bytes, want %d", name, len(got), len(want))
+		}
+	}
+	t.Logf("✓ Hidden entries padded to a size bucket, real sizes restored")
+}
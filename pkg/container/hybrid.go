@@ -0,0 +1,84 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// ErrNoPQSignature is returned when VerifyOptions.PQPublicKey is set but the
+// container was sealed without a post-quantum signature. Without this check
+// an attacker able to forge Ed25519 could simply strip the ML-DSA signature.
+var ErrNoPQSignature = errors.New("container has no post-quantum signature")
+
+// newPQKey returns the manifest record of the ML-DSA key with the given seed.
+func newPQKey(seed []byte) (*manifest.PQKey, error) {
+	pub, err := imfcrypto.PQPublicKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &manifest.PQKey{
+		Algorithm: imfcrypto.PQAlgorithm,
+		PublicKey: base64.StdEncoding.EncodeToString(pub),
+	}, nil
+}
+
+// pqAlgorithm returns the algorithm of m's post-quantum key, or "" if it
+// has none.
+func pqAlgorithm(m *manifest.Manifest) string {
+	if m.PQKey == nil {
+		return ""
+	}
+	return m.PQKey.Algorithm
+}
+
+// addPQSignature signs signable, the same bytes the Ed25519 signature
+// covers, with the ML-DSA key seed and records the signature in m.
+func addPQSignature(m *manifest.Manifest, seed, signable []byte) error {
+	sig, err := imfcrypto.PQSign(seed, signable)
+	if err != nil {
+		return fmt.Errorf("post-quantum signing: %w", err)
+	}
+	m.PQSignature = base64.StdEncoding.EncodeToString(sig)
+	return nil
+}
+
+// checkPQSignature verifies m's post-quantum signature over signable, if it
+// has one. If want is set, the signature is required and must be by that key.
+func checkPQSignature(m *manifest.Manifest, signable, want []byte) error {
+	if m.PQKey == nil {
+		if want != nil {
+			return ErrNoPQSignature
+		}
+		return nil
+	}
+	if m.PQKey.Algorithm != imfcrypto.PQAlgorithm {
+		return fmt.Errorf("unsupported post-quantum algorithm %q", m.PQKey.Algorithm)
+	}
+	pub, err := base64.StdEncoding.DecodeString(m.PQKey.PublicKey)
+	if err != nil {
+		return fmt.Errorf("decoding post-quantum public key: %w", err)
+	}
+	if want != nil && !bytes.Equal(pub, want) {
+		return classify(ErrSignature, errors.New("POST-QUANTUM SIGNATURE VERIFICATION FAILED: signed by a different ML-DSA key"))
+	}
+	sig, err := base64.StdEncoding.DecodeString(m.PQSignature)
+	if err != nil {
+		return fmt.Errorf("decoding post-quantum signature: %w", err)
+	}
+	ok, err := imfcrypto.PQVerify(pub, signable, sig)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return classify(ErrSignature, errors.New("POST-QUANTUM SIGNATURE VERIFICATION FAILED — container may be tampered"))
+	}
+	return nil
+}
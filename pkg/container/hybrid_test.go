@@ -0,0 +1,68 @@
+package container_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestHybridSignature(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "archive.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "deed.txt")
+	os.WriteFile(src, []byte("to be kept for a century"), 0644)
+	container.Add(imfPath, []string{src})
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	seed, err := imfcrypto.GeneratePQKey()
+	if err != nil {
+		t.Fatalf("GeneratePQKey: %v", err)
+	}
+	pqPub, _ := imfcrypto.PQPublicKey(seed)
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, PQKey: seed}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	report, err := container.VerifyWithReport(imfPath, container.VerifyOptions{PQPublicKey: pqPub})
+	if err != nil {
+		t.Fatalf("VerifyWithReport: %v", err)
+	}
+	if report.PQ != imfcrypto.PQAlgorithm {
+		t.Fatalf("report.PQ = %q", report.PQ)
+	}
+
+	// Another ML-DSA key is not accepted in place of the expected one.
+	otherSeed, _ := imfcrypto.GeneratePQKey()
+	otherPub, _ := imfcrypto.PQPublicKey(otherSeed)
+	if err := container.Verify(imfPath, container.VerifyOptions{PQPublicKey: otherPub}); err == nil {
+		t.Fatal("expected verification with another ML-DSA key to fail")
+	}
+
+	// A forged ML-DSA signature fails even though Ed25519 still verifies.
+	m := readManifest(t, imfPath)
+	m.PQSignature = base64.StdEncoding.EncodeToString(make([]byte, 3309))
+	data, _ := m.Marshal()
+	forged := filepath.Join(tmpDir, "forged.imf")
+	replaceEntry(t, imfPath, forged, "manifest.json", data)
+	if err := container.Verify(forged, container.VerifyOptions{}); err == nil || !strings.Contains(err.Error(), "POST-QUANTUM") {
+		t.Fatalf("expected post-quantum verification failure, got %v", err)
+	}
+
+	// Requiring the ML-DSA key rejects a container sealed without it.
+	plain := filepath.Join(tmpDir, "plain.imf")
+	container.Create(plain)
+	container.Add(plain, []string{src})
+	container.Seal(plain, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true})
+	if err := container.Verify(plain, container.VerifyOptions{PQPublicKey: pqPub}); !errors.Is(err, container.ErrNoPQSignature) {
+		t.Fatalf("expected ErrNoPQSignature, got %v", err)
+	}
+
+	t.Logf("✓ Hybrid Ed25519 + ML-DSA-65 signature verified, forgery and stripping detected")
+}
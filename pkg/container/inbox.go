@@ -0,0 +1,196 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultInboxInterval is how often WatchInbox scans the inbox.
+const DefaultInboxInterval = time.Minute
+
+// InboxOptions configures WatchInbox.
+type InboxOptions struct {
+	Interval time.Duration // time between scans; defaults to DefaultInboxInterval
+	Pack     PackOptions   // how each item is packed and sealed, as for Pack
+	// DoneDir is where each item is moved once sealed; defaults to .done
+	// in the inbox.
+	DoneDir string
+
+	// OnSealed is called for each item sealed, with the container made.
+	OnSealed func(item, containerPath string, r *SealReport)
+	// OnError is called when an item cannot be sealed. It is left in the
+	// inbox and tried again once it changes.
+	OnError func(item string, err error)
+}
+
+// WatchInbox scans the inbox directory every opts.Interval, packing and
+// sealing into outDir each file or subdirectory that has appeared in it:
+// inbox/report.pdf becomes outDir/report.pdf.imf, and inbox/case-7/, with
+// everything under it, outDir/case-7.imf. An item is only sealed once two
+// scans in a row find it unchanged, so a file still being copied in, or a
+// directory still being filled, waits for the next scan. Names starting
+// with "." are ignored, which leaves room for partial uploads and for the
+// done directory. A sealed item is moved to opts.DoneDir, so it is sealed
+// once; if its container name is taken, a number is added to it.
+//
+// WatchInbox runs until ctx is cancelled.
+func WatchInbox(ctx context.Context, inbox, outDir string, opts InboxOptions) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultInboxInterval
+	}
+	onError := opts.OnError
+	if onError == nil {
+		onError = func(string, error) {}
+	}
+	doneDir := opts.DoneDir
+	if doneDir == "" {
+		doneDir = filepath.Join(inbox, ".done")
+	}
+	if st, err := os.Stat(inbox); err != nil {
+		return fmt.Errorf("reading inbox: %w", err)
+	} else if !st.IsDir() {
+		return fmt.Errorf("%s is not a directory", inbox)
+	}
+	// Containers written into the inbox would be sealed again in turn.
+	skip := map[string]bool{}
+	for _, dir := range []string{outDir, doneDir} {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		skip[abs] = true
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating %s: %w", dir, err)
+		}
+	}
+	if abs, _ := filepath.Abs(inbox); skip[abs] {
+		return errors.New("the output and done directories must not be the inbox itself")
+	}
+
+	seen := map[string]string{}   // item → its state at the last scan
+	failed := map[string]string{} // item → its state when it last failed
+	for {
+		entries, err := os.ReadDir(inbox)
+		if err != nil {
+			return fmt.Errorf("reading inbox: %w", err)
+		}
+		current := map[string]string{}
+		for _, e := range entries {
+			item := filepath.Join(inbox, e.Name())
+			abs, _ := filepath.Abs(item)
+			if strings.HasPrefix(e.Name(), ".") || skip[abs] {
+				continue
+			}
+			state, ok := itemState(item)
+			if !ok {
+				continue
+			}
+			current[item] = state
+			if seen[item] != state || failed[item] == state {
+				continue
+			}
+			containerPath, r, err := sealItem(item, outDir, opts.Pack)
+			if err == nil {
+				err = moveUnique(item, doneDir)
+			}
+			if err != nil {
+				failed[item] = state
+				onError(item, err)
+				continue
+			}
+			delete(current, item)
+			if opts.OnSealed != nil {
+				opts.OnSealed(item, containerPath, r)
+			}
+		}
+		seen = current
+
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// itemState summarizes an inbox item — the number, sizes, and modification
+// times of its files — so that a change between scans shows. It reports
+// false for an item with nothing to seal yet, such as an empty directory.
+func itemState(item string) (string, bool) {
+	var files, size int64
+	var latest time.Time
+	err := filepath.WalkDir(item, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		size += fi.Size()
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+		return nil
+	})
+	if err != nil || files == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%d %d %d", files, size, latest.UnixNano()), true
+}
+
+// sealItem packs and seals an inbox item into a new container in outDir.
+func sealItem(item, outDir string, opts PackOptions) (string, *SealReport, error) {
+	st, err := os.Lstat(item)
+	if err != nil {
+		return "", nil, err
+	}
+	containerPath := uniquePath(filepath.Join(outDir, filepath.Base(item)), ".imf")
+	var r *SealReport
+	if st.IsDir() {
+		r, err = Pack(item, containerPath, opts)
+	} else if err = checkPackTarget(containerPath); err == nil {
+		r, err = packPaths([]string{item}, filepath.Dir(item), containerPath, opts)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return containerPath, r, nil
+}
+
+// moveUnique moves path into dir, numbering it if the name is taken.
+func moveUnique(path, dir string) error {
+	dst := uniquePath(filepath.Join(dir, filepath.Base(path)), "")
+	if err := os.Rename(path, dst); err != nil {
+		return fmt.Errorf("moving to %s: %w", dir, err)
+	}
+	return nil
+}
+
+// uniquePath returns base+ext, or, if that exists, the first of base-2+ext,
+// base-3+ext, ... that does not.
+func uniquePath(base, ext string) string {
+	path := base + ext
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
@@ -0,0 +1,82 @@
+package container_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestWatchInbox(t *testing.T) {
+	tmpDir := t.TempDir()
+	inbox := filepath.Join(tmpDir, "inbox")
+	outDir := filepath.Join(tmpDir, "sealed")
+	os.MkdirAll(filepath.Join(inbox, "case-7", "photos"), 0755)
+	os.MkdirAll(filepath.Join(inbox, "empty"), 0755)
+	os.WriteFile(filepath.Join(inbox, "case-7", "notes.txt"), []byte("notes"), 0644)
+	os.WriteFile(filepath.Join(inbox, "case-7", "photos", "1.jpg"), []byte("jpeg"), 0644)
+	os.WriteFile(filepath.Join(inbox, "report.pdf"), []byte("%PDF"), 0644)
+	os.WriteFile(filepath.Join(inbox, ".upload.part"), []byte("partial"), 0644)
+	// A container of the same name is already there.
+	os.MkdirAll(outDir, 0755)
+	os.WriteFile(filepath.Join(outDir, "report.pdf.imf"), []byte("older"), 0644)
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sealed := map[string]string{}
+	err := container.WatchInbox(ctx, inbox, outDir, container.InboxOptions{
+		Interval: 10 * time.Millisecond,
+		Pack: container.PackOptions{
+			Seal: container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true},
+		},
+		OnSealed: func(item, containerPath string, r *container.SealReport) {
+			sealed[filepath.Base(item)] = containerPath
+			if len(sealed) == 2 {
+				cancel()
+			}
+		},
+		OnError: func(item string, err error) {
+			t.Errorf("%s: %v", item, err)
+		},
+	})
+	if err != context.Canceled {
+		t.Fatalf("WatchInbox: %v, sealed %v", err, sealed)
+	}
+
+	if sealed["case-7"] != filepath.Join(outDir, "case-7.imf") || sealed["report.pdf"] != filepath.Join(outDir, "report.pdf-2.imf") {
+		t.Fatalf("sealed %v", sealed)
+	}
+	for _, path := range sealed {
+		if err := container.Verify(path, container.VerifyOptions{}); err != nil {
+			t.Fatalf("Verify %s: %v", path, err)
+		}
+	}
+	files, err := container.ListFiles(sealed["case-7"])
+	if err != nil || len(files) != 2 || files[0].OriginalName != "notes.txt" || files[1].OriginalName != "photos/1.jpg" {
+		t.Fatalf("case-7 holds %+v, %v", files, err)
+	}
+
+	var left []string
+	entries, _ := os.ReadDir(inbox)
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	sort.Strings(left)
+	if want := []string{".done", ".upload.part", "empty"}; len(left) != len(want) || left[0] != want[0] || left[1] != want[1] || left[2] != want[2] {
+		t.Fatalf("inbox holds %v, want %v", left, want)
+	}
+	if _, err := os.Stat(filepath.Join(inbox, ".done", "case-7", "photos", "1.jpg")); err != nil {
+		t.Fatalf("sealed directory not moved to .done: %v", err)
+	}
+
+	if err := container.WatchInbox(context.Background(), inbox, inbox, container.InboxOptions{}); err == nil {
+		t.Fatal("expected the inbox as output directory to be rejected")
+	}
+	t.Logf("✓ Watched inbox sealed %d items and set them aside", len(sealed))
+}
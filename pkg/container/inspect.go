@@ -0,0 +1,67 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// Inspection is a container's manifest as stored, for debugging and audits.
+type Inspection struct {
+	Stored   []byte             // manifest.json exactly as stored in the archive
+	Manifest *manifest.Manifest // the decoded manifest; the outer header if hidden
+	Signable []byte             // the exact bytes Manifest's signature is over
+
+	// Hidden is the decrypted manifest of a hidden container, set only
+	// when a key to read it was given, with the bytes its own signature
+	// is over.
+	Hidden         *manifest.Manifest
+	HiddenSignable []byte
+}
+
+// Inspect reads a container's manifest without checking it: nothing is
+// verified, so a damaged or forged container can be looked at too, as long
+// as its manifest parses. With opts.Passphrase or opts.RecipientKey, a
+// hidden manifest is decrypted as well.
+func Inspect(containerPath string, opts InfoOptions) (*Inspection, error) {
+	m, zipData, err := readContainer(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		return nil, fmt.Errorf("opening zip: %w", err)
+	}
+	in := &Inspection{Manifest: m}
+	limits := CurrentLimits()
+	for _, f := range zr.File {
+		if f.Name == manifestPath {
+			if in.Stored, err = readEntry(f, &budget{limit: limits.MaxManifestSize, remaining: limits.MaxManifestSize}); err != nil {
+				return nil, fmt.Errorf("reading manifest: %w", err)
+			}
+			break
+		}
+	}
+	if in.Stored == nil {
+		return nil, errors.New("manifest.json not found in container")
+	}
+	if in.Signable, err = m.SignableBytes(); err != nil {
+		return nil, fmt.Errorf("computing signable bytes: %w", err)
+	}
+
+	if m.IsHidden() && (opts.Passphrase != "" || opts.RecipientKey != nil) {
+		if in.Hidden, err = revealManifest(m, zipData, opts.Passphrase, opts.RecipientKey); err != nil {
+			return nil, err
+		}
+		if in.HiddenSignable, err = in.Hidden.SignableBytes(); err != nil {
+			return nil, fmt.Errorf("computing signable bytes: %w", err)
+		}
+	}
+	return in, nil
+}
@@ -0,0 +1,88 @@
+package container_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+func TestInspect(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "test.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "ledger.csv")
+	os.WriteFile(src, []byte("a,b\n1,2\n"), 0644)
+	container.Add(imfPath, []string{src})
+	kp, _ := imfcrypto.GenerateKeyPair()
+	err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	in, err := container.Inspect(imfPath, container.InfoOptions{})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	stored, err := manifest.Unmarshal(in.Stored)
+	if err != nil || stored.Signature != in.Manifest.Signature {
+		t.Fatalf("stored manifest %+v, %v", stored, err)
+	}
+	if len(in.Manifest.Files) != 1 || in.Manifest.Files[0].OriginalName != "ledger.csv" {
+		t.Fatalf("files %+v", in.Manifest.Files)
+	}
+	sig, _ := base64.StdEncoding.DecodeString(in.Manifest.Signature)
+	if !ed25519.Verify(kp.PublicKey, in.Signable, sig) {
+		t.Fatal("signature does not verify over the signable bytes")
+	}
+	if in.Hidden != nil {
+		t.Fatal("plain container reported a hidden manifest")
+	}
+
+	hiddenPath := filepath.Join(tmpDir, "hidden.imf")
+	container.Create(hiddenPath)
+	container.Add(hiddenPath, []string{src})
+	err = container.Seal(hiddenPath, container.SealOptions{
+		PrivateKey:   kp.PrivateKey,
+		EmbedPubKey:  true,
+		Passphrase:   "pw",
+		Iterations:   imfcrypto.MinSealIterations,
+		HideManifest: true,
+	})
+	if err != nil {
+		t.Fatalf("Seal hidden: %v", err)
+	}
+	in, err = container.Inspect(hiddenPath, container.InfoOptions{})
+	if err != nil || in.Hidden != nil || !in.Manifest.IsHidden() {
+		t.Fatalf("Inspect without key: %+v, %v", in, err)
+	}
+	in, err = container.Inspect(hiddenPath, container.InfoOptions{Passphrase: "pw"})
+	if err != nil {
+		t.Fatalf("Inspect with passphrase: %v", err)
+	}
+	if in.Hidden == nil || len(in.Hidden.Files) != 1 || in.Hidden.Files[0].OriginalName != "ledger.csv" {
+		t.Fatalf("hidden manifest %+v", in.Hidden)
+	}
+	sig, _ = base64.StdEncoding.DecodeString(in.Hidden.Signature)
+	if !ed25519.Verify(kp.PublicKey, in.HiddenSignable, sig) {
+		t.Fatal("hidden manifest signature does not verify over its signable bytes")
+	}
+	if _, err := container.Inspect(hiddenPath, container.InfoOptions{Passphrase: "wrong"}); err == nil {
+		t.Fatal("expected a wrong passphrase to fail")
+	}
+
+	// A manifest that fails verification can still be inspected.
+	tampered := filepath.Join(tmpDir, "tampered.imf")
+	data := bytes.Replace(in.Stored, []byte(`"state"`), []byte(` "state"`), 1)
+	replaceEntry(t, hiddenPath, tampered, "manifest.json", data)
+	if in, err = container.Inspect(tampered, container.InfoOptions{}); err != nil || !bytes.Equal(in.Stored, data) {
+		t.Fatalf("Inspect tampered: %v", err)
+	}
+	t.Log("✓ Inspect returned the stored manifest and the exact bytes each signature is over")
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/sigstore"
+)
+
+// KeylessOptions configures keyless signing (see package sigstore). The
+// container is signed with a one-time key that Fulcio certifies for the
+// sealer's OIDC identity, and the signature is recorded in Rekor, so there
+// is no long-lived key to protect — convenient for CI pipelines.
+type KeylessOptions struct {
+	IdentityToken string // OIDC token proving the sealer's identity; see sigstore.IdentityToken
+	FulcioURL     string // defaults to sigstore.DefaultFulcioURL
+	RekorURL      string // defaults to sigstore.DefaultRekorURL
+}
+
+func (o *KeylessOptions) fulcioURL() string {
+	if o.FulcioURL != "" {
+		return o.FulcioURL
+	}
+	return sigstore.DefaultFulcioURL
+}
+
+func (o *KeylessOptions) rekorURL() string {
+	if o.RekorURL != "" {
+		return o.RekorURL
+	}
+	return sigstore.DefaultRekorURL
+}
+
+// ErrNoTransparencyLog is returned when VerifyOptions.RekorKey is set but
+// the container was not signed keylessly.
+var ErrNoTransparencyLog = errors.New("container has no transparency log entry")
+
+// requestKeylessCert asks Fulcio to certify the one-time key for the
+// identity in the options' token.
+func requestKeylessCert(o *KeylessOptions, key ed25519.PrivateKey) ([]*x509.Certificate, error) {
+	chain, err := sigstore.RequestCertificate(o.fulcioURL(), o.IdentityToken, key)
+	if err != nil {
+		return nil, fmt.Errorf("obtaining signing certificate: %w", err)
+	}
+	return chain, nil
+}
+
+// logSignature records in Rekor the one-time key's signature over the
+// SHA-256 of signable, the manifest bytes it signed, and returns the entry
+// as JSON for the manifest.
+func logSignature(o *KeylessOptions, signer imfcrypto.Signer, leaf *x509.Certificate, signable []byte) (json.RawMessage, *sigstore.Entry, error) {
+	digest := imfcrypto.HashSHA256(signable)
+	sig, err := signer.Sign(digest[:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("signing log entry: %w", err)
+	}
+	entry, err := sigstore.Upload(o.rekorURL(), leaf, digest[:], sig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("recording signature in transparency log: %w", err)
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding log entry: %w", err)
+	}
+	return raw, entry, nil
+}
+
+// checkRekor parses a manifest's transparency log entry and checks that it
+// records a signature over signable by the leaf certificate's key. With key,
+// the entry must also be proven included in the Rekor log with that key.
+func checkRekor(raw json.RawMessage, chain []*x509.Certificate, signable []byte, key crypto.PublicKey) (*sigstore.Entry, error) {
+	var entry sigstore.Entry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, fmt.Errorf("TRANSPARENCY LOG VERIFICATION FAILED: decoding entry: %w", err)
+	}
+	if chain == nil {
+		return nil, errors.New("TRANSPARENCY LOG VERIFICATION FAILED: container has no signing certificate")
+	}
+	digest := imfcrypto.HashSHA256(signable)
+	if err := entry.Check(chain[0], digest[:]); err != nil {
+		return nil, fmt.Errorf("TRANSPARENCY LOG VERIFICATION FAILED: %w", err)
+	}
+	if key != nil {
+		if err := entry.Verify(key); err != nil {
+			return nil, fmt.Errorf("TRANSPARENCY LOG VERIFICATION FAILED: %w", err)
+		}
+	}
+	return &entry, nil
+}
+
+// checkIdentity checks that a Fulcio certificate was issued to identity by
+// issuer; an empty value matches anything.
+func checkIdentity(leaf *x509.Certificate, identity, issuer string) error {
+	gotIdentity, gotIssuer := sigstore.Identity(leaf)
+	if identity != "" && gotIdentity != identity {
+		return fmt.Errorf("IDENTITY MISMATCH: certificate is for %q, not %q", gotIdentity, identity)
+	}
+	if issuer != "" && gotIssuer != issuer {
+		return fmt.Errorf("IDENTITY MISMATCH: certificate was issued by %q, not %q", gotIssuer, issuer)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package container_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+func TestSealKeylessFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "build.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "artifact.bin")
+	os.WriteFile(src, []byte("release build"), 0644)
+	container.Add(imfPath, []string{src})
+	kp, _ := imfcrypto.GenerateKeyPair()
+
+	// Keyless signing replaces the key rather than adding to it.
+	keyless := &container.KeylessOptions{IdentityToken: "e30.e30.c2ln"}
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, Keyless: keyless}); err == nil {
+		t.Fatal("expected a signing key combined with keyless signing to be rejected")
+	}
+
+	// A dry run needs no identity and contacts nobody.
+	report, err := container.SealWithReport(imfPath, container.SealOptions{Keyless: &container.KeylessOptions{}, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if report.Rekor == "" || report.RekorEntry != nil {
+		t.Fatalf("unexpected dry-run report: log %q, entry %v", report.Rekor, report.RekorEntry)
+	}
+
+	// A Fulcio that refuses leaves the container open.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid identity token", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	keyless.FulcioURL, keyless.RekorURL = srv.URL, srv.URL
+	if err := container.Seal(imfPath, container.SealOptions{Keyless: keyless}); err == nil {
+		t.Fatal("expected seal to fail when Fulcio refuses")
+	}
+	if m := readManifest(t, imfPath); m.State != manifest.StateOpen {
+		t.Fatalf("container state after failed keyless seal: %s", m.State)
+	}
+
+	// A container sealed with a key has no log entry or identity to check.
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	rekorKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err := container.Verify(imfPath, container.VerifyOptions{RekorKey: &rekorKey.PublicKey}); !errors.Is(err, container.ErrNoTransparencyLog) {
+		t.Fatalf("expected ErrNoTransparencyLog, got %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{Identity: "ci@example.com"}); !errors.Is(err, container.ErrNoCertChain) {
+		t.Fatalf("expected ErrNoCertChain, got %v", err)
+	}
+
+	t.Logf("✓ Failed keyless seal leaves container open; missing log entry and identity detected")
+}
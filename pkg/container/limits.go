@@ -0,0 +1,121 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrLimitExceeded is returned when a container exceeds the configured
+// resource limits. Containers from untrusted sources (uploads, downloads)
+// may be crafted as zip bombs; the limits stop them before memory runs out.
+var ErrLimitExceeded = errors.New("container exceeds resource limits")
+
+// Limits bounds the resources spent reading a container.
+// A zero field means "no limit" for that dimension.
+type Limits struct {
+	MaxEntries          int   // maximum number of ZIP entries
+	MaxDecompressedSize int64 // maximum total uncompressed bytes across all entries
+	MaxManifestSize     int64 // maximum uncompressed size of manifest.json
+}
+
+// DefaultLimits are generous enough for any legitimate container while still
+// refusing obvious zip bombs.
+var DefaultLimits = Limits{
+	MaxEntries:          100000,
+	MaxDecompressedSize: 64 << 30, // 64 GiB
+	MaxManifestSize:     64 << 20, // 64 MiB
+}
+
+var (
+	limitsMu     sync.RWMutex
+	activeLimits = DefaultLimits
+)
+
+// SetLimits replaces the limits applied by every subsequent read.
+func SetLimits(l Limits) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	activeLimits = l
+}
+
+// CurrentLimits returns the limits currently in effect.
+func CurrentLimits() Limits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return activeLimits
+}
+
+// checkArchive applies the entry-count and declared-size limits to an opened
+// archive before any entry is decompressed.
+func (l Limits) checkArchive(zr *zip.Reader) error {
+	if l.MaxEntries > 0 && len(zr.File) > l.MaxEntries {
+		return fmt.Errorf("%w: %d entries (max %d)", ErrLimitExceeded, len(zr.File), l.MaxEntries)
+	}
+	if l.MaxDecompressedSize > 0 {
+		var total uint64
+		for _, f := range zr.File {
+			total += f.UncompressedSize64
+			if total > uint64(l.MaxDecompressedSize) {
+				return fmt.Errorf("%w: declared size exceeds %d bytes", ErrLimitExceeded, l.MaxDecompressedSize)
+			}
+		}
+	}
+	return nil
+}
+
+// budget tracks decompressed bytes actually read, so that entries whose
+// headers understate their size are still caught.
+type budget struct {
+	limit     int64 // zero means unlimited
+	remaining int64
+}
+
+func (l Limits) newBudget() *budget {
+	return &budget{limit: l.MaxDecompressedSize, remaining: l.MaxDecompressedSize}
+}
+
+// readAll reads r to EOF, charging the bytes against the budget.
+func (b *budget) readAll(r io.Reader, name string) ([]byte, error) {
+	if b.limit <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, b.remaining+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > b.remaining {
+		return nil, fmt.Errorf("%w: %s pushes decompressed size past %d bytes", ErrLimitExceeded, name, b.limit)
+	}
+	b.remaining -= int64(len(data))
+	return data, nil
+}
+
+// reader wraps r so that bytes streamed through it are charged against the
+// budget, for callers that hash rather than buffer an entry.
+func (b *budget) reader(r io.Reader, name string) io.Reader {
+	if b.limit <= 0 {
+		return r
+	}
+	return &budgetReader{b: b, r: r, name: name}
+}
+
+type budgetReader struct {
+	b    *budget
+	r    io.Reader
+	name string
+}
+
+func (br *budgetReader) Read(p []byte) (int, error) {
+	n, err := br.r.Read(p)
+	br.b.remaining -= int64(n)
+	if br.b.remaining < 0 {
+		return n, fmt.Errorf("%w: %s pushes decompressed size past %d bytes", ErrLimitExceeded, br.name, br.b.limit)
+	}
+	return n, err
+}
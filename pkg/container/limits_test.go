@@ -0,0 +1,77 @@
+package container_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+// withLimits installs l for the duration of the test.
+func withLimits(t *testing.T, l container.Limits) {
+	t.Helper()
+	prev := container.CurrentLimits()
+	container.SetLimits(l)
+	t.Cleanup(func() { container.SetLimits(prev) })
+}
+
+func TestLimitsEntryCount(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "many.imf")
+	container.Create(imfPath)
+
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		p := filepath.Join(tmpDir, name)
+		os.WriteFile(p, []byte(name), 0644)
+		paths = append(paths, p)
+	}
+	if err := container.Add(imfPath, paths); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	withLimits(t, container.Limits{MaxEntries: 3})
+	_, err := container.ListFiles(imfPath)
+	if !errors.Is(err, container.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded for 4 entries, got %v", err)
+	}
+	t.Logf("✓ Entry count limit enforced: %v", err)
+}
+
+func TestLimitsDecompressedSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "bomb.imf")
+	container.Create(imfPath)
+
+	// Zeros compress extremely well — a miniature zip bomb.
+	p := filepath.Join(tmpDir, "zeros.bin")
+	os.WriteFile(p, make([]byte, 1<<20), 0644)
+	if err := container.Add(imfPath, []string{p}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if fi, _ := os.Stat(imfPath); fi.Size() > 64<<10 {
+		t.Fatalf("expected highly compressed container, got %d bytes", fi.Size())
+	}
+
+	withLimits(t, container.Limits{MaxDecompressedSize: 512 << 10})
+	err := container.Extract(imfPath, container.ExtractOptions{OutputDir: filepath.Join(tmpDir, "out")})
+	if !errors.Is(err, container.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	t.Logf("✓ Decompressed size limit enforced: %v", err)
+}
+
+func TestLimitsManifestSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "manifest.imf")
+	container.Create(imfPath)
+
+	withLimits(t, container.Limits{MaxManifestSize: 16})
+	_, err := container.GetInfo(imfPath)
+	if !errors.Is(err, container.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	t.Logf("✓ Manifest size limit enforced: %v", err)
+}
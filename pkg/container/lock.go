@@ -0,0 +1,46 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrBusy is returned when another process (or another operation in this
+// one, such as the GUI) is already modifying the container.
+var ErrBusy = errors.New("container is busy")
+
+// errWouldBlock is returned by the platform lockFile when the lock is held.
+var errWouldBlock = errors.New("lock held")
+
+// lockContainer takes an exclusive advisory lock on the container file for
+// the duration of a read-modify-write cycle. The lock is non-blocking: if it
+// is already held, ErrBusy is returned rather than waiting. The returned
+// function releases it.
+//
+// The lock is on the container itself, not a sidecar file, so nothing is left
+// behind if the process dies. rewriteContainer truncates and rewrites the
+// same inode, so the lock stays in force while the new contents are written.
+func lockContainer(path string) (func(), error) {
+	if IsRemote(path) {
+		return nil, fmt.Errorf("%s: remote containers are read-only", path)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errWouldBlock) {
+			return nil, fmt.Errorf("%w: %s is being modified by another process", ErrBusy, path)
+		}
+		return nil, fmt.Errorf("locking container: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,14 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package container
+
+import "os"
+
+// Advisory locking is not available on this platform; operations proceed
+// unsynchronized as before.
+func lockFile(f *os.File) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
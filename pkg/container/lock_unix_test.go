@@ -0,0 +1,45 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package container_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestMutationsRespectLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "busy.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("data"), 0644)
+
+	// Simulate another process holding the lock.
+	f, err := os.Open(imfPath)
+	if err != nil {
+		t.Fatalf("opening container: %v", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		t.Fatalf("flock: %v", err)
+	}
+
+	if err := container.Add(imfPath, []string{src}); !errors.Is(err, container.ErrBusy) {
+		t.Fatalf("Add: expected ErrBusy, got %v", err)
+	}
+	kp, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey}); !errors.Is(err, container.ErrBusy) {
+		t.Fatalf("Seal: expected ErrBusy, got %v", err)
+	}
+
+	f.Close()
+	if err := container.Add(imfPath, []string{src}); err != nil {
+		t.Fatalf("Add after unlock: %v", err)
+	}
+	t.Logf("✓ Mutations refused while the container is locked")
+}
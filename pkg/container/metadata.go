@@ -0,0 +1,73 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// Limits on metadata, in characters, so that it stays a description rather
+// than a place to store content.
+const (
+	MaxMetadataField       = 200   // title, case number, template, and each tag
+	MaxMetadataDescription = 10000 // description
+	MaxMetadataTags        = 50
+)
+
+// SetMetadata replaces the metadata of the open container at containerPath.
+// Fields are trimmed of surrounding space, and empty and repeated tags
+// dropped; metadata with nothing left in it is removed.
+func SetMetadata(containerPath string, md manifest.Metadata) error {
+	clean, err := cleanMetadata(md)
+	if err != nil {
+		return err
+	}
+	return editOpen(containerPath, func(m *manifest.Manifest, entries map[string][]byte) error {
+		m.Metadata = clean
+		return nil
+	})
+}
+
+// cleanMetadata checks md against the limits and tidies it, returning nil
+// if it is empty.
+func cleanMetadata(md manifest.Metadata) (*manifest.Metadata, error) {
+	clean := manifest.Metadata{
+		Title:       strings.TrimSpace(md.Title),
+		Description: strings.TrimSpace(md.Description),
+		CaseNumber:  strings.TrimSpace(md.CaseNumber),
+		Template:    strings.TrimSpace(md.Template),
+	}
+	for _, tag := range md.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(clean.Tags, tag) {
+			clean.Tags = append(clean.Tags, tag)
+		}
+	}
+
+	for name, v := range map[string]string{"title": clean.Title, "case number": clean.CaseNumber, "template": clean.Template} {
+		if utf8.RuneCountInString(v) > MaxMetadataField {
+			return nil, fmt.Errorf("%s is longer than %d characters", name, MaxMetadataField)
+		}
+	}
+	if utf8.RuneCountInString(clean.Description) > MaxMetadataDescription {
+		return nil, fmt.Errorf("description is longer than %d characters", MaxMetadataDescription)
+	}
+	if len(clean.Tags) > MaxMetadataTags {
+		return nil, fmt.Errorf("more than %d tags", MaxMetadataTags)
+	}
+	for _, tag := range clean.Tags {
+		if utf8.RuneCountInString(tag) > MaxMetadataField {
+			return nil, fmt.Errorf("tag %.20q... is longer than %d characters", tag, MaxMetadataField)
+		}
+	}
+
+	if clean.Title == "" && clean.Description == "" && clean.CaseNumber == "" && len(clean.Tags) == 0 && clean.Template == "" {
+		return nil, nil
+	}
+	return &clean, nil
+}
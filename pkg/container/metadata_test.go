@@ -0,0 +1,69 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+func TestSetMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "case.imf")
+	container.Create(imfPath)
+	file := filepath.Join(tmpDir, "survey.txt")
+	os.WriteFile(file, []byte("north wall"), 0644)
+	container.Add(imfPath, []string{file})
+
+	long := manifest.Metadata{Title: strings.Repeat("x", container.MaxMetadataField+1)}
+	if err := container.SetMetadata(imfPath, long); err == nil {
+		t.Fatal("expected a title over the limit to fail")
+	}
+	err := container.SetMetadata(imfPath, manifest.Metadata{
+		Title:      "  Site survey ",
+		CaseNumber: "2026-0042",
+		Tags:       []string{"photos", " ", "photos", "site"},
+	})
+	if err != nil {
+		t.Fatalf("SetMetadata: %v", err)
+	}
+	info, err := container.GetInfo(imfPath)
+	if err != nil {
+		t.Fatalf("GetInfo: %v", err)
+	}
+	want := &manifest.Metadata{Title: "Site survey", CaseNumber: "2026-0042", Tags: []string{"photos", "site"}}
+	if !reflect.DeepEqual(info.Metadata, want) {
+		t.Fatalf("metadata = %+v, want %+v", info.Metadata, want)
+	}
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if m := readManifest(t, imfPath); !reflect.DeepEqual(m.Metadata, want) {
+		t.Fatalf("sealed metadata = %+v, want %+v", m.Metadata, want)
+	}
+	if err := container.SetMetadata(imfPath, manifest.Metadata{Title: "changed"}); err == nil {
+		t.Fatal("expected changing a sealed container's metadata to fail")
+	}
+}
+
+func TestClearMetadata(t *testing.T) {
+	imfPath := filepath.Join(t.TempDir(), "case.imf")
+	container.Create(imfPath)
+	container.SetMetadata(imfPath, manifest.Metadata{Title: "draft"})
+	if err := container.SetMetadata(imfPath, manifest.Metadata{Tags: []string{""}}); err != nil {
+		t.Fatalf("SetMetadata: %v", err)
+	}
+	if m := readManifest(t, imfPath); m.Metadata != nil {
+		t.Fatalf("expected empty metadata to be removed, got %+v", m.Metadata)
+	}
+}
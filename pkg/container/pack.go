@@ -0,0 +1,99 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PackOptions configures Pack.
+type PackOptions struct {
+	Add  AddOptions  // BaseDir is set by Pack; other fields apply as for Add
+	Seal SealOptions // key, encryption, and expiry for the final seal
+}
+
+// Pack creates a container at containerPath holding every file under dir
+// (recursively, with paths relative to dir preserved) and seals it.
+//
+// The container is assembled under a temporary name next to containerPath
+// and only renamed into place once sealing succeeds, so a failure at any
+// step never leaves a half-built or unsealed container behind.
+func Pack(dir, containerPath string, opts PackOptions) (*SealReport, error) {
+	if err := checkPackTarget(containerPath); err != nil {
+		return nil, err
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	// Collect files in lexical order so identical trees pack identically.
+	// Directories are descended into; symlinks are passed through so the
+	// add step can apply the symlink policy.
+	var paths []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() || d.Type()&fs.ModeSymlink != 0 {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking %s: %w", dir, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files found in %s", dir)
+	}
+	return packPaths(paths, dir, containerPath, opts)
+}
+
+// checkPackTarget checks that a container can be packed at containerPath.
+func checkPackTarget(containerPath string) error {
+	if !strings.HasSuffix(containerPath, ".imf") {
+		return errors.New("container path must have .imf extension")
+	}
+	if _, err := os.Stat(containerPath); err == nil {
+		return fmt.Errorf("file already exists: %s", containerPath)
+	}
+	return nil
+}
+
+// packPaths is Pack for the files in paths, named relative to baseDir.
+func packPaths(paths []string, baseDir, containerPath string, opts PackOptions) (*SealReport, error) {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(containerPath), ".imf-pack-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpPath := filepath.Join(tmpDir, filepath.Base(containerPath))
+
+	if err := Create(tmpPath); err != nil {
+		return nil, err
+	}
+	addOpts := opts.Add
+	addOpts.BaseDir = baseDir
+	if err := AddWithOptions(tmpPath, paths, addOpts); err != nil {
+		return nil, err
+	}
+	report, err := SealWithReport(tmpPath, opts.Seal)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Seal.DryRun {
+		return report, nil
+	}
+
+	if err := os.Rename(tmpPath, containerPath); err != nil {
+		return nil, fmt.Errorf("moving container into place: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,64 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestPack(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "evidence")
+	os.MkdirAll(filepath.Join(srcDir, "photos", "day1"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "notes.txt"), []byte("case notes"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "photos", "day1", "a.jpg"), []byte("jpeg bytes"), 0644)
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	imfPath := filepath.Join(tmpDir, "case.imf")
+	opts := container.PackOptions{
+		Seal: container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, Passphrase: "pw"},
+	}
+	report, err := container.Pack(srcDir, imfPath, opts)
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	if len(report.Files) != 2 || !report.Encrypted {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: outDir, Passphrase: "pw"}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(outDir, "photos", "day1", "a.jpg"))
+	if err != nil || string(got) != "jpeg bytes" {
+		t.Fatalf("nested path not preserved: %q, %v", got, err)
+	}
+
+	// An existing output is never overwritten.
+	if _, err := container.Pack(srcDir, imfPath, opts); err == nil {
+		t.Fatal("expected Pack to refuse an existing container")
+	}
+
+	// A failed seal leaves nothing behind.
+	badPath := filepath.Join(tmpDir, "bad.imf")
+	if _, err := container.Pack(srcDir, badPath, container.PackOptions{}); err == nil {
+		t.Fatal("expected Pack without a key to fail")
+	}
+	if _, err := os.Stat(badPath); !os.IsNotExist(err) {
+		t.Fatal("failed pack left a container behind")
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == "" && e.Name() != "evidence" && e.Name() != "out" {
+			t.Fatalf("temporary file left behind: %s", e.Name())
+		}
+	}
+	t.Logf("✓ Directory packed, sealed, and extracted with paths intact")
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath is returned when an entry name would resolve outside the
+// extraction directory (zip-slip), or is otherwise not a plain relative path.
+var ErrUnsafePath = errors.New("unsafe path")
+
+// SanitizePath normalizes an entry name recorded in a container to a clean,
+// slash-separated relative path. Both Unix and Windows separators are accepted.
+// Names that are empty, absolute, carry a drive letter or UNC prefix, contain
+// NUL bytes, or have any ".." component are rejected with ErrUnsafePath.
+//
+// Redundant separators and "." components are dropped, so "a//./b" becomes "a/b".
+func SanitizePath(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("%w: empty name", ErrUnsafePath)
+	}
+	if strings.ContainsRune(name, 0) {
+		return "", fmt.Errorf("%w: %q contains NUL byte", ErrUnsafePath, name)
+	}
+
+	// Treat backslashes as separators regardless of the host OS — a container
+	// built on Windows must not be able to smuggle "..\" past a Unix extractor.
+	p := strings.ReplaceAll(name, `\`, "/")
+
+	if strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("%w: %q is absolute", ErrUnsafePath, name)
+	}
+	// Drive letters ("C:", "c:foo") and anything else with a colon in the
+	// first component are rejected; on Windows these are volume-relative.
+	if first, _, _ := strings.Cut(p, "/"); strings.Contains(first, ":") {
+		return "", fmt.Errorf("%w: %q has a drive or volume prefix", ErrUnsafePath, name)
+	}
+
+	var parts []string
+	for _, part := range strings.Split(p, "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			return "", fmt.Errorf("%w: %q escapes the output directory", ErrUnsafePath, name)
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("%w: %q has no file name", ErrUnsafePath, name)
+	}
+
+	return path.Join(parts...), nil
+}
+
+// SafeJoin sanitizes name and joins it onto root using the host separator.
+// The result is guaranteed to lie inside root.
+func SafeJoin(root, name string) (string, error) {
+	clean, err := SanitizePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	joined := filepath.Join(root, filepath.FromSlash(clean))
+
+	// Defense in depth: confirm the joined path is still under root after
+	// filepath.Join has applied host-specific cleaning.
+	rel, err := filepath.Rel(root, joined)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q escapes the output directory", ErrUnsafePath, name)
+	}
+	return joined, nil
+}
@@ -0,0 +1,123 @@
+package container_test
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+func TestSanitizePathAccepts(t *testing.T) {
+	cases := map[string]string{
+		"report.pdf":            "report.pdf",
+		"docs/report.pdf":       "docs/report.pdf",
+		`docs\report.pdf`:       "docs/report.pdf",
+		"./docs//report.pdf":    "docs/report.pdf",
+		`docs\.\sub\a.txt`:      "docs/sub/a.txt",
+		"docs/":                 "docs",
+		"..hidden":              "..hidden",
+		"a..b/c":                "a..b/c",
+		"unicode/résumé.txt":    "unicode/résumé.txt",
+		"deep/nested/tree/x.md": "deep/nested/tree/x.md",
+	}
+	for in, want := range cases {
+		got, err := container.SanitizePath(in)
+		if err != nil {
+			t.Errorf("SanitizePath(%q): unexpected error: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("SanitizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSanitizePathRejects(t *testing.T) {
+	cases := []string{
+		"",
+		".",
+		"./",
+		"..",
+		"../evil.txt",
+		"docs/../../evil.txt",
+		"docs/../evil.txt",
+		`..\evil.txt`,
+		`docs\..\..\evil.txt`,
+		"/etc/passwd",
+		`\Windows\System32\evil.dll`,
+		`C:\Windows\evil.dll`,
+		"C:/Windows/evil.dll",
+		"c:evil.txt",
+		`\\server\share\evil.txt`,
+		"//server/share/evil.txt",
+		"ok/\x00/evil",
+	}
+	for _, in := range cases {
+		got, err := container.SanitizePath(in)
+		if err == nil {
+			t.Errorf("SanitizePath(%q) = %q, want error", in, got)
+			continue
+		}
+		if !errors.Is(err, container.ErrUnsafePath) {
+			t.Errorf("SanitizePath(%q): error %v is not ErrUnsafePath", in, err)
+		}
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	root := t.TempDir()
+
+	got, err := container.SafeJoin(root, `sub\dir/file.txt`)
+	if err != nil {
+		t.Fatalf("SafeJoin: %v", err)
+	}
+	want := filepath.Join(root, "sub", "dir", "file.txt")
+	if got != want {
+		t.Fatalf("SafeJoin = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"../x", `..\x`, "/abs", `C:\x`, "a/../../x"} {
+		p, err := container.SafeJoin(root, bad)
+		if err == nil {
+			t.Errorf("SafeJoin(%q) = %q, want error", bad, p)
+			continue
+		}
+		if strings.HasPrefix(p, root) && p != "" {
+			t.Errorf("SafeJoin(%q) returned a path alongside an error", bad)
+		}
+	}
+}
+
+// TestExtractRejectsZipSlip builds an open container by hand whose manifest
+// points an entry outside the output directory, and checks Extract refuses it.
+func TestExtractRejectsZipSlip(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "slip.imf")
+
+	f, err := os.Create(imfPath)
+	if err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("manifest.json")
+	w.Write([]byte(`{"version":1,"state":"open","created_at":"2026-01-01T00:00:00Z","files":[` +
+		`{"path":"files/evil.txt","original_name":"../evil.txt","original_size":4,"sha256":""}]}`))
+	w, _ = zw.Create("files/evil.txt")
+	w.Write([]byte("evil"))
+	zw.Close()
+	f.Close()
+
+	outDir := filepath.Join(tmpDir, "out")
+	err = container.Extract(imfPath, container.ExtractOptions{OutputDir: outDir})
+	if !errors.Is(err, container.ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "evil.txt")); err == nil {
+		t.Fatal("SECURITY FAILURE: file written outside the output directory")
+	}
+	t.Logf("✓ Zip-slip entry rejected")
+}
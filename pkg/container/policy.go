@@ -0,0 +1,158 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// SignaturePolicy requires that Threshold of Keys sign a container before it
+// verifies, e.g. 2 of 3 for dual control. The policy is recorded in the
+// signed manifest at seal time; the other key holders add their signatures
+// afterwards with Cosign.
+type SignaturePolicy struct {
+	Threshold int
+	Keys      []ed25519.PublicKey
+}
+
+// PolicyStatus reports how far a container is from meeting its policy.
+type PolicyStatus struct {
+	Threshold int      // signatures required
+	Keys      []string // base64 Ed25519 public keys allowed to sign
+	Signed    []string // base64 keys whose signature verified
+}
+
+// Satisfied reports whether enough policy keys have signed.
+func (s *PolicyStatus) Satisfied() bool {
+	return len(s.Signed) >= s.Threshold
+}
+
+// newManifestPolicy validates p and converts it for the manifest.
+func newManifestPolicy(p *SignaturePolicy) (*manifest.Policy, error) {
+	if len(p.Keys) == 0 {
+		return nil, errors.New("signature policy has no keys")
+	}
+	if p.Threshold < 1 || p.Threshold > len(p.Keys) {
+		return nil, fmt.Errorf("signature policy threshold %d out of range 1..%d", p.Threshold, len(p.Keys))
+	}
+	mp := &manifest.Policy{Threshold: p.Threshold}
+	seen := make(map[string]bool, len(p.Keys))
+	for _, k := range p.Keys {
+		if len(k) != ed25519.PublicKeySize {
+			return nil, errors.New("signature policy key is not an Ed25519 public key")
+		}
+		enc := base64.StdEncoding.EncodeToString(k)
+		if seen[enc] {
+			return nil, errors.New("signature policy lists the same key twice")
+		}
+		seen[enc] = true
+		mp.Keys = append(mp.Keys, enc)
+	}
+	return mp, nil
+}
+
+// addCosignature signs m's signable bytes with signer and records the
+// result. The signer's key must be one of the policy keys and must not have
+// signed already.
+func addCosignature(m *manifest.Manifest, signer imfcrypto.Signer) error {
+	if m.Policy == nil {
+		return errors.New("container has no signature policy")
+	}
+	pub := base64.StdEncoding.EncodeToString(signer.Public())
+	if !policyLists(m.Policy, pub) {
+		return errors.New("key is not listed in the container's signature policy")
+	}
+	for _, c := range m.Cosignatures {
+		if c.PublicKey == pub {
+			return errors.New("container is already signed with this key")
+		}
+	}
+	signable, err := m.SignableBytes()
+	if err != nil {
+		return fmt.Errorf("computing signable bytes: %w", err)
+	}
+	sig, err := signer.Sign(signable)
+	if err != nil {
+		return fmt.Errorf("signing manifest: %w", err)
+	}
+	m.Cosignatures = append(m.Cosignatures, manifest.Cosignature{
+		PublicKey: pub,
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	})
+	return nil
+}
+
+// policyLists reports whether the base64 public key pub is one of p's keys.
+func policyLists(p *manifest.Policy, pub string) bool {
+	for _, k := range p.Keys {
+		if k == pub {
+			return true
+		}
+	}
+	return false
+}
+
+// policyStatus checks each cosignature in m against signable. Signatures by
+// keys outside the policy, repeats, and invalid signatures are not counted.
+func policyStatus(m *manifest.Manifest, signable []byte) *PolicyStatus {
+	s := &PolicyStatus{Threshold: m.Policy.Threshold, Keys: m.Policy.Keys}
+	listed := make(map[string]bool, len(m.Policy.Keys))
+	for _, k := range m.Policy.Keys {
+		listed[k] = true
+	}
+	for _, c := range m.Cosignatures {
+		if !listed[c.PublicKey] {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(c.PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(c.Signature)
+		if err != nil || !imfcrypto.Verify(ed25519.PublicKey(key), signable, sig) {
+			continue
+		}
+		listed[c.PublicKey] = false
+		s.Signed = append(s.Signed, c.PublicKey)
+	}
+	return s
+}
+
+// Cosign adds a signature by key to a sealed container that has a signature
+// policy listing key. Cosignatures are kept outside the signed bytes, so
+// adding one changes neither the manifest content nor the sealer's
+// signature; the stored files are copied unchanged.
+func Cosign(containerPath string, key ed25519.PrivateKey) error {
+	signer, err := imfcrypto.NewKeySigner(key)
+	if err != nil {
+		return fmt.Errorf("invalid signing key: %w", err)
+	}
+	unlock, err := lockContainer(containerPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, zipData, err := readContainer(containerPath)
+	if err != nil {
+		return err
+	}
+	if !m.IsSealed() {
+		return errors.New("container is not sealed")
+	}
+	if err := addCosignature(m, signer); err != nil {
+		return err
+	}
+	entries, err := readZipEntries(zipData, manifestPath)
+	if err != nil {
+		return err
+	}
+	return rewriteContainer(containerPath, m, entries, nil)
+}
@@ -0,0 +1,151 @@
+package container_test
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestSignaturePolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "dual.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "wire-transfer.txt")
+	os.WriteFile(src, []byte("pay 1,000,000"), 0644)
+	container.Add(imfPath, []string{src})
+
+	a, _ := imfcrypto.GenerateKeyPair()
+	b, _ := imfcrypto.GenerateKeyPair()
+	c, _ := imfcrypto.GenerateKeyPair()
+	outsider, _ := imfcrypto.GenerateKeyPair()
+	keys := []ed25519.PublicKey{a.PublicKey, b.PublicKey, c.PublicKey}
+
+	bad := container.SealOptions{PrivateKey: a.PrivateKey, Policy: &container.SignaturePolicy{Threshold: 4, Keys: keys}}
+	if err := container.Seal(imfPath, bad); err == nil {
+		t.Fatal("expected a threshold above the key count to fail")
+	}
+
+	report, err := container.SealWithReport(imfPath, container.SealOptions{
+		PrivateKey:  a.PrivateKey,
+		EmbedPubKey: true,
+		Policy:      &container.SignaturePolicy{Threshold: 2, Keys: keys},
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if report.Policy == nil || len(report.Policy.Signed) != 1 {
+		t.Fatalf("expected the listed sealer to have signed: %+v", report.Policy)
+	}
+
+	// One of two required signatures is not enough.
+	err = container.Verify(imfPath, container.VerifyOptions{})
+	if err == nil || !strings.Contains(err.Error(), "POLICY NOT MET") {
+		t.Fatalf("expected policy failure, got %v", err)
+	}
+
+	if err := container.Cosign(imfPath, outsider.PrivateKey); err == nil {
+		t.Fatal("expected a key outside the policy to be refused")
+	}
+	if err := container.Cosign(imfPath, a.PrivateKey); err == nil {
+		t.Fatal("expected a second signature by the same key to be refused")
+	}
+	if err := container.Cosign(imfPath, c.PrivateKey); err != nil {
+		t.Fatalf("Cosign: %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify after cosign: %v", err)
+	}
+	info, err := container.GetInfo(imfPath)
+	if err != nil || info.Policy == nil || !info.Policy.Satisfied() || len(info.Policy.Keys) != 3 {
+		t.Fatalf("GetInfo: %+v, %v", info, err)
+	}
+
+	// Lowering the threshold breaks the sealer's signature.
+	m := readManifest(t, imfPath)
+	m.Policy.Threshold = 1
+	m.Cosignatures = m.Cosignatures[:1]
+	data, _ := m.Marshal()
+	tampered := filepath.Join(tmpDir, "tampered.imf")
+	replaceEntry(t, imfPath, tampered, "manifest.json", data)
+	if err := container.Verify(tampered, container.VerifyOptions{}); err == nil {
+		t.Fatal("expected a tampered policy to fail verification")
+	}
+
+	t.Logf("✓ Signature policy: 2 of 3 enforced, cosign refuses outsiders and repeats")
+}
+
+func TestSignaturePolicyHiddenManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "hidden-dual.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("a"), 0644)
+	container.Add(imfPath, []string{src})
+
+	sealer, _ := imfcrypto.GenerateKeyPair()
+	a, _ := imfcrypto.GenerateKeyPair()
+	b, _ := imfcrypto.GenerateKeyPair()
+	err := container.Seal(imfPath, container.SealOptions{
+		PrivateKey:   sealer.PrivateKey,
+		EmbedPubKey:  true,
+		Passphrase:   "pw",
+		HideManifest: true,
+		Policy:       &container.SignaturePolicy{Threshold: 2, Keys: []ed25519.PublicKey{a.PublicKey, b.PublicKey}},
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	for _, kp := range []*imfcrypto.KeyPair{a, b} {
+		if err := container.Verify(imfPath, container.VerifyOptions{}); err == nil {
+			t.Fatal("expected verification to fail before the threshold is met")
+		}
+		if err := container.Cosign(imfPath, kp.PrivateKey); err != nil {
+			t.Fatalf("Cosign: %v", err)
+		}
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: filepath.Join(tmpDir, "out"), Passphrase: "pw"}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	t.Logf("✓ Signature policy on a hidden manifest is cosigned without the passphrase")
+}
+
+// replaceEntry copies the container at src to dst with the named entry's
+// content replaced by data.
+func replaceEntry(t *testing.T, src, dst, name string, data []byte) {
+	t.Helper()
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		t.Fatalf("opening container: %v", err)
+	}
+	defer zr.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		t.Fatalf("creating %s: %v", dst, err)
+	}
+	defer out.Close()
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == name {
+			content = data
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("writing %s: %v", dst, err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"io"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// Progress is told how far a long operation has got: done of total units
+// of the named stage. An operation runs its stages one after another, each
+// counting up from zero; units are bytes, except for StageDeriveKey, which
+// counts PBKDF2 iterations, and StageRead, which counts files. It is called
+// often, from the goroutine doing the work, so it should return quickly.
+type Progress func(stage string, done, total int64)
+
+// Stages reported to a Progress.
+const (
+	StageRead      = "read"       // Add: reading the files to add
+	StageDeriveKey = "derive key" // deriving the key from a passphrase
+	StageEncrypt   = "encrypt"    // Seal: encrypting the files
+	StageDecrypt   = "decrypt"    // Extract: decrypting and checking the files
+	StageHash      = "hash"       // Verify: hashing the stored entries
+	StageWrite     = "write"      // writing the container, or the extracted files
+)
+
+// writeChunk is how much of an entry is written between progress reports.
+const writeChunk = 1 << 20
+
+// meter counts the units done in one stage and reports the running total.
+// A nil meter counts nothing, so callers need not check for a Progress.
+type meter struct {
+	progress    Progress
+	stage       string
+	done, total int64
+}
+
+// start begins a stage of total units, or returns nil if p is nil.
+func (p Progress) start(stage string, total int64) *meter {
+	if p == nil {
+		return nil
+	}
+	m := &meter{progress: p, stage: stage, total: total}
+	m.add(0)
+	return m
+}
+
+func (m *meter) add(n int64) {
+	if m == nil {
+		return
+	}
+	m.done += n
+	m.progress(m.stage, m.done, m.total)
+}
+
+// finish reports the stage complete, whatever was counted.
+func (m *meter) finish() {
+	if m == nil {
+		return
+	}
+	m.add(m.total - m.done)
+}
+
+// reader counts the bytes read from r.
+func (m *meter) reader(r io.Reader) io.Reader {
+	if m == nil {
+		return r
+	}
+	return &meterReader{m: m, r: r}
+}
+
+type meterReader struct {
+	m *meter
+	r io.Reader
+}
+
+func (mr *meterReader) Read(p []byte) (int, error) {
+	n, err := mr.r.Read(p)
+	mr.m.add(int64(n))
+	return n, err
+}
+
+// write writes data to w a chunk at a time, counting each chunk.
+func (m *meter) write(w io.Writer, data []byte) error {
+	if m == nil {
+		_, err := w.Write(data)
+		return err
+	}
+	for len(data) > 0 {
+		n := min(len(data), writeChunk)
+		if _, err := w.Write(data[:n]); err != nil {
+			return err
+		}
+		m.add(int64(n))
+		data = data[n:]
+	}
+	return nil
+}
+
+// deriveKey is the key derivation step reported to p.
+func (p Progress) deriveKey(passphrase string, salt []byte, iterations int) ([]byte, error) {
+	m := p.start(StageDeriveKey, int64(iterations))
+	return imfcrypto.DeriveKeyProgress(passphrase, salt, iterations, func(done, total int) {
+		if m != nil {
+			m.total = int64(total)
+			m.add(int64(done) - m.done)
+		}
+	})
+}
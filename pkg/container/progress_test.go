@@ -0,0 +1,107 @@
+package container_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// progressLog records the stages reported to a Progress, checking that each
+// counts up to its total.
+type progressLog struct {
+	t      *testing.T
+	stages []string
+	done   map[string]int64
+}
+
+func newProgressLog(t *testing.T) *progressLog {
+	return &progressLog{t: t, done: map[string]int64{}}
+}
+
+func (l *progressLog) report(stage string, done, total int64) {
+	if n := len(l.stages); n == 0 || l.stages[n-1] != stage {
+		l.stages = append(l.stages, stage)
+	} else if done < l.done[stage] {
+		l.t.Fatalf("%s went back from %d to %d", stage, l.done[stage], done)
+	}
+	if done > total {
+		l.t.Fatalf("%s: %d done of %d", stage, done, total)
+	}
+	l.done[stage] = done
+}
+
+// finished checks that the stages ran in order, each to completion.
+func (l *progressLog) finished(want ...string) {
+	l.t.Helper()
+	if len(l.stages) != len(want) {
+		l.t.Fatalf("stages %q, want %q", l.stages, want)
+	}
+	for i, stage := range want {
+		if l.stages[i] != stage {
+			l.t.Fatalf("stages %q, want %q", l.stages, want)
+		}
+		if l.done[stage] == 0 {
+			l.t.Fatalf("stage %s never advanced", stage)
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	big := filepath.Join(tmpDir, "big.bin")
+	data := bytes.Repeat([]byte("immutable "), 300_000)
+	os.WriteFile(big, data, 0644)
+	os.WriteFile(filepath.Join(tmpDir, "small.txt"), []byte("small"), 0644)
+
+	imfPath := filepath.Join(tmpDir, "test.imf")
+	container.Create(imfPath)
+	add := newProgressLog(t)
+	err := container.AddWithOptions(imfPath, []string{big, filepath.Join(tmpDir, "small.txt")}, container.AddOptions{Progress: add.report})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	add.finished(container.StageRead, container.StageWrite)
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	seal := newProgressLog(t)
+	err = container.Seal(imfPath, container.SealOptions{
+		PrivateKey:  kp.PrivateKey,
+		EmbedPubKey: true,
+		Passphrase:  "pw",
+		Iterations:  imfcrypto.MinSealIterations,
+		Progress:    seal.report,
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	seal.finished(container.StageDeriveKey, container.StageEncrypt, container.StageWrite)
+	if seal.done[container.StageEncrypt] != int64(len(data)+len("small")) {
+		t.Fatalf("encrypted %d bytes, want %d", seal.done[container.StageEncrypt], len(data)+len("small"))
+	}
+
+	verify := newProgressLog(t)
+	if err := container.Verify(imfPath, container.VerifyOptions{Progress: verify.report}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	verify.finished(container.StageHash)
+
+	extract := newProgressLog(t)
+	err = container.Extract(imfPath, container.ExtractOptions{
+		Passphrase: "pw",
+		OutputDir:  filepath.Join(tmpDir, "out"),
+		Progress:   extract.report,
+	})
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	extract.finished(container.StageDeriveKey, container.StageDecrypt, container.StageWrite)
+	if got, _ := os.ReadFile(filepath.Join(tmpDir, "out", "big.bin")); !bytes.Equal(got, data) {
+		t.Fatal("extracted file differs")
+	}
+
+	t.Log("✓ Add, seal, verify and extract reported each stage through to the end")
+}
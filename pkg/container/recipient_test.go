@@ -0,0 +1,66 @@
+package container_test
+
+import (
+	"crypto/ecdh"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestSealToRecipients(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "shared.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "memo.txt")
+	os.WriteFile(src, []byte("for alice and bob"), 0644)
+	container.Add(imfPath, []string{src})
+
+	alice, _ := imfcrypto.GenerateRecipientKey()
+	bob, _ := imfcrypto.GenerateRecipientKey()
+	eve, _ := imfcrypto.GenerateRecipientKey()
+	kp, _ := imfcrypto.GenerateKeyPair()
+
+	err := container.Seal(imfPath, container.SealOptions{
+		PrivateKey: kp.PrivateKey,
+		Passphrase: "also",
+		Recipients: []*ecdh.PublicKey{alice.PublicKey()},
+	})
+	if err == nil {
+		t.Fatal("expected sealing with both a passphrase and recipients to fail")
+	}
+
+	err = container.Seal(imfPath, container.SealOptions{
+		PrivateKey:  kp.PrivateKey,
+		EmbedPubKey: true,
+		Recipients:  []*ecdh.PublicKey{alice.PublicKey(), bob.PublicKey()},
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	for name, key := range map[string]*ecdh.PrivateKey{"alice": alice, "bob": bob} {
+		outDir := filepath.Join(tmpDir, name)
+		err := container.Extract(imfPath, container.ExtractOptions{OutputDir: outDir, RecipientKey: key})
+		if err != nil {
+			t.Fatalf("Extract as %s: %v", name, err)
+		}
+		got, _ := os.ReadFile(filepath.Join(outDir, "memo.txt"))
+		if string(got) != "for alice and bob" {
+			t.Fatalf("%s extracted %q", name, got)
+		}
+	}
+
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: filepath.Join(tmpDir, "eve"), RecipientKey: eve}); err == nil {
+		t.Fatal("SECURITY FAILURE: non-recipient extracted the container")
+	}
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: filepath.Join(tmpDir, "pp"), Passphrase: "guess"}); err == nil {
+		t.Fatal("expected a passphrase to be rejected for a recipient-encrypted container")
+	}
+	t.Logf("✓ Container sealed to two recipients; each decrypts with their own key")
+}
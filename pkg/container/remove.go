@@ -0,0 +1,103 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// Remove deletes the named files from the open container at containerPath.
+// Names are the original names shown by ListFiles. Nothing is removed unless
+// every name is found.
+func Remove(containerPath string, names []string) error {
+	if len(names) == 0 {
+		return errors.New("no files to remove")
+	}
+	return editOpen(containerPath, func(m *manifest.Manifest, entries map[string][]byte) error {
+		for _, name := range names {
+			i, err := fileIndex(m, name)
+			if err != nil {
+				return err
+			}
+			delete(entries, m.Files[i].Path)
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+		}
+		return nil
+	})
+}
+
+// Rename gives the file oldName in the open container at containerPath the
+// name newName, which may include folders, as in "docs/report.pdf". Its
+// content, hash, and provenance are kept. The new name must not be taken.
+func Rename(containerPath, oldName, newName string) error {
+	clean, err := SanitizePath(newName)
+	if err != nil {
+		return err
+	}
+	name := norm.NFC.String(clean)
+	return editOpen(containerPath, func(m *manifest.Manifest, entries map[string][]byte) error {
+		i, err := fileIndex(m, oldName)
+		if err != nil {
+			return err
+		}
+		fe := &m.Files[i]
+		zipPath := filesDir + name
+		if prior, ok := findEntry(m, zipPath); ok {
+			if prior == fe.Path {
+				return nil
+			}
+			return fmt.Errorf("an entry named %s already exists", name)
+		}
+		entries[zipPath] = entries[fe.Path]
+		delete(entries, fe.Path)
+		fe.Path, fe.OriginalName, fe.OriginalForm = zipPath, name, ""
+		if clean != name {
+			fe.OriginalForm = clean
+		}
+		return nil
+	})
+}
+
+// editOpen lets edit change the manifest and file entries of the open
+// container at path, then writes the container back.
+func editOpen(path string, edit func(m *manifest.Manifest, entries map[string][]byte) error) error {
+	unlock, err := lockContainer(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, zipData, err := readContainer(path)
+	if err != nil {
+		return err
+	}
+	if m.IsSealed() {
+		return errors.New("cannot change the files of a sealed container")
+	}
+	entries, err := readZipEntries(zipData, manifestPath)
+	if err != nil {
+		return err
+	}
+	if err := edit(m, entries); err != nil {
+		return err
+	}
+	return rewriteContainer(path, m, entries, nil)
+}
+
+// fileIndex returns the index in m.Files of the file with the original
+// name name.
+func fileIndex(m *manifest.Manifest, name string) (int, error) {
+	want := norm.NFC.String(name)
+	for i, fe := range m.Files {
+		if norm.NFC.String(fe.OriginalName) == want {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("file not found: %s", name)
+}
@@ -0,0 +1,69 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestRemoveAndRename(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "test.imf")
+	container.Create(imfPath)
+	var paths []string
+	for name, data := range map[string]string{"a.txt": "alpha", "b.txt": "beta", "c.txt": "gamma"} {
+		p := filepath.Join(tmpDir, name)
+		os.WriteFile(p, []byte(data), 0644)
+		paths = append(paths, p)
+	}
+	if err := container.Add(imfPath, paths); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if err := container.Remove(imfPath, []string{"b.txt", "missing.txt"}); err == nil {
+		t.Fatal("expected removing a missing name to fail")
+	}
+	if got := len(readManifest(t, imfPath).Files); got != 3 {
+		t.Fatalf("a failed remove changed the container: %d files", got)
+	}
+	if err := container.Remove(imfPath, []string{"b.txt"}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	if err := container.Rename(imfPath, "a.txt", "c.txt"); err == nil {
+		t.Fatal("expected renaming onto a taken name to fail")
+	}
+	if err := container.Rename(imfPath, "a.txt", "../a.txt"); err == nil {
+		t.Fatal("expected an unsafe new name to fail")
+	}
+	if err := container.Rename(imfPath, "a.txt", "docs/first.txt"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	m := readManifest(t, imfPath)
+	names := map[string]string{}
+	for _, f := range m.Files {
+		names[f.OriginalName] = f.Path
+	}
+	if len(names) != 2 || names["docs/first.txt"] != "files/docs/first.txt" || names["c.txt"] == "" {
+		t.Fatalf("unexpected files after remove and rename: %v", names)
+	}
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	out := filepath.Join(tmpDir, "out")
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: out}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(out, "docs", "first.txt")); err != nil || string(data) != "alpha" {
+		t.Fatalf("renamed file extracted as %q, %v", data, err)
+	}
+	if err := container.Remove(imfPath, []string{"c.txt"}); err == nil {
+		t.Fatal("expected removing from a sealed container to fail")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// ResealOptions configures Reseal.
+type ResealOptions struct {
+	Verify       VerifyOptions    // how to verify the old container (old public key, expiry)
+	Passphrase   string           // decrypts the old container, if it is encrypted with a passphrase
+	RecipientKey *ecdh.PrivateKey // decrypts the old container, if it is encrypted to recipients
+	Seal         SealOptions      // new key, encryption, and expiry for the new container
+}
+
+// Reseal migrates the contents of oldPath into a new container at newPath,
+// sealed with a new key and written with the current manifest version. It is
+// used for key rotation and manifest schema upgrades.
+//
+// A sealed source must pass Verify and decrypt cleanly before anything is
+// written; an open (legacy, never sealed) source has its file hashes checked.
+// File names, link targets, and provenance recorded in the old manifest are
+// carried over unchanged. As with Pack, the new container is only renamed
+// into place once sealing succeeds.
+func Reseal(oldPath, newPath string, opts ResealOptions) (*SealReport, error) {
+	if !strings.HasSuffix(newPath, ".imf") {
+		return nil, errors.New("container path must have .imf extension")
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return nil, fmt.Errorf("file already exists: %s", newPath)
+	}
+
+	old, zipData, err := readContainer(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	if old.IsSealed() {
+		if err := Verify(oldPath, opts.Verify); err != nil {
+			return nil, fmt.Errorf("verifying %s: %w", oldPath, err)
+		}
+	}
+	if old, err = revealManifest(old, zipData, opts.Passphrase, opts.RecipientKey); err != nil {
+		return nil, err
+	}
+
+	entries, err := readZipEntries(zipData, manifestPath, sealedMarker, pubKeyPath, certChainPath)
+	if err != nil {
+		return nil, err
+	}
+	plaintexts, err := openEntries(old, entries, opts.Passphrase, opts.RecipientKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start from a fresh manifest so the new container carries the current
+	// schema version; only per-file records are carried over.
+	m := manifest.New()
+	m.SymlinkPolicy = old.SymlinkPolicy
+	files := make(map[string][]byte, len(old.Files))
+	for _, fe := range old.Files {
+		// Store under the plaintext name as Add would; the old path may
+		// carry an .enc suffix or be an opaque hidden-manifest name.
+		data := plaintexts[fe.Path]
+		fe.Path = filesDir + fe.OriginalName
+		fe.EncryptedSHA256 = ""
+		if err := m.AddFile(fe); err != nil {
+			return nil, fmt.Errorf("adding %s to manifest: %w", fe.OriginalName, err)
+		}
+		files[fe.Path] = data
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(newPath), ".imf-reseal-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpPath := filepath.Join(tmpDir, filepath.Base(newPath))
+
+	if err := rewriteContainer(tmpPath, m, nil, files); err != nil {
+		return nil, err
+	}
+	report, err := SealWithReport(tmpPath, opts.Seal)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Seal.DryRun {
+		return report, nil
+	}
+
+	if err := os.Rename(tmpPath, newPath); err != nil {
+		return nil, fmt.Errorf("moving container into place: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,84 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestReseal(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldPath := filepath.Join(tmpDir, "old.imf")
+	container.Create(oldPath)
+	src := filepath.Join(tmpDir, "doc.txt")
+	os.WriteFile(src, []byte("rotating keys"), 0644)
+	container.Add(oldPath, []string{src})
+
+	oldKey, _ := imfcrypto.GenerateKeyPair()
+	newKey, _ := imfcrypto.GenerateKeyPair()
+	if err := container.Seal(oldPath, container.SealOptions{PrivateKey: oldKey.PrivateKey, Passphrase: "old"}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	newPath := filepath.Join(tmpDir, "new.imf")
+	opts := container.ResealOptions{
+		Verify:     container.VerifyOptions{PublicKey: oldKey.PublicKey},
+		Passphrase: "old",
+		Seal:       container.SealOptions{PrivateKey: newKey.PrivateKey, Passphrase: "new"},
+	}
+
+	// The wrong old key must stop the migration before anything is written.
+	bad := opts
+	bad.Verify.PublicKey = newKey.PublicKey
+	if _, err := container.Reseal(oldPath, newPath, bad); err == nil {
+		t.Fatal("expected reseal with the wrong old key to fail")
+	}
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		t.Fatal("failed reseal left a container behind")
+	}
+
+	if _, err := container.Reseal(oldPath, newPath, opts); err != nil {
+		t.Fatalf("Reseal: %v", err)
+	}
+	if err := container.Verify(newPath, container.VerifyOptions{PublicKey: newKey.PublicKey}); err != nil {
+		t.Fatalf("Verify with new key: %v", err)
+	}
+	if err := container.Verify(newPath, container.VerifyOptions{PublicKey: oldKey.PublicKey}); err == nil {
+		t.Fatal("new container should not verify with the old key")
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	if err := container.Extract(newPath, container.ExtractOptions{OutputDir: outDir, Passphrase: "new"}); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(outDir, "doc.txt"))
+	if string(got) != "rotating keys" {
+		t.Fatalf("content changed across reseal: %q", got)
+	}
+	t.Logf("✓ Container resealed under a new key")
+}
+
+func TestResealOpenContainer(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldPath := filepath.Join(tmpDir, "legacy.imf")
+	container.Create(oldPath)
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("never sealed"), 0644)
+	container.Add(oldPath, []string{src})
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	newPath := filepath.Join(tmpDir, "sealed.imf")
+	_, err := container.Reseal(oldPath, newPath, container.ResealOptions{
+		Seal: container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true},
+	})
+	if err != nil {
+		t.Fatalf("Reseal: %v", err)
+	}
+	if err := container.Verify(newPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	t.Logf("✓ Open container migrated to a sealed one")
+}
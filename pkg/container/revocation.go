@@ -0,0 +1,176 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// Revocation is a key owner's signed statement that their key must no longer
+// be trusted from RevokedAt on, e.g. because it was lost or stolen. It is
+// signed by the revoked key itself, so anyone can check it and nobody else
+// can forge it.
+type Revocation struct {
+	PublicKey string    `json:"public_key"`       // base64-encoded Ed25519 public key being revoked
+	RevokedAt time.Time `json:"revoked_at"`       // signatures from this time on are rejected
+	Reason    string    `json:"reason,omitempty"` // free-form, e.g. "key compromise"
+	Signature string    `json:"signature"`        // base64-encoded Ed25519 signature by the revoked key
+}
+
+// maxRevocationListSize caps a revocation list fetched from a URL.
+const maxRevocationListSize = 16 << 20
+
+// Revoke returns a revocation statement for key, effective at.
+func Revoke(key ed25519.PrivateKey, at time.Time, reason string) (*Revocation, error) {
+	if err := imfcrypto.ValidatePrivateKey(key); err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	r := &Revocation{
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		RevokedAt: at.UTC(),
+		Reason:    reason,
+	}
+	rb, err := r.SignableBytes()
+	if err != nil {
+		return nil, err
+	}
+	r.Signature = base64.StdEncoding.EncodeToString(imfcrypto.Sign(key, rb))
+	return r, nil
+}
+
+// SignableBytes returns the bytes the revoked key signs: the JSON form of r
+// with the signature field zeroed out.
+func (r *Revocation) SignableBytes() ([]byte, error) {
+	cp := *r
+	cp.Signature = ""
+	return json.Marshal(cp)
+}
+
+// Check verifies that r was signed by the key it revokes.
+func (r *Revocation) Check() error {
+	key, err := base64.StdEncoding.DecodeString(r.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("revocation: invalid public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil {
+		return errors.New("revocation: invalid signature encoding")
+	}
+	rb, err := r.SignableBytes()
+	if err != nil {
+		return err
+	}
+	if !imfcrypto.Verify(ed25519.PublicKey(key), rb, sig) {
+		return errors.New("revocation: bad signature")
+	}
+	return nil
+}
+
+// ParseRevocations decodes a revocation list: a JSON array of statements, or
+// a single statement. Every statement's signature is checked.
+func ParseRevocations(data []byte) ([]Revocation, error) {
+	var list []Revocation
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		list = make([]Revocation, 1)
+		if err := json.Unmarshal(trimmed, &list[0]); err != nil {
+			return nil, fmt.Errorf("parsing revocation: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parsing revocation list: %w", err)
+	}
+	for i := range list {
+		if err := list[i].Check(); err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return list, nil
+}
+
+// LoadRevocations reads every *.json revocation list in dir. A missing
+// directory holds no revocations.
+func LoadRevocations(dir string) ([]Revocation, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var all []Revocation
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		list, err := ParseRevocations(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		all = append(all, list...)
+	}
+	return all, nil
+}
+
+// FetchRevocations downloads and parses the revocation list published at
+// rawURL.
+func FetchRevocations(rawURL string) ([]Revocation, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching revocations: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching revocations: server returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRevocationListSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching revocations: %w", err)
+	}
+	if len(data) > maxRevocationListSize {
+		return nil, fmt.Errorf("fetching revocations: list exceeds %d bytes", maxRevocationListSize)
+	}
+	return ParseRevocations(data)
+}
+
+// checkRevocation looks for a revocation of pubKey among revs. A container
+// sealed at or after the revocation time, or whose seal time is unknown,
+// fails. One sealed before it is returned as a warning: its seal time is the
+// signer's own claim, which a thief holding the key could backdate.
+func checkRevocation(revs []Revocation, pubKey ed25519.PublicKey, sealedAt *time.Time) (*Revocation, error) {
+	enc := base64.StdEncoding.EncodeToString(pubKey)
+	var found *Revocation
+	for i := range revs {
+		r := &revs[i]
+		if r.PublicKey != enc || r.Check() != nil {
+			continue
+		}
+		if found == nil || r.RevokedAt.Before(found.RevokedAt) {
+			found = r
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	if sealedAt == nil || !sealedAt.Before(found.RevokedAt) {
+		return nil, fmt.Errorf("KEY REVOKED: signing key %s was revoked at %s", imfcrypto.Fingerprint(pubKey), found.RevokedAt.Format(time.RFC3339))
+	}
+	return found, nil
+}
@@ -0,0 +1,68 @@
+package container_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+func TestKeyRevocation(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "contract.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "contract.txt")
+	os.WriteFile(src, []byte("signed before the laptop was stolen"), 0644)
+	container.Add(imfPath, []string{src})
+
+	kp, _ := imfcrypto.GenerateKeyPair()
+	container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true})
+
+	// Revoked after the seal: verifies, with the revocation reported.
+	later, err := container.Revoke(kp.PrivateKey, time.Now().Add(time.Hour), "laptop stolen")
+	if err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	report, err := container.VerifyWithReport(imfPath, container.VerifyOptions{Revocations: []container.Revocation{*later}})
+	if err != nil {
+		t.Fatalf("VerifyWithReport: %v", err)
+	}
+	if report.Revocation == nil || report.Revocation.Reason != "laptop stolen" {
+		t.Fatalf("expected a revocation warning, got %+v", report.Revocation)
+	}
+
+	// Revoked before the seal: fails.
+	earlier, _ := container.Revoke(kp.PrivateKey, time.Now().Add(-time.Hour), "")
+	if err := container.Verify(imfPath, container.VerifyOptions{Revocations: []container.Revocation{*earlier}}); err == nil || !strings.Contains(err.Error(), "REVOKED") {
+		t.Fatalf("expected KEY REVOKED, got %v", err)
+	}
+
+	// Only the key's owner can revoke it.
+	other, _ := imfcrypto.GenerateKeyPair()
+	forged, _ := container.Revoke(other.PrivateKey, time.Now().Add(-time.Hour), "")
+	forged.PublicKey = earlier.PublicKey
+	data, _ := json.Marshal(forged)
+	if _, err := container.ParseRevocations(data); err == nil {
+		t.Fatal("expected a forged revocation to be rejected")
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{Revocations: []container.Revocation{*forged}}); err != nil {
+		t.Fatalf("forged revocation honored: %v", err)
+	}
+
+	// A local revocation list directory holds statements or arrays of them.
+	revDir := filepath.Join(tmpDir, "revoked")
+	os.Mkdir(revDir, 0700)
+	data, _ = json.Marshal([]container.Revocation{*earlier})
+	os.WriteFile(filepath.Join(revDir, "list.json"), data, 0644)
+	revs, err := container.LoadRevocations(revDir)
+	if err != nil || len(revs) != 1 {
+		t.Fatalf("LoadRevocations: %d statements, %v", len(revs), err)
+	}
+
+	t.Logf("✓ Revocation after seal warns, before seal fails, forgeries rejected")
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// maxLargest is how many of the biggest files Stats reports.
+const maxLargest = 10
+
+// Stats summarizes a container's contents and storage efficiency.
+type Stats struct {
+	FileCount        int
+	PlaintextSize    int64               // sum of original file sizes
+	StoredSize       int64               // compressed bytes of file entries inside the ZIP
+	ContainerSize    int64               // size of the .imf file on disk
+	CompressionRatio float64             // StoredSize / PlaintextSize (lower is better)
+	ByType           map[string]TypeStat // keyed by MIME type
+	Largest          []FileInfo          // biggest files first
+	DuplicateGroups  int                 // distinct contents stored more than once
+	DuplicateFiles   int                 // files whose content repeats an earlier file
+	DuplicateBytes   int64               // plaintext bytes taken by those repeats
+}
+
+// TypeStat is the per-type portion of Stats.
+type TypeStat struct {
+	Count int
+	Size  int64
+}
+
+// GetStats computes size, compression, type, and duplicate statistics for
+// a container. Duplicates are found by plaintext SHA-256, so they are
+// detected even in encrypted containers.
+func GetStats(containerPath string) (*Stats, error) {
+	m, zipData, err := readContainer(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	if m, err = revealManifest(m, zipData, "", nil); err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		return nil, fmt.Errorf("opening zip: %w", err)
+	}
+	stored := make(map[string]int64, len(zr.File))
+	for _, f := range zr.File {
+		stored[f.Name] = int64(f.CompressedSize64)
+	}
+
+	st := &Stats{
+		FileCount:     len(m.Files),
+		ContainerSize: int64(len(zipData)),
+		ByType:        make(map[string]TypeStat),
+	}
+	seen := make(map[string]int)
+	var all []FileInfo
+	for _, fe := range m.Files {
+		st.PlaintextSize += fe.OriginalSize
+		st.StoredSize += stored[fe.Path]
+
+		mt := mimeType(fe.OriginalName)
+		ts := st.ByType[mt]
+		ts.Count++
+		ts.Size += fe.OriginalSize
+		st.ByType[mt] = ts
+
+		seen[fe.SHA256]++
+		switch seen[fe.SHA256] {
+		case 1:
+		case 2:
+			st.DuplicateGroups++
+			fallthrough
+		default:
+			st.DuplicateFiles++
+			st.DuplicateBytes += fe.OriginalSize
+		}
+
+		all = append(all, FileInfo{
+			OriginalName: fe.OriginalName,
+			OriginalSize: fe.OriginalSize,
+			SHA256:       fe.SHA256,
+			Path:         fe.Path,
+			MimeType:     mt,
+		})
+	}
+	if st.PlaintextSize > 0 {
+		st.CompressionRatio = float64(st.StoredSize) / float64(st.PlaintextSize)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].OriginalSize > all[j].OriginalSize })
+	if len(all) > maxLargest {
+		all = all[:maxLargest]
+	}
+	st.Largest = all
+	return st, nil
+}
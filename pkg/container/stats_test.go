@@ -0,0 +1,52 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+func TestGetStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "stats.imf")
+	container.Create(imfPath)
+
+	contents := map[string]string{
+		"a.txt":  "duplicated content",
+		"b.txt":  "duplicated content",
+		"c.txt":  "duplicated content",
+		"big.md": string(make([]byte, 4096)),
+	}
+	var paths []string
+	for name, body := range contents {
+		p := filepath.Join(tmpDir, name)
+		os.WriteFile(p, []byte(body), 0644)
+		paths = append(paths, p)
+	}
+	if err := container.Add(imfPath, paths); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	st, err := container.GetStats(imfPath)
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if st.FileCount != 4 || st.PlaintextSize != 3*18+4096 {
+		t.Fatalf("unexpected totals: %+v", st)
+	}
+	if st.DuplicateGroups != 1 || st.DuplicateFiles != 2 || st.DuplicateBytes != 36 {
+		t.Fatalf("unexpected duplicate counts: groups=%d files=%d bytes=%d", st.DuplicateGroups, st.DuplicateFiles, st.DuplicateBytes)
+	}
+	if st.ByType["text/plain"].Count != 3 {
+		t.Fatalf("expected 3 text/plain files, got %+v", st.ByType)
+	}
+	if st.Largest[0].OriginalName != "big.md" {
+		t.Fatalf("expected big.md largest, got %s", st.Largest[0].OriginalName)
+	}
+	if st.CompressionRatio <= 0 || st.CompressionRatio >= 1 {
+		t.Fatalf("expected zeros to compress, ratio %.3f", st.CompressionRatio)
+	}
+	t.Logf("✓ Stats computed: %d files, ratio %.3f", st.FileCount, st.CompressionRatio)
+}
@@ -0,0 +1,268 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Object is an opened container: random access to its bytes plus its size.
+// Reading containers only needs ReadAt, so remote objects can be fetched a
+// range at a time instead of being downloaded in full.
+type Object interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+}
+
+// Backend opens containers addressed by a URL scheme, such as "s3".
+type Backend interface {
+	Open(location string) (Object, error)
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Backend{
+		"s3":    s3Backend{},
+		"gs":    gcsBackend{},
+		"http":  httpBackend{},
+		"https": httpBackend{},
+	}
+)
+
+// RegisterBackend makes containers at "<scheme>://..." readable through b.
+// It replaces any backend already registered for the scheme.
+func RegisterBackend(scheme string, b Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[scheme] = b
+}
+
+// IsRemote reports whether path names a container in a registered backend
+// rather than on the local filesystem. Remote containers can be verified,
+// listed, inspected, and extracted, but not modified.
+func IsRemote(path string) bool {
+	_, ok := backendFor(path)
+	return ok
+}
+
+func backendFor(path string) (Backend, bool) {
+	scheme, _, ok := strings.Cut(path, "://")
+	if !ok {
+		return nil, false
+	}
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	b, ok := backends[scheme]
+	return b, ok
+}
+
+// openObject opens a container for reading from whichever backend owns path,
+// falling back to the local filesystem.
+func openObject(path string) (Object, error) {
+	if b, ok := backendFor(path); ok {
+		obj, err := b.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading container: %w", err)
+		}
+		return obj, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	return &fileObject{File: f, size: st.Size()}, nil
+}
+
+type bytesObject struct{ *bytes.Reader }
+
+func (bytesObject) Close() error { return nil }
+
+type fileObject struct {
+	*os.File
+	size int64
+}
+
+func (o *fileObject) Size() int64 { return o.size }
+
+// remoteTimeout bounds each request made to an object store.
+const remoteTimeout = 2 * time.Minute
+
+// rangeBlockSize is how much is fetched per request. ZIP readers issue many
+// small reads; fetching aligned blocks keeps that to a handful of requests.
+const rangeBlockSize = 1 << 20
+
+// rangeCacheBlocks is how many blocks are kept. Verification alternates
+// between the central directory at the end of the archive, the manifest at
+// the start, and the entry being hashed, so a few blocks avoid refetching.
+const rangeCacheBlocks = 4
+
+// httpBackend reads containers published at plain http:// or https:// URLs.
+// The server must honor Range requests; the container is never downloaded
+// in full or written to disk.
+type httpBackend struct{}
+
+func (httpBackend) Open(location string) (Object, error) {
+	obj, err := openRangeObject(func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, location, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	return obj, nil
+}
+
+// rangeObject reads an object over HTTP with Range requests. newRequest
+// builds an authenticated GET for the object; the Range header is added here.
+type rangeObject struct {
+	client     *http.Client
+	newRequest func() (*http.Request, error)
+	size       int64
+	etag       string // pins later requests to the version first seen
+
+	mu     sync.Mutex
+	blocks []rangeBlock // most recently used first
+}
+
+type rangeBlock struct {
+	off  int64
+	data []byte
+}
+
+// openRangeObject issues a one-byte ranged GET to learn the object's size.
+// Servers that ignore Range get the whole object buffered instead.
+func openRangeObject(newRequest func() (*http.Request, error)) (Object, error) {
+	o := &rangeObject{
+		client:     &http.Client{Timeout: remoteTimeout},
+		newRequest: newRequest,
+	}
+	resp, err := o.get(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// "Content-Range: bytes 0-0/12345"
+		_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+		size, err := strconv.ParseInt(total, 10, 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("server sent an unusable Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		o.size = size
+		o.etag = resp.Header.Get("ETag")
+	case http.StatusOK:
+		// The server ignored Range and is sending the whole object. Keep it
+		// in memory, within the same cap used for downloaded add sources.
+		data, err := io.ReadAll(io.LimitReader(resp.Body, DefaultMaxDownloadSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > DefaultMaxDownloadSize {
+			return nil, fmt.Errorf("server does not support range requests and the object exceeds %d bytes", DefaultMaxDownloadSize)
+		}
+		return bytesObject{bytes.NewReader(data)}, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Zero-length object; it will fail to open as a ZIP.
+		o.size = 0
+	default:
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	return o, nil
+}
+
+func (o *rangeObject) get(start, end int64) (*http.Response, error) {
+	req, err := o.newRequest()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if o.etag != "" {
+		req.Header.Set("If-Match", o.etag)
+	}
+	return o.client.Do(req)
+}
+
+func (o *rangeObject) Size() int64  { return o.size }
+func (o *rangeObject) Close() error { return nil }
+
+// ReadAt serves reads from cached blocks, fetching the aligned block that
+// covers each position when it is not already held.
+func (o *rangeObject) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= o.size {
+			return n, io.EOF
+		}
+		b, err := o.block(pos - pos%rangeBlockSize)
+		if err != nil {
+			return n, err
+		}
+		n += copy(p[n:], b.data[pos-b.off:])
+	}
+	return n, nil
+}
+
+// block returns the cached block at start, fetching it if needed.
+func (o *rangeObject) block(start int64) (rangeBlock, error) {
+	for i, b := range o.blocks {
+		if b.off == start {
+			copy(o.blocks[1:i+1], o.blocks[:i])
+			o.blocks[0] = b
+			return b, nil
+		}
+	}
+
+	end := min(start+rangeBlockSize, o.size) - 1
+	resp, err := o.get(start, end)
+	if err != nil {
+		return rangeBlock{}, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusPreconditionFailed:
+		return rangeBlock{}, errors.New("object changed while it was being read")
+	default:
+		return rangeBlock{}, fmt.Errorf("range request returned status %d", resp.StatusCode)
+	}
+	want := end - start + 1
+	data, err := io.ReadAll(io.LimitReader(resp.Body, want+1))
+	if err != nil {
+		return rangeBlock{}, err
+	}
+	if int64(len(data)) != want {
+		return rangeBlock{}, fmt.Errorf("range request returned %d bytes, want %d", len(data), want)
+	}
+
+	b := rangeBlock{off: start, data: data}
+	if len(o.blocks) < rangeCacheBlocks {
+		o.blocks = append(o.blocks, rangeBlock{})
+	}
+	copy(o.blocks[1:], o.blocks)
+	o.blocks[0] = b
+	return b, nil
+}
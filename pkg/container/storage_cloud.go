@@ -0,0 +1,212 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// splitBucketURL splits "s3://bucket/some/key" into bucket and key.
+func splitBucketURL(location, scheme string) (string, string, error) {
+	rest := strings.TrimPrefix(location, scheme+"://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("%s: expected %s://bucket/key", location, scheme)
+	}
+	return bucket, key, nil
+}
+
+// s3Backend reads s3://bucket/key objects with the standard AWS environment:
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN for
+// credentials (requests are anonymous without them), AWS_REGION or
+// AWS_DEFAULT_REGION for the region, and AWS_ENDPOINT_URL_S3 or
+// AWS_ENDPOINT_URL for S3-compatible stores, which are addressed path-style.
+type s3Backend struct{}
+
+func (s3Backend) Open(location string) (Object, error) {
+	bucket, key, err := splitBucketURL(location, "s3")
+	if err != nil {
+		return nil, err
+	}
+	region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	var u *url.URL
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		u, err = objectURL(endpoint, bucket+"/"+key)
+	} else {
+		u, err = objectURL(fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region), key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+
+	creds := awsCredentials{
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		region:       region,
+	}
+	obj, err := openRangeObject(func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if creds.accessKey != "" {
+			creds.sign(req, time.Now().UTC())
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	return obj, nil
+}
+
+// gcsBackend reads gs://bucket/key objects through the Cloud Storage XML API. GOOGLE_OAUTH_ACCESS_TOKEN supplies a bearer token (requests
+// are anonymous without it); STORAGE_EMULATOR_HOST points at an emulator.
+type gcsBackend struct{}
+
+func (gcsBackend) Open(location string) (Object, error) {
+	bucket, key, err := splitBucketURL(location, "gs")
+	if err != nil {
+		return nil, err
+	}
+	base := "https://storage.googleapis.com"
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		base = host
+		if !strings.Contains(base, "://") {
+			base = "http://" + base
+		}
+	}
+	u, err := objectURL(base, bucket+"/"+key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+
+	token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	obj, err := openRangeObject(func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	return obj, nil
+}
+
+// objectURL appends an object path to a service endpoint. The path is set
+// unescaped so keys containing "?", "#", or spaces are encoded, not parsed.
+func objectURL(endpoint, objectPath string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + objectPath
+	u.RawPath = ""
+	return u, nil
+}
+
+func firstEnv(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+type awsCredentials struct {
+	accessKey, secretKey, sessionToken, region string
+}
+
+// emptySHA256 is the hex SHA-256 of an empty body, used for GET requests.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sign adds an AWS Signature Version 4 Authorization header to a GET request.
+// The Range header is set after signing and is deliberately left unsigned.
+func (c awsCredentials) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptySHA256)
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, vals := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(vals, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, name := range names {
+		canonHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		awsURIEncode(req.URL.Path),
+		req.URL.RawQuery,
+		canonHeaders.String(),
+		signedHeaders,
+		emptySHA256,
+	}, "\n")
+	scope := day + "/" + c.region + "/s3/aws4_request"
+	canonHash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), day)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, sig))
+}
+
+// awsURIEncode escapes a path the way SigV4 expects: every byte except
+// unreserved characters and "/" is percent-encoded.
+func awsURIEncode(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+	"github.com/immutable-container/imf/pkg/timelock"
+)
+
+// TimeLockOptions configures time-lock encryption (see package timelock).
+// The files are encrypted with a random key that is itself encrypted to the
+// drand round published at Until, so nobody — the sealer included — can
+// decrypt them before then, and anyone can afterwards. It is the
+// counterpart of an expiry, which only blocks access after a date.
+type TimeLockOptions struct {
+	Until time.Time // earliest time the files can be decrypted
+	URL   string    // drand endpoint; defaults to timelock.DefaultURL
+	Chain string    // drand chain hash; defaults to timelock.DefaultChain
+}
+
+// ErrTimeLocked is returned when a time-locked container is opened before
+// its unlock time.
+var ErrTimeLocked = errors.New("container is time-locked")
+
+// kdfTimeLock is recorded in EncryptionInfo.KDF for time-locked containers.
+const kdfTimeLock = "drand-tlock-BLS12-381"
+
+// lockContentKey encrypts key to the drand round published at o.Until,
+// returning the manifest's record of it.
+func lockContentKey(o *TimeLockOptions, key []byte) (*manifest.TimeLock, error) {
+	if !o.Until.After(time.Now()) {
+		return nil, fmt.Errorf("time lock %s is not in the future", o.Until.Format(time.RFC3339))
+	}
+	url, hash := o.URL, o.Chain
+	if url == "" {
+		url = timelock.DefaultURL
+	}
+	if hash == "" {
+		hash = timelock.DefaultChain
+	}
+	chain, err := timelock.FetchChain(url, hash)
+	if err != nil {
+		return nil, err
+	}
+	round := chain.RoundAt(o.Until)
+	ct, err := timelock.Lock(chain.PublicKey, round, key)
+	if err != nil {
+		return nil, fmt.Errorf("time-locking content key: %w", err)
+	}
+	return &manifest.TimeLock{
+		URL:        url,
+		Chain:      hash,
+		PublicKey:  hex.EncodeToString(chain.PublicKey),
+		Round:      round,
+		Unlocks:    chain.RoundTime(round).UTC(),
+		Ciphertext: base64.StdEncoding.EncodeToString(ct),
+	}, nil
+}
+
+// unlockContentKey recovers a time-locked content key with the drand
+// beacon for its round. The recorded unlock time is only a hint to save a
+// request; the beacon signature is what opens the lock.
+func unlockContentKey(tl *manifest.TimeLock) ([]byte, error) {
+	if tl == nil {
+		return nil, errors.New("container is time-locked but has no time lock")
+	}
+	if time.Now().Before(tl.Unlocks) {
+		return nil, fmt.Errorf("%w until %s", ErrTimeLocked, tl.Unlocks.Format(time.RFC3339))
+	}
+	pub, err := hex.DecodeString(tl.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("decoding time-lock public key: %w", err)
+	}
+	ct, err := base64.StdEncoding.DecodeString(tl.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decoding time-locked key: %w", err)
+	}
+	sig, err := timelock.Signature(&timelock.Chain{URL: tl.URL, Hash: tl.Chain, PublicKey: pub}, tl.Round)
+	if errors.Is(err, timelock.ErrTooEarly) {
+		return nil, fmt.Errorf("%w until drand round %d is published (due %s)", ErrTimeLocked, tl.Round, tl.Unlocks.Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return timelock.Unlock(sig, ct)
+}
@@ -0,0 +1,113 @@
+package container_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/timelock"
+)
+
+// newDrand starts a fake drand relay publishing a round every second.
+func newDrand(t *testing.T, hash string) *httptest.Server {
+	t.Helper()
+	var secret bls12381.Scalar
+	secret.SetUint64(0x5eed)
+	var pub bls12381.G2
+	pub.ScalarMult(&secret, bls12381.G2Generator())
+	genesis := time.Now().Add(-time.Hour).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/"+hash+"/")
+		if path == "info" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"public_key":   hex.EncodeToString(pub.BytesCompressed()),
+				"period":       1,
+				"genesis_time": genesis,
+				"hash":         hash,
+				"schemeID":     timelock.Scheme,
+			})
+			return
+		}
+		round, err := strconv.ParseUint(strings.TrimPrefix(path, "public/"), 10, 64)
+		if err != nil || int64(round) > time.Now().Unix()-genesis+1 {
+			http.Error(w, "too early", http.StatusTooEarly)
+			return
+		}
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], round)
+		msg := sha256.Sum256(b[:])
+		var sig bls12381.G1
+		sig.Hash(msg[:], []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_"))
+		sig.ScalarMult(&secret, &sig)
+		json.NewEncoder(w).Encode(map[string]any{"round": round, "signature": hex.EncodeToString(sig.BytesCompressed())})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSealTimeLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "embargoed.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "results.txt")
+	os.WriteFile(src, []byte("embargoed until publication"), 0644)
+	container.Add(imfPath, []string{src})
+	kp, _ := imfcrypto.GenerateKeyPair()
+
+	hash := strings.Repeat("ab", 32)
+	srv := newDrand(t, hash)
+	lock := &container.TimeLockOptions{Until: time.Now().Add(time.Second), URL: srv.URL, Chain: hash}
+
+	err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, Passphrase: "also", TimeLock: lock})
+	if err == nil {
+		t.Fatal("expected a time lock combined with a passphrase to be rejected")
+	}
+	past := &container.TimeLockOptions{Until: time.Now().Add(-time.Minute), URL: srv.URL, Chain: hash}
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, TimeLock: past}); err == nil {
+		t.Fatal("expected a time lock in the past to be rejected")
+	}
+
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, TimeLock: lock}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify before unlock: %v", err)
+	}
+	info, err := container.GetInfo(imfPath)
+	if err != nil {
+		t.Fatalf("GetInfo: %v", err)
+	}
+	if info.TimeLock == nil || info.TimeLock.Before(lock.Until.Truncate(time.Second)) {
+		t.Fatalf("info unlock time %v, want at or after %s", info.TimeLock, lock.Until)
+	}
+
+	// Before the unlock time nobody can decrypt, whatever they supply.
+	outDir := filepath.Join(tmpDir, "out")
+	err = container.Extract(imfPath, container.ExtractOptions{OutputDir: outDir, Passphrase: "guess"})
+	if !errors.Is(err, container.ErrTimeLocked) {
+		t.Fatalf("expected ErrTimeLocked, got %v", err)
+	}
+
+	time.Sleep(time.Until(*info.TimeLock) + 100*time.Millisecond)
+	if err := container.Extract(imfPath, container.ExtractOptions{OutputDir: outDir}); err != nil {
+		t.Fatalf("Extract after unlock: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(outDir, "results.txt"))
+	if string(got) != "embargoed until publication" {
+		t.Fatalf("extracted %q", got)
+	}
+	t.Logf("✓ Time-locked container opened only after %s", info.TimeLock.Format(time.RFC3339))
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/tsa"
+)
+
+// ErrNoTimestamp is returned when VerifyOptions.TSARoots is set but the
+// container was sealed without an RFC 3161 timestamp.
+var ErrNoTimestamp = errors.New("container has no trusted timestamp")
+
+// requestTimestamp asks the TSA at url to timestamp the SHA-256 of signable,
+// the manifest bytes the sealer signed, and returns the token base64-encoded
+// for the manifest.
+func requestTimestamp(url string, signable []byte) (string, *tsa.Token, error) {
+	digest := imfcrypto.HashSHA256(signable)
+	der, err := tsa.Timestamp(url, digest[:])
+	if err != nil {
+		return "", nil, fmt.Errorf("obtaining timestamp: %w", err)
+	}
+	tok, err := tsa.Parse(der)
+	if err != nil {
+		return "", nil, fmt.Errorf("obtaining timestamp: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(der), tok, nil
+}
+
+// checkTimestamp parses a manifest's timestamp token and checks that it
+// covers signable. With roots, the TSA's certificate must chain to one of
+// them.
+func checkTimestamp(enc string, signable []byte, roots *x509.CertPool) (*tsa.Token, error) {
+	der, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, fmt.Errorf("TIMESTAMP VERIFICATION FAILED: decoding token: %w", err)
+	}
+	tok, err := tsa.Parse(der)
+	if err != nil {
+		return nil, fmt.Errorf("TIMESTAMP VERIFICATION FAILED: %w", err)
+	}
+	digest := imfcrypto.HashSHA256(signable)
+	if err := tok.Verify(digest[:], roots); err != nil {
+		return nil, fmt.Errorf("TIMESTAMP VERIFICATION FAILED: %w", err)
+	}
+	return tok, nil
+}
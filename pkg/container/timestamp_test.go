@@ -0,0 +1,47 @@
+package container_test
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+func TestSealTimestampFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "build.imf")
+	container.Create(imfPath)
+	src := filepath.Join(tmpDir, "artifact.bin")
+	os.WriteFile(src, []byte("release build"), 0644)
+	container.Add(imfPath, []string{src})
+	kp, _ := imfcrypto.GenerateKeyPair()
+
+	// A TSA that refuses leaves the container open.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true, TSAURL: srv.URL}); err == nil {
+		t.Fatal("expected seal to fail when the TSA refuses")
+	}
+	if m := readManifest(t, imfPath); m.State != manifest.StateOpen {
+		t.Fatalf("container state after failed timestamp: %s", m.State)
+	}
+
+	// Without a timestamp, requiring one from a trusted TSA fails.
+	if err := container.Seal(imfPath, container.SealOptions{PrivateKey: kp.PrivateKey, EmbedPubKey: true}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if err := container.Verify(imfPath, container.VerifyOptions{TSARoots: x509.NewCertPool()}); !errors.Is(err, container.ErrNoTimestamp) {
+		t.Fatalf("expected ErrNoTimestamp, got %v", err)
+	}
+
+	t.Logf("✓ Failed timestamp leaves container open; missing timestamp detected")
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// WitnessOptions configures Witness.
+type WitnessOptions struct {
+	Name   string        // optional name recorded (and signed) with the witness
+	Verify VerifyOptions // the container must verify before it is witnessed
+}
+
+// WitnessInfo is one witness countersignature.
+type WitnessInfo struct {
+	PublicKey string // base64 Ed25519 public key
+	Name      string
+	Timestamp time.Time
+}
+
+// Witness adds a countersignature by key to a sealed container: a signed
+// statement that the witness saw this manifest at the current time. The
+// container is verified first, so nobody can witness a broken container.
+// Witnesses are kept outside the signed manifest bytes, like cosignatures,
+// and each one signs the previous one, making a notarization chain.
+func Witness(containerPath string, key ed25519.PrivateKey, opts WitnessOptions) (*WitnessInfo, error) {
+	signer, err := imfcrypto.NewKeySigner(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	unlock, err := lockContainer(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := Verify(containerPath, opts.Verify); err != nil {
+		return nil, fmt.Errorf("container does not verify: %w", err)
+	}
+	m, zipData, err := readContainer(containerPath)
+	if err != nil {
+		return nil, err
+	}
+	w, err := addWitness(m, signer, opts.Name, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readZipEntries(zipData, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := rewriteContainer(containerPath, m, entries, nil); err != nil {
+		return nil, err
+	}
+	return &WitnessInfo{PublicKey: w.PublicKey, Name: w.Name, Timestamp: w.Timestamp}, nil
+}
+
+// addWitness signs m's manifest hash, the time, and the link to the previous
+// witness with signer, and appends the result to m.
+func addWitness(m *manifest.Manifest, signer imfcrypto.Signer, name string, at time.Time) (*manifest.Witness, error) {
+	signable, err := m.SignableBytes()
+	if err != nil {
+		return nil, fmt.Errorf("computing signable bytes: %w", err)
+	}
+	digest := imfcrypto.HashSHA256(signable)
+	w := manifest.Witness{
+		PublicKey:      base64.StdEncoding.EncodeToString(signer.Public()),
+		Name:           name,
+		Timestamp:      at,
+		ManifestSHA256: hex.EncodeToString(digest[:]),
+	}
+	if n := len(m.Witnesses); n > 0 {
+		if w.Previous, err = witnessLink(m.Witnesses[n-1]); err != nil {
+			return nil, err
+		}
+	}
+	wb, err := w.SignableBytes()
+	if err != nil {
+		return nil, fmt.Errorf("computing witness bytes: %w", err)
+	}
+	sig, err := signer.Sign(wb)
+	if err != nil {
+		return nil, fmt.Errorf("signing witness: %w", err)
+	}
+	w.Signature = base64.StdEncoding.EncodeToString(sig)
+	m.Witnesses = append(m.Witnesses, w)
+	return &m.Witnesses[len(m.Witnesses)-1], nil
+}
+
+// witnessLink is the value the next witness records as Previous: the hex
+// SHA-256 of w's signature.
+func witnessLink(w manifest.Witness) (string, error) {
+	sig, err := base64.StdEncoding.DecodeString(w.Signature)
+	if err != nil {
+		return "", fmt.Errorf("decoding witness signature: %w", err)
+	}
+	sum := imfcrypto.HashSHA256(sig)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// checkWitnesses verifies every witness in m against the manifest's
+// signable bytes and the chain links between them.
+func checkWitnesses(m *manifest.Manifest, signable []byte) ([]WitnessInfo, error) {
+	if len(m.Witnesses) == 0 {
+		return nil, nil
+	}
+	digest := imfcrypto.HashSHA256(signable)
+	want := hex.EncodeToString(digest[:])
+	var infos []WitnessInfo
+	prev := ""
+	for i, w := range m.Witnesses {
+		fail := func(reason string) error {
+			return fmt.Errorf("WITNESS VERIFICATION FAILED: witness %d: %s", i+1, reason)
+		}
+		if w.ManifestSHA256 != want {
+			return nil, fail("signed a different manifest")
+		}
+		if w.Previous != prev {
+			return nil, fail("witness chain is broken")
+		}
+		key, err := base64.StdEncoding.DecodeString(w.PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fail("invalid public key")
+		}
+		sig, err := base64.StdEncoding.DecodeString(w.Signature)
+		if err != nil {
+			return nil, fail("invalid signature encoding")
+		}
+		wb, err := w.SignableBytes()
+		if err != nil {
+			return nil, fmt.Errorf("computing witness bytes: %w", err)
+		}
+		if !imfcrypto.Verify(ed25519.PublicKey(key), wb, sig) {
+			return nil, fail("bad signature")
+		}
+		link := imfcrypto.HashSHA256(sig)
+		prev = hex.EncodeToString(link[:])
+		infos = append(infos, WitnessInfo{PublicKey: w.PublicKey, Name: w.Name, Timestamp: w.Timestamp})
+	}
+	return infos, nil
+}
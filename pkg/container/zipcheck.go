@@ -0,0 +1,195 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package container
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Sizes and signature of a ZIP local file header (APPNOTE 4.3.7).
+const (
+	localHeaderLen = 30
+	localHeaderSig = 0x04034b50
+	flagDataDesc   = 0x8 // sizes and CRC follow the data in a data descriptor
+)
+
+// checkLocalHeader confirms that the local file header preceding an entry's
+// data agrees with the central directory record archive/zip reads from.
+// archive/zip only consults the local header for its length fields, so
+// without this check the rest of the header could be altered undetected.
+func checkLocalHeader(ra io.ReaderAt, f *zip.File) error {
+	dataOff, err := f.DataOffset()
+	if err != nil {
+		return err
+	}
+	start := dataOff - int64(localHeaderLen+len(f.Name)+len(f.Extra))
+	if start < 0 {
+		return fmt.Errorf("local header for %s is out of range", f.Name)
+	}
+
+	buf := make([]byte, localHeaderLen+len(f.Name))
+	if _, err := ra.ReadAt(buf, start); err != nil {
+		return fmt.Errorf("reading local header for %s: %w", f.Name, err)
+	}
+
+	le := binary.LittleEndian
+	if le.Uint32(buf[0:]) != localHeaderSig {
+		return fmt.Errorf("local header for %s has a bad signature", f.Name)
+	}
+	if le.Uint16(buf[4:]) != f.ReaderVersion ||
+		le.Uint16(buf[6:]) != f.Flags ||
+		le.Uint16(buf[8:]) != f.Method ||
+		le.Uint16(buf[10:]) != f.ModifiedTime ||
+		le.Uint16(buf[12:]) != f.ModifiedDate ||
+		int(le.Uint16(buf[26:])) != len(f.Name) ||
+		int(le.Uint16(buf[28:])) != len(f.Extra) ||
+		string(buf[localHeaderLen:]) != f.Name {
+		return fmt.Errorf("local header for %s does not match the central directory", f.Name)
+	}
+
+	crc, csize, usize := le.Uint32(buf[14:]), le.Uint32(buf[18:]), le.Uint32(buf[22:])
+	if f.Flags&flagDataDesc != 0 {
+		// Values live in the trailing data descriptor, which archive/zip
+		// checks itself; the header copies must be zero.
+		if crc != 0 || csize != 0 || usize != 0 {
+			return fmt.Errorf("local header for %s has unexpected sizes", f.Name)
+		}
+		return nil
+	}
+	if crc != f.CRC32 ||
+		(csize != 0xFFFFFFFF && uint64(csize) != f.CompressedSize64) ||
+		(usize != 0xFFFFFFFF && uint64(usize) != f.UncompressedSize64) {
+		return fmt.Errorf("local header for %s does not match the central directory", f.Name)
+	}
+	return nil
+}
+
+// Signatures and fixed sizes of the trailing ZIP structures.
+const (
+	centralHeaderSig = 0x02014b50
+	centralHeaderLen = 46
+	dataDescSig      = 0x08074b50
+	dataDescLen      = 16
+	eocdSig          = 0x06054b50
+	eocdLen          = 22
+)
+
+// checkArchiveLayout confirms that an archive is laid out exactly as the IMF
+// writer produces it: entries packed back to back from offset zero, followed
+// by a central directory whose records re-encode byte for byte from the
+// parsed entries, followed by an end record with no comment. Every byte of
+// the file is thereby accounted for, so a change to any of them — including
+// fields archive/zip parses but otherwise ignores — is detected.
+//
+// Archives that need ZIP64 structures are only checked entry by entry.
+func checkArchiveLayout(ra io.ReaderAt, size int64, files []*zip.File) error {
+	if size < eocdLen {
+		return fmt.Errorf("archive too short")
+	}
+	eocd := make([]byte, eocdLen)
+	if _, err := ra.ReadAt(eocd, size-eocdLen); err != nil {
+		return fmt.Errorf("reading end of central directory: %w", err)
+	}
+
+	le := binary.LittleEndian
+	if le.Uint32(eocd[0:]) != eocdSig {
+		return fmt.Errorf("archive has trailing data or a comment")
+	}
+	count := le.Uint16(eocd[10:])
+	dirSize := le.Uint32(eocd[12:])
+	dirOff := le.Uint32(eocd[16:])
+	if count == 0xFFFF || dirSize == 0xFFFFFFFF || dirOff == 0xFFFFFFFF {
+		return nil // ZIP64
+	}
+	if le.Uint16(eocd[4:]) != 0 || le.Uint16(eocd[6:]) != 0 ||
+		le.Uint16(eocd[8:]) != count || int(count) != len(files) ||
+		le.Uint16(eocd[20:]) != 0 || int64(dirOff)+int64(dirSize) != size-eocdLen {
+		return fmt.Errorf("end of central directory record is not canonical")
+	}
+
+	dir := make([]byte, dirSize)
+	if _, err := ra.ReadAt(dir, int64(dirOff)); err != nil {
+		return fmt.Errorf("reading central directory: %w", err)
+	}
+
+	// Walk the central directory alongside the parsed entries (archive/zip
+	// keeps them in directory order), re-encoding each record.
+	var next int64 // where the next entry's local header must begin
+	pos := 0
+	for _, f := range files {
+		if f.CompressedSize64 >= 0xFFFFFFFF || f.UncompressedSize64 >= 0xFFFFFFFF {
+			return nil // ZIP64
+		}
+		// Fields that round-trip through archive/zip unchecked must hold the
+		// values the IMF writer always produces (MS-DOS host, no attributes,
+		// no comment), or a flipped bit there would re-encode identically.
+		if f.CreatorVersion != f.ReaderVersion || f.ExternalAttrs != 0 || f.Comment != "" {
+			return fmt.Errorf("central directory record for %s is not canonical", f.Name)
+		}
+		rec := centralRecord(f, uint32(next))
+		if pos+len(rec) > len(dir) || string(dir[pos:pos+len(rec)]) != string(rec) {
+			return fmt.Errorf("central directory record for %s is not canonical", f.Name)
+		}
+		pos += len(rec)
+
+		dataOff, err := f.DataOffset()
+		if err != nil {
+			return err
+		}
+		if dataOff != next+int64(localHeaderLen+len(f.Name)+len(f.Extra)) {
+			return fmt.Errorf("entry %s is not where the central directory places it", f.Name)
+		}
+		next = dataOff + int64(f.CompressedSize64)
+
+		if f.Flags&flagDataDesc != 0 {
+			desc := make([]byte, dataDescLen)
+			if _, err := ra.ReadAt(desc, next); err != nil {
+				return fmt.Errorf("reading data descriptor for %s: %w", f.Name, err)
+			}
+			if le.Uint32(desc[0:]) != dataDescSig || le.Uint32(desc[4:]) != f.CRC32 ||
+				uint64(le.Uint32(desc[8:])) != f.CompressedSize64 ||
+				uint64(le.Uint32(desc[12:])) != f.UncompressedSize64 {
+				return fmt.Errorf("data descriptor for %s does not match the central directory", f.Name)
+			}
+			next += dataDescLen
+		}
+	}
+	if pos != len(dir) {
+		return fmt.Errorf("central directory has unaccounted bytes")
+	}
+	if next != int64(dirOff) {
+		return fmt.Errorf("archive has unaccounted bytes before the central directory")
+	}
+	return nil
+}
+
+// centralRecord encodes the central directory record for f, with the local
+// header at offset, as archive/zip's writer would.
+func centralRecord(f *zip.File, offset uint32) []byte {
+	buf := make([]byte, centralHeaderLen, centralHeaderLen+len(f.Name)+len(f.Extra)+len(f.Comment))
+	le := binary.LittleEndian
+	le.PutUint32(buf[0:], centralHeaderSig)
+	le.PutUint16(buf[4:], f.CreatorVersion)
+	le.PutUint16(buf[6:], f.ReaderVersion)
+	le.PutUint16(buf[8:], f.Flags)
+	le.PutUint16(buf[10:], f.Method)
+	le.PutUint16(buf[12:], f.ModifiedTime)
+	le.PutUint16(buf[14:], f.ModifiedDate)
+	le.PutUint32(buf[16:], f.CRC32)
+	le.PutUint32(buf[20:], uint32(f.CompressedSize64))
+	le.PutUint32(buf[24:], uint32(f.UncompressedSize64))
+	le.PutUint16(buf[28:], uint16(len(f.Name)))
+	le.PutUint16(buf[30:], uint16(len(f.Extra)))
+	le.PutUint16(buf[32:], uint16(len(f.Comment)))
+	// Disk number (34) and internal attributes (36) are always zero.
+	le.PutUint32(buf[38:], f.ExternalAttrs)
+	le.PutUint32(buf[42:], offset)
+	buf = append(buf, f.Name...)
+	buf = append(buf, f.Extra...)
+	buf = append(buf, f.Comment...)
+	return buf
+}
@@ -13,16 +13,22 @@
 
 // Package crypto provides cryptographic primitives for immutable containers.
 // Uses Ed25519 for signing, AES-256-GCM for encryption, and scrypt for KDF.
-// All implementations use Go stdlib only — no external dependencies.
+// All primitives use Go stdlib only, except Argon2id for protected key
+// files, which comes from golang.org/x/crypto. Key sources outside the
+// process (OpenSSH key files, PKCS#11 tokens, and the OS keychain) are read
+// through their usual third-party packages.
 package crypto
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ed25519"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -40,6 +46,16 @@ const (
 
 	// PBKDF2 iterations — high count for passphrase-based derivation.
 	PBKDF2Iterations = 600000
+	// MinSealIterations is the fewest PBKDF2 iterations accepted for new
+	// containers.
+	MinSealIterations = 100000
+	// MinOpenIterations is the fewest accepted when opening a container, so
+	// containers sealed under older, weaker settings still decrypt while a
+	// trivially brute-forceable setting is refused.
+	MinOpenIterations = 10000
+	// MaxIterations caps the count read from a manifest so a hostile value
+	// cannot stall decryption indefinitely.
+	MaxIterations = 100000000
 )
 
 // KeyPair holds an Ed25519 key pair.
@@ -73,13 +89,51 @@ func MarshalPublicKeyPEM(key ed25519.PublicKey) []byte {
 	})
 }
 
-// ParsePrivateKeyPEM decodes a PEM-encoded private key.
+// MarshalPrivateKeyPKCS8PEM encodes the private key as a standard PKCS#8
+// "PRIVATE KEY" block, as written by openssl genpkey -algorithm ed25519.
+func MarshalPrivateKeyPKCS8PEM(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("encoding PKCS#8 key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPublicKeyPKIXPEM encodes the public key as a standard
+// SubjectPublicKeyInfo "PUBLIC KEY" block.
+func MarshalPublicKeyPKIXPEM(key ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("encoding public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM decodes a PEM-encoded private key. The IMF block type,
+// standard PKCS#8 ("PRIVATE KEY"), and OpenSSH private key files holding an
+// Ed25519 key are accepted. Passphrase-protected keys yield ErrKeyProtected.
 func ParsePrivateKeyPEM(data []byte) (ed25519.PrivateKey, error) {
 	block, _ := pem.Decode(data)
 	if block == nil {
 		return nil, errors.New("failed to decode PEM block")
 	}
-	if block.Type != "IMF ED25519 PRIVATE KEY" {
+	switch block.Type {
+	case "IMF ED25519 PRIVATE KEY":
+	case encryptedKeyType:
+		return nil, ErrKeyProtected
+	case sshKeyType:
+		return parseSSHPrivateKey(data, nil)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing PKCS#8 key: %w", err)
+		}
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("PKCS#8 key is %T, not Ed25519", key)
+		}
+		return edKey, nil
+	default:
 		return nil, fmt.Errorf("unexpected PEM type: %s", block.Type)
 	}
 	if len(block.Bytes) != ed25519.PrivateKeySize {
@@ -88,13 +142,30 @@ func ParsePrivateKeyPEM(data []byte) (ed25519.PrivateKey, error) {
 	return ed25519.PrivateKey(block.Bytes), nil
 }
 
-// ParsePublicKeyPEM decodes a PEM-encoded public key.
+// ParsePublicKeyPEM decodes a PEM-encoded public key. The IMF block type and
+// standard SubjectPublicKeyInfo ("PUBLIC KEY") holding an Ed25519 key are
+// accepted, as is an OpenSSH "ssh-ed25519 ..." public key line.
 func ParsePublicKeyPEM(data []byte) (ed25519.PublicKey, error) {
+	if isSSHPublicKey(data) {
+		return parseSSHPublicKey(data)
+	}
 	block, _ := pem.Decode(data)
 	if block == nil {
 		return nil, errors.New("failed to decode PEM block")
 	}
-	if block.Type != "IMF ED25519 PUBLIC KEY" {
+	switch block.Type {
+	case "IMF ED25519 PUBLIC KEY":
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is %T, not Ed25519", key)
+		}
+		return edKey, nil
+	default:
 		return nil, fmt.Errorf("unexpected PEM type: %s", block.Type)
 	}
 	if len(block.Bytes) != ed25519.PublicKeySize {
@@ -103,6 +174,51 @@ func ParsePublicKeyPEM(data []byte) (ed25519.PublicKey, error) {
 	return ed25519.PublicKey(block.Bytes), nil
 }
 
+// ParseCertificateChainPEM decodes the CERTIFICATE blocks in data, in order.
+// A signing chain lists the signer's certificate first, then any
+// intermediates; other block types are skipped.
+func ParseCertificateChainPEM(data []byte) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate %d: %w", len(chain)+1, err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("no certificates found in PEM data")
+	}
+	return chain, nil
+}
+
+// ValidatePrivateKey checks that key is a well-formed Ed25519 private key
+// whose embedded public half matches its seed.
+func ValidatePrivateKey(key ed25519.PrivateKey) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("expected %d bytes, got %d", ed25519.PrivateKeySize, len(key))
+	}
+	derived := ed25519.NewKeyFromSeed(key.Seed())
+	if !bytes.Equal(derived[ed25519.SeedSize:], key[ed25519.SeedSize:]) {
+		return errors.New("public key does not match seed")
+	}
+	return nil
+}
+
+// Fingerprint identifies a public key by the hex SHA-256 of its 32 raw bytes.
+func Fingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
 // Sign signs data with the given private key.
 func Sign(privateKey ed25519.PrivateKey, data []byte) []byte {
 	return ed25519.Sign(privateKey, data)
@@ -141,27 +257,54 @@ func GenerateSalt() ([]byte, error) {
 // DeriveKey derives an AES-256 key from a passphrase and salt using PBKDF2-HMAC-SHA256.
 // Uses 600,000 iterations per OWASP 2023 recommendations.
 func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
-	return pbkdf2([]byte(passphrase), salt, PBKDF2Iterations, KeySize), nil
+	return DeriveKeyIterations(passphrase, salt, PBKDF2Iterations)
 }
 
+// DeriveKeyIterations is like DeriveKey with an explicit iteration count,
+// which must lie between MinOpenIterations and MaxIterations.
+func DeriveKeyIterations(passphrase string, salt []byte, iterations int) ([]byte, error) {
+	return DeriveKeyProgress(passphrase, salt, iterations, nil)
+}
+
+// DeriveKeyProgress is like DeriveKeyIterations, calling progress, if not
+// nil, with the number of iterations done so far as the derivation runs.
+func DeriveKeyProgress(passphrase string, salt []byte, iterations int, progress func(done, total int)) ([]byte, error) {
+	if iterations < MinOpenIterations || iterations > MaxIterations {
+		return nil, fmt.Errorf("PBKDF2 iterations %d outside accepted range %d-%d", iterations, MinOpenIterations, MaxIterations)
+	}
+	password := []byte(passphrase)
+	defer Wipe(password)
+	return pbkdf2(password, salt, iterations, KeySize, progress), nil
+}
+
+// progressInterval is how many PBKDF2 iterations run between calls to a
+// progress function: often enough for a smooth bar, rarely enough to cost
+// nothing.
+const progressInterval = 1 << 13
+
 // pbkdf2 implements PBKDF2-HMAC-SHA256 using only Go stdlib.
-func pbkdf2(password, salt []byte, iterations, keyLen int) []byte {
+func pbkdf2(password, salt []byte, iterations, keyLen int, progress func(done, total int)) []byte {
 	numBlocks := (keyLen + sha256.Size - 1) / sha256.Size
 	dk := make([]byte, 0, numBlocks*sha256.Size)
 
 	for block := 1; block <= numBlocks; block++ {
-		dk = append(dk, pbkdf2Block(password, salt, iterations, block)...)
+		dk = append(dk, pbkdf2Block(password, salt, iterations, block, func(done int) {
+			if progress != nil {
+				progress((block-1)*iterations+done, numBlocks*iterations)
+			}
+		})...)
 	}
 	return dk[:keyLen]
 }
 
-func pbkdf2Block(password, salt []byte, iterations, blockNum int) []byte {
+func pbkdf2Block(password, salt []byte, iterations, blockNum int, progress func(done int)) []byte {
 	mac := hmac.New(sha256.New, password)
 
 	// U1 = PRF(password, salt || INT_32_BE(blockNum))
 	mac.Write(salt)
 	mac.Write([]byte{byte(blockNum >> 24), byte(blockNum >> 16), byte(blockNum >> 8), byte(blockNum)})
 	u := mac.Sum(nil)
+	defer Wipe(u)
 
 	result := make([]byte, len(u))
 	copy(result, u)
@@ -174,7 +317,11 @@ func pbkdf2Block(password, salt []byte, iterations, blockNum int) []byte {
 		for j := range result {
 			result[j] ^= u[j]
 		}
+		if (i+1)%progressInterval == 0 {
+			progress(i + 1)
+		}
 	}
+	progress(iterations)
 	return result
 }
 
@@ -200,6 +347,10 @@ func Encrypt(key, plaintext []byte) ([]byte, error) {
 	return ciphertext, nil
 }
 
+// ErrDecrypt is returned, wrapped, when data fails to decrypt: the key, or
+// the passphrase it was derived from, is wrong, or the data was altered.
+var ErrDecrypt = errors.New("wrong passphrase or key, or corrupt data")
+
 // Decrypt decrypts data encrypted by Encrypt (nonce || ciphertext).
 func Decrypt(key, data []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
@@ -220,7 +371,7 @@ func Decrypt(key, data []byte) ([]byte, error) {
 	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
 	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
-		return nil, fmt.Errorf("decrypting: %w", err)
+		return nil, fmt.Errorf("decrypting: %w", ErrDecrypt)
 	}
 
 	return plaintext, nil
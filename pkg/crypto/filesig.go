@@ -0,0 +1,133 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FileSignature is a detached Ed25519 signature over the exact bytes of any
+// file, kept beside it (conventionally as NAME.sig), so the key that seals
+// containers can also sign a loose document. It has the same form as a
+// container's detached signature, so either kind checks the same way.
+type FileSignature struct {
+	PublicKey string    `json:"public_key"` // base64-encoded Ed25519 public key
+	SHA256    string    `json:"sha256"`     // hex SHA-256 of the file
+	Size      int64     `json:"size"`       // size of the file in bytes
+	SignedAt  time.Time `json:"signed_at"`  // when it was signed, by the signer's clock
+	Signature string    `json:"signature"`  // base64-encoded Ed25519 signature
+}
+
+// ErrFileSignature is returned, wrapped, when a file does not match its
+// detached signature, or the signature is not valid under the expected key.
+var ErrFileSignature = errors.New("file signature verification failed")
+
+// SignFile signs the contents read from r with signer.
+func SignFile(r io.Reader, signer Signer) (*FileSignature, error) {
+	size, digest, err := hashFile(r)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileSignature{
+		PublicKey: base64.StdEncoding.EncodeToString(signer.Public()),
+		SHA256:    digest,
+		Size:      size,
+		SignedAt:  time.Now().UTC(),
+	}
+	sb, err := s.SignableBytes()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(sb)
+	if err != nil {
+		return nil, fmt.Errorf("signing file: %w", err)
+	}
+	s.Signature = base64.StdEncoding.EncodeToString(sig)
+	return s, nil
+}
+
+// SignableBytes returns the bytes the signer signs: the JSON form of s with
+// the signature field zeroed out.
+func (s *FileSignature) SignableBytes() ([]byte, error) {
+	cp := *s
+	cp.Signature = ""
+	return json.Marshal(cp)
+}
+
+// ParseFileSignature decodes a detached file signature.
+func ParseFileSignature(data []byte) (*FileSignature, error) {
+	var s FileSignature
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing file signature: %w", err)
+	}
+	return &s, nil
+}
+
+// Verify checks that s is publicKey's signature over the contents read
+// from r.
+func (s *FileSignature) Verify(r io.Reader, publicKey ed25519.PublicKey) error {
+	key, err := base64.StdEncoding.DecodeString(s.PublicKey)
+	if err != nil || !bytes.Equal(key, publicKey) {
+		return fmt.Errorf("%w: not made by the expected key", ErrFileSignature)
+	}
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding file signature: %w", err)
+	}
+	sb, err := s.SignableBytes()
+	if err != nil {
+		return err
+	}
+	if !Verify(publicKey, sb, sig) {
+		return fmt.Errorf("%w: bad signature", ErrFileSignature)
+	}
+	size, digest, err := hashFile(r)
+	if err != nil {
+		return err
+	}
+	if size != s.Size || digest != s.SHA256 {
+		return fmt.Errorf("%w: file differs from the signed bytes", ErrFileSignature)
+	}
+	return nil
+}
+
+// SignerKey returns the public key s claims to be signed by, as recorded in
+// the signature. It is not verified; use it only to look the key up.
+func (s *FileSignature) SignerKey() (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(s.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("file signature has no valid public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// hashFile returns the size and hex SHA-256 of the contents read from r.
+func hashFile(r io.Reader) (int64, string, error) {
+	cr := &countingReader{r: r}
+	sum, err := HashReaderSHA256(cr)
+	if err != nil {
+		return 0, "", fmt.Errorf("reading file: %w", err)
+	}
+	return cr.n, hex.EncodeToString(sum[:]), nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Private keys can be kept in the operating system's credential store
+// (macOS Keychain, Windows Credential Manager, or the Secret Service via
+// libsecret on Linux) instead of a PEM file. Each key is stored under the
+// "imf" service with its name as the account, and is referred to as
+// "keychain:NAME".
+
+// keychainService is the service name imf keys are stored under.
+const keychainService = "imf"
+
+// ErrKeychainKeyNotFound is returned when no key is stored under a name.
+var ErrKeychainKeyNotFound = errors.New("no key of that name in the keychain")
+
+// KeychainKeyName returns the key name of a "keychain:NAME" key reference.
+func KeychainKeyName(ref string) (string, bool) {
+	return strings.CutPrefix(ref, "keychain:")
+}
+
+// StoreKeychainKey saves key in the OS keychain under name. An existing key
+// of the same name is never overwritten.
+func StoreKeychainKey(name string, key ed25519.PrivateKey) error {
+	if name == "" {
+		return errors.New("empty keychain key name")
+	}
+	if err := ValidatePrivateKey(key); err != nil {
+		return err
+	}
+	_, err := keyring.Get(keychainService, name)
+	if err == nil {
+		return fmt.Errorf("keychain already holds a key named %q", name)
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("reading keychain: %w", err)
+	}
+	if err := keyring.Set(keychainService, name, string(MarshalPrivateKeyPEM(key))); err != nil {
+		return fmt.Errorf("writing keychain: %w", err)
+	}
+	return nil
+}
+
+// LoadKeychainKey reads the key stored in the OS keychain under name.
+func LoadKeychainKey(name string) (ed25519.PrivateKey, error) {
+	data, err := keyring.Get(keychainService, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %q", ErrKeychainKeyNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading keychain: %w", err)
+	}
+	key, err := ParsePrivateKeyPEM([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("keychain key %q: %w", name, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// A protected private key file holds the Ed25519 key encrypted with
+// AES-256-GCM under a key derived from a passphrase with Argon2id. The
+// Argon2id parameters and salt travel in the PEM headers, so they can be
+// raised later without breaking existing key files.
+
+// encryptedKeyType is the PEM block type of a protected private key.
+const encryptedKeyType = "IMF ENCRYPTED ED25519 PRIVATE KEY"
+
+// Argon2id parameters for new protected keys (RFC 9106, second recommended
+// option: 64 MiB of memory).
+const (
+	ArgonTime    = 3
+	ArgonMemory  = 64 * 1024 // KiB
+	ArgonThreads = 4
+)
+
+// Upper bounds on Argon2id parameters read from a key file, so a hostile
+// file cannot exhaust memory or stall the process.
+const (
+	maxArgonTime   = 100
+	maxArgonMemory = 4 * 1024 * 1024 // KiB, 4 GiB
+)
+
+// ErrKeyProtected is returned by ParsePrivateKeyPEM for a passphrase-protected
+// key; use ParseEncryptedPrivateKeyPEM instead.
+var ErrKeyProtected = errors.New("private key is passphrase-protected")
+
+// MarshalEncryptedPrivateKeyPEM encrypts key under passphrase and encodes it
+// as PEM.
+func MarshalEncryptedPrivateKeyPEM(key ed25519.PrivateKey, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	salt, err := GenerateSalt()
+	if err != nil {
+		return nil, err
+	}
+	kek := argon2.IDKey([]byte(passphrase), salt, ArgonTime, ArgonMemory, ArgonThreads, KeySize)
+	defer Wipe(kek)
+	ciphertext, err := Encrypt(kek, key)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type: encryptedKeyType,
+		Headers: map[string]string{
+			"KDF":     "argon2id",
+			"Salt":    base64.StdEncoding.EncodeToString(salt),
+			"Time":    strconv.Itoa(ArgonTime),
+			"Memory":  strconv.Itoa(ArgonMemory),
+			"Threads": strconv.Itoa(ArgonThreads),
+		},
+		Bytes: ciphertext,
+	}), nil
+}
+
+// IsEncryptedPrivateKeyPEM reports whether data holds a passphrase-protected
+// private key.
+func IsEncryptedPrivateKeyPEM(data []byte) bool {
+	block, _ := pem.Decode(data)
+	return block != nil && block.Type == encryptedKeyType
+}
+
+// ParseEncryptedPrivateKeyPEM decrypts a protected private key, or an
+// encrypted OpenSSH key, with passphrase. Unprotected keys are accepted too,
+// and the passphrase ignored, so callers can pass any key file through it.
+func ParseEncryptedPrivateKeyPEM(data []byte, passphrase string) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+	switch block.Type {
+	case encryptedKeyType:
+	case sshKeyType:
+		return parseSSHPrivateKey(data, []byte(passphrase))
+	default:
+		return ParsePrivateKeyPEM(data)
+	}
+	if kdf := block.Headers["KDF"]; kdf != "argon2id" {
+		return nil, fmt.Errorf("unsupported key KDF: %q", kdf)
+	}
+	salt, err := base64.StdEncoding.DecodeString(block.Headers["Salt"])
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("invalid key salt")
+	}
+	t, err1 := strconv.ParseUint(block.Headers["Time"], 10, 32)
+	m, err2 := strconv.ParseUint(block.Headers["Memory"], 10, 32)
+	p, err3 := strconv.ParseUint(block.Headers["Threads"], 10, 8)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return nil, fmt.Errorf("invalid Argon2id parameters: %w", err)
+	}
+	if t < 1 || t > maxArgonTime || m < 8*p || m > maxArgonMemory || p < 1 {
+		return nil, fmt.Errorf("Argon2id parameters out of range: time=%d memory=%d threads=%d", t, m, p)
+	}
+
+	kek := argon2.IDKey([]byte(passphrase), salt, uint32(t), uint32(m), uint8(p), KeySize)
+	defer Wipe(kek)
+	plaintext, err := Decrypt(kek, block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting key file: %w", ErrDecrypt)
+	}
+	key := ed25519.PrivateKey(plaintext)
+	if err := ValidatePrivateKey(key); err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return key, nil
+}
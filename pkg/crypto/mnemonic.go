@@ -0,0 +1,129 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	xpbkdf2 "golang.org/x/crypto/pbkdf2"
+)
+
+// A signing key can be backed up on paper as a BIP39 recovery phrase. The
+// phrase encodes random entropy plus a checksum in words from the standard
+// English list; the Ed25519 key is derived from it as the SLIP-0010 master
+// key of the phrase's BIP39 seed (with no BIP39 passphrase), so the same
+// phrase always yields the same key.
+
+// MnemonicWords is the length of the phrases GenerateMnemonic produces,
+// encoding 256 bits of entropy.
+const MnemonicWords = 24
+
+// ErrInvalidMnemonic is returned for a phrase with an unknown word, the
+// wrong number of words, or a bad checksum — usually a transcription error.
+var ErrInvalidMnemonic = errors.New("invalid recovery phrase")
+
+//go:embed bip39_english.txt
+var bip39English string
+
+var bip39Words = sync.OnceValues(func() ([]string, map[string]int) {
+	words := strings.Fields(bip39English)
+	index := make(map[string]int, len(words))
+	for i, w := range words {
+		index[w] = i
+	}
+	return words, index
+})
+
+// GenerateMnemonic returns a new random 24-word recovery phrase.
+func GenerateMnemonic() (string, error) {
+	entropy := make([]byte, MnemonicWords*4/3)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", fmt.Errorf("generating entropy: %w", err)
+	}
+	return MnemonicFromEntropy(entropy)
+}
+
+// MnemonicFromEntropy encodes 16 to 32 bytes of entropy, in steps of 4, as
+// a BIP39 phrase of 12 to 24 words.
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	n := len(entropy)
+	if n < 16 || n > 32 || n%4 != 0 {
+		return "", fmt.Errorf("entropy must be 16 to 32 bytes in steps of 4, got %d", n)
+	}
+	// The checksum is the first n/4 bits of the entropy's SHA-256, appended
+	// to it; every 11 bits then pick a word.
+	sum := sha256.Sum256(entropy)
+	bits := append(append([]byte{}, entropy...), sum[0])
+	words, _ := bip39Words()
+	phrase := make([]string, (n*8+n/4)/11)
+	for i := range phrase {
+		phrase[i] = words[readBits(bits, i*11, 11)]
+	}
+	return strings.Join(phrase, " "), nil
+}
+
+// KeyPairFromMnemonic re-derives the key pair for a recovery phrase. Case
+// and extra whitespace are ignored.
+func KeyPairFromMnemonic(phrase string) (*KeyPair, error) {
+	fields := strings.Fields(strings.ToLower(phrase))
+	if err := checkMnemonic(fields); err != nil {
+		return nil, err
+	}
+	seed := xpbkdf2.Key([]byte(strings.Join(fields, " ")), []byte("mnemonic"), 2048, 64, sha512.New)
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	priv := ed25519.NewKeyFromSeed(mac.Sum(nil)[:ed25519.SeedSize])
+	return &KeyPair{PublicKey: priv.Public().(ed25519.PublicKey), PrivateKey: priv}, nil
+}
+
+// checkMnemonic checks that words are a BIP39 phrase with a valid checksum.
+func checkMnemonic(words []string) error {
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return fmt.Errorf("%w: %d words, want 12, 15, 18, 21, or 24", ErrInvalidMnemonic, len(words))
+	}
+	_, index := bip39Words()
+	bits := make([]byte, (len(words)*11+7)/8)
+	for i, w := range words {
+		v, ok := index[w]
+		if !ok {
+			return fmt.Errorf("%w: unknown word %q (word %d)", ErrInvalidMnemonic, w, i+1)
+		}
+		writeBits(bits, i*11, 11, v)
+	}
+	n := len(words) * 4 / 3 // entropy bytes
+	sum := sha256.Sum256(bits[:n])
+	if readBits(bits, n*8, n/4) != int(sum[0])>>(8-n/4) {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+	return nil
+}
+
+// readBits returns the count bits of b starting at bit offset, most
+// significant first.
+func readBits(b []byte, offset, count int) int {
+	v := 0
+	for i := offset; i < offset+count; i++ {
+		v = v<<1 | int(b[i/8]>>(7-i%8)&1)
+	}
+	return v
+}
+
+// writeBits stores the low count bits of v into b at bit offset.
+func writeBits(b []byte, offset, count, v int) {
+	for i := 0; i < count; i++ {
+		if v>>(count-1-i)&1 == 1 {
+			pos := offset + i
+			b[pos/8] |= 1 << (7 - pos%8)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+//go:build cgo
+
+package crypto
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS#11 v3.0 identifiers for Edwards-curve keys, which predate the
+// constants in the binding.
+const (
+	ckmEdDSA     = 0x1057 // CKM_EDDSA
+	ckkECEdwards = 0x40   // CKK_EC_EDWARDS
+)
+
+// PKCS11Signer signs with an Ed25519 key held in a PKCS#11 token. The key
+// never leaves the token. Call Close when done to log out and unload the
+// module.
+type PKCS11Signer struct {
+	mu      sync.Mutex // a PKCS#11 session is not safe for concurrent use
+	closed  bool
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	key     pkcs11.ObjectHandle
+	public  ed25519.PublicKey
+}
+
+// NewPKCS11Signer loads the module, logs in to the slot, and finds the key
+// pair described by cfg.
+func NewPKCS11Signer(cfg PKCS11Config) (*PKCS11Signer, error) {
+	ctx := pkcs11.New(cfg.ModulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("cannot load PKCS#11 module %s", cfg.ModulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("initializing PKCS#11 module: %w", err)
+	}
+	s := &PKCS11Signer{ctx: ctx}
+	var err error
+	if s.session, err = ctx.OpenSession(cfg.Slot, pkcs11.CKF_SERIAL_SESSION); err != nil {
+		s.unload()
+		return nil, fmt.Errorf("opening session on slot %d: %w", cfg.Slot, err)
+	}
+	if err := ctx.Login(s.session, pkcs11.CKU_USER, cfg.PIN); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		s.Close()
+		return nil, fmt.Errorf("logging in to slot %d: %w", cfg.Slot, err)
+	}
+
+	if s.key, err = s.findKey(pkcs11.CKO_PRIVATE_KEY, cfg.Label); err != nil {
+		s.Close()
+		return nil, err
+	}
+	pubHandle, err := s.findKey(pkcs11.CKO_PUBLIC_KEY, cfg.Label)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	attrs, err := ctx.GetAttributeValue(s.session, pubHandle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil || len(attrs) != 1 {
+		s.Close()
+		return nil, fmt.Errorf("reading public key: %w", err)
+	}
+	if s.public, err = decodeECPoint(attrs[0].Value); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// findKey returns the single Ed25519 key object of the given class, matching
+// label if it is not empty.
+func (s *PKCS11Signer) findKey(class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, ckkECEdwards),
+	}
+	if label != "" {
+		template = append(template, pkcs11.NewAttribute(pkcs11.CKA_LABEL, label))
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, fmt.Errorf("searching token: %w", err)
+	}
+	defer s.ctx.FindObjectsFinal(s.session)
+	objs, _, err := s.ctx.FindObjects(s.session, 2)
+	if err != nil {
+		return 0, fmt.Errorf("searching token: %w", err)
+	}
+	kind := "private"
+	if class == pkcs11.CKO_PUBLIC_KEY {
+		kind = "public"
+	}
+	switch len(objs) {
+	case 0:
+		return 0, fmt.Errorf("no Ed25519 %s key labeled %q on token", kind, label)
+	case 1:
+		return objs[0], nil
+	}
+	return 0, fmt.Errorf("several Ed25519 %s keys on token; choose one with pkcs11:LABEL", kind)
+}
+
+// decodeECPoint extracts the 32-byte key from CKA_EC_POINT, which tokens
+// store either raw or wrapped in a DER OCTET STRING.
+func decodeECPoint(v []byte) (ed25519.PublicKey, error) {
+	if len(v) == ed25519.PublicKeySize+2 && v[0] == 0x04 && v[1] == ed25519.PublicKeySize {
+		v = v[2:]
+	}
+	if len(v) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("unexpected Ed25519 public key encoding (%d bytes)", len(v))
+	}
+	return ed25519.PublicKey(v), nil
+}
+
+// Public returns the token key's public half.
+func (s *PKCS11Signer) Public() ed25519.PublicKey {
+	return s.public
+}
+
+// Sign signs message on the token with CKM_EDDSA. The signature is checked
+// against the public key before it is returned.
+func (s *PKCS11Signer) Sign(message []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrSignerClosed
+	}
+	mech := []*pkcs11.Mechanism{pkcs11.NewMechanism(ckmEdDSA, nil)}
+	if err := s.ctx.SignInit(s.session, mech, s.key); err != nil {
+		return nil, fmt.Errorf("PKCS#11 sign: %w", err)
+	}
+	sig, err := s.ctx.Sign(s.session, message)
+	if err != nil {
+		return nil, fmt.Errorf("PKCS#11 sign: %w", err)
+	}
+	if !Verify(s.public, message, sig) {
+		return nil, errors.New("PKCS#11 signature does not verify against the token's public key")
+	}
+	return sig, nil
+}
+
+// Close logs out, closes the session, and unloads the module. Like a key
+// signer's, it may be called more than once, and while another goroutine
+// signs.
+func (s *PKCS11Signer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.ctx.Logout(s.session)
+	s.ctx.CloseSession(s.session)
+	s.unload()
+	return nil
+}
+
+func (s *PKCS11Signer) unload() {
+	s.ctx.Finalize()
+	s.ctx.Destroy()
+}
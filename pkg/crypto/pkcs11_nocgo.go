@@ -0,0 +1,28 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+//go:build !cgo
+
+package crypto
+
+import (
+	"crypto/ed25519"
+	"errors"
+)
+
+// PKCS11Signer is unavailable in builds without cgo, which is needed to load
+// a PKCS#11 module.
+type PKCS11Signer struct{}
+
+// NewPKCS11Signer always fails in builds without cgo.
+func NewPKCS11Signer(cfg PKCS11Config) (*PKCS11Signer, error) {
+	return nil, errors.New("PKCS#11 support requires a build with cgo enabled")
+}
+
+func (s *PKCS11Signer) Public() ed25519.PublicKey { return nil }
+
+func (s *PKCS11Signer) Sign(message []byte) ([]byte, error) {
+	return nil, errors.New("PKCS#11 support requires a build with cgo enabled")
+}
+
+func (s *PKCS11Signer) Close() error { return nil }
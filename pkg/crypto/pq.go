@@ -0,0 +1,67 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Post-quantum keys are ML-DSA-65 (FIPS 204) key pairs, used alongside the
+// Ed25519 signing key so that a container stays authentic even if Ed25519 is
+// one day broken. The private key is kept as its 32-byte seed, from which the
+// full key is derived.
+
+// PQAlgorithm names the post-quantum signature scheme used in hybrid mode.
+const PQAlgorithm = "ML-DSA-65"
+
+const (
+	PQSeedSize      = 32   // size of an ML-DSA private key seed
+	PQPublicKeySize = 1952 // size of an encoded ML-DSA-65 public key
+)
+
+// ErrPQUnavailable is returned by the ML-DSA operations when imf was built
+// with a Go release that does not include crypto/mldsa.
+var ErrPQUnavailable = errors.New("ML-DSA support requires imf to be built with Go 1.27 or later")
+
+// MarshalPQPrivateKeyPEM encodes an ML-DSA-65 private key seed as PEM.
+func MarshalPQPrivateKeyPEM(seed []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "IMF ML-DSA-65 PRIVATE KEY", Bytes: seed})
+}
+
+// MarshalPQPublicKeyPEM encodes an ML-DSA-65 public key as PEM.
+func MarshalPQPublicKeyPEM(pub []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "IMF ML-DSA-65 PUBLIC KEY", Bytes: pub})
+}
+
+// ParsePQPrivateKeyPEM decodes a PEM-encoded ML-DSA-65 private key seed.
+func ParsePQPrivateKeyPEM(data []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+	if block.Type != "IMF ML-DSA-65 PRIVATE KEY" {
+		return nil, fmt.Errorf("unexpected PEM type: %s", block.Type)
+	}
+	if len(block.Bytes) != PQSeedSize {
+		return nil, fmt.Errorf("invalid ML-DSA seed size: %d", len(block.Bytes))
+	}
+	return block.Bytes, nil
+}
+
+// ParsePQPublicKeyPEM decodes a PEM-encoded ML-DSA-65 public key.
+func ParsePQPublicKeyPEM(data []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+	if block.Type != "IMF ML-DSA-65 PUBLIC KEY" {
+		return nil, fmt.Errorf("unexpected PEM type: %s", block.Type)
+	}
+	if len(block.Bytes) != PQPublicKeySize {
+		return nil, fmt.Errorf("invalid ML-DSA public key size: %d", len(block.Bytes))
+	}
+	return block.Bytes, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+//go:build go1.27
+
+package crypto
+
+import (
+	"crypto/mldsa"
+	"crypto/rand"
+	"fmt"
+)
+
+// GeneratePQKey creates a new ML-DSA-65 private key and returns its seed.
+func GeneratePQKey() ([]byte, error) {
+	seed := make([]byte, PQSeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("generating ML-DSA key: %w", err)
+	}
+	return seed, nil
+}
+
+// PQPublicKey returns the encoded public key for an ML-DSA-65 seed.
+func PQPublicKey(seed []byte) ([]byte, error) {
+	key, err := mldsa.NewPrivateKey(mldsa.MLDSA65(), seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ML-DSA key: %w", err)
+	}
+	return key.PublicKey().Bytes(), nil
+}
+
+// PQSign signs message with the ML-DSA-65 key derived from seed, using the
+// hedged (randomized) variant and an empty context string.
+func PQSign(seed, message []byte) ([]byte, error) {
+	key, err := mldsa.NewPrivateKey(mldsa.MLDSA65(), seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ML-DSA key: %w", err)
+	}
+	sig, err := key.Sign(rand.Reader, message, &mldsa.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("ML-DSA signing: %w", err)
+	}
+	return sig, nil
+}
+
+// PQVerify checks an ML-DSA-65 signature. It returns an error, rather than
+// false, if the public key is malformed.
+func PQVerify(pub, message, sig []byte) (bool, error) {
+	key, err := mldsa.NewPublicKey(mldsa.MLDSA65(), pub)
+	if err != nil {
+		return false, fmt.Errorf("invalid ML-DSA public key: %w", err)
+	}
+	return mldsa.Verify(key, message, sig, nil) == nil, nil
+}
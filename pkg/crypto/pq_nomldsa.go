@@ -0,0 +1,21 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+//go:build !go1.27
+
+package crypto
+
+// ML-DSA is only in the standard library from Go 1.27 on. Older builds can
+// still read and write ML-DSA keys but not use them.
+
+// GeneratePQKey always fails in builds without crypto/mldsa.
+func GeneratePQKey() ([]byte, error) { return nil, ErrPQUnavailable }
+
+// PQPublicKey always fails in builds without crypto/mldsa.
+func PQPublicKey(seed []byte) ([]byte, error) { return nil, ErrPQUnavailable }
+
+// PQSign always fails in builds without crypto/mldsa.
+func PQSign(seed, message []byte) ([]byte, error) { return nil, ErrPQUnavailable }
+
+// PQVerify always fails in builds without crypto/mldsa.
+func PQVerify(pub, message, sig []byte) (bool, error) { return false, ErrPQUnavailable }
@@ -0,0 +1,137 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Recipient keys are X25519 key pairs, separate from the Ed25519 signing
+// keys. Sealing to a recipient wraps the random content key with a key agreed
+// between a fresh ephemeral key and the recipient's public key, so only the
+// holder of the matching private key can unwrap it.
+
+// wrapInfo binds derived wrapping keys to this use and format version.
+const wrapInfo = "imf x25519 content key wrap v1"
+
+// GenerateRecipientKey creates a new X25519 recipient key pair.
+func GenerateRecipientKey() (*ecdh.PrivateKey, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating recipient key: %w", err)
+	}
+	return key, nil
+}
+
+// MarshalRecipientPrivateKeyPEM encodes an X25519 private key as PEM.
+func MarshalRecipientPrivateKeyPEM(key *ecdh.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "IMF X25519 PRIVATE KEY",
+		Bytes: key.Bytes(),
+	})
+}
+
+// MarshalRecipientPublicKeyPEM encodes an X25519 public key as PEM.
+func MarshalRecipientPublicKeyPEM(key *ecdh.PublicKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "IMF X25519 PUBLIC KEY",
+		Bytes: key.Bytes(),
+	})
+}
+
+// ParseRecipientPrivateKeyPEM decodes a PEM-encoded X25519 private key.
+func ParseRecipientPrivateKeyPEM(data []byte) (*ecdh.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+	if block.Type != "IMF X25519 PRIVATE KEY" {
+		return nil, fmt.Errorf("unexpected PEM type: %s", block.Type)
+	}
+	return ecdh.X25519().NewPrivateKey(block.Bytes)
+}
+
+// ParseRecipientPublicKeyPEM decodes a PEM-encoded X25519 public key.
+func ParseRecipientPublicKeyPEM(data []byte) (*ecdh.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+	if block.Type != "IMF X25519 PUBLIC KEY" {
+		return nil, fmt.Errorf("unexpected PEM type: %s", block.Type)
+	}
+	return ecdh.X25519().NewPublicKey(block.Bytes)
+}
+
+// GenerateContentKey creates a random AES-256 key for encrypting files.
+func GenerateContentKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating content key: %w", err)
+	}
+	return key, nil
+}
+
+// WrapKey encrypts contentKey for recipient. It returns the ephemeral public
+// key and the wrapped key (nonce || ciphertext); both are needed to unwrap.
+func WrapKey(recipient *ecdh.PublicKey, contentKey []byte) (ephemeral, wrapped []byte, err error) {
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating ephemeral key: %w", err)
+	}
+	shared, err := eph.ECDH(recipient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key agreement: %w", err)
+	}
+	defer Wipe(shared)
+	ephemeral = eph.PublicKey().Bytes()
+	kek := wrappingKey(shared, ephemeral, recipient.Bytes())
+	defer Wipe(kek)
+	wrapped, err = Encrypt(kek, contentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ephemeral, wrapped, nil
+}
+
+// UnwrapKey recovers a content key wrapped by WrapKey for key's public half.
+func UnwrapKey(key *ecdh.PrivateKey, ephemeral, wrapped []byte) ([]byte, error) {
+	eph, err := ecdh.X25519().NewPublicKey(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ephemeral key: %w", err)
+	}
+	shared, err := key.ECDH(eph)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement: %w", err)
+	}
+	defer Wipe(shared)
+	kek := wrappingKey(shared, ephemeral, key.PublicKey().Bytes())
+	defer Wipe(kek)
+	contentKey, err := Decrypt(kek, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping content key: %w", err)
+	}
+	return contentKey, nil
+}
+
+// wrappingKey derives the key-encryption key with HKDF-SHA256 (RFC 5869),
+// salted with both public keys so it is bound to this exact exchange.
+func wrappingKey(shared, ephemeral, recipient []byte) []byte {
+	extract := hmac.New(sha256.New, append(append([]byte{}, ephemeral...), recipient...))
+	extract.Write(shared)
+	prk := extract.Sum(nil)
+	defer Wipe(prk)
+
+	// One SHA-256 block of output is exactly KeySize bytes.
+	expand := hmac.New(sha256.New, prk)
+	expand.Write([]byte(wrapInfo))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)[:KeySize]
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Signer produces Ed25519 signatures without necessarily exposing the
+// private key, so a seal can be signed by a hardware token (a FIDO2 or PIV
+// security key) as well as by a key held in memory.
+type Signer interface {
+	// Public returns the Ed25519 public key that verifies the signatures.
+	Public() ed25519.PublicKey
+	// Sign returns the Ed25519 signature of message.
+	Sign(message []byte) ([]byte, error)
+}
+
+// ErrSignerClosed is returned by Sign once the signer has been closed.
+var ErrSignerClosed = errors.New("signer has been closed")
+
+// keySigner signs with an in-memory private key.
+type keySigner struct {
+	mu     sync.Mutex         // Close waits for a signature in progress
+	key    ed25519.PrivateKey // nil once closed
+	public ed25519.PublicKey
+}
+
+// NewKeySigner returns a Signer for an in-memory private key.
+func NewKeySigner(key ed25519.PrivateKey) (Signer, error) {
+	if err := ValidatePrivateKey(key); err != nil {
+		return nil, err
+	}
+	return &keySigner{key: key, public: key.Public().(ed25519.PublicKey)}, nil
+}
+
+func (s *keySigner) Public() ed25519.PublicKey {
+	return s.public
+}
+
+func (s *keySigner) Sign(message []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil {
+		return nil, ErrSignerClosed
+	}
+	return Sign(s.key, message), nil
+}
+
+// Close wipes the private key, which the caller passed to NewKeySigner and
+// must not use afterwards. Like an HSM session, an in-memory key is released
+// through io.Closer once the signer is no longer needed. Close may be called
+// more than once, and while another goroutine signs: it waits for that
+// signature, and later ones fail with ErrSignerClosed.
+func (s *keySigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	Wipe(s.key)
+	s.key = nil
+	return nil
+}
+
+// ExternalSigner delegates signing to a helper program, which is how
+// hardware tokens are reached: the token's vendor tooling stays out of imf,
+// and the private key never leaves the device. The program is run as
+//
+//	<program> [args...] public-key   prints the public key (any format ParsePublicKeyPEM reads)
+//	<program> [args...] sign         reads the message on stdin, prints the base64 signature
+//
+// Its stderr is passed through so it can ask the user to touch the token or
+// enter a PIN (on the terminal, since stdin carries the message).
+type ExternalSigner struct {
+	program string
+	args    []string
+	public  ed25519.PublicKey
+}
+
+// HardwareSignerProgram returns the helper program for a "hw:" key
+// reference: "hw:" selects imf-signer-fido2, and "hw:NAME" selects
+// imf-signer-NAME.
+func HardwareSignerProgram(ref string) (string, bool) {
+	name, ok := strings.CutPrefix(ref, "hw:")
+	if !ok {
+		return "", false
+	}
+	if name == "" {
+		name = "fido2"
+	}
+	return "imf-signer-" + name, true
+}
+
+// NewExternalSigner starts using program as a signer and fetches its public
+// key.
+func NewExternalSigner(program string, args ...string) (*ExternalSigner, error) {
+	s := &ExternalSigner{program: program, args: args}
+	out, err := s.run("public-key", nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.public, err = ParsePublicKeyPEM(out); err != nil {
+		return nil, fmt.Errorf("%s: reading public key: %w", program, err)
+	}
+	return s, nil
+}
+
+// Public returns the token's public key.
+func (s *ExternalSigner) Public() ed25519.PublicKey {
+	return s.public
+}
+
+// Sign asks the helper program to sign message. The signature is checked
+// against the public key before it is returned, so a misbehaving helper or
+// token cannot produce a container that fails to verify later.
+func (s *ExternalSigner) Sign(message []byte) ([]byte, error) {
+	out, err := s.run("sign", message)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: decoding signature: %w", s.program, err)
+	}
+	if !Verify(s.public, message, sig) {
+		return nil, fmt.Errorf("%s: signature does not verify against its public key", s.program)
+	}
+	return sig, nil
+}
+
+// run invokes the helper with the given command and stdin.
+func (s *ExternalSigner) run(command string, stdin []byte) ([]byte, error) {
+	cmd := exec.Command(s.program, append(append([]string{}, s.args...), command)...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("signer program %s not found in PATH", s.program)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", s.program, command, err)
+	}
+	return out, nil
+}
+
+// PKCS11Config locates an Ed25519 key pair in a PKCS#11 token, typically an
+// HSM. The key must support CKM_EDDSA (PKCS#11 v3.0).
+type PKCS11Config struct {
+	ModulePath string // path to the vendor's PKCS#11 module (.so, .dylib, or .dll)
+	Slot       uint   // slot ID holding the token
+	Label      string // CKA_LABEL of the key pair; empty matches the only Ed25519 key
+	PIN        string // user PIN
+}
+
+// PKCS11ConfigFromEnv builds a PKCS11Config for the key labeled label from
+// IMF_PKCS11_MODULE, IMF_PKCS11_SLOT (default 0), and IMF_PKCS11_PIN. The PIN
+// is only ever taken from the environment so it stays out of shell history
+// and process listings.
+func PKCS11ConfigFromEnv(label string) (PKCS11Config, error) {
+	cfg := PKCS11Config{
+		ModulePath: os.Getenv("IMF_PKCS11_MODULE"),
+		Label:      label,
+		PIN:        os.Getenv("IMF_PKCS11_PIN"),
+	}
+	if cfg.ModulePath == "" {
+		return cfg, errors.New("IMF_PKCS11_MODULE is not set")
+	}
+	if s := os.Getenv("IMF_PKCS11_SLOT"); s != "" {
+		slot, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid IMF_PKCS11_SLOT: %w", err)
+		}
+		cfg.Slot = uint(slot)
+	}
+	return cfg, nil
+}
+
+// PKCS11Label returns the key label of a "pkcs11:" or "pkcs11:LABEL" key
+// reference.
+func PKCS11Label(ref string) (string, bool) {
+	return strings.CutPrefix(ref, "pkcs11:")
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// OpenSSH keys (~/.ssh/id_ed25519 and id_ed25519.pub) are accepted wherever
+// an Ed25519 key is read, so existing SSH keys can sign and verify
+// containers. Only ed25519 keys qualify; RSA and ECDSA keys are rejected.
+
+// sshKeyType is the PEM block type of an OpenSSH private key file.
+const sshKeyType = "OPENSSH PRIVATE KEY"
+
+// parseSSHPrivateKey decodes an OpenSSH private key file. An encrypted key
+// is decrypted with passphrase; if passphrase is nil, ErrKeyProtected is
+// returned instead.
+func parseSSHPrivateKey(data []byte, passphrase []byte) (ed25519.PrivateKey, error) {
+	raw, err := ssh.ParseRawPrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == nil {
+			return nil, ErrKeyProtected
+		}
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase(data, passphrase)
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, fmt.Errorf("decrypting key file: %w", ErrDecrypt)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing OpenSSH key: %w", err)
+	}
+	switch k := raw.(type) {
+	case *ed25519.PrivateKey:
+		return *k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	}
+	return nil, fmt.Errorf("OpenSSH key is %T, not ed25519", raw)
+}
+
+// isSSHPublicKey reports whether data looks like an OpenSSH public key line.
+func isSSHPublicKey(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("ssh-"))
+}
+
+// parseSSHPublicKey decodes an OpenSSH public key line such as the contents
+// of id_ed25519.pub.
+func parseSSHPublicKey(data []byte) (ed25519.PublicKey, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing OpenSSH public key: %w", err)
+	}
+	if pub.Type() != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("OpenSSH key is %s, not ssh-ed25519", pub.Type())
+	}
+	return pub.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey), nil
+}
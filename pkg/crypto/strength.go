@@ -0,0 +1,423 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Passphrase strength is estimated the way zxcvbn does it: instead of
+// counting character classes, look for the patterns an attacker tries
+// first — common passwords, dictionary words (with capitals, l33t
+// substitutions, or reversed), repeats, sequences, keyboard rows, and
+// years — and find the cheapest way to build the passphrase from them and
+// brute-forced characters. The estimate is the number of guesses that
+// takes, so "P@ssw0rd2024!" scores far worse than its length and symbols
+// suggest, and four random common words far better.
+
+// MinPassphraseScore is the lowest score imf accepts without a warning.
+const MinPassphraseScore = 3
+
+// strengthGuessRate is the guesses per second assumed for CrackTime: an
+// offline attack on a slow hash such as PBKDF2 at 600,000 iterations.
+const strengthGuessRate = 1e4
+
+// maxStrengthRunes bounds the work of an estimate; anything beyond is long
+// enough that it cannot lower the score.
+const maxStrengthRunes = 100
+
+// Strength is the estimated strength of a passphrase.
+type Strength struct {
+	Score      int     // 0 (too guessable) to 4 (very unguessable), as in zxcvbn
+	Guesses    float64 // estimated guesses needed to find the passphrase
+	CrackTime  string  // how long those guesses take offline, e.g. "3 hours"
+	Warning    string  // what makes the passphrase weak, if it is
+	Suggestion string  // how to make it stronger, if it is weak
+}
+
+// Label names the score: "very weak" to "very strong".
+func (s Strength) Label() string {
+	return [...]string{"very weak", "weak", "fair", "strong", "very strong"}[s.Score]
+}
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// strengthDicts ranks candidate words: common passwords by popularity, and
+// the BIP39 English words, which stand in for a list of common words, all at
+// the size of the list.
+var strengthDicts = sync.OnceValues(func() (map[string]int, map[string]int) {
+	common := make(map[string]int)
+	for i, w := range strings.Fields(commonPasswordList) {
+		common[w] = i + 1
+	}
+	_, words := bip39Words()
+	return common, words
+})
+
+// l33t substitutions undone before a dictionary lookup; '1' is tried as
+// both "i" and "l".
+var l33tTables = []map[rune]rune{
+	{'4': 'a', '@': 'a', '3': 'e', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'},
+	{'4': 'a', '@': 'a', '3': 'e', '1': 'l', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'},
+}
+
+// keyboardRows are the straight runs of a US keyboard, unshifted and shifted.
+var keyboardRows = []string{
+	"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./",
+	"~!@#$%^&*()_+", "qwertyuiop{}|", "asdfghjkl:\"", "zxcvbnm<>?",
+}
+
+// Pattern kinds, for the warning.
+const (
+	patternCommon = iota
+	patternWord
+	patternRepeat
+	patternSequence
+	patternKeyboard
+	patternYear
+	patternBruteforce
+)
+
+// strengthMatch is a run of the passphrase, pw[i:j], recognized as a
+// pattern, with the log10 of the guesses it costs.
+type strengthMatch struct {
+	i, j    int
+	guesses float64 // log10
+	kind    int
+	rank    int // for patternCommon
+}
+
+// EstimateStrength estimates how hard passphrase is to guess.
+func EstimateStrength(passphrase string) Strength {
+	pw := []rune(passphrase)
+	if len(pw) > maxStrengthRunes {
+		pw = pw[:maxStrengthRunes]
+	}
+	if len(pw) == 0 {
+		return Strength{Score: 0, Guesses: 1, CrackTime: "instant", Warning: "No passphrase", Suggestion: "Use a few uncommon words"}
+	}
+	matches := strengthMatches(pw)
+	lg, seq := cheapestSequence(pw, matches)
+
+	s := Strength{Guesses: math.Pow(10, lg), CrackTime: crackTime(lg - math.Log10(strengthGuessRate))}
+	switch {
+	case lg < 3:
+		s.Score = 0
+	case lg < 6:
+		s.Score = 1
+	case lg < 8:
+		s.Score = 2
+	case lg < 10:
+		s.Score = 3
+	default:
+		s.Score = 4
+	}
+	if s.Score < MinPassphraseScore {
+		s.Warning, s.Suggestion = strengthFeedback(pw, seq)
+	}
+	return s
+}
+
+// strengthMatches finds every pattern in pw.
+func strengthMatches(pw []rune) []strengthMatch {
+	lower := []rune(strings.ToLower(string(pw)))
+	var out []strengthMatch
+
+	// Dictionary words, as written, with l33t undone, and reversed.
+	common, words := strengthDicts()
+	for i := range lower {
+		for j := i + 3; j <= len(lower) && j-i <= 24; j++ {
+			out = append(out, dictionaryMatches(pw[i:j], lower[i:j], i, common, words)...)
+		}
+	}
+
+	// Runs of one character.
+	for i := 0; i < len(pw); {
+		j := i + 1
+		for j < len(pw) && pw[j] == pw[i] {
+			j++
+		}
+		if j-i >= 3 {
+			out = append(out, strengthMatch{i: i, j: j, guesses: math.Log10(10 * float64(j-i)), kind: patternRepeat})
+		}
+		i = j
+	}
+
+	// Sequences: a constant step of 1 or 2 within digits or letters.
+	for i := 0; i+2 < len(pw); {
+		d := pw[i+1] - pw[i]
+		j := i + 1
+		if (d == 1 || d == -1 || d == 2 || d == -2) && sameClass(pw[i], pw[i+1]) {
+			for j+1 < len(pw) && pw[j+1]-pw[j] == d && sameClass(pw[j], pw[j+1]) {
+				j++
+			}
+		}
+		if j-i+1 >= 3 {
+			base := 26.0
+			if unicode.IsDigit(pw[i]) {
+				base = 10
+			}
+			if strings.ContainsRune("aAzZ019", pw[i]) {
+				base = 4
+			}
+			if d < 0 {
+				base *= 2
+			}
+			out = append(out, strengthMatch{i: i, j: j + 1, guesses: math.Log10(base * float64(j-i+1)), kind: patternSequence})
+			i = j
+			continue
+		}
+		i++
+	}
+
+	// Straight rows of keys, either direction.
+	for i := range lower {
+		for j := i + 3; j <= len(lower); j++ {
+			if !onKeyboardRow(string(lower[i:j])) {
+				break
+			}
+			out = append(out, strengthMatch{i: i, j: j, guesses: math.Log10(40 * float64(j-i)), kind: patternKeyboard})
+		}
+	}
+
+	// Years, guessed outward from the present.
+	for i := 0; i+4 <= len(pw); i++ {
+		if !allDigits(pw[i : i+4]) {
+			continue
+		}
+		y, _ := strconv.Atoi(string(pw[i : i+4]))
+		if y < 1900 || y > 2099 {
+			continue
+		}
+		out = append(out, strengthMatch{i: i, j: i + 4, guesses: math.Log10(math.Max(math.Abs(float64(y-2026)), 20)), kind: patternYear})
+	}
+	return out
+}
+
+// dictionaryMatches looks up one substring, original and lowercase, in the
+// dictionaries.
+func dictionaryMatches(orig, lower []rune, i int, common, words map[string]int) []strengthMatch {
+	var out []strengthMatch
+	variations := math.Log10(upperVariations(orig))
+	try := func(w string, extra float64) {
+		if r, ok := common[w]; ok {
+			out = append(out, strengthMatch{i: i, j: i + len(orig), guesses: math.Max(math.Log10(float64(r))+variations+extra, math.Log10(50)), kind: patternCommon, rank: r})
+		}
+		if _, ok := words[w]; ok {
+			out = append(out, strengthMatch{i: i, j: i + len(orig), guesses: math.Log10(float64(len(words))) + variations + extra, kind: patternWord})
+		}
+	}
+	w := string(lower)
+	try(w, 0)
+	try(reverse(w), math.Log10(2))
+	for _, table := range l33tTables {
+		subbed, changed := []rune(w), false
+		for k, c := range subbed {
+			if r, ok := table[c]; ok {
+				subbed[k], changed = r, true
+			}
+		}
+		if changed {
+			try(string(subbed), math.Log10(2))
+		}
+	}
+	return out
+}
+
+// cheapestSequence returns the log10 guesses of the cheapest way to cover
+// pw with matches and brute-forced runs, and that sequence. As in zxcvbn, a
+// sequence of l patterns costs l! times the product of their guesses: the
+// attacker does not know the order in which they combine.
+func cheapestSequence(pw []rune, matches []strengthMatch) (float64, []strengthMatch) {
+	n := len(pw)
+	byEnd := make([][]strengthMatch, n+1)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j <= n; j++ {
+			// Brute force costs 10 guesses a character, at least 11 for one
+			// character and 51 for more, as in zxcvbn.
+			g := float64(j - i)
+			if j-i == 1 {
+				g = math.Log10(11)
+			} else if g < math.Log10(51) {
+				g = math.Log10(51)
+			}
+			byEnd[j] = append(byEnd[j], strengthMatch{i: i, j: j, guesses: g, kind: patternBruteforce})
+		}
+	}
+
+	// best[k][l] is the cheapest product of l matches covering pw[:k].
+	inf := math.Inf(1)
+	best := make([][]float64, n+1)
+	from := make([][]strengthMatch, n+1)
+	for k := range best {
+		best[k] = make([]float64, n+1)
+		from[k] = make([]strengthMatch, n+1)
+		for l := range best[k] {
+			best[k][l] = inf
+		}
+	}
+	best[0][0] = 0
+	for k := 1; k <= n; k++ {
+		for _, m := range byEnd[k] {
+			for l := 0; l < n; l++ {
+				if c := best[m.i][l] + m.guesses; c < best[k][l+1] {
+					best[k][l+1], from[k][l+1] = c, m
+				}
+			}
+		}
+	}
+	total, bestL := inf, 0
+	for l := 1; l <= n; l++ {
+		lf, _ := math.Lgamma(float64(l + 1))
+		if c := best[n][l] + lf/math.Ln10; c < total {
+			total, bestL = c, l
+		}
+	}
+	var seq []strengthMatch
+	for k, l := n, bestL; l > 0; l-- {
+		m := from[k][l]
+		seq = append([]strengthMatch{m}, seq...)
+		k = m.i
+	}
+	return total, seq
+}
+
+// strengthFeedback explains a weak passphrase by its longest pattern.
+func strengthFeedback(pw []rune, seq []strengthMatch) (warning, suggestion string) {
+	suggestion = "Add another word or two; uncommon words are better"
+	var worst *strengthMatch
+	for k := range seq {
+		if m := &seq[k]; m.kind != patternBruteforce && (worst == nil || m.j-m.i > worst.j-worst.i) {
+			worst = m
+		}
+	}
+	if worst == nil {
+		if len(pw) < 12 {
+			return "Short passphrases are easy to guess", "Use a longer passphrase, such as a few uncommon words"
+		}
+		return "", suggestion
+	}
+	switch worst.kind {
+	case patternCommon:
+		switch {
+		case worst.rank <= 10:
+			warning = "This is a top-10 common password"
+		case worst.rank <= 100:
+			warning = "This is a top-100 common password"
+		default:
+			warning = "This is a very common password"
+		}
+	case patternWord:
+		warning = "Common words are easy to guess, even with capitals or symbols swapped in"
+	case patternRepeat:
+		warning = `Repeats like "aaa" are easy to guess`
+	case patternSequence:
+		warning = `Sequences like "abc" or "6543" are easy to guess`
+	case patternKeyboard:
+		warning = "Straight rows of keys are easy to guess"
+	case patternYear:
+		warning = "Recent years are easy to guess"
+	}
+	return warning, suggestion
+}
+
+// crackTime describes 10^lg seconds.
+func crackTime(lg float64) string {
+	secs := math.Pow(10, lg)
+	units := []struct {
+		name string
+		secs float64
+	}{{"year", 365 * 86400}, {"month", 31 * 86400}, {"day", 86400}, {"hour", 3600}, {"minute", 60}, {"second", 1}}
+	switch {
+	case secs < 1:
+		return "less than a second"
+	case secs >= 100*units[0].secs:
+		return "centuries"
+	}
+	for _, u := range units {
+		if secs >= u.secs {
+			n := int(math.Round(secs / u.secs))
+			if n == 1 {
+				return "1 " + u.name
+			}
+			return fmt.Sprintf("%d %ss", n, u.name)
+		}
+	}
+	return "less than a second"
+}
+
+// upperVariations counts the capitalizations an attacker tries for a word
+// written like s: all lowercase costs nothing extra, a leading capital or
+// all capitals twice, anything else by the number of ways to place its
+// capitals.
+func upperVariations(s []rune) float64 {
+	var upper, lower int
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0 || (upper == 1 && unicode.IsUpper(s[0])):
+		return 2
+	}
+	var v float64
+	for k := 1; k <= min(upper, lower); k++ {
+		v += binomial(upper+lower, k)
+	}
+	return v
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+	return r
+}
+
+func onKeyboardRow(s string) bool {
+	rs := reverse(s)
+	for _, row := range keyboardRows {
+		if strings.Contains(row, s) || strings.Contains(row, rs) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameClass(a, b rune) bool {
+	return unicode.IsDigit(a) && unicode.IsDigit(b) || unicode.IsLower(a) && unicode.IsLower(b) || unicode.IsUpper(a) && unicode.IsUpper(b)
+}
+
+func allDigits(s []rune) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
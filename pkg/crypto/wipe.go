@@ -0,0 +1,20 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package crypto
+
+import "runtime"
+
+// Wipe overwrites b with zeros. Private keys, derived encryption keys, and
+// passphrase bytes should be wiped, usually with defer, as soon as they are
+// no longer needed, rather than left in memory until the garbage collector
+// reuses it.
+//
+// Wiping narrows the window in which a memory dump or swap file exposes a
+// secret; it cannot guarantee erasure. Go strings cannot be wiped, and the
+// runtime may already have copied a slice (when growing a stack, say).
+func Wipe(b []byte) {
+	clear(b)
+	// Keep the writes from being optimized away as dead stores.
+	runtime.KeepAlive(b)
+}
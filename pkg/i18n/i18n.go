@@ -0,0 +1,184 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package i18n translates imf's user-facing messages.
+//
+// Messages are written in English in the source, and the English text is
+// the key: a catalog for another language maps it, format verbs and all, to
+// the translation. A message missing from a catalog is shown in English, so
+// a catalog can be completed gradually. Catalogs are JSON objects embedded
+// from locales/<lang>.json, one per language; adding a file adds a
+// language.
+//
+// The language is chosen by IMF_LANG, then the caller's own setting (the
+// config file's lang, or a browser's Accept-Language), then the POSIX
+// locale variables LC_ALL, LC_MESSAGES, and LANG, and finally English.
+// Only messages meant for people are translated: JSON output, manifest
+// contents, and anything scripts parse stay in English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// English is the source language, which needs no catalog.
+const English = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]map[string]string // by language code
+	matcher  language.Matcher
+	codes    []string // in the order matcher was built with, English first
+	loadErr  error
+)
+
+// load reads the embedded catalogs once.
+func load() error {
+	loadOnce.Do(func() {
+		catalogs = map[string]map[string]string{English: {}}
+		codes = []string{English}
+		entries, err := localeFS.ReadDir("locales")
+		if err != nil {
+			loadErr = err
+			return
+		}
+		for _, e := range entries {
+			data, err := localeFS.ReadFile(path.Join("locales", e.Name()))
+			if err != nil {
+				loadErr = err
+				return
+			}
+			var msgs map[string]string
+			if err := json.Unmarshal(data, &msgs); err != nil {
+				loadErr = fmt.Errorf("locale %s: %w", e.Name(), err)
+				return
+			}
+			code := strings.TrimSuffix(e.Name(), ".json")
+			catalogs[code] = msgs
+			codes = append(codes, code)
+		}
+		sort.Strings(codes[1:])
+		tags := make([]language.Tag, len(codes))
+		for i, c := range codes {
+			tags[i] = language.Make(c)
+		}
+		matcher = language.NewMatcher(tags)
+	})
+	return loadErr
+}
+
+// Languages returns the codes of the languages there are catalogs for,
+// English first.
+func Languages() []string {
+	if load() != nil {
+		return []string{English}
+	}
+	return append([]string(nil), codes...)
+}
+
+// Match returns the supported language that best suits prefs, which are
+// tried in order: each may be a language tag ("de-CH"), a POSIX locale
+// ("de_CH.UTF-8"), or an Accept-Language header. Empty preferences and the
+// "C" and "POSIX" locales are skipped; with nothing usable, it is English.
+func Match(prefs ...string) string {
+	if load() != nil {
+		return English
+	}
+	for _, p := range prefs {
+		tags := parsePreference(p)
+		if len(tags) == 0 {
+			continue
+		}
+		_, i, conf := matcher.Match(tags...)
+		if conf != language.No {
+			return codes[i]
+		}
+	}
+	return English
+}
+
+// parsePreference parses one preference given to Match.
+func parsePreference(p string) []language.Tag {
+	p = strings.TrimSpace(p)
+	if p == "" || p == "C" || p == "POSIX" || strings.HasPrefix(p, "C.") {
+		return nil
+	}
+	if strings.ContainsAny(p, ",;") {
+		tags, _, err := language.ParseAcceptLanguage(p)
+		if err != nil {
+			return nil
+		}
+		return tags
+	}
+	// A POSIX locale: language[_territory][.codeset][@modifier].
+	p, _, _ = strings.Cut(p, ".")
+	p, _, _ = strings.Cut(p, "@")
+	tag, err := language.Parse(strings.ReplaceAll(p, "_", "-"))
+	if err != nil {
+		return nil
+	}
+	return []language.Tag{tag}
+}
+
+// Detect returns the language to use given the caller's own setting, which
+// may be empty: IMF_LANG wins over it, and the POSIX locale variables are
+// used only if neither is set.
+func Detect(setting string) string {
+	return Match(os.Getenv("IMF_LANG"), setting, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG"))
+}
+
+// Printer formats messages in one language.
+type Printer struct {
+	lang string
+	msgs map[string]string
+}
+
+// NewPrinter returns a Printer for lang, a code returned by Match or
+// Languages. An unknown code prints English.
+func NewPrinter(lang string) *Printer {
+	if load() != nil {
+		return &Printer{lang: English}
+	}
+	msgs, ok := catalogs[lang]
+	if !ok {
+		lang, msgs = English, catalogs[English]
+	}
+	return &Printer{lang: lang, msgs: msgs}
+}
+
+// Language returns the code of the language p prints.
+func (p *Printer) Language() string { return p.lang }
+
+// Sprintf translates format, then formats it with args as fmt.Sprintf does.
+// A translation keeps the original's verbs, reordering them with explicit
+// argument indexes ("%[2]s") where the language needs to.
+func (p *Printer) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(p.Translate(format), args...)
+}
+
+// Translate returns the translation of msg, or msg itself if the catalog
+// has none.
+func (p *Printer) Translate(msg string) string {
+	if t, ok := p.msgs[msg]; ok && t != "" {
+		return t
+	}
+	return msg
+}
+
+// Messages returns p's whole catalog, for a client such as the GUI's
+// browser page to translate with. It must not be modified.
+func (p *Printer) Messages() map[string]string {
+	return p.msgs
+}
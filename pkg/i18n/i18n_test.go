@@ -0,0 +1,92 @@
+package i18n_test
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/i18n"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		prefs []string
+		want  string
+	}{
+		{[]string{"de"}, "de"},
+		{[]string{"de_CH.UTF-8"}, "de"},
+		{[]string{"fr-CA"}, "fr"},
+		{[]string{"es-MX,es;q=0.9,en;q=0.5"}, "es"},
+		{[]string{"ja-JP,de;q=0.8"}, "de"},
+		{[]string{"", "C.UTF-8", "POSIX", "fr_FR@euro"}, "fr"},
+		{[]string{"en_GB", "de"}, "en"},
+		{[]string{"ja"}, "en"},
+		{nil, "en"},
+	}
+	for _, tt := range tests {
+		if got := i18n.Match(tt.prefs...); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.prefs, got, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "es_ES.UTF-8")
+	t.Setenv("IMF_LANG", "")
+	if got := i18n.Detect(""); got != "es" {
+		t.Errorf("from LANG: got %q, want es", got)
+	}
+	if got := i18n.Detect("fr"); got != "fr" {
+		t.Errorf("setting over LANG: got %q, want fr", got)
+	}
+	t.Setenv("IMF_LANG", "de")
+	if got := i18n.Detect("fr"); got != "de" {
+		t.Errorf("IMF_LANG over setting: got %q, want de", got)
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	p := i18n.NewPrinter("de")
+	if got := p.Sprintf("Created %s", "a.imf"); got != "a.imf angelegt" {
+		t.Errorf("translated: got %q", got)
+	}
+	if got := p.Sprintf("no such message %d", 1); got != "no such message 1" {
+		t.Errorf("missing message: got %q", got)
+	}
+	if got := i18n.NewPrinter("xx").Language(); got != i18n.English {
+		t.Errorf("unknown language: got %q, want English", got)
+	}
+}
+
+// verbs matches the formatting verbs in a message, ignoring explicit
+// argument indexes, which a translation may use to reorder them.
+var verbs = regexp.MustCompile(`%(?:\[\d+\])?[a-z]`)
+
+func TestCatalogsKeepVerbs(t *testing.T) {
+	for _, lang := range i18n.Languages()[1:] {
+		for msg, tr := range i18n.NewPrinter(lang).Messages() {
+			want, got := verbList(msg), verbList(tr)
+			if len(want) != len(got) {
+				t.Errorf("%s: %q has verbs %v, translation %q has %v", lang, msg, want, tr, got)
+				continue
+			}
+			for i := range want {
+				if want[i] != got[i] {
+					t.Errorf("%s: %q has verbs %v, translation %q has %v", lang, msg, want, tr, got)
+					break
+				}
+			}
+		}
+	}
+}
+
+func verbList(s string) []string {
+	var vs []string
+	for _, v := range verbs.FindAllString(s, -1) {
+		vs = append(vs, v[len(v)-1:])
+	}
+	sort.Strings(vs)
+	return vs
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package keyring keeps named Ed25519 keys in a local directory, by default
+// ~/.imf/keys, so keys are referred to by name rather than by loose PEM files.
+//
+// Each key is stored as two files:
+//
+//	NAME.pub  the public key (PEM, mode 0644)
+//	NAME.pem  the private key file exactly as it was added (mode 0600),
+//	          present only for keys this user can sign with
+//
+// Private key files are kept in whatever form they were added in, so a
+// passphrase-protected key stays protected in the keyring.
+package keyring
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+var (
+	// ErrNotFound is returned for a key name that is not in the keyring.
+	ErrNotFound = errors.New("key not found in keyring")
+	// ErrExists is returned when adding a key under a name already in use.
+	ErrExists = errors.New("key name already in keyring")
+	// ErrNoPrivateKey is returned when a public-only key is asked to sign.
+	ErrNoPrivateKey = errors.New("keyring holds only the public key")
+)
+
+// validName matches key names: letters, digits, '.', '_', and '-', not
+// starting with '.'.
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// Key describes one key in the keyring.
+type Key struct {
+	Name        string
+	PublicKey   ed25519.PublicKey
+	Fingerprint string // see imfcrypto.Fingerprint
+	HasPrivate  bool   // the private key file is in the keyring
+}
+
+// Keyring is a directory of named keys.
+type Keyring struct {
+	dir string
+}
+
+// DefaultDir returns the keyring directory: $IMF_KEYRING if set, otherwise
+// ~/.imf/keys.
+func DefaultDir() (string, error) {
+	return imfDir("IMF_KEYRING", "keys")
+}
+
+// DefaultTrustDir returns the trust store directory: $IMF_TRUSTED_KEYS if
+// set, otherwise ~/.imf/trusted_keys. The trust store has the same layout
+// as a keyring but holds only the public keys of signers whose containers
+// are accepted on the strength of their embedded key.
+func DefaultTrustDir() (string, error) {
+	return imfDir("IMF_TRUSTED_KEYS", "trusted_keys")
+}
+
+// DefaultRevocationDir returns the directory of local revocation lists:
+// $IMF_REVOCATIONS if set, otherwise ~/.imf/revoked.
+func DefaultRevocationDir() (string, error) {
+	return imfDir("IMF_REVOCATIONS", "revoked")
+}
+
+// imfDir returns $env if set, otherwise ~/.imf/sub.
+func imfDir(env, sub string) (string, error) {
+	if dir := os.Getenv(env); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
+	}
+	return filepath.Join(home, ".imf", sub), nil
+}
+
+// Open opens the keyring in dir, creating the directory if needed.
+func Open(dir string) (*Keyring, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating keyring: %w", err)
+	}
+	return &Keyring{dir: dir}, nil
+}
+
+// OpenDefault opens the keyring in DefaultDir.
+func OpenDefault() (*Keyring, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return Open(dir)
+}
+
+// OpenTrustStore opens the trust store in DefaultTrustDir.
+func OpenTrustStore() (*Keyring, error) {
+	dir, err := DefaultTrustDir()
+	if err != nil {
+		return nil, err
+	}
+	return Open(dir)
+}
+
+// Dir returns the keyring's directory.
+func (k *Keyring) Dir() string {
+	return k.dir
+}
+
+// ValidName reports whether name can be used as a key name.
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// Add stores pub under name, together with the private key file private if
+// it is not nil. private must hold the private half of pub; it may be in any
+// format imfcrypto reads, including a passphrase-protected one, which the
+// caller has already decrypted to obtain pub.
+func (k *Keyring) Add(name string, pub ed25519.PublicKey, private []byte) (*Key, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("invalid key name %q", name)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(pub))
+	}
+	pubPath := k.pubPath(name)
+	if _, err := os.Stat(pubPath); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	if private != nil {
+		if err := writeNew(k.privPath(name), private, 0600); err != nil {
+			return nil, err
+		}
+	}
+	// The public key is written last: its presence is what makes the key
+	// part of the keyring.
+	if err := writeNew(pubPath, imfcrypto.MarshalPublicKeyPEM(pub), 0644); err != nil {
+		if private != nil {
+			os.Remove(k.privPath(name))
+		}
+		return nil, err
+	}
+	return &Key{Name: name, PublicKey: pub, Fingerprint: imfcrypto.Fingerprint(pub), HasPrivate: private != nil}, nil
+}
+
+// Get returns the key stored under name.
+func (k *Keyring) Get(name string) (*Key, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	data, err := os.ReadFile(k.pubPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pub, err := imfcrypto.ParsePublicKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", name, err)
+	}
+	_, err = os.Stat(k.privPath(name))
+	return &Key{Name: name, PublicKey: pub, Fingerprint: imfcrypto.Fingerprint(pub), HasPrivate: err == nil}, nil
+}
+
+// List returns every key in the keyring, sorted by name.
+func (k *Keyring) List() ([]Key, error) {
+	entries, err := os.ReadDir(k.dir)
+	if err != nil {
+		return nil, err
+	}
+	var keys []Key
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".pub")
+		if !ok || e.IsDir() || !ValidName(name) {
+			continue
+		}
+		key, err := k.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys, nil
+}
+
+// PublicKeys returns the public key of every key in the keyring.
+func (k *Keyring) PublicKeys() ([]ed25519.PublicKey, error) {
+	keys, err := k.List()
+	if err != nil {
+		return nil, err
+	}
+	pubs := make([]ed25519.PublicKey, len(keys))
+	for i, key := range keys {
+		pubs[i] = key.PublicKey
+	}
+	return pubs, nil
+}
+
+// PrivateKeyFile returns the private key file stored under name, as it was
+// added.
+func (k *Keyring) PrivateKeyFile(name string) ([]byte, error) {
+	if _, err := k.Get(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(k.privPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoPrivateKey, name)
+	}
+	return data, err
+}
+
+// Remove deletes the key stored under name, private half included.
+func (k *Keyring) Remove(name string) error {
+	if _, err := k.Get(name); err != nil {
+		return err
+	}
+	if err := os.Remove(k.privPath(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.Remove(k.pubPath(name))
+}
+
+func (k *Keyring) pubPath(name string) string {
+	return filepath.Join(k.dir, name+".pub")
+}
+
+func (k *Keyring) privPath(name string) string {
+	return filepath.Join(k.dir, name+".pem")
+}
+
+// writeNew writes data to a file that must not already exist.
+func writeNew(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,72 @@
+package keyring_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/keyring"
+)
+
+func TestKeyringLifecycle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	kr, err := keyring.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	signing, _ := imfcrypto.GenerateKeyPair()
+	privPEM, _ := imfcrypto.MarshalEncryptedPrivateKeyPEM(signing.PrivateKey, "hunter2")
+	added, err := kr.Add("release", signing.PublicKey, privPEM)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if added.Fingerprint != imfcrypto.Fingerprint(signing.PublicKey) || !added.HasPrivate {
+		t.Fatalf("unexpected key: %+v", added)
+	}
+	if _, err := kr.Add("release", signing.PublicKey, nil); !errors.Is(err, keyring.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if _, err := kr.Add("../escape", signing.PublicKey, nil); err == nil {
+		t.Fatal("expected a path-like name to be rejected")
+	}
+
+	colleague, _ := imfcrypto.GenerateKeyPair()
+	if _, err := kr.Add("alice", colleague.PublicKey, nil); err != nil {
+		t.Fatalf("Add public: %v", err)
+	}
+
+	keys, err := kr.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(keys) != 2 || keys[0].Name != "alice" || keys[0].HasPrivate || keys[1].Name != "release" {
+		t.Fatalf("unexpected list: %+v", keys)
+	}
+
+	// The private key file is kept exactly as added, still protected.
+	data, err := kr.PrivateKeyFile("release")
+	if err != nil || !imfcrypto.IsEncryptedPrivateKeyPEM(data) {
+		t.Fatalf("PrivateKeyFile: %v", err)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "release.pem")); info.Mode().Perm() != 0600 {
+		t.Fatalf("private key file mode %v", info.Mode().Perm())
+	}
+	if _, err := kr.PrivateKeyFile("alice"); !errors.Is(err, keyring.ErrNoPrivateKey) {
+		t.Fatalf("expected ErrNoPrivateKey, got %v", err)
+	}
+
+	if err := kr.Remove("release"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := kr.Get("release"); !errors.Is(err, keyring.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "release.pem")); !os.IsNotExist(err) {
+		t.Fatal("private key file left behind after Remove")
+	}
+
+	t.Logf("✓ Keyring add/list/export/remove with fingerprint %s", added.Fingerprint[:16])
+}
@@ -33,34 +33,163 @@ const (
 	StateSealed State = "sealed"
 )
 
+// SymlinkPolicy controls how symbolic links are treated when files are added
+// to a container, and whether stored links are recreated on extraction.
+type SymlinkPolicy string
+
+const (
+	SymlinkFollow SymlinkPolicy = "follow" // copy the link target's contents (default)
+	SymlinkStore  SymlinkPolicy = "store"  // store the link itself, recreate it on extraction
+	SymlinkReject SymlinkPolicy = "reject" // refuse to add or extract symlinks
+)
+
+// ParseSymlinkPolicy validates a policy name. An empty string yields SymlinkFollow.
+func ParseSymlinkPolicy(s string) (SymlinkPolicy, error) {
+	switch p := SymlinkPolicy(s); p {
+	case "":
+		return SymlinkFollow, nil
+	case SymlinkFollow, SymlinkStore, SymlinkReject:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown symlink policy %q (want follow, store, or reject)", s)
+}
+
 // EncryptionInfo holds encryption-related metadata.
 type EncryptionInfo struct {
-	Algorithm  string `json:"algorithm"`            // e.g., "AES-256-GCM"
-	KDF        string `json:"kdf"`                  // e.g., "PBKDF2-HMAC-SHA256"
-	Salt       string `json:"salt"`                 // base64-encoded salt
-	Iterations int    `json:"iterations,omitempty"` // KDF iterations
+	Algorithm  string      `json:"algorithm"`            // e.g., "AES-256-GCM"
+	KDF        string      `json:"kdf"`                  // e.g., "PBKDF2-HMAC-SHA256" or "X25519-HKDF-SHA256"
+	Salt       string      `json:"salt,omitempty"`       // base64-encoded salt (passphrase mode)
+	Iterations int         `json:"iterations,omitempty"` // KDF iterations
+	Recipients []Recipient `json:"recipients,omitempty"` // content key wrapped per recipient (public-key mode)
+	TimeLock   *TimeLock   `json:"time_lock,omitempty"`  // content key locked to a drand round (time-lock mode)
+	Padding    string      `json:"padding,omitempty"`    // how plaintexts were padded before encryption, if at all (hidden manifests)
+}
+
+// Recipient holds the content key wrapped for one X25519 public key.
+type Recipient struct {
+	PublicKey    string `json:"public_key"`    // base64-encoded recipient X25519 public key
+	EphemeralKey string `json:"ephemeral_key"` // base64-encoded sender ephemeral X25519 public key
+	WrappedKey   string `json:"wrapped_key"`   // base64-encoded AES-256-GCM wrapped content key
+}
+
+// TimeLock holds the content key encrypted to a future round of a drand
+// beacon chain; it can be decrypted once the beacon publishes that round.
+type TimeLock struct {
+	URL        string    `json:"url"`        // drand HTTP endpoint to fetch the round's signature from
+	Chain      string    `json:"chain"`      // hex drand chain hash
+	PublicKey  string    `json:"public_key"` // hex chain public key the key is encrypted under
+	Round      uint64    `json:"round"`      // beacon round that unlocks the key
+	Unlocks    time.Time `json:"unlocks"`    // when the round is published
+	Ciphertext string    `json:"ciphertext"` // base64-encoded time-lock encrypted content key
 }
 
 // FileEntry describes a single file stored in the container.
 type FileEntry struct {
-	Path            string `json:"path"`                       // path inside zip (e.g., "files/doc.pdf.enc")
-	OriginalName    string `json:"original_name"`              // original filename
-	OriginalSize    int64  `json:"original_size"`              // size before encryption
-	SHA256          string `json:"sha256"`                     // hash of original plaintext content
-	EncryptedSHA256 string `json:"encrypted_sha256,omitempty"` // hash of encrypted content
+	Path            string     `json:"path"`                       // path inside zip (e.g., "files/doc.pdf.enc")
+	OriginalName    string     `json:"original_name"`              // original filename
+	OriginalSize    int64      `json:"original_size"`              // size before encryption
+	SHA256          string     `json:"sha256"`                     // hash of original plaintext content
+	EncryptedSHA256 string     `json:"encrypted_sha256,omitempty"` // hash of encrypted content
+	LinkTarget      string     `json:"link_target,omitempty"`      // symlink target, if stored as a link
+	SourceURL       string     `json:"source_url,omitempty"`       // URL the file was downloaded from
+	RetrievedAt     *time.Time `json:"retrieved_at,omitempty"`     // when the URL was fetched
+	OriginalForm    string     `json:"original_form,omitempty"`    // filename as given, if it differed from its NFC form
 }
 
 // Manifest is the top-level container metadata.
 type Manifest struct {
-	Version    int            `json:"version"`
-	State      State          `json:"state"`
-	CreatedAt  time.Time      `json:"created_at"`
-	SealedAt   *time.Time     `json:"sealed_at,omitempty"`
-	ExpiresAt  *time.Time     `json:"expires_at,omitempty"`
-	PublicKey  string         `json:"public_key,omitempty"`   // base64-encoded Ed25519 public key
-	Encryption *EncryptionInfo `json:"encryption,omitempty"`
-	Files      []FileEntry    `json:"files"`
-	Signature  string         `json:"signature,omitempty"` // base64-encoded Ed25519 signature
+	Version       int             `json:"version"`
+	State         State           `json:"state"`
+	CreatedAt     time.Time       `json:"created_at"`
+	SealedAt      *time.Time      `json:"sealed_at,omitempty"`
+	ExpiresAt     *time.Time      `json:"expires_at,omitempty"`
+	PublicKey     string          `json:"public_key,omitempty"` // base64-encoded Ed25519 public key
+	CertChain     []string        `json:"cert_chain,omitempty"` // base64 DER X.509 certificates for the signing key, leaf first
+	PQKey         *PQKey          `json:"pq_key,omitempty"`     // post-quantum key for the hybrid signature, if any
+	Encryption    *EncryptionInfo `json:"encryption,omitempty"`
+	SymlinkPolicy SymlinkPolicy   `json:"symlink_policy,omitempty"` // policy used when files were added
+	Metadata      *Metadata       `json:"metadata,omitempty"`       // what the container is, as its creator describes it
+	Files         []FileEntry     `json:"files"`
+	Envelope      *Envelope       `json:"envelope,omitempty"`     // set only on the outer header of a hidden manifest
+	Policy        *Policy         `json:"policy,omitempty"`       // k-of-n signature requirement, if any
+	Signer        *SignerIdentity `json:"signer,omitempty"`       // who sealed the container
+	Signature     string          `json:"signature,omitempty"`    // base64-encoded Ed25519 signature
+	PQSignature   string          `json:"pq_signature,omitempty"` // base64 signature over the signable bytes by PQKey
+	Timestamp     string          `json:"timestamp,omitempty"`    // base64 DER RFC 3161 token over the SHA-256 of the signable bytes
+	Rekor         json.RawMessage `json:"rekor,omitempty"`        // Rekor log entry for a keyless signature over the SHA-256 of the signable bytes
+	Anchor        string          `json:"anchor,omitempty"`       // base64 OpenTimestamps proof over the SHA-256 of the signable bytes
+	Cosignatures  []Cosignature   `json:"cosignatures,omitempty"`
+	Witnesses     []Witness       `json:"witnesses,omitempty"` // countersignatures added after sealing
+}
+
+// Metadata describes a container for the people who handle it. It is set
+// while the container is open and, like the rest of the manifest, signed
+// when it is sealed.
+type Metadata struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	CaseNumber  string   `json:"case_number,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Template    string   `json:"template,omitempty"` // the ID of the template the container was made from, if any
+}
+
+// SignerIdentity says who sealed the container. It is signed along with the
+// rest of the manifest. Name and Email are self-declared; Fingerprint must
+// match the key the signature verifies under.
+type SignerIdentity struct {
+	Name        string `json:"name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Fingerprint string `json:"fingerprint"` // hex SHA-256 of the Ed25519 public key
+}
+
+// PQKey is the post-quantum public key that signs the manifest alongside
+// the Ed25519 key. It is part of the signed bytes, so the Ed25519 signature
+// also vouches for it.
+type PQKey struct {
+	Algorithm string `json:"algorithm"`  // currently always "ML-DSA-65"
+	PublicKey string `json:"public_key"` // base64-encoded public key
+}
+
+// Policy requires that at least Threshold of Keys have signed the manifest
+// for the container to count as authentic.
+type Policy struct {
+	Threshold int      `json:"threshold"`
+	Keys      []string `json:"keys"` // base64-encoded Ed25519 public keys
+}
+
+// Cosignature is one policy key's signature over the manifest's signable bytes.
+type Cosignature struct {
+	PublicKey string `json:"public_key"` // base64-encoded Ed25519 public key
+	Signature string `json:"signature"`  // base64-encoded Ed25519 signature
+}
+
+// Witness is a third party's signed statement that they saw the manifest
+// with the given hash at the given time. Each witness also signs the hash of
+// the previous witness's signature, so the list forms a chain that cannot be
+// reordered or have entries removed from the middle.
+type Witness struct {
+	PublicKey      string    `json:"public_key"`         // base64-encoded Ed25519 public key
+	Name           string    `json:"name,omitempty"`     // self-declared witness name
+	Timestamp      time.Time `json:"timestamp"`          // when the witness signed, by their clock
+	ManifestSHA256 string    `json:"manifest_sha256"`    // hex SHA-256 of the manifest's signable bytes
+	Previous       string    `json:"previous,omitempty"` // hex SHA-256 of the previous witness's signature
+	Signature      string    `json:"signature"`          // base64-encoded Ed25519 signature
+}
+
+// SignableBytes returns the bytes the witness signs: the JSON form of w
+// with the signature field zeroed out.
+func (w *Witness) SignableBytes() ([]byte, error) {
+	cp := *w
+	cp.Signature = ""
+	return json.Marshal(cp)
+}
+
+// Envelope is what the outer header of a container with a hidden (encrypted)
+// manifest records in place of the file list: enough to check every stored
+// byte against the signature without revealing names or plaintext sizes.
+type Envelope struct {
+	ManifestSHA256 string            `json:"manifest_sha256"` // SHA-256 of the encrypted manifest blob
+	Entries        map[string]string `json:"entries"`         // zip path -> SHA-256 of the stored (encrypted) bytes
 }
 
 // New creates a new open manifest.
@@ -90,11 +219,31 @@ func (m *Manifest) AddFile(entry FileEntry) error {
 	return nil
 }
 
+// RemoveFile removes the entry with the given zip path. Fails if sealed.
+func (m *Manifest) RemoveFile(path string) error {
+	if m.State == StateSealed {
+		return errors.New("cannot remove files from a sealed container")
+	}
+	for i, f := range m.Files {
+		if f.Path == path {
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("file not found: %s", path)
+}
+
 // IsSealed returns true if the container is sealed.
 func (m *Manifest) IsSealed() bool {
 	return m.State == StateSealed
 }
 
+// IsHidden returns true if this is the outer header of a container whose
+// real manifest is encrypted.
+func (m *Manifest) IsHidden() bool {
+	return m.Envelope != nil
+}
+
 // IsExpired returns true if the container has an expiration date that has passed.
 func (m *Manifest) IsExpired() bool {
 	if m.ExpiresAt == nil {
@@ -118,11 +267,21 @@ func (m *Manifest) Seal() error {
 }
 
 // SignableBytes returns the manifest bytes used for signing.
-// This is the JSON representation with the signature field zeroed out.
+// This is the JSON representation with the signature, post-quantum
+// signature, timestamp, transparency log entry, anchor, cosignature, and
+// witness fields zeroed out, so the signatures, a timestamp, log entry or
+// blockchain anchor over these bytes, cosignatures, and witnesses can all be
+// added after signing.
 func (m *Manifest) SignableBytes() ([]byte, error) {
 	// Create a copy with no signature for signing.
 	cp := *m
 	cp.Signature = ""
+	cp.PQSignature = ""
+	cp.Timestamp = ""
+	cp.Rekor = nil
+	cp.Anchor = ""
+	cp.Cosignatures = nil
+	cp.Witnesses = nil
 	return json.Marshal(cp)
 }
 
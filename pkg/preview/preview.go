@@ -0,0 +1,437 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package preview reads the text out of office documents — Word, Excel, and
+// PowerPoint files in their Office Open XML forms, and OpenDocument text —
+// so that a container's documents can be reviewed without the programs that
+// made them. Formatting, images, and embedded objects are left out.
+package preview
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits on what a preview holds, so that a huge document, or a small one
+// that decompresses to a huge one, yields a preview of its start.
+const (
+	MaxText = 256 << 10 // bytes of text in all
+	MaxRows = 500       // rows shown of each sheet
+	MaxCols = 50        // columns shown of each sheet
+
+	maxPart = 64 << 20 // bytes read of one XML part of the document
+)
+
+// ErrUnsupported is returned for a file that is not a document Office reads.
+var ErrUnsupported = errors.New("no preview for this kind of file")
+
+// Document is the text of a document.
+type Document struct {
+	Format    string    `json:"format"` // "docx", "xlsx", "pptx", or "odt"
+	Sections  []Section `json:"sections"`
+	Truncated bool      `json:"truncated,omitempty"` // a limit was reached and the rest left out
+}
+
+// Section is the body of a text document, one slide, or one sheet.
+type Section struct {
+	Title      string     `json:"title,omitempty"` // a sheet's name
+	Slide      int        `json:"slide,omitempty"` // a slide's number
+	Paragraphs []string   `json:"paragraphs,omitempty"`
+	Rows       [][]string `json:"rows,omitempty"` // a sheet's cells, row by row
+}
+
+// Supported reports whether Office reads files with the extension ext,
+// given with its dot, in any case.
+func Supported(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".docx", ".xlsx", ".pptx", ".odt":
+		return true
+	}
+	return false
+}
+
+// Office returns the text of the document at name, which Supported must
+// accept by its extension.
+func Office(name string) (*Document, error) {
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(name, `\`, "/")))
+	if !Supported(ext) {
+		return nil, ErrUnsupported
+	}
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid %s document: %w", ext[1:], err)
+	}
+	defer zr.Close()
+
+	p := &reader{zr: &zr.Reader, doc: &Document{Format: ext[1:]}}
+	switch ext {
+	case ".docx":
+		err = p.paragraphs("word/document.xml", Section{}, wordText)
+	case ".odt":
+		err = p.paragraphs("content.xml", Section{}, odfText)
+	case ".pptx":
+		err = p.slides()
+	case ".xlsx":
+		err = p.sheets()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.doc, nil
+}
+
+// reader reads the parts of one document.
+type reader struct {
+	zr   *zip.Reader
+	doc  *Document
+	used int // bytes of text so far
+}
+
+// open returns the part of the document named name.
+func (p *reader) open(name string) (io.ReadCloser, error) {
+	f, err := p.zr.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid %s document: missing %s", p.doc.Format, name)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, maxPart), f}, nil
+}
+
+// add counts s against MaxText, returning as much of it as fits and
+// whether all of it did.
+func (p *reader) add(s string) (string, bool) {
+	fits := p.used+len(s) <= MaxText
+	if !fits {
+		s = strings.ToValidUTF8(s[:max(MaxText-p.used, 0)], "")
+		p.doc.Truncated = true
+	}
+	p.used += len(s)
+	return s, fits
+}
+
+// full reports whether MaxText has been reached.
+func (p *reader) full() bool { return p.used >= MaxText }
+
+// textMarkup says how a format marks up its text: the elements that are
+// paragraphs, that hold text, and that stand for a character.
+type textMarkup struct {
+	para  map[string]bool
+	text  map[string]bool // nil: all text within a paragraph
+	chars map[string]string
+}
+
+var wordText = textMarkup{
+	para:  map[string]bool{"p": true},
+	text:  map[string]bool{"t": true},
+	chars: map[string]string{"tab": "\t", "br": "\n", "cr": "\n"},
+}
+
+var slideText = textMarkup{
+	para:  map[string]bool{"p": true},
+	text:  map[string]bool{"t": true},
+	chars: map[string]string{"br": "\n"},
+}
+
+var odfText = textMarkup{
+	para:  map[string]bool{"p": true, "h": true},
+	chars: map[string]string{"tab": "\t", "line-break": "\n", "s": " "},
+}
+
+// paragraphs adds sec, with the paragraphs of part. Empty paragraphs are
+// left out.
+func (p *reader) paragraphs(part string, sec Section, m textMarkup) error {
+	rc, err := p.open(part)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var para strings.Builder
+	inPara, inText := 0, 0
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", part, err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			name := tok.Name.Local
+			switch {
+			case m.para[name]:
+				inPara++
+			case m.text[name]:
+				inText++
+			case inPara > 0 && m.chars[name] != "":
+				para.WriteString(m.chars[name])
+			}
+		case xml.EndElement:
+			name := tok.Name.Local
+			switch {
+			case m.para[name]:
+				if inPara--; inPara == 0 {
+					if s := strings.TrimSpace(para.String()); s != "" {
+						s, ok := p.add(s)
+						sec.Paragraphs = append(sec.Paragraphs, s)
+						if !ok {
+							p.doc.Sections = append(p.doc.Sections, sec)
+							return nil
+						}
+					}
+					para.Reset()
+				}
+			case m.text[name]:
+				inText--
+			}
+		case xml.CharData:
+			if inPara > 0 && (m.text == nil || inText > 0) {
+				para.Write(tok)
+			}
+		}
+	}
+	p.doc.Sections = append(p.doc.Sections, sec)
+	return nil
+}
+
+// slides adds a section for each slide, in the order of their numbers.
+func (p *reader) slides() error {
+	type slide struct {
+		n    int
+		name string
+	}
+	var slides []slide
+	for _, f := range p.zr.File {
+		num, ok := strings.CutPrefix(f.Name, "ppt/slides/slide")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSuffix(num, ".xml")); err == nil && strings.HasSuffix(num, ".xml") {
+			slides = append(slides, slide{n, f.Name})
+		}
+	}
+	if len(slides) == 0 {
+		return errors.New("not a valid pptx document: no slides")
+	}
+	sort.Slice(slides, func(i, j int) bool { return slides[i].n < slides[j].n })
+	for _, s := range slides {
+		if err := p.paragraphs(s.name, Section{Slide: s.n}, slideText); err != nil {
+			return err
+		}
+		if p.full() {
+			break
+		}
+	}
+	return nil
+}
+
+// sheets adds a section for each sheet of a workbook, in the workbook's
+// order.
+func (p *reader) sheets() error {
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := p.decode("xl/workbook.xml", &wb); err != nil {
+		return err
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := p.decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return err
+	}
+	targets := make(map[string]string)
+	for _, r := range rels.Rels {
+		if strings.HasPrefix(r.Target, "/") {
+			targets[r.ID] = strings.TrimPrefix(r.Target, "/")
+		} else {
+			targets[r.ID] = path.Join("xl", r.Target)
+		}
+	}
+	shared, err := p.sharedStrings()
+	if err != nil {
+		return err
+	}
+
+	for _, sh := range wb.Sheets {
+		part, ok := targets[sh.ID]
+		if !ok {
+			return fmt.Errorf("not a valid xlsx document: no part for sheet %s", sh.Name)
+		}
+		if err := p.sheet(part, sh.Name, shared); err != nil {
+			return err
+		}
+		if p.full() {
+			break
+		}
+	}
+	return nil
+}
+
+// decode unmarshals the XML part name into v.
+func (p *reader) decode(name string, v any) error {
+	rc, err := p.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+	return nil
+}
+
+// sharedStrings returns the workbook's table of strings, which cells refer
+// to by index. A workbook without strings has no table.
+func (p *reader) sharedStrings() ([]string, error) {
+	if !slices.ContainsFunc(p.zr.File, func(f *zip.File) bool { return f.Name == "xl/sharedStrings.xml" }) {
+		return nil, nil
+	}
+	var sst struct {
+		Items []struct {
+			Text string `xml:",innerxml"`
+		} `xml:"si"`
+	}
+	if err := p.decode("xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+	shared := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		shared[i] = innerText(si.Text)
+	}
+	return shared, nil
+}
+
+// innerText returns the text of the <t> elements in an XML fragment,
+// leaving out phonetic guides.
+func innerText(fragment string) string {
+	var b strings.Builder
+	inText, inPhonetic := 0, 0
+	d := xml.NewDecoder(strings.NewReader(fragment))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return b.String()
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "t":
+				inText++
+			case "rPh":
+				inPhonetic++
+			}
+		case xml.EndElement:
+			switch tok.Name.Local {
+			case "t":
+				inText--
+			case "rPh":
+				inPhonetic--
+			}
+		case xml.CharData:
+			if inText > 0 && inPhonetic == 0 {
+				b.Write(tok)
+			}
+		}
+	}
+}
+
+// sheet adds a section holding the cells of the worksheet part, titled
+// with the sheet's name. Numbers are shown as stored, without their format.
+func (p *reader) sheet(part, title string, shared []string) error {
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline struct {
+					Text string `xml:",innerxml"`
+				} `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := p.decode(part, &ws); err != nil {
+		return err
+	}
+
+	sec := Section{Title: title}
+	for _, row := range ws.Rows {
+		if len(sec.Rows) == MaxRows {
+			p.doc.Truncated = true
+			break
+		}
+		var cells []string
+		for i, c := range row.Cells {
+			col := column(c.Ref)
+			if col < 0 {
+				col = i
+			}
+			if col >= MaxCols {
+				p.doc.Truncated = true
+				continue
+			}
+			v := c.Value
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(v); err == nil && n >= 0 && n < len(shared) {
+					v = shared[n]
+				}
+			case "inlineStr":
+				v = innerText(c.Inline.Text)
+			case "b":
+				v = map[string]string{"0": "FALSE", "1": "TRUE"}[v]
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+			v, ok := p.add(v)
+			cells = append(cells[:col], v)
+			if !ok {
+				sec.Rows = append(sec.Rows, cells)
+				p.doc.Sections = append(p.doc.Sections, sec)
+				return nil
+			}
+		}
+		sec.Rows = append(sec.Rows, cells)
+	}
+	p.doc.Sections = append(p.doc.Sections, sec)
+	return nil
+}
+
+// column returns the zero-based column of a cell reference such as "B7",
+// or -1 if ref has no column letters.
+func column(ref string) int {
+	col := 0
+	for i, c := range ref {
+		if c < 'A' || c > 'Z' {
+			if i == 0 {
+				return -1
+			}
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+		if col > 1<<20 {
+			return -1
+		}
+	}
+	return col - 1
+}
@@ -0,0 +1,136 @@
+package preview_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/preview"
+)
+
+// writeZip writes a document made of the given parts.
+func writeZip(t *testing.T, name string, parts map[string]string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, data := range parts {
+		w, _ := zw.Create(name)
+		w.Write([]byte(data))
+	}
+	zw.Close()
+	f.Close()
+	return p
+}
+
+func TestWord(t *testing.T) {
+	p := writeZip(t, "letter.docx", map[string]string{
+		"word/document.xml": `<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Dear </w:t></w:r><w:r><w:t>Sir,</w:t></w:r></w:p>
+<w:p></w:p>
+<w:p><w:r><w:t>Total:</w:t><w:tab/><w:t>5 &amp; 6</w:t></w:r><w:r><w:delText>gone</w:delText></w:r></w:p>
+</w:body></w:document>`,
+	})
+	doc, err := preview.Office(p)
+	if err != nil {
+		t.Fatalf("Office: %v", err)
+	}
+	want := []string{"Dear Sir,", "Total:\t5 & 6"}
+	if doc.Format != "docx" || len(doc.Sections) != 1 || !reflect.DeepEqual(doc.Sections[0].Paragraphs, want) {
+		t.Fatalf("unexpected preview: %+v", doc)
+	}
+}
+
+func TestSlides(t *testing.T) {
+	slide := func(text string) string {
+		return `<p:sld xmlns:p="p" xmlns:a="a"><p:cSld><p:spTree><p:sp><p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp></p:spTree></p:cSld></p:sld>`
+	}
+	p := writeZip(t, "deck.pptx", map[string]string{
+		"ppt/slides/slide10.xml":           slide("ten"),
+		"ppt/slides/slide2.xml":            slide("two"),
+		"ppt/slides/_rels/slide2.xml.rels": `<Relationships/>`,
+	})
+	doc, err := preview.Office(p)
+	if err != nil {
+		t.Fatalf("Office: %v", err)
+	}
+	if len(doc.Sections) != 2 || doc.Sections[0].Slide != 2 || doc.Sections[1].Slide != 10 || doc.Sections[1].Paragraphs[0] != "ten" {
+		t.Fatalf("unexpected preview: %+v", doc)
+	}
+}
+
+func TestSheets(t *testing.T) {
+	p := writeZip(t, "budget.xlsx", map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Q1" sheetId="1" r:id="rId1"/><sheet name="Q2" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>Item</t></si><si><r><t>Co</t></r><r><t>st</t></r><rPh><t>x</t></rPh></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>Rent</t></is></c><c r="B2" t="b"><v>1</v></c><c r="C2"><v>1200.5</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData/></worksheet>`,
+	})
+	doc, err := preview.Office(p)
+	if err != nil {
+		t.Fatalf("Office: %v", err)
+	}
+	want := [][]string{{"Item", "", "Cost"}, {"Rent", "TRUE", "1200.5"}}
+	if len(doc.Sections) != 2 || doc.Sections[0].Title != "Q1" || doc.Sections[1].Title != "Q2" || !reflect.DeepEqual(doc.Sections[0].Rows, want) {
+		t.Fatalf("unexpected preview: %+v", doc)
+	}
+}
+
+func TestOpenDocument(t *testing.T) {
+	p := writeZip(t, "notes.odt", map[string]string{
+		"content.xml": `<office:document-content xmlns:office="o" xmlns:text="t"><office:body><office:text>
+<text:h>Title</text:h><text:p>One<text:s/><text:span>two</text:span></text:p></office:text></office:body></office:document-content>`,
+	})
+	doc, err := preview.Office(p)
+	if err != nil {
+		t.Fatalf("Office: %v", err)
+	}
+	if want := []string{"Title", "One two"}; !reflect.DeepEqual(doc.Sections[0].Paragraphs, want) {
+		t.Fatalf("unexpected preview: %+v", doc)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	para := `<w:p><w:r><w:t>` + strings.Repeat("x", 1000) + `</w:t></w:r></w:p>`
+	p := writeZip(t, "long.docx", map[string]string{
+		"word/document.xml": `<w:document xmlns:w="w"><w:body>` + strings.Repeat(para, 300) + `</w:body></w:document>`,
+	})
+	doc, err := preview.Office(p)
+	if err != nil {
+		t.Fatalf("Office: %v", err)
+	}
+	n := 0
+	for _, s := range doc.Sections[0].Paragraphs {
+		n += len(s)
+	}
+	if !doc.Truncated || n != preview.MaxText {
+		t.Fatalf("expected %d bytes of text, truncated; got %d, %v", preview.MaxText, n, doc.Truncated)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if _, err := preview.Office("photo.jpg"); err != preview.ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	p := filepath.Join(t.TempDir(), "fake.docx")
+	os.WriteFile(p, []byte("not a zip"), 0644)
+	if _, err := preview.Office(p); err == nil {
+		t.Fatal("expected an error for a document that is not a zip")
+	}
+	p = writeZip(t, "empty.docx", map[string]string{"x": ""})
+	if _, err := preview.Office(p); err == nil {
+		t.Fatal("expected an error for a document without its body")
+	}
+}
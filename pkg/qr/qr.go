@@ -0,0 +1,499 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package qr makes QR codes (ISO/IEC 18004), so that a hash can be passed
+// from one screen to a phone's camera rather than read out digit by digit.
+// Data is encoded as bytes, in the smallest of versions 1 to 10 that holds
+// it — up to 271 bytes at level L — which is plenty for hashes and
+// fingerprints.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Level is how much of the code may be damaged and still be read.
+type Level int
+
+const (
+	L Level = iota // about 7%
+	M              // about 15%
+	Q              // about 25%
+	H              // about 30%
+)
+
+// MaxVersion is the largest version Encode makes.
+const MaxVersion = 10
+
+// ErrTooLong is returned for data that does not fit in MaxVersion.
+var ErrTooLong = errors.New("qr: data too long")
+
+// Code is a QR code: Size modules square, dark or light.
+type Code struct {
+	Size    int
+	Version int
+	Level   Level
+	Mask    int
+
+	dark     []bool
+	reserved []bool // modules of the function patterns, which data skips
+}
+
+// Dark reports whether the module at column x, row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.dark[y*c.Size+x]
+}
+
+// blocks is how a version's codewords are split into blocks at a level:
+// the error correction codewords of each block, and the number of blocks
+// in each of the two groups with their data codewords.
+type blocks struct {
+	ec             int
+	n1, d1, n2, d2 int
+}
+
+// blockTable is indexed by version-1, then by Level.
+var blockTable = [MaxVersion][4]blocks{
+	{{7, 1, 19, 0, 0}, {10, 1, 16, 0, 0}, {13, 1, 13, 0, 0}, {17, 1, 9, 0, 0}},
+	{{10, 1, 34, 0, 0}, {16, 1, 28, 0, 0}, {22, 1, 22, 0, 0}, {28, 1, 16, 0, 0}},
+	{{15, 1, 55, 0, 0}, {26, 1, 44, 0, 0}, {18, 2, 17, 0, 0}, {22, 2, 13, 0, 0}},
+	{{20, 1, 80, 0, 0}, {18, 2, 32, 0, 0}, {26, 2, 24, 0, 0}, {16, 4, 9, 0, 0}},
+	{{26, 1, 108, 0, 0}, {24, 2, 43, 0, 0}, {18, 2, 15, 2, 16}, {22, 2, 11, 2, 12}},
+	{{18, 2, 68, 0, 0}, {16, 4, 27, 0, 0}, {24, 4, 19, 0, 0}, {28, 4, 15, 0, 0}},
+	{{20, 2, 78, 0, 0}, {18, 4, 31, 0, 0}, {18, 2, 14, 4, 15}, {26, 4, 13, 1, 14}},
+	{{24, 2, 97, 0, 0}, {22, 2, 38, 2, 39}, {22, 4, 18, 2, 19}, {26, 4, 14, 2, 15}},
+	{{30, 2, 116, 0, 0}, {22, 3, 36, 2, 37}, {20, 4, 16, 4, 17}, {24, 4, 12, 4, 13}},
+	{{18, 2, 68, 2, 69}, {26, 4, 43, 1, 44}, {24, 6, 19, 2, 20}, {28, 6, 15, 2, 16}},
+}
+
+// dataCodewords returns how many data codewords a version holds at a level.
+func (b blocks) dataCodewords() int { return b.n1*b.d1 + b.n2*b.d2 }
+
+// Encode returns data as a QR code at level, in the smallest version that
+// holds it, with the mask that leaves it easiest to read.
+func Encode(data []byte, level Level) (*Code, error) {
+	if level < L || level > H {
+		return nil, fmt.Errorf("qr: unknown level %d", level)
+	}
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= 8*blockTable[v-1][level].dataCodewords() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+	codewords := addErrorCorrection(encodeData(data, version, level), blockTable[version-1][level])
+
+	var best *Code
+	bestPenalty := -1
+	for mask := 0; mask < 8; mask++ {
+		c := newCode(version, level)
+		c.placeData(codewords, mask)
+		c.drawFormat(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = c, p
+		}
+	}
+	return best, nil
+}
+
+// countBits is the width of the byte count in a version.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// encodeData returns the data codewords: the byte mode indicator, the
+// count, the bytes, a terminator, and padding.
+func encodeData(data []byte, version int, level Level) []byte {
+	var b bitBuffer
+	b.put(0b0100, 4)
+	b.put(len(data), countBits(version))
+	for _, c := range data {
+		b.put(int(c), 8)
+	}
+	capacity := 8 * blockTable[version-1][level].dataCodewords()
+	b.put(0, min(4, capacity-b.n))
+	b.put(0, (8-b.n%8)%8)
+	for pad := 0; b.n < capacity; pad++ {
+		b.put([]int{0xEC, 0x11}[pad%2], 8)
+	}
+	return b.bytes
+}
+
+// bitBuffer is a string of bits, filled most significant bit first.
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+func (b *bitBuffer) put(v, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.bytes = append(b.bytes, 0)
+		}
+		if v>>i&1 == 1 {
+			b.bytes[b.n/8] |= 0x80 >> (b.n % 8)
+		}
+		b.n++
+	}
+}
+
+// addErrorCorrection splits data into blocks, adds each block's error
+// correction codewords, and interleaves them all.
+func addErrorCorrection(data []byte, bl blocks) []byte {
+	var dataBlocks, ecBlocks [][]byte
+	for i := 0; i < bl.n1+bl.n2; i++ {
+		n := bl.d1
+		if i >= bl.n1 {
+			n = bl.d2
+		}
+		dataBlocks = append(dataBlocks, data[:n])
+		ecBlocks = append(ecBlocks, reedSolomon(data[:n], bl.ec))
+		data = data[n:]
+	}
+	var out []byte
+	for i := 0; i < max(bl.d1, bl.d2); i++ {
+		for _, b := range dataBlocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := 0; i < bl.ec; i++ {
+		for _, b := range ecBlocks {
+			out = append(out, b[i])
+		}
+	}
+	return out
+}
+
+// GF(256) with the QR code's polynomial, x^8+x^4+x^3+x^2+1.
+var gfExp, gfLog [256]byte
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		if x <<= 1; x >= 256 {
+			x ^= 0x11D
+		}
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+int(gfLog[b]))%255]
+}
+
+// reedSolomon returns the n error correction codewords of data.
+func reedSolomon(data []byte, n int) []byte {
+	// The generator polynomial, (x-α^0)(x-α^1)...(x-α^(n-1)), highest
+	// coefficient (1) left out.
+	gen := make([]byte, n)
+	gen[n-1] = 1
+	root := byte(1)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			gen[j] = gfMul(gen[j], root)
+			if j+1 < n {
+				gen[j] ^= gen[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	rem := make([]byte, n)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for j := range rem {
+			rem[j] ^= gfMul(gen[j], factor)
+		}
+	}
+	return rem
+}
+
+// newCode returns a code of the given version with its function patterns
+// drawn and the areas for format and version information reserved.
+func newCode(version int, level Level) *Code {
+	size := 17 + 4*version
+	c := &Code{Size: size, Version: version, Level: level, dark: make([]bool, size*size)}
+	reserved := make([]bool, size*size)
+	set := func(x, y int, dark bool) {
+		c.dark[y*size+x] = dark
+		reserved[y*size+x] = true
+	}
+
+	for i := 0; i < size; i++ {
+		set(6, i, i%2 == 0)
+		set(i, 6, i%2 == 0)
+	}
+	for _, corner := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || x >= size || y < 0 || y >= size {
+					continue
+				}
+				d := max(abs(dx), abs(dy))
+				set(x, y, d != 2 && d != 4)
+			}
+		}
+	}
+	pos := alignmentPositions(version)
+	for i, ax := range pos {
+		for j, ay := range pos {
+			if i == 0 && j == 0 || i == 0 && j == len(pos)-1 || i == len(pos)-1 && j == 0 {
+				continue // a finder pattern is there
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					set(ax+dx, ay+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Format information, drawn later, and the dark module.
+	for i := 0; i < 9; i++ {
+		if i != 6 { // the timing patterns
+			set(8, i, false)
+			set(i, 8, false)
+		}
+	}
+	for i := 0; i < 8; i++ {
+		set(size-1-i, 8, false)
+		set(8, size-1-i, false)
+	}
+	set(8, size-8, true)
+	if version >= 7 {
+		bits := versionBits(version)
+		for i := 0; i < 18; i++ {
+			a, b := size-11+i%3, i/3
+			set(a, b, bits>>i&1 == 1)
+			set(b, a, bits>>i&1 == 1)
+		}
+	}
+	c.reserved = reserved
+	return c
+}
+
+// versionBits returns a version's 18 bits of version information: the
+// version, with a BCH code.
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+// formatBits returns the 15 bits of format information for a level and
+// mask: the two, with a BCH code, masked so that they are never all light.
+func formatBits(level Level, mask int) int {
+	data := []int{1, 0, 3, 2}[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// alignmentPositions returns the rows and columns on which a version's
+// alignment patterns are centered.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	size := 17 + 4*version
+	step := (version*4 + n*2 + 1) / (n*2 - 2) * 2
+	pos := make([]int, n)
+	pos[0] = 6
+	for i, p := n-1, size-7; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+	return pos
+}
+
+// maskBit reports whether mask flips the module at column x, row y.
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// placeData fills the modules left free by the function patterns with
+// codewords, in the zigzag the standard lays down: up and down pairs of
+// columns from the right, skipping the vertical timing pattern, and masked.
+func (c *Code) placeData(codewords []byte, mask int) {
+	c.Mask = mask
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert // upward
+				}
+				if c.reserved[y*c.Size+x] {
+					continue
+				}
+				dark := i < len(codewords)*8 && codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+				c.dark[y*c.Size+x] = dark != maskBit(mask, x, y)
+			}
+		}
+	}
+}
+
+// drawFormat draws the level and mask, twice.
+func (c *Code) drawFormat(mask int) {
+	bits := formatBits(c.Level, mask)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+	size := c.Size
+	set := func(x, y int, dark bool) { c.dark[y*size+x] = dark }
+
+	for i := 0; i <= 5; i++ {
+		set(8, i, bit(i))
+	}
+	set(8, 7, bit(6))
+	set(8, 8, bit(7))
+	set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		set(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		set(8, size-15+i, bit(i))
+	}
+}
+
+// penalty scores how hard the code is to read, by the standard's four
+// rules: runs of one color, blocks of one color, patterns that look like
+// finders, and an imbalance of dark and light.
+func (c *Code) penalty() int {
+	size, p, darkCount := c.Size, 0, 0
+	finder := []bool{true, false, true, true, true, false, true}
+	for _, rows := range []bool{true, false} {
+		for a := 0; a < size; a++ {
+			at := func(b int) bool {
+				if rows {
+					return c.Dark(b, a)
+				}
+				return c.Dark(a, b)
+			}
+			run := 0
+			for b := 0; b < size; b++ {
+				if b > 0 && at(b) == at(b-1) {
+					run++
+				} else {
+					run = 1
+				}
+				if run == 5 {
+					p += 3
+				} else if run > 5 {
+					p++
+				}
+				// A finder pattern with four light modules on either side.
+				if b+7 <= size && matches(at, b, finder) {
+					if light(at, b-4, b, size) || light(at, b+7, b+11, size) {
+						p += 40
+					}
+				}
+			}
+		}
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			d := c.Dark(x, y)
+			if d {
+				darkCount++
+			}
+			if x+1 < size && y+1 < size && d == c.Dark(x+1, y) && d == c.Dark(x, y+1) && d == c.Dark(x+1, y+1) {
+				p += 3
+			}
+		}
+	}
+	total := size * size
+	p += (abs(darkCount*20-total*10)+total-1)/total*10 - 10
+	return p
+}
+
+func matches(at func(int) bool, from int, pattern []bool) bool {
+	for i, want := range pattern {
+		if at(from+i) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// light reports whether the modules from..to are all light; those beyond
+// the edge count as light, as the quiet zone around the code is.
+func light(at func(int) bool, from, to, size int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < size && at(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// SVG returns the code as an SVG image, with the quiet zone of four
+// modules around it that readers need. It scales to whatever size it is
+// shown at.
+func (c *Code) SVG() string {
+	n := c.Size + 8
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x+4, y+4)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.String()
+}
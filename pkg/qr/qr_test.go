@@ -0,0 +1,188 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" as version 1-M, from the standard's worked example.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := reedSolomon(data, 10); !bytes.Equal(got, want) {
+		t.Fatalf("reedSolomon = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	for _, c := range []struct {
+		level Level
+		mask  int
+		want  string
+	}{
+		{L, 0, "111011111000100"},
+		{L, 4, "110011000101111"},
+		{M, 0, "101010000010010"},
+		{H, 0, "001011010001001"},
+	} {
+		if got := fmt.Sprintf("%015b", formatBits(c.level, c.mask)); got != c.want {
+			t.Errorf("formatBits(%d, %d) = %s, want %s", c.level, c.mask, got, c.want)
+		}
+	}
+	if got := fmt.Sprintf("%018b", versionBits(7)); got != "000111110010010100" {
+		t.Errorf("versionBits(7) = %s", got)
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	for v, want := range map[int]string{1: "[]", 2: "[6 18]", 6: "[6 34]", 7: "[6 22 38]", 10: "[6 28 50]"} {
+		if got := fmt.Sprint(alignmentPositions(v)); got != want {
+			t.Errorf("alignmentPositions(%d) = %s, want %s", v, got, want)
+		}
+	}
+}
+
+// decode reads a code back: its format information, then its codewords,
+// checking each block's error correction, then the bytes they hold.
+func decode(t *testing.T, c *Code) []byte {
+	t.Helper()
+	var format int
+	for i := 0; i <= 5; i++ {
+		if c.Dark(8, i) {
+			format |= 1 << i
+		}
+	}
+	for i, xy := range [][2]int{{8, 7}, {8, 8}, {7, 8}} {
+		if c.Dark(xy[0], xy[1]) {
+			format |= 1 << (6 + i)
+		}
+	}
+	for i := 9; i < 15; i++ {
+		if c.Dark(14-i, 8) {
+			format |= 1 << i
+		}
+	}
+	if format != formatBits(c.Level, c.Mask) {
+		t.Fatalf("format information %015b does not match level %d, mask %d", format, c.Level, c.Mask)
+	}
+
+	ref := newCode(c.Version, c.Level)
+	var bits []bool
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !ref.reserved[y*c.Size+x] {
+					bits = append(bits, c.Dark(x, y) != maskBit(c.Mask, x, y))
+				}
+			}
+		}
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				codewords[i] |= 0x80 >> j
+			}
+		}
+	}
+
+	bl := blockTable[c.Version-1][c.Level]
+	n := bl.n1 + bl.n2
+	blocksData := make([][]byte, n)
+	i := 0
+	for k := 0; k < max(bl.d1, bl.d2); k++ {
+		for b := 0; b < n; b++ {
+			if b < bl.n1 && k < bl.d1 || b >= bl.n1 && k < bl.d2 {
+				blocksData[b] = append(blocksData[b], codewords[i])
+				i++
+			}
+		}
+	}
+	var data []byte
+	for b := 0; b < n; b++ {
+		var ec []byte
+		for k := 0; k < bl.ec; k++ {
+			ec = append(ec, codewords[i+k*n+b])
+		}
+		if !bytes.Equal(reedSolomon(blocksData[b], bl.ec), ec) {
+			t.Fatalf("block %d: error correction does not match", b)
+		}
+		data = append(data, blocksData[b]...)
+	}
+
+	var pos int
+	read := func(w int) int {
+		v := 0
+		for k := 0; k < w; k++ {
+			v = v<<1 | int(data[pos/8]>>(7-pos%8)&1)
+			pos++
+		}
+		return v
+	}
+	if mode := read(4); mode != 0b0100 {
+		t.Fatalf("mode %04b, want byte mode", mode)
+	}
+	out := make([]byte, read(countBits(c.Version)))
+	for k := range out {
+		out[k] = byte(read(8))
+	}
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 14, 15, 64, 100, 150, 213} {
+		for level := L; level <= H; level++ {
+			data := []byte(strings.Repeat("sha256:0123456789abcdef", 10)[:n])
+			c, err := Encode(data, level)
+			if err == ErrTooLong {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("Encode(%d bytes, %d): %v", n, level, err)
+			}
+			if got := decode(t, c); !bytes.Equal(got, data) {
+				t.Fatalf("version %d-%d: decoded %q, want %q", c.Version, level, got, data)
+			}
+		}
+	}
+	if c, _ := Encode(make([]byte, 14), M); c.Version != 1 {
+		t.Errorf("14 bytes at M should fit version 1, got %d", c.Version)
+	}
+	if c, _ := Encode(make([]byte, 62), M); c.Version != 4 {
+		t.Errorf("62 bytes at M should fit version 4, got %d", c.Version)
+	}
+	if c, _ := Encode(make([]byte, 63), M); c.Version != 5 {
+		t.Errorf("63 bytes at M should need version 5, got %d", c.Version)
+	}
+	if _, err := Encode(make([]byte, 272), L); err != ErrTooLong {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestFunctionPatterns(t *testing.T) {
+	c, err := Encode([]byte("a"), M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The top-left finder's rings, the timing pattern, and the dark module.
+	for _, m := range []struct {
+		x, y int
+		dark bool
+	}{{0, 0, true}, {1, 1, false}, {3, 3, true}, {7, 7, false}, {8, 6, true}, {9, 6, false}, {6, 9, false}, {6, 12, true}, {8, c.Size - 8, true}} {
+		if c.Dark(m.x, m.y) != m.dark {
+			t.Errorf("module (%d, %d) dark = %v, want %v", m.x, m.y, !m.dark, m.dark)
+		}
+	}
+	if svg := c.SVG(); !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `viewBox="0 0 29 29"`) {
+		t.Errorf("unexpected SVG: %.80s", svg)
+	}
+}
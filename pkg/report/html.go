@@ -0,0 +1,79 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package report
+
+import (
+	"html/template"
+	"io"
+	"strings"
+)
+
+// htmlTemplate is a self-contained page, styled for printing as well as
+// for the screen.
+var htmlTemplate = template.Must(template.New("certificate").Funcs(template.FuncMap{
+	"upper": strings.ToUpper,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} — {{.C.Container}}</title>
+<style>
+  @page { size: A4; margin: 20mm; }
+  body { font-family: Helvetica, Arial, sans-serif; font-size: 10.5pt; color: #111; max-width: 190mm; margin: 2em auto; }
+  h1 { font-size: 20pt; margin: 0 0 .2em; }
+  h2 { font-size: 12pt; border-bottom: 1px solid #999; padding-bottom: .2em; margin: 1.6em 0 .5em; }
+  .sub { color: #555; margin: 0 0 1em; }
+  .outcome { padding: .6em .8em; color: #fff; font-weight: bold; }
+  .ok { background: #1f6f3a; }
+  .failed { background: #a11d1d; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; vertical-align: top; padding: .2em .6em .2em 0; }
+  .fields th { width: 30%; font-weight: normal; color: #555; }
+  .files th { border-bottom: 1px solid #999; }
+  .files td { border-bottom: 1px solid #ddd; }
+  .mono { font-family: "Courier New", Courier, monospace; font-size: 9pt; word-break: break-all; }
+  .bad { color: #a11d1d; font-weight: bold; }
+  footer { margin-top: 2em; color: #555; font-size: 9pt; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="sub">Immutable File Container</p>
+<div class="outcome {{if .C.Verified}}ok{{else}}failed{{end}}">{{.Outcome}}</div>
+{{range .Sections}}
+<h2>{{.Title}}</h2>
+<table class="fields">
+{{- range .Fields}}
+<tr><th>{{.Label}}</th><td{{if .Mono}} class="mono"{{end}}>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{end}}
+<h2>Files</h2>
+<p>{{.FilesSummary}}</p>
+<table class="files">
+<tr><th>Status</th><th>File</th><th>SHA-256</th></tr>
+{{- range .C.Files}}
+<tr>
+<td{{if and (ne .Status "ok") (ne .Status "unchecked")}} class="bad"{{end}}>{{upper .Status}}</td>
+<td>{{.Name}}</td>
+<td class="mono">{{if .Encrypted}}encrypted: {{end}}{{.Expected}}{{if .Computed}}<br><span class="bad">found: {{.Computed}}</span>{{end}}</td>
+</tr>
+{{- end}}
+</table>
+<footer>{{.Footer}}</footer>
+</body>
+</html>
+`))
+
+// WriteHTML writes c to w as a standalone HTML page.
+func WriteHTML(w io.Writer, c *Certificate) error {
+	return htmlTemplate.Execute(w, struct {
+		Title        string
+		C            *Certificate
+		Outcome      string
+		Sections     []section
+		FilesSummary string
+		Footer       string
+	}{title, c, c.outcome(), c.sections(), c.filesSummary(), c.footer()})
+}
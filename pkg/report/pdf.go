@@ -0,0 +1,308 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/immutable-container/imf/pkg/container"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// The PDF is written directly: a few pages of text in the standard Type 1
+// fonts, which every reader has, so no font is embedded and nothing beyond
+// the PDF 1.4 basics is needed.
+
+// Page geometry in points: A4 with margins of about 20mm.
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 56
+	labelWidth = 150 // width of the label column of a section
+	fileIndent = 70  // where a file's name and hashes start
+)
+
+// Fonts, as named in each page's resources.
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+	fontMono    = "F3" // Courier
+)
+
+// Colours, as RGB fractions.
+var (
+	black = [3]float64{0.07, 0.07, 0.07}
+	grey  = [3]float64{0.33, 0.33, 0.33}
+	green = [3]float64{0.12, 0.44, 0.23}
+	red   = [3]float64{0.63, 0.11, 0.11}
+	white = [3]float64{1, 1, 1}
+)
+
+// helveticaWidths are the advance widths of ASCII 32 to 126 in Helvetica,
+// in thousandths of the font size, for wrapping text.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// pdfLayout places text on pages, top to bottom.
+type pdfLayout struct {
+	pages []*bytes.Buffer // content stream of each page
+	y     float64         // baseline of the next line on the last page
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, &bytes.Buffer{})
+	l.y = pageHeight - margin
+}
+
+func (l *pdfLayout) page() *bytes.Buffer {
+	return l.pages[len(l.pages)-1]
+}
+
+// need starts a new page unless h more points fit above the bottom margin.
+func (l *pdfLayout) need(h float64) {
+	if l.y-h < margin {
+		l.newPage()
+	}
+}
+
+// text draws s with its baseline at (x, y).
+func (l *pdfLayout) text(font string, size, x, y float64, color [3]float64, s string) {
+	fmt.Fprintf(l.page(), "%.3f %.3f %.3f rg BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		color[0], color[1], color[2], font, size, x, y, pdfString(s))
+}
+
+// fill draws a filled rectangle with its lower left corner at (x, y).
+func (l *pdfLayout) fill(x, y, w, h float64, color [3]float64) {
+	fmt.Fprintf(l.page(), "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n", color[0], color[1], color[2], x, y, w, h)
+}
+
+// rule draws a thin horizontal line across the text width at y.
+func (l *pdfLayout) rule(y float64) {
+	fmt.Fprintf(l.page(), "0.6 G 0.5 w %d %.2f m %d %.2f l S\n", margin, y, pageWidth-margin, y)
+}
+
+// WritePDF writes c to w as a PDF document.
+func WritePDF(w io.Writer, c *Certificate) error {
+	l := &pdfLayout{}
+	l.newPage()
+	width := float64(pageWidth - 2*margin)
+
+	l.text(fontBold, 20, margin, l.y-14, black, title)
+	l.y -= 34
+	l.text(fontRegular, 10, margin, l.y, grey, "Immutable File Container")
+	l.y -= 18
+
+	banner := green
+	if !c.Verified() {
+		banner = red
+	}
+	lines := wrap(fontBold, 11, c.outcome(), width-16)
+	h := float64(len(lines))*14 + 10
+	l.fill(margin, l.y-h, width, h, banner)
+	for i, line := range lines {
+		l.text(fontBold, 11, margin+8, l.y-17-float64(i)*14, white, line)
+	}
+	l.y -= h + 12
+
+	for _, s := range c.sections() {
+		l.heading(s.Title)
+		for _, f := range s.Fields {
+			font, size := fontRegular, 9.5
+			if f.Mono {
+				font, size = fontMono, 8.5
+			}
+			labels := wrap(fontRegular, 9.5, f.Label, labelWidth-10)
+			values := wrap(font, size, f.Value, width-labelWidth)
+			n := max(len(labels), len(values))
+			l.need(float64(n) * 13)
+			for i, line := range labels {
+				l.text(fontRegular, 9.5, margin, l.y-float64(i)*13, grey, line)
+			}
+			for i, line := range values {
+				l.text(font, size, margin+labelWidth, l.y-float64(i)*13, black, line)
+			}
+			l.y -= float64(n)*13 + 2
+		}
+	}
+
+	l.heading("Files")
+	l.text(fontRegular, 9.5, margin, l.y, black, c.filesSummary())
+	l.y -= 18
+	for _, f := range c.Files {
+		names := wrap(fontRegular, 9.5, f.Name, width-fileIndent)
+		hashes := []string{f.Expected}
+		if f.Encrypted {
+			hashes[0] = "encrypted " + f.Expected
+		}
+		if f.Computed != "" {
+			hashes = append(hashes, "found "+f.Computed)
+		}
+		l.need(float64(len(names))*12 + float64(len(hashes))*11)
+		color := black
+		if f.Status != container.FileStatusOK && f.Status != FileStatusUnchecked {
+			color = red
+		}
+		l.text(fontBold, 9, margin, l.y, color, strings.ToUpper(f.Status))
+		for _, line := range names {
+			l.text(fontRegular, 9.5, margin+fileIndent, l.y, black, line)
+			l.y -= 12
+		}
+		for i, line := range hashes {
+			hc := black
+			if i > 0 {
+				hc = red
+			}
+			l.text(fontMono, 8, margin+fileIndent, l.y, hc, line)
+			l.y -= 11
+		}
+		l.y -= 4
+	}
+
+	l.y -= 10
+	for _, line := range wrap(fontRegular, 8.5, c.footer(), width) {
+		l.need(11)
+		l.text(fontRegular, 8.5, margin, l.y, grey, line)
+		l.y -= 11
+	}
+
+	for i := range l.pages {
+		footer := fmt.Sprintf("%s: %s  —  page %d of %d", title, c.Container, i+1, len(l.pages))
+		line := wrap(fontRegular, 7.5, footer, width)[0]
+		fmt.Fprintf(l.pages[i], "%.3f %.3f %.3f rg BT /%s 7.5 Tf %d %d Td (%s) Tj ET\n",
+			grey[0], grey[1], grey[2], fontRegular, margin, margin/2, pdfString(line))
+	}
+	return writePDFDocument(w, l.pages, pdfString(title+": "+c.Container), c.CheckedAt.UTC().Format("20060102150405"))
+}
+
+// heading starts a section with its title over a rule.
+func (l *pdfLayout) heading(s string) {
+	l.need(60)
+	l.y -= 10
+	l.text(fontBold, 12, margin, l.y, black, s)
+	l.rule(l.y - 4)
+	l.y -= 20
+}
+
+// writePDFDocument writes the objects of a PDF with the given page content
+// streams, title (already a PDF string), and creation time
+// (YYYYMMDDHHmmSS, in UTC), followed by its cross-reference table.
+func writePDFDocument(w io.Writer, pages []*bytes.Buffer, docTitle, created string) error {
+	var buf bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	// The comment of high bytes marks the file as binary.
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 6 are fixed; page i is object 7+2i and its contents 8+2i.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 7+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	for _, font := range []string{"Helvetica", "Helvetica-Bold", "Courier"} {
+		obj("<< /Type /Font /Subtype /Type1 /BaseFont /" + font + " /Encoding /WinAnsiEncoding >>")
+	}
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (imf) /CreationDate (D:%sZ) >>", docTitle, created))
+	for i, p := range pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /%s 3 0 R /%s 4 0 R /%s 5 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, fontMono, 8+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", p.Len(), p.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfString encodes s as the body of a PDF literal string in
+// WinAnsiEncoding, escaping what must be and replacing any character the
+// encoding lacks with "?".
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			c = '?'
+		}
+		switch {
+		case c == '\\' || c == '(' || c == ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// textWidth is the width of s in points.
+func textWidth(font string, size float64, s string) float64 {
+	units := 0
+	for _, r := range s {
+		switch {
+		case font == fontMono:
+			units += 600
+		case r >= 32 && r <= 126:
+			units += helveticaWidths[r-32]
+		default:
+			units += 556
+		}
+	}
+	if font == fontBold {
+		units += units / 16 // bold runs a little wider
+	}
+	return float64(units) * size / 1000
+}
+
+// wrap breaks s into lines no wider than width, at spaces where it can and
+// within a word, such as a long path or hash, where it must.
+func wrap(font string, size float64, s string, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(font, size, candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = ""
+		for _, r := range word {
+			if line != "" && textWidth(font, size, line+string(r)) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
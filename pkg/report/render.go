@@ -0,0 +1,150 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/container"
+)
+
+// The HTML and PDF forms of a certificate lay out the same content: a
+// title, the outcome, labelled sections, then the table of files.
+
+const title = "Verification Certificate"
+
+// field is one labelled line of a section.
+type field struct {
+	Label string
+	Value string
+	Mono  bool // a hash, best shown in a fixed-width font
+}
+
+// section is a titled group of fields.
+type section struct {
+	Title  string
+	Fields []field
+}
+
+// outcome is the one-line result shown under the title.
+func (c *Certificate) outcome() string {
+	if c.Err != nil {
+		return "FAILED: " + c.Err.Error()
+	}
+	return "VERIFIED: the signature and the integrity of every file were confirmed"
+}
+
+// sections returns the certificate's content, other than the files.
+func (c *Certificate) sections() []section {
+	box := section{Title: "Container", Fields: []field{
+		{Label: "File", Value: c.Container},
+		{Label: "Size", Value: groupDigits(c.Size) + " bytes"},
+		{Label: "SHA-256", Value: c.SHA256, Mono: true},
+		{Label: "Signed manifest SHA-256", Value: c.ManifestSHA256, Mono: true},
+	}}
+
+	seal := section{Title: "Seal"}
+	if s := c.Signer; s != nil {
+		who := strings.TrimSpace(s.Name)
+		if s.Email != "" {
+			who = strings.TrimSpace(who + " <" + s.Email + ">")
+		}
+		if who != "" {
+			seal.Fields = append(seal.Fields, field{Label: "Signer", Value: who})
+		}
+		seal.Fields = append(seal.Fields, field{Label: "Key fingerprint", Value: s.Fingerprint, Mono: true})
+	} else {
+		seal.Fields = append(seal.Fields, field{Label: "Signer", Value: "(not recorded)"})
+	}
+	seal.Fields = append(seal.Fields, field{Label: "Checked against", Value: c.Key})
+	if c.SealedAt != nil {
+		seal.Fields = append(seal.Fields, field{Label: "Sealed", Value: formatTime(*c.SealedAt)})
+	}
+	if ts := c.Timestamp; ts != nil {
+		trust := "TSA not checked against trusted roots"
+		if ts.Trusted {
+			trust = "trusted TSA"
+		}
+		seal.Fields = append(seal.Fields, field{Label: "RFC 3161 timestamp", Value: fmt.Sprintf("%s by %s (%s)", formatTime(ts.Time), ts.TSA, trust)})
+	}
+
+	anc := section{Title: "Bitcoin anchor"}
+	switch a := c.Anchor; {
+	case a == nil:
+		anc.Fields = append(anc.Fields, field{Label: "Status", Value: "not anchored"})
+	case a.Err != nil:
+		anc.Fields = append(anc.Fields, field{Label: "Status", Value: "unreadable proof: " + a.Err.Error()})
+	default:
+		where := "adjacent .ots proof"
+		if a.Embedded {
+			where = "proof embedded in the manifest"
+		}
+		status := a.State + ", " + where
+		if a.State == anchor.StateMismatch {
+			status = "MISMATCH: the proof is for other contents (" + where + ")"
+		}
+		anc.Fields = append(anc.Fields, field{Label: "Status", Value: status})
+		target := "container file"
+		if a.Target == anchor.TargetManifest {
+			target = "signed manifest"
+		}
+		if a.Hash != "" {
+			anc.Fields = append(anc.Fields, field{Label: "Anchored hash (" + target + ")", Value: a.Hash, Mono: true})
+		}
+		for _, b := range a.Blocks {
+			v := "height " + strconv.FormatUint(b.Height, 10)
+			if !b.Time.IsZero() {
+				v += ", " + formatTime(b.Time)
+			}
+			anc.Fields = append(anc.Fields, field{Label: "Bitcoin block", Value: v})
+		}
+		for _, p := range a.Pending {
+			anc.Fields = append(anc.Fields, field{Label: "Awaiting calendar", Value: p})
+		}
+	}
+
+	return []section{box, seal, anc}
+}
+
+// filesSummary counts the files and those that failed.
+func (c *Certificate) filesSummary() string {
+	failed, unchecked := 0, 0
+	for _, f := range c.Files {
+		switch f.Status {
+		case container.FileStatusOK:
+		case FileStatusUnchecked:
+			unchecked++
+		default:
+			failed++
+		}
+	}
+	if unchecked > 0 {
+		return fmt.Sprintf("%d file(s), not checked", len(c.Files))
+	}
+	return fmt.Sprintf("%d file(s) checked, %d failed", len(c.Files), failed)
+}
+
+// footer says what the certificate is, and how to repeat the check.
+func (c *Certificate) footer() string {
+	return fmt.Sprintf("Generated by imf on %s. This certificate records the result of checking the container at that time; "+
+		"anyone holding the container can repeat the check with \"imf verify -detail\".", formatTime(c.CheckedAt))
+}
+
+// formatTime formats t in UTC, the way a certificate shows times.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05 UTC")
+}
+
+// groupDigits formats n with thousands separators.
+func groupDigits(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package report produces verification certificates: a printable record
+// of a container's check — its hash, who sealed it and when, its Bitcoin
+// anchor, and the hash of every file — in a form lawyers and auditors can
+// file. A certificate is rendered as HTML or as PDF.
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/anchor"
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+// FileStatusUnchecked marks a file that was not checked because
+// verification failed before its contents were reached.
+const FileStatusUnchecked = "unchecked"
+
+// Certificate is the record of one verification of a container.
+type Certificate struct {
+	Container      string    // the container's path, as given
+	Size           int64     // size of the container file in bytes
+	SHA256         string    // hex SHA-256 of the container file
+	ManifestSHA256 string    // hex SHA-256 of the manifest bytes the signature is over
+	CheckedAt      time.Time // when the check was made
+	Key            string    // which public key the signature was checked against
+	Signer         *manifest.SignerIdentity
+	SealedAt       *time.Time // recorded seal time; nil for a hidden manifest
+	Timestamp      *Timestamp
+	Anchor         *Anchor
+	Files          []File
+
+	// Err is why verification failed, or nil if the container verified.
+	// The rest of the certificate is still filled in as far as it could be.
+	Err error
+}
+
+// Timestamp is an RFC 3161 timestamp carried by the container.
+type Timestamp struct {
+	Time    time.Time
+	TSA     string // subject of the TSA's certificate
+	Trusted bool   // the TSA was checked against trusted roots
+}
+
+// Anchor is the state of the container's OpenTimestamps proof.
+type Anchor struct {
+	State    string         // anchor.StatePending, StateConfirmed, StateMismatch, or "unreadable"
+	Target   string         // what Hash is of: anchor.TargetFile or TargetManifest
+	Hash     string         // the digest anchored
+	Embedded bool           // the proof is in the manifest rather than an .ots file
+	Blocks   []anchor.Block // Bitcoin blocks it is anchored in
+	Pending  []string       // calendars still to commit
+	Err      error          // why the proof could not be read, if State is "unreadable"
+}
+
+// File is the check of one file in the container.
+type File struct {
+	Name      string
+	Encrypted bool   // the hashes are of the stored ciphertext
+	Expected  string // hex SHA-256 recorded in the manifest
+	Computed  string // hex SHA-256 of the stored bytes, if it differs or could not be read
+	Status    string // container.FileStatusOK, FileStatusMismatch, FileStatusMissing, or FileStatusUnchecked
+}
+
+// Options configures New.
+type Options struct {
+	Verify container.VerifyOptions // how the container is verified, as for imf verify
+	Anchor anchor.StatusOptions    // how its anchor is looked up
+}
+
+// New verifies the local container at path and records the result. A
+// container that fails verification still gets a certificate, with Err
+// set; New itself fails only if the container cannot be read at all.
+func New(path string, opts Options) (*Certificate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	sum, err := imfcrypto.HashReaderSHA256(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading container: %w", err)
+	}
+	in, err := container.Inspect(path, container.InfoOptions{})
+	if err != nil {
+		return nil, err
+	}
+	signed := sha256.Sum256(in.Signable)
+
+	c := &Certificate{
+		Container:      path,
+		Size:           st.Size(),
+		SHA256:         hex.EncodeToString(sum[:]),
+		ManifestSHA256: hex.EncodeToString(signed[:]),
+		CheckedAt:      time.Now().UTC(),
+		Key:            keySource(in.Manifest, opts.Verify),
+		Signer:         in.Manifest.Signer,
+		SealedAt:       in.Manifest.SealedAt,
+	}
+
+	r, err := container.VerifyWithReport(path, opts.Verify)
+	c.Err = err
+	if r != nil && len(r.Files) > 0 {
+		for _, fc := range r.Files {
+			file := File{Name: fc.Name, Encrypted: fc.Encrypted, Expected: fc.Expected, Status: fc.Status}
+			if fc.Computed != fc.Expected {
+				file.Computed = fc.Computed
+			}
+			c.Files = append(c.Files, file)
+		}
+	} else {
+		// Verification stopped before the files, so list what the
+		// manifest records without vouching for it.
+		for _, fe := range in.Manifest.Files {
+			file := File{Name: fe.OriginalName, Expected: fe.SHA256, Status: FileStatusUnchecked}
+			if in.Manifest.Encryption != nil {
+				file.Encrypted, file.Expected = true, fe.EncryptedSHA256
+			}
+			c.Files = append(c.Files, file)
+		}
+	}
+	if r != nil {
+		if r.Signer != nil {
+			c.Signer = r.Signer
+		}
+		if r.SealedAt != nil {
+			c.SealedAt = r.SealedAt
+		}
+		if ts := r.Timestamp; ts != nil {
+			c.Timestamp = &Timestamp{Time: ts.Time, TSA: ts.Signer.Subject.String(), Trusted: opts.Verify.TSARoots != nil}
+		}
+	}
+
+	status, err := anchor.StatusWithOptions(path, opts.Anchor)
+	switch {
+	case err != nil:
+		c.Anchor = &Anchor{State: "unreadable", Err: err}
+	case status != nil:
+		c.Anchor = &Anchor{
+			State:    status.State,
+			Target:   status.Target,
+			Hash:     status.Hash,
+			Embedded: status.Embedded,
+			Blocks:   status.Blocks,
+			Pending:  status.Pending,
+		}
+	}
+	return c, nil
+}
+
+// Verified reports whether the container passed verification.
+func (c *Certificate) Verified() bool {
+	return c.Err == nil
+}
+
+// keySource describes the key the signature is checked against, since
+// an embedded key on its own says nothing about who sealed a container.
+func keySource(m *manifest.Manifest, opts container.VerifyOptions) string {
+	switch {
+	case opts.PublicKey != nil:
+		return "public key supplied by the verifier"
+	case opts.RequireTrusted:
+		return "embedded public key, required to be in the verifier's trust store"
+	case m.PublicKey != "":
+		return "embedded public key (not checked against a trust store)"
+	default:
+		return "none available"
+	}
+}
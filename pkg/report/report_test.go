@@ -0,0 +1,138 @@
+package report_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/container"
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/report"
+)
+
+// sealedContainer makes a sealed container holding two files.
+func sealedContainer(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	imfPath := filepath.Join(tmpDir, "evidence.imf")
+	container.Create(imfPath)
+	a := filepath.Join(tmpDir, "contract (signed).pdf")
+	b := filepath.Join(tmpDir, "exhibit-é.txt")
+	os.WriteFile(a, []byte("%PDF-1.4 contract"), 0644)
+	os.WriteFile(b, []byte("exhibit"), 0644)
+	container.Add(imfPath, []string{a, b})
+	kp, _ := imfcrypto.GenerateKeyPair()
+	err := container.Seal(imfPath, container.SealOptions{
+		PrivateKey:  kp.PrivateKey,
+		EmbedPubKey: true,
+		SignerName:  "Dana Reyes",
+		SignerEmail: "dana@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	return imfPath
+}
+
+func TestCertificate(t *testing.T) {
+	imfPath := sealedContainer(t)
+	c, err := report.New(imfPath, report.Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if !c.Verified() {
+		t.Fatalf("not verified: %v", c.Err)
+	}
+	if c.Signer == nil || c.Signer.Name != "Dana Reyes" || c.SealedAt == nil || c.Anchor != nil {
+		t.Fatalf("certificate %+v", c)
+	}
+	if len(c.Files) != 2 || c.Files[0].Status != container.FileStatusOK || c.Files[0].Computed != "" {
+		t.Fatalf("files %+v", c.Files)
+	}
+	data, _ := os.ReadFile(imfPath)
+	if sum := imfcrypto.HashSHA256(data); c.SHA256 != fmt.Sprintf("%x", sum) || c.Size != int64(len(data)) {
+		t.Fatalf("container hash %s, size %d", c.SHA256, c.Size)
+	}
+
+	var html bytes.Buffer
+	if err := report.WriteHTML(&html, c); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	for _, want := range []string{"VERIFIED", c.SHA256, c.Files[1].Expected, "exhibit-é.txt", "Dana Reyes &lt;dana@example.com&gt;"} {
+		if !strings.Contains(html.String(), want) {
+			t.Fatalf("HTML lacks %q", want)
+		}
+	}
+
+	var pdf bytes.Buffer
+	if err := report.WritePDF(&pdf, c); err != nil {
+		t.Fatalf("WritePDF: %v", err)
+	}
+	checkPDF(t, pdf.Bytes())
+	for _, want := range []string{c.SHA256, `contract \(signed\).pdf`, "exhibit-\\351.txt", "VERIFIED"} {
+		if !bytes.Contains(pdf.Bytes(), []byte(want)) {
+			t.Fatalf("PDF lacks %q", want)
+		}
+	}
+
+	// A failed check still gets a certificate, saying so.
+	other, _ := imfcrypto.GenerateKeyPair()
+	c, err = report.New(imfPath, report.Options{Verify: container.VerifyOptions{PublicKey: other.PublicKey}})
+	if err != nil {
+		t.Fatalf("New with wrong key: %v", err)
+	}
+	if c.Verified() || !errors.Is(c.Err, container.ErrSignature) {
+		t.Fatalf("wrong key: %v", c.Err)
+	}
+	if len(c.Files) != 2 || c.Files[0].Status != report.FileStatusUnchecked {
+		t.Fatalf("files %+v", c.Files)
+	}
+	html.Reset()
+	report.WriteHTML(&html, c)
+	if !strings.Contains(html.String(), "FAILED") || strings.Contains(html.String(), "VERIFIED") {
+		t.Fatal("HTML does not report the failure")
+	}
+
+	if _, err := report.New(filepath.Join(t.TempDir(), "missing.imf"), report.Options{}); err == nil {
+		t.Fatal("expected a missing container to fail")
+	}
+	t.Log("✓ Certificates record the check, as HTML and as a well-formed PDF")
+}
+
+// checkPDF checks that every object the cross-reference table lists is
+// where it says, and that the trailer points at the table.
+func checkPDF(t *testing.T, data []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("PDF header or trailer missing")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(data[xref:], -1)
+	if len(entries) < 8 {
+		t.Fatalf("only %d objects", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[off:], []byte(want)) {
+			t.Fatalf("object %d not at offset %d", i+1, off)
+		}
+	}
+	for _, s := range regexp.MustCompile(`(?s)/Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllSubmatch(data, -1) {
+		if n, _ := strconv.Atoi(string(s[1])); n != len(s[2]) {
+			t.Fatalf("stream length %d, actual %d", n, len(s[2]))
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+package sigstore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a Rekor log entry with the evidence that it was logged.
+type Entry struct {
+	Body           string        `json:"body"`           // base64 canonical entry, as logged
+	IntegratedTime int64         `json:"integratedTime"` // Unix time the log accepted the entry
+	LogID          string        `json:"logID"`          // hex SHA-256 of the log's public key
+	LogIndex       int64         `json:"logIndex"`
+	Verification   *Verification `json:"verification,omitempty"`
+}
+
+// Verification is the log's signed promise to include an entry, and the
+// proof that it did.
+type Verification struct {
+	SignedEntryTimestamp string          `json:"signedEntryTimestamp"` // base64 signature over the entry
+	InclusionProof       *InclusionProof `json:"inclusionProof,omitempty"`
+}
+
+// InclusionProof is an RFC 6962 Merkle audit path from an entry to a tree
+// head, together with the log's signed checkpoint for that head.
+type InclusionProof struct {
+	Checkpoint string   `json:"checkpoint"`
+	Hashes     []string `json:"hashes"` // hex sibling hashes, leaf to root
+	LogIndex   int64    `json:"logIndex"`
+	RootHash   string   `json:"rootHash"` // hex
+	TreeSize   int64    `json:"treeSize"`
+}
+
+// Time returns when the log accepted the entry.
+func (e *Entry) Time() time.Time {
+	return time.Unix(e.IntegratedTime, 0).UTC()
+}
+
+// rekord is a "rekord" entry: a signature by an X.509 certificate's key
+// over some data. Rekor is sent the data and logs only its hash.
+type rekord struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Data struct {
+			Content string `json:"content,omitempty"`
+			Hash    *struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash,omitempty"`
+		} `json:"data"`
+		Signature struct {
+			Content   string `json:"content"`
+			Format    string `json:"format"`
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// Upload records sig, cert's signature over digest, in the Rekor log at url
+// and returns the new entry. Only digest itself is sent, never the data it
+// is a digest of.
+func Upload(url string, cert *x509.Certificate, digest, sig []byte) (*Entry, error) {
+	var r rekord
+	r.APIVersion = "0.0.1"
+	r.Kind = "rekord"
+	r.Spec.Data.Content = base64.StdEncoding.EncodeToString(digest)
+	r.Spec.Signature.Content = base64.StdEncoding.EncodeToString(sig)
+	r.Spec.Signature.Format = "x509"
+	r.Spec.Signature.PublicKey.Content = base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("encoding log entry: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(url, "/")+"/api/v1/log/entries", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("contacting Rekor: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp map[string]*Entry
+	if err := doJSON(req, http.StatusCreated, &resp); err != nil {
+		return nil, fmt.Errorf("contacting Rekor: %w", err)
+	}
+	if len(resp) != 1 {
+		return nil, fmt.Errorf("Rekor returned %d entries, want 1", len(resp))
+	}
+	var entry *Entry
+	for _, e := range resp {
+		entry = e
+	}
+	if entry == nil || entry.Verification == nil {
+		return nil, errors.New("Rekor returned an entry without verification data")
+	}
+	if err := entry.Check(cert, digest); err != nil {
+		return nil, fmt.Errorf("Rekor logged a different entry: %w", err)
+	}
+	return entry, nil
+}
+
+// Check checks that e records a valid signature over digest by cert's key.
+// It does not check that the entry was logged; see Verify.
+func (e *Entry) Check(cert *x509.Certificate, digest []byte) error {
+	body, err := base64.StdEncoding.DecodeString(e.Body)
+	if err != nil {
+		return fmt.Errorf("decoding entry body: %w", err)
+	}
+	var r rekord
+	if err := json.Unmarshal(body, &r); err != nil {
+		return fmt.Errorf("parsing entry body: %w", err)
+	}
+	if r.Kind != "rekord" || r.Spec.Signature.Format != "x509" {
+		return fmt.Errorf("unsupported entry kind %q", r.Kind)
+	}
+
+	h := r.Spec.Data.Hash
+	want := sha256.Sum256(digest)
+	if h == nil || h.Algorithm != "sha256" || h.Value != hex.EncodeToString(want[:]) {
+		return errors.New("entry is for different data")
+	}
+	pemCert, err := base64.StdEncoding.DecodeString(r.Spec.Signature.PublicKey.Content)
+	if err != nil {
+		return fmt.Errorf("decoding entry certificate: %w", err)
+	}
+	block, _ := pem.Decode(pemCert)
+	if block == nil || !bytes.Equal(block.Bytes, cert.Raw) {
+		return errors.New("entry is for a different certificate")
+	}
+	sig, err := base64.StdEncoding.DecodeString(r.Spec.Signature.Content)
+	if err != nil {
+		return fmt.Errorf("decoding entry signature: %w", err)
+	}
+	pub, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok || !ed25519.Verify(pub, digest, sig) {
+		return errors.New("entry signature is invalid")
+	}
+	return nil
+}
+
+// Verify checks that e was logged by the Rekor log whose key is key: the
+// signed entry timestamp must verify, and the inclusion proof must lead
+// from the entry to a tree head in a checkpoint the log signed.
+func (e *Entry) Verify(key crypto.PublicKey) error {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return fmt.Errorf("encoding Rekor key: %w", err)
+	}
+	keyID := sha256.Sum256(der)
+	if e.LogID != hex.EncodeToString(keyID[:]) {
+		return errors.New("entry is from a different log")
+	}
+	if e.Verification == nil {
+		return errors.New("entry has no verification data")
+	}
+
+	// The signed entry timestamp covers the canonical JSON of the entry's
+	// fields, keys sorted.
+	set, err := base64.StdEncoding.DecodeString(e.Verification.SignedEntryTimestamp)
+	if err != nil {
+		return fmt.Errorf("decoding signed entry timestamp: %w", err)
+	}
+	payload, _ := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{e.Body, e.IntegratedTime, e.LogID, e.LogIndex})
+	if !verifySignature(key, payload, set) {
+		return errors.New("signed entry timestamp is invalid")
+	}
+
+	p := e.Verification.InclusionProof
+	if p == nil {
+		return errors.New("entry has no inclusion proof")
+	}
+	body, err := base64.StdEncoding.DecodeString(e.Body)
+	if err != nil {
+		return fmt.Errorf("decoding entry body: %w", err)
+	}
+	proof := make([][]byte, len(p.Hashes))
+	for i, h := range p.Hashes {
+		if proof[i], err = hex.DecodeString(h); err != nil {
+			return fmt.Errorf("decoding inclusion proof: %w", err)
+		}
+	}
+	root, err := rootFromInclusionProof(p.LogIndex, p.TreeSize, leafHash(body), proof)
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(root) != p.RootHash {
+		return errors.New("inclusion proof does not lead to the tree head")
+	}
+	return checkCheckpoint(p.Checkpoint, key, p.TreeSize, root)
+}
+
+// checkCheckpoint checks that cp is a signed note by key committing to a
+// tree of size entries with the given root.
+//
+// A checkpoint is the note text — origin, tree size, base64 root hash, and
+// optional extra lines — then a blank line and one or more signature lines
+// "— <name> <base64(key hint || signature)>".
+func checkCheckpoint(cp string, key crypto.PublicKey, size int64, root []byte) error {
+	text, sigs, ok := strings.Cut(cp, "\n\n")
+	if !ok {
+		return errors.New("malformed checkpoint")
+	}
+	text += "\n"
+	lines := strings.Split(text, "\n")
+	if len(lines) < 4 {
+		return errors.New("malformed checkpoint")
+	}
+	if n, err := strconv.ParseInt(lines[1], 10, 64); err != nil || n != size {
+		return errors.New("checkpoint is for a different tree size")
+	}
+	if lines[2] != base64.StdEncoding.EncodeToString(root) {
+		return errors.New("checkpoint is for a different root hash")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return fmt.Errorf("encoding Rekor key: %w", err)
+	}
+	hint := sha256.Sum256(der)
+	for _, line := range strings.Split(sigs, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(line, "— "))
+		if !strings.HasPrefix(line, "— ") || len(fields) != 2 {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil || len(sig) < 4 || !bytes.Equal(sig[:4], hint[:4]) {
+			continue
+		}
+		if verifySignature(key, []byte(text), sig[4:]) {
+			return nil
+		}
+		return errors.New("checkpoint signature is invalid")
+	}
+	return errors.New("checkpoint is not signed by the log")
+}
+
+// verifySignature checks a Rekor signature over msg: ECDSA over its SHA-256,
+// or Ed25519 over msg itself.
+func verifySignature(key crypto.PublicKey, msg, sig []byte) bool {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(msg)
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, msg, sig)
+	}
+	return false
+}
+
+// RFC 6962 Merkle tree hashing, with domain separation between leaves and
+// interior nodes.
+
+func leafHash(data []byte) []byte {
+	h := sha256.Sum256(append([]byte{0}, data...))
+	return h[:]
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// rootFromInclusionProof computes the root of a tree of size leaves from
+// the hash of leaf index and its audit path. The path first climbs the
+// subtree the leaf shares with its neighbours, then the right border.
+func rootFromInclusionProof(index, size int64, leaf []byte, proof [][]byte) ([]byte, error) {
+	if index < 0 || index >= size {
+		return nil, fmt.Errorf("inclusion proof index %d outside tree of size %d", index, size)
+	}
+	inner := bits.Len64(uint64(index) ^ uint64(size-1))
+	border := bits.OnesCount64(uint64(index) >> inner)
+	if len(proof) != inner+border {
+		return nil, fmt.Errorf("inclusion proof has %d hashes, want %d", len(proof), inner+border)
+	}
+	res := leaf
+	for i, h := range proof[:inner] {
+		if (index>>i)&1 == 0 {
+			res = nodeHash(res, h)
+		} else {
+			res = nodeHash(h, res)
+		}
+	}
+	for _, h := range proof[inner:] {
+		res = nodeHash(h, res)
+	}
+	return res, nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package sigstore implements keyless signing with Sigstore's Fulcio
+// certificate authority and Rekor transparency log.
+//
+// Instead of keeping a long-lived key, the sealer proves who they are with
+// an OpenID Connect token, such as the one a CI pipeline is issued:
+//
+//  1. Certify: Fulcio checks the token and issues a certificate, valid for
+//     a few minutes, binding a fresh key to the token's identity and issuer
+//  2. Log: the signature is recorded in Rekor, which returns a signed entry
+//     timestamp and a Merkle inclusion proof for the entry
+//  3. Verify: anyone holding Rekor's public key can check, offline, that the
+//     signature was logged while the certificate was valid
+//
+// Only what keyless signing needs is implemented: Fulcio's v2 signingCert
+// endpoint, and Rekor's v1 log entry and public key endpoints with "rekord"
+// entries. Certificate transparency SCTs are not checked.
+package sigstore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// The public-good Sigstore instances.
+const (
+	DefaultFulcioURL = "https://fulcio.sigstore.dev"
+	DefaultRekorURL  = "https://rekor.sigstore.dev"
+)
+
+// maxResponseSize caps a Fulcio or Rekor response; both are a few kilobytes.
+const maxResponseSize = 1 << 20
+
+var (
+	// Fulcio records the token's issuer in the certificate, originally as a
+	// raw string and now as a DER UTF8String.
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// IdentityToken returns an OIDC token for Fulcio: SIGSTORE_ID_TOKEN if it
+// is set, otherwise one requested from GitHub Actions when running there
+// with the id-token permission.
+func IdentityToken() (string, error) {
+	if tok := os.Getenv("SIGSTORE_ID_TOKEN"); tok != "" {
+		return tok, nil
+	}
+	reqURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	reqToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if reqURL == "" || reqToken == "" {
+		return "", errors.New("no identity token: set SIGSTORE_ID_TOKEN, or run in GitHub Actions with id-token: write")
+	}
+	req, err := http.NewRequest(http.MethodGet, reqURL+"&audience=sigstore", nil)
+	if err != nil {
+		return "", fmt.Errorf("requesting GitHub Actions token: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+reqToken)
+	var resp struct {
+		Value string `json:"value"`
+	}
+	if err := doJSON(req, http.StatusOK, &resp); err != nil {
+		return "", fmt.Errorf("requesting GitHub Actions token: %w", err)
+	}
+	if resp.Value == "" {
+		return "", errors.New("GitHub Actions returned an empty token")
+	}
+	return resp.Value, nil
+}
+
+// tokenSubject returns the identity Fulcio will certify for an OIDC token:
+// its email claim if it has one, otherwise its subject. The token's
+// signature is Fulcio's to check.
+func tokenSubject(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("identity token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("decoding identity token: %w", err)
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("decoding identity token: %w", err)
+	}
+	if claims.Email != "" {
+		return claims.Email, nil
+	}
+	if claims.Subject == "" {
+		return "", errors.New("identity token has no subject")
+	}
+	return claims.Subject, nil
+}
+
+// Fulcio v2 signingCert request and response bodies.
+
+type certRequest struct {
+	Credentials struct {
+		OIDCIdentityToken string `json:"oidcIdentityToken"`
+	} `json:"credentials"`
+	PublicKeyRequest struct {
+		PublicKey struct {
+			Algorithm string `json:"algorithm"`
+			Content   string `json:"content"`
+		} `json:"publicKey"`
+		ProofOfPossession string `json:"proofOfPossession"`
+	} `json:"publicKeyRequest"`
+}
+
+type certChain struct {
+	Chain struct {
+		Certificates []string `json:"certificates"`
+	} `json:"chain"`
+}
+
+type certResponse struct {
+	Embedded *certChain `json:"signedCertificateEmbeddedSct"`
+	Detached *certChain `json:"signedCertificateDetachedSct"`
+}
+
+// RequestCertificate asks the Fulcio instance at url to certify key's
+// public half for the identity in token, and returns the certificate chain,
+// leaf first. Possession of key is proven by signing the token's subject.
+func RequestCertificate(url, token string, key ed25519.PrivateKey) ([]*x509.Certificate, error) {
+	subject, err := tokenSubject(token)
+	if err != nil {
+		return nil, err
+	}
+	pub := key.Public().(ed25519.PublicKey)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("encoding public key: %w", err)
+	}
+	var cr certRequest
+	cr.Credentials.OIDCIdentityToken = token
+	cr.PublicKeyRequest.PublicKey.Algorithm = "ED25519"
+	cr.PublicKeyRequest.PublicKey.Content = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	cr.PublicKeyRequest.ProofOfPossession = base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(subject)))
+	body, err := json.Marshal(cr)
+	if err != nil {
+		return nil, fmt.Errorf("encoding certificate request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(url, "/")+"/api/v2/signingCert", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("contacting Fulcio: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp certResponse
+	if err := doJSON(req, http.StatusCreated, &resp); err != nil {
+		return nil, fmt.Errorf("contacting Fulcio: %w", err)
+	}
+	cc := resp.Embedded
+	if cc == nil {
+		cc = resp.Detached
+	}
+	if cc == nil || len(cc.Chain.Certificates) == 0 {
+		return nil, errors.New("Fulcio response carries no certificates")
+	}
+
+	var chain []*x509.Certificate
+	for _, p := range cc.Chain.Certificates {
+		block, _ := pem.Decode([]byte(p))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return nil, errors.New("Fulcio returned a malformed certificate")
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing Fulcio certificate: %w", err)
+		}
+		chain = append(chain, c)
+	}
+	if leaf, ok := chain[0].PublicKey.(ed25519.PublicKey); !ok || !leaf.Equal(pub) {
+		return nil, errors.New("Fulcio certified a different key")
+	}
+	return chain, nil
+}
+
+// Identity returns the identity and OIDC issuer a Fulcio certificate was
+// issued for. The identity is an email address or, for workloads such as
+// CI jobs, a URI.
+func Identity(cert *x509.Certificate) (identity, issuer string) {
+	switch {
+	case len(cert.EmailAddresses) > 0:
+		identity = cert.EmailAddresses[0]
+	case len(cert.URIs) > 0:
+		identity = cert.URIs[0].String()
+	}
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var s string
+			if _, err := asn1.UnmarshalWithParams(ext.Value, &s, "utf8"); err == nil {
+				return identity, s
+			}
+		case ext.Id.Equal(oidIssuerV1):
+			issuer = string(ext.Value)
+		}
+	}
+	return identity, issuer
+}
+
+// PublicKey fetches the signing key of the Rekor log at url. Keys fetched
+// this way are only as trustworthy as the connection; pin a copy with
+// ParsePublicKeyPEM where that matters.
+func PublicKey(url string) (crypto.PublicKey, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(strings.TrimSuffix(url, "/") + "/api/v1/log/publicKey")
+	if err != nil {
+		return nil, fmt.Errorf("contacting Rekor: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Rekor %s returned status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading Rekor public key: %w", err)
+	}
+	return ParsePublicKeyPEM(data)
+}
+
+// ParsePublicKeyPEM decodes a Rekor public key in PKIX PEM form.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("no PUBLIC KEY block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+	return key, nil
+}
+
+// doJSON sends req and decodes a JSON response with status want into v.
+func doJSON(req *http.Request, want int, v any) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if len(body) > maxResponseSize {
+		return fmt.Errorf("response exceeds %d bytes", maxResponseSize)
+	}
+	if resp.StatusCode != want {
+		return fmt.Errorf("%s returned status %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
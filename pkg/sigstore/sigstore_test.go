@@ -0,0 +1,233 @@
+package sigstore_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/immutable-container/imf/pkg/sigstore"
+)
+
+const (
+	testEmail  = "ci@example.com"
+	testIssuer = "https://issuer.example.com"
+)
+
+// testToken is an unsigned JWT for testEmail; the fake Fulcio trusts it.
+func testToken() string {
+	claims, _ := json.Marshal(map[string]string{"sub": "1234", "email": testEmail})
+	return "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".c2ln"
+}
+
+// newFulcio starts a CA that certifies any key whose proof of possession
+// verifies, and returns its URL.
+func newFulcio(t *testing.T) string {
+	t.Helper()
+	rootPub, rootKey, _ := ed25519.GenerateKey(rand.Reader)
+	rootTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Fulcio Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rootDER, _ := x509.CreateCertificate(rand.Reader, rootTmpl, rootTmpl, rootPub, rootKey)
+	root, _ := x509.ParseCertificate(rootDER)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			PublicKeyRequest struct {
+				PublicKey struct {
+					Content string `json:"content"`
+				} `json:"publicKey"`
+				ProofOfPossession string `json:"proofOfPossession"`
+			} `json:"publicKeyRequest"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		block, _ := pem.Decode([]byte(req.PublicKeyRequest.PublicKey.Content))
+		if block == nil {
+			http.Error(w, "bad key", http.StatusBadRequest)
+			return
+		}
+		key, _ := x509.ParsePKIXPublicKey(block.Bytes)
+		pub, _ := key.(ed25519.PublicKey)
+		pop, _ := base64.StdEncoding.DecodeString(req.PublicKeyRequest.ProofOfPossession)
+		if pub == nil || !ed25519.Verify(pub, []byte(testEmail), pop) {
+			http.Error(w, "bad proof of possession", http.StatusBadRequest)
+			return
+		}
+		issuer, _ := asn1.MarshalWithParams(testIssuer, "utf8")
+		leafDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber:    big.NewInt(time.Now().UnixNano()),
+			NotBefore:       time.Now().Add(-time.Minute),
+			NotAfter:        time.Now().Add(10 * time.Minute),
+			KeyUsage:        x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			EmailAddresses:  []string{testEmail},
+			ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}, Value: issuer}},
+		}, root, pub, rootKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var resp struct {
+			Embedded struct {
+				Chain struct {
+					Certificates []string `json:"certificates"`
+				} `json:"chain"`
+			} `json:"signedCertificateEmbeddedSct"`
+		}
+		for _, der := range [][]byte{leafDER, rootDER} {
+			resp.Embedded.Chain.Certificates = append(resp.Embedded.Chain.Certificates,
+				string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// newRekor starts a log that already holds one entry, appends each upload
+// as the second, and returns its URL.
+func newRekor(t *testing.T) string {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	logID := sha256.Sum256(der)
+	sign := func(msg []byte) []byte {
+		digest := sha256.Sum256(msg)
+		sig, _ := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		return sig
+	}
+	hash := func(prefix byte, parts ...[]byte) []byte {
+		h := sha256.New()
+		h.Write([]byte{prefix})
+		for _, p := range parts {
+			h.Write(p)
+		}
+		return h.Sum(nil)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/log/publicKey", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	})
+	mux.HandleFunc("/api/v1/log/entries", func(w http.ResponseWriter, r *http.Request) {
+		// Like Rekor, log the hash of the data in place of the data.
+		var entry map[string]any
+		json.NewDecoder(r.Body).Decode(&entry)
+		data := entry["spec"].(map[string]any)["data"].(map[string]any)
+		content, _ := base64.StdEncoding.DecodeString(data["content"].(string))
+		sum := sha256.Sum256(content)
+		delete(data, "content")
+		data["hash"] = map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(sum[:])}
+		body, _ := json.Marshal(entry)
+
+		first := hash(0, []byte("genesis"))
+		root := hash(1, first, hash(0, body))
+		note := fmt.Sprintf("test.rekor - 1\n2\n%s\n", base64.StdEncoding.EncodeToString(root))
+		cp := note + "\n— test.rekor " + base64.StdEncoding.EncodeToString(append(logID[:4:4], sign([]byte(note))...)) + "\n"
+
+		e := sigstore.Entry{
+			Body:           base64.StdEncoding.EncodeToString(body),
+			IntegratedTime: time.Now().Unix(),
+			LogID:          hex.EncodeToString(logID[:]),
+			LogIndex:       1,
+		}
+		set, _ := json.Marshal(map[string]any{"body": e.Body, "integratedTime": e.IntegratedTime, "logID": e.LogID, "logIndex": e.LogIndex})
+		e.Verification = &sigstore.Verification{
+			SignedEntryTimestamp: base64.StdEncoding.EncodeToString(sign(set)),
+			InclusionProof: &sigstore.InclusionProof{
+				Checkpoint: cp,
+				Hashes:     []string{hex.EncodeToString(first)},
+				LogIndex:   1,
+				RootHash:   hex.EncodeToString(root),
+				TreeSize:   2,
+			},
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]sigstore.Entry{"uuid": e})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestKeylessSigning(t *testing.T) {
+	fulcioURL, rekorURL := newFulcio(t), newRekor(t)
+	t.Setenv("SIGSTORE_ID_TOKEN", testToken())
+
+	token, err := sigstore.IdentityToken()
+	if err != nil {
+		t.Fatalf("IdentityToken: %v", err)
+	}
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	chain, err := sigstore.RequestCertificate(fulcioURL, token, key)
+	if err != nil {
+		t.Fatalf("RequestCertificate: %v", err)
+	}
+	if id, iss := sigstore.Identity(chain[0]); id != testEmail || iss != testIssuer {
+		t.Fatalf("certificate for %q from %q", id, iss)
+	}
+
+	digest := sha256.Sum256([]byte("manifest bytes"))
+	entry, err := sigstore.Upload(rekorURL, chain[0], digest[:], ed25519.Sign(key, digest[:]))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	rekorKey, err := sigstore.PublicKey(rekorURL)
+	if err != nil {
+		t.Fatalf("PublicKey: %v", err)
+	}
+	if err := entry.Verify(rekorKey); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := entry.Check(chain[0], digest[:]); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	// Another digest, or another log's key, are rejected.
+	other := sha256.Sum256([]byte("other bytes"))
+	if err := entry.Check(chain[0], other[:]); err == nil {
+		t.Fatal("expected a different digest to be rejected")
+	}
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err := entry.Verify(&otherKey.PublicKey); err == nil {
+		t.Fatal("expected another log's key to be rejected")
+	}
+
+	// A broken audit path or a forged checkpoint fail the inclusion proof.
+	bad := *entry
+	proof := *entry.Verification.InclusionProof
+	bad.Verification = &sigstore.Verification{SignedEntryTimestamp: entry.Verification.SignedEntryTimestamp, InclusionProof: &proof}
+	proof.Hashes = []string{strings.Repeat("00", 32)}
+	if err := bad.Verify(rekorKey); err == nil {
+		t.Fatal("expected a broken inclusion proof to be rejected")
+	}
+	proof.Hashes = entry.Verification.InclusionProof.Hashes
+	proof.Checkpoint = strings.Replace(proof.Checkpoint, "\n2\n", "\n3\n", 1)
+	if err := bad.Verify(rekorKey); err == nil {
+		t.Fatal("expected a forged checkpoint to be rejected")
+	}
+
+	t.Logf("✓ Fulcio certificate obtained, Rekor entry logged and proven, tampering rejected")
+}
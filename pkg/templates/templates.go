@@ -0,0 +1,197 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package templates reads container templates. A template starts a kind of
+// container off with its details filled in, names the details it must not
+// be sealed without, and says how it is sealed: when it expires and
+// whether it is encrypted. A few templates are built in; JSON files in the
+// templates directory add more, or replace a built-in one of the same name.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+)
+
+//go:embed builtin/*.json
+var builtinFS embed.FS
+
+// Encryption policies.
+const (
+	EncryptionOptional = ""         // the person sealing decides
+	EncryptionRequired = "required" // sealed only with a passphrase
+	EncryptionNone     = "none"     // sealed only without one
+)
+
+// Template is one kind of container.
+type Template struct {
+	ID          string            `json:"id"` // the template's file name, without .json
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Metadata    manifest.Metadata `json:"metadata"`              // details a new container starts with
+	Required    []string          `json:"required,omitempty"`    // details that must be filled in: "title", "description", "case_number", or "tags"
+	ExpiryDays  int               `json:"expiry_days,omitempty"` // when it expires, in days from sealing; 0 for never
+	Encryption  string            `json:"encryption,omitempty"`  // one of the Encryption policies
+}
+
+// ErrNotFound is returned by Find for a template that does not exist.
+var ErrNotFound = errors.New("no such template")
+
+// DefaultDir returns the templates directory: $IMF_TEMPLATES if set,
+// otherwise ~/.imf/templates.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("IMF_TEMPLATES"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
+	}
+	return filepath.Join(home, ".imf", "templates"), nil
+}
+
+// Load returns the built-in templates and those in dir, sorted by name. A
+// missing dir, or "", holds none.
+func Load(dir string) ([]Template, error) {
+	byID := make(map[string]Template)
+	if err := load(builtinFS, "builtin", byID); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := load(os.DirFS(dir), ".", byID); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	list := make([]Template, 0, len(byID))
+	for _, t := range byID {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Find returns the template with the given ID, from those Load returns.
+func Find(dir, id string) (*Template, error) {
+	list, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range list {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// load adds the templates in the directory dir of fsys to byID.
+func load(fsys fs.FS, dir string, byID map[string]Template) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() || strings.HasPrefix(id, ".") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		var t Template
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.DisallowUnknownFields() // a misspelled field is not silently ignored
+		if err := d.Decode(&t); err != nil {
+			return fmt.Errorf("template %s: %w", e.Name(), err)
+		}
+		t.ID = id
+		if err := t.check(); err != nil {
+			return fmt.Errorf("template %s: %w", e.Name(), err)
+		}
+		byID[id] = t
+	}
+	return nil
+}
+
+// fields names the details Required may list, as they are named to people.
+var fields = map[string]string{
+	"title":       "a title",
+	"description": "a description",
+	"case_number": "a case number",
+	"tags":        "tags",
+}
+
+// check reports what is wrong with a template as read.
+func (t *Template) check() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("no name")
+	}
+	for _, f := range t.Required {
+		if fields[f] == "" {
+			return fmt.Errorf("unknown required detail %q: use title, description, case_number, or tags", f)
+		}
+	}
+	if t.ExpiryDays < 0 {
+		return errors.New("expiry_days is negative")
+	}
+	switch t.Encryption {
+	case EncryptionOptional, EncryptionRequired, EncryptionNone:
+	default:
+		return fmt.Errorf("unknown encryption %q: use required or none, or leave it out", t.Encryption)
+	}
+	return nil
+}
+
+// CheckMetadata reports which of the details the template requires md
+// lacks. md may be nil.
+func (t *Template) CheckMetadata(md *manifest.Metadata) error {
+	if md == nil {
+		md = &manifest.Metadata{}
+	}
+	var missing []string
+	for _, f := range t.Required {
+		var empty bool
+		switch f {
+		case "title":
+			empty = strings.TrimSpace(md.Title) == ""
+		case "description":
+			empty = strings.TrimSpace(md.Description) == ""
+		case "case_number":
+			empty = strings.TrimSpace(md.CaseNumber) == ""
+		case "tags":
+			empty = !slices.ContainsFunc(md.Tags, func(tag string) bool { return strings.TrimSpace(tag) != "" })
+		}
+		if empty {
+			missing = append(missing, fields[f])
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the %s template requires %s", t.Name, strings.Join(missing, " and "))
+	}
+	return nil
+}
+
+// CheckEncryption reports whether the template allows a container to be
+// sealed encrypted, or not.
+func (t *Template) CheckEncryption(encrypted bool) error {
+	switch {
+	case t.Encryption == EncryptionRequired && !encrypted:
+		return fmt.Errorf("the %s template requires a passphrase to encrypt the container", t.Name)
+	case t.Encryption == EncryptionNone && encrypted:
+		return fmt.Errorf("the %s template leaves containers unencrypted", t.Name)
+	}
+	return nil
+}
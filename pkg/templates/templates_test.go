@@ -0,0 +1,78 @@
+package templates_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immutable-container/imf/pkg/manifest"
+	"github.com/immutable-container/imf/pkg/templates"
+)
+
+func TestBuiltin(t *testing.T) {
+	list, err := templates.Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var names []string
+	for _, tpl := range list {
+		names = append(names, tpl.Name)
+	}
+	if len(list) != 3 || names[0] != "Legal evidence" || names[1] != "Medical records" || names[2] != "Software release" {
+		t.Fatalf("unexpected built-in templates: %v", names)
+	}
+	if _, err := templates.Find("", "missing"); !errors.Is(err, templates.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUserTemplates(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "legal-evidence.json"), []byte(`{"name":"Court exhibits","required":["case_number"],"expiry_days":30}`), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a template"), 0644)
+
+	tpl, err := templates.Find(dir, "legal-evidence")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if tpl.Name != "Court exhibits" || tpl.ExpiryDays != 30 || tpl.Encryption != templates.EncryptionOptional {
+		t.Fatalf("the user's template did not replace the built-in one: %+v", tpl)
+	}
+	if list, _ := templates.Load(dir); len(list) != 3 {
+		t.Fatalf("expected 3 templates, got %d", len(list))
+	}
+	if list, err := templates.Load(filepath.Join(dir, "missing")); err != nil || len(list) != 3 {
+		t.Fatalf("a missing directory should hold no templates: %d, %v", len(list), err)
+	}
+
+	for _, bad := range []string{`{"name":""}`, `{"name":"x","required":["author"]}`, `{"name":"x","encryption":"maybe"}`, `{"name":"x","expiry_days":-1}`, `{"name":"x","expires":30}`, `{`} {
+		os.WriteFile(filepath.Join(dir, "bad.json"), []byte(bad), 0644)
+		if _, err := templates.Load(dir); err == nil {
+			t.Errorf("expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestChecks(t *testing.T) {
+	tpl, err := templates.Find("", "legal-evidence")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if err := tpl.CheckMetadata(nil); err == nil {
+		t.Fatal("expected missing details to be reported")
+	}
+	if err := tpl.CheckMetadata(&manifest.Metadata{Title: "Exhibit A", CaseNumber: " "}); err == nil {
+		t.Fatal("expected a blank case number to be reported")
+	}
+	if err := tpl.CheckMetadata(&manifest.Metadata{Title: "Exhibit A", CaseNumber: "2026-0042"}); err != nil {
+		t.Fatalf("CheckMetadata: %v", err)
+	}
+	if tpl.CheckEncryption(false) == nil || tpl.CheckEncryption(true) != nil {
+		t.Fatal("the legal evidence template should require encryption")
+	}
+	release, _ := templates.Find("", "software-release")
+	if release.CheckEncryption(true) == nil || release.CheckEncryption(false) != nil {
+		t.Fatal("the software release template should refuse encryption")
+	}
+}
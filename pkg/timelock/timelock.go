@@ -0,0 +1,300 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package timelock encrypts data so that nobody can decrypt it before a
+// given time, using the drand randomness beacon.
+//
+// A drand network publishes, every few seconds, a threshold BLS signature
+// over the number of the current round. No one can produce a round's
+// signature before the network does, and that signature is the private key
+// for identity-based encryption to the round, as in drand's tlock:
+//
+//  1. Lock: fetch the chain's public key once, pick the round published at
+//     the unlock time, and encrypt to it — the beacon is not involved
+//  2. Wait: the network publishes the round's signature when its time comes
+//  3. Unlock: fetch the signature, check it against the chain's public key,
+//     and decrypt
+//
+// The encryption is Boneh–Franklin's FullIdent scheme over BLS12-381. Only
+// chains with signatures on G1 and public keys on G2 (scheme
+// "bls-unchained-g1-rfc9380", such as quicknet) are supported. The
+// ciphertext layout is imf's own, not tlock's age-based file format.
+package timelock
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+)
+
+// DefaultURL is drand's public HTTP relay; DefaultChain is the hash of its
+// quicknet chain, which publishes a round every 3 seconds.
+const (
+	DefaultURL   = "https://api.drand.sh"
+	DefaultChain = "52db9ba70e0cc0f6eaf7803dd07447a1f5477735fd3f661792ba94600c84e971"
+)
+
+// Scheme is the only drand signature scheme supported.
+const Scheme = "bls-unchained-g1-rfc9380"
+
+// KeySize is the size of the data Lock encrypts: a symmetric key.
+const KeySize = 32
+
+// maxResponseSize caps a drand response; both endpoints return a few
+// hundred bytes.
+const maxResponseSize = 1 << 16
+
+// dst is the domain separation tag drand hashes round numbers to G1 with.
+var dst = []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_")
+
+// ErrTooEarly is returned by Signature before the round's signature has
+// been published.
+var ErrTooEarly = errors.New("time lock has not opened yet")
+
+// Chain describes a drand chain.
+type Chain struct {
+	URL       string        // HTTP endpoint serving the chain
+	Hash      string        // hex chain hash, identifying the chain
+	PublicKey []byte        // compressed G2 public key of the network
+	Period    time.Duration // time between rounds
+	Genesis   time.Time     // when round 1 was published
+}
+
+// FetchChain retrieves the parameters of the chain with the given hash from
+// the drand endpoint at url. A chain hash commits to the parameters, so a
+// relay cannot substitute another network's key for the hash's.
+func FetchChain(url, hash string) (*Chain, error) {
+	var info struct {
+		PublicKey   string `json:"public_key"`
+		Period      int64  `json:"period"`
+		GenesisTime int64  `json:"genesis_time"`
+		Hash        string `json:"hash"`
+		SchemeID    string `json:"schemeID"`
+	}
+	if err := getJSON(strings.TrimSuffix(url, "/")+"/"+hash+"/info", &info); err != nil {
+		return nil, fmt.Errorf("fetching drand chain info: %w", err)
+	}
+	if info.Hash != hash {
+		return nil, fmt.Errorf("drand returned chain %s, not %s", info.Hash, hash)
+	}
+	if info.SchemeID != Scheme {
+		return nil, fmt.Errorf("unsupported drand scheme %q (want %s)", info.SchemeID, Scheme)
+	}
+	pub, err := hex.DecodeString(info.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("decoding drand public key: %w", err)
+	}
+	var pk bls12381.G2
+	if err := pk.SetBytes(pub); err != nil {
+		return nil, fmt.Errorf("parsing drand public key: %w", err)
+	}
+	if info.Period <= 0 {
+		return nil, fmt.Errorf("invalid drand period %d", info.Period)
+	}
+	return &Chain{
+		URL:       url,
+		Hash:      hash,
+		PublicKey: pub,
+		Period:    time.Duration(info.Period) * time.Second,
+		Genesis:   time.Unix(info.GenesisTime, 0).UTC(),
+	}, nil
+}
+
+// RoundAt returns the first round published at or after t.
+func (c *Chain) RoundAt(t time.Time) uint64 {
+	if !t.After(c.Genesis) {
+		return 1
+	}
+	elapsed := t.Sub(c.Genesis)
+	return uint64((elapsed+c.Period-1)/c.Period) + 1
+}
+
+// RoundTime returns when round is published.
+func (c *Chain) RoundTime(round uint64) time.Time {
+	return c.Genesis.Add(time.Duration(round-1) * c.Period)
+}
+
+// Lock encrypts a KeySize-byte key to round of the chain with public key
+// pub. Only the round's signature can decrypt it.
+func Lock(pub []byte, round uint64, key []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	var pk bls12381.G2
+	if err := pk.SetBytes(pub); err != nil {
+		return nil, fmt.Errorf("parsing drand public key: %w", err)
+	}
+
+	// FullIdent: a random sigma fixes r; U = rP lets the holder of the
+	// round's key recover e(Q, pk)^r, which masks sigma, which masks key.
+	sigma := make([]byte, KeySize)
+	if _, err := rand.Read(sigma); err != nil {
+		return nil, fmt.Errorf("generating randomness: %w", err)
+	}
+	r := h3(sigma, key)
+	var u bls12381.G2
+	u.ScalarMult(r, bls12381.G2Generator())
+	gid := bls12381.Pair(roundPoint(round), &pk)
+	gid.Exp(gid, r)
+
+	ct := u.BytesCompressed()
+	ct = append(ct, xor(sigma, h2(gid))...)
+	ct = append(ct, xor(key, h4(sigma))...)
+	return ct, nil
+}
+
+// Unlock decrypts a ciphertext from Lock with the signature for its round,
+// which must already have been checked (see Signature).
+func Unlock(sig []byte, ct []byte) ([]byte, error) {
+	usize := bls12381.G2SizeCompressed
+	if len(ct) != usize+2*KeySize {
+		return nil, fmt.Errorf("time-lock ciphertext is %d bytes, want %d", len(ct), usize+2*KeySize)
+	}
+	var u bls12381.G2
+	if err := u.SetBytes(ct[:usize]); err != nil {
+		return nil, fmt.Errorf("parsing time-lock ciphertext: %w", err)
+	}
+	var s bls12381.G1
+	if err := s.SetBytes(sig); err != nil {
+		return nil, fmt.Errorf("parsing drand signature: %w", err)
+	}
+	sigma := xor(ct[usize:usize+KeySize], h2(bls12381.Pair(&s, &u)))
+	key := xor(ct[usize+KeySize:], h4(sigma))
+
+	// Re-deriving U from sigma and key rejects a tampered ciphertext.
+	var check bls12381.G2
+	check.ScalarMult(h3(sigma, key), bls12381.G2Generator())
+	if !check.IsEqual(&u) {
+		return nil, errors.New("time-lock ciphertext is corrupt or for another round")
+	}
+	return key, nil
+}
+
+// Signature fetches the chain's signature for round and checks it against
+// the chain's public key. Only c.URL, c.Hash, and c.PublicKey are used.
+// Before the network has published the round it returns ErrTooEarly.
+func Signature(c *Chain, round uint64) ([]byte, error) {
+	var beacon struct {
+		Round     uint64 `json:"round"`
+		Signature string `json:"signature"`
+	}
+	url := fmt.Sprintf("%s/%s/public/%d", strings.TrimSuffix(c.URL, "/"), c.Hash, round)
+	if err := getJSON(url, &beacon); err != nil {
+		// Relays answer 425 Too Early for a future round; some answer 404.
+		var se *statusError
+		if errors.As(err, &se) && (se.code == http.StatusTooEarly || se.code == http.StatusNotFound) {
+			return nil, fmt.Errorf("%w: drand round %d is not published yet", ErrTooEarly, round)
+		}
+		return nil, fmt.Errorf("fetching drand round %d: %w", round, err)
+	}
+	sig, err := hex.DecodeString(beacon.Signature)
+	if err != nil || beacon.Round != round {
+		return nil, fmt.Errorf("drand returned a malformed beacon for round %d", round)
+	}
+	if err := VerifySignature(c.PublicKey, round, sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// VerifySignature checks that sig is the chain's BLS signature for round:
+// e(sig, P) = e(H(round), pub).
+func VerifySignature(pub []byte, round uint64, sig []byte) error {
+	var pk bls12381.G2
+	if err := pk.SetBytes(pub); err != nil {
+		return fmt.Errorf("parsing drand public key: %w", err)
+	}
+	var s bls12381.G1
+	if err := s.SetBytes(sig); err != nil {
+		return fmt.Errorf("parsing drand signature: %w", err)
+	}
+	q := roundPoint(round)
+	res := bls12381.ProdPairFrac([]*bls12381.G1{&s, q}, []*bls12381.G2{bls12381.G2Generator(), &pk}, []int{1, -1})
+	if !res.IsIdentity() {
+		return fmt.Errorf("drand signature for round %d is invalid", round)
+	}
+	return nil
+}
+
+// roundPoint hashes a round number to G1 the way drand signs it: the
+// message is the SHA-256 of the big-endian round number.
+func roundPoint(round uint64) *bls12381.G1 {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], round)
+	msg := sha256.Sum256(b[:])
+	var q bls12381.G1
+	q.Hash(msg[:], dst)
+	return &q
+}
+
+// The FullIdent random oracles, separated by tag.
+
+func h2(gid *bls12381.Gt) []byte {
+	b, _ := gid.MarshalBinary()
+	h := sha256.Sum256(append([]byte("IMF-TLOCK-H2"), b...))
+	return h[:]
+}
+
+func h3(sigma, key []byte) *bls12381.Scalar {
+	h := sha512.New()
+	h.Write([]byte("IMF-TLOCK-H3"))
+	h.Write(sigma)
+	h.Write(key)
+	var r bls12381.Scalar
+	r.SetBytes(h.Sum(nil))
+	return &r
+}
+
+func h4(sigma []byte) []byte {
+	h := sha256.Sum256(append([]byte("IMF-TLOCK-H4"), sigma...))
+	return h[:]
+}
+
+func xor(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
+
+// statusError is a non-200 response from a drand endpoint.
+type statusError struct {
+	host string
+	code int
+	body []byte
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.host, e.code, e.body)
+}
+
+// getJSON fetches url and decodes its JSON body into v.
+func getJSON(url string, v any) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{host: resp.Request.URL.Host, code: resp.StatusCode, body: bytes.TrimSpace(body)}
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,135 @@
+package timelock_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"github.com/immutable-container/imf/pkg/timelock"
+)
+
+const chainHash = "dd2b4c62bd9d5e4cb9e7b1de7df9bd5e1c5a6a0b1d1e3c7b7fbc7e1d6b1c1a2f"
+
+// newDrand starts a fake drand relay for one chain whose network key is
+// secret. It signs any round except those listed in withheld, which it
+// answers with 425 Too Early as a real relay does for future rounds.
+func newDrand(t *testing.T, secret *bls12381.Scalar, genesis time.Time, withheld ...uint64) *httptest.Server {
+	t.Helper()
+	var pub bls12381.G2
+	pub.ScalarMult(secret, bls12381.G2Generator())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/"+chainHash+"/")
+		if path == "info" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"public_key":   hex.EncodeToString(pub.BytesCompressed()),
+				"period":       3,
+				"genesis_time": genesis.Unix(),
+				"hash":         chainHash,
+				"schemeID":     timelock.Scheme,
+			})
+			return
+		}
+		round, err := strconv.ParseUint(strings.TrimPrefix(path, "public/"), 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		for _, w2 := range withheld {
+			if round == w2 {
+				http.Error(w, "too early", http.StatusTooEarly)
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"round":     round,
+			"signature": hex.EncodeToString(sign(secret, round)),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// sign produces the network's BLS signature for round.
+func sign(secret *bls12381.Scalar, round uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], round)
+	msg := sha256.Sum256(b[:])
+	var q bls12381.G1
+	q.Hash(msg[:], []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_"))
+	q.ScalarMult(secret, &q)
+	return q.BytesCompressed()
+}
+
+func TestTimeLock(t *testing.T) {
+	var secret bls12381.Scalar
+	secret.SetUint64(0x1d2c3b4a5f6e7d8c)
+	genesis := time.Now().Add(-time.Hour).Truncate(time.Second)
+	srv := newDrand(t, &secret, genesis, 5000)
+
+	chain, err := timelock.FetchChain(srv.URL, chainHash)
+	if err != nil {
+		t.Fatalf("FetchChain: %v", err)
+	}
+	if chain.Period != 3*time.Second || !chain.Genesis.Equal(genesis) {
+		t.Fatalf("chain period %s genesis %s", chain.Period, chain.Genesis)
+	}
+	if _, err := timelock.FetchChain(srv.URL, strings.Repeat("0", 64)); err == nil {
+		t.Fatal("expected an unknown chain hash to be rejected")
+	}
+
+	// Rounds are counted from 1 at genesis.
+	if r := chain.RoundAt(genesis.Add(7 * time.Second)); r != 4 {
+		t.Fatalf("RoundAt(genesis+7s) = %d, want 4", r)
+	}
+	if tm := chain.RoundTime(4); !tm.Equal(genesis.Add(9 * time.Second)) {
+		t.Fatalf("RoundTime(4) = %s", tm)
+	}
+
+	key := bytes.Repeat([]byte{0x42}, timelock.KeySize)
+	round := chain.RoundAt(time.Now())
+	ct, err := timelock.Lock(chain.PublicKey, round, key)
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	sig, err := timelock.Signature(chain, round)
+	if err != nil {
+		t.Fatalf("Signature: %v", err)
+	}
+	got, err := timelock.Unlock(sig, ct)
+	if err != nil || !bytes.Equal(got, key) {
+		t.Fatalf("Unlock: %x, %v", got, err)
+	}
+
+	// Another round's signature does not open the lock, and a flipped bit
+	// is detected.
+	other, _ := timelock.Signature(chain, round+1)
+	if _, err := timelock.Unlock(other, ct); err == nil {
+		t.Fatal("expected the wrong round's signature to fail")
+	}
+	ct[len(ct)-1] ^= 1
+	if _, err := timelock.Unlock(sig, ct); err == nil {
+		t.Fatal("expected a tampered ciphertext to fail")
+	}
+
+	// An unpublished round, and a forged signature.
+	if _, err := timelock.Signature(chain, 5000); !errors.Is(err, timelock.ErrTooEarly) {
+		t.Fatalf("expected ErrTooEarly, got %v", err)
+	}
+	var wrong bls12381.Scalar
+	wrong.SetUint64(7)
+	if err := timelock.VerifySignature(chain.PublicKey, round, sign(&wrong, round)); err == nil {
+		t.Fatal("expected a signature under another key to fail")
+	}
+
+	t.Logf("✓ Key locked to round %d opens only with that round's beacon", round)
+}
@@ -0,0 +1,415 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package tsa obtains and checks RFC 3161 trusted timestamps.
+//
+// A time-stamping authority (TSA) signs a statement that it saw a given
+// SHA-256 digest at a given time. Unlike an OpenTimestamps proof (see package
+// anchor), such a token is recognized as evidence in jurisdictions that
+// require a qualified TSA. The process:
+//
+//  1. Request: POST a TimeStampReq carrying the digest to the TSA's URL
+//  2. Receive: Get back a TimeStampToken, a CMS SignedData over a TSTInfo
+//     that records the digest and the time
+//  3. Verify: Anyone can check the token's signature and that the TSA's
+//     certificate chains to a CA they trust
+//
+// Only the subset of CMS that TSAs produce is implemented: one signer,
+// identified by issuer and serial number or by subject key identifier, with
+// RSA PKCS #1 v1.5, ECDSA, or Ed25519 signatures.
+package tsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	// Register the digests a TSA may use for its own signature.
+	_ "crypto/sha1"
+	_ "crypto/sha512"
+)
+
+// maxResponseSize caps a TSA's response; tokens are a few kilobytes.
+const maxResponseSize = 1 << 20
+
+var (
+	oidSHA1          = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+)
+
+// Token is a parsed and signature-checked RFC 3161 timestamp token.
+type Token struct {
+	Time         time.Time             // when the TSA saw the digest, by its clock
+	Digest       []byte                // the SHA-256 digest that was timestamped
+	SerialNumber *big.Int              // unique per token issued by the TSA
+	Policy       asn1.ObjectIdentifier // the TSA policy the token was issued under
+	Signer       *x509.Certificate     // the TSA's certificate
+	Certificates []*x509.Certificate   // every certificate in the token, for chain building
+}
+
+// Timestamp asks the TSA at url to timestamp a SHA-256 digest and returns
+// the DER-encoded token. The token's signature, digest, and nonce are checked
+// before it is returned.
+func Timestamp(url string, digest []byte) ([]byte, error) {
+	if len(digest) != sha256.Size {
+		return nil, fmt.Errorf("digest must be %d bytes, got %d", sha256.Size, len(digest))
+	}
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding timestamp request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(url, "application/timestamp-query", bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("contacting TSA: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TSA %s returned status %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading TSA response: %w", err)
+	}
+	if len(body) > maxResponseSize {
+		return nil, fmt.Errorf("TSA response exceeds %d bytes", maxResponseSize)
+	}
+
+	var tsr timeStampResp
+	if _, err := asn1.Unmarshal(body, &tsr); err != nil {
+		return nil, fmt.Errorf("parsing TSA response: %w", err)
+	}
+	// 0 is "granted", 1 "grantedWithMods"; anything else is a refusal.
+	if tsr.Status.Status > 1 {
+		return nil, fmt.Errorf("TSA refused the request (status %d)", tsr.Status.Status)
+	}
+	if len(tsr.Token.FullBytes) == 0 {
+		return nil, errors.New("TSA response carries no token")
+	}
+
+	tok, info, err := parse(tsr.Token.FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(tok.Digest, digest) {
+		return nil, errors.New("TSA timestamped a different digest")
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return nil, errors.New("TSA response nonce does not match the request")
+	}
+	return tsr.Token.FullBytes, nil
+}
+
+// Parse decodes a DER-encoded timestamp token and checks that it is signed
+// by the TSA certificate it carries. It does not check that the certificate
+// is trusted; see Token.Verify.
+func Parse(der []byte) (*Token, error) {
+	tok, _, err := parse(der)
+	return tok, err
+}
+
+// Verify checks that t timestamps digest and, if roots is non-nil, that the
+// TSA's certificate chains to one of roots, is valid for time-stamping, and
+// was valid at the time of the timestamp.
+func (t *Token) Verify(digest []byte, roots *x509.CertPool) error {
+	if !bytes.Equal(t.Digest, digest) {
+		return errors.New("timestamp is for a different digest")
+	}
+	if roots == nil {
+		return nil
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range t.Certificates {
+		intermediates.AddCert(c)
+	}
+	_, err := t.Signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   t.Time,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	})
+	if err != nil {
+		return fmt.Errorf("TSA certificate: %w", err)
+	}
+	return nil
+}
+
+// parse decodes a token, checks its CMS signature, and returns it with the
+// TSTInfo it signs.
+func parse(der []byte) (*Token, *tstInfo, error) {
+	var ci contentInfo
+	if rest, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, nil, fmt.Errorf("parsing timestamp token: %w", err)
+	} else if len(rest) > 0 {
+		return nil, nil, errors.New("parsing timestamp token: trailing data")
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, nil, fmt.Errorf("timestamp token is %v, not signed data", ci.ContentType)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, nil, fmt.Errorf("parsing timestamp token: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, nil, errors.New("timestamp token does not contain a TSTInfo")
+	}
+	var info tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return nil, nil, fmt.Errorf("parsing TSTInfo: %w", err)
+	}
+	if !info.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) {
+		return nil, nil, fmt.Errorf("unsupported message imprint algorithm %v", info.MessageImprint.HashAlgorithm.Algorithm)
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing TSA certificates: %w", err)
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, nil, fmt.Errorf("timestamp token has %d signers, want 1", len(sd.SignerInfos))
+	}
+	si := sd.SignerInfos[0]
+	signer := findSigner(certs, si.SID)
+	if signer == nil {
+		return nil, nil, errors.New("timestamp token does not include the TSA certificate")
+	}
+	if err := checkSignerInfo(si, signer, sd.EncapContentInfo.EContent); err != nil {
+		return nil, nil, fmt.Errorf("timestamp signature: %w", err)
+	}
+
+	return &Token{
+		Time:         info.GenTime,
+		Digest:       info.MessageImprint.HashedMessage,
+		SerialNumber: info.SerialNumber,
+		Policy:       info.Policy,
+		Signer:       signer,
+		Certificates: certs,
+	}, &info, nil
+}
+
+// findSigner returns the certificate among certs that sid identifies.
+func findSigner(certs []*x509.Certificate, sid asn1.RawValue) *x509.Certificate {
+	if sid.Class == asn1.ClassContextSpecific && sid.Tag == 0 {
+		for _, c := range certs {
+			if bytes.Equal(c.SubjectKeyId, sid.Bytes) {
+				return c
+			}
+		}
+		return nil
+	}
+	var ias issuerAndSerial
+	if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err != nil {
+		return nil
+	}
+	for _, c := range certs {
+		if bytes.Equal(c.RawIssuer, ias.Issuer.FullBytes) && c.SerialNumber.Cmp(ias.SerialNumber) == 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+// checkSignerInfo verifies si's signature by cert over content. With signed
+// attributes, the signature covers them and they carry content's digest.
+func checkSignerInfo(si signerInfo, cert *x509.Certificate, content []byte) error {
+	hash, ok := digestHash(si.DigestAlgorithm.Algorithm)
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm %v", si.DigestAlgorithm.Algorithm)
+	}
+	sigAlg, ok := signatureAlgorithm(si.SignatureAlgorithm.Algorithm, hash)
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %v", si.SignatureAlgorithm.Algorithm)
+	}
+
+	signed := content
+	if len(si.SignedAttrs.FullBytes) > 0 {
+		var attrs []attribute
+		if _, err := asn1.UnmarshalWithParams(si.SignedAttrs.FullBytes, &attrs, "set,tag:0"); err != nil {
+			return fmt.Errorf("parsing signed attributes: %w", err)
+		}
+		h := hash.New()
+		h.Write(content)
+		var digestOK, typeOK bool
+		for _, a := range attrs {
+			switch {
+			case a.Type.Equal(oidMessageDigest):
+				var d []byte
+				_, err := asn1.Unmarshal(a.Values.Bytes, &d)
+				digestOK = err == nil && bytes.Equal(d, h.Sum(nil))
+			case a.Type.Equal(oidContentType):
+				var ct asn1.ObjectIdentifier
+				_, err := asn1.Unmarshal(a.Values.Bytes, &ct)
+				typeOK = err == nil && ct.Equal(oidTSTInfo)
+			}
+		}
+		if !digestOK || !typeOK {
+			return errors.New("signed attributes do not match the token content")
+		}
+		// The signature is over the attributes' DER encoding as a SET, not
+		// with the [0] IMPLICIT tag they carry inside SignerInfo.
+		signed = append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+	}
+	return cert.CheckSignature(sigAlg, signed, si.Signature)
+}
+
+// digestHash maps a CMS digest algorithm identifier to its hash.
+func digestHash(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, true
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, true
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, true
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// signatureAlgorithm maps a CMS signature algorithm identifier, which for
+// RSA and ECDSA often names only the key type, and the signer's digest
+// algorithm to an x509.SignatureAlgorithm.
+func signatureAlgorithm(oid asn1.ObjectIdentifier, hash crypto.Hash) (x509.SignatureAlgorithm, bool) {
+	rsa := map[crypto.Hash]x509.SignatureAlgorithm{
+		crypto.SHA1: x509.SHA1WithRSA, crypto.SHA256: x509.SHA256WithRSA,
+		crypto.SHA384: x509.SHA384WithRSA, crypto.SHA512: x509.SHA512WithRSA,
+	}
+	ecdsa := map[crypto.Hash]x509.SignatureAlgorithm{
+		crypto.SHA1: x509.ECDSAWithSHA1, crypto.SHA256: x509.ECDSAWithSHA256,
+		crypto.SHA384: x509.ECDSAWithSHA384, crypto.SHA512: x509.ECDSAWithSHA512,
+	}
+	switch oid.String() {
+	case "1.2.840.113549.1.1.1": // rsaEncryption
+		alg, ok := rsa[hash]
+		return alg, ok
+	case "1.2.840.10045.2.1": // id-ecPublicKey
+		alg, ok := ecdsa[hash]
+		return alg, ok
+	case "1.2.840.113549.1.1.5":
+		return x509.SHA1WithRSA, true
+	case "1.2.840.113549.1.1.11":
+		return x509.SHA256WithRSA, true
+	case "1.2.840.113549.1.1.12":
+		return x509.SHA384WithRSA, true
+	case "1.2.840.113549.1.1.13":
+		return x509.SHA512WithRSA, true
+	case "1.2.840.10045.4.3.2":
+		return x509.ECDSAWithSHA256, true
+	case "1.2.840.10045.4.3.3":
+		return x509.ECDSAWithSHA384, true
+	case "1.2.840.10045.4.3.4":
+		return x509.ECDSAWithSHA512, true
+	case "1.3.101.112": // Ed25519
+		return x509.PureEd25519, true
+	}
+	return 0, false
+}
+
+// ASN.1 structures from RFC 3161 and RFC 5652 (CMS).
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional,default:false"`
+}
+
+type pkiStatusInfo struct {
+	Status int
+}
+
+type timeStampResp struct {
+	Status pkiStatusInfo
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type encapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       accuracy  `asn1:"optional"`
+	Ordering       bool      `asn1:"optional,default:false"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 Benjamin Toso <benjamin.toso@gmail.com>
+// Licensed under the Apache License, Version 2.0
+
+// Package update finds, checks, and installs new releases of imf.
+//
+// A release is described by an index, a small JSON document listing the
+// version and, for each platform, where its binary is and the binary's
+// size and SHA-256:
+//
+//	{
+//	  "version": "1.3.0",
+//	  "published": "2026-11-02T09:00:00Z",
+//	  "notes": "https://example.org/imf/1.3.0",
+//	  "assets": {
+//	    "linux/amd64": {"url": "imf-linux-amd64", "size": 9437184, "sha256": "..."}
+//	  }
+//	}
+//
+// The index is signed with the release key, as by "imf sign-file
+// release.json", and the signature is served beside it as release.json.sig.
+// Only the index is signed; a binary is accepted by its size and hash in the
+// signed index, so nothing downloaded is trusted before it is checked.
+// Asset URLs may be relative to the index.
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+)
+
+// DefaultURL is the index of the latest published release.
+const DefaultURL = "https://github.com/rabenja/immutable-container/releases/latest/download/release.json"
+
+// Limits on what is downloaded: an index is a few hundred bytes, and a
+// binary tens of megabytes.
+const (
+	maxIndexSize  = 1 << 20
+	maxBinarySize = 512 << 20
+	indexTimeout  = 30 * time.Second
+	binaryTimeout = 10 * time.Minute
+)
+
+// ErrNoAsset is returned by Release.Asset when a release has no binary for
+// the platform.
+var ErrNoAsset = errors.New("no release binary for this platform")
+
+// Release is a signed release index.
+type Release struct {
+	Version   string           `json:"version"`
+	Published time.Time        `json:"published"`
+	Notes     string           `json:"notes,omitempty"`
+	Assets    map[string]Asset `json:"assets"` // keyed by GOOS/GOARCH
+
+	base *url.URL // where the index was fetched from, for relative asset URLs
+}
+
+// Asset is one platform's binary in a release.
+type Asset struct {
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"` // hex
+}
+
+// Platform returns this binary's GOOS/GOARCH, the key of its asset.
+func Platform() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// Check fetches the release index at indexURL and its signature at
+// indexURL+".sig", and verifies the signature under releaseKey.
+func Check(indexURL string, releaseKey ed25519.PublicKey) (*Release, error) {
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", indexURL, err)
+	}
+	client := &http.Client{Timeout: indexTimeout}
+	index, err := get(client, indexURL, maxIndexSize)
+	if err != nil {
+		return nil, err
+	}
+	sigData, err := get(client, indexURL+".sig", maxIndexSize)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := imfcrypto.ParseFileSignature(sigData)
+	if err != nil {
+		return nil, err
+	}
+	if err := sig.Verify(strings.NewReader(string(index)), releaseKey); err != nil {
+		return nil, fmt.Errorf("release index: %w", err)
+	}
+
+	var r Release
+	if err := json.Unmarshal(index, &r); err != nil {
+		return nil, fmt.Errorf("parsing release index: %w", err)
+	}
+	if _, err := parseVersion(r.Version); err != nil {
+		return nil, fmt.Errorf("release index: %w", err)
+	}
+	r.base = base
+	return &r, nil
+}
+
+// Asset returns the release's binary for platform, with its URL resolved.
+func (r *Release) Asset(platform string) (Asset, error) {
+	a, ok := r.Assets[platform]
+	if !ok {
+		return Asset{}, fmt.Errorf("%w (%s)", ErrNoAsset, platform)
+	}
+	ref, err := url.Parse(a.URL)
+	if err != nil {
+		return Asset{}, fmt.Errorf("parsing asset URL: %w", err)
+	}
+	if r.base != nil {
+		a.URL = r.base.ResolveReference(ref).String()
+	}
+	return a, nil
+}
+
+// Download fetches a's binary into a new file in dir, checking its size and
+// hash against the signed index, and returns the file's path. The file is
+// removed if it does not match.
+func Download(a Asset, dir string, progress func(done, total int64)) (string, error) {
+	if a.Size <= 0 || a.Size > maxBinarySize {
+		return "", fmt.Errorf("release binary size %d out of range", a.Size)
+	}
+	client := &http.Client{Timeout: binaryTimeout}
+	resp, err := client.Get(a.URL)
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %w", a.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s: server returned status %d", a.URL, resp.StatusCode)
+	}
+
+	f, err := os.CreateTemp(dir, ".imf-update-*")
+	if err != nil {
+		return "", err
+	}
+	ok := false
+	defer func() {
+		f.Close()
+		if !ok {
+			os.Remove(f.Name())
+		}
+	}()
+
+	h := sha256.New()
+	var body io.Reader = io.LimitReader(resp.Body, a.Size+1)
+	if progress != nil {
+		body = &progressReader{r: body, total: a.Size, progress: progress}
+	}
+	n, err := io.Copy(io.MultiWriter(f, h), body)
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %w", a.URL, err)
+	}
+	if n != a.Size {
+		return "", fmt.Errorf("release binary is %d bytes, the signed index says %d", n, a.Size)
+	}
+	if hex.EncodeToString(h.Sum(nil)) != strings.ToLower(a.SHA256) {
+		return "", errors.New("release binary does not match the hash in the signed index")
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	ok = true
+	return f.Name(), nil
+}
+
+// Install replaces the executable at exe with the file at newPath, which
+// must be in the same directory so the swap is a rename. On Windows, where
+// a running executable cannot be replaced, it is moved aside to exe+".old"
+// first; Install removes a leftover one from a previous update.
+func Install(newPath, exe string) error {
+	if err := os.Chmod(newPath, 0755); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("moving the running binary aside: %w", err)
+		}
+		if err := os.Rename(newPath, exe); err != nil {
+			os.Rename(old, exe)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(newPath, exe)
+}
+
+// Executable returns the path of the running binary with symlinks
+// resolved, which is what Install must replace.
+func Executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// Newer reports whether version a is later than version b. Both are
+// semantic versions, with or without a leading "v"; one that does not parse,
+// such as "dev", is older than any release.
+func Newer(a, b string) bool {
+	va, errA := parseVersion(a)
+	vb, errB := parseVersion(b)
+	switch {
+	case errA != nil:
+		return false
+	case errB != nil:
+		return true
+	}
+	for i := range va.core {
+		if va.core[i] != vb.core[i] {
+			return va.core[i] > vb.core[i]
+		}
+	}
+	// A pre-release precedes its release: 1.3.0-rc.1 < 1.3.0.
+	switch {
+	case va.pre == vb.pre:
+		return false
+	case va.pre == "":
+		return true
+	case vb.pre == "":
+		return false
+	}
+	return va.pre > vb.pre
+}
+
+// semver is a parsed MAJOR.MINOR.PATCH[-PRE] version; build metadata is
+// dropped.
+type semver struct {
+	core [3]int
+	pre  string
+}
+
+func parseVersion(s string) (semver, error) {
+	var v semver
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, v.pre, _ = strings.Cut(s, "-")
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		v.core[i] = n
+	}
+	return v, nil
+}
+
+// get fetches url, reading at most limit bytes.
+func get(client *http.Client, url string, limit int64) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: server returned status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("fetching %s: exceeds limit of %d bytes", url, limit)
+	}
+	return data, nil
+}
+
+// progressReader reports the bytes read through it.
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	total    int64
+	progress func(done, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	p.progress(p.done, p.total)
+	return n, err
+}
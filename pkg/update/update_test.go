@@ -0,0 +1,121 @@
+package update_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	imfcrypto "github.com/immutable-container/imf/pkg/crypto"
+	"github.com/immutable-container/imf/pkg/update"
+)
+
+// newReleaseServer serves a release index for version, signed by kp, with
+// one binary for this platform at a relative URL.
+func newReleaseServer(t *testing.T, kp *imfcrypto.KeyPair, version string, binary []byte) *httptest.Server {
+	t.Helper()
+	sum := sha256.Sum256(binary)
+	index, _ := json.Marshal(map[string]any{
+		"version": version,
+		"assets": map[string]any{
+			update.Platform(): map[string]any{"url": "bin/imf", "size": len(binary), "sha256": hex.EncodeToString(sum[:])},
+		},
+	})
+	signer, _ := imfcrypto.NewKeySigner(kp.PrivateKey)
+	sig, err := imfcrypto.SignFile(bytes.NewReader(index), signer)
+	if err != nil {
+		t.Fatalf("SignFile: %v", err)
+	}
+	sigData, _ := json.Marshal(sig)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/release.json", func(w http.ResponseWriter, r *http.Request) { w.Write(index) })
+	mux.HandleFunc("/release.json.sig", func(w http.ResponseWriter, r *http.Request) { w.Write(sigData) })
+	mux.HandleFunc("/bin/imf", func(w http.ResponseWriter, r *http.Request) { w.Write(binary) })
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUpdate(t *testing.T) {
+	kp, _ := imfcrypto.GenerateKeyPair()
+	binary := []byte("#!/bin/sh\necho new imf\n")
+	srv := newReleaseServer(t, kp, "1.3.0", binary)
+
+	r, err := update.Check(srv.URL+"/release.json", kp.PublicKey)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if r.Version != "1.3.0" {
+		t.Fatalf("version = %q", r.Version)
+	}
+	asset, err := r.Asset(update.Platform())
+	if err != nil {
+		t.Fatalf("Asset: %v", err)
+	}
+	if asset.URL != srv.URL+"/bin/imf" {
+		t.Fatalf("asset URL = %q, want it resolved against the index", asset.URL)
+	}
+	if _, err := r.Asset("plan9/mips"); !errors.Is(err, update.ErrNoAsset) {
+		t.Fatalf("missing platform: got %v, want ErrNoAsset", err)
+	}
+
+	// An index signed by another key is refused.
+	other, _ := imfcrypto.GenerateKeyPair()
+	if _, err := update.Check(srv.URL+"/release.json", other.PublicKey); !errors.Is(err, imfcrypto.ErrFileSignature) {
+		t.Fatalf("wrong key: got %v, want ErrFileSignature", err)
+	}
+
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "imf")
+	os.WriteFile(exe, []byte("old imf"), 0755)
+	path, err := update.Download(asset, dir, nil)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if err := update.Install(path, exe); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if got, _ := os.ReadFile(exe); !bytes.Equal(got, binary) {
+		t.Fatalf("installed binary = %q", got)
+	}
+
+	// A binary that does not match the signed hash is not kept.
+	asset.SHA256 = hex.EncodeToString(make([]byte, 32))
+	if _, err := update.Download(asset, dir, nil); err == nil {
+		t.Fatal("expected a hash mismatch")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("left %d files behind, want only the executable", len(entries))
+	}
+	t.Log("✓ Signed release index checked, binary verified and installed")
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.3.0", "1.2.9", true},
+		{"1.2.9", "1.3.0", false},
+		{"v1.10.0", "1.9.0", true},
+		{"1.3.0", "1.3.0", false},
+		{"1.3.0", "1.3.0-rc.1", true},
+		{"1.3.0-rc.1", "1.3.0", false},
+		{"1.3.0-rc.2", "1.3.0-rc.1", true},
+		{"1.0.0", "dev", true},
+		{"dev", "1.0.0", false},
+		{"0.0.0-20261016204501-1021e9ca9e09", "0.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := update.Newer(tt.a, tt.b); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,23 +1,30 @@
 //! IMF Viewer — Tauri native wrapper for the IMF web GUI.
 //!
 //! Architecture:
-//! 1. Launches the Go `imf` binary as a sidecar with `imf gui`
-//! 2. Sets IMF_NO_BROWSER=1 so the Go binary doesn't open a browser
-//! 3. Detects the port from sidecar stdout
-//! 4. Handles macOS file association via RunEvent::Opened (Apple Events)
-//! 5. Creates a native Tauri webview window pointing at the local HTTP server
-//! 6. Kills the sidecar on window close
+//! 1. Launches the Go `imf` binary as a sidecar with `imf gui -app`, which
+//!    opens no browser and stops when its stdin closes
+//! 2. Detects the port from sidecar stdout
+//! 3. Handles macOS file association via RunEvent::Opened (Apple Events)
+//! 4. Creates a native Tauri webview window pointing at the local HTTP server
+//! 5. Stops the sidecar gracefully when the window closes or the app quits,
+//!    and quits the app when the sidecar stops (the GUI's Quit button)
 
 use std::io::{BufRead, BufReader};
-use std::process::{Child, Command, Stdio};
+use std::process::{Child, ChildStdin, Command, Stdio};
 use std::sync::Mutex;
+use std::time::{Duration, Instant};
 use tauri::Manager;
 
 struct SidecarState {
     child: Mutex<Option<Child>>,
+    stdin: Mutex<Option<ChildStdin>>,
     port: Mutex<u16>,
 }
 
+/// How long the sidecar is given to stop before it is killed: the GUI
+/// waits up to 10 seconds for a seal or extraction in progress.
+const SHUTDOWN_WAIT: Duration = Duration::from_secs(15);
+
 fn sidecar_path(app: &tauri::AppHandle) -> std::path::PathBuf {
     let binary_name = if cfg!(target_os = "windows") { "imf.exe" } else { "imf" };
     let mut candidates: Vec<std::path::PathBuf> = Vec::new();
@@ -42,19 +49,21 @@ fn sidecar_path(app: &tauri::AppHandle) -> std::path::PathBuf {
     std::path::PathBuf::from(binary_name)
 }
 
-fn launch_sidecar(app: &tauri::AppHandle) -> Result<(Child, u16), String> {
+fn launch_sidecar(app: &tauri::AppHandle) -> Result<(Child, ChildStdin, u16), String> {
     let binary = sidecar_path(app);
     let mut child = Command::new(&binary)
-        .arg("gui")
+        .args(["gui", "-app"])
         .env("IMF_NO_BROWSER", "1")
+        .stdin(Stdio::piped())
         .stdout(Stdio::piped())
         .stderr(Stdio::inherit())
         .spawn()
         .map_err(|e| format!("Failed to launch sidecar at {:?}: {}", binary, e))?;
+    let stdin = child.stdin.take().ok_or("Failed to capture stdin")?;
     let stdout = child.stdout.take().ok_or("Failed to capture stdout")?;
-    let reader = BufReader::new(stdout);
+    let mut lines = BufReader::new(stdout).lines();
     let mut port: u16 = 0;
-    for line in reader.lines().map_while(Result::ok) {
+    for line in lines.by_ref().map_while(Result::ok) {
         if line.contains("running at http://127.0.0.1:") {
             if let Some(port_str) = line.rsplit(':').next() {
                 if let Ok(p) = port_str.trim().parse::<u16>() {
@@ -68,7 +77,57 @@ fn launch_sidecar(app: &tauri::AppHandle) -> Result<(Child, u16), String> {
         let _ = child.kill();
         return Err("Could not detect sidecar port".to_string());
     }
-    Ok((child, port))
+    // Keep reading stdout: with the pipe closed, the sidecar's next line,
+    // such as "IMF GUI stopped", would kill it with SIGPIPE.
+    std::thread::spawn(move || {
+        for line in lines.map_while(Result::ok) {
+            println!("{}", line);
+        }
+    });
+    Ok((child, stdin, port))
+}
+
+/// Stops the sidecar as the GUI's Quit button would: closing its stdin
+/// tells `imf gui -app` to let a seal or extraction in progress finish,
+/// wipe the loaded keys, and remove the extracted files. It is killed only
+/// if it has not stopped within SHUTDOWN_WAIT. Calling it again does nothing.
+fn stop_sidecar(state: &SidecarState) {
+    if let Ok(mut stdin) = state.stdin.lock() {
+        stdin.take();
+    }
+    let Some(mut child) = state.child.lock().ok().and_then(|mut c| c.take()) else {
+        return;
+    };
+    let deadline = Instant::now() + SHUTDOWN_WAIT;
+    while Instant::now() < deadline {
+        match child.try_wait() {
+            Ok(None) => std::thread::sleep(Duration::from_millis(100)),
+            _ => return,
+        }
+    }
+    let _ = child.kill();
+    let _ = child.wait();
+}
+
+/// Quits the app when the sidecar stops on its own, as it does when the
+/// GUI's Quit button is pressed, rather than leaving the window on a server
+/// that is gone.
+fn watch_sidecar(handle: tauri::AppHandle) {
+    std::thread::spawn(move || loop {
+        std::thread::sleep(Duration::from_millis(500));
+        let state = handle.state::<SidecarState>();
+        let exited = match state.child.lock() {
+            Ok(mut child) => match child.as_mut() {
+                Some(c) => !matches!(c.try_wait(), Ok(None)),
+                None => return, // being stopped by stop_sidecar
+            },
+            Err(_) => return,
+        };
+        if exited {
+            handle.exit(0);
+            return;
+        }
+    });
 }
 
 fn urlencod(s: &str) -> String {
@@ -121,13 +180,15 @@ fn main() {
         .plugin(tauri_plugin_shell::init())
         .setup(move |app| {
             let handle = app.handle().clone();
-            let (child, port) = launch_sidecar(&handle)
+            let (child, stdin, port) = launch_sidecar(&handle)
                 .map_err(|e| Box::new(std::io::Error::new(std::io::ErrorKind::Other, e)))?;
 
             app.manage(SidecarState {
                 child: Mutex::new(Some(child)),
+                stdin: Mutex::new(Some(stdin)),
                 port: Mutex::new(port),
             });
+            watch_sidecar(handle.clone());
 
             // Check if a file path was stored by an early Opened event
             let pending_file = pending_for_setup.lock().ok().and_then(|mut p| p.take());
@@ -161,13 +222,10 @@ fn main() {
             Ok(())
         })
         .on_window_event(|window, event| {
+            // Closing the window quits the app, and with it the server.
             if let tauri::WindowEvent::Destroyed = event {
                 if let Some(state) = window.try_state::<SidecarState>() {
-                    if let Ok(mut child) = state.child.lock() {
-                        if let Some(ref mut c) = *child {
-                            let _ = c.kill();
-                        }
-                    }
+                    stop_sidecar(&state);
                 }
             }
         })
@@ -178,6 +236,14 @@ fn main() {
     // When user double-clicks an .imf file, macOS sends an Apple Event
     // which Tauri delivers as RunEvent::Opened with file:// URLs.
     app.run(move |app_handle, event| {
+        // Quitting from the menu or the dock (Cmd+Q) may not destroy the
+        // window first.
+        if let tauri::RunEvent::Exit = &event {
+            if let Some(state) = app_handle.try_state::<SidecarState>() {
+                stop_sidecar(&state);
+            }
+            return;
+        }
         if let tauri::RunEvent::Opened { urls } = &event {
             for url in urls {
                 if let Some(path) = imf_path_from_url(url) {